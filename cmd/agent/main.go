@@ -1,60 +1,66 @@
 package main
 
 import (
-	"errors"
 	"flag"
 	"log"
-	"net/http"
-	_ "net/http/pprof" // #nosec G108 -- debug-only pprof server is bound to 127.0.0.1
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/nhdewitt/spectra/internal/agent"
+	"github.com/nhdewitt/spectra/internal/debugserver"
+	"github.com/nhdewitt/spectra/internal/hostinfo"
 )
 
 func main() {
 	// DEBUGGING
-	debugMode := flag.Bool("debug", false, "Enable pprof debug server on localhost:6060")
-	configPath := flag.String("config", "", "Path to agent config file (default: OS-specific)")
+	debugMode := flag.Bool("debug", false, "Enable pprof debug server on localhost:6060 (or $SPECTRA_DEBUG_ADDR)")
+	configPath := flag.String("config", "", "Path to agent config file (default: $SPECTRA_CONFIG or OS-specific)")
+	dryRun := flag.Bool("dry-run", false, "Print collected metrics to stdout instead of sending them")
+	metricsListen := flag.String("metrics-listen", "", "Serve metrics for scraping at http://<addr>/metrics instead of sending them")
 	flag.Parse()
 
-	if *debugMode {
-		go func() {
-			log.Println("DEBUG MODE: pprof server running on http://127.0.0.1:6060/debug/pprof/")
-
-			srv := &http.Server{
-				Addr:              "127.0.0.1:6060",
-				ReadHeaderTimeout: 5 * time.Second,
-			}
-
-			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-				log.Printf("Failed to start debug server: %v", err)
-			}
-		}()
+	debugAddr := debugserver.AddrFromEnv()
+	if *debugMode && debugAddr == "" {
+		debugAddr = "127.0.0.1:6060"
+	}
+	if srv, err := debugserver.Start(debugAddr); err != nil {
+		log.Printf("Failed to start debug server: %v", err)
+	} else if srv != nil {
+		log.Printf("DEBUG MODE: pprof server running on http://%s/debug/pprof/", srv.Addr)
 	}
 
 	// Try loading config file
 	path := *configPath
 	if path == "" {
-		path = agent.DefaultConfigPath()
+		path = agent.ConfigPathFromEnv()
 	}
 
 	cfg, err := agent.LoadConfig(path)
 	if err != nil {
 		log.Printf("No config file at %s, using environment variables", path)
 		cfg = agent.ConfigFromEnv()
+	} else {
+		agent.ApplyEnv(cfg)
 	}
 
-	hostname, err := os.Hostname()
-	if err != nil {
-		log.Fatalf("Error getting hostname: %v", err)
+	if *dryRun {
+		cfg.DryRun = true
 	}
-	if h := os.Getenv("HOSTNAME"); h != "" {
-		hostname = h
+	if *metricsListen != "" {
+		cfg.MetricsListen = *metricsListen
+	}
+
+	if cfg.Hostname == "" {
+		hostname := os.Getenv("HOSTNAME")
+		if hostname == "" {
+			hostname = hostinfo.Cached().Hostname
+		}
+		if hostname == "" {
+			log.Fatal("Error getting hostname")
+		}
+		cfg.Hostname = hostname
 	}
-	cfg.Hostname = hostname
 
 	a := agent.New(*cfg)
 
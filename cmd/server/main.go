@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/nhdewitt/spectra/internal/database"
+	"github.com/nhdewitt/spectra/internal/debugserver"
 	"github.com/nhdewitt/spectra/internal/secret"
 	"github.com/nhdewitt/spectra/internal/server"
 	"github.com/nhdewitt/spectra/internal/setup"
@@ -38,18 +39,51 @@ func main() {
 
 	queries := database.New(pool)
 
+	queuePolicy, err := server.ParseQueuePolicy(cfg.CommandQueuePolicy)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+
 	srvCfg := server.Config{
 		Port:           cfg.ListenPort,
 		ReleasesDir:    "releases",
 		MaxConnections: 1024,
+		MaxBatchSize:   cfg.MaxBatchSize,
+		CmdQueueSize:   cfg.CommandQueueSize,
+		CmdQueuePolicy: queuePolicy,
+		StateFile:      cfg.StateFile,
+		DrainDelay:     time.Duration(cfg.ShutdownDrainSeconds) * time.Second,
 		ExternalURL:    cfg.ExternalURL,
 		TLSCert:        cfg.TLSCert,
 		TLSKey:         cfg.TLSKey,
 		TLSCA:          cfg.TLSCA,
+		OTLPEndpoint:   cfg.OTLPEndpoint,
+		Influx: server.InfluxConfig{
+			URL:    cfg.InfluxURL,
+			Org:    cfg.InfluxOrg,
+			Bucket: cfg.InfluxBucket,
+			Token:  cfg.InfluxToken,
+		},
+		RateLimits: server.RateLimits{
+			Anon:   server.RateLimit(cfg.RateLimits.Anon),
+			Authed: server.RateLimit(cfg.RateLimits.Authed),
+			Agent:  server.RateLimit(cfg.RateLimits.Agent),
+		},
+		CORS: server.CORSConfig{
+			AllowedOrigins: cfg.CORSOrigins,
+			AllowedMethods: cfg.CORSMethods,
+			AllowedHeaders: cfg.CORSHeaders,
+		},
 	}
 
 	srv := server.New(srvCfg, queries)
 
+	if dbg, err := debugserver.Start(debugserver.AddrFromEnv()); err != nil {
+		srv.Logger.Error("debug server not started", "error", err)
+	} else if dbg != nil {
+		srv.Logger.Info("debug server listening", "addr", dbg.Addr)
+	}
+
 	cipher, err := secret.NewFromEnv()
 	switch {
 	case errors.Is(err, secret.ErrNoKey):
@@ -77,7 +111,7 @@ func main() {
 		return
 	}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), srv.Config.DrainDelay+30*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(shutdownCtx); err != nil {
@@ -39,8 +39,21 @@ type Config struct {
 	LogLevel          string
 	CACert            string
 	TLSSkipVerify     bool
+	Collectors        map[string]CollectorConfig // per-collector overrides, keyed by job name
+	DryRun            bool                       // print metrics instead of sending them
+	MetricsListen     string                     // serve /metrics on this address instead of sending, e.g. "127.0.0.1:9273"
+	Tags              []string                   // sent to the server on registration
+	Encoding          string                     // metrics wire format: EncodingJSON (default) or EncodingMsgpack
+	Logger            *logging.Logger            // overrides LogFile/LogLevel when set
+	DedupHeartbeat    time.Duration              // suppress unchanged metrics, resending at least this often; 0 disables
 }
 
+// Metrics wire formats.
+const (
+	EncodingJSON    = "json"
+	EncodingMsgpack = "msgpack"
+)
+
 // Agent is the main application controller
 type Agent struct {
 	Config     Config
@@ -51,15 +64,22 @@ type Agent struct {
 	metricsCh chan protocol.Envelope
 	batch     []protocol.Envelope
 	wg        sync.WaitGroup
-	cancel    context.CancelFunc
-	done      chan struct{}
+	// collectors tracks goroutines writing to metricsCh so the sender
+	// can drain the channel after they have all stopped
+	collectors sync.WaitGroup
+	cancel     context.CancelFunc
+	done       chan struct{}
 
 	cache *metricsCache
+	dedup *metricDeduper // nil unless Config.DedupHeartbeat is set
 
 	gzipMu  sync.Mutex
 	gzipBuf bytes.Buffer
 	gzipW   *gzip.Writer
 
+	streamsMu sync.Mutex
+	streams   map[string]context.CancelFunc // running streaming commands, by command ID
+
 	commonHeaders map[string]string
 
 	RetryConfig  RetryConfig
@@ -67,9 +87,16 @@ type Agent struct {
 	backoffStep  int
 
 	Platform platform.Info
-	Identity Identity
+
+	// identityMu guards Identity, which changes when the agent
+	// re-registers while other loops are sending requests.
+	identityMu sync.RWMutex
+	Identity   Identity
 
 	BinaryHash string
+
+	// DryRunOutput receives envelopes when Config.DryRun is set.
+	DryRunOutput io.Writer
 }
 
 type RetryConfig struct {
@@ -118,7 +145,10 @@ func New(cfg Config) *Agent {
 		logCfg.ConsoleLevel = logging.ParseLevel(cfg.LogLevel)
 	}
 
-	logger := logging.New(logCfg)
+	logger := cfg.Logger
+	if logger == nil {
+		logger = logging.New(logCfg)
+	}
 	transport := http.DefaultTransport.(*http.Transport).Clone()
 	transport.TLSClientConfig = tlsConfigFromAgentConfig(cfg, logger)
 
@@ -139,6 +169,7 @@ func New(cfg Config) *Agent {
 		Logger:     logger,
 		Client:     client,
 		DriveCache: disk.NewDriveCache(),
+		dedup:      newMetricDeduper(cfg.DedupHeartbeat),
 		metricsCh:  make(chan protocol.Envelope, 500),
 		batch:      make([]protocol.Envelope, 0, 50),
 		cancel:     nil,
@@ -152,9 +183,10 @@ func New(cfg Config) *Agent {
 			"X-Agent-Version":  version.Version,
 			"X-Agent-Commit":   version.Commit,
 		},
-		RetryConfig: DefaultRetryConfig(),
-		Platform:    platform.Detect(),
-		Identity:    id,
+		RetryConfig:  DefaultRetryConfig(),
+		Platform:     platform.Detect(),
+		Identity:     id,
+		DryRunOutput: os.Stdout,
 	}
 }
 
@@ -176,15 +208,21 @@ func (a *Agent) Start() error {
 		a.commonHeaders["X-Agent-Binary-Hash"] = a.BinaryHash
 	}
 
+	if a.Config.DryRun {
+		return a.startDryRun(ctx)
+	}
+	if a.Config.MetricsListen != "" {
+		return a.startScrapeTarget(ctx)
+	}
+
 	if a.Identity.ID == "" {
-		if err := a.Register(ctx); err != nil {
+		if err := a.registerUntilAcknowledged(ctx); err != nil {
 			return fmt.Errorf("registration failed: %w", err)
 		}
-		a.Logger.Info("agent registered", "agent_id", a.Identity.ID)
 	}
 
 	// Mount Manager (Windows disk mapping)
-	go disk.RunMountManager(ctx, a.DriveCache, 30*time.Second)
+	go disk.RunMountManager(ctx, a.DriveCache, 30*time.Second, a.Logger.Logger)
 
 	// Metric Sender
 	a.wg.Add(1)
@@ -220,6 +258,25 @@ func (a *Agent) Start() error {
 	return nil
 }
 
+// startDryRun runs the collectors and prints their output without
+// registering, polling for commands, or contacting the server.
+func (a *Agent) startDryRun(ctx context.Context) error {
+	a.Logger.Info("dry-run mode: metrics will be printed, not sent")
+
+	go disk.RunMountManager(ctx, a.DriveCache, 30*time.Second, a.Logger.Logger)
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		a.runDryRun(ctx, a.DryRunOutput)
+	}()
+
+	a.startCollectors(ctx)
+
+	<-ctx.Done()
+	return nil
+}
+
 // Shutdown gracefully stops all background tasks
 func (a *Agent) Shutdown() {
 	a.Logger.Info("agent shutting down")
@@ -234,9 +291,12 @@ func (a *Agent) setHeaders(req *http.Request) {
 	for k, v := range a.commonHeaders {
 		req.Header.Set(k, v)
 	}
-	if a.Identity.ID != "" {
-		req.Header.Set("X-Agent-ID", a.Identity.ID)
-		req.Header.Set("X-Agent-Secret", a.Identity.Secret)
+	a.identityMu.RLock()
+	id := a.Identity
+	a.identityMu.RUnlock()
+	if id.ID != "" {
+		req.Header.Set("X-Agent-ID", id.ID)
+		req.Header.Set("X-Agent-Secret", id.Secret)
 	}
 	req.Header.Set("X-Spectra-Agent-Version", version.Version)
 }
@@ -8,13 +8,18 @@ import (
 	"github.com/nhdewitt/spectra/internal/collector/containers"
 	"github.com/nhdewitt/spectra/internal/collector/cpu"
 	"github.com/nhdewitt/spectra/internal/collector/disk"
+	"github.com/nhdewitt/spectra/internal/collector/gpu"
 	"github.com/nhdewitt/spectra/internal/collector/memory"
 	"github.com/nhdewitt/spectra/internal/collector/network"
+	"github.com/nhdewitt/spectra/internal/collector/pci"
 	"github.com/nhdewitt/spectra/internal/collector/pi"
+	"github.com/nhdewitt/spectra/internal/collector/power"
 	"github.com/nhdewitt/spectra/internal/collector/processes"
+	"github.com/nhdewitt/spectra/internal/collector/sensors"
 	"github.com/nhdewitt/spectra/internal/collector/services"
 	"github.com/nhdewitt/spectra/internal/collector/system"
 	"github.com/nhdewitt/spectra/internal/collector/temperature"
+	"github.com/nhdewitt/spectra/internal/collector/usb"
 	"github.com/nhdewitt/spectra/internal/collector/wifi"
 	"github.com/nhdewitt/spectra/internal/inventory"
 	"github.com/nhdewitt/spectra/internal/protocol"
@@ -22,76 +27,190 @@ import (
 
 // job is a helper struct for internal use.
 type job struct {
+	Name     string
 	Interval time.Duration
 	Fn       collector.CollectFunc
 }
 
-func (a *Agent) startCollectors(ctx context.Context) {
-	c := collector.New(a.Config.Hostname, a.metricsCh)
+func init() {
+	collector.Register("cpu", 5*time.Second, cpu.Collect)
+	collector.Register("power", 10*time.Second, power.CollectRAPL)
+	collector.Register("memory", 10*time.Second, memory.Collect)
+	collector.Register("swap", 30*time.Second, memory.CollectSwap)
+	collector.Register("slab", 60*time.Second, memory.CollectSlab)
+	collector.Register("network", 5*time.Second, network.Collect)
+	collector.Register("arp", 60*time.Second, network.CollectARP)
+	collector.Register("sockets", 30*time.Second, network.CollectSocketSummary)
+	collector.Register("tcp", 30*time.Second, network.CollectTCPStats)
+	collector.Register("system", 300*time.Second, system.Collect)
+	collector.Register("process_summary", 10*time.Second, processes.CollectProcessSummary)
+	collector.Register("wifi", 30*time.Second, wifi.Collect)
+	collector.Register("containers", 60*time.Second, containers.Collect)
+	collector.Register("failed_units", 30*time.Second, services.CollectFailedUnits)
+	collector.Register("timers", 300*time.Second, services.CollectTimers)
+	collector.Register("ipmi", 60*time.Second, sensors.CollectIPMI)
+	collector.Register("sensors", 30*time.Second, sensors.CollectSensors)
+	collector.Register("gpu_processes", 30*time.Second, gpu.CollectNVIDIAProcesses)
+	collector.Register("usb", 300*time.Second, usb.Collect)
+	collector.Register("pci", 3600*time.Second, pci.Collect)
+	collector.Register("dmi", 300*time.Second, system.CollectDMI)
+	collector.Register("entropy", 60*time.Second, system.CollectEntropy)
+}
 
+// piJobs are only scheduled on Raspberry Pi hardware.
+var piJobs = []job{
+	{"pi_clocks", 15 * time.Second, pi.CollectClocks},
+	{"pi_throttle", 10 * time.Second, pi.CollectThrottle},
+	{"pi_voltage", 60 * time.Second, pi.CollectVoltage},
+	{"pi_gpu", 60 * time.Second, pi.CollectGPU},
+}
+
+// optInJobs are only scheduled when explicitly enabled in
+// Config.Collectors, because they are expensive or disruptive.
+var optInJobs = []job{
+	{"wifi_scan", 300 * time.Second, wifi.CollectWiFiScan},
+}
+
+// defaultJobs returns the built-in collectors that depend on agent state
+// and so cannot be registered from init.
+func (a *Agent) defaultJobs() []job {
 	diskCol := disk.MakeDiskCollector(a.DriveCache)
 	diskIOCol := disk.MakeDiskIOCollector(a.DriveCache)
-	svcCol := services.MakeCollector(a.Platform.SystemctlPath)
-	tempCol := temperature.MakeCollector(a.Platform.ThermalZones)
-
-	jobs := []job{
-		{5 * time.Second, cpu.Collect},
-		{10 * time.Second, memory.Collect},
-		{5 * time.Second, network.Collect},
-		{300 * time.Second, system.Collect},
-		{60 * time.Second, diskCol},
-		{5 * time.Second, diskIOCol},
-		{60 * time.Second, svcCol},
-		{15 * time.Second, processes.Collect},
-		{10 * time.Second, tempCol},
-		{30 * time.Second, wifi.Collect},
-		{60 * time.Second, containers.Collect},
+	svcCol := services.MakeCollector(a.Platform.SystemctlPath, a.Config.Collectors["services"].Accounting)
+	tempCol := temperature.MakeCollector(a.Platform.ThermalZones, a.Config.Collectors["temperature"].MaxTemp)
+	procCol := processes.MakeCollector(a.Config.Collectors["processes"].TopN)
+
+	return []job{
+		{"disk", 60 * time.Second, diskCol},
+		{"disk_io", 5 * time.Second, diskIOCol},
+		{"services", 60 * time.Second, svcCol},
+		{"processes", 15 * time.Second, procCol},
+		{"temperature", 10 * time.Second, tempCol},
 	}
+}
 
+// registeredJobs returns every collector added through collector.Register,
+// including the stateless built-ins registered above.
+func registeredJobs() []job {
+	regs := collector.Registered()
+	jobs := make([]job, 0, len(regs))
+	for _, r := range regs {
+		jobs = append(jobs, job{r.Name, r.Interval, r.Fn})
+	}
+	return jobs
+}
+
+// buildJobs returns the jobs to schedule after applying Config.Collectors.
+func (a *Agent) buildJobs() []job {
+	jobs := append(a.defaultJobs(), registeredJobs()...)
+
+	known := make(map[string]bool, len(jobs)+len(piJobs))
 	for _, j := range jobs {
-		go c.Run(ctx, j.Interval, j.Fn)
+		known[j.Name] = true
+	}
+	for _, j := range piJobs {
+		known[j.Name] = true
+	}
+	for _, j := range optInJobs {
+		known[j.Name] = true
 	}
 
 	if a.Platform.IsRaspberryPi {
-		piJobs := []job{
-			{15 * time.Second, pi.CollectClocks},
-			{10 * time.Second, pi.CollectThrottle},
-			{60 * time.Second, pi.CollectVoltage},
-			{60 * time.Second, pi.CollectGPU},
+		jobs = append(jobs, piJobs...)
+	}
+	for _, j := range optInJobs {
+		if c, ok := a.Config.Collectors[j.Name]; ok && c.Enabled != nil && *c.Enabled {
+			jobs = append(jobs, j)
 		}
-		for _, j := range piJobs {
-			go c.Run(ctx, j.Interval, j.Fn)
+	}
+
+	for name := range a.Config.Collectors {
+		if !known[name] {
+			a.Logger.Warn("unknown collector in config, ignoring", "collector", name)
 		}
 	}
 
-	// Nightly tasks
-	go a.runNightly(ctx, 2, 0, func() {
-		apps, err := inventory.GetInstalledApps(ctx)
-		if err != nil {
-			a.Logger.Warn("nightly apps collection failed", "error", err)
-			return
+	return applyCollectorConfig(jobs, a.Config.Collectors)
+}
+
+// applyCollectorConfig drops disabled jobs and overrides intervals.
+// Jobs without an entry in cfg keep their defaults.
+func applyCollectorConfig(jobs []job, cfg map[string]CollectorConfig) []job {
+	if len(cfg) == 0 {
+		return jobs
+	}
+
+	out := make([]job, 0, len(jobs))
+	for _, j := range jobs {
+		c, ok := cfg[j.Name]
+		if ok && c.Enabled != nil && !*c.Enabled {
+			continue
 		}
-		a.metricsCh <- protocol.Envelope{
-			Type:      "application_list",
-			Timestamp: time.Now(),
-			Hostname:  a.Config.Hostname,
-			Data:      &protocol.ApplicationListMetric{Applications: apps},
+		if ok && c.Interval > 0 {
+			j.Interval = time.Duration(c.Interval)
 		}
+		out = append(out, j)
+	}
+	return out
+}
+
+func (a *Agent) startCollectors(ctx context.Context) {
+	c := collector.New(a.Config.Hostname, a.metricsCh)
+
+	for _, j := range a.buildJobs() {
+		a.collectors.Go(func() { c.RunNamed(ctx, j.Name, j.Interval, j.Fn) })
+	}
+
+	// Nightly tasks
+	a.collectors.Go(func() {
+		a.runNightly(ctx, 2, 0, func() {
+			apps, err := inventory.GetInstalledApps(ctx)
+			if err != nil {
+				a.Logger.Warn("nightly apps collection failed", "error", err)
+				return
+			}
+			a.emit(ctx, protocol.Envelope{
+				Type:          protocol.TypeApplicationList,
+				SchemaVersion: protocol.SchemaVersion,
+				Timestamp:     time.Now(),
+				Hostname:      a.Config.Hostname,
+				Data:          &protocol.ApplicationListMetric{Applications: apps},
+			})
+		})
 	})
 
-	go a.runNightly(ctx, 2, 5, func() {
-		metrics, err := inventory.GetUpdates(ctx)
-		if err != nil {
-			a.Logger.Warn("nightly updates collection failed", "error", err)
-			return
-		}
-		for _, m := range metrics {
-			a.metricsCh <- protocol.Envelope{
-				Type:      m.MetricType(),
-				Timestamp: time.Now(),
-				Hostname:  a.Config.Hostname,
-				Data:      m,
+	a.collectors.Go(func() {
+		a.runNightly(ctx, 2, 5, func() {
+			metrics, err := inventory.GetUpdates(ctx)
+			if err != nil {
+				a.Logger.Warn("nightly updates collection failed", "error", err)
+				return
 			}
-		}
+			for _, m := range metrics {
+				if !a.emit(ctx, protocol.Envelope{
+					Type:          m.MetricType(),
+					SchemaVersion: protocol.SchemaVersion,
+					Timestamp:     time.Now(),
+					Hostname:      a.Config.Hostname,
+					Data:          m,
+				}) {
+					return
+				}
+			}
+		})
 	})
 }
+
+// emit queues env for the sender. It never sends once ctx is cancelled,
+// and reports whether env was queued.
+func (a *Agent) emit(ctx context.Context, env protocol.Envelope) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case a.metricsCh <- env:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
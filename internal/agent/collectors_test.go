@@ -8,6 +8,7 @@ import (
 
 	"github.com/nhdewitt/spectra/internal/collector/cpu"
 	"github.com/nhdewitt/spectra/internal/collector/disk"
+	"github.com/nhdewitt/spectra/internal/logging"
 )
 
 func TestJob_Struct(t *testing.T) {
@@ -56,6 +57,105 @@ func TestStartCollectors_ContextCancelled(t *testing.T) {
 	}
 }
 
+func boolPtr(b bool) *bool { return &b }
+
+func TestBuildJobs_AppliesConfig(t *testing.T) {
+	a := New(Config{
+		Hostname:     "test-agent",
+		IdentityPath: filepath.Join(t.TempDir(), "agent-id.json"),
+		Collectors: map[string]CollectorConfig{
+			"processes": {Enabled: boolPtr(false)},
+			"services":  {Interval: Duration(5 * time.Minute)},
+			"cpu":       {Enabled: boolPtr(true)},
+			"bogus":     {Enabled: boolPtr(false)},
+		},
+	})
+	a.Logger = logging.NewDiscard()
+
+	jobs := a.buildJobs()
+	byName := make(map[string]job, len(jobs))
+	for _, j := range jobs {
+		byName[j.Name] = j
+	}
+
+	if _, ok := byName["processes"]; ok {
+		t.Error("disabled collector 'processes' should be omitted")
+	}
+	if got := byName["services"].Interval; got != 5*time.Minute {
+		t.Errorf("services interval: got %v, want 5m", got)
+	}
+	if got := byName["cpu"].Interval; got != 5*time.Second {
+		t.Errorf("cpu interval: got %v, want default 5s", got)
+	}
+	if got := byName["memory"].Interval; got != 10*time.Second {
+		t.Errorf("memory interval: got %v, want default 10s", got)
+	}
+	want := len(a.defaultJobs()) + len(registeredJobs()) - 1
+	if len(jobs) != want {
+		t.Errorf("job count: got %d, want %d", len(jobs), want)
+	}
+}
+
+func TestBuildJobs_IncludesRegistered(t *testing.T) {
+	a := New(Config{Hostname: "test-agent", IdentityPath: filepath.Join(t.TempDir(), "agent-id.json")})
+
+	names := make(map[string]bool)
+	for _, j := range a.buildJobs() {
+		names[j.Name] = true
+	}
+
+	for _, want := range []string{"cpu", "power", "memory", "network", "system", "processes", "process_summary", "wifi", "containers", "disk", "disk_io", "services", "temperature", "failed_units", "timers", "ipmi", "sensors", "usb", "pci", "dmi", "entropy", "swap", "slab", "arp"} {
+		if !names[want] {
+			t.Errorf("expected built-in collector %q in job list", want)
+		}
+	}
+}
+
+func TestBuildJobs_OptIn(t *testing.T) {
+	hasJob := func(jobs []job, name string) bool {
+		for _, j := range jobs {
+			if j.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	a := New(Config{Hostname: "test-agent", IdentityPath: filepath.Join(t.TempDir(), "agent-id.json")})
+	a.Logger = logging.NewDiscard()
+	if hasJob(a.buildJobs(), "wifi_scan") {
+		t.Error("wifi_scan should be off by default")
+	}
+
+	a.Config.Collectors = map[string]CollectorConfig{"wifi_scan": {Enabled: boolPtr(true), Interval: Duration(10 * time.Minute)}}
+	jobs := a.buildJobs()
+	if !hasJob(jobs, "wifi_scan") {
+		t.Fatal("wifi_scan should be scheduled when enabled")
+	}
+	for _, j := range jobs {
+		if j.Name == "wifi_scan" && j.Interval != 10*time.Minute {
+			t.Errorf("wifi_scan interval: got %v, want 10m", j.Interval)
+		}
+	}
+}
+
+func TestApplyCollectorConfig_NoConfig(t *testing.T) {
+	jobs := []job{
+		{"cpu", 5 * time.Second, cpu.Collect},
+		{"memory", 10 * time.Second, cpu.Collect},
+	}
+
+	got := applyCollectorConfig(jobs, nil)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(got))
+	}
+	for i := range jobs {
+		if got[i].Name != jobs[i].Name || got[i].Interval != jobs[i].Interval {
+			t.Errorf("job %d changed: got %+v", i, got[i])
+		}
+	}
+}
+
 func TestMakeDiskCollector(t *testing.T) {
 	cache := disk.NewDriveCache()
 	diskCol := disk.MakeDiskCollector(cache)
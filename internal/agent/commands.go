@@ -9,66 +9,130 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/nhdewitt/spectra/internal/collector/processes"
 	"github.com/nhdewitt/spectra/internal/diagnostics"
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
+// commandPollInterval is the pause between command polls while the
+// server is reachable.
+const commandPollInterval = 5 * time.Second
+
+// Bounds for the per-command deadline requested by the server.
+const (
+	defaultCommandTimeout = 60 * time.Second
+	maxCommandTimeout     = 10 * time.Minute
+)
+
+// commandTimeout returns the deadline cmd asks for, falling back to
+// defaultCommandTimeout and capped at maxCommandTimeout.
+func commandTimeout(cmd protocol.Command) time.Duration {
+	if cmd.Timeout <= 0 {
+		return defaultCommandTimeout
+	}
+	return time.Duration(min(cmd.Timeout, int(maxCommandTimeout/time.Second))) * time.Second
+}
+
 // runCommandLoop long-polls the server for tasks
 func (a *Agent) runCommandLoop(ctx context.Context) {
 	url := fmt.Sprintf("%s%s", a.Config.BaseURL, a.Config.CommandPath)
 	a.Logger.Info("command loop started", "url", url)
 
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+	a.pollCommands(ctx, url, commandPollInterval)
+}
+
+// pollCommands polls url every interval until ctx is cancelled. Consecutive
+// failures back off exponentially per RetryConfig, with jitter, so an
+// unreachable server isn't hammered; the first success resets the cadence.
+func (a *Agent) pollCommands(ctx context.Context, url string, interval time.Duration) {
+	failures := 0
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			a.pollOnce(ctx, url)
+		case <-timer.C:
+		}
+
+		wait := interval
+		if err := a.pollOnce(ctx, url); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			wait = withJitter(a.RetryConfig.Delay(failures))
+			failures++
+			a.Logger.Debug("command poll failed", "error", err, "failures", failures, "retry_in", wait)
+		} else if failures > 0 {
+			a.Logger.Info("command poll recovered", "failures", failures)
+			failures = 0
 		}
+
+		timer.Reset(wait)
 	}
 }
 
-func (a *Agent) pollOnce(ctx context.Context, url string) {
+// pollOnce makes a single long-poll request, dispatching any command it
+// returns. A transport error or unexpected status is returned so the
+// caller can back off.
+func (a *Agent) pollOnce(ctx context.Context, url string) error {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		a.Logger.Error("failed to create command request", "error", err)
-		return
+		return err
 	}
 	a.setHeaders(req)
 	req.Header.Del("Content-Encoding")
 
 	resp, err := a.Client.Do(req)
 	if err != nil {
-		a.Logger.Debug("command poll failed", "error", err)
-		return
+		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusOK {
+	switch resp.StatusCode {
+	case http.StatusOK:
 		var cmd protocol.Command
 		if err := json.NewDecoder(resp.Body).Decode(&cmd); err == nil {
 			go a.handleCommand(ctx, cmd)
 		}
+		return nil
+	case http.StatusNoContent:
+		return nil
+	default:
+		return fmt.Errorf("command poll: %s", resp.Status)
 	}
 }
 
 func (a *Agent) handleCommand(ctx context.Context, cmd protocol.Command) {
 	a.Logger.Info("command received", "type", cmd.Type, "id", cmd.ID)
 
+	// Streaming commands run on their own deadline and upload their own results
+	if cmd.Type == protocol.CmdFollowLogs {
+		a.handleFollowLogs(ctx, cmd)
+		return
+	}
+
 	var resultData any
 	var err error
 
-	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	// The result upload uses the parent ctx so a command that ran out its
+	// deadline can still report the error.
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout(cmd))
 	defer cancel()
 
 	switch cmd.Type {
 	case protocol.CmdFetchLogs:
 		var req protocol.LogRequest
 		if json.Unmarshal(cmd.Payload, &req) == nil {
-			resultData, err = diagnostics.FetchLogs(ctx, req)
+			var logs []protocol.LogEntry
+			logs, err = diagnostics.FetchLogs(ctx, req)
+			if req.Dedup {
+				logs = diagnostics.CollapseRepeats(logs)
+			}
+			resultData = logs
 		} else {
 			err = fmt.Errorf("invalid log request payload")
 		}
@@ -92,7 +156,7 @@ func (a *Agent) handleCommand(ctx context.Context, cmd protocol.Command) {
 				req.TopN = 50
 			}
 
-			resultData, err = diagnostics.RunDiskUsageTop(ctx, targetPath, req.TopN, req.TopN)
+			resultData, err = diagnostics.RunDiskUsageTopConcurrent(ctx, targetPath, req.TopN, req.TopN, req.Workers)
 		}
 
 	case protocol.CmdRestartAgent:
@@ -101,6 +165,9 @@ func (a *Agent) handleCommand(ctx context.Context, cmd protocol.Command) {
 	case protocol.CmdListMounts:
 		resultData = a.DriveCache.ListMounts()
 
+	case protocol.CmdRouteTable:
+		resultData, err = diagnostics.RouteTable(ctx)
+
 	case protocol.CmdNetworkDiag:
 		var req protocol.NetworkRequest
 		if json.Unmarshal(cmd.Payload, &req) == nil {
@@ -109,6 +176,70 @@ func (a *Agent) handleCommand(ctx context.Context, cmd protocol.Command) {
 			err = fmt.Errorf("invalid network request payload")
 		}
 
+	case protocol.CmdCertCheck:
+		var req protocol.CertCheckRequest
+		if json.Unmarshal(cmd.Payload, &req) == nil {
+			resultData, err = diagnostics.CheckCert(ctx, req)
+		} else {
+			err = fmt.Errorf("invalid cert check request payload")
+		}
+
+	case protocol.CmdIOStat:
+		var req protocol.IOStatRequest
+		if len(cmd.Payload) > 0 && json.Unmarshal(cmd.Payload, &req) != nil {
+			err = fmt.Errorf("invalid iostat request payload")
+		} else {
+			resultData, err = diagnostics.RunIOStat(ctx, req)
+		}
+
+	case protocol.CmdTopProcesses:
+		var req protocol.TopProcessesRequest
+		if len(cmd.Payload) > 0 && json.Unmarshal(cmd.Payload, &req) != nil {
+			err = fmt.Errorf("invalid top processes request payload")
+		} else {
+			resultData, err = processes.TopProcesses(ctx, req)
+		}
+
+	case protocol.CmdMTUProbe:
+		var req protocol.MTURequest
+		if json.Unmarshal(cmd.Payload, &req) == nil {
+			resultData, err = diagnostics.RunMTUProbe(ctx, req)
+		} else {
+			err = fmt.Errorf("invalid mtu probe request payload")
+		}
+
+	case protocol.CmdProcFiles:
+		var req protocol.ProcFilesRequest
+		if json.Unmarshal(cmd.Payload, &req) == nil {
+			resultData, err = diagnostics.ProcFiles(ctx, req)
+		} else {
+			err = fmt.Errorf("invalid proc files request payload")
+		}
+
+	case protocol.CmdMountLatency:
+		var req protocol.MountLatencyRequest
+		if len(cmd.Payload) > 0 && json.Unmarshal(cmd.Payload, &req) != nil {
+			err = fmt.Errorf("invalid mount latency request payload")
+		} else {
+			mountpoints := []string{req.Mountpoint}
+			if req.Mountpoint == "" {
+				mountpoints = mountpoints[:0]
+				for _, m := range a.DriveCache.ListMounts() {
+					mountpoints = append(mountpoints, m.Mountpoint)
+				}
+			}
+			resultData, err = diagnostics.RunMountLatency(ctx, mountpoints, req)
+		}
+
+	case protocol.CmdThroughput:
+		var req protocol.ThroughputRequest
+		if len(cmd.Payload) > 0 && json.Unmarshal(cmd.Payload, &req) != nil {
+			err = fmt.Errorf("invalid throughput request payload")
+		} else {
+			url := fmt.Sprintf("%s/api/v1/agent/throughput", a.Config.BaseURL)
+			resultData, err = diagnostics.RunThroughput(ctx, a.Client, url, req, a.setHeaders)
+		}
+
 	case protocol.CmdUpdateAgent:
 		var req protocol.UpdateAgentRequest
 		if json.Unmarshal(cmd.Payload, &req) == nil {
@@ -117,11 +248,19 @@ func (a *Agent) handleCommand(ctx context.Context, cmd protocol.Command) {
 			err = fmt.Errorf("invalid update request payload")
 		}
 
+	case protocol.CmdCancel:
+		var req protocol.CancelRequest
+		if json.Unmarshal(cmd.Payload, &req) == nil {
+			err = a.cancelStream(req.CommandID)
+		} else {
+			err = fmt.Errorf("invalid cancel request payload")
+		}
+
 	default:
 		err = fmt.Errorf("unknown command type: %s", cmd.Type)
 	}
 
-	if uploadErr := a.uploadCommandResult(ctx, cmd, resultData, err); uploadErr != nil {
+	if uploadErr := a.uploadCommandResult(parent, cmd, resultData, err); uploadErr != nil {
 		a.Logger.Error("failed to upload command result", "command_id", cmd.ID, "error", uploadErr)
 	}
 }
@@ -144,6 +283,11 @@ func (a *Agent) uploadCommandResult(ctx context.Context, cmd protocol.Command, d
 		}
 	}
 
+	return a.postCommandResult(ctx, res)
+}
+
+// postCommandResult compresses and sends a single result to the server.
+func (a *Agent) postCommandResult(ctx context.Context, res protocol.CommandResult) error {
 	var payload []byte
 	var compressedSize int
 
@@ -190,7 +334,7 @@ func (a *Agent) uploadCommandResult(ctx context.Context, cmd protocol.Command, d
 		return fmt.Errorf("server rejected result (%s): %s", resp.Status, string(body))
 	}
 
-	a.Logger.Debug("command result uploaded", "command_id", cmd.ID, "compressed_bytes", compressedSize)
+	a.Logger.Debug("command result uploaded", "command_id", res.ID, "compressed_bytes", compressedSize)
 	return nil
 }
 
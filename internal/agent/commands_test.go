@@ -5,8 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -24,7 +27,9 @@ func TestPollOnce_NoCommand(t *testing.T) {
 	a.Config.BaseURL = srv.URL
 	a.Config.CommandPath = "/api/v1/agent/command"
 
-	a.pollOnce(context.Background(), srv.URL+"/api/v1/agent/command")
+	if err := a.pollOnce(context.Background(), srv.URL+"/api/v1/agent/command"); err != nil {
+		t.Errorf("204 should not be an error: %v", err)
+	}
 }
 
 func TestPollOnce_ReceivesCommand(t *testing.T) {
@@ -53,8 +58,9 @@ func TestPollOnce_ReceivesCommand(t *testing.T) {
 func TestPollOnce_ServerDown(t *testing.T) {
 	a := newTestAgentWithLogger()
 
-	// Should not panic
-	a.pollOnce(context.Background(), "http://127.0.0.1:1/api/v1/agent/command")
+	if err := a.pollOnce(context.Background(), "http://127.0.0.1:1/api/v1/agent/command"); err == nil {
+		t.Error("expected an error when the server is down")
+	}
 }
 
 func TestPollOnce_ServerError(t *testing.T) {
@@ -65,8 +71,72 @@ func TestPollOnce_ServerError(t *testing.T) {
 
 	a := newTestAgentWithLogger()
 
-	// Should not panic — non-200 is silently ignored
-	a.pollOnce(context.Background(), srv.URL+"/api/v1/agent/command")
+	// Non-200 is reported so the loop can back off
+	if err := a.pollOnce(context.Background(), srv.URL+"/api/v1/agent/command"); err == nil {
+		t.Error("expected an error for a 500 response")
+	}
+}
+
+func TestPollCommands_BacksOffAndResets(t *testing.T) {
+	var mu sync.Mutex
+	var arrivals []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		n := len(arrivals)
+		mu.Unlock()
+
+		// Down for the first three polls, then back
+		if n <= 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	a := newTestAgentWithLogger()
+	a.RetryConfig = RetryConfig{InitialDelay: 40 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.pollCommands(ctx, srv.URL+"/api/v1/agent/command", 5*time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.After(2 * time.Second)
+	for {
+		mu.Lock()
+		n := len(arrivals)
+		mu.Unlock()
+		if n >= 6 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("only %d polls before deadline", n)
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	gap := func(i int) time.Duration { return arrivals[i].Sub(arrivals[i-1]) }
+
+	// Failure backoff: ~40ms, ~80ms, ~160ms (+/-25%)
+	if gap(1) < 30*time.Millisecond {
+		t.Errorf("first backoff too short: %v", gap(1))
+	}
+	if gap(2) <= gap(1) || gap(3) <= gap(2) {
+		t.Errorf("backoff should grow: %v, %v, %v", gap(1), gap(2), gap(3))
+	}
+	// After the first success the normal interval applies again
+	if gap(5) >= gap(1) {
+		t.Errorf("backoff should reset after success: gap %v, first backoff %v", gap(5), gap(1))
+	}
 }
 
 func TestPollOnce_InvalidJSON(t *testing.T) {
@@ -311,6 +381,40 @@ func TestHandleCommand_ListMounts(t *testing.T) {
 	}
 }
 
+func TestHandleCommand_TopProcesses(t *testing.T) {
+	var received protocol.CommandResult
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gz, _ := gzip.NewReader(r.Body)
+		json.NewDecoder(gz).Decode(&received)
+		gz.Close()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	a := newTestAgentWithLogger()
+	a.Config.BaseURL = srv.URL
+
+	payload, _ := json.Marshal(protocol.TopProcessesRequest{SortBy: protocol.SortByMemory, Limit: 3})
+	cmd := protocol.Command{ID: "cmd-top", Type: protocol.CmdTopProcesses, Payload: payload}
+	a.handleCommand(context.Background(), cmd)
+
+	if received.Error != "" {
+		t.Fatalf("unexpected error: %s", received.Error)
+	}
+	var list protocol.ProcessListMetric
+	if err := json.Unmarshal(received.Payload, &list); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if len(list.Processes) == 0 || len(list.Processes) > 3 {
+		t.Fatalf("got %d processes, want 1-3", len(list.Processes))
+	}
+	for i := 1; i < len(list.Processes); i++ {
+		if list.Processes[i].MemRSS > list.Processes[i-1].MemRSS {
+			t.Errorf("not sorted by memory: %d > %d at %d", list.Processes[i].MemRSS, list.Processes[i-1].MemRSS, i)
+		}
+	}
+}
+
 func TestHandleCommand_ContextTimeout(t *testing.T) {
 	var received protocol.CommandResult
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -333,3 +437,58 @@ func TestHandleCommand_ContextTimeout(t *testing.T) {
 
 	// Should still attempt to upload the result
 }
+
+func TestCommandTimeout(t *testing.T) {
+	tests := []struct {
+		timeout int
+		want    time.Duration
+	}{
+		{0, defaultCommandTimeout},
+		{-10, defaultCommandTimeout},
+		{5, 5 * time.Second},
+		{300, 300 * time.Second},
+		{math.MaxInt32, maxCommandTimeout},
+	}
+
+	for _, tt := range tests {
+		got := commandTimeout(protocol.Command{Timeout: tt.timeout})
+		if got != tt.want {
+			t.Errorf("commandTimeout(%d) = %v, want %v", tt.timeout, got, tt.want)
+		}
+	}
+}
+
+func TestHandleCommand_HonorsTimeout(t *testing.T) {
+	var received protocol.CommandResult
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/agent/throughput" {
+			// Never respond; only the command deadline ends the request
+			<-r.Context().Done()
+			return
+		}
+		gz, _ := gzip.NewReader(r.Body)
+		json.NewDecoder(gz).Decode(&received)
+		gz.Close()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	a := newTestAgentWithLogger()
+	a.Config.BaseURL = srv.URL
+
+	cmd := protocol.Command{ID: "cmd-deadline", Type: protocol.CmdThroughput, Timeout: 1}
+
+	start := time.Now()
+	a.handleCommand(context.Background(), cmd)
+	elapsed := time.Since(start)
+
+	if elapsed > 5*time.Second {
+		t.Errorf("command ran %v, want about 1s", elapsed)
+	}
+	if received.ID != "cmd-deadline" {
+		t.Fatalf("result not uploaded after deadline: %+v", received)
+	}
+	if !strings.Contains(received.Error, "deadline exceeded") {
+		t.Errorf("Error = %q, want deadline exceeded", received.Error)
+	}
+}
@@ -2,8 +2,11 @@ package agent
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/nhdewitt/spectra/internal/fileutil"
@@ -13,11 +16,72 @@ import (
 // After registration, Token is cleared and AgentID/Secret are written.
 type fileConfig struct {
 	Server        string `json:"server"`
+	Hostname      string `json:"hostname,omitempty"`
 	Token         string `json:"token,omitempty"`
 	AgentID       string `json:"agent_id,omitempty"`
 	Secret        string `json:"secret,omitempty"`
 	CACert        string `json:"ca_cert,omitempty"`
 	TLSSkipVerify bool   `json:"tls_skip_verify,omitempty"`
+	LogFile       string `json:"log_file,omitempty"`
+	LogLevel      string `json:"log_level,omitempty"`
+	Encoding      string `json:"encoding,omitempty"`
+	MetricsListen string `json:"metrics_listen,omitempty"`
+
+	// DedupHeartbeat enables suppression of unchanged metrics; see
+	// Config.DedupHeartbeat.
+	DedupHeartbeat Duration `json:"dedup_heartbeat,omitempty"`
+
+	Tags       []string                   `json:"tags,omitempty"`
+	Collectors map[string]CollectorConfig `json:"collectors,omitempty"`
+}
+
+// CollectorConfig overrides the defaults of a single collector, keyed
+// by collector name ("cpu", "processes", ...). A nil Enabled keeps the
+// collector enabled; a zero Interval keeps its default interval. TopN
+// only applies to "processes": it keeps the N busiest by CPU plus the N
+// largest by memory, and zero keeps them all. Accounting only applies to
+// "services": it adds per-unit cgroup memory and CPU usage. MaxTemp only
+// applies to "temperature": sensor max readings at or above it are
+// dropped as bogus, and zero keeps the 200°C default.
+type CollectorConfig struct {
+	Enabled    *bool    `json:"enabled,omitempty"`
+	Interval   Duration `json:"interval,omitempty"`
+	TopN       int      `json:"top_n,omitempty"`
+	Accounting bool     `json:"accounting,omitempty"`
+	MaxTemp    float64  `json:"max_temp,omitempty"`
+}
+
+// Duration is a time.Duration that reads and writes as a Go duration
+// string ("30s", "5m") in the config file.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	if v < 0 {
+		return fmt.Errorf("duration must not be negative: %s", s)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// ConfigPathFromEnv returns the config file location: SPECTRA_CONFIG
+// if set, otherwise the OS default.
+func ConfigPathFromEnv() string {
+	if p := os.Getenv("SPECTRA_CONFIG"); p != "" {
+		return p
+	}
+	return DefaultConfigPath()
 }
 
 // DefaultConfigPath returns the OS-appropriate config file location.
@@ -48,11 +112,18 @@ func LoadConfig(path string) (*Config, error) {
 	}
 
 	cfg := &Config{
-		BaseURL:      fc.Server,
-		MetricsPath:  "/api/v1/agent/metrics",
-		CommandPath:  "/api/v1/agent/command",
-		PollInterval: 5 * time.Second,
-		ConfigPath:   path,
+		BaseURL:       fc.Server,
+		Hostname:      fc.Hostname,
+		MetricsPath:   "/api/v1/agent/metrics",
+		CommandPath:   "/api/v1/agent/command",
+		PollInterval:  5 * time.Second,
+		ConfigPath:    path,
+		LogFile:       fc.LogFile,
+		LogLevel:      fc.LogLevel,
+		Encoding:      fc.Encoding,
+		MetricsListen: fc.MetricsListen,
+
+		DedupHeartbeat: time.Duration(fc.DedupHeartbeat),
 	}
 
 	if fc.AgentID != "" && fc.Secret != "" {
@@ -64,6 +135,8 @@ func LoadConfig(path string) (*Config, error) {
 
 	cfg.CACert = fc.CACert
 	cfg.TLSSkipVerify = fc.TLSSkipVerify
+	cfg.Collectors = fc.Collectors
+	cfg.Tags = fc.Tags
 
 	return cfg, nil
 }
@@ -71,17 +144,72 @@ func LoadConfig(path string) (*Config, error) {
 // ConfigFromEnv builds a Config from environment variables.
 // Backwards-compatible with the original env-based setup.
 func ConfigFromEnv() *Config {
-	baseURL := os.Getenv("SPECTRA_SERVER")
-	if baseURL == "" {
-		baseURL = "http://127.0.0.1:8080"
-	}
-
-	return &Config{
-		BaseURL:      baseURL,
+	cfg := &Config{
+		BaseURL:      "http://127.0.0.1:8080",
 		MetricsPath:  "/api/v1/agent/metrics",
 		CommandPath:  "/api/v1/agent/command",
 		PollInterval: 5 * time.Second,
 	}
+	ApplyEnv(cfg)
+	return cfg
+}
+
+// ApplyEnv overrides cfg with any SPECTRA_* environment variables that
+// are set, so env always takes precedence over the config file.
+func ApplyEnv(cfg *Config) {
+	if v := os.Getenv("SPECTRA_SERVER"); v != "" {
+		cfg.BaseURL = v
+	}
+	if v := os.Getenv("SPECTRA_HOSTNAME"); v != "" {
+		cfg.Hostname = v
+	}
+	if v := os.Getenv("SPECTRA_TOKEN"); v != "" {
+		cfg.RegistrationToken = v
+	}
+	if v := os.Getenv("SPECTRA_CA_CERT"); v != "" {
+		cfg.CACert = v
+	}
+	if v := os.Getenv("SPECTRA_TLS_SKIP_VERIFY"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.TLSSkipVerify = b
+		}
+	}
+	if v := os.Getenv("SPECTRA_LOG_FILE"); v != "" {
+		cfg.LogFile = v
+	}
+	if v := os.Getenv("SPECTRA_LOG_LEVEL"); v != "" {
+		cfg.LogLevel = v
+	}
+	if v := os.Getenv("SPECTRA_ENCODING"); v != "" {
+		cfg.Encoding = v
+	}
+	if v := os.Getenv("SPECTRA_DEDUP_HEARTBEAT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.DedupHeartbeat = d
+		}
+	}
+	if v := os.Getenv("SPECTRA_TAGS"); v != "" {
+		cfg.Tags = splitTags(v)
+	}
+	if v := os.Getenv("SPECTRA_METRICS_LISTEN"); v != "" {
+		cfg.MetricsListen = v
+	}
+	if v := os.Getenv("SPECTRA_DRY_RUN"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.DryRun = b
+		}
+	}
+}
+
+// splitTags parses a comma-separated tag list, dropping empty entries.
+func splitTags(s string) []string {
+	var tags []string
+	for t := range strings.SplitSeq(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
 }
 
 // SaveCredentials writes the permanent agent_id+secret back to
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"testing"
 	"time"
 )
@@ -77,6 +78,57 @@ func TestLoadConfig(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "collector overrides",
+			fileContent: `{
+				"server": "https://api.example.com",
+				"collectors": {
+					"processes": {"enabled": false},
+					"services": {"interval": "2m"}
+				}
+			}`,
+			expectedError: false,
+			checkConfig: func(t *testing.T, cfg *Config) {
+				p, ok := cfg.Collectors["processes"]
+				if !ok || p.Enabled == nil || *p.Enabled {
+					t.Errorf("expected processes disabled, got %+v", p)
+				}
+				if got := time.Duration(cfg.Collectors["services"].Interval); got != 2*time.Minute {
+					t.Errorf("expected services interval 2m, got %v", got)
+				}
+			},
+		},
+		{
+			name: "processes top_n",
+			fileContent: `{
+				"server": "https://api.example.com",
+				"collectors": {"processes": {"top_n": 25}}
+			}`,
+			expectedError: false,
+			checkConfig: func(t *testing.T, cfg *Config) {
+				if got := cfg.Collectors["processes"].TopN; got != 25 {
+					t.Errorf("expected processes top_n 25, got %d", got)
+				}
+			},
+		},
+		{
+			name: "services accounting",
+			fileContent: `{
+				"server": "https://api.example.com",
+				"collectors": {"services": {"accounting": true}}
+			}`,
+			expectedError: false,
+			checkConfig: func(t *testing.T, cfg *Config) {
+				if !cfg.Collectors["services"].Accounting {
+					t.Errorf("expected services accounting enabled, got %+v", cfg.Collectors["services"])
+				}
+			},
+		},
+		{
+			name:          "invalid collector interval",
+			fileContent:   `{"server": "https://api.example.com", "collectors": {"cpu": {"interval": "fast"}}}`,
+			expectedError: true,
+		},
 		{
 			name:          "file does not exist",
 			fileContent:   "", // won't be written
@@ -163,6 +215,72 @@ func TestConfigFromEnv(t *testing.T) {
 	})
 }
 
+func TestConfigPathFromEnv(t *testing.T) {
+	t.Setenv("SPECTRA_CONFIG", "")
+	if got := ConfigPathFromEnv(); got != DefaultConfigPath() {
+		t.Errorf("expected default path %q, got %q", DefaultConfigPath(), got)
+	}
+
+	t.Setenv("SPECTRA_CONFIG", "/opt/spectra/agent.json")
+	if got := ConfigPathFromEnv(); got != "/opt/spectra/agent.json" {
+		t.Errorf("expected SPECTRA_CONFIG path, got %q", got)
+	}
+}
+
+func TestLoadConfig_EnvPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.json")
+	content := `{
+		"server": "https://file.example.com",
+		"hostname": "file-host",
+		"token": "file-token",
+		"ca_cert": "/etc/spectra/ca.pem",
+		"log_level": "info",
+		"collectors": {"wifi": {"enabled": false}}
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	t.Setenv("SPECTRA_SERVER", "https://env.example.com")
+	t.Setenv("SPECTRA_HOSTNAME", "")
+	t.Setenv("SPECTRA_TOKEN", "")
+	t.Setenv("SPECTRA_CA_CERT", "")
+	t.Setenv("SPECTRA_TLS_SKIP_VERIFY", "true")
+	t.Setenv("SPECTRA_LOG_FILE", "")
+	t.Setenv("SPECTRA_LOG_LEVEL", "debug")
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	ApplyEnv(cfg)
+
+	// Overridden by env
+	if cfg.BaseURL != "https://env.example.com" {
+		t.Errorf("BaseURL: got %q, want env value", cfg.BaseURL)
+	}
+	if !cfg.TLSSkipVerify {
+		t.Error("TLSSkipVerify: expected true from env")
+	}
+	if cfg.LogLevel != "debug" {
+		t.Errorf("LogLevel: got %q, want debug", cfg.LogLevel)
+	}
+
+	// Kept from file
+	if cfg.Hostname != "file-host" {
+		t.Errorf("Hostname: got %q, want file-host", cfg.Hostname)
+	}
+	if cfg.RegistrationToken != "file-token" {
+		t.Errorf("RegistrationToken: got %q, want file-token", cfg.RegistrationToken)
+	}
+	if cfg.CACert != "/etc/spectra/ca.pem" {
+		t.Errorf("CACert: got %q, want /etc/spectra/ca.pem", cfg.CACert)
+	}
+	if _, ok := cfg.Collectors["wifi"]; !ok {
+		t.Error("Collectors: expected wifi override from file")
+	}
+}
+
 func TestSaveCredentials(t *testing.T) {
 	tempDir := t.TempDir()
 	filePath := filepath.Join(tempDir, "test_save.json")
@@ -196,3 +314,57 @@ func TestSaveCredentials(t *testing.T) {
 		t.Error("expected Token to be empty")
 	}
 }
+
+func TestApplyEnv_Tags(t *testing.T) {
+	cfg := &Config{Tags: []string{"from-file"}}
+
+	t.Setenv("SPECTRA_TAGS", " prod, rack-2 ,,")
+	ApplyEnv(cfg)
+
+	want := []string{"prod", "rack-2"}
+	if !slices.Equal(cfg.Tags, want) {
+		t.Errorf("Tags: got %v, want %v", cfg.Tags, want)
+	}
+}
+
+func TestDedupHeartbeat_FileAndEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.json")
+	if err := os.WriteFile(path, []byte(`{"server": "http://s", "dedup_heartbeat": "5m"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.DedupHeartbeat != 5*time.Minute {
+		t.Errorf("file: got %v, want 5m", cfg.DedupHeartbeat)
+	}
+
+	t.Setenv("SPECTRA_DEDUP_HEARTBEAT", "90s")
+	ApplyEnv(cfg)
+	if cfg.DedupHeartbeat != 90*time.Second {
+		t.Errorf("env: got %v, want 90s", cfg.DedupHeartbeat)
+	}
+}
+
+func TestMetricsListen_FileAndEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.json")
+	if err := os.WriteFile(path, []byte(`{"server": "http://s", "metrics_listen": "127.0.0.1:9273"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.MetricsListen != "127.0.0.1:9273" {
+		t.Errorf("file: got %q, want 127.0.0.1:9273", cfg.MetricsListen)
+	}
+
+	t.Setenv("SPECTRA_METRICS_LISTEN", ":9100")
+	ApplyEnv(cfg)
+	if cfg.MetricsListen != ":9100" {
+		t.Errorf("env: got %q, want :9100", cfg.MetricsListen)
+	}
+}
@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// metricDeduper suppresses envelopes whose payload is byte-identical to
+// the last one sent for the same metric, but lets one through at least
+// every heartbeat so the server still sees the metric is alive. It is
+// used only from the sender goroutine.
+type metricDeduper struct {
+	heartbeat time.Duration
+	last      map[string]dedupEntry
+	now       func() time.Time
+
+	suppressed int
+}
+
+type dedupEntry struct {
+	sum  [sha256.Size]byte
+	sent time.Time
+}
+
+// newMetricDeduper returns nil when heartbeat is zero, which disables
+// deduplication.
+func newMetricDeduper(heartbeat time.Duration) *metricDeduper {
+	if heartbeat <= 0 {
+		return nil
+	}
+	return &metricDeduper{
+		heartbeat: heartbeat,
+		last:      make(map[string]dedupEntry),
+		now:       time.Now,
+	}
+}
+
+// shouldSend reports whether env should go out, recording it as sent if
+// so.
+func (d *metricDeduper) shouldSend(env protocol.Envelope) bool {
+	data, err := json.Marshal(env.Data)
+	if err != nil {
+		return true
+	}
+	sum := sha256.Sum256(data)
+	key := env.Type + "\x00" + metricIdentity(env.Data)
+	now := d.now()
+
+	if prev, ok := d.last[key]; ok && prev.sum == sum && now.Sub(prev.sent) < d.heartbeat {
+		d.suppressed++
+		return false
+	}
+
+	d.last[key] = dedupEntry{sum: sum, sent: now}
+	return true
+}
+
+// metricIdentity distinguishes instances of metric types a collector
+// emits several of per cycle (one per disk, interface, sensor...).
+// Singleton types return "".
+func metricIdentity(m protocol.Metric) string {
+	switch v := m.(type) {
+	case protocol.DiskMetric:
+		return v.Device + "\x00" + v.Mountpoint
+	case protocol.DiskIOMetric:
+		return v.Device
+	case protocol.NetworkMetric:
+		return v.Interface
+	case protocol.TemperatureMetric:
+		return v.Sensor
+	case protocol.WiFiMetric:
+		return v.Interface
+	case protocol.ContainerMetric:
+		return v.Source + "\x00" + v.ID
+	case protocol.ServiceMetric:
+		return v.Name
+	case protocol.SensorMetric:
+		return v.Source + "\x00" + v.Chip + "\x00" + v.Name
+	case protocol.PowerDrawMetric:
+		return v.Domain
+	case protocol.SwapDeviceMetric:
+		return v.Name
+	case protocol.GPUProcessMetric:
+		return v.GPU + "\x00" + strconv.Itoa(v.PID)
+	case protocol.CollectorHealthMetric:
+		return v.Name
+	case *protocol.CollectorHealthMetric:
+		// Collector.RunNamed sends health as a pointer
+		return v.Name
+	}
+	return ""
+}
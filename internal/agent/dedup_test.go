@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestMetricDeduper_SuppressesUntilHeartbeat(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	d := newMetricDeduper(time.Minute)
+	d.now = func() time.Time { return now }
+
+	env := protocol.Envelope{Type: "dmi", Data: protocol.DMIMetric{SysVendor: "LENOVO"}}
+
+	if !d.shouldSend(env) {
+		t.Fatal("first envelope should be sent")
+	}
+	now = now.Add(30 * time.Second)
+	if d.shouldSend(env) {
+		t.Error("identical envelope within heartbeat should be suppressed")
+	}
+	if d.suppressed != 1 {
+		t.Errorf("suppressed: got %d, want 1", d.suppressed)
+	}
+
+	now = now.Add(30 * time.Second)
+	if !d.shouldSend(env) {
+		t.Error("identical envelope should be resent once the heartbeat elapses")
+	}
+}
+
+func TestMetricDeduper_SendsChanges(t *testing.T) {
+	d := newMetricDeduper(time.Hour)
+
+	a := protocol.Envelope{Type: "system", Data: protocol.SystemMetric{Processes: 100}}
+	b := protocol.Envelope{Type: "system", Data: protocol.SystemMetric{Processes: 101}}
+
+	for i, env := range []protocol.Envelope{a, b, a} {
+		if !d.shouldSend(env) {
+			t.Errorf("envelope %d changed from the last one sent and should go out", i)
+		}
+	}
+}
+
+func TestMetricDeduper_PerIdentity(t *testing.T) {
+	d := newMetricDeduper(time.Hour)
+
+	eth0 := protocol.Envelope{Type: "network", Data: protocol.NetworkMetric{Interface: "eth0"}}
+	wlan0 := protocol.Envelope{Type: "network", Data: protocol.NetworkMetric{Interface: "wlan0"}}
+
+	for _, env := range []protocol.Envelope{eth0, wlan0} {
+		if !d.shouldSend(env) {
+			t.Errorf("first %v should be sent", env.Data)
+		}
+	}
+	for _, env := range []protocol.Envelope{eth0, wlan0} {
+		if d.shouldSend(env) {
+			t.Errorf("repeat of %v should be suppressed", env.Data)
+		}
+	}
+}
+
+func TestMetricDeduper_PerIdentityEmittedForms(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b protocol.Envelope
+	}{
+		{
+			// Collector.RunNamed emits health as a pointer
+			name: "collector health",
+			a:    protocol.Envelope{Type: protocol.TypeCollectorHealth, Data: &protocol.CollectorHealthMetric{Name: "cpu"}},
+			b:    protocol.Envelope{Type: protocol.TypeCollectorHealth, Data: &protocol.CollectorHealthMetric{Name: "disk"}},
+		},
+		{
+			name: "gpu processes",
+			a:    protocol.Envelope{Type: protocol.TypeGPUProcess, Data: protocol.GPUProcessMetric{GPU: "GPU-1", PID: 100, Name: "python"}},
+			b:    protocol.Envelope{Type: protocol.TypeGPUProcess, Data: protocol.GPUProcessMetric{GPU: "GPU-1", PID: 200, Name: "python"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if metricIdentity(tt.a.Data) == metricIdentity(tt.b.Data) {
+				t.Fatalf("distinct instances share identity %q", metricIdentity(tt.a.Data))
+			}
+
+			d := newMetricDeduper(time.Hour)
+			for _, env := range []protocol.Envelope{tt.a, tt.b} {
+				if !d.shouldSend(env) {
+					t.Errorf("first %+v should be sent", env.Data)
+				}
+			}
+			for _, env := range []protocol.Envelope{tt.a, tt.b} {
+				if d.shouldSend(env) {
+					t.Errorf("repeat of %+v should be suppressed", env.Data)
+				}
+			}
+		})
+	}
+}
+
+func TestNewMetricDeduper_Disabled(t *testing.T) {
+	if d := newMetricDeduper(0); d != nil {
+		t.Error("zero heartbeat should disable dedup")
+	}
+}
@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// runDryRun replaces the metric sender when Config.DryRun is set. Each
+// envelope is marshaled exactly as it would be for upload and written
+// to w as indented JSON; nothing is sent to the server.
+func (a *Agent) runDryRun(ctx context.Context, w io.Writer) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	write := func(env any) {
+		if err := enc.Encode(env); err != nil {
+			a.Logger.Warn("dry-run encode failed", "error", err)
+		}
+	}
+
+	for {
+		select {
+		case envelope, ok := <-a.metricsCh:
+			if !ok {
+				return
+			}
+			write(envelope)
+
+		case <-ctx.Done():
+			// Drain whatever the collectors already produced, once they
+			// have all stopped
+			a.collectors.Wait()
+			for {
+				select {
+				case envelope := <-a.metricsCh:
+					write(envelope)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
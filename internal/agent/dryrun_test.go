@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/logging"
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestRunDryRun_WritesEnvelopes(t *testing.T) {
+	a := New(Config{Hostname: "dry-host", IdentityPath: filepath.Join(t.TempDir(), "agent-id.json")})
+	a.Logger = logging.NewDiscard()
+
+	a.metricsCh <- protocol.Envelope{
+		Type:      "cpu",
+		Timestamp: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Hostname:  "dry-host",
+		Data:      protocol.CPUMetric{Usage: 42.5},
+	}
+	a.metricsCh <- protocol.Envelope{
+		Type:      "memory",
+		Timestamp: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Hostname:  "dry-host",
+		Data:      protocol.MemoryMetric{Total: 1024},
+	}
+
+	var buf bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // drain and return
+
+	a.runDryRun(ctx, &buf)
+
+	dec := json.NewDecoder(&buf)
+	var got []map[string]any
+	for dec.More() {
+		var env map[string]any
+		if err := dec.Decode(&env); err != nil {
+			t.Fatalf("output is not valid JSON: %v", err)
+		}
+		got = append(got, env)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 envelopes, got %d", len(got))
+	}
+	if got[0]["type"] != "cpu" || got[1]["type"] != "memory" {
+		t.Errorf("unexpected types: %v, %v", got[0]["type"], got[1]["type"])
+	}
+	data, ok := got[0]["data"].(map[string]any)
+	if !ok || data["usage"] != 42.5 {
+		t.Errorf("expected cpu data with usage 42.5, got %v", got[0]["data"])
+	}
+}
+
+func TestRunDryRun_ClosedChannel(t *testing.T) {
+	a := New(Config{IdentityPath: filepath.Join(t.TempDir(), "agent-id.json")})
+	close(a.metricsCh)
+
+	done := make(chan struct{})
+	go func() {
+		a.runDryRun(context.Background(), &bytes.Buffer{})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("runDryRun did not return on closed channel")
+	}
+}
+
+func TestApplyEnv_DryRun(t *testing.T) {
+	t.Setenv("SPECTRA_DRY_RUN", "1")
+	cfg := ConfigFromEnv()
+	if !cfg.DryRun {
+		t.Error("expected DryRun from SPECTRA_DRY_RUN=1")
+	}
+}
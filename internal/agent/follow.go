@@ -0,0 +1,139 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/diagnostics"
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const (
+	defaultFollowDuration = time.Minute
+	// maxFollowDuration stays under the server's command result TTL.
+	maxFollowDuration   = 5 * time.Minute
+	followFlushInterval = 2 * time.Second
+	followBatchSize     = 200
+)
+
+// followLogs is the log source for CmdFollowLogs. Tests replace it with a fake.
+var followLogs = diagnostics.FollowLogs
+
+// followDuration clamps a requested follow duration to the allowed range.
+func followDuration(seconds int) time.Duration {
+	if seconds <= 0 {
+		return defaultFollowDuration
+	}
+	return min(time.Duration(seconds)*time.Second, maxFollowDuration)
+}
+
+// handleFollowLogs streams new log entries back as partial results, batched
+// by size or flush interval, until the duration elapses, the command is
+// cancelled or the source ends. The final result carries any remaining
+// entries and has Partial unset.
+func (a *Agent) handleFollowLogs(ctx context.Context, cmd protocol.Command) {
+	var req protocol.FollowLogsRequest
+	if err := json.Unmarshal(cmd.Payload, &req); err != nil {
+		a.finishStream(ctx, cmd, nil, fmt.Errorf("invalid follow logs request payload"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, followDuration(req.DurationSeconds))
+	defer cancel()
+
+	a.trackStream(cmd.ID, cancel)
+	defer a.untrackStream(cmd.ID)
+
+	entries, err := followLogs(ctx, req.MinLevel)
+	if err != nil {
+		a.finishStream(ctx, cmd, nil, err)
+		return
+	}
+
+	ticker := time.NewTicker(followFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]protocol.LogEntry, 0, followBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := a.uploadPartialResult(ctx, cmd, batch); err != nil {
+			a.Logger.Warn("failed to upload partial result", "command_id", cmd.ID, "error", err)
+		}
+		batch = batch[:0]
+	}
+
+loop:
+	for {
+		select {
+		case e, ok := <-entries:
+			if !ok {
+				break loop
+			}
+			batch = append(batch, e)
+			if len(batch) >= followBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			break loop
+		}
+	}
+
+	a.finishStream(ctx, cmd, batch, nil)
+}
+
+// finishStream uploads the final result of a streaming command. It runs
+// detached from ctx, which has usually expired by the time a stream ends.
+func (a *Agent) finishStream(ctx context.Context, cmd protocol.Command, data any, cmdErr error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	if err := a.uploadCommandResult(ctx, cmd, data, cmdErr); err != nil {
+		a.Logger.Error("failed to upload command result", "command_id", cmd.ID, "error", err)
+	}
+}
+
+func (a *Agent) uploadPartialResult(ctx context.Context, cmd protocol.Command, entries []protocol.LogEntry) error {
+	raw, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return a.postCommandResult(ctx, protocol.CommandResult{
+		ID:      cmd.ID,
+		Type:    cmd.Type,
+		Payload: raw,
+		Partial: true,
+	})
+}
+
+func (a *Agent) trackStream(id string, cancel context.CancelFunc) {
+	a.streamsMu.Lock()
+	defer a.streamsMu.Unlock()
+	if a.streams == nil {
+		a.streams = make(map[string]context.CancelFunc)
+	}
+	a.streams[id] = cancel
+}
+
+func (a *Agent) untrackStream(id string) {
+	a.streamsMu.Lock()
+	defer a.streamsMu.Unlock()
+	delete(a.streams, id)
+}
+
+// cancelStream stops the streaming command with the given ID.
+func (a *Agent) cancelStream(id string) error {
+	a.streamsMu.Lock()
+	defer a.streamsMu.Unlock()
+	cancel, ok := a.streams[id]
+	if !ok {
+		return fmt.Errorf("no running command %q", id)
+	}
+	cancel()
+	return nil
+}
@@ -0,0 +1,192 @@
+package agent
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// resultRecorder collects the command results posted to a test server.
+type resultRecorder struct {
+	mu      sync.Mutex
+	results []protocol.CommandResult
+}
+
+func (rr *resultRecorder) handler(w http.ResponseWriter, r *http.Request) {
+	var res protocol.CommandResult
+	gz, _ := gzip.NewReader(r.Body)
+	json.NewDecoder(gz).Decode(&res)
+	gz.Close()
+
+	rr.mu.Lock()
+	rr.results = append(rr.results, res)
+	rr.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (rr *resultRecorder) snapshot() []protocol.CommandResult {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	return append([]protocol.CommandResult(nil), rr.results...)
+}
+
+func stubFollowLogs(t *testing.T, fn func(context.Context, protocol.LogLevel) (<-chan protocol.LogEntry, error)) {
+	t.Helper()
+	orig := followLogs
+	followLogs = fn
+	t.Cleanup(func() { followLogs = orig })
+}
+
+func TestHandleCommand_FollowLogs(t *testing.T) {
+	var rr resultRecorder
+	srv := httptest.NewServer(http.HandlerFunc(rr.handler))
+	defer srv.Close()
+
+	var gotLevel protocol.LogLevel
+	stubFollowLogs(t, func(ctx context.Context, minLevel protocol.LogLevel) (<-chan protocol.LogEntry, error) {
+		gotLevel = minLevel
+		out := make(chan protocol.LogEntry)
+		go func() {
+			defer close(out)
+			for _, msg := range []string{"one", "two", "three"} {
+				out <- protocol.LogEntry{Source: "fake", Level: protocol.LevelWarning, Message: msg}
+			}
+		}()
+		return out, nil
+	})
+
+	a := newTestAgentWithLogger()
+	a.Config.BaseURL = srv.URL
+
+	payload, _ := json.Marshal(protocol.FollowLogsRequest{MinLevel: protocol.LevelWarning, DurationSeconds: 5})
+	a.handleCommand(context.Background(), protocol.Command{ID: "cmd-follow", Type: protocol.CmdFollowLogs, Payload: payload})
+
+	if gotLevel != protocol.LevelWarning {
+		t.Errorf("min level: got %q, want WARNING", gotLevel)
+	}
+
+	results := rr.snapshot()
+	if len(results) == 0 {
+		t.Fatal("expected at least one result")
+	}
+
+	var msgs []string
+	for i, res := range results {
+		if res.ID != "cmd-follow" || res.Type != protocol.CmdFollowLogs {
+			t.Errorf("result %d: got %s/%s", i, res.ID, res.Type)
+		}
+		if res.Error != "" {
+			t.Errorf("result %d: unexpected error %q", i, res.Error)
+		}
+		if last := i == len(results)-1; res.Partial == last {
+			t.Errorf("result %d: partial = %v, want %v", i, res.Partial, !last)
+		}
+
+		var entries []protocol.LogEntry
+		if err := json.Unmarshal(res.Payload, &entries); err != nil {
+			t.Fatalf("result %d payload: %v", i, err)
+		}
+		for _, e := range entries {
+			msgs = append(msgs, e.Message)
+		}
+	}
+
+	want := []string{"one", "two", "three"}
+	if len(msgs) != len(want) {
+		t.Fatalf("forwarded %v, want %v", msgs, want)
+	}
+	for i := range want {
+		if msgs[i] != want[i] {
+			t.Errorf("entry %d: got %q, want %q", i, msgs[i], want[i])
+		}
+	}
+}
+
+func TestHandleCommand_CancelFollowLogs(t *testing.T) {
+	var rr resultRecorder
+	srv := httptest.NewServer(http.HandlerFunc(rr.handler))
+	defer srv.Close()
+
+	started := make(chan struct{})
+	stubFollowLogs(t, func(ctx context.Context, _ protocol.LogLevel) (<-chan protocol.LogEntry, error) {
+		out := make(chan protocol.LogEntry)
+		go func() {
+			defer close(out)
+			close(started)
+			<-ctx.Done()
+		}()
+		return out, nil
+	})
+
+	a := newTestAgentWithLogger()
+	a.Config.BaseURL = srv.URL
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.handleCommand(context.Background(), protocol.Command{ID: "cmd-follow", Type: protocol.CmdFollowLogs, Payload: []byte(`{}`)})
+	}()
+	<-started
+
+	payload, _ := json.Marshal(protocol.CancelRequest{CommandID: "cmd-follow"})
+	a.handleCommand(context.Background(), protocol.Command{ID: "cmd-cancel", Type: protocol.CmdCancel, Payload: payload})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("follow did not stop after cancel")
+	}
+
+	byID := make(map[string]protocol.CommandResult)
+	for _, res := range rr.snapshot() {
+		byID[res.ID] = res
+	}
+	if res := byID["cmd-cancel"]; res.Error != "" {
+		t.Errorf("cancel result error: %q", res.Error)
+	}
+	if res, ok := byID["cmd-follow"]; !ok || res.Partial {
+		t.Errorf("expected a final follow result, got %+v", res)
+	}
+}
+
+func TestHandleCommand_CancelUnknown(t *testing.T) {
+	var rr resultRecorder
+	srv := httptest.NewServer(http.HandlerFunc(rr.handler))
+	defer srv.Close()
+
+	a := newTestAgentWithLogger()
+	a.Config.BaseURL = srv.URL
+
+	payload, _ := json.Marshal(protocol.CancelRequest{CommandID: "missing"})
+	a.handleCommand(context.Background(), protocol.Command{ID: "cmd-cancel", Type: protocol.CmdCancel, Payload: payload})
+
+	results := rr.snapshot()
+	if len(results) != 1 || results[0].Error == "" {
+		t.Errorf("expected an error result, got %+v", results)
+	}
+}
+
+func TestFollowDuration(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    time.Duration
+	}{
+		{0, defaultFollowDuration},
+		{-5, defaultFollowDuration},
+		{30, 30 * time.Second},
+		{3600, maxFollowDuration},
+	}
+
+	for _, tt := range tests {
+		if got := followDuration(tt.seconds); got != tt.want {
+			t.Errorf("followDuration(%d) = %v, want %v", tt.seconds, got, tt.want)
+		}
+	}
+}
@@ -15,9 +15,10 @@ import (
 
 // Register gathers host info and sends it to the server.
 func (a *Agent) Register(ctx context.Context) error {
-	info := hostinfo.CollectHostInfo()
+	info := hostinfo.Cached()
 	info.Hostname = a.Config.Hostname
 	info.AgentVer = version.Version
+	info.Tags = a.Config.Tags
 
 	regReq := protocol.RegisterRequest{
 		Token: a.Config.RegistrationToken,
@@ -56,7 +57,11 @@ func (a *Agent) Register(ctx context.Context) error {
 				"retry_in", delay.String(),
 				"error", reqErr,
 			)
-			time.Sleep(delay)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
 		}
 	}
 
@@ -74,14 +79,41 @@ func (a *Agent) Register(ctx context.Context) error {
 		return fmt.Errorf("decode response: %w", err)
 	}
 
-	a.Identity = Identity{
+	id := Identity{
 		ID:     resp.AgentID,
 		Secret: resp.Secret,
 	}
+	a.identityMu.Lock()
+	a.Identity = id
+	a.identityMu.Unlock()
 
-	if err := saveIdentity(a.Identity, a.Config.IdentityPath); err != nil {
+	if err := saveIdentity(id, a.Config.IdentityPath); err != nil {
 		return fmt.Errorf("saving identity: %w", err)
 	}
 
 	return nil
 }
+
+// registerUntilAcknowledged calls Register until the server accepts it,
+// backing off between rounds, so an agent that boots before the server
+// waits for it instead of exiting. It only gives up when ctx is done.
+func (a *Agent) registerUntilAcknowledged(ctx context.Context) error {
+	for round := 0; ; round++ {
+		err := a.Register(ctx)
+		if err == nil {
+			a.Logger.Info("agent registered", "agent_id", a.Identity.ID)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		delay := withJitter(a.RetryConfig.Delay(round))
+		a.Logger.Warn("registration failed, retrying", "error", err, "retry_in", delay.String())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/nhdewitt/spectra/internal/logging"
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
@@ -188,6 +189,65 @@ func TestRegister_RetrySuccess(t *testing.T) {
 	}
 }
 
+func TestRegisterUntilAcknowledged_ServerComesUp(t *testing.T) {
+	var requestCount int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requestCount, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(protocol.RegisterResponse{
+			AgentID: "boot-id",
+			Secret:  "boot-secret",
+		})
+	}))
+	defer server.Close()
+
+	a := New(testConfig(t, server.URL))
+	a.Logger = logging.NewDiscard()
+	// One attempt per Register call, so the outer loop does the retrying
+	a.RetryConfig = RetryConfig{
+		MaxAttempts:  1,
+		InitialDelay: 1 * time.Millisecond,
+		MaxDelay:     10 * time.Millisecond,
+		Multiplier:   2.0,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := a.registerUntilAcknowledged(ctx); err != nil {
+		t.Fatalf("registerUntilAcknowledged: %v", err)
+	}
+	if got := atomic.LoadInt32(&requestCount); got != 3 {
+		t.Errorf("request count: got %d, want 3", got)
+	}
+	if a.Identity.ID != "boot-id" {
+		t.Errorf("Identity.ID: got %s, want boot-id", a.Identity.ID)
+	}
+}
+
+func TestRegisterUntilAcknowledged_ContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	a := New(testConfig(t, server.URL))
+	a.Logger = logging.NewDiscard()
+	a.RetryConfig = RetryConfig{MaxAttempts: 1, InitialDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, Multiplier: 2}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := a.registerUntilAcknowledged(ctx); err == nil {
+		t.Error("expected an error once the context expires")
+	}
+}
+
 func TestRegister_ConnectionError(t *testing.T) {
 	cfg := testConfig(t, "http://localhost:59999")
 	a := New(cfg)
@@ -0,0 +1,231 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/collector/disk"
+	"github.com/nhdewitt/spectra/internal/export"
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// scrapeStaleAfter drops series no collector has refreshed for this long,
+// such as an unmounted disk or a removed interface.
+const scrapeStaleAfter = 15 * time.Minute
+
+// scrapeContentType is the Prometheus text exposition format.
+const scrapeContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// scrapeCache holds the latest sample of every series for /metrics, keyed
+// by measurement and tags.
+type scrapeCache struct {
+	mu     sync.Mutex
+	series map[string]scrapeSeries
+}
+
+type scrapeSeries struct {
+	sample export.Sample
+	seen   time.Time
+}
+
+func newScrapeCache() *scrapeCache {
+	return &scrapeCache{series: make(map[string]scrapeSeries)}
+}
+
+// add replaces the cached samples for env's metric. Metrics without a
+// numeric form are ignored.
+func (c *scrapeCache) add(env protocol.Envelope, now time.Time) {
+	if env.Data == nil {
+		return
+	}
+	// Collectors emit values, while export.Samples matches the pointers
+	// the server decodes; Clone always returns a pointer.
+	samples := export.Samples(env.Data.Clone())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range samples {
+		c.series[seriesKey(s)] = scrapeSeries{sample: s, seen: now}
+	}
+}
+
+func seriesKey(s export.Sample) string {
+	var b strings.Builder
+	b.WriteString(s.Measurement)
+	for _, t := range s.Tags {
+		b.WriteByte(0)
+		b.WriteString(t.Key)
+		b.WriteByte('=')
+		b.WriteString(t.Value)
+	}
+	return b.String()
+}
+
+// writeTo renders every fresh series as Prometheus gauges named
+// spectra_<measurement>_<field>, labeled with host and the sample tags,
+// and forgets stale ones.
+//
+//	# TYPE spectra_cpu_usage gauge
+//	spectra_cpu_usage{host="web-01"} 42.5
+func (c *scrapeCache) writeTo(w io.Writer, host string, now time.Time) error {
+	byName := make(map[string][]string)
+
+	c.mu.Lock()
+	for key, s := range c.series {
+		if now.Sub(s.seen) > scrapeStaleAfter {
+			delete(c.series, key)
+			continue
+		}
+		labels := formatLabels(host, s.sample.Tags)
+		for _, f := range s.sample.Fields {
+			name := metricName("spectra_" + s.sample.Measurement + "_" + f.Key)
+			byName[name] = append(byName[name], name+labels+" "+formatValue(f.Value))
+		}
+	}
+	c.mu.Unlock()
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		lines := byName[name]
+		slices.Sort(lines)
+
+		b.WriteString("# TYPE ")
+		b.WriteString(name)
+		b.WriteString(" gauge\n")
+		for _, line := range lines {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatLabels renders {host="h",key="value"}, leaving out empty tag
+// values.
+func formatLabels(host string, tags []export.Tag) string {
+	var b strings.Builder
+	b.WriteString(`{host="`)
+	b.WriteString(labelEscaper.Replace(host))
+	b.WriteByte('"')
+	for _, t := range tags {
+		if t.Value == "" {
+			continue
+		}
+		b.WriteByte(',')
+		b.WriteString(metricName(t.Key))
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(t.Value))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricName replaces characters not allowed in metric and label names
+// with underscores.
+func metricName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// scrapeHandler serves the cached samples at GET /metrics.
+func (a *Agent) scrapeHandler(cache *scrapeCache) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", scrapeContentType)
+		if err := cache.writeTo(w, a.Config.Hostname, time.Now()); err != nil {
+			a.Logger.Debug("writing metrics response failed", "error", err)
+		}
+	})
+	return mux
+}
+
+// runScrapeCache replaces the metric sender when Config.MetricsListen is
+// set, keeping the latest value of every series for scrapes.
+func (a *Agent) runScrapeCache(ctx context.Context, cache *scrapeCache) {
+	for {
+		select {
+		case envelope, ok := <-a.metricsCh:
+			if !ok {
+				return
+			}
+			cache.add(envelope, time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// startScrapeTarget runs the collectors and serves their latest values
+// at /metrics on Config.MetricsListen, without registering, polling for
+// commands, or contacting the server.
+func (a *Agent) startScrapeTarget(ctx context.Context) error {
+	ln, err := net.Listen("tcp", a.Config.MetricsListen)
+	if err != nil {
+		return fmt.Errorf("metrics listener: %w", err)
+	}
+	a.Logger.Info("scrape mode: serving metrics, not sending", "addr", ln.Addr().String())
+
+	go disk.RunMountManager(ctx, a.DriveCache, 30*time.Second, a.Logger.Logger)
+
+	cache := newScrapeCache()
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		a.runScrapeCache(ctx, cache)
+	}()
+
+	srv := &http.Server{
+		Handler:           a.scrapeHandler(cache),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.Logger.Error("metrics listener failed", "error", err)
+		}
+	}()
+
+	a.startCollectors(ctx)
+
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/logging"
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// expositionLine matches a comment or a sample line of the Prometheus
+// text format.
+var expositionLine = regexp.MustCompile(`^(# TYPE [a-zA-Z_:][a-zA-Z0-9_:]* gauge|[a-zA-Z_:][a-zA-Z0-9_:]*\{([a-zA-Z_][a-zA-Z0-9_]*="([^"\\]|\\.)*",?)*\} \S+)$`)
+
+func TestScrapeHandler_ServesLatestValues(t *testing.T) {
+	a := New(Config{Hostname: "scrape-host", IdentityPath: filepath.Join(t.TempDir(), "agent-id.json")})
+	a.Logger = logging.NewDiscard()
+
+	cache := newScrapeCache()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.runScrapeCache(ctx, cache)
+		close(done)
+	}()
+
+	a.metricsCh <- protocol.Envelope{Type: protocol.TypeCPU, Data: protocol.CPUMetric{Usage: 10}}
+	a.metricsCh <- protocol.Envelope{Type: protocol.TypeCPU, Data: protocol.CPUMetric{Usage: 42.5, LoadAvg1: 0.5}}
+	a.metricsCh <- protocol.Envelope{Type: protocol.TypeDisk, Data: protocol.DiskMetric{
+		Device: "/dev/sda1", Mountpoint: `/mnt/"quoted"`, Filesystem: "ext4", UsedPct: 25,
+	}}
+	a.metricsCh <- protocol.Envelope{Type: protocol.TypeDisk, Data: protocol.DiskMetric{
+		Device: "/dev/sdb1", Mountpoint: "/data", Filesystem: "xfs", UsedPct: 75,
+	}}
+	// No numeric form; must not show up
+	a.metricsCh <- protocol.Envelope{Type: protocol.TypeApplicationList, Data: protocol.ApplicationListMetric{}}
+
+	// Wait for the cache to take everything queued
+	deadline := time.Now().Add(2 * time.Second)
+	for len(a.metricsCh) > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	srv := httptest.NewServer(a.scrapeHandler(cache))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != scrapeContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, scrapeContentType)
+	}
+
+	text := string(body)
+	for i, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		if !expositionLine.MatchString(line) {
+			t.Errorf("line %d is not valid exposition: %q", i+1, line)
+		}
+	}
+
+	for _, want := range []string{
+		"# TYPE spectra_cpu_usage gauge\nspectra_cpu_usage{host=\"scrape-host\"} 42.5\n",
+		"spectra_cpu_load_1m{host=\"scrape-host\"} 0.5\n",
+		"# TYPE spectra_disk_used_pct gauge\n" +
+			"spectra_disk_used_pct{host=\"scrape-host\",device=\"/dev/sda1\",mountpoint=\"/mnt/\\\"quoted\\\"\",fstype=\"ext4\"} 25\n" +
+			"spectra_disk_used_pct{host=\"scrape-host\",device=\"/dev/sdb1\",mountpoint=\"/data\",fstype=\"xfs\"} 75\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing\n%s\nin\n%s", want, text)
+		}
+	}
+	if strings.Count(text, "# TYPE spectra_cpu_usage gauge") != 1 {
+		t.Errorf("spectra_cpu_usage should be declared once:\n%s", text)
+	}
+	if strings.Contains(text, "application") {
+		t.Errorf("inventory metric rendered:\n%s", text)
+	}
+}
+
+func TestScrapeHandler_MethodNotAllowed(t *testing.T) {
+	a := New(Config{Hostname: "scrape-host", IdentityPath: filepath.Join(t.TempDir(), "agent-id.json")})
+	a.Logger = logging.NewDiscard()
+
+	rec := httptest.NewRecorder()
+	a.scrapeHandler(newScrapeCache()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", rec.Code)
+	}
+}
+
+func TestScrapeCache_DropsStaleSeries(t *testing.T) {
+	cache := newScrapeCache()
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cache.add(protocol.Envelope{Data: protocol.NetworkMetric{Interface: "eth0", RxBytes: 1}}, t0)
+	cache.add(protocol.Envelope{Data: protocol.CPUMetric{Usage: 5}}, t0.Add(scrapeStaleAfter))
+
+	var b strings.Builder
+	if err := cache.writeTo(&b, "h", t0.Add(scrapeStaleAfter+time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "eth0") {
+		t.Errorf("stale interface still rendered:\n%s", b.String())
+	}
+	if !strings.Contains(b.String(), "spectra_cpu_usage") {
+		t.Errorf("fresh cpu series missing:\n%s", b.String())
+	}
+	if len(cache.series) != 1 {
+		t.Errorf("cache holds %d series, want 1", len(cache.series))
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := map[float64]string{
+		0:    "0",
+		42.5: "42.5",
+		1e21: "1e+21",
+		-3:   "-3",
+	}
+	for v, want := range tests {
+		if got := formatValue(v); got != want {
+			t.Errorf("formatValue(%v) = %q, want %q", v, got, want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand/v2"
 	"net/http"
@@ -17,6 +18,24 @@ const (
 	SendInterval = 5 * time.Second // Force sending every 5 seconds
 )
 
+// shutdownFlushTimeout bounds the final upload after the agent context is
+// cancelled, so shutdown delivers the last batch without hanging on a dead
+// server.
+const shutdownFlushTimeout = 5 * time.Second
+
+// cacheReplaySize bounds each POST when replaying cached metrics. It stays
+// under the server's default per-batch envelope limit.
+const cacheReplaySize = 500
+
+// errAgentUnknown means the server rejected this agent's identity,
+// typically because it was purged or the database was reset.
+var errAgentUnknown = errors.New("server does not recognize this agent")
+
+// errBatchTooLarge means the server refused a batch with 413 for holding
+// more envelopes than it accepts. Resending the same batch can never
+// succeed, so it is split rather than cached.
+var errBatchTooLarge = errors.New("server rejected batch as too large")
+
 // runMetricSender consumes the channel and sends batches via HTTP
 func (a *Agent) runMetricSender(ctx context.Context) {
 	batch := make([]protocol.Envelope, 0, BatchSize)
@@ -24,30 +43,67 @@ func (a *Agent) runMetricSender(ctx context.Context) {
 	ticker := time.NewTicker(SendInterval)
 	defer ticker.Stop()
 
-	flush := func() {
+	flush := func(ctx context.Context) {
+		if a.dedup != nil && a.dedup.suppressed > 0 {
+			a.Logger.Debug("suppressed unchanged metrics", "count", a.dedup.suppressed)
+			a.dedup.suppressed = 0
+		}
 		if len(batch) > 0 {
 			a.uploadBatch(ctx, batch)
 			batch = batch[:0]
 		}
 	}
 
+	add := func(envelope protocol.Envelope) {
+		if a.dedup != nil && !a.dedup.shouldSend(envelope) {
+			return
+		}
+		batch = append(batch, envelope)
+	}
+
 	for {
 		select {
 		case envelope, ok := <-a.metricsCh:
 			if !ok {
-				flush()
+				flush(ctx)
 				return
 			}
-			batch = append(batch, envelope)
+			add(envelope)
 			if len(batch) >= BatchSize {
-				flush()
+				flush(ctx)
 			}
 
 		case <-ticker.C:
-			flush()
+			flush(ctx)
 
 		case <-ctx.Done():
-			flush()
+			// Collectors stop sending once ctx is cancelled; wait for them
+			// so nothing lands in the channel after it has been drained.
+			a.collectors.Wait()
+
+			// Upload on a context detached from the cancelled one so the
+			// final batches can still be delivered
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownFlushTimeout)
+			defer cancel()
+
+			for drained := false; !drained; {
+				select {
+				case envelope := <-a.metricsCh:
+					add(envelope)
+					if len(batch) >= BatchSize {
+						flush(ctx)
+					}
+				default:
+					drained = true
+				}
+			}
+			flush(ctx)
+
+			// A send interrupted by the cancellation left its batch in
+			// the cache
+			if a.cache.Len() > 0 {
+				a.uploadBatch(ctx, nil)
+			}
 			return
 		}
 	}
@@ -58,22 +114,33 @@ func (a *Agent) uploadBatch(ctx context.Context, batch []protocol.Envelope) {
 
 	// Try sending cached metrics first
 	if cached := a.cache.Drain(); len(cached) > 0 {
-		if err := a.postCompressed(ctx, url, cached); err != nil {
-			// Re-cache everything
-			a.cache.Add(cached)
-			a.cache.Add(batch)
-			a.applyBackoff()
-			a.Logger.Warn("server unreachable",
-				"cache_size", a.cache.Len(),
-				"retry_in", time.Until(a.backoffUntil).Round(time.Second))
-			return
+		// Replay in chunks so a long outage doesn't produce a batch the
+		// server rejects as oversized.
+		for i := 0; i < len(cached); i += cacheReplaySize {
+			chunk := cached[i:min(i+cacheReplaySize, len(cached))]
+			if sent, err := a.postSplitting(ctx, url, chunk); err != nil {
+				// Re-cache everything not yet delivered
+				a.cache.Add(cached[i+sent:])
+				a.cache.Add(batch)
+				a.reregisterIfUnknown(ctx, err)
+				a.applyBackoff()
+				a.Logger.Warn("server unreachable",
+					"cache_size", a.cache.Len(),
+					"retry_in", time.Until(a.backoffUntil).Round(time.Second))
+				return
+			}
 		}
 		a.Logger.Debug("sent cached metrics", "count", len(cached))
 	}
 
+	if len(batch) == 0 {
+		return
+	}
+
 	// Send current batch
-	if err := a.postCompressed(ctx, url, batch); err != nil {
-		a.cache.Add(batch)
+	if sent, err := a.postSplitting(ctx, url, batch); err != nil {
+		a.cache.Add(batch[sent:])
+		a.reregisterIfUnknown(ctx, err)
 		a.applyBackoff()
 		a.Logger.Warn("error sending metrics",
 			"error", err,
@@ -81,16 +148,70 @@ func (a *Agent) uploadBatch(ctx context.Context, batch []protocol.Envelope) {
 			"retry_in", time.Until(a.backoffUntil).Round(time.Second))
 		return
 	}
+	a.Logger.Debug("metrics batch sent", "batch_size", len(batch))
 
 	a.resetBackoff()
 }
 
+// postSplitting posts batch, halving it each time the server answers 413
+// so a server with a lower MaxBatchSize still gets every envelope. A
+// single envelope that is still refused is dropped, not retried forever.
+// It returns how many leading envelopes were delivered or dropped, so
+// callers re-cache only the rest on error.
+func (a *Agent) postSplitting(ctx context.Context, url string, batch []protocol.Envelope) (int, error) {
+	err := a.postCompressed(ctx, url, batch)
+	if err == nil {
+		return len(batch), nil
+	}
+	if !errors.Is(err, errBatchTooLarge) {
+		return 0, err
+	}
+	if len(batch) == 1 {
+		a.Logger.Warn("server rejected metric as too large, dropping it",
+			"type", batch[0].Type)
+		return 1, nil
+	}
+
+	mid := len(batch) / 2
+	a.Logger.Debug("server rejected batch as too large, splitting",
+		"batch_size", len(batch))
+	sent, err := a.postSplitting(ctx, url, batch[:mid])
+	if err != nil {
+		return sent, err
+	}
+	sent, err = a.postSplitting(ctx, url, batch[mid:])
+	return mid + sent, err
+}
+
+// reregisterIfUnknown registers again when err shows the server has
+// forgotten this agent. Cached metrics go out under the new identity on
+// the next send.
+func (a *Agent) reregisterIfUnknown(ctx context.Context, err error) {
+	if !errors.Is(err, errAgentUnknown) {
+		return
+	}
+	a.Logger.Warn("server no longer recognizes agent, re-registering")
+	if err := a.Register(ctx); err != nil {
+		a.Logger.Error("re-registration failed", "error", err)
+		return
+	}
+	a.Logger.Info("agent re-registered", "agent_id", a.Identity.ID)
+}
+
 func (a *Agent) applyBackoff() {
 	delay := a.RetryConfig.Delay(a.backoffStep)
 	a.backoffStep++
-	// +/-25% jitter to prevent all agents hammering at the same time on server recovery
-	quarter := delay / 4
-	a.backoffUntil = time.Now().Add(delay - quarter + time.Duration(rand.Int64N(int64(2*quarter))))
+	a.backoffUntil = time.Now().Add(withJitter(delay))
+}
+
+// withJitter spreads d by +/-25% to prevent all agents hammering at the
+// same time on server recovery.
+func withJitter(d time.Duration) time.Duration {
+	quarter := d / 4
+	if quarter <= 0 {
+		return d
+	}
+	return d - quarter + time.Duration(rand.Int64N(int64(2*quarter)))
 }
 
 func (a *Agent) resetBackoff() {
@@ -101,17 +222,27 @@ func (a *Agent) resetBackoff() {
 	}
 }
 
-// postCompressed marshals data to JSON, compresses it, and sends it to the server.
+// postCompressed marshals data to JSON (or MessagePack when configured),
+// compresses it, and sends it to the server.
 func (a *Agent) postCompressed(ctx context.Context, url string, batch []protocol.Envelope) error {
+	useMsgpack := a.Config.Encoding == EncodingMsgpack
+
 	a.gzipMu.Lock()
 	a.gzipBuf.Reset()
 	a.gzipW.Reset(&a.gzipBuf)
 
-	if err := json.NewEncoder(a.gzipW).Encode(batch); err != nil {
+	if useMsgpack {
+		if err := protocol.NewMsgpackEncoder(a.gzipW).Encode(batch); err != nil {
+			a.gzipMu.Unlock()
+			return fmt.Errorf("msgpack encode error: %w", err)
+		}
+	} else if err := json.NewEncoder(a.gzipW).Encode(batch); err != nil {
+		a.gzipMu.Unlock()
 		return fmt.Errorf("json encode error: %w", err)
 	}
 
 	if err := a.gzipW.Close(); err != nil {
+		a.gzipMu.Unlock()
 		return fmt.Errorf("gzip close error: %w", err)
 	}
 
@@ -124,6 +255,9 @@ func (a *Agent) postCompressed(ctx context.Context, url string, batch []protocol
 	}
 
 	a.setHeaders(req)
+	if useMsgpack {
+		req.Header.Set("Content-Type", protocol.ContentTypeMsgpack)
+	}
 
 	resp, err := a.Client.Do(req)
 	if err != nil {
@@ -131,6 +265,12 @@ func (a *Agent) postCompressed(ctx context.Context, url string, batch []protocol
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: status %d", errAgentUnknown, resp.StatusCode)
+	}
+	if resp.StatusCode == http.StatusRequestEntityTooLarge {
+		return fmt.Errorf("%w: %d envelopes", errBatchTooLarge, len(batch))
+	}
 	if resp.StatusCode >= 300 {
 		return fmt.Errorf("server returned status %d", resp.StatusCode)
 	}
@@ -7,10 +7,13 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/nhdewitt/spectra/internal/collector"
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
@@ -72,6 +75,44 @@ func TestPostCompressed_GzipContent(t *testing.T) {
 	}
 }
 
+func TestPostCompressed_Msgpack(t *testing.T) {
+	var contentType string
+	var got []struct {
+		Type string `json:"type"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("failed to read gzip: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+
+		if err := protocol.NewMsgpackDecoder(gz).Decode(&got); err != nil {
+			t.Errorf("failed to decode msgpack: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	a := newTestAgentWithLogger()
+	a.Config.Encoding = EncodingMsgpack
+
+	batch := []protocol.Envelope{testEnvelope("cpu"), testEnvelope("memory")}
+	if err := a.postCompressed(context.Background(), srv.URL+"/metrics", batch); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if contentType != protocol.ContentTypeMsgpack {
+		t.Errorf("expected Content-Type %s, got %q", protocol.ContentTypeMsgpack, contentType)
+	}
+	if len(got) != 2 || got[0].Type != "cpu" || got[1].Type != "memory" {
+		t.Errorf("unexpected decoded batch: %+v", got)
+	}
+}
+
 func TestPostCompressed_ServerError(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -209,6 +250,36 @@ func TestUploadBatch_CachesOnFailure(t *testing.T) {
 	}
 }
 
+func TestUploadBatch_ReregistersWhenForgotten(t *testing.T) {
+	var registered atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/agent/register" {
+			registered.Store(true)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(protocol.RegisterResponse{AgentID: "new-id", Secret: "new-secret"})
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	a := newTestAgentWithLogger()
+	a.Config.BaseURL = srv.URL
+	a.Config.IdentityPath = filepath.Join(t.TempDir(), "agent-id.json")
+
+	a.uploadBatch(context.Background(), []protocol.Envelope{testEnvelope("cpu")})
+
+	if !registered.Load() {
+		t.Fatal("expected a re-registration after 401")
+	}
+	if a.Identity.ID != "new-id" {
+		t.Errorf("Identity.ID: got %q, want new-id", a.Identity.ID)
+	}
+	if a.cache.Len() != 1 {
+		t.Errorf("rejected batch should stay cached, got %d", a.cache.Len())
+	}
+}
+
 func TestUploadBatch_DrainsCacheFirst(t *testing.T) {
 	var calls []int // track envelope counts per call
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -241,6 +312,74 @@ func TestUploadBatch_DrainsCacheFirst(t *testing.T) {
 	}
 }
 
+func TestUploadBatch_ReplaysCacheInChunks(t *testing.T) {
+	var calls []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gz, _ := gzip.NewReader(r.Body)
+		var batch []protocol.Envelope
+		json.NewDecoder(gz).Decode(&batch)
+		gz.Close()
+		calls = append(calls, len(batch))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	a := newTestAgentWithLogger()
+	a.Config.BaseURL = srv.URL
+	a.Config.MetricsPath = "/api/v1/agent/metrics"
+
+	cached := make([]protocol.Envelope, cacheReplaySize*2+1)
+	for i := range cached {
+		cached[i] = testEnvelope("cpu")
+	}
+	a.cache.Add(cached)
+
+	a.uploadBatch(context.Background(), []protocol.Envelope{testEnvelope("memory")})
+
+	want := []int{cacheReplaySize, cacheReplaySize, 1, 1}
+	if len(calls) != len(want) {
+		t.Fatalf("calls: got %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("call %d: got %d envelopes, want %d", i, calls[i], want[i])
+		}
+	}
+	if a.cache.Len() != 0 {
+		t.Errorf("cache should be empty, got %d", a.cache.Len())
+	}
+}
+
+func TestUploadBatch_RecachesUndeliveredChunks(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if calls.Add(1) > 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	a := newTestAgentWithLogger()
+	a.Config.BaseURL = srv.URL
+	a.Config.MetricsPath = "/api/v1/agent/metrics"
+
+	cached := make([]protocol.Envelope, cacheReplaySize+10)
+	for i := range cached {
+		cached[i] = testEnvelope("cpu")
+	}
+	a.cache.Add(cached)
+
+	a.uploadBatch(context.Background(), []protocol.Envelope{testEnvelope("memory")})
+
+	// First chunk delivered; the remaining 10 plus the current batch are kept
+	if a.cache.Len() != 11 {
+		t.Errorf("expected 11 cached envelopes, got %d", a.cache.Len())
+	}
+}
+
 func TestUploadBatch_CachesDrainFailure(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		io.Copy(io.Discard, r.Body)
@@ -265,6 +404,101 @@ func TestUploadBatch_CachesDrainFailure(t *testing.T) {
 	}
 }
 
+// limitedServer accepts batches of up to limit envelopes, answering 413
+// for larger ones, and counts the envelopes it accepted.
+func limitedServer(t *testing.T, limit int, accepted *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gz, _ := gzip.NewReader(r.Body)
+		var batch []protocol.Envelope
+		json.NewDecoder(gz).Decode(&batch)
+		gz.Close()
+		if len(batch) > limit {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		accepted.Add(int32(len(batch)))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestUploadBatch_SplitsOnTooLarge(t *testing.T) {
+	var accepted atomic.Int32
+	srv := limitedServer(t, 2, &accepted)
+
+	a := newTestAgentWithLogger()
+	a.Config.BaseURL = srv.URL
+	a.Config.MetricsPath = "/api/v1/agent/metrics"
+
+	a.cache.Add([]protocol.Envelope{testEnvelope("cpu"), testEnvelope("cpu"), testEnvelope("cpu")})
+	batch := make([]protocol.Envelope, 5)
+	for i := range batch {
+		batch[i] = testEnvelope("memory")
+	}
+	a.uploadBatch(context.Background(), batch)
+
+	if got := accepted.Load(); got != 8 {
+		t.Errorf("accepted: got %d envelopes, want 8", got)
+	}
+	if a.cache.Len() != 0 {
+		t.Errorf("a 413 must not re-cache the batch, got %d cached", a.cache.Len())
+	}
+	if a.backoffStep != 0 {
+		t.Errorf("a 413 must not back off, got step %d", a.backoffStep)
+	}
+}
+
+func TestUploadBatch_DropsEnvelopeTooLargeAlone(t *testing.T) {
+	var accepted atomic.Int32
+	srv := limitedServer(t, 0, &accepted)
+
+	a := newTestAgentWithLogger()
+	a.Config.BaseURL = srv.URL
+	a.Config.MetricsPath = "/api/v1/agent/metrics"
+
+	a.uploadBatch(context.Background(), []protocol.Envelope{testEnvelope("cpu"), testEnvelope("memory")})
+
+	if got := accepted.Load(); got != 0 {
+		t.Errorf("accepted: got %d, want 0", got)
+	}
+	if a.cache.Len() != 0 {
+		t.Errorf("refused envelopes should be dropped, got %d cached", a.cache.Len())
+	}
+}
+
+func TestUploadBatch_RecachesOnlyUndeliveredHalf(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		case 2:
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	a := newTestAgentWithLogger()
+	a.Config.BaseURL = srv.URL
+	a.Config.MetricsPath = "/api/v1/agent/metrics"
+
+	batch := make([]protocol.Envelope, 4)
+	for i := range batch {
+		batch[i] = testEnvelope("cpu")
+	}
+	a.uploadBatch(context.Background(), batch)
+
+	// The first half was delivered; only the second half is kept
+	if a.cache.Len() != 2 {
+		t.Errorf("expected 2 cached envelopes, got %d", a.cache.Len())
+	}
+}
+
 func TestUploadBatch_EmptyCache(t *testing.T) {
 	var callCount atomic.Int32
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -318,6 +552,90 @@ func TestRunMetricSender_FlushesOnContextCancel(t *testing.T) {
 	}
 }
 
+func TestRunMetricSender_ShutdownDrainsCollectors(t *testing.T) {
+	// A send interrupted by shutdown may be retried, so count distinct
+	// envelopes rather than deliveries
+	var mu sync.Mutex
+	received := make(map[float64]bool)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("failed to read gzip: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+
+		var batch []struct {
+			Data protocol.CPUMetric `json:"data"`
+		}
+		if err := json.NewDecoder(gz).Decode(&batch); err != nil {
+			t.Errorf("failed to decode batch: %v", err)
+		}
+		mu.Lock()
+		for _, env := range batch {
+			received[env.Data.Usage] = true
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	a := newTestAgentWithLogger()
+	a.Config.BaseURL = srv.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Small channel so collectors regularly block on a full buffer
+	a.metricsCh = make(chan protocol.Envelope, 4)
+	c := collector.New(a.Config.Hostname, a.metricsCh)
+
+	var collected atomic.Int64
+	for range 4 {
+		a.collectors.Go(func() {
+			c.Run(ctx, time.Millisecond, func(context.Context) ([]protocol.Metric, error) {
+				n := collected.Add(1)
+				return []protocol.Metric{&protocol.CPUMetric{Usage: float64(n)}}, nil
+			})
+		})
+	}
+
+	senderDone := make(chan struct{})
+	go func() {
+		a.runMetricSender(ctx)
+		close(senderDone)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-senderDone:
+	case <-time.After(10 * time.Second):
+		t.Fatal("sender did not finish after shutdown")
+	}
+
+	if n := len(a.metricsCh); n != 0 {
+		t.Errorf("%d envelopes left in channel after shutdown", n)
+	}
+
+	// Each collector can lose at most the one envelope whose send was
+	// interrupted by cancellation; everything queued must be delivered.
+	mu.Lock()
+	got := int64(len(received))
+	mu.Unlock()
+	made := collected.Load()
+	if got == 0 || got > made || got < made-4 {
+		t.Errorf("received %d envelopes, collected %d", got, made)
+	}
+
+	// Collectors have stopped: nothing is written after shutdown
+	time.Sleep(20 * time.Millisecond)
+	if n := len(a.metricsCh); n != 0 {
+		t.Errorf("%d envelopes written after shutdown", n)
+	}
+}
+
 func TestRunMetricSender_FlushesOnChannelClose(t *testing.T) {
 	var callCount atomic.Int32
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
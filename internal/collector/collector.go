@@ -2,80 +2,199 @@ package collector
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"math/rand/v2"
+	"runtime/debug"
+	"sync"
 	"time"
 
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
+// DefaultHealthInterval is how often a named collector reports its health.
+const DefaultHealthInterval = 60 * time.Second
+
 type CollectFunc func(context.Context) ([]protocol.Metric, error)
 
 type Collector struct {
-	hostname string
-	out      chan<- protocol.Envelope
+	hostname       string
+	out            chan<- protocol.Envelope
+	healthInterval time.Duration
+	jitter         func(interval time.Duration) time.Duration
 }
 
 func New(hostname string, out chan<- protocol.Envelope) *Collector {
 	return &Collector{
-		hostname: hostname,
-		out:      out,
+		hostname:       hostname,
+		out:            out,
+		healthInterval: DefaultHealthInterval,
+		jitter:         newJitter(rand.Uint64()),
+	}
+}
+
+// tickSpread is the fraction of the interval later ticks are spread over:
+// each lands within +/-5% of one interval after the previous tick.
+const tickSpread = 10
+
+// newJitter returns a function picking a random offset in [0, interval)
+// from a source seeded with seed, so tests can reproduce the offsets.
+// It is safe for concurrent use.
+func newJitter(seed uint64) func(time.Duration) time.Duration {
+	var mu sync.Mutex
+	r := rand.New(rand.NewPCG(seed, seed))
+	return func(interval time.Duration) time.Duration {
+		if interval <= 0 {
+			return 0
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return time.Duration(r.Int64N(int64(interval)))
+	}
+}
+
+// nextDelay returns how long to wait for the next collection. The first
+// one, the baseline, waits a random fraction of the whole interval so
+// collectors sharing an interval, and agents started on the same minute
+// boundary, don't all fire at once; later ticks vary by up to tickSpread
+// so they don't settle back into lockstep. Without jitter the baseline
+// runs immediately and every later delay is the interval.
+func (c *Collector) nextDelay(interval time.Duration, first bool) time.Duration {
+	if c.jitter == nil {
+		if first {
+			return 0
+		}
+		return interval
+	}
+	if first {
+		return c.jitter(interval)
+	}
+	spread := interval / tickSpread
+	return interval - spread/2 + c.jitter(spread)
+}
+
+// health tracks the collection outcome of a single named collector.
+type health struct {
+	name              string
+	lastSuccess       time.Time
+	consecutiveErrors int
+	lastError         string
+}
+
+func (h *health) success() {
+	h.lastSuccess = time.Now()
+	h.consecutiveErrors = 0
+	h.lastError = ""
+}
+
+func (h *health) failure(err error) {
+	h.consecutiveErrors++
+	h.lastError = err.Error()
+}
+
+func (h *health) metric() *protocol.CollectorHealthMetric {
+	return &protocol.CollectorHealthMetric{
+		Name:              h.name,
+		LastSuccess:       h.lastSuccess,
+		ConsecutiveErrors: h.consecutiveErrors,
+		LastError:         h.lastError,
 	}
 }
 
 // wrap creates an envelope from any metric
 func (c *Collector) wrap(m protocol.Metric) protocol.Envelope {
 	return protocol.Envelope{
-		Type:      m.MetricType(),
-		Timestamp: time.Now(),
-		Hostname:  c.hostname,
-		Data:      m,
+		Type:          m.MetricType(),
+		SchemaVersion: protocol.SchemaVersion,
+		Timestamp:     time.Now(),
+		Hostname:      c.hostname,
+		Data:          m,
 	}
 }
 
-// send handles channel send with context cancellation
-func (c *Collector) send(ctx context.Context, m protocol.Metric) {
+// send queues m unless ctx is cancelled, and reports whether it did. The
+// up-front check matters: with both cases ready select picks at random,
+// so a cancelled collector could otherwise still write.
+func (c *Collector) send(ctx context.Context, m protocol.Metric) bool {
+	if ctx.Err() != nil {
+		return false
+	}
 	select {
 	case c.out <- c.wrap(m):
+		return true
 	case <-ctx.Done():
+		return false
 	}
 }
 
 // Run executes a collection function at the specified interval
 func (c *Collector) Run(ctx context.Context, interval time.Duration, collect CollectFunc) {
+	c.RunNamed(ctx, "", interval, collect)
+}
+
+// RunNamed is like Run, but also tracks the outcome of each collection
+// under name and emits a CollectorHealthMetric every health interval.
+// An empty name disables health reporting.
+//
+// A panicking collect is recovered and counted as a failed collection;
+// named collectors report their health right away so the panic isn't
+// hidden until the next health interval. Collection resumes on the next
+// tick.
+func (c *Collector) RunNamed(ctx context.Context, name string, interval time.Duration, collect CollectFunc) {
+	h := &health{name: name}
+
 	collectAndSend := func() {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("Panic recovered in collector: %v", r)
+				log.Printf("Panic recovered in collector %q: %v\n%s", name, r, debug.Stack())
+				h.failure(fmt.Errorf("panic: %v", r))
+				if name != "" {
+					c.send(ctx, h.metric())
+				}
 			}
 		}()
 
 		data, err := collect(ctx)
 		if err != nil {
+			h.failure(err)
 			return
 		}
+		h.success()
 
 		for _, m := range data {
 			if m == nil {
 				log.Printf("Warning: collector returned nil metric in slice, skipping")
 				continue
 			}
-			c.send(ctx, m)
+			if !c.send(ctx, m) {
+				return
+			}
 		}
 	}
 
-	// Collect Baseline
-	collectAndSend()
+	// A timer rather than a ticker, so each tick can draw its own offset.
+	// It is reset before collecting so slow collections don't add drift.
+	// Its first firing is the baseline collection.
+	tick := time.NewTimer(c.nextDelay(interval, true))
+	defer tick.Stop()
 
-	// Start ticker
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+	// A nil channel never fires, so unnamed collectors skip health reports
+	var healthC <-chan time.Time
+	if name != "" && c.healthInterval > 0 {
+		healthTicker := time.NewTicker(c.healthInterval)
+		defer healthTicker.Stop()
+		healthC = healthTicker.C
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
+		case <-tick.C:
+			tick.Reset(c.nextDelay(interval, false))
 			collectAndSend()
+		case <-healthC:
+			c.send(ctx, h.metric())
 		}
 	}
 }
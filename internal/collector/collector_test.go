@@ -3,6 +3,8 @@ package collector
 import (
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -19,6 +21,10 @@ func (m mockMetric) MetricType() string {
 	return "MOCK_METRIC"
 }
 
+func (m mockMetric) Validate() error { return nil }
+
+func (m mockMetric) Clone() protocol.Metric { return &m }
+
 type harness struct {
 	c      *Collector
 	out    chan protocol.Envelope
@@ -29,8 +35,12 @@ type harness struct {
 func newHarness(bufferSize int) *harness {
 	out := make(chan protocol.Envelope, bufferSize)
 	ctx, cancel := context.WithCancel(context.Background())
+	c := New("test-host", out)
+	// Deterministic schedule: baseline at once, then every interval.
+	// The jittered schedule is covered through nextDelay.
+	c.jitter = nil
 	return &harness{
-		c:      New("test-host", out),
+		c:      c,
 		out:    out,
 		ctx:    ctx,
 		cancel: cancel,
@@ -65,6 +75,9 @@ func TestCollector_Run(t *testing.T) {
 		if env.Timestamp.IsZero() {
 			t.Error("expected valid timestamp, got zero")
 		}
+		if env.SchemaVersion != protocol.SchemaVersion {
+			t.Errorf("expected schema version %d, got %d", protocol.SchemaVersion, env.SchemaVersion)
+		}
 
 		// Verify payload
 		if m, ok := env.Data.(mockMetric); !ok || m.Value != 1 {
@@ -118,6 +131,42 @@ func TestCollector_PanicRecovery(t *testing.T) {
 	}
 }
 
+func TestCollector_PanicReportsHealth(t *testing.T) {
+	h := newHarness(10)
+	defer h.cancel()
+	h.c.healthInterval = time.Hour // only panics should trigger reports
+
+	panicking := func(ctx context.Context) ([]protocol.Metric, error) {
+		var fields []string
+		_ = fields[3] // index out of range, like bad /proc content
+		return nil, nil
+	}
+
+	go h.c.RunNamed(h.ctx, "sched", 10*time.Millisecond, panicking)
+
+	// Baseline plus at least two ticks: the loop must survive each panic
+	for want := 1; want <= 3; want++ {
+		select {
+		case env := <-h.out:
+			m, ok := env.Data.(*protocol.CollectorHealthMetric)
+			if !ok {
+				t.Fatalf("expected *CollectorHealthMetric, got %T", env.Data)
+			}
+			if m.Name != "sched" {
+				t.Errorf("Name: got %q, want sched", m.Name)
+			}
+			if m.ConsecutiveErrors != want {
+				t.Errorf("ConsecutiveErrors: got %d, want %d", m.ConsecutiveErrors, want)
+			}
+			if !strings.HasPrefix(m.LastError, "panic: ") || !strings.Contains(m.LastError, "index out of range") {
+				t.Errorf("LastError: got %q", m.LastError)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for health report %d; collector loop died", want)
+		}
+	}
+}
+
 func TestCollector_ErrorHandling(t *testing.T) {
 	h := newHarness(5)
 	defer h.cancel()
@@ -158,6 +207,35 @@ func TestCollector_ContextCancellation(t *testing.T) {
 	}
 }
 
+func TestCollector_NoSendAfterCancel(t *testing.T) {
+	h := newHarness(10)
+	h.cancel()
+
+	var calls int
+	collect := func(ctx context.Context) ([]protocol.Metric, error) {
+		calls++
+		return []protocol.Metric{mockMetric{Value: 1}, mockMetric{Value: 2}}, nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.c.RunNamed(h.ctx, "cpu", 10*time.Millisecond, collect)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+	if n := len(h.out); n != 0 {
+		t.Errorf("%d envelopes written after cancellation", n)
+	}
+	if calls != 1 {
+		t.Errorf("collect called %d times, want only the baseline", calls)
+	}
+}
+
 func TestCollector_EmptyMetrics(t *testing.T) {
 	h := newHarness(5)
 	defer h.cancel()
@@ -267,6 +345,98 @@ func TestCollector_NilMetricInSlice(t *testing.T) {
 	}
 }
 
+func TestCollector_HealthReportsErrors(t *testing.T) {
+	h := newHarness(10)
+	defer h.cancel()
+	h.c.healthInterval = 30 * time.Millisecond
+
+	failingCollect := func(ctx context.Context) ([]protocol.Metric, error) {
+		return nil, errors.New("iw: executable file not found")
+	}
+
+	go h.c.RunNamed(h.ctx, "wifi", 10*time.Millisecond, failingCollect)
+
+	select {
+	case env := <-h.out:
+		if env.Type != "collector_health" {
+			t.Fatalf("expected collector_health envelope, got %s", env.Type)
+		}
+		m, ok := env.Data.(*protocol.CollectorHealthMetric)
+		if !ok {
+			t.Fatalf("expected *CollectorHealthMetric, got %T", env.Data)
+		}
+		if m.Name != "wifi" {
+			t.Errorf("Name: got %q, want wifi", m.Name)
+		}
+		if m.ConsecutiveErrors < 2 {
+			t.Errorf("ConsecutiveErrors: got %d, want >= 2", m.ConsecutiveErrors)
+		}
+		if m.LastError != "iw: executable file not found" {
+			t.Errorf("LastError: got %q", m.LastError)
+		}
+		if !m.LastSuccess.IsZero() {
+			t.Errorf("LastSuccess: got %v, want zero", m.LastSuccess)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("timed out waiting for health metric")
+	}
+}
+
+func TestCollector_HealthResetsOnSuccess(t *testing.T) {
+	h := newHarness(20)
+	defer h.cancel()
+	h.c.healthInterval = 50 * time.Millisecond
+
+	var calls int
+	var mu sync.Mutex
+
+	recovering := func(ctx context.Context) ([]protocol.Metric, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls <= 2 {
+			return nil, errors.New("fail")
+		}
+		return nil, nil
+	}
+
+	go h.c.RunNamed(h.ctx, "flaky", 10*time.Millisecond, recovering)
+
+	select {
+	case env := <-h.out:
+		m := env.Data.(*protocol.CollectorHealthMetric)
+		if m.ConsecutiveErrors != 0 {
+			t.Errorf("ConsecutiveErrors: got %d, want 0", m.ConsecutiveErrors)
+		}
+		if m.LastError != "" {
+			t.Errorf("LastError: got %q, want empty", m.LastError)
+		}
+		if m.LastSuccess.IsZero() {
+			t.Error("LastSuccess should be set after a successful run")
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("timed out waiting for health metric")
+	}
+}
+
+func TestCollector_UnnamedSkipsHealth(t *testing.T) {
+	h := newHarness(5)
+	defer h.cancel()
+	h.c.healthInterval = 10 * time.Millisecond
+
+	errorCollect := func(ctx context.Context) ([]protocol.Metric, error) {
+		return nil, errors.New("fail")
+	}
+
+	go h.c.Run(h.ctx, 10*time.Millisecond, errorCollect)
+
+	select {
+	case env := <-h.out:
+		t.Fatalf("unnamed collector should not report health, got %s", env.Type)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
 func BenchmarkCollector_Wrap(b *testing.B) {
 	c := New("test-host", make(chan protocol.Envelope, 100))
 	m := mockMetric{Value: 42}
@@ -276,3 +446,128 @@ func BenchmarkCollector_Wrap(b *testing.B) {
 		_ = c.wrap(m)
 	}
 }
+
+func TestNewJitter_Seeded(t *testing.T) {
+	a, b := newJitter(42), newJitter(42)
+	for range 10 {
+		x, y := a(time.Second), b(time.Second)
+		if x != y {
+			t.Fatalf("same seed gave different offsets: %v vs %v", x, y)
+		}
+		if x < 0 || x >= time.Second {
+			t.Fatalf("offset %v outside [0, 1s)", x)
+		}
+	}
+	if got := a(0); got != 0 {
+		t.Errorf("zero interval: got %v, want 0", got)
+	}
+}
+
+func TestCollector_NextDelay(t *testing.T) {
+	const interval = 10 * time.Second
+
+	c := New("test-host", nil)
+	var asked []time.Duration
+	offset := time.Duration(0)
+	c.jitter = func(d time.Duration) time.Duration {
+		asked = append(asked, d)
+		return offset
+	}
+
+	// First tick: an offset drawn over the whole interval
+	offset = 3 * time.Second
+	if got := c.nextDelay(interval, true); got != 3*time.Second {
+		t.Errorf("first delay: got %v, want 3s", got)
+	}
+
+	// Later ticks: interval +/-5%, drawn over a tenth of it
+	for _, tt := range []struct {
+		offset, want time.Duration
+	}{
+		{0, 9500 * time.Millisecond},
+		{500 * time.Millisecond, interval},
+		{time.Second - 1, 10500*time.Millisecond - 1},
+	} {
+		offset = tt.offset
+		if got := c.nextDelay(interval, false); got != tt.want {
+			t.Errorf("offset %v: got %v, want %v", tt.offset, got, tt.want)
+		}
+	}
+
+	want := []time.Duration{interval, time.Second, time.Second, time.Second}
+	if !slices.Equal(asked, want) {
+		t.Errorf("jitter ranges: got %v, want %v", asked, want)
+	}
+
+	c.jitter = nil
+	if got := c.nextDelay(interval, true); got != 0 {
+		t.Errorf("baseline without jitter: got %v, want 0", got)
+	}
+	if got := c.nextDelay(interval, false); got != interval {
+		t.Errorf("without jitter: got %v, want %v", got, interval)
+	}
+}
+
+// Collectors started together must not take their baseline together:
+// each waits its own offset into the interval before the first collection.
+func TestCollector_BaselineSpreadAcrossInterval(t *testing.T) {
+	const interval = 400 * time.Millisecond
+	seeds := []uint64{1, 2, 3, 4}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	firsts := make([]chan time.Duration, len(seeds))
+	offsets := make([]time.Duration, len(seeds))
+	for i, seed := range seeds {
+		offsets[i] = newJitter(seed)(interval)
+		firsts[i] = make(chan time.Duration, 1)
+
+		c := New("test-host", make(chan protocol.Envelope, 10))
+		c.jitter = newJitter(seed)
+		first := firsts[i]
+		go c.Run(ctx, interval, func(context.Context) ([]protocol.Metric, error) {
+			select {
+			case first <- time.Since(start):
+			default:
+			}
+			return nil, nil
+		})
+	}
+
+	got := make([]time.Duration, len(seeds))
+	for i, first := range firsts {
+		select {
+		case got[i] = <-first:
+		case <-time.After(2 * interval):
+			t.Fatalf("collector %d never took its baseline", i)
+		}
+		if got[i] < offsets[i] {
+			t.Errorf("collector %d: baseline after %v, before its %v offset", i, got[i], offsets[i])
+		}
+	}
+
+	if slices.Max(got)-slices.Min(got) < interval/10 {
+		t.Errorf("baselines fired in lockstep: %v", got)
+	}
+}
+
+func TestCollector_NextDelaySpreadsLaterTicks(t *testing.T) {
+	const interval = time.Second
+
+	c := New("test-host", nil)
+	c.jitter = newJitter(7)
+
+	seen := make(map[time.Duration]bool)
+	for range 20 {
+		d := c.nextDelay(interval, false)
+		if d < 950*time.Millisecond || d >= 1050*time.Millisecond {
+			t.Fatalf("delay %v outside interval +/-5%%", d)
+		}
+		seen[d] = true
+	}
+	if len(seen) < 2 {
+		t.Error("later ticks all drew the same delay")
+	}
+}
@@ -16,8 +16,11 @@ func Collect(ctx context.Context) ([]protocol.Metric, error) {
 	proxmoxGuests, proxmoxErr := collectProxmox(ctx)
 	result = append(result, proxmoxGuests...)
 
-	if dockerErr != nil && proxmoxErr != nil {
-		return nil, fmt.Errorf("docker: %w, proxmox: %w", dockerErr, proxmoxErr)
+	lxdInstances, lxdErr := collectLXDContainers(ctx)
+	result = append(result, lxdInstances...)
+
+	if dockerErr != nil && proxmoxErr != nil && lxdErr != nil {
+		return nil, fmt.Errorf("docker: %w, proxmox: %w, lxd: %w", dockerErr, proxmoxErr, lxdErr)
 	}
 
 	return []protocol.Metric{
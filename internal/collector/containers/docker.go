@@ -25,6 +25,7 @@ const (
 type DockerClient interface {
 	ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error)
 	ContainerStats(ctx context.Context, containerID string, stream bool) (container.StatsResponseReader, error)
+	ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error)
 	Close() error
 }
 
@@ -123,45 +124,14 @@ func collectDocker(ctx context.Context) ([]protocol.ContainerMetric, error) {
 				return
 			}
 
-			cpuPercent := calculateCPUPercent(&stats)
-
-			memUsage := float64(stats.MemoryStats.Usage)
-			if v, ok := stats.MemoryStats.Stats["inactive_file"]; ok {
-				memUsage -= float64(v)
-			}
-			if memUsage < 0 {
-				memUsage = float64(stats.MemoryStats.Usage)
-			}
-
-			numCores := uint32(len(stats.CPUStats.CPUUsage.PercpuUsage))
-			if stats.CPUStats.OnlineCPUs > 0 {
-				numCores = stats.CPUStats.OnlineCPUs
-			}
-
-			id := c.ID
-			if len(id) > 12 {
-				id = id[:12]
+			// Restart count and health are only in the inspect response;
+			// without it the metric still goes out with those left empty
+			var info *container.InspectResponse
+			if resp, err := dockerCli.ContainerInspect(ctx, c.ID); err == nil {
+				info = &resp
 			}
 
-			rxBytes, txBytes := calculateNet(stats.Networks)
-
-			results <- result{
-				metric: protocol.ContainerMetric{
-					ID:            id,
-					Name:          strings.TrimPrefix(c.Names[0], "/"),
-					Image:         c.Image,
-					State:         c.State,
-					Source:        dockerSource,
-					Kind:          kindContainer,
-					CPUPercent:    cpuPercent,
-					CPULimitCores: numCores,
-					MemoryBytes:   uint64(memUsage),
-					MemoryLimit:   stats.MemoryStats.Limit,
-					NetRxBytes:    rxBytes,
-					NetTxBytes:    txBytes,
-				},
-				ok: true,
-			}
+			results <- result{metric: dockerMetric(c, &stats, info), ok: true}
 		}(c)
 	}
 
@@ -176,6 +146,70 @@ func collectDocker(ctx context.Context) ([]protocol.ContainerMetric, error) {
 	return metrics, nil
 }
 
+// dockerMetric maps a container's list entry, stats and (optional) inspect
+// response to a ContainerMetric.
+func dockerMetric(c container.Summary, stats *DockerStats, info *container.InspectResponse) protocol.ContainerMetric {
+	memUsage := float64(stats.MemoryStats.Usage)
+	if v, ok := stats.MemoryStats.Stats["inactive_file"]; ok {
+		memUsage -= float64(v)
+	}
+	if memUsage < 0 {
+		memUsage = float64(stats.MemoryStats.Usage)
+	}
+
+	numCores := uint32(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	if stats.CPUStats.OnlineCPUs > 0 {
+		numCores = stats.CPUStats.OnlineCPUs
+	}
+
+	id := c.ID
+	if len(id) > 12 {
+		id = id[:12]
+	}
+
+	rxBytes, txBytes := calculateNet(stats.Networks)
+
+	m := protocol.ContainerMetric{
+		ID:            id,
+		Name:          strings.TrimPrefix(c.Names[0], "/"),
+		Image:         c.Image,
+		State:         c.State,
+		Source:        dockerSource,
+		Kind:          kindContainer,
+		CPUPercent:    calculateCPUPercent(stats),
+		CPULimitCores: numCores,
+		MemoryBytes:   uint64(memUsage),
+		MemoryLimit:   stats.MemoryStats.Limit,
+		NetRxBytes:    rxBytes,
+		NetTxBytes:    txBytes,
+		Health:        healthFromStatus(c.Status),
+	}
+
+	if info != nil && info.ContainerJSONBase != nil {
+		m.RestartCount = info.RestartCount
+		if info.State != nil && info.State.Health != nil {
+			m.Health = string(info.State.Health.Status)
+		}
+	}
+
+	return m
+}
+
+// healthFromStatus extracts the healthcheck state from a list entry's
+// Status, e.g. "Up 2 hours (unhealthy)" or "Up 5 seconds (health: starting)".
+// It is empty for containers without a healthcheck.
+func healthFromStatus(status string) string {
+	switch {
+	case strings.HasSuffix(status, "(healthy)"):
+		return string(container.Healthy)
+	case strings.HasSuffix(status, "(unhealthy)"):
+		return string(container.Unhealthy)
+	case strings.HasSuffix(status, "(health: starting)"):
+		return string(container.Starting)
+	}
+	return ""
+}
+
 func calculateCPUPercent(v *DockerStats) float64 {
 	var cpuPercent float64
 	cpuDelta := float64(v.CPUStats.CPUUsage.TotalUsage) - float64(v.PreCPUStats.CPUUsage.TotalUsage)
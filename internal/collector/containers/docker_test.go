@@ -18,6 +18,7 @@ import (
 type mockDockerClient struct {
 	containers []container.Summary
 	statsDelay time.Duration
+	inspect    map[string]container.InspectResponse
 }
 
 func (m *mockDockerClient) ContainerList(ctx context.Context, opts container.ListOptions) ([]container.Summary, error) {
@@ -59,6 +60,14 @@ func (m *mockDockerClient) ContainerStats(ctx context.Context, id string, stream
 	}, nil
 }
 
+func (m *mockDockerClient) ContainerInspect(ctx context.Context, id string) (container.InspectResponse, error) {
+	resp, ok := m.inspect[id]
+	if !ok {
+		return container.InspectResponse{}, fmt.Errorf("no such container: %s", id)
+	}
+	return resp, nil
+}
+
 func (m *mockDockerClient) Close() error {
 	return nil
 }
@@ -438,6 +447,78 @@ func TestContainerIDTruncation(t *testing.T) {
 	}
 }
 
+func TestCollectDocker_RestartCountAndHealth(t *testing.T) {
+	containers := makeMockContainers(2)
+	containers[0].Status = "Up 3 seconds (healthy)"
+	containers[1].Status = "Up 5 seconds (health: starting)"
+
+	dockerCli = &mockDockerClient{
+		containers: containers,
+		inspect: map[string]container.InspectResponse{
+			containers[0].ID: {
+				ContainerJSONBase: &container.ContainerJSONBase{
+					RestartCount: 7,
+					State: &container.State{
+						Health: &container.Health{Status: container.Unhealthy},
+					},
+				},
+			},
+		},
+	}
+	defer func() { dockerCli = nil }()
+
+	metrics, err := collectDocker(context.Background())
+	if err != nil {
+		t.Fatalf("collectDocker: %v", err)
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("got %d metrics, want 2", len(metrics))
+	}
+
+	byName := make(map[string]int)
+	for i, m := range metrics {
+		byName[m.Name] = i
+	}
+
+	// Inspect wins over the list status
+	m := metrics[byName["container-0"]]
+	if m.RestartCount != 7 {
+		t.Errorf("container-0 RestartCount = %d, want 7", m.RestartCount)
+	}
+	if m.Health != "unhealthy" {
+		t.Errorf("container-0 Health = %q, want unhealthy", m.Health)
+	}
+
+	// Inspect failed: health falls back to the list status
+	m = metrics[byName["container-1"]]
+	if m.RestartCount != 0 {
+		t.Errorf("container-1 RestartCount = %d, want 0", m.RestartCount)
+	}
+	if m.Health != "starting" {
+		t.Errorf("container-1 Health = %q, want starting", m.Health)
+	}
+}
+
+func TestHealthFromStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   string
+	}{
+		{"Up 2 hours (healthy)", "healthy"},
+		{"Up 2 hours (unhealthy)", "unhealthy"},
+		{"Up 5 seconds (health: starting)", "starting"},
+		{"Up 2 hours", ""},
+		{"Exited (1) 3 minutes ago", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := healthFromStatus(tt.status); got != tt.want {
+			t.Errorf("healthFromStatus(%q) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
 func BenchmarkCalculateCPUPercent(b *testing.B) {
 	stats := &DockerStats{
 		CPUStats: DockerCPUStats{
@@ -0,0 +1,219 @@
+//go:build linux
+
+package containers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const (
+	lxdSource         = "lxd"
+	lxdTypeVM         = "virtual-machine"
+	lxdLoopbackIface  = "lo"
+	lxdCommandTimeout = 5 * time.Second
+)
+
+// lxdInstance is the subset of an `lxc list --format json` entry used here.
+type lxdInstance struct {
+	Name   string            `json:"name"`
+	Type   string            `json:"type"` // "container" or "virtual-machine"
+	Status string            `json:"status"`
+	Config map[string]string `json:"config"`
+	State  *lxdState         `json:"state"`
+}
+
+type lxdState struct {
+	CPU struct {
+		Usage uint64 `json:"usage"` // nanoseconds, cumulative
+	} `json:"cpu"`
+	Memory struct {
+		Usage uint64 `json:"usage"`
+		Total uint64 `json:"total"`
+	} `json:"memory"`
+	Network map[string]struct {
+		Counters struct {
+			BytesReceived uint64 `json:"bytes_received"`
+			BytesSent     uint64 `json:"bytes_sent"`
+		} `json:"counters"`
+	} `json:"network"`
+}
+
+type lxdCPUSample struct {
+	usage uint64
+	at    time.Time
+}
+
+var (
+	lxdPrevCPU   = make(map[string]lxdCPUSample)
+	lxdPrevCPUMu sync.Mutex
+)
+
+func collectLXDContainers(ctx context.Context) ([]protocol.ContainerMetric, error) {
+	if !hasCommand("lxc") {
+		return nil, nil
+	}
+
+	out, err := runLXCList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	instances, err := parseLXDList(out)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	lxdPrevCPUMu.Lock()
+	defer lxdPrevCPUMu.Unlock()
+
+	metrics := make([]protocol.ContainerMetric, 0, len(instances))
+	seen := make(map[string]bool, len(instances))
+	for _, inst := range instances {
+		m := lxdMetric(inst)
+
+		if inst.State != nil {
+			cur := lxdCPUSample{usage: inst.State.CPU.Usage, at: now}
+			if prev, ok := lxdPrevCPU[inst.Name]; ok {
+				m.CPUPercent = lxdCPUPercent(prev, cur)
+			}
+			lxdPrevCPU[inst.Name] = cur
+			seen[inst.Name] = true
+		}
+
+		metrics = append(metrics, m)
+	}
+
+	// Forget deleted instances so a recreated one starts fresh
+	for name := range lxdPrevCPU {
+		if !seen[name] {
+			delete(lxdPrevCPU, name)
+		}
+	}
+
+	return metrics, nil
+}
+
+func runLXCList(ctx context.Context) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, lxdCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "lxc", "list", "--format", "json")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ctx.Err()
+		}
+		if stderr.Len() > 0 {
+			return nil, errors.New(strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	}
+
+	return stdout.Bytes(), nil
+}
+
+func parseLXDList(data []byte) ([]lxdInstance, error) {
+	var instances []lxdInstance
+	if err := json.Unmarshal(data, &instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// lxdMetric maps an instance to a ContainerMetric. CPUPercent is left zero;
+// it needs the previous sample and is filled in by the caller.
+func lxdMetric(inst lxdInstance) protocol.ContainerMetric {
+	kind := kindLXC
+	if inst.Type == lxdTypeVM {
+		kind = kindVM
+	}
+
+	m := protocol.ContainerMetric{
+		ID:            inst.Name,
+		Name:          inst.Name,
+		Image:         inst.Config["image.description"],
+		State:         strings.ToLower(inst.Status),
+		Source:        lxdSource,
+		Kind:          kind,
+		CPULimitCores: parseLXDCPULimit(inst.Config["limits.cpu"]),
+	}
+
+	if inst.State == nil {
+		return m
+	}
+
+	m.MemoryBytes = inst.State.Memory.Usage
+	m.MemoryLimit = inst.State.Memory.Total
+
+	for name, iface := range inst.State.Network {
+		if name == lxdLoopbackIface {
+			continue
+		}
+		m.NetRxBytes += iface.Counters.BytesReceived
+		m.NetTxBytes += iface.Counters.BytesSent
+	}
+
+	return m
+}
+
+// lxdCPUPercent converts the cumulative CPU time between two samples into
+// percent of one core, matching the Docker and Proxmox collectors.
+func lxdCPUPercent(prev, cur lxdCPUSample) float64 {
+	elapsed := cur.at.Sub(prev.at)
+	if elapsed <= 0 || cur.usage < prev.usage {
+		return 0
+	}
+	return float64(cur.usage-prev.usage) / float64(elapsed.Nanoseconds()) * 100.0
+}
+
+// parseLXDCPULimit reads limits.cpu, which is either a core count ("2") or
+// a CPU set ("0-3", "1,3,5-7").
+func parseLXDCPULimit(s string) uint32 {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+
+	if !strings.ContainsAny(s, ",-") {
+		n, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return 0
+		}
+		return uint32(n)
+	}
+
+	var count uint32
+	for part := range strings.SplitSeq(s, ",") {
+		lo, hi, isRange := strings.Cut(part, "-")
+		if !isRange {
+			if _, err := strconv.ParseUint(part, 10, 32); err != nil {
+				return 0
+			}
+			count++
+			continue
+		}
+		start, err1 := strconv.ParseUint(lo, 10, 32)
+		end, err2 := strconv.ParseUint(hi, 10, 32)
+		if err1 != nil || err2 != nil || end < start {
+			return 0
+		}
+		count += uint32(end - start + 1)
+	}
+	return count
+}
@@ -0,0 +1,146 @@
+//go:build linux
+
+package containers
+
+import (
+	"testing"
+	"time"
+)
+
+const sampleLXCList = `[
+  {
+    "name": "web",
+    "type": "container",
+    "status": "Running",
+    "config": {
+      "image.description": "Ubuntu noble amd64 (20240101_07:42)",
+      "limits.cpu": "2"
+    },
+    "state": {
+      "status": "Running",
+      "cpu": {"usage": 5000000000},
+      "memory": {"usage": 268435456, "usage_peak": 0, "total": 1073741824},
+      "network": {
+        "eth0": {"counters": {"bytes_received": 1000, "bytes_sent": 2000}},
+        "eth1": {"counters": {"bytes_received": 300, "bytes_sent": 400}},
+        "lo": {"counters": {"bytes_received": 99999, "bytes_sent": 99999}}
+      }
+    }
+  },
+  {
+    "name": "win-vm",
+    "type": "virtual-machine",
+    "status": "Stopped",
+    "config": {"limits.cpu": "0-3"},
+    "state": {
+      "status": "Stopped",
+      "cpu": {"usage": 0},
+      "memory": {"usage": 0, "total": 0},
+      "network": null
+    }
+  },
+  {
+    "name": "bare",
+    "type": "container",
+    "status": "Frozen",
+    "config": {},
+    "state": null
+  }
+]`
+
+func TestParseLXDList(t *testing.T) {
+	instances, err := parseLXDList([]byte(sampleLXCList))
+	if err != nil {
+		t.Fatalf("parseLXDList: %v", err)
+	}
+	if len(instances) != 3 {
+		t.Fatalf("got %d instances, want 3", len(instances))
+	}
+
+	web := lxdMetric(instances[0])
+	if web.ID != "web" || web.Name != "web" {
+		t.Errorf("web ID/Name = %q/%q", web.ID, web.Name)
+	}
+	if web.Source != "lxd" || web.Kind != "lxc" {
+		t.Errorf("web Source/Kind = %q/%q, want lxd/lxc", web.Source, web.Kind)
+	}
+	if web.State != "running" {
+		t.Errorf("web State = %q, want running", web.State)
+	}
+	if web.Image != "Ubuntu noble amd64 (20240101_07:42)" {
+		t.Errorf("web Image = %q", web.Image)
+	}
+	if web.CPULimitCores != 2 {
+		t.Errorf("web CPULimitCores = %d, want 2", web.CPULimitCores)
+	}
+	if web.MemoryBytes != 268435456 || web.MemoryLimit != 1073741824 {
+		t.Errorf("web memory = %d/%d", web.MemoryBytes, web.MemoryLimit)
+	}
+	// lo excluded
+	if web.NetRxBytes != 1300 || web.NetTxBytes != 2400 {
+		t.Errorf("web net = %d/%d, want 1300/2400", web.NetRxBytes, web.NetTxBytes)
+	}
+
+	vm := lxdMetric(instances[1])
+	if vm.Kind != "vm" || vm.State != "stopped" {
+		t.Errorf("vm Kind/State = %q/%q, want vm/stopped", vm.Kind, vm.State)
+	}
+	if vm.CPULimitCores != 4 {
+		t.Errorf("vm CPULimitCores = %d, want 4", vm.CPULimitCores)
+	}
+
+	bare := lxdMetric(instances[2])
+	if bare.State != "frozen" || bare.MemoryBytes != 0 {
+		t.Errorf("bare = %+v", bare)
+	}
+}
+
+func TestParseLXDList_Invalid(t *testing.T) {
+	if _, err := parseLXDList([]byte("not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestLXDCPUPercent(t *testing.T) {
+	start := time.Now()
+	tests := []struct {
+		name string
+		prev lxdCPUSample
+		cur  lxdCPUSample
+		want float64
+	}{
+		{"half core", lxdCPUSample{1e9, start}, lxdCPUSample{1.5e9, start.Add(time.Second)}, 50},
+		{"two cores", lxdCPUSample{0, start}, lxdCPUSample{20e9, start.Add(10 * time.Second)}, 200},
+		{"counter reset", lxdCPUSample{5e9, start}, lxdCPUSample{1e9, start.Add(time.Second)}, 0},
+		{"no elapsed", lxdCPUSample{1e9, start}, lxdCPUSample{2e9, start}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lxdCPUPercent(tt.prev, tt.cur); got != tt.want {
+				t.Errorf("lxdCPUPercent = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLXDCPULimit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want uint32
+	}{
+		{"", 0},
+		{"4", 4},
+		{"0-3", 4},
+		{"1,3,5-7", 5},
+		{"0", 0},
+		{"abc", 0},
+		{"3-1", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseLXDCPULimit(tt.in); got != tt.want {
+			t.Errorf("parseLXDCPULimit(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
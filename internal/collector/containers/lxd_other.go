@@ -0,0 +1,13 @@
+//go:build !linux
+
+package containers
+
+import (
+	"context"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func collectLXDContainers(ctx context.Context) ([]protocol.ContainerMetric, error) {
+	return nil, nil
+}
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -18,23 +20,40 @@ import (
 // Package-level state for delta calculation
 var lastRawData map[string]Raw
 
+// deltaScratch is reused by Collect for each cycle's deltas. The core
+// usage slice is not reused, since the emitted CPUMetric keeps it.
+var deltaScratch map[string]Delta
+
+const cpuSysfsPath = "/sys/devices/system/cpu"
+
+// Collect reports CPU usage and, from the same /proc/stat read, context
+// switch and interrupt rates. Both need a previous sample, so the first
+// call only records a baseline.
 func Collect(ctx context.Context) ([]protocol.Metric, error) {
-	cur, err := parseProcStat()
+	stat, err := parseProcStat()
 	if err != nil {
 		return nil, fmt.Errorf("parsing /proc/stat: %w", err)
 	}
 
+	var metrics []protocol.Metric
+	if m, ok := collectSched(stat); ok {
+		metrics = append(metrics, m)
+	}
+
+	cur := stat.CPU
+
 	// First sample - store and skip
 	if len(lastRawData) == 0 {
 		lastRawData = cur
-		return nil, nil
+		return metrics, nil
 	}
 
-	deltaMap, ok := calculateDeltas(cur, lastRawData)
+	deltaMap, ok := calculateDeltasInto(deltaScratch, cur, lastRawData)
 	if !ok {
 		lastRawData = nil
-		return nil, nil
+		return metrics, nil
 	}
+	deltaScratch = deltaMap
 	lastRawData = cur
 
 	usage := util.Percent(deltaMap["cpu"].Used, deltaMap["cpu"].Total)
@@ -46,30 +65,56 @@ func Collect(ctx context.Context) ([]protocol.Metric, error) {
 		return nil, fmt.Errorf("parsing /proc/loadavg: %w", err)
 	}
 
-	return []protocol.Metric{protocol.CPUMetric{
+	freqMHz, governor := parseCPUFreqFrom(cpuSysfsPath)
+
+	return append([]protocol.Metric{protocol.CPUMetric{
 		Usage:     usage,
 		CoreUsage: coreUsage,
 		IOWait:    iowait,
 		LoadAvg1:  load1,
 		LoadAvg5:  load5,
 		LoadAvg15: load15,
-	}}, nil
+		FreqMHz:   freqMHz,
+		Governor:  governor,
+	}}, metrics...), nil
 }
 
 // calculateDeltas takes the current and previous raw maps and returns a map containing
 // the delta for each key (cpu, cpu0, ...)
 func calculateDeltas(current, previous map[string]Raw) (map[string]Delta, bool) {
-	deltaMap := make(map[string]Delta)
+	return calculateDeltasInto(nil, current, previous)
+}
+
+// calculateDeltasInto is calculateDeltas writing into dst, which is
+// cleared first so cores from an earlier cycle never linger. A nil dst
+// allocates a new map.
+//
+// Cores that appear or disappear between samples (hotplug), or whose
+// counters went backwards, are skipped so the remaining cores still
+// report. Only a missing or regressed aggregate "cpu" line fails the
+// whole sample.
+func calculateDeltasInto(dst map[string]Delta, current, previous map[string]Raw) (map[string]Delta, bool) {
+	if dst == nil {
+		dst = make(map[string]Delta, len(current))
+	}
+	clear(dst)
+	deltaMap := dst
 
 	for key, cur := range current {
 		prev, ok := previous[key]
 		if !ok {
-			return nil, false
+			if key == "cpu" {
+				return nil, false
+			}
+			continue
 		}
 
 		if cur.User < prev.User || cur.Nice < prev.Nice || cur.System < prev.System || cur.Idle < prev.Idle || cur.IOWait < prev.IOWait ||
 			cur.IRQ < prev.IRQ || cur.SoftIRQ < prev.SoftIRQ || cur.Steal < prev.Steal {
-			return nil, false
+			if key == "cpu" {
+				return nil, false
+			}
+			continue
 		}
 
 		delta := Delta{}
@@ -92,39 +137,74 @@ func calculateDeltas(current, previous map[string]Raw) (map[string]Delta, bool)
 		deltaMap[key] = delta
 	}
 
+	if _, ok := deltaMap["cpu"]; !ok {
+		return nil, false
+	}
 	return deltaMap, true
 }
 
-func parseProcStat() (map[string]Raw, error) {
+// procStat is one read of /proc/stat: the per-CPU times plus the ctxt
+// and intr totals that follow them.
+type procStat struct {
+	CPU      map[string]Raw
+	Sched    schedRaw
+	HasSched bool
+}
+
+func parseProcStat() (procStat, error) {
 	f, err := os.Open("/proc/stat")
 	if err != nil {
-		return nil, err
+		return procStat{}, err
 	}
 	defer f.Close()
 
 	return parseProcStatFrom(f)
 }
 
-func parseProcStatFrom(r io.Reader) (map[string]Raw, error) {
-	result := make(map[string]Raw)
+// parseProcStatFrom reads the cpu lines and the ctxt and intr totals,
+// stopping once it has both. HasSched is false if either is missing.
+func parseProcStatFrom(r io.Reader) (procStat, error) {
+	stat := procStat{CPU: make(map[string]Raw)}
+	var haveCtxt, haveIntr bool
+	inCPU := true // the cpu lines come first, as one block
+
 	scanner := bufio.NewScanner(r)
+	// intr lists every IRQ and can be long on large machines
+	scanner.Buffer(nil, 1024*1024)
 
-	for scanner.Scan() {
+	for !(haveCtxt && haveIntr) && scanner.Scan() {
 		line := scanner.Text()
-		if !strings.HasPrefix(line, "cpu") {
-			break
+		if inCPU && strings.HasPrefix(line, "cpu") {
+			raw, err := parseCPULine(line)
+			if err != nil {
+				continue
+			}
+
+			fields := strings.Fields(line)
+			stat.CPU[fields[0]] = raw
+			continue
 		}
+		inCPU = false
 
-		raw, err := parseCPULine(line)
-		if err != nil {
+		key, rest, _ := strings.Cut(line, " ")
+		if key != "ctxt" && key != "intr" {
 			continue
 		}
-
-		fields := strings.Fields(line)
-		result[fields[0]] = raw
+		// intr's first value is the total; the rest are per-IRQ
+		total, _, _ := strings.Cut(rest, " ")
+		n, err := strconv.ParseUint(total, 10, 64)
+		if err != nil {
+			return stat, fmt.Errorf("%s: %w", key, err)
+		}
+		if key == "ctxt" {
+			stat.Sched.Ctxt, haveCtxt = n, true
+		} else {
+			stat.Sched.Intr, haveIntr = n, true
+		}
 	}
 
-	return result, scanner.Err()
+	stat.HasSched = haveCtxt && haveIntr
+	return stat, scanner.Err()
 }
 
 func parseCPULine(line string) (Raw, error) {
@@ -149,16 +229,20 @@ func parseCPULine(line string) (Raw, error) {
 	}, nil
 }
 
-// calcCoreUsage returns per-core CPU usage util.Percentages.
-// Assumes contiguous core numbering (cpu0, cpu1, ..., cpuN-1).
-// Missing cores will show 0% usage.
+// calcCoreUsage returns per-core CPU usage util.Percentages, indexed by
+// core number. Cores missing from deltaMap, such as offline CPUs leaving a
+// gap in the numbering, show 0% usage.
 func calcCoreUsage(deltaMap map[string]Delta) []float64 {
-	numCores := len(deltaMap) - 1 // exclude aggregate "cpu"
-	usage := make([]float64, numCores)
+	numCores := 0
+	for key := range deltaMap {
+		if i, ok := coreIndex(key); ok && i >= numCores {
+			numCores = i + 1
+		}
+	}
 
-	for i := range numCores {
-		coreKey := fmt.Sprintf("cpu%d", i)
-		if delta, ok := deltaMap[coreKey]; ok && delta.Total > 0 {
+	usage := make([]float64, numCores)
+	for key, delta := range deltaMap {
+		if i, ok := coreIndex(key); ok && delta.Total > 0 {
 			usage[i] = util.Percent(delta.Used, delta.Total)
 		}
 	}
@@ -166,6 +250,19 @@ func calcCoreUsage(deltaMap map[string]Delta) []float64 {
 	return usage
 }
 
+// coreIndex returns N for a per-core /proc/stat key "cpuN".
+func coreIndex(key string) (int, bool) {
+	num, ok := strings.CutPrefix(key, "cpu")
+	if !ok || num == "" {
+		return 0, false
+	}
+	i, err := strconv.Atoi(num)
+	if err != nil || i < 0 {
+		return 0, false
+	}
+	return i, true
+}
+
 func parseLoadAvg() (load1, load5, load15 float64, err error) {
 	data, err := os.ReadFile("/proc/loadavg")
 	if err != nil {
@@ -194,3 +291,37 @@ func parseLoadAvg() (load1, load5, load15 float64, err error) {
 
 	return load1, load5, load15, nil
 }
+
+// parseCPUFreqFrom reads cpufreq data for every core under root
+// (normally /sys/devices/system/cpu). It returns the average current
+// frequency in MHz and the scaling governor of the first core that
+// reports one. Both are zero/empty when cpufreq isn't exposed.
+func parseCPUFreqFrom(root string) (freqMHz float64, governor string) {
+	dirs, err := filepath.Glob(filepath.Join(root, "cpu[0-9]*", "cpufreq"))
+	if err != nil || len(dirs) == 0 {
+		return 0, ""
+	}
+	sort.Strings(dirs)
+
+	var sumKHz uint64
+	var n int
+	for _, dir := range dirs {
+		if data, err := os.ReadFile(filepath.Join(dir, "scaling_cur_freq")); err == nil {
+			if khz, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64); err == nil {
+				sumKHz += khz
+				n++
+			}
+		}
+		if governor == "" {
+			if data, err := os.ReadFile(filepath.Join(dir, "scaling_governor")); err == nil {
+				governor = strings.TrimSpace(string(data))
+			}
+		}
+	}
+
+	if n > 0 {
+		freqMHz = float64(sumKHz) / float64(n) / 1000
+	}
+
+	return freqMHz, governor
+}
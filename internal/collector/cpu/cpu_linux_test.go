@@ -13,6 +13,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/nhdewitt/spectra/internal/protocol"
 	"github.com/nhdewitt/spectra/internal/util"
 )
 
@@ -29,7 +30,7 @@ func parseFixture(t *testing.T, key string) map[string]Raw {
 	if err != nil {
 		t.Fatalf("parsing fixture %q: %v", key, err)
 	}
-	return got
+	return got.CPU
 }
 
 func BenchmarkCollect(b *testing.B) {
@@ -264,13 +265,85 @@ func TestCalculateDelta_MissingPreviousKey(t *testing.T) {
 	}
 
 	got, ok := calculateDeltas(current, previous)
+	if !ok {
+		t.Fatal("expected ok=true when only a core is new")
+	}
+	if _, ok := got["cpu0"]; ok {
+		t.Error("new core cpu0 should be skipped")
+	}
+	if got["cpu"].User != 50 {
+		t.Errorf("aggregate User delta: got %d, want 50", got["cpu"].User)
+	}
+}
+
+func TestCalculateDelta_MissingAggregate(t *testing.T) {
+	current := map[string]Raw{
+		"cpu":  {User: 100},
+		"cpu0": {User: 50},
+	}
+	previous := map[string]Raw{
+		"cpu0": {User: 25},
+	}
 
-	if ok {
-		t.Error("expected ok=-false when previous key is missing")
+	if got, ok := calculateDeltas(current, previous); ok || got != nil {
+		t.Errorf("expected nil,false without a previous aggregate, got %v,%v", got, ok)
+	}
+}
+
+func TestCalculateDelta_CoreOffline(t *testing.T) {
+	previous := map[string]Raw{
+		"cpu":  {User: 300, Idle: 300},
+		"cpu0": {User: 100, Idle: 100},
+		"cpu1": {User: 100, Idle: 100},
+		"cpu2": {User: 100, Idle: 100},
+	}
+	current := map[string]Raw{
+		"cpu":  {User: 400, Idle: 400},
+		"cpu0": {User: 150, Idle: 150},
+		"cpu2": {User: 150, Idle: 150},
 	}
 
-	if got != nil {
-		t.Error("expected nil map when previous key is missing")
+	got, ok := calculateDeltas(current, previous)
+	if !ok {
+		t.Fatal("expected ok=true when a core goes offline")
+	}
+	if _, ok := got["cpu1"]; ok {
+		t.Error("offline core cpu1 should not produce a delta")
+	}
+	for _, key := range []string{"cpu0", "cpu2"} {
+		if d := got[key]; d.Used != 50 || d.Total != 100 {
+			t.Errorf("%s: got Used %d Total %d, want 50/100", key, d.Used, d.Total)
+		}
+	}
+}
+
+func TestCalculateDelta_CoreOnline(t *testing.T) {
+	previous := map[string]Raw{
+		"cpu":  {User: 200, Idle: 200},
+		"cpu0": {User: 100, Idle: 100},
+		"cpu1": {User: 100, Idle: 100},
+	}
+	current := map[string]Raw{
+		"cpu":  {User: 300, Idle: 300},
+		"cpu0": {User: 150, Idle: 150},
+		"cpu1": {User: 150, Idle: 150},
+		"cpu2": {User: 5, Idle: 5},
+	}
+
+	got, ok := calculateDeltas(current, previous)
+	if !ok {
+		t.Fatal("expected ok=true when a core comes online")
+	}
+	if _, ok := got["cpu2"]; ok {
+		t.Error("new core cpu2 should be skipped until it has a baseline")
+	}
+	for _, key := range []string{"cpu0", "cpu1"} {
+		if d := got[key]; d.Used != 50 || d.Total != 100 {
+			t.Errorf("%s: got Used %d Total %d, want 50/100", key, d.Used, d.Total)
+		}
+	}
+	if usage := calcCoreUsage(got); len(usage) != 2 {
+		t.Errorf("core usage length: got %d, want 2", len(usage))
 	}
 }
 
@@ -347,7 +420,7 @@ func TestCalcCoreUsage_ZeroTotal(t *testing.T) {
 }
 
 func TestCalcCoreUsage_SparseCores(t *testing.T) {
-	// Edge case: What if cpu1 is missing but cpu0 and cpu2 exist?
+	// cpu1 offline: cpu2 keeps its index and the gap reads 0%
 	deltaMap := map[string]Delta{
 		"cpu":  {Used: 100, Total: 200},
 		"cpu0": {Used: 50, Total: 100},
@@ -357,12 +430,14 @@ func TestCalcCoreUsage_SparseCores(t *testing.T) {
 
 	got := calcCoreUsage(deltaMap)
 
-	if len(got) != 2 {
-		t.Errorf("got %d cores, want 2", len(got))
+	want := []float64{50.0, 0, 75.0}
+	if len(got) != len(want) {
+		t.Fatalf("got %d cores, want %d", len(got), len(want))
 	}
-
-	if got[1] != 0 {
-		t.Errorf("missing core should have 0 usage, got %f", got[1])
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("core %d: got %f, want %f", i, got[i], want[i])
+		}
 	}
 }
 
@@ -390,6 +465,66 @@ func BenchmarkCalculateDelta(b *testing.B) {
 	}
 }
 
+func BenchmarkCalculateDeltaInto(b *testing.B) {
+	current := map[string]Raw{
+		"cpu":  {User: 200, Nice: 20, System: 50, Idle: 1000, IOWait: 10, IRQ: 5, SoftIRQ: 3, Steal: 1, Guest: 0},
+		"cpu0": {User: 100, Nice: 10, System: 25, Idle: 500, IOWait: 5, IRQ: 2, SoftIRQ: 1, Steal: 0, Guest: 0},
+		"cpu1": {User: 100, Nice: 10, System: 25, Idle: 500, IOWait: 5, IRQ: 3, SoftIRQ: 2, Steal: 1, Guest: 0},
+	}
+	previous := map[string]Raw{
+		"cpu":  {User: 100, Nice: 10, System: 25, Idle: 500, IOWait: 5, IRQ: 2, SoftIRQ: 1, Steal: 0, Guest: 0},
+		"cpu0": {User: 50, Nice: 5, System: 12, Idle: 250, IOWait: 2, IRQ: 1, SoftIRQ: 0, Steal: 0, Guest: 0},
+		"cpu1": {User: 50, Nice: 5, System: 12, Idle: 250, IOWait: 2, IRQ: 1, SoftIRQ: 1, Steal: 0, Guest: 0},
+	}
+	scratch := make(map[string]Delta, len(current))
+
+	b.ReportAllocs()
+	for b.Loop() {
+		scratch, _ = calculateDeltasInto(scratch, current, previous)
+	}
+}
+
+func TestCalculateDeltasInto_ReuseDropsStaleCores(t *testing.T) {
+	fourCores := map[string]Raw{
+		"cpu":  {User: 400, Idle: 400},
+		"cpu0": {User: 100, Idle: 100},
+		"cpu1": {User: 100, Idle: 100},
+		"cpu2": {User: 100, Idle: 100},
+		"cpu3": {User: 100, Idle: 100},
+	}
+	scratch, ok := calculateDeltasInto(nil, fourCores, fourCores)
+	if !ok || len(scratch) != 5 {
+		t.Fatalf("first cycle: ok=%v len=%d, want ok and 5 entries", ok, len(scratch))
+	}
+
+	// cpu2 and cpu3 go offline
+	twoCores := map[string]Raw{
+		"cpu":  {User: 300, Idle: 300},
+		"cpu0": {User: 150, Idle: 150},
+		"cpu1": {User: 150, Idle: 150},
+	}
+	prev := map[string]Raw{
+		"cpu":  {User: 200, Idle: 200},
+		"cpu0": {User: 100, Idle: 100},
+		"cpu1": {User: 100, Idle: 100},
+	}
+	got, ok := calculateDeltasInto(scratch, twoCores, prev)
+	if !ok {
+		t.Fatal("second cycle: expected ok")
+	}
+	if len(got) != 3 {
+		t.Errorf("second cycle: got %d entries, want 3: %v", len(got), got)
+	}
+	for _, stale := range []string{"cpu2", "cpu3"} {
+		if _, ok := got[stale]; ok {
+			t.Errorf("stale core %s still present after reuse", stale)
+		}
+	}
+	if usage := calcCoreUsage(got); len(usage) != 2 || usage[0] != 50 || usage[1] != 50 {
+		t.Errorf("core usage after reuse: got %v, want [50 50]", usage)
+	}
+}
+
 func BenchmarkCalcCoreUsage(b *testing.B) {
 	deltaMap := map[string]Delta{
 		"cpu":  {Used: 100, Total: 200},
@@ -412,10 +547,11 @@ func TestParseProcStat_RealFilesystem(t *testing.T) {
 		t.Skip("skipping: requires Linux /proc filesystem")
 	}
 
-	result, err := parseProcStat()
+	stat, err := parseProcStat()
 	if err != nil {
 		t.Fatalf("parseProcStat() error = %v", err)
 	}
+	result := stat.CPU
 
 	// Must have aggregate "cpu" entry
 	if _, ok := result["cpu"]; !ok {
@@ -463,6 +599,7 @@ func TestCollect_Integration(t *testing.T) {
 	}
 
 	lastRawData = nil
+	lastSched = schedRaw{}
 	ctx := context.Background()
 
 	// First call - baseline
@@ -482,8 +619,14 @@ func TestCollect_Integration(t *testing.T) {
 	if metrics2 == nil {
 		t.Fatal("Collect() second call returned nil, expected metrics")
 	}
-	if len(metrics2) != 1 {
-		t.Fatalf("Collect() returned %d metrics, expected 1", len(metrics2))
+	if len(metrics2) != 2 {
+		t.Fatalf("Collect() returned %d metrics, expected 2", len(metrics2))
+	}
+	if _, ok := metrics2[0].(protocol.CPUMetric); !ok {
+		t.Errorf("first metric: got %T, want protocol.CPUMetric", metrics2[0])
+	}
+	if _, ok := metrics2[1].(protocol.SchedMetric); !ok {
+		t.Errorf("second metric: got %T, want protocol.SchedMetric", metrics2[1])
 	}
 }
 
@@ -499,8 +642,10 @@ func TestCollect_CounterReset(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if metrics != nil {
-		t.Error("expected nil metrics after counter reset")
+	for _, m := range metrics {
+		if _, ok := m.(protocol.CPUMetric); ok {
+			t.Error("expected no CPU metric after counter reset")
+		}
 	}
 	if lastRawData != nil {
 		t.Error("expected lastRawData to be reset to nil")
@@ -717,6 +862,60 @@ func parseLoadAvgFile(path string) (load1, load5, load15 float64, err error) {
 
 // Test edge cases and error conditions
 
+func TestParseCPUFreqFrom(t *testing.T) {
+	root := t.TempDir()
+
+	// Four cores: three expose cpufreq, cpu3 has no cpufreq directory
+	cores := []struct {
+		name     string
+		freqKHz  string
+		governor string
+	}{
+		{"cpu0", "1500000\n", "ondemand\n"},
+		{"cpu1", "1200000\n", "ondemand\n"},
+		{"cpu2", "600000\n", "ondemand\n"},
+	}
+	for _, c := range cores {
+		dir := filepath.Join(root, c.name, "cpufreq")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "scaling_cur_freq"), []byte(c.freqKHz), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "scaling_governor"), []byte(c.governor), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, "cpu3"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Non-core entries must be ignored
+	if err := os.MkdirAll(filepath.Join(root, "cpufreq", "policy0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	freq, gov := parseCPUFreqFrom(root)
+	if math.Abs(freq-1100) > 0.001 {
+		t.Errorf("FreqMHz: got %f, want 1100", freq)
+	}
+	if gov != "ondemand" {
+		t.Errorf("Governor: got %q, want ondemand", gov)
+	}
+}
+
+func TestParseCPUFreqFrom_NoCpufreq(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cpu0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	freq, gov := parseCPUFreqFrom(root)
+	if freq != 0 || gov != "" {
+		t.Errorf("expected zero values, got %f %q", freq, gov)
+	}
+}
+
 func TestParseProcStat_FileNotFound(t *testing.T) {
 	_, err := os.Open("/nonexistent/proc/stat")
 	if err == nil {
@@ -753,8 +952,8 @@ func TestParseProcStatFrom_MalformedLines(t *testing.T) {
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-			if len(got) != tt.wantCPUs {
-				t.Errorf("got %d CPUs, want %d", len(got), tt.wantCPUs)
+			if len(got.CPU) != tt.wantCPUs {
+				t.Errorf("got %d CPUs, want %d", len(got.CPU), tt.wantCPUs)
 			}
 		})
 	}
@@ -882,7 +1081,8 @@ func TestParseProcStatFrom(t *testing.T) {
 				t.Fatalf("unknown fixture %q", tt.fixture)
 			}
 
-			got, err := parseProcStatFrom(strings.NewReader(s))
+			stat, err := parseProcStatFrom(strings.NewReader(s))
+			got := stat.CPU
 			if (err != nil) != tt.wantErr {
 				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -943,7 +1143,11 @@ func TestProcStatFromDeltas(t *testing.T) {
 		{
 			name:     "cpu hotplug",
 			fixtures: []string{"delta_hotplug_t0", "delta_hotplug_t1"},
-			wantOK:   false,
+			wantOK:   true,
+			wantDelta: Delta{
+				User: 1000, Nice: 50, System: 500, Idle: 8000, IOWait: 200, IRQ: 10, SoftIRQ: 20, Steal: 5, Total: 9785, Used: 1585,
+			},
+			wantUsage: 16.20,
 		},
 	}
 
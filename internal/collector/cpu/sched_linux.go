@@ -0,0 +1,52 @@
+//go:build linux
+
+package cpu
+
+import (
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// schedRaw holds the cumulative scheduler counters from /proc/stat.
+type schedRaw struct {
+	Ctxt uint64
+	Intr uint64
+	Time time.Time
+}
+
+// Package-level state for sched delta calculation
+var lastSched schedRaw
+
+// collectSched turns the ctxt and intr totals from a /proc/stat read
+// into per-second rates since the previous read.
+func collectSched(stat procStat) (protocol.SchedMetric, bool) {
+	if !stat.HasSched {
+		return protocol.SchedMetric{}, false
+	}
+	cur := stat.Sched
+	cur.Time = time.Now()
+
+	prev := lastSched
+	lastSched = cur
+
+	return calcSchedRates(cur, prev)
+}
+
+// calcSchedRates returns per-second rates between two samples. It reports
+// false when there is no previous sample, no time has passed, or a counter
+// went backwards.
+func calcSchedRates(cur, prev schedRaw) (protocol.SchedMetric, bool) {
+	if prev.Time.IsZero() || cur.Ctxt < prev.Ctxt || cur.Intr < prev.Intr {
+		return protocol.SchedMetric{}, false
+	}
+	secs := cur.Time.Sub(prev.Time).Seconds()
+	if secs <= 0 {
+		return protocol.SchedMetric{}, false
+	}
+
+	return protocol.SchedMetric{
+		ContextSwitchesPerSec: float64(cur.Ctxt-prev.Ctxt) / secs,
+		InterruptsPerSec:      float64(cur.Intr-prev.Intr) / secs,
+	}, true
+}
@@ -0,0 +1,107 @@
+//go:build linux
+
+package cpu
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestParseProcStatFrom_Sched(t *testing.T) {
+	tests := []struct {
+		sample   string
+		wantCtxt uint64
+		wantIntr uint64
+	}{
+		{"proc_stat_4core", 28341562, 12847362},
+		{"proc_stat_single_core", 9234521, 5823412},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sample, func(t *testing.T) {
+			stat, err := parseProcStatFrom(strings.NewReader(procStatSamples[tt.sample]))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !stat.HasSched {
+				t.Fatal("expected ctxt and intr to be read")
+			}
+			got := stat.Sched
+			if got.Ctxt != tt.wantCtxt || got.Intr != tt.wantIntr {
+				t.Errorf("got ctxt %d intr %d, want ctxt %d intr %d", got.Ctxt, got.Intr, tt.wantCtxt, tt.wantIntr)
+			}
+		})
+	}
+}
+
+func TestParseProcStatFrom_SchedMissing(t *testing.T) {
+	stat, err := parseProcStatFrom(strings.NewReader("cpu  1 2 3 4 5 6 7 8 0 0\nctxt 100\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stat.HasSched {
+		t.Error("expected no sched counters when intr is missing")
+	}
+	if len(stat.CPU) != 1 {
+		t.Errorf("got %d CPUs, want 1", len(stat.CPU))
+	}
+}
+
+func TestParseProcStatFrom_SchedMalformed(t *testing.T) {
+	if _, err := parseProcStatFrom(strings.NewReader("cpu  1 2 3 4 5 6 7 8 0 0\nintr x 1 2\nctxt 100\n")); err == nil {
+		t.Error("expected error for a malformed intr total")
+	}
+}
+
+func TestCalcSchedRates(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	prev := schedRaw{Ctxt: 1_000_000, Intr: 500_000, Time: t0}
+	cur := schedRaw{Ctxt: 1_050_000, Intr: 520_000, Time: t0.Add(5 * time.Second)}
+
+	got, ok := calcSchedRates(cur, prev)
+	if !ok {
+		t.Fatal("expected rates for two valid samples")
+	}
+	want := protocol.SchedMetric{ContextSwitchesPerSec: 10_000, InterruptsPerSec: 4_000}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, ok := calcSchedRates(cur, schedRaw{}); ok {
+		t.Error("expected no rates without a previous sample")
+	}
+	if _, ok := calcSchedRates(cur, cur); ok {
+		t.Error("expected no rates when no time has passed")
+	}
+}
+
+func TestCalcSchedRates_CounterReset(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	prev := schedRaw{Ctxt: 1_000_000, Intr: 500_000, Time: t0}
+
+	tests := []struct {
+		name string
+		cur  schedRaw
+	}{
+		{"ctxt regressed", schedRaw{Ctxt: 10, Intr: 600_000, Time: t0.Add(5 * time.Second)}},
+		{"intr regressed", schedRaw{Ctxt: 1_100_000, Intr: 10, Time: t0.Add(5 * time.Second)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if m, ok := calcSchedRates(tt.cur, prev); ok {
+				t.Errorf("expected sample to be skipped, got %+v", m)
+			}
+		})
+	}
+
+	// The regressed sample becomes the new baseline
+	next := schedRaw{Ctxt: 20, Intr: 600_010, Time: t0.Add(10 * time.Second)}
+	got, ok := calcSchedRates(next, tests[0].cur)
+	if !ok || got.ContextSwitchesPerSec != 2 || got.InterruptsPerSec != 2 {
+		t.Errorf("after reset: got %+v, %v; want 2/s each", got, ok)
+	}
+}
@@ -54,26 +54,25 @@ func buildDiskMetric(m MountInfo, stat unix.Statfs_t) protocol.DiskMetric {
 	if bfree > stat.Blocks {
 		bfree = stat.Blocks
 	}
-	if ffree > stat.Files {
-		ffree = stat.Files
-	}
 
 	total := stat.Blocks * bsize
 	available := bavail * bsize
 	used := (stat.Blocks - bfree) * bsize
-	inodesUsed := stat.Files - ffree
+	inodesUsed, inodesAvail, inodesPct := inodeUsage(stat.Files, ffree)
 
 	return protocol.DiskMetric{
-		Device:      m.Device,
-		Mountpoint:  m.Mountpoint,
-		Filesystem:  m.FSType,
-		Type:        fsCategory(m.FSType),
-		Total:       total,
-		Used:        used,
-		Available:   available,
-		UsedPct:     util.Percent(used, total),
-		InodesTotal: stat.Files,
-		InodesUsed:  inodesUsed,
-		InodesPct:   util.Percent(inodesUsed, stat.Files),
+		Device:          m.Device,
+		Mountpoint:      m.Mountpoint,
+		Filesystem:      m.FSType,
+		Type:            fsCategory(m.FSType),
+		Total:           total,
+		Used:            used,
+		Available:       available,
+		UsedPct:         util.Percent(used, total),
+		InodesTotal:     stat.Files,
+		InodesUsed:      inodesUsed,
+		InodesPct:       inodesPct,
+		InodesAvailable: inodesAvail,
+		ReadOnly:        m.ReadOnly,
 	}
 }
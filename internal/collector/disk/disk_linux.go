@@ -95,19 +95,21 @@ func buildDiskMetric(m MountInfo, stat unix.Statfs_t) protocol.DiskMetric {
 	total := stat.Blocks * bsize
 	available := stat.Bavail * bsize
 	used := (stat.Blocks - stat.Bfree) * bsize
-	inodesUsed := stat.Files - stat.Ffree
+	inodesUsed, inodesAvail, inodesPct := inodeUsage(stat.Files, stat.Ffree)
 
 	return protocol.DiskMetric{
-		Device:      m.Device,
-		Mountpoint:  m.Mountpoint,
-		Filesystem:  m.FSType,
-		Type:        fsCategory(m.FSType),
-		Total:       total,
-		Used:        used,
-		Available:   available,
-		UsedPct:     util.Percent(used, total),
-		InodesTotal: stat.Files,
-		InodesUsed:  inodesUsed,
-		InodesPct:   util.Percent(inodesUsed, stat.Files),
+		Device:          m.Device,
+		Mountpoint:      m.Mountpoint,
+		Filesystem:      m.FSType,
+		Type:            mediaType(sysBlockDir, m.Device),
+		Total:           total,
+		Used:            used,
+		Available:       available,
+		UsedPct:         util.Percent(used, total),
+		InodesTotal:     stat.Files,
+		InodesUsed:      inodesUsed,
+		InodesPct:       inodesPct,
+		InodesAvailable: inodesAvail,
+		ReadOnly:        m.ReadOnly,
 	}
 }
@@ -5,6 +5,7 @@ package disk
 
 import (
 	"context"
+	"math"
 	"testing"
 
 	"github.com/nhdewitt/spectra/internal/protocol"
@@ -90,6 +91,13 @@ func TestFsCategory(t *testing.T) {
 }
 
 func TestBuildDiskMetric(t *testing.T) {
+	sysBlockDir = fakeSysBlock(t, map[string]string{
+		"sda":     "1",
+		"sdb":     "0",
+		"mmcblk0": "0",
+	}, "sda/sda1", "sdb/sdb1", "mmcblk0/mmcblk0p1")
+	t.Cleanup(func() { sysBlockDir = "/sys/block" })
+
 	tests := []struct {
 		name string
 		info MountInfo
@@ -112,17 +120,18 @@ func TestBuildDiskMetric(t *testing.T) {
 				Ffree:  6000000,
 			},
 			want: protocol.DiskMetric{
-				Device:      "/dev/sda1",
-				Mountpoint:  "/",
-				Filesystem:  "ext4",
-				Type:        "local",
-				Total:       107374182400,
-				Used:        53687091200,
-				Available:   48318382080,
-				UsedPct:     50.0,
-				InodesTotal: 6553600,
-				InodesUsed:  553600,
-				InodesPct:   8.45,
+				Device:          "/dev/sda1",
+				Mountpoint:      "/",
+				Filesystem:      "ext4",
+				Type:            "hdd",
+				Total:           107374182400,
+				Used:            53687091200,
+				Available:       48318382080,
+				UsedPct:         50.0,
+				InodesTotal:     6553600,
+				InodesUsed:      553600,
+				InodesPct:       8.45,
+				InodesAvailable: 6000000,
 			},
 		},
 		{
@@ -141,17 +150,18 @@ func TestBuildDiskMetric(t *testing.T) {
 				Ffree:  100000,
 			},
 			want: protocol.DiskMetric{
-				Device:      "/dev/mmcblk0p1",
-				Mountpoint:  "/",
-				Filesystem:  "ext4",
-				Type:        "local",
-				Total:       32212254720,
-				Used:        31138512896,
-				Available:   536870912,
-				UsedPct:     96.67,
-				InodesTotal: 1966080,
-				InodesUsed:  1866080,
-				InodesPct:   94.91,
+				Device:          "/dev/mmcblk0p1",
+				Mountpoint:      "/",
+				Filesystem:      "ext4",
+				Type:            "ssd",
+				Total:           32212254720,
+				Used:            31138512896,
+				Available:       536870912,
+				UsedPct:         96.67,
+				InodesTotal:     1966080,
+				InodesUsed:      1866080,
+				InodesPct:       94.91,
+				InodesAvailable: 100000,
 			},
 		},
 		{
@@ -170,17 +180,18 @@ func TestBuildDiskMetric(t *testing.T) {
 				Ffree:  1000000,
 			},
 			want: protocol.DiskMetric{
-				Device:      "/dev/sdb1",
-				Mountpoint:  "/mnt/data",
-				Filesystem:  "xfs",
-				Type:        "local",
-				Total:       214748364800,
-				Used:        0,
-				Available:   214748364800,
-				UsedPct:     0.0,
-				InodesTotal: 1000000,
-				InodesUsed:  0,
-				InodesPct:   0.0,
+				Device:          "/dev/sdb1",
+				Mountpoint:      "/mnt/data",
+				Filesystem:      "xfs",
+				Type:            "ssd",
+				Total:           214748364800,
+				Used:            0,
+				Available:       214748364800,
+				UsedPct:         0.0,
+				InodesTotal:     1000000,
+				InodesUsed:      0,
+				InodesPct:       0.0,
+				InodesAvailable: 1000000,
 			},
 		},
 		{
@@ -202,7 +213,7 @@ func TestBuildDiskMetric(t *testing.T) {
 				Device:      "/dev/mmcblk0p1",
 				Mountpoint:  "/boot",
 				Filesystem:  "vfat",
-				Type:        "local",
+				Type:        "ssd",
 				Total:       268435456,
 				Used:        53687296,
 				Available:   214748160,
@@ -228,17 +239,77 @@ func TestBuildDiskMetric(t *testing.T) {
 				Ffree:  9000000,
 			},
 			want: protocol.DiskMetric{
-				Device:      "192.168.1.100:/share",
-				Mountpoint:  "/mnt/nfs",
-				Filesystem:  "nfs",
-				Type:        "other",
-				Total:       1073741824000,
-				Used:        536870912000,
-				Available:   536870912000,
+				Device:          "192.168.1.100:/share",
+				Mountpoint:      "/mnt/nfs",
+				Filesystem:      "nfs",
+				Type:            "unknown",
+				Total:           1073741824000,
+				Used:            536870912000,
+				Available:       536870912000,
+				UsedPct:         50.0,
+				InodesTotal:     10000000,
+				InodesUsed:      1000000,
+				InodesPct:       10.0,
+				InodesAvailable: 9000000,
+			},
+		},
+		{
+			name: "inodes nearly exhausted, bytes fine",
+			info: MountInfo{
+				Device:     "/dev/sdc1",
+				Mountpoint: "/var/spool",
+				FSType:     "ext4",
+			},
+			stat: unix.Statfs_t{
+				Bsize:  4096,
+				Blocks: 26214400,
+				Bfree:  20971520,
+				Bavail: 19660800,
+				Files:  6553600,
+				Ffree:  6554,
+			},
+			want: protocol.DiskMetric{
+				Device:          "/dev/sdc1",
+				Mountpoint:      "/var/spool",
+				Filesystem:      "ext4",
+				Type:            "unknown",
+				Total:           107374182400,
+				Used:            21474836480,
+				Available:       80530636800,
+				UsedPct:         20.0,
+				InodesTotal:     6553600,
+				InodesUsed:      6547046,
+				InodesPct:       99.9,
+				InodesAvailable: 6554,
+			},
+		},
+		{
+			name: "network filesystem without inode totals",
+			info: MountInfo{
+				Device:     "server:/export",
+				Mountpoint: "/mnt/share",
+				FSType:     "nfs4",
+			},
+			stat: unix.Statfs_t{
+				Bsize:  4096,
+				Blocks: 1000,
+				Bfree:  500,
+				Bavail: 500,
+				Files:  0,
+				Ffree:  12345,
+			},
+			want: protocol.DiskMetric{
+				Device:      "server:/export",
+				Mountpoint:  "/mnt/share",
+				Filesystem:  "nfs4",
+				Type:        "unknown",
+				Total:       4096000,
+				Used:        2048000,
+				Available:   2048000,
 				UsedPct:     50.0,
-				InodesTotal: 10000000,
-				InodesUsed:  1000000,
-				InodesPct:   10.0,
+				InodesTotal: 0,
+				InodesUsed:  0,
+				InodesPct:   0.0,
 			},
 		},
 	}
@@ -281,6 +352,12 @@ func TestBuildDiskMetric(t *testing.T) {
 			if !approxEqual(got.InodesPct, tt.want.InodesPct, 0.01) {
 				t.Errorf("InodesPct = %.2f, want %.2f", got.InodesPct, tt.want.InodesPct)
 			}
+			if math.IsNaN(got.InodesPct) {
+				t.Errorf("InodesPct is NaN")
+			}
+			if got.InodesAvailable != tt.want.InodesAvailable {
+				t.Errorf("InodesAvailable = %d, want %d", got.InodesAvailable, tt.want.InodesAvailable)
+			}
 		})
 	}
 }
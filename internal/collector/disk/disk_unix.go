@@ -4,9 +4,11 @@ package disk
 
 import (
 	"context"
+	"maps"
 
 	"github.com/nhdewitt/spectra/internal/collector"
 	"github.com/nhdewitt/spectra/internal/protocol"
+	"github.com/nhdewitt/spectra/internal/util"
 	"golang.org/x/sys/unix"
 )
 
@@ -33,9 +35,11 @@ func CollectDisk(ctx context.Context, cache *DriveCache) ([]protocol.Metric, err
 	return result, nil
 }
 
+// loadMountMap returns a copy of the cached mounts, since the mount
+// manager updates the cached map in place.
 func loadMountMap(cache *DriveCache) map[string]MountInfo {
 	cache.RWMutex.RLock()
-	mountMap := cache.DeviceToMountpoint
+	mountMap := maps.Clone(cache.DeviceToMountpoint)
 	cache.RWMutex.RUnlock()
 
 	return mountMap
@@ -47,6 +51,16 @@ func statfs(path string) (unix.Statfs_t, error) {
 	return stat, err
 }
 
+// inodeUsage derives used and available inodes and the used percent from
+// statfs counts. Free is clamped to total so filesystems that report no
+// inode table (network and FUSE mounts often return zero totals) come out
+// as zero usage rather than an underflow or NaN.
+func inodeUsage(total, free uint64) (used, avail uint64, pct float64) {
+	free = min(free, total)
+	used = total - free
+	return used, free, util.Percent(used, total)
+}
+
 func fsCategory(fsType string) string {
 	if _, local := localFilesystems[fsType]; local {
 		return "local"
@@ -0,0 +1,64 @@
+//go:build linux
+
+package disk
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sysBlockDir is where the kernel lists block devices. It is a variable so
+// tests can point it at a fake tree.
+var sysBlockDir = "/sys/block"
+
+// mediaType classifies the disk behind device as "nvme", "ssd" or "hdd"
+// from its queue/rotational flag, or "unknown" if the disk can't be found
+// (ZFS datasets, network shares) or doesn't report the flag.
+func mediaType(sysBlock, device string) string {
+	disk := blockDisk(sysBlock, device)
+	if disk == "" {
+		return "unknown"
+	}
+
+	data, err := os.ReadFile(filepath.Join(sysBlock, disk, "queue", "rotational"))
+	if err != nil {
+		return "unknown"
+	}
+
+	switch strings.TrimSpace(string(data)) {
+	case "0":
+		if strings.HasPrefix(disk, "nvme") {
+			return "nvme"
+		}
+		return "ssd"
+	case "1":
+		return "hdd"
+	default:
+		return "unknown"
+	}
+}
+
+// blockDisk returns the whole-disk name under sysBlock for a device path,
+// mapping partitions (sda1, nvme0n1p2) to their parent disk. Symlinks
+// such as /dev/mapper/* are resolved to their dm-N node first.
+func blockDisk(sysBlock, device string) string {
+	if resolved, err := filepath.EvalSymlinks(device); err == nil {
+		device = resolved
+	}
+	name := filepath.Base(device)
+	if name == "" || name == "." || name == "/" {
+		return ""
+	}
+
+	if _, err := os.Stat(filepath.Join(sysBlock, name)); err == nil {
+		return name
+	}
+
+	// Partitions appear as subdirectories of their disk
+	matches, _ := filepath.Glob(filepath.Join(sysBlock, "*", name))
+	if len(matches) != 1 {
+		return ""
+	}
+	return filepath.Base(filepath.Dir(matches[0]))
+}
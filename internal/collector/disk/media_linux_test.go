@@ -0,0 +1,70 @@
+//go:build linux
+
+package disk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeSysBlock builds a /sys/block tree with the given queue/rotational
+// values per disk, plus extra directories such as partitions ("sda/sda1").
+func fakeSysBlock(t *testing.T, rotational map[string]string, dirs ...string) string {
+	t.Helper()
+	root := t.TempDir()
+
+	for disk, value := range rotational {
+		queue := filepath.Join(root, disk, "queue")
+		if err := os.MkdirAll(queue, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(queue, "rotational"), []byte(value+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, d := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestMediaType(t *testing.T) {
+	root := fakeSysBlock(t, map[string]string{
+		"nvme0n1": "0",
+		"sda":     "0",
+		"sdb":     "1",
+		"mmcblk0": "0",
+		"dm-0":    "0",
+		"sr0":     "garbage",
+	}, "nvme0n1/nvme0n1p2", "sda/sda1", "sdb/sdb3", "mmcblk0/mmcblk0p1", "loop0")
+
+	tests := []struct {
+		device string
+		want   string
+	}{
+		{"/dev/nvme0n1", "nvme"},
+		{"/dev/nvme0n1p2", "nvme"},
+		{"/dev/sda", "ssd"},
+		{"/dev/sda1", "ssd"},
+		{"/dev/sdb3", "hdd"},
+		{"/dev/mmcblk0p1", "ssd"},
+		{"/dev/dm-0", "ssd"},
+		{"/dev/sr0", "unknown"},   // unparseable flag
+		{"/dev/loop0", "unknown"}, // no queue directory
+		{"/dev/sdz1", "unknown"},  // not in sysfs
+		{"server:/export", "unknown"},
+		{"tank/data", "unknown"},
+		{"", "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.device, func(t *testing.T) {
+			if got := mediaType(root, tt.device); got != tt.want {
+				t.Errorf("mediaType(%q) = %q, want %q", tt.device, got, tt.want)
+			}
+		})
+	}
+}
@@ -46,6 +46,7 @@ func (c *DriveCache) Refresh() error {
 			Device:     device,
 			Mountpoint: mntPoint,
 			FSType:     fstype,
+			ReadOnly:   stat.Flags&unix.MNT_RDONLY != 0,
 		}
 	}
 
@@ -27,6 +27,7 @@ func parseMounts() ([]MountInfo, error) {
 			Device:     unix.ByteSliceToString(fs.Mntfromname[:]),
 			Mountpoint: unix.ByteSliceToString(fs.Mntonname[:]),
 			FSType:     unix.ByteSliceToString(fs.Fstypename[:]),
+			ReadOnly:   fs.Flags&unix.MNT_RDONLY != 0,
 		}
 
 		if shouldIgnore(m) {
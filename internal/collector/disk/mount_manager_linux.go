@@ -35,6 +35,9 @@ func parseMountsFrom(r io.Reader) ([]MountInfo, error) {
 			Mountpoint: decodeMountPath(fields[1]),
 			FSType:     fields[2],
 		}
+		if len(fields) > 3 {
+			m.ReadOnly = hasMountOption(fields[3], "ro")
+		}
 
 		if shouldIgnore(m) {
 			continue
@@ -46,6 +49,17 @@ func parseMountsFrom(r io.Reader) ([]MountInfo, error) {
 	return mounts, scanner.Err()
 }
 
+// hasMountOption reports whether the comma-separated mount options
+// contain opt.
+func hasMountOption(options, opt string) bool {
+	for o := range strings.SplitSeq(options, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
 // decodeMountPath replaces common octal escapes in /proc/mounts.
 func decodeMountPath(s string) string {
 	s = strings.ReplaceAll(s, `\040`, " ")
@@ -4,9 +4,12 @@ package disk
 
 import (
 	"context"
+	"log/slog"
 	"strings"
 	"testing"
 	"time"
+
+	"golang.org/x/sys/unix"
 )
 
 func TestParseMountsFrom(t *testing.T) {
@@ -49,6 +52,42 @@ C:\ /mnt/wsl/docker-desktop-data ext4 rw,relatime 0 0
 	}
 }
 
+func TestParseMountsFrom_ReadOnly(t *testing.T) {
+	input := `
+/dev/sda1 / ext4 rw,relatime,errors=remount-ro 0 0
+/dev/sdb1 /mnt/data xfs ro,relatime 0 0
+/dev/sdc1 /backup btrfs relatime,ro 0 0
+/dev/sdd1 /short ext4
+`
+	mounts, err := parseMountsFrom(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseMountsFrom failed: %v", err)
+	}
+	if len(mounts) != 4 {
+		t.Fatalf("expected 4 mounts, got %d", len(mounts))
+	}
+
+	want := map[string]bool{
+		"/":         false, // errors=remount-ro is not the ro flag
+		"/mnt/data": true,
+		"/backup":   true,
+		"/short":    false,
+	}
+	for _, m := range mounts {
+		if m.ReadOnly != want[m.Mountpoint] {
+			t.Errorf("%s ReadOnly = %v, want %v", m.Mountpoint, m.ReadOnly, want[m.Mountpoint])
+		}
+	}
+
+	stat := unix.Statfs_t{Bsize: 4096, Blocks: 100, Bfree: 50, Bavail: 50}
+	if dm := buildDiskMetric(mounts[1], stat); !dm.ReadOnly {
+		t.Error("DiskMetric.ReadOnly = false for ro mount")
+	}
+	if dm := buildDiskMetric(mounts[0], stat); dm.ReadOnly {
+		t.Error("DiskMetric.ReadOnly = true for rw mount")
+	}
+}
+
 func TestParseMountsFrom_Empty(t *testing.T) {
 	reader := strings.NewReader("")
 	mounts, err := parseMountsFrom(reader)
@@ -292,11 +331,73 @@ func TestCreateDeviceToMountpointMap_DuplicateDevices(t *testing.T) {
 	}
 }
 
+func TestApplyMounts_OnlyDelta(t *testing.T) {
+	snap1 := `/dev/sda1 / ext4 rw,relatime 0 0
+/dev/sdb1 /mnt/data xfs rw,relatime 0 0
+/dev/sdc1 /mnt/old ext4 rw,relatime 0 0
+`
+	// sdc1 is unmounted, sdd1 is new, sdb1 moved, and a transient
+	// tmpfs appears that shouldIgnore must keep out
+	snap2 := `/dev/sda1 / ext4 rw,relatime 0 0
+/dev/sdb1 /srv/data xfs rw,relatime 0 0
+/dev/sdd1 /mnt/usb ext4 rw,relatime 0 0
+tmpfs /run/user/1000 tmpfs rw,nosuid,nodev 0 0
+`
+
+	cache := NewDriveCache()
+	first, err := parseMountsFrom(strings.NewReader(snap1))
+	if err != nil {
+		t.Fatalf("parse snap1: %v", err)
+	}
+	if ev := applyMounts(cache, first); len(ev) != 3 {
+		t.Fatalf("initial apply: got %d events, want 3 adds", len(ev))
+	}
+
+	second, err := parseMountsFrom(strings.NewReader(snap2))
+	if err != nil {
+		t.Fatalf("parse snap2: %v", err)
+	}
+	events := applyMounts(cache, second)
+
+	got := make(map[string]bool)
+	for _, ev := range events {
+		got[string(ev.Kind)+" "+ev.Device+" "+ev.Info.Mountpoint] = true
+	}
+	want := []string{
+		"removed sdc1 /mnt/old",
+		"removed sdb1 /mnt/data",
+		"added sdb1 /srv/data",
+		"added sdd1 /mnt/usb",
+	}
+	if len(events) != len(want) {
+		t.Errorf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for _, w := range want {
+		if !got[w] {
+			t.Errorf("missing event %q", w)
+		}
+	}
+
+	if len(cache.DeviceToMountpoint) != 3 {
+		t.Errorf("cache size: got %d, want 3", len(cache.DeviceToMountpoint))
+	}
+	if cache.DeviceToMountpoint["sdb1"].Mountpoint != "/srv/data" {
+		t.Errorf("sdb1 mountpoint: got %q, want /srv/data", cache.DeviceToMountpoint["sdb1"].Mountpoint)
+	}
+	if _, ok := cache.DeviceToMountpoint["tmpfs"]; ok {
+		t.Error("ignored tmpfs mount should not reach the cache")
+	}
+
+	if ev := applyMounts(cache, second); len(ev) != 0 {
+		t.Errorf("unchanged snapshot: got %d events, want 0", len(ev))
+	}
+}
+
 func TestMountManager_Race_Linux(t *testing.T) {
 	cache := NewDriveCache()
 	ctx := t.Context()
 
-	go RunMountManager(ctx, cache, 1*time.Millisecond)
+	go RunMountManager(ctx, cache, 1*time.Millisecond, slog.New(slog.DiscardHandler))
 
 	stopReader := make(chan struct{})
 	go func() {
@@ -323,7 +424,7 @@ func TestRunMountManager_ContextCancel(t *testing.T) {
 
 	done := make(chan struct{})
 	go func() {
-		RunMountManager(ctx, cache, 1*time.Hour)
+		RunMountManager(ctx, cache, 1*time.Hour, slog.New(slog.DiscardHandler))
 		close(done)
 	}()
 
@@ -453,6 +554,6 @@ func BenchmarkUpdateCache(b *testing.B) {
 	cache := NewDriveCache()
 	b.ReportAllocs()
 	for b.Loop() {
-		updateCache(cache)
+		updateCache(cache, slog.New(slog.DiscardHandler))
 	}
 }
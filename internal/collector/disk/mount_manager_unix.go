@@ -4,7 +4,7 @@ package disk
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 )
@@ -20,33 +20,82 @@ func createDeviceToMountpointMap(mounts []MountInfo) map[string]MountInfo {
 	return deviceMap
 }
 
-func RunMountManager(ctx context.Context, cache *DriveCache, interval time.Duration) {
+// RunMountManager refreshes cache every interval until ctx is done,
+// logging mount changes at debug level.
+func RunMountManager(ctx context.Context, cache *DriveCache, interval time.Duration, logger *slog.Logger) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	updateCache(cache)
+	updateCache(cache, logger)
 
 	for {
 		select {
 		case <-ticker.C:
-			updateCache(cache)
+			updateCache(cache, logger)
 		case <-ctx.Done():
-			fmt.Println("Mount manager stopped.")
+			logger.Debug("mount manager stopped")
 			return
 		}
 	}
 }
 
-func updateCache(cache *DriveCache) {
+func updateCache(cache *DriveCache, logger *slog.Logger) {
 	currentMounts, err := parseMounts()
 	if err != nil {
-		fmt.Printf("Error updating mount cache: %v\n", err)
+		logger.Debug("updating mount cache failed", "error", err)
 		return
 	}
 
-	newMap := createDeviceToMountpointMap(currentMounts)
+	for _, ev := range applyMounts(cache, currentMounts) {
+		logger.Debug("mount "+string(ev.Kind),
+			"device", ev.Info.Device,
+			"mountpoint", ev.Info.Mountpoint,
+			"fstype", ev.Info.FSType,
+		)
+	}
+}
+
+// mountEventKind describes how a cache entry changed.
+type mountEventKind string
+
+const (
+	mountAdded   mountEventKind = "added"
+	mountRemoved mountEventKind = "removed"
+)
+
+// mountEvent is a single change applied to the DriveCache. A device that
+// moved to a different mountpoint produces a removal followed by an add.
+type mountEvent struct {
+	Kind   mountEventKind
+	Device string
+	Info   MountInfo
+}
+
+// applyMounts diffs mounts against the cached map and updates only the
+// entries that changed, returning what was added and removed.
+func applyMounts(cache *DriveCache, mounts []MountInfo) []mountEvent {
+	newMap := createDeviceToMountpointMap(mounts)
 
 	cache.RWMutex.Lock()
-	cache.DeviceToMountpoint = newMap
-	cache.RWMutex.Unlock()
+	defer cache.RWMutex.Unlock()
+
+	if cache.DeviceToMountpoint == nil {
+		cache.DeviceToMountpoint = make(map[string]MountInfo, len(newMap))
+	}
+
+	var events []mountEvent
+	for dev, old := range cache.DeviceToMountpoint {
+		if cur, ok := newMap[dev]; !ok || cur != old {
+			events = append(events, mountEvent{Kind: mountRemoved, Device: dev, Info: old})
+			delete(cache.DeviceToMountpoint, dev)
+		}
+	}
+	for dev, cur := range newMap {
+		if _, ok := cache.DeviceToMountpoint[dev]; !ok {
+			events = append(events, mountEvent{Kind: mountAdded, Device: dev, Info: cur})
+			cache.DeviceToMountpoint[dev] = cur
+		}
+	}
+
+	return events
 }
@@ -7,7 +7,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 	"time"
 	"unsafe"
@@ -18,7 +18,7 @@ import (
 	"golang.org/x/sys/windows"
 )
 
-func RunMountManager(ctx context.Context, cache *DriveCache, interval time.Duration) {
+func RunMountManager(ctx context.Context, cache *DriveCache, interval time.Duration, logger *slog.Logger) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -29,7 +29,7 @@ func RunMountManager(ctx context.Context, cache *DriveCache, interval time.Durat
 		case <-ticker.C:
 			updateDriveCacheNative(cache)
 		case <-ctx.Done():
-			log.Println("Mount Manager stopped.")
+			logger.Debug("mount manager stopped")
 			return
 		}
 	}
@@ -4,6 +4,7 @@ package disk
 
 import (
 	"context"
+	"log/slog"
 	"strings"
 	"testing"
 	"time"
@@ -125,7 +126,7 @@ func TestRunMountManager_Race(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go RunMountManager(ctx, cache, 10*time.Millisecond)
+	go RunMountManager(ctx, cache, 10*time.Millisecond, slog.New(slog.DiscardHandler))
 
 	// Simulate concurrent readers
 	stopReader := make(chan struct{})
@@ -153,7 +154,7 @@ func TestRunMountManager_ContextCancel(t *testing.T) {
 
 	done := make(chan struct{})
 	go func() {
-		RunMountManager(ctx, cache, 1*time.Hour)
+		RunMountManager(ctx, cache, 1*time.Hour, slog.New(slog.DiscardHandler))
 		close(done)
 	}()
 
@@ -8,6 +8,7 @@ type MountInfo struct {
 	Device     string
 	Mountpoint string
 	FSType     string
+	ReadOnly   bool
 }
 
 type DriveCache struct {
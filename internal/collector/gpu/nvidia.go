@@ -0,0 +1,84 @@
+// Package gpu collects per-process GPU usage from vendor tools.
+package gpu
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// CollectNVIDIAProcesses reports GPU memory held by each compute process
+// via `nvidia-smi --query-compute-apps`. It is a no-op when nvidia-smi is
+// missing or no driver is loaded.
+func CollectNVIDIAProcesses(ctx context.Context) ([]protocol.Metric, error) {
+	path, err := exec.LookPath("nvidia-smi")
+	if err != nil {
+		return nil, nil
+	}
+	out, err := exec.CommandContext(ctx, path,
+		"--query-compute-apps=pid,process_name,used_memory,gpu_uuid",
+		"--format=csv,noheader,nounits",
+	).Output()
+	if err != nil {
+		return nil, nil
+	}
+
+	procs, err := parseComputeApps(bytes.NewReader(out))
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := make([]protocol.Metric, len(procs))
+	for i, p := range procs {
+		metrics[i] = p
+	}
+	return metrics, nil
+}
+
+// parseComputeApps parses nvidia-smi compute-app CSV without header or
+// units:
+//
+//	4123, /usr/bin/python3, 2048, GPU-5d3f0b3e-...
+//
+// used_memory is MiB. "[N/A]" (common under Windows WDDM) reports zero
+// memory; lines without a numeric PID are skipped.
+func parseComputeApps(r io.Reader) ([]protocol.GPUProcessMetric, error) {
+	var procs []protocol.GPUProcessMetric
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		cols := strings.Split(scanner.Text(), ",")
+		if len(cols) < 3 {
+			continue
+		}
+		for i := range cols {
+			cols[i] = strings.TrimSpace(cols[i])
+		}
+
+		pid, err := strconv.Atoi(cols[0])
+		if err != nil || pid <= 0 {
+			continue
+		}
+
+		p := protocol.GPUProcessMetric{
+			PID:  pid,
+			Name: cols[1],
+		}
+		if mib, err := strconv.ParseUint(cols[2], 10, 64); err == nil {
+			p.UsedMemory = mib << 20
+		}
+		if len(cols) > 3 {
+			p.GPU = cols[3]
+		}
+
+		procs = append(procs, p)
+	}
+
+	return procs, scanner.Err()
+}
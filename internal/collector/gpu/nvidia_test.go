@@ -0,0 +1,41 @@
+package gpu
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const computeAppsSample = `4123, /usr/bin/python3, 2048, GPU-5d3f0b3e-1c2a-4f7e-9a11-0b8c6f2e1d44
+5310, /opt/ollama/bin/ollama, 7532, GPU-5d3f0b3e-1c2a-4f7e-9a11-0b8c6f2e1d44
+6001, C:\Program Files\App\app.exe, [N/A], GPU-a0b1c2d3-0000-1111-2222-333344445555
+No running processes found
+`
+
+func TestParseComputeApps(t *testing.T) {
+	got, err := parseComputeApps(strings.NewReader(computeAppsSample))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []protocol.GPUProcessMetric{
+		{PID: 4123, Name: "/usr/bin/python3", UsedMemory: 2048 << 20, GPU: "GPU-5d3f0b3e-1c2a-4f7e-9a11-0b8c6f2e1d44"},
+		{PID: 5310, Name: "/opt/ollama/bin/ollama", UsedMemory: 7532 << 20, GPU: "GPU-5d3f0b3e-1c2a-4f7e-9a11-0b8c6f2e1d44"},
+		{PID: 6001, Name: `C:\Program Files\App\app.exe`, GPU: "GPU-a0b1c2d3-0000-1111-2222-333344445555"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseComputeApps_Empty(t *testing.T) {
+	got, err := parseComputeApps(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no processes, got %+v", got)
+	}
+}
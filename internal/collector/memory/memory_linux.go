@@ -21,29 +21,49 @@ func parseMemInfo() (memRaw, error) {
 	return parseMemInfoFrom(f)
 }
 
+// parseMemInfoFrom parses /proc/meminfo. MemTotal, SwapTotal and SwapFree
+// are required; the remaining fields are optional. Kernels older than 3.14
+// don't report MemAvailable, so it is estimated from MemFree+Buffers+Cached.
 func parseMemInfoFrom(r io.Reader) (memRaw, error) {
 	var raw memRaw
+	var free uint64
 
-	targets := map[string]*uint64{
-		"MemTotal":     &raw.Total,
+	required := map[string]*uint64{
+		"MemTotal":  &raw.Total,
+		"SwapTotal": &raw.SwapTotal,
+		"SwapFree":  &raw.SwapFree,
+	}
+	optional := map[string]*uint64{
 		"MemAvailable": &raw.Available,
-		"SwapTotal":    &raw.SwapTotal,
-		"SwapFree":     &raw.SwapFree,
+		"MemFree":      &free,
+		"Buffers":      &raw.Buffers,
+		"Cached":       &raw.Cached,
+		"Shmem":        &raw.Shmem,
+		"Dirty":        &raw.Dirty,
+		"Writeback":    &raw.Writeback,
+		"WritebackTmp": &raw.WritebackTmp,
 	}
+	seen := make(map[string]bool, len(required)+len(optional))
 
 	scanner := bufio.NewScanner(r)
 
-	for scanner.Scan() && len(targets) > 0 {
+	for scanner.Scan() && len(seen) < len(required)+len(optional) {
 		fields := strings.Fields(scanner.Text())
 		if len(fields) < 2 {
 			continue
 		}
 
 		key := strings.TrimSuffix(fields[0], ":")
-		target, ok := targets[key]
-		if !ok {
+		// Ignore duplicates so they can't change the value
+		if seen[key] {
 			continue
 		}
+		target, ok := required[key]
+		if !ok {
+			if target, ok = optional[key]; !ok {
+				continue
+			}
+		}
 
 		value, err := strconv.ParseUint(fields[1], 10, 64)
 		if err != nil {
@@ -51,20 +71,26 @@ func parseMemInfoFrom(r io.Reader) (memRaw, error) {
 		}
 
 		*target = value * 1024
-		// Remove the key to prevent duplicates from changing the value
-		delete(targets, key)
+		seen[key] = true
 	}
 
 	if err := scanner.Err(); err != nil {
 		return memRaw{}, fmt.Errorf("reading /proc/meminfo: %w", err)
 	}
-	if len(targets) > 0 {
-		missing := make([]string, 0, len(targets))
-		for k := range targets {
+
+	var missing []string
+	for k := range required {
+		if !seen[k] {
 			missing = append(missing, k)
 		}
+	}
+	if len(missing) > 0 {
 		return memRaw{}, fmt.Errorf("missing fields in /proc/meminfo: %v", missing)
 	}
 
+	if !seen["MemAvailable"] {
+		raw.Available = min(free+raw.Buffers+raw.Cached, raw.Total)
+	}
+
 	return raw, nil
 }
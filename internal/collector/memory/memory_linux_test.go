@@ -47,6 +47,103 @@ Dirty:			     100 kB
 	}
 }
 
+func TestParseMemInfoFrom_CacheBreakdown(t *testing.T) {
+	input := `
+MemTotal:        3884096 kB
+MemFree:          512000 kB
+MemAvailable:    2900000 kB
+Buffers:          120000 kB
+Cached:          1800000 kB
+SwapCached:         1024 kB
+SwapTotal:        102396 kB
+SwapFree:         102396 kB
+Dirty:              2048 kB
+Shmem:             65536 kB
+`
+	raw, err := parseMemInfoFrom(strings.NewReader(strings.TrimSpace(input)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checks := []struct {
+		name string
+		got  uint64
+		want uint64
+	}{
+		{"Available", raw.Available, 2900000 * 1024},
+		{"Buffers", raw.Buffers, 120000 * 1024},
+		{"Cached", raw.Cached, 1800000 * 1024},
+		{"Shmem", raw.Shmem, 65536 * 1024},
+		{"Dirty", raw.Dirty, 2048 * 1024},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s: got %d, want %d", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestParseMemInfoFrom_Writeback(t *testing.T) {
+	input := `
+MemTotal:         948016 kB
+MemFree:           31468 kB
+MemAvailable:     402744 kB
+Buffers:           18732 kB
+Cached:           388024 kB
+SwapCached:         2132 kB
+SwapTotal:        102396 kB
+SwapFree:          61180 kB
+Dirty:             97320 kB
+Writeback:         24576 kB
+AnonPages:        421448 kB
+Mapped:           124064 kB
+Shmem:             12196 kB
+NFS_Unstable:          0 kB
+Bounce:                0 kB
+WritebackTmp:        128 kB
+`
+	raw, err := parseMemInfoFrom(strings.NewReader(strings.TrimSpace(input)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checks := []struct {
+		name string
+		got  uint64
+		want uint64
+	}{
+		{"Dirty", raw.Dirty, 97320 * 1024},
+		{"Writeback", raw.Writeback, 24576 * 1024},
+		{"WritebackTmp", raw.WritebackTmp, 128 * 1024},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s: got %d, want %d", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestParseMemInfoFrom_NoMemAvailable(t *testing.T) {
+	// Pre-3.14 kernels don't report MemAvailable
+	input := `
+MemTotal:        1000000 kB
+MemFree:          200000 kB
+Buffers:           50000 kB
+Cached:           300000 kB
+SwapTotal:             0 kB
+SwapFree:              0 kB
+`
+	raw, err := parseMemInfoFrom(strings.NewReader(strings.TrimSpace(input)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := uint64((200000 + 50000 + 300000) * 1024)
+	if raw.Available != want {
+		t.Errorf("Available: got %d, want estimate %d", raw.Available, want)
+	}
+}
+
 func TestParseMemInfoFrom_MissingFields(t *testing.T) {
 	input := `
 MemTotal:		16307664 kB
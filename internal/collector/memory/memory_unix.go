@@ -14,6 +14,14 @@ type memRaw struct {
 	Available uint64
 	SwapTotal uint64
 	SwapFree  uint64
+
+	// Linux only; zero elsewhere
+	Buffers      uint64
+	Cached       uint64
+	Shmem        uint64
+	Dirty        uint64
+	Writeback    uint64
+	WritebackTmp uint64
 }
 
 func Collect(ctx context.Context) ([]protocol.Metric, error) {
@@ -33,5 +41,12 @@ func Collect(ctx context.Context) ([]protocol.Metric, error) {
 		SwapTotal: raw.SwapTotal,
 		SwapUsed:  swapUsed,
 		SwapPct:   util.Percent(swapUsed, raw.SwapTotal),
+		Buffers:   raw.Buffers,
+		Cached:    raw.Cached,
+		Shmem:     raw.Shmem,
+		Dirty:     raw.Dirty,
+
+		Writeback:    raw.Writeback,
+		WritebackTmp: raw.WritebackTmp,
 	}}, nil
 }
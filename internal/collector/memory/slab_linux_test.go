@@ -0,0 +1,56 @@
+//go:build linux
+
+package memory
+
+import (
+	"strings"
+	"testing"
+)
+
+const slabinfoSample = `slabinfo - version: 2.1
+# name            <active_objs> <num_objs> <objsize> <objperslab> <pagesperslab> : tunables <limit> <batchcount> <sharedfactor> : slabdata <active_slabs> <num_slabs> <sharedavail>
+ext4_inode_cache   42134  42315   1176   27    8 : tunables    0    0    0 : slabdata   1567   1567      0
+dentry            118209 120435    192   21    1 : tunables    0    0    0 : slabdata   5735   5735      0
+kmalloc-256         3456   3456    256   32    2 : tunables    0    0    0 : slabdata    108    108      0
+kmalloc-8          12288  12288      8  512    1 : tunables    0    0    0 : slabdata     24     24      0
+`
+
+func TestParseSlabinfoFrom(t *testing.T) {
+	got, err := parseSlabinfoFrom(strings.NewReader(slabinfoSample), 4096, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ext4 := uint64(1567 * 8 * 4096)
+	dentry := uint64(5735 * 1 * 4096)
+	kmalloc256 := uint64(108 * 2 * 4096)
+	kmalloc8 := uint64(24 * 1 * 4096)
+
+	if want := ext4 + dentry + kmalloc256 + kmalloc8; got.TotalBytes != want {
+		t.Errorf("TotalBytes: got %d, want %d", got.TotalBytes, want)
+	}
+	if len(got.Caches) != 2 {
+		t.Fatalf("caches: got %d, want 2", len(got.Caches))
+	}
+	if got.Caches[0].Name != "ext4_inode_cache" || got.Caches[0].Bytes != ext4 {
+		t.Errorf("largest: got %+v, want ext4_inode_cache at %d bytes", got.Caches[0], ext4)
+	}
+	if c := got.Caches[1]; c.Name != "dentry" || c.Bytes != dentry || c.ActiveObjs != 118209 || c.NumObjs != 120435 || c.ObjSize != 192 {
+		t.Errorf("second: got %+v", c)
+	}
+}
+
+func TestParseSlabinfoFrom_BadHeader(t *testing.T) {
+	for _, input := range []string{"", "slabinfo - version: 1.1\n", "ext4_inode_cache 1 1 1 1 1\n"} {
+		if _, err := parseSlabinfoFrom(strings.NewReader(input), 4096, 10); err == nil {
+			t.Errorf("input %q: expected error", input)
+		}
+	}
+}
+
+func TestParseSlabinfoFrom_MalformedCounter(t *testing.T) {
+	input := "slabinfo - version: 2.1\nbad x 1 1 1 1 : tunables 0 0 0 : slabdata 1 1 0\n"
+	if _, err := parseSlabinfoFrom(strings.NewReader(input), 4096, 10); err == nil {
+		t.Error("expected error for non-numeric counter")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package memory
+
+import (
+	"context"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// CollectSlab is a no-op on platforms without /proc/slabinfo.
+func CollectSlab(ctx context.Context) ([]protocol.Metric, error) {
+	return nil, nil
+}
@@ -0,0 +1,86 @@
+//go:build linux
+
+package memory
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// CollectSwap reports each active swap partition or file from
+// /proc/swaps. It is a no-op when /proc/swaps is absent.
+func CollectSwap(ctx context.Context) ([]protocol.Metric, error) {
+	f, err := os.Open("/proc/swaps")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	devices, err := parseSwapsFrom(f)
+	if err != nil {
+		return nil, fmt.Errorf("parsing /proc/swaps: %w", err)
+	}
+
+	metrics := make([]protocol.Metric, 0, len(devices))
+	for _, d := range devices {
+		metrics = append(metrics, d)
+	}
+	return metrics, nil
+}
+
+// swapNameUnescaper undoes the octal escaping /proc/swaps applies to
+// whitespace and backslashes in paths.
+var swapNameUnescaper = strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`)
+
+// parseSwapsFrom parses /proc/swaps:
+//
+//	Filename    Type       Size     Used  Priority
+//	/dev/sda2   partition  8388604  0     -2
+//
+// Size and Used are in KiB and converted to bytes.
+func parseSwapsFrom(r io.Reader) ([]protocol.SwapDeviceMetric, error) {
+	var devices []protocol.SwapDeviceMetric
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] == "Filename" {
+			continue
+		}
+
+		size, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("size for %s: %w", fields[0], err)
+		}
+		used, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("used for %s: %w", fields[0], err)
+		}
+		prio, err := strconv.Atoi(fields[4])
+		if err != nil {
+			return nil, fmt.Errorf("priority for %s: %w", fields[0], err)
+		}
+
+		devices = append(devices, protocol.SwapDeviceMetric{
+			Name:     swapNameUnescaper.Replace(fields[0]),
+			Type:     fields[1],
+			Size:     size * 1024,
+			Used:     used * 1024,
+			Priority: prio,
+		})
+	}
+
+	return devices, scanner.Err()
+}
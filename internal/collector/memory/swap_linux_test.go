@@ -0,0 +1,50 @@
+//go:build linux
+
+package memory
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestParseSwapsFrom(t *testing.T) {
+	input := `Filename				Type		Size		Used		Priority
+/dev/sda2                               partition	8388604		1024		-2
+/mnt/sd\040card/swapfile                file		2097148		524288		-3
+`
+	got, err := parseSwapsFrom(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []protocol.SwapDeviceMetric{
+		{Name: "/dev/sda2", Type: "partition", Size: 8388604 * 1024, Used: 1024 * 1024, Priority: -2},
+		{Name: "/mnt/sd card/swapfile", Type: "file", Size: 2097148 * 1024, Used: 524288 * 1024, Priority: -3},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseSwapsFrom_NoSwap(t *testing.T) {
+	got, err := parseSwapsFrom(strings.NewReader("Filename\t\t\t\tType\t\tSize\t\tUsed\t\tPriority\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %d entries, want none", len(got))
+	}
+}
+
+func TestParseSwapsFrom_Malformed(t *testing.T) {
+	if _, err := parseSwapsFrom(strings.NewReader("/dev/sda2 partition big 0 -2\n")); err == nil {
+		t.Error("expected error for non-numeric size")
+	}
+}
@@ -0,0 +1,87 @@
+//go:build linux
+
+package network
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// Neighbor entry flags from <linux/if_arp.h>.
+const (
+	atfCom  = 0x02 // completed entry (MAC known)
+	atfPerm = 0x04 // permanent entry
+)
+
+// CollectARP reports the kernel's IPv4 neighbor table from /proc/net/arp.
+// It is a no-op when the file is absent.
+func CollectARP(ctx context.Context) ([]protocol.Metric, error) {
+	f, err := os.Open("/proc/net/arp")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries, err := parseProcARPFrom(f)
+	if err != nil {
+		return nil, fmt.Errorf("parsing /proc/net/arp: %w", err)
+	}
+	return []protocol.Metric{protocol.ARPTableMetric{Entries: entries}}, nil
+}
+
+// parseProcARPFrom parses /proc/net/arp:
+//
+//	IP address       HW type     Flags       HW address            Mask     Device
+//	192.168.1.1      0x1         0x2         aa:bb:cc:dd:ee:ff     *        eth0
+//
+// Incomplete entries (still resolving, or unanswered) have no MAC.
+func parseProcARPFrom(r io.Reader) ([]protocol.ARPEntry, error) {
+	entries := []protocol.ARPEntry{}
+	scanner := bufio.NewScanner(r)
+
+	// Skip header
+	scanner.Scan()
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(strings.TrimPrefix(fields[2], "0x"), 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("bad flags %q for %s: %w", fields[2], fields[0], err)
+		}
+
+		e := protocol.ARPEntry{
+			IP:     fields[0],
+			Device: fields[5],
+		}
+		switch {
+		case flags&atfPerm != 0:
+			e.State = protocol.ARPPermanent
+		case flags&atfCom != 0:
+			e.State = protocol.ARPComplete
+		default:
+			e.State = protocol.ARPIncomplete
+		}
+		if e.State != protocol.ARPIncomplete {
+			e.MAC = strings.ToLower(fields[3])
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, scanner.Err()
+}
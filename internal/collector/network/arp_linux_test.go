@@ -0,0 +1,53 @@
+//go:build linux
+
+package network
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestParseProcARPFrom(t *testing.T) {
+	input := `IP address       HW type     Flags       HW address            Mask     Device
+192.168.1.1      0x1         0x2         AA:BB:CC:DD:EE:FF     *        eth0
+192.168.1.50     0x1         0x0         00:00:00:00:00:00     *        eth0
+10.0.0.2         0x1         0x6         11:22:33:44:55:66     *        wg0
+`
+	got, err := parseProcARPFrom(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []protocol.ARPEntry{
+		{IP: "192.168.1.1", MAC: "aa:bb:cc:dd:ee:ff", Device: "eth0", State: protocol.ARPComplete},
+		{IP: "192.168.1.50", Device: "eth0", State: protocol.ARPIncomplete},
+		{IP: "10.0.0.2", MAC: "11:22:33:44:55:66", Device: "wg0", State: protocol.ARPPermanent},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseProcARPFrom_HeaderOnly(t *testing.T) {
+	got, err := parseProcARPFrom(strings.NewReader("IP address       HW type     Flags       HW address            Mask     Device\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Errorf("got %v, want an empty, non-nil list", got)
+	}
+}
+
+func TestParseProcARPFrom_BadFlags(t *testing.T) {
+	input := "IP address HW type Flags HW address Mask Device\n192.168.1.1 0x1 zz aa:bb:cc:dd:ee:ff * eth0\n"
+	if _, err := parseProcARPFrom(strings.NewReader(input)); err == nil {
+		t.Error("expected error for malformed flags")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package network
+
+import (
+	"context"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// CollectARP is only implemented on Linux.
+func CollectARP(ctx context.Context) ([]protocol.Metric, error) {
+	return nil, nil
+}
@@ -0,0 +1,105 @@
+//go:build linux
+
+package network
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// CollectSocketSummary reports socket counts from /proc/net/sockstat and,
+// when IPv6 is enabled, /proc/net/sockstat6. It is a no-op when sockstat
+// is absent.
+func CollectSocketSummary(ctx context.Context) ([]protocol.Metric, error) {
+	var m protocol.SocketStatMetric
+
+	ok, err := parseSockstatFile("/proc/net/sockstat", &m)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if _, err := parseSockstatFile("/proc/net/sockstat6", &m); err != nil {
+		return nil, err
+	}
+	return []protocol.Metric{m}, nil
+}
+
+// parseSockstatFile parses path into m. ok is false when path does not
+// exist.
+func parseSockstatFile(path string, m *protocol.SocketStatMetric) (ok bool, err error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	if err := parseSockstatFrom(f, m); err != nil {
+		return false, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// parseSockstatFrom parses sockstat or sockstat6 into m. Each line is a
+// protocol followed by key/value pairs:
+//
+//	sockets: used 290
+//	TCP: inuse 5 orphan 0 tw 2 alloc 7 mem 1
+//	UDP: inuse 3 mem 2
+//	TCP6: inuse 3
+//
+// Protocols and keys that SocketStatMetric doesn't carry are skipped.
+func parseSockstatFrom(r io.Reader, m *protocol.SocketStatMetric) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		proto, rest, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		fields := strings.Fields(rest)
+		for i := 0; i+1 < len(fields); i += 2 {
+			dst := sockstatField(m, proto, fields[i])
+			if dst == nil {
+				continue
+			}
+			v, err := strconv.Atoi(fields[i+1])
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", proto, fields[i], err)
+			}
+			*dst = v
+		}
+	}
+	return scanner.Err()
+}
+
+func sockstatField(m *protocol.SocketStatMetric, proto, key string) *int {
+	switch proto + " " + key {
+	case "sockets used":
+		return &m.Used
+	case "TCP inuse":
+		return &m.TCPInUse
+	case "TCP orphan":
+		return &m.TCPOrphan
+	case "TCP tw":
+		return &m.TCPTimeWait
+	case "TCP alloc":
+		return &m.TCPAlloc
+	case "UDP inuse":
+		return &m.UDPInUse
+	case "TCP6 inuse":
+		return &m.TCP6InUse
+	case "UDP6 inuse":
+		return &m.UDP6InUse
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+//go:build linux
+
+package network
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestParseSockstatFrom(t *testing.T) {
+	sockstat := `sockets: used 290
+TCP: inuse 5 orphan 1 tw 2 alloc 7 mem 1
+UDP: inuse 3 mem 2
+UDPLITE: inuse 0
+RAW: inuse 0
+FRAG: inuse 0 memory 0
+`
+	sockstat6 := `TCP6: inuse 4
+UDP6: inuse 2
+UDPLITE6: inuse 0
+RAW6: inuse 1
+FRAG6: inuse 0 memory 0
+`
+	var got protocol.SocketStatMetric
+	if err := parseSockstatFrom(strings.NewReader(sockstat), &got); err != nil {
+		t.Fatalf("sockstat: %v", err)
+	}
+	if err := parseSockstatFrom(strings.NewReader(sockstat6), &got); err != nil {
+		t.Fatalf("sockstat6: %v", err)
+	}
+
+	want := protocol.SocketStatMetric{
+		Used:        290,
+		TCPInUse:    5,
+		TCP6InUse:   4,
+		TCPOrphan:   1,
+		TCPTimeWait: 2,
+		TCPAlloc:    7,
+		UDPInUse:    3,
+		UDP6InUse:   2,
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseSockstatFrom_Malformed(t *testing.T) {
+	var m protocol.SocketStatMetric
+	if err := parseSockstatFrom(strings.NewReader("TCP: inuse lots\n"), &m); err == nil {
+		t.Error("expected error for non-numeric count")
+	}
+}
+
+func TestParseSockstatFrom_IgnoresUnknown(t *testing.T) {
+	var m protocol.SocketStatMetric
+	if err := parseSockstatFrom(strings.NewReader("MPTCP: inuse x\nsockets: used 12 extra\n"), &m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Used != 12 {
+		t.Errorf("Used = %d, want 12", m.Used)
+	}
+}
+
+func TestCollectSocketSummary(t *testing.T) {
+	got, err := CollectSocketSummary(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Skip("/proc/net/sockstat not available")
+	}
+	if err := got[0].(protocol.SocketStatMetric).Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
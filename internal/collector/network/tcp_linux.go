@@ -0,0 +1,179 @@
+//go:build linux
+
+package network
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+	"github.com/nhdewitt/spectra/internal/util"
+)
+
+// tcpRaw holds the cumulative TCP counters from /proc/net/snmp and
+// /proc/net/netstat.
+type tcpRaw struct {
+	OutSegs     uint64
+	RetransSegs uint64
+	InErrs      uint64
+	OutRsts     uint64
+	Timeouts    uint64 // TcpExt, 0 without netstat
+	ListenDrops uint64 // TcpExt, 0 without netstat
+	Time        time.Time
+}
+
+// Package-level state for TCP delta calculation
+var lastTCP tcpRaw
+
+// CollectTCPStats reports TCP retransmit and error rates since the previous
+// call. The first call only records a baseline. It is a no-op when
+// /proc/net/snmp is absent.
+func CollectTCPStats(ctx context.Context) ([]protocol.Metric, error) {
+	snmp, err := os.Open("/proc/net/snmp")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer snmp.Close()
+
+	var netstat io.Reader
+	if f, err := os.Open("/proc/net/netstat"); err == nil {
+		defer f.Close()
+		netstat = f
+	}
+
+	cur, err := parseTCPStatsFrom(snmp, netstat)
+	if err != nil {
+		return nil, err
+	}
+	cur.Time = time.Now()
+
+	prev := lastTCP
+	lastTCP = cur
+
+	m, ok := calcTCPRates(cur, prev)
+	if !ok {
+		return nil, nil
+	}
+	return []protocol.Metric{m}, nil
+}
+
+// parseTCPStatsFrom reads the Tcp section of /proc/net/snmp and, if
+// netstat is non-nil, the TcpExt section of /proc/net/netstat.
+func parseTCPStatsFrom(snmp, netstat io.Reader) (tcpRaw, error) {
+	var raw tcpRaw
+
+	tcp, err := parseSNMPSectionFrom(snmp, "Tcp")
+	if err != nil {
+		return raw, fmt.Errorf("parsing /proc/net/snmp: %w", err)
+	}
+	if tcp == nil {
+		return raw, errors.New("parsing /proc/net/snmp: no Tcp section")
+	}
+	for key, dst := range map[string]*uint64{
+		"OutSegs":     &raw.OutSegs,
+		"RetransSegs": &raw.RetransSegs,
+		"InErrs":      &raw.InErrs,
+		"OutRsts":     &raw.OutRsts,
+	} {
+		if *dst, err = snmpCounter(tcp, key); err != nil {
+			return raw, fmt.Errorf("parsing /proc/net/snmp: %w", err)
+		}
+	}
+
+	if netstat == nil {
+		return raw, nil
+	}
+	ext, err := parseSNMPSectionFrom(netstat, "TcpExt")
+	if err != nil {
+		return raw, fmt.Errorf("parsing /proc/net/netstat: %w", err)
+	}
+	// Older kernels lack some TcpExt counters; leave those at zero
+	raw.Timeouts, _ = snmpCounter(ext, "TCPTimeouts")
+	raw.ListenDrops, _ = snmpCounter(ext, "ListenDrops")
+	return raw, nil
+}
+
+// parseSNMPSectionFrom returns the named section of an snmp-style file,
+// where each section is a header line of names followed by a line of
+// values with the same prefix:
+//
+//	Tcp: RtoAlgorithm RtoMin RtoMax MaxConn ActiveOpens ...
+//	Tcp: 1 200 120000 -1 4562 ...
+//
+// It returns nil if the section is absent.
+func parseSNMPSectionFrom(r io.Reader, section string) (map[string]string, error) {
+	prefix := section + ":"
+	scanner := bufio.NewScanner(r)
+	// TcpExt lines run to a few kilobytes
+	scanner.Buffer(make([]byte, 0, 16*1024), 256*1024)
+
+	for scanner.Scan() {
+		names := strings.Fields(scanner.Text())
+		if len(names) == 0 || names[0] != prefix {
+			continue
+		}
+		if !scanner.Scan() {
+			break
+		}
+		values := strings.Fields(scanner.Text())
+		if len(values) != len(names) || values[0] != prefix {
+			return nil, fmt.Errorf("%s: header has %d fields, values have %d", section, len(names), len(values))
+		}
+
+		fields := make(map[string]string, len(names)-1)
+		for i := 1; i < len(names); i++ {
+			fields[names[i]] = values[i]
+		}
+		return fields, nil
+	}
+	return nil, scanner.Err()
+}
+
+func snmpCounter(fields map[string]string, key string) (uint64, error) {
+	v, ok := fields[key]
+	if !ok {
+		return 0, fmt.Errorf("missing %s", key)
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return n, nil
+}
+
+// calcTCPRates returns per-second rates between two samples. It reports
+// false when there is no previous sample, no time has passed, or a counter
+// went backwards.
+func calcTCPRates(cur, prev tcpRaw) (protocol.TCPStatMetric, bool) {
+	if prev.Time.IsZero() ||
+		cur.OutSegs < prev.OutSegs || cur.RetransSegs < prev.RetransSegs ||
+		cur.InErrs < prev.InErrs || cur.OutRsts < prev.OutRsts ||
+		cur.Timeouts < prev.Timeouts || cur.ListenDrops < prev.ListenDrops {
+		return protocol.TCPStatMetric{}, false
+	}
+	secs := cur.Time.Sub(prev.Time).Seconds()
+	if secs <= 0 {
+		return protocol.TCPStatMetric{}, false
+	}
+
+	retrans := cur.RetransSegs - prev.RetransSegs
+	return protocol.TCPStatMetric{
+		RetransPerSec:     float64(retrans) / secs,
+		RetransPct:        util.Percent(retrans, cur.OutSegs-prev.OutSegs),
+		InErrsPerSec:      float64(cur.InErrs-prev.InErrs) / secs,
+		OutRstsPerSec:     float64(cur.OutRsts-prev.OutRsts) / secs,
+		TimeoutsPerSec:    float64(cur.Timeouts-prev.Timeouts) / secs,
+		ListenDropsPerSec: float64(cur.ListenDrops-prev.ListenDrops) / secs,
+	}, true
+}
@@ -0,0 +1,122 @@
+//go:build linux
+
+package network
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const sampleSNMP = `Ip: Forwarding DefaultTTL InReceives InHdrErrors
+Ip: 1 64 1203341 0
+Icmp: InMsgs InErrors
+Icmp: 45 0
+Tcp: RtoAlgorithm RtoMin RtoMax MaxConn ActiveOpens PassiveOpens AttemptFails EstabResets CurrEstab InSegs OutSegs RetransSegs InErrs OutRsts InCsumErrors
+Tcp: 1 200 120000 -1 4562 3987 493 128 2 86896 87817 31 2 588 0
+Udp: InDatagrams NoPorts InErrors OutDatagrams
+Udp: 5123 12 0 5200
+`
+
+const sampleNetstat = `TcpExt: SyncookiesSent ListenOverflows ListenDrops TCPTimeouts
+TcpExt: 0 3 4 57
+MPTcpExt: MPCapableSYNRX TCPTimeouts
+MPTcpExt: 0 999
+IpExt: InNoRoutes InTruncatedPkts
+IpExt: 0 0
+`
+
+func TestParseTCPStatsFrom(t *testing.T) {
+	got, err := parseTCPStatsFrom(strings.NewReader(sampleSNMP), strings.NewReader(sampleNetstat))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := tcpRaw{OutSegs: 87817, RetransSegs: 31, InErrs: 2, OutRsts: 588, Timeouts: 57, ListenDrops: 4}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseTCPStatsFrom_NoNetstat(t *testing.T) {
+	got, err := parseTCPStatsFrom(strings.NewReader(sampleSNMP), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.RetransSegs != 31 || got.Timeouts != 0 || got.ListenDrops != 0 {
+		t.Errorf("got %+v, want snmp counters only", got)
+	}
+}
+
+func TestParseTCPStatsFrom_Malformed(t *testing.T) {
+	tests := []struct {
+		name string
+		snmp string
+	}{
+		{"no tcp section", "Udp: InDatagrams\nUdp: 1\n"},
+		{"truncated values", "Tcp: InSegs OutSegs RetransSegs InErrs OutRsts\nTcp: 1 2 3\n"},
+		{"missing counter", "Tcp: InSegs OutSegs RetransSegs InErrs\nTcp: 1 2 3 4\n"},
+		{"not a number", "Tcp: OutSegs RetransSegs InErrs OutRsts\nTcp: 1 x 3 4\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseTCPStatsFrom(strings.NewReader(tt.snmp), nil); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestCalcTCPRates(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	prev := tcpRaw{OutSegs: 10_000, RetransSegs: 100, InErrs: 5, OutRsts: 50, Timeouts: 10, ListenDrops: 0, Time: t0}
+	cur := tcpRaw{OutSegs: 12_000, RetransSegs: 150, InErrs: 15, OutRsts: 60, Timeouts: 20, ListenDrops: 5, Time: t0.Add(10 * time.Second)}
+
+	got, ok := calcTCPRates(cur, prev)
+	if !ok {
+		t.Fatal("expected rates for two valid samples")
+	}
+	want := protocol.TCPStatMetric{
+		RetransPerSec:     5,
+		RetransPct:        2.5,
+		InErrsPerSec:      1,
+		OutRstsPerSec:     1,
+		TimeoutsPerSec:    1,
+		ListenDropsPerSec: 0.5,
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, ok := calcTCPRates(cur, tcpRaw{}); ok {
+		t.Error("expected no rates without a previous sample")
+	}
+	if _, ok := calcTCPRates(cur, cur); ok {
+		t.Error("expected no rates when no time has passed")
+	}
+}
+
+func TestCalcTCPRates_CounterReset(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	prev := tcpRaw{OutSegs: 10_000, RetransSegs: 100, InErrs: 5, OutRsts: 50, Timeouts: 10, Time: t0}
+	later := t0.Add(10 * time.Second)
+
+	tests := []struct {
+		name string
+		cur  tcpRaw
+	}{
+		{"out segs regressed", tcpRaw{OutSegs: 1, RetransSegs: 100, InErrs: 5, OutRsts: 50, Timeouts: 10, Time: later}},
+		{"retrans regressed", tcpRaw{OutSegs: 20_000, RetransSegs: 1, InErrs: 5, OutRsts: 50, Timeouts: 10, Time: later}},
+		{"timeouts regressed", tcpRaw{OutSegs: 20_000, RetransSegs: 100, InErrs: 5, OutRsts: 50, Time: later}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if m, ok := calcTCPRates(tt.cur, prev); ok {
+				t.Errorf("expected sample to be skipped, got %+v", m)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+//go:build linux
+
+package pci
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const sysfsPCIDevices = "/sys/bus/pci/devices"
+
+// Collect reports the PCI devices present. It prefers `lspci -mm -nn`,
+// which resolves IDs to names through the pci.ids database, and falls
+// back to sysfs with hex IDs only. It is a no-op when neither is available.
+func Collect(ctx context.Context) ([]protocol.Metric, error) {
+	var devices []protocol.PCIDeviceMetric
+	var err error
+
+	if out, lerr := exec.CommandContext(ctx, "lspci", "-mm", "-nn").Output(); lerr == nil {
+		devices, err = parseLspciFrom(bytes.NewReader(out))
+	} else {
+		devices, err = parsePCISysfsFrom(sysfsPCIDevices)
+	}
+	if err != nil || devices == nil {
+		return nil, err
+	}
+
+	return []protocol.Metric{protocol.PCIDeviceListMetric{Devices: devices}}, nil
+}
+
+// parseLspciFrom parses `lspci -mm -nn` output, one device per line:
+//
+//	00:02.0 "VGA compatible controller [0300]" "Intel Corporation [8086]" "HD Graphics 620 [5916]" -r02 "Lenovo [17aa]" "Device [224b]"
+//
+// Unquoted -r/-p tokens carry the revision and programming interface.
+func parseLspciFrom(r io.Reader) ([]protocol.PCIDeviceMetric, error) {
+	devices := []protocol.PCIDeviceMetric{}
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		fields := splitLspciFields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+
+		dev := protocol.PCIDeviceMetric{Slot: fields[0]}
+		var quoted []string
+		for _, f := range fields[1:] {
+			if unq, ok := strings.CutPrefix(f, `"`); ok {
+				quoted = append(quoted, strings.TrimSuffix(unq, `"`))
+			} else if rev, ok := strings.CutPrefix(f, "-r"); ok {
+				dev.Revision = rev
+			}
+		}
+		if len(quoted) < 3 {
+			continue
+		}
+
+		dev.Class, dev.ClassID = splitNameID(quoted[0])
+		dev.Vendor, dev.VendorID = splitNameID(quoted[1])
+		dev.Device, dev.DeviceID = splitNameID(quoted[2])
+		dev.Class = unresolved(dev.Class, "Class")
+		dev.Vendor = unresolved(dev.Vendor, "Vendor")
+		dev.Device = unresolved(dev.Device, "Device")
+		devices = append(devices, dev)
+	}
+
+	return devices, scanner.Err()
+}
+
+// splitLspciFields splits a line on spaces, keeping quoted strings whole
+// (quotes included) so they can be told apart from option tokens.
+func splitLspciFields(line string) []string {
+	var fields []string
+	for line = strings.TrimSpace(line); line != ""; line = strings.TrimSpace(line) {
+		end := strings.IndexByte(line, ' ')
+		if line[0] == '"' {
+			if q := strings.IndexByte(line[1:], '"'); q >= 0 {
+				end = q + 2
+			} else {
+				end = -1
+			}
+		}
+		if end < 0 || end >= len(line) {
+			fields = append(fields, line)
+			break
+		}
+		fields = append(fields, line[:end])
+		line = line[end:]
+	}
+	return fields
+}
+
+// splitNameID splits "Intel Corporation [8086]" into its name and hex ID.
+// Without a bracketed ID the whole string is returned as the name.
+func splitNameID(s string) (name, id string) {
+	if strings.HasSuffix(s, "]") {
+		if i := strings.LastIndex(s, " ["); i >= 0 {
+			return s[:i], s[i+2 : len(s)-1]
+		}
+	}
+	return s, ""
+}
+
+// unresolved returns "" when name is the placeholder lspci prints for an
+// ID missing from pci.ids, so unknown names stay empty as in sysfs mode.
+func unresolved(name, placeholder string) string {
+	if name == placeholder {
+		return ""
+	}
+	return name
+}
+
+// parsePCISysfsFrom reads every device under a sysfs pci/devices root,
+// reporting hex IDs without names. It returns nil if root does not exist.
+func parsePCISysfsFrom(root string) ([]protocol.PCIDeviceMetric, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	devices := []protocol.PCIDeviceMetric{}
+	for _, e := range entries {
+		dir := filepath.Join(root, e.Name())
+		vendor := readHexAttr(dir, "vendor")
+		device := readHexAttr(dir, "device")
+		if vendor == "" || device == "" {
+			continue
+		}
+
+		// class is 0xCCSSPP; lspci reports the class and subclass
+		class := readHexAttr(dir, "class")
+		if len(class) == 6 {
+			class = class[:4]
+		}
+
+		devices = append(devices, protocol.PCIDeviceMetric{
+			Slot:     strings.TrimPrefix(e.Name(), "0000:"),
+			ClassID:  class,
+			VendorID: vendor,
+			DeviceID: device,
+			Revision: readHexAttr(dir, "revision"),
+		})
+	}
+
+	slices.SortFunc(devices, func(a, b protocol.PCIDeviceMetric) int {
+		return strings.Compare(a.Slot, b.Slot)
+	})
+	return devices, nil
+}
+
+// readHexAttr returns a sysfs hex attribute without its 0x prefix, or ""
+// if it can't be read.
+func readHexAttr(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(data)), "0x")
+}
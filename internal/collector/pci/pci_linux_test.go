@@ -0,0 +1,94 @@
+//go:build linux
+
+package pci
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const lspciSample = `00:00.0 "Host bridge [0600]" "Intel Corporation [8086]" "Xeon E3-1200 v6/7th Gen Core Processor Host Bridge/DRAM Registers [5904]" -r02 "Lenovo [17aa]" "Device [224b]"
+00:02.0 "VGA compatible controller [0300]" "Intel Corporation [8086]" "HD Graphics 620 [5916]" -r02 "Lenovo [17aa]" "ThinkPad T470 [224b]"
+01:00.0 "Ethernet controller [0200]" "Realtek Semiconductor Co., Ltd. [10ec]" "RTL8111/8168/8411 PCI Express Gigabit Ethernet Controller [8168]" -r15 -p01 "" ""
+02:00.0 "Class [0108]" "Vendor [144d]" "Device [a808]"
+`
+
+func TestParseLspciFrom(t *testing.T) {
+	got, err := parseLspciFrom(strings.NewReader(lspciSample))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []protocol.PCIDeviceMetric{
+		{Slot: "00:00.0", ClassID: "0600", Class: "Host bridge", VendorID: "8086", Vendor: "Intel Corporation",
+			DeviceID: "5904", Device: "Xeon E3-1200 v6/7th Gen Core Processor Host Bridge/DRAM Registers", Revision: "02"},
+		{Slot: "00:02.0", ClassID: "0300", Class: "VGA compatible controller", VendorID: "8086", Vendor: "Intel Corporation",
+			DeviceID: "5916", Device: "HD Graphics 620", Revision: "02"},
+		{Slot: "01:00.0", ClassID: "0200", Class: "Ethernet controller", VendorID: "10ec", Vendor: "Realtek Semiconductor Co., Ltd.",
+			DeviceID: "8168", Device: "RTL8111/8168/8411 PCI Express Gigabit Ethernet Controller", Revision: "15"},
+		// Without a pci.ids entry lspci prints generic names, reported as empty
+		{Slot: "02:00.0", ClassID: "0108", VendorID: "144d", DeviceID: "a808"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseLspciFrom_Malformed(t *testing.T) {
+	got, err := parseLspciFrom(strings.NewReader("garbage\n00:1f.0 \"ISA bridge [0601]\"\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no devices, got %+v", got)
+	}
+}
+
+func TestSplitNameID(t *testing.T) {
+	tests := []struct {
+		in, name, id string
+	}{
+		{"Intel Corporation [8086]", "Intel Corporation", "8086"},
+		{"Intel Corporation", "Intel Corporation", ""},
+		{"Device [a808]", "Device", "a808"},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		name, id := splitNameID(tt.in)
+		if name != tt.name || id != tt.id {
+			t.Errorf("splitNameID(%q) = %q, %q; want %q, %q", tt.in, name, id, tt.name, tt.id)
+		}
+	}
+}
+
+func TestParsePCISysfsFrom(t *testing.T) {
+	root := t.TempDir()
+	dev := filepath.Join(root, "0000:00:02.0")
+	if err := os.MkdirAll(dev, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, val := range map[string]string{"vendor": "0x8086", "device": "0x5916", "class": "0x030000", "revision": "0x02"} {
+		if err := os.WriteFile(filepath.Join(dev, name), []byte(val+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := parsePCISysfsFrom(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []protocol.PCIDeviceMetric{{Slot: "00:02.0", ClassID: "0300", VendorID: "8086", DeviceID: "5916", Revision: "02"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if missing, err := parsePCISysfsFrom(filepath.Join(root, "missing")); err != nil || missing != nil {
+		t.Errorf("missing root: got %+v, %v; want nil, nil", missing, err)
+	}
+}
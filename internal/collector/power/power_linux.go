@@ -0,0 +1,144 @@
+//go:build linux
+
+package power
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const powercapRoot = "/sys/class/powercap"
+
+// raplSample is one reading of a RAPL zone's cumulative energy counter.
+type raplSample struct {
+	Domain   string // e.g. "package-0", "package-0/dram"
+	Energy   uint64 // energy_uj
+	MaxRange uint64 // max_energy_range_uj, where Energy wraps to zero
+	Time     time.Time
+}
+
+var (
+	raplMu   sync.Mutex
+	lastRAPL map[string]raplSample
+)
+
+// CollectRAPL reports the average power draw of each RAPL domain since
+// the previous call. The first call only records a baseline. It is a
+// no-op where powercap is absent or energy_uj isn't readable (it is
+// root-only on most current kernels).
+func CollectRAPL(ctx context.Context) ([]protocol.Metric, error) {
+	cur := readRAPLFrom(powercapRoot, time.Now())
+	if len(cur) == 0 {
+		return nil, nil
+	}
+
+	raplMu.Lock()
+	prev := lastRAPL
+	lastRAPL = cur
+	raplMu.Unlock()
+
+	return calcPowerDraw(cur, prev), nil
+}
+
+// readRAPLFrom reads every intel-rapl zone under a sysfs powercap root,
+// keyed by zone directory. Subzones are named after their parent, so two
+// sockets' "dram" zones stay distinct. Unreadable zones are skipped.
+func readRAPLFrom(root string, now time.Time) map[string]raplSample {
+	dirs, _ := filepath.Glob(filepath.Join(root, "intel-rapl:*"))
+
+	samples := make(map[string]raplSample, len(dirs))
+	for _, dir := range dirs {
+		energy, err := readUintAttr(dir, "energy_uj")
+		if err != nil {
+			continue
+		}
+		maxRange, _ := readUintAttr(dir, "max_energy_range_uj")
+
+		zone := filepath.Base(dir)
+		domain := readAttr(dir, "name")
+		// "intel-rapl:0:1" is a subzone of "intel-rapl:0"
+		if i := strings.LastIndexByte(zone, ':'); strings.Count(zone, ":") > 1 {
+			if parent := readAttr(filepath.Join(root, zone[:i]), "name"); parent != "" {
+				domain = parent + "/" + domain
+			}
+		}
+		if domain == "" {
+			domain = zone
+		}
+
+		samples[zone] = raplSample{
+			Domain:   domain,
+			Energy:   energy,
+			MaxRange: maxRange,
+			Time:     now,
+		}
+	}
+	return samples
+}
+
+// calcPowerDraw converts two sets of samples into average watts per
+// domain, sorted by domain. Zones missing from prev are skipped.
+func calcPowerDraw(cur, prev map[string]raplSample) []protocol.Metric {
+	var results []protocol.PowerDrawMetric
+	for zone, c := range cur {
+		p, ok := prev[zone]
+		if !ok {
+			continue
+		}
+		secs := c.Time.Sub(p.Time).Seconds()
+		if secs <= 0 {
+			continue
+		}
+
+		results = append(results, protocol.PowerDrawMetric{
+			Domain: c.Domain,
+			Watts:  float64(energyDelta(p.Energy, c.Energy, c.MaxRange)) / 1e6 / secs,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Domain < results[j].Domain })
+
+	metrics := make([]protocol.Metric, len(results))
+	for i, r := range results {
+		metrics[i] = r
+	}
+	return metrics
+}
+
+// energyDelta returns the microjoules consumed between two counter
+// readings, allowing for one wrap at maxRange. A counter that went
+// backwards without a known range counts as zero.
+func energyDelta(prev, cur, maxRange uint64) uint64 {
+	if cur >= prev {
+		return cur - prev
+	}
+	if maxRange == 0 || prev > maxRange {
+		return 0
+	}
+	return maxRange - prev + cur
+}
+
+// readAttr returns a trimmed sysfs attribute, or "" if it can't be read.
+func readAttr(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readUintAttr(dir, name string) (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
@@ -0,0 +1,102 @@
+//go:build linux
+
+package power
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func writeZone(t *testing.T, root, zone, name, energy, maxRange string) {
+	t.Helper()
+	dir := filepath.Join(root, zone)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for file, val := range map[string]string{"name": name, "energy_uj": energy, "max_energy_range_uj": maxRange} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(val+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadRAPLFrom(t *testing.T) {
+	root := t.TempDir()
+	writeZone(t, root, "intel-rapl:0", "package-0", "1000000", "262143328850")
+	writeZone(t, root, "intel-rapl:0:0", "core", "400000", "262143328850")
+	writeZone(t, root, "intel-rapl:0:1", "dram", "200000", "65712999613")
+	// Other powercap controls are ignored
+	writeZone(t, root, "dtpm", "dtpm", "1", "1")
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	got := readRAPLFrom(root, now)
+
+	want := map[string]raplSample{
+		"intel-rapl:0":   {Domain: "package-0", Energy: 1000000, MaxRange: 262143328850, Time: now},
+		"intel-rapl:0:0": {Domain: "package-0/core", Energy: 400000, MaxRange: 262143328850, Time: now},
+		"intel-rapl:0:1": {Domain: "package-0/dram", Energy: 200000, MaxRange: 65712999613, Time: now},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestReadRAPLFrom_Missing(t *testing.T) {
+	if got := readRAPLFrom(filepath.Join(t.TempDir(), "missing"), time.Now()); len(got) != 0 {
+		t.Errorf("expected no zones, got %+v", got)
+	}
+}
+
+func TestCalcPowerDraw(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	t1 := t0.Add(10 * time.Second)
+
+	prev := map[string]raplSample{
+		"intel-rapl:0":   {Domain: "package-0", Energy: 5_000_000, MaxRange: 262_143_328_850, Time: t0},
+		"intel-rapl:0:1": {Domain: "package-0/dram", Energy: 65_700_000_000, MaxRange: 65_712_999_613, Time: t0},
+	}
+	cur := map[string]raplSample{
+		// 150 J over 10 s
+		"intel-rapl:0": {Domain: "package-0", Energy: 155_000_000, MaxRange: 262_143_328_850, Time: t1},
+		// Wrapped: 12_999_613 uJ to the top of the range plus 7_000_387 after = 20 J
+		"intel-rapl:0:1": {Domain: "package-0/dram", Energy: 7_000_387, MaxRange: 65_712_999_613, Time: t1},
+		// New zone without a baseline is skipped
+		"intel-rapl:1": {Domain: "package-1", Energy: 1, MaxRange: 1, Time: t1},
+	}
+
+	got := calcPowerDraw(cur, prev)
+	want := []protocol.Metric{
+		protocol.PowerDrawMetric{Domain: "package-0", Watts: 15},
+		protocol.PowerDrawMetric{Domain: "package-0/dram", Watts: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+
+	if got := calcPowerDraw(cur, nil); len(got) != 0 {
+		t.Errorf("expected no metrics without a baseline, got %+v", got)
+	}
+}
+
+func TestEnergyDelta(t *testing.T) {
+	tests := []struct {
+		name                string
+		prev, cur, maxRange uint64
+		want                uint64
+	}{
+		{"increase", 100, 250, 1000, 150},
+		{"wrap", 900, 50, 1000, 150},
+		{"no range", 900, 50, 0, 0},
+		{"prev beyond range", 2000, 50, 1000, 0},
+	}
+	for _, tt := range tests {
+		if got := energyDelta(tt.prev, tt.cur, tt.maxRange); got != tt.want {
+			t.Errorf("%s: energyDelta(%d, %d, %d) = %d, want %d", tt.name, tt.prev, tt.cur, tt.maxRange, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package power
+
+import (
+	"context"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// CollectRAPL is a no-op on platforms without powercap.
+func CollectRAPL(ctx context.Context) ([]protocol.Metric, error) {
+	return nil, nil
+}
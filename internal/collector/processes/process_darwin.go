@@ -30,10 +30,10 @@ type processRaw struct {
 	NumThreads uint32
 }
 
-// Collect gathers the process list on Darwin.
+// collect gathers the process list on Darwin.
 // This uses ps(1)'s %cpu and matches what Activity Monitor
 // displays.
-func Collect(ctx context.Context) ([]protocol.Metric, error) {
+func collect(ctx context.Context) ([]protocol.Metric, error) {
 	procs, totalMem, err := collectRaw(ctx)
 	if err != nil {
 		return nil, err
@@ -6,7 +6,6 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
-	"io"
 
 	"golang.org/x/sys/unix"
 )
@@ -18,9 +17,11 @@ import (
 // 0 StructSize
 // 4 Layout
 // 72 Pid
+// 76 Ppid
 // 265 Rssize
 // 308 Pctcpu
 // 328 Runtime
+// 336 Start
 // 388 Stat
 // 447 Comm
 // 596 NumThreads
@@ -29,7 +30,8 @@ type kinfoProc struct {
 	Layout     int32      // 4: ki_layout
 	_          [8]uint64  // 8: ki_args..ki_wchan (8 pointers)
 	Pid        int32      // 72: ki_pid
-	_          [5]int32   // 76: ki_ppid..ki_tsid
+	Ppid       int32      // 76: ki_ppid
+	_          [4]int32   // 80: ki_pgid..ki_tsid
 	_          [2]int16   // 96: ki_jobc, ki_spare_short1
 	_          uint32     // 100: ki_tdev_freebsd11
 	_          [16]uint32 // 104: siglist+sigmask+sigignore+sigcatch (4*sigset_t)
@@ -43,7 +45,8 @@ type kinfoProc struct {
 	Pctcpu     uint32     // 308: ki_pctcpu
 	_          [4]uint32  // 312: ki_estcpu..ki_cow
 	Runtime    uint64     // 328: ki_runtime
-	_          [4]int64   // 336: ki_start, ki_childtime (2*timeval)
+	Start      [2]int64   // 336: ki_start (timeval)
+	_          [2]int64   // 352: ki_childtime (timeval)
 	_          [2]int64   // 368: ki_flag, ki_kiflag
 	_          int32      // 384: ki_traceflag
 	Stat       int8       // 388: ki_stat
@@ -73,6 +76,17 @@ const kinfoSize = 600
 
 var clkTck = 1_000_000.0 // ki_runtime is in microseconds
 
+// fscale is FSCALE from sys/param.h: ki_pctcpu is a fixed-point fraction
+// of one CPU with FSHIFT (11) fractional bits.
+const fscale = 1 << 11
+
+// pctcpuToPercent converts ki_pctcpu to a percentage of one CPU. The
+// kernel value is a decaying average, so it seeds the first collection
+// while later ones use the exact runtime delta.
+func pctcpuToPercent(pctcpu uint32) float64 {
+	return float64(pctcpu) * 100.0 / fscale
+}
+
 func collectRaw() ([]processRaw, int64, error) {
 	// Get total memory for RSS percentage calc
 	physmem, err := unix.SysctlUint64("hw.physmem")
@@ -91,33 +105,54 @@ func collectRaw() ([]processRaw, int64, error) {
 		return nil, 0, fmt.Errorf("kern.proc.proc: %w", err)
 	}
 
-	reader := bytes.NewReader(buf)
-	var procs []processRaw
-
-	for reader.Len() >= kinfoSize {
-		var kp kinfoProc
-		if err := binary.Read(reader, binary.LittleEndian, &kp); err != nil {
-			break
-		}
-
-		// Skip remaining bytes
-		if skip := int64(kp.StructSize) - kinfoSize; skip > 0 {
-			reader.Seek(skip, io.SeekCurrent)
-		}
+	kps := decodeKinfoProcs(buf)
+	procs := make([]processRaw, 0, len(kps))
 
+	for _, kp := range kps {
 		procs = append(procs, processRaw{
 			PID:        int(kp.Pid),
+			PPID:       int(kp.Ppid),
+			StartTime:  kp.Start[0],
 			Name:       unix.ByteSliceToString(kp.Comm[:]),
 			State:      statToString(kp.Stat),
 			RSSBytes:   uint64(kp.Rssize) * uint64(pageSize),
 			TotalTicks: uint64(kp.Runtime),
 			NumThreads: uint32(kp.NumThreads),
+
+			KernelCPU:    pctcpuToPercent(kp.Pctcpu),
+			HasKernelCPU: true,
 		})
 	}
 
 	return procs, int64(physmem), nil
 }
 
+// decodeKinfoProcs decodes the kinfo_proc array returned by kern.proc.proc.
+// Each entry is advanced by its own ki_structsize, so the extra fields a
+// newer kernel appends past kinfoSize are skipped rather than misread as
+// the next entry. Decoding stops at a truncated entry or one whose size is
+// smaller than the layout we understand.
+func decodeKinfoProcs(buf []byte) []kinfoProc {
+	var kps []kinfoProc
+
+	for off := 0; len(buf)-off >= kinfoSize; {
+		size := int(int32(binary.LittleEndian.Uint32(buf[off:])))
+		if size < kinfoSize || size > len(buf)-off {
+			break
+		}
+
+		var kp kinfoProc
+		if err := binary.Read(bytes.NewReader(buf[off:off+kinfoSize]), binary.LittleEndian, &kp); err != nil {
+			break
+		}
+		kps = append(kps, kp)
+
+		off += size
+	}
+
+	return kps
+}
+
 func statToString(stat int8) string {
 	// sys/proc.h:
 	// SIDL = 1 (process being created)
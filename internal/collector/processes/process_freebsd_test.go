@@ -57,6 +57,10 @@ func TestKinfoProcFieldOffsets(t *testing.T) {
 	// Runtime at offset 328 (uint64) — microseconds
 	le.PutUint64(buf[328:], 5_000_000)
 
+	// Start at offset 336 (timeval) — seconds, then microseconds
+	le.PutUint64(buf[336:], 1_700_000_000)
+	le.PutUint64(buf[344:], 250_000)
+
 	// Stat at offset 388 (int8) — SRUN = 2
 	buf[388] = 2
 
@@ -77,6 +81,12 @@ func TestKinfoProcFieldOffsets(t *testing.T) {
 	if kp.Pid != 12345 {
 		t.Errorf("Pid = %d, want 12345", kp.Pid)
 	}
+	if kp.Ppid != 1 {
+		t.Errorf("Ppid = %d, want 1", kp.Ppid)
+	}
+	if kp.Start[0] != 1_700_000_000 || kp.Start[1] != 250_000 {
+		t.Errorf("Start = %v, want [1700000000 250000]", kp.Start)
+	}
 	if kp.Rssize != 8192 {
 		t.Errorf("Rssize = %d, want 8192", kp.Rssize)
 	}
@@ -171,6 +181,96 @@ func TestKinfoProcOversizedEntry(t *testing.T) {
 	}
 }
 
+func TestPctcpuToPercent(t *testing.T) {
+	tests := []struct {
+		raw  uint32
+		want float64
+	}{
+		{0, 0},
+		{1024, 50},  // FSCALE/2
+		{2048, 100}, // one full CPU
+		{4096, 200}, // two CPUs' worth of threads
+		{205, 10.009765625},
+	}
+
+	for _, tt := range tests {
+		if got := pctcpuToPercent(tt.raw); got != tt.want {
+			t.Errorf("pctcpuToPercent(%d) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+// TestDecodeKinfoProcs_MixedSizes verifies that a buffer mixing standard
+// and oversized entries decodes every PID without drifting out of
+// alignment.
+func TestDecodeKinfoProcs_MixedSizes(t *testing.T) {
+	sizes := []int{kinfoSize, 1088, kinfoSize, 1360, 1088, kinfoSize}
+	le := binary.LittleEndian
+
+	var buf []byte
+	for i, size := range sizes {
+		entry := make([]byte, size)
+		le.PutUint32(entry[0:], uint32(size))
+		le.PutUint32(entry[72:], uint32(500+i))
+		le.PutUint32(entry[596:], uint32(i+1))
+		// Garbage in the tail must not leak into the next entry
+		for j := kinfoSize; j < size; j++ {
+			entry[j] = 0xAB
+		}
+		buf = append(buf, entry...)
+	}
+
+	kps := decodeKinfoProcs(buf)
+	if len(kps) != len(sizes) {
+		t.Fatalf("decoded %d entries, want %d", len(kps), len(sizes))
+	}
+	for i, kp := range kps {
+		if kp.Pid != int32(500+i) {
+			t.Errorf("entry %d: Pid = %d, want %d", i, kp.Pid, 500+i)
+		}
+		if kp.NumThreads != int32(i+1) {
+			t.Errorf("entry %d: NumThreads = %d, want %d", i, kp.NumThreads, i+1)
+		}
+	}
+}
+
+// TestDecodeKinfoProcs_StopsOnBadSize verifies decoding halts at an entry
+// that is truncated or reports a size smaller than kinfoSize.
+func TestDecodeKinfoProcs_StopsOnBadSize(t *testing.T) {
+	le := binary.LittleEndian
+
+	good := make([]byte, kinfoSize)
+	le.PutUint32(good[0:], uint32(kinfoSize))
+	le.PutUint32(good[72:], 1)
+
+	tests := []struct {
+		name string
+		tail []byte
+	}{
+		{"undersized", func() []byte {
+			b := make([]byte, kinfoSize)
+			le.PutUint32(b[0:], 100)
+			return b
+		}()},
+		{"truncated", func() []byte {
+			b := make([]byte, kinfoSize)
+			le.PutUint32(b[0:], 1088)
+			return b
+		}()},
+		{"short tail", make([]byte, 10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := append(append([]byte{}, good...), tt.tail...)
+			kps := decodeKinfoProcs(buf)
+			if len(kps) != 1 || kps[0].Pid != 1 {
+				t.Errorf("got %d entries, want only the first", len(kps))
+			}
+		})
+	}
+}
+
 // TestKinfoProcCommMaxLength verifies that a full-length command name
 // (19 chars + null) is correctly extracted.
 func TestKinfoProcCommMaxLength(t *testing.T) {
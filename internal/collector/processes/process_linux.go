@@ -3,6 +3,7 @@
 package processes
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"os"
@@ -31,6 +32,7 @@ type pidStatRaw struct {
 	UTime      uint64
 	STime      uint64
 	RSSPages   uint64
+	StartTicks uint64 // clock ticks after boot
 	TotalTicks uint64
 	NumThreads uint32
 }
@@ -47,6 +49,13 @@ func collectRaw() ([]processRaw, int64, error) {
 		return nil, 0, err
 	}
 
+	// Start times are relative to boot; without it they are left unset
+	var bootTime int64
+	if f, err := os.Open("/proc/stat"); err == nil {
+		bootTime, _ = parseBootTimeFrom(f)
+		f.Close()
+	}
+
 	pageSize := uint64(os.Getpagesize())
 	var procs []processRaw
 
@@ -67,14 +76,28 @@ func collectRaw() ([]processRaw, int64, error) {
 			continue
 		}
 
-		procs = append(procs, processRaw{
+		raw := processRaw{
 			PID:        pid,
+			PPID:       stat.PPID,
 			Name:       stat.Name,
 			State:      stat.State,
 			RSSBytes:   stat.RSSPages * pageSize,
 			TotalTicks: stat.TotalTicks,
 			NumThreads: stat.NumThreads,
-		})
+		}
+
+		if bootTime > 0 {
+			raw.StartTime = bootTime + int64(float64(stat.StartTicks)/clkTck)
+		}
+
+		// io is only readable for our own processes unless we're root
+		if f, err := os.Open(filepath.Join("/proc", entry.Name(), "io")); err == nil {
+			raw.ReadBytes, raw.WriteBytes, err = parsePidIOFrom(f)
+			raw.HasIO = err == nil
+			f.Close()
+		}
+
+		procs = append(procs, raw)
 	}
 
 	return procs, int64(totalMem), nil
@@ -110,12 +133,14 @@ func parsePidStatFrom(r io.Reader) (*pidStatRaw, error) {
 	// utime (14) -> 11
 	// stime (15) -> 12
 	// num_threads (20) -> 17
+	// starttime (22) -> 19
 	// rss (24) -> 21
 
 	ppid, _ := strconv.Atoi(fields[1])
 	utime := parse(11)
 	stime := parse(12)
 	numThreads := parse(17)
+	startTicks := parse(19)
 	rss := parse(21)
 
 	return &pidStatRaw{
@@ -125,7 +150,53 @@ func parsePidStatFrom(r io.Reader) (*pidStatRaw, error) {
 		UTime:      utime,
 		STime:      stime,
 		RSSPages:   rss,
+		StartTicks: startTicks,
 		TotalTicks: utime + stime,
 		NumThreads: uint32(numThreads),
 	}, nil
 }
+
+// parsePidIOFrom returns read_bytes and write_bytes from /proc/[pid]/io.
+func parsePidIOFrom(r io.Reader) (read, write uint64, err error) {
+	var haveRead, haveWrite bool
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, val, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "read_bytes":
+			read, err = strconv.ParseUint(strings.TrimSpace(val), 10, 64)
+			haveRead = err == nil
+		case "write_bytes":
+			write, err = strconv.ParseUint(strings.TrimSpace(val), 10, 64)
+			haveWrite = err == nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+	if !haveRead || !haveWrite {
+		return 0, 0, fmt.Errorf("missing read_bytes or write_bytes")
+	}
+	return read, write, nil
+}
+
+// parseBootTimeFrom returns the btime line of /proc/stat as unix seconds.
+func parseBootTimeFrom(r io.Reader) (int64, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if val, ok := strings.CutPrefix(scanner.Text(), "btime "); ok {
+			return strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("btime not found")
+}
@@ -4,6 +4,7 @@ package processes
 
 import (
 	"context"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -259,6 +260,136 @@ func TestProcessStateCleanup(t *testing.T) {
 	}
 }
 
+func TestParsePidStatFrom_PPidAndStartTime(t *testing.T) {
+	input := "4242 (postgres) S 1187 4242 4242 0 -1 4194560 1500 0 0 0 30 12 0 0 20 0 1 0 98765 221908992 3456 18446744073709551615 0 0 0 0 0 0 0 0 0 0 0 0 17 2 0 0 0 0 0"
+	stat, err := parsePidStatFrom(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stat.PPID != 1187 {
+		t.Errorf("PPID: got %d, want 1187", stat.PPID)
+	}
+	if stat.StartTicks != 98765 {
+		t.Errorf("StartTicks: got %d, want 98765", stat.StartTicks)
+	}
+	if stat.RSSPages != 3456 {
+		t.Errorf("RSSPages: got %d, want 3456", stat.RSSPages)
+	}
+}
+
+func TestParseBootTimeFrom(t *testing.T) {
+	input := `cpu  2255 34 2290 22625563 6290 127 456 0 0 0
+cpu0 1132 34 1441 11311718 3675 127 438 0 0 0
+intr 114930548 113199788 3 0 5 263 0 4 [... lots more numbers ...]
+ctxt 1990473
+btime 1062191376
+processes 2915
+`
+	got, err := parseBootTimeFrom(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 1062191376 {
+		t.Errorf("got %d, want 1062191376", got)
+	}
+
+	if _, err := parseBootTimeFrom(strings.NewReader("cpu 1 2 3\n")); err == nil {
+		t.Error("expected error when btime is missing")
+	}
+}
+
+func TestCollect_PPidAndStartTime(t *testing.T) {
+	metrics, err := Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	self := os.Getpid()
+	for _, p := range metrics[0].(protocol.ProcessListMetric).Processes {
+		if p.Pid != self {
+			continue
+		}
+		if p.PPid != os.Getppid() {
+			t.Errorf("PPid: got %d, want %d", p.PPid, os.Getppid())
+		}
+		if now := time.Now().Unix(); p.StartTime <= 0 || p.StartTime > now+1 {
+			t.Errorf("StartTime %d not plausible (now %d)", p.StartTime, now)
+		}
+		return
+	}
+	t.Error("own process not found")
+}
+
+func TestParsePidIOFrom(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantRead  uint64
+		wantWrite uint64
+		wantErr   bool
+	}{
+		{
+			name: "Standard",
+			input: `rchar: 323934931
+wchar: 323929600
+syscr: 632687
+syscw: 632675
+read_bytes: 4096
+write_bytes: 323932160
+cancelled_write_bytes: 0
+`,
+			wantRead:  4096,
+			wantWrite: 323932160,
+		},
+		{
+			name:    "Missing write_bytes",
+			input:   "rchar: 1\nread_bytes: 4096\n",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid value",
+			input:   "read_bytes: abc\nwrite_bytes: 0\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			read, write, err := parsePidIOFrom(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if read != tt.wantRead || write != tt.wantWrite {
+				t.Errorf("got read %d write %d, want read %d write %d", read, write, tt.wantRead, tt.wantWrite)
+			}
+		})
+	}
+}
+
+func TestIODelta(t *testing.T) {
+	first := processRaw{PID: 42, HasIO: true, ReadBytes: 1000, WriteBytes: 5000}
+	prev := processState{hasIO: first.HasIO, lastRead: first.ReadBytes, lastWrite: first.WriteBytes}
+
+	second := processRaw{PID: 42, HasIO: true, ReadBytes: 4096, WriteBytes: 5000}
+	if read, write := ioDelta(prev, second); read != 3096 || write != 0 {
+		t.Errorf("delta: got read %d write %d, want 3096 and 0", read, write)
+	}
+
+	// PID reuse resets the counters
+	reused := processRaw{PID: 42, HasIO: true, ReadBytes: 10, WriteBytes: 20}
+	if read, write := ioDelta(prev, reused); read != 0 || write != 0 {
+		t.Errorf("reset: got read %d write %d, want 0 and 0", read, write)
+	}
+
+	// Unreadable io file on either side yields no delta
+	if read, write := ioDelta(prev, processRaw{PID: 42}); read != 0 || write != 0 {
+		t.Errorf("unreadable: got read %d write %d, want 0 and 0", read, write)
+	}
+	if read, write := ioDelta(processState{}, second); read != 0 || write != 0 {
+		t.Errorf("no previous IO: got read %d write %d, want 0 and 0", read, write)
+	}
+}
+
 func BenchmarkParsePidStatFrom(b *testing.B) {
 	input := "12345 (chrome) S 1234 12345 12345 0 -1 4194304 12345 0 123 0 500 200 0 0 20 0 50 0 123456 987654321 25000 18446744073709551615 0 0 0 0 0 0 0 0 0 0 0 0 17 3 0 0 0 0 0"
 	b.ReportAllocs()
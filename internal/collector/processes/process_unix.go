@@ -9,26 +9,40 @@ import (
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
-// processState stores the last CPU ticks for a PID.
+// processState stores the last CPU ticks and IO counters for a PID.
 type processState struct {
 	lastTicks uint64
 	lastTime  time.Time
+	hasIO     bool
+	lastRead  uint64
+	lastWrite uint64
 }
 
 // processRaw is the intermediate representation returned
 // by collectRaw on each platform.
 type processRaw struct {
 	PID        int
+	PPID       int
+	StartTime  int64 // unix seconds
 	Name       string
 	State      string
 	RSSBytes   uint64
 	TotalTicks uint64 // cumulative CPU ticks (utime + stime)
 	NumThreads uint32
+	HasIO      bool   // false if the IO counters couldn't be read
+	ReadBytes  uint64 // cumulative bytes read from storage
+	WriteBytes uint64 // cumulative bytes written to storage
+
+	// KernelCPU is the kernel's own CPU percent estimate, used until a
+	// second sample allows a tick delta. HasKernelCPU is false on
+	// platforms that don't report one.
+	KernelCPU    float64
+	HasKernelCPU bool
 }
 
 var lastProcessStates = make(map[int]processState)
 
-func Collect(ctx context.Context) ([]protocol.Metric, error) {
+func collect(ctx context.Context) ([]protocol.Metric, error) {
 	procs, totalMem, err := collectRaw()
 	if err != nil {
 		return nil, err
@@ -51,21 +65,35 @@ func Collect(ctx context.Context) ([]protocol.Metric, error) {
 			if deltaTime > 0 {
 				cpuPercent = ((deltaTicks / clkTck) / deltaTime) * 100.0
 			}
+		} else if p.HasKernelCPU {
+			cpuPercent = p.KernelCPU
+		}
+
+		var readBytes, writeBytes uint64
+		if prev, ok := lastProcessStates[p.PID]; ok {
+			readBytes, writeBytes = ioDelta(prev, p)
 		}
 
 		currentStates[p.PID] = processState{
 			lastTicks: p.TotalTicks,
 			lastTime:  now,
+			hasIO:     p.HasIO,
+			lastRead:  p.ReadBytes,
+			lastWrite: p.WriteBytes,
 		}
 
 		results = append(results, protocol.ProcessMetric{
 			Pid:          p.PID,
+			PPid:         p.PPID,
+			StartTime:    p.StartTime,
 			Name:         p.Name,
 			Status:       normalizeProcState(p.State, cpuPercent),
 			MemRSS:       p.RSSBytes,
 			MemPercent:   memPercent,
 			CPUPercent:   cpuPercent,
 			ThreadsTotal: p.NumThreads,
+			ReadBytes:    readBytes,
+			WriteBytes:   writeBytes,
 		})
 	}
 
@@ -76,6 +104,21 @@ func Collect(ctx context.Context) ([]protocol.Metric, error) {
 	}, nil
 }
 
+// ioDelta returns the bytes read and written since prev. It is zero when
+// either sample lacks IO counters or a counter went backwards (PID reuse).
+func ioDelta(prev processState, p processRaw) (read, write uint64) {
+	if !prev.hasIO || !p.HasIO {
+		return 0, 0
+	}
+	if p.ReadBytes >= prev.lastRead {
+		read = p.ReadBytes - prev.lastRead
+	}
+	if p.WriteBytes >= prev.lastWrite {
+		write = p.WriteBytes - prev.lastWrite
+	}
+	return read, write
+}
+
 func normalizeProcState(state string, cpuPercent float64) protocol.ProcStatus {
 	if state == "" {
 		return protocol.ProcOther
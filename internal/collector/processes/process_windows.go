@@ -36,7 +36,7 @@ type schedSummary struct {
 	ThreadsWaiting  uint32
 }
 
-func Collect(ctx context.Context) ([]protocol.Metric, error) {
+func collect(ctx context.Context) ([]protocol.Metric, error) {
 	// Grab scheduler summaries (thread counts + status)
 	sched, err := getProcessSchedulerSummary()
 	if err != nil {
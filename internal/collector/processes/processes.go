@@ -0,0 +1,142 @@
+package processes
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/nhdewitt/spectra/internal/collector"
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// collectMu serializes collect, whose CPU and IO rates are computed
+// against per-PID state left by the previous call.
+var collectMu sync.Mutex
+
+// Collect gathers the current process list. It is safe to call from the
+// periodic collector and on-demand commands at the same time; each call
+// measures rates since whichever call came before it.
+func Collect(ctx context.Context) ([]protocol.Metric, error) {
+	collectMu.Lock()
+	defer collectMu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return collect(ctx)
+}
+
+const (
+	defaultTopProcessesLimit = 20
+	maxTopProcessesLimit     = 500
+)
+
+// TopProcesses returns the current process list sorted by req.SortBy,
+// highest first, and cut to req.Limit entries.
+func TopProcesses(ctx context.Context, req protocol.TopProcessesRequest) (*protocol.ProcessListMetric, error) {
+	if req.SortBy != "" && req.SortBy != protocol.SortByCPU && req.SortBy != protocol.SortByMemory {
+		return nil, fmt.Errorf("invalid sort_by %q (want cpu or memory)", req.SortBy)
+	}
+
+	metrics, err := Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, m := range metrics {
+		if list, ok := m.(protocol.ProcessListMetric); ok {
+			list.Processes = sortProcesses(list.Processes, req.SortBy, req.Limit)
+			return &list, nil
+		}
+	}
+	return &protocol.ProcessListMetric{Processes: []protocol.ProcessMetric{}}, nil
+}
+
+// sortProcesses orders procs by CPU (the default) or resident memory,
+// highest first with ties broken by PID, and keeps at most limit entries.
+func sortProcesses(procs []protocol.ProcessMetric, sortBy string, limit int) []protocol.ProcessMetric {
+	if limit <= 0 {
+		limit = defaultTopProcessesLimit
+	}
+	limit = min(limit, maxTopProcessesLimit)
+
+	out := slices.Clone(procs)
+	slices.SortFunc(out, func(a, b protocol.ProcessMetric) int {
+		var c int
+		if sortBy == protocol.SortByMemory {
+			c = cmp.Compare(b.MemRSS, a.MemRSS)
+		} else {
+			c = cmp.Compare(b.CPUPercent, a.CPUPercent)
+		}
+		if c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Pid, b.Pid)
+	})
+
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// MakeCollector returns Collect limited to the topN processes by CPU plus
+// the topN by memory. A topN of zero or less keeps every process.
+func MakeCollector(topN int) collector.CollectFunc {
+	if topN <= 0 {
+		return Collect
+	}
+	return func(ctx context.Context) ([]protocol.Metric, error) {
+		metrics, err := Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for i, m := range metrics {
+			if list, ok := m.(protocol.ProcessListMetric); ok {
+				list.Processes = topProcesses(list.Processes, topN)
+				metrics[i] = list
+			}
+		}
+		return metrics, nil
+	}
+}
+
+// topProcesses returns the union of the n highest processes by CPU and the
+// n highest by resident memory, so at most 2n, in their original order.
+func topProcesses(procs []protocol.ProcessMetric, n int) []protocol.ProcessMetric {
+	if n <= 0 || len(procs) <= n {
+		return procs
+	}
+
+	idx := make([]int, len(procs))
+	for i := range idx {
+		idx[i] = i
+	}
+
+	keep := make([]bool, len(procs))
+	slices.SortStableFunc(idx, func(a, b int) int {
+		return cmp.Compare(procs[b].CPUPercent, procs[a].CPUPercent)
+	})
+	for _, i := range idx[:n] {
+		keep[i] = true
+	}
+	slices.SortStableFunc(idx, func(a, b int) int {
+		return cmp.Compare(procs[b].MemRSS, procs[a].MemRSS)
+	})
+	for _, i := range idx[:n] {
+		keep[i] = true
+	}
+
+	out := make([]protocol.ProcessMetric, 0, 2*n)
+	for i, p := range procs {
+		if keep[i] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
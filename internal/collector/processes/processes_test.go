@@ -0,0 +1,117 @@
+package processes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestTopProcesses(t *testing.T) {
+	// CPU rises with pid while memory falls, so the top CPU and top
+	// memory sets are disjoint
+	var procs []protocol.ProcessMetric
+	for i := range 100 {
+		procs = append(procs, protocol.ProcessMetric{
+			Pid:        i,
+			Name:       fmt.Sprintf("p%d", i),
+			CPUPercent: float64(i),
+			MemRSS:     uint64(100-i) << 20,
+		})
+	}
+
+	got := topProcesses(procs, 5)
+	if len(got) != 10 {
+		t.Fatalf("got %d processes, want 10", len(got))
+	}
+
+	want := []int{0, 1, 2, 3, 4, 95, 96, 97, 98, 99}
+	for i, p := range got {
+		if p.Pid != want[i] {
+			t.Errorf("got[%d].Pid = %d, want %d", i, p.Pid, want[i])
+		}
+	}
+}
+
+func TestTopProcesses_Overlap(t *testing.T) {
+	// One process leads both rankings, so the union is smaller than 2n
+	procs := []protocol.ProcessMetric{
+		{Pid: 1, CPUPercent: 90, MemRSS: 900},
+		{Pid: 2, CPUPercent: 50, MemRSS: 10},
+		{Pid: 3, CPUPercent: 1, MemRSS: 500},
+		{Pid: 4, CPUPercent: 0, MemRSS: 0},
+	}
+
+	got := topProcesses(procs, 2)
+	if len(got) != 3 {
+		t.Fatalf("got %d processes, want 3: %+v", len(got), got)
+	}
+	for i, pid := range []int{1, 2, 3} {
+		if got[i].Pid != pid {
+			t.Errorf("got[%d].Pid = %d, want %d", i, got[i].Pid, pid)
+		}
+	}
+}
+
+func TestTopProcesses_All(t *testing.T) {
+	procs := []protocol.ProcessMetric{{Pid: 1}, {Pid: 2}, {Pid: 3}}
+
+	for _, n := range []int{0, -1, 3, 10} {
+		if got := topProcesses(procs, n); len(got) != len(procs) {
+			t.Errorf("n=%d: got %d processes, want all %d", n, len(got), len(procs))
+		}
+	}
+}
+
+func TestSortProcesses(t *testing.T) {
+	procs := []protocol.ProcessMetric{
+		{Pid: 1, CPUPercent: 5, MemRSS: 300},
+		{Pid: 2, CPUPercent: 50, MemRSS: 100},
+		{Pid: 3, CPUPercent: 20, MemRSS: 900},
+		{Pid: 4, CPUPercent: 50, MemRSS: 200},
+	}
+
+	tests := []struct {
+		name   string
+		sortBy string
+		limit  int
+		want   []int
+	}{
+		{"CPU default", "", 3, []int{2, 4, 3}},
+		{"Memory", protocol.SortByMemory, 2, []int{3, 1}},
+		{"Limit above length", protocol.SortByCPU, 10, []int{2, 4, 3, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sortProcesses(procs, tt.sortBy, tt.limit)
+			var pids []int
+			for _, p := range got {
+				pids = append(pids, p.Pid)
+			}
+			if fmt.Sprint(pids) != fmt.Sprint(tt.want) {
+				t.Errorf("got %v, want %v", pids, tt.want)
+			}
+		})
+	}
+
+	if procs[0].Pid != 1 {
+		t.Error("input slice was reordered")
+	}
+}
+
+func TestTopProcesses_InvalidSort(t *testing.T) {
+	if _, err := TopProcesses(context.Background(), protocol.TopProcessesRequest{SortBy: "name"}); err == nil {
+		t.Error("expected error for unknown sort order")
+	}
+}
+
+func TestTopProcesses_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := TopProcesses(ctx, protocol.TopProcessesRequest{}); err == nil {
+		t.Error("expected error for cancelled context")
+	}
+}
@@ -0,0 +1,65 @@
+//go:build linux
+
+package processes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// CollectProcessSummary tallies processes by state and counts their
+// threads, without shipping the per-process list.
+func CollectProcessSummary(ctx context.Context) ([]protocol.Metric, error) {
+	summary, err := parseProcessSummaryFrom("/proc")
+	if err != nil {
+		return nil, err
+	}
+	return []protocol.Metric{summary}, nil
+}
+
+// parseProcessSummaryFrom reads every <pid>/stat under a procfs root.
+// Processes that exit mid-walk are skipped.
+func parseProcessSummaryFrom(root string) (protocol.ProcessSummaryMetric, error) {
+	var m protocol.ProcessSummaryMetric
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return m, err
+	}
+
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+
+		f, err := os.Open(filepath.Join(root, entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		stat, err := parsePidStatFrom(f)
+		f.Close()
+		if err != nil || stat.State == "" {
+			continue
+		}
+
+		m.Total++
+		m.Threads += int(stat.NumThreads)
+
+		switch stat.State[0] {
+		case 'R':
+			m.Running++
+		case 'S', 'D', 'I', 'W':
+			m.Sleeping++
+		case 'T', 't':
+			m.Stopped++
+		case 'Z':
+			m.Zombie++
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,66 @@
+//go:build linux
+
+package processes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func writeFakeStat(t *testing.T, root string, pid int, name, state string, threads int) {
+	t.Helper()
+	dir := filepath.Join(root, fmt.Sprint(pid))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	line := fmt.Sprintf("%d (%s) %s 1 %d 0 0 0 0 0 0 0 0 10 20 0 0 20 0 %d 0 500 0 100 0 0", pid, name, state, pid, threads)
+	if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseProcessSummaryFrom(t *testing.T) {
+	root := t.TempDir()
+	writeFakeStat(t, root, 1, "systemd", "S", 1)
+	writeFakeStat(t, root, 2, "kthreadd", "I", 1)
+	writeFakeStat(t, root, 100, "stress", "R", 4)
+	writeFakeStat(t, root, 101, "dd", "D", 1)
+	writeFakeStat(t, root, 200, "defunct child", "Z", 1)
+	writeFakeStat(t, root, 201, "defunct", "Z", 1)
+	writeFakeStat(t, root, 300, "vim", "T", 2)
+
+	// Non-PID entries and unreadable stats are ignored
+	if err := os.MkdirAll(filepath.Join(root, "sys"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "400"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parseProcessSummaryFrom(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := protocol.ProcessSummaryMetric{
+		Total:    7,
+		Running:  1,
+		Sleeping: 3,
+		Stopped:  1,
+		Zombie:   2,
+		Threads:  11,
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseProcessSummaryFrom_MissingRoot(t *testing.T) {
+	if _, err := parseProcessSummaryFrom(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected error for missing root")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package processes
+
+import (
+	"context"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// CollectProcessSummary is not yet implemented outside Linux.
+func CollectProcessSummary(ctx context.Context) ([]protocol.Metric, error) {
+	return nil, nil
+}
@@ -0,0 +1,56 @@
+package collector
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Registration describes a collector added through Register.
+type Registration struct {
+	Name     string
+	Interval time.Duration
+	Fn       CollectFunc
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []Registration
+)
+
+// Register makes a collector available to the agent under name, to be run
+// every interval. It is intended to be called from an init function, so
+// downstream builds can add collectors without editing the agent.
+// Register panics if name is empty or already registered, if interval is
+// not positive, or if fn is nil.
+func Register(name string, interval time.Duration, fn CollectFunc) {
+	if name == "" {
+		panic("collector: Register with empty name")
+	}
+	if interval <= 0 {
+		panic(fmt.Sprintf("collector: Register %q with non-positive interval %v", name, interval))
+	}
+	if fn == nil {
+		panic(fmt.Sprintf("collector: Register %q with nil CollectFunc", name))
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, r := range registry {
+		if r.Name == name {
+			panic(fmt.Sprintf("collector: Register called twice for %q", name))
+		}
+	}
+	registry = append(registry, Registration{Name: name, Interval: interval, Fn: fn})
+}
+
+// Registered returns a copy of all registered collectors in registration order.
+func Registered() []Registration {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	out := make([]Registration, len(registry))
+	copy(out, registry)
+	return out
+}
@@ -0,0 +1,109 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// resetRegistry restores the global registry after a test.
+func resetRegistry(t *testing.T) {
+	t.Helper()
+	registryMu.Lock()
+	saved := append([]Registration(nil), registry...)
+	registryMu.Unlock()
+
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry = saved
+		registryMu.Unlock()
+	})
+}
+
+func TestRegister_CustomCollectorRuns(t *testing.T) {
+	resetRegistry(t)
+
+	Register("custom_test", 10*time.Millisecond, func(ctx context.Context) ([]protocol.Metric, error) {
+		return []protocol.Metric{mockMetric{Value: 7}}, nil
+	})
+
+	var reg *Registration
+	for _, r := range Registered() {
+		if r.Name == "custom_test" {
+			reg = &r
+			break
+		}
+	}
+	if reg == nil {
+		t.Fatal("custom_test not found in Registered()")
+	}
+	if reg.Interval != 10*time.Millisecond {
+		t.Errorf("Interval: got %v, want 10ms", reg.Interval)
+	}
+
+	h := newHarness(5)
+	defer h.cancel()
+	go h.c.RunNamed(h.ctx, reg.Name, reg.Interval, reg.Fn)
+
+	select {
+	case env := <-h.out:
+		if m, ok := env.Data.(mockMetric); !ok || m.Value != 7 {
+			t.Errorf("expected mockMetric{7}, got %v", env.Data)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("registered collector did not run")
+	}
+}
+
+func TestRegistered_ReturnsCopy(t *testing.T) {
+	resetRegistry(t)
+
+	Register("copy_test", time.Second, func(ctx context.Context) ([]protocol.Metric, error) {
+		return nil, nil
+	})
+
+	regs := Registered()
+	for i := range regs {
+		regs[i].Name = "mutated"
+	}
+
+	for _, r := range Registered() {
+		if r.Name == "mutated" {
+			t.Fatal("mutating the returned slice changed the registry")
+		}
+	}
+}
+
+func TestRegister_Panics(t *testing.T) {
+	noop := func(ctx context.Context) ([]protocol.Metric, error) { return nil, nil }
+
+	tests := []struct {
+		name     string
+		regName  string
+		interval time.Duration
+		fn       CollectFunc
+	}{
+		{"empty name", "", time.Second, noop},
+		{"zero interval", "x", 0, noop},
+		{"nil func", "x", time.Second, nil},
+		{"duplicate", "dup_test", time.Second, noop},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetRegistry(t)
+			if tt.regName == "dup_test" {
+				Register("dup_test", time.Second, noop)
+			}
+
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			Register(tt.regName, tt.interval, tt.fn)
+		})
+	}
+}
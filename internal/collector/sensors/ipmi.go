@@ -0,0 +1,114 @@
+package sensors
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// ipmiStatus maps ipmitool's sensor status codes to SensorMetric.Status.
+var ipmiStatus = map[string]string{
+	"ok": "ok",
+	"nc": "warning",  // non-critical
+	"cr": "critical", // critical
+	"nr": "critical", // non-recoverable
+}
+
+// ipmiUnits maps ipmitool reading units to SensorMetric kind and unit.
+var ipmiUnits = map[string][2]string{
+	"RPM":       {"fan", "RPM"},
+	"Volts":     {"voltage", "V"},
+	"degrees C": {"temperature", "C"},
+	"Watts":     {"power", "W"},
+	"Amps":      {"current", "A"},
+	"percent":   {"other", "%"},
+}
+
+// CollectIPMI reports fan, voltage, temperature and power readings from
+// the BMC via `ipmitool sdr elist`, falling back to lm-sensors when
+// ipmitool is missing or the BMC can't be reached. It is a no-op when
+// neither source is available.
+func CollectIPMI(ctx context.Context) ([]protocol.Metric, error) {
+	if path, err := exec.LookPath("ipmitool"); err == nil {
+		out, err := exec.CommandContext(ctx, path, "sdr", "elist").Output()
+		if err == nil {
+			sensors, err := parseIPMISdrFrom(bytes.NewReader(out))
+			if err != nil {
+				return nil, err
+			}
+			return toMetrics(sensors), nil
+		}
+	}
+
+	path, err := exec.LookPath("sensors")
+	if err != nil {
+		return nil, nil
+	}
+	sensors, err := readLMSensors(ctx, path)
+	if err != nil {
+		return nil, nil
+	}
+	return toMetrics(sensors), nil
+}
+
+// parseIPMISdrFrom parses `ipmitool sdr elist` output:
+//
+//	Fan1A RPM        | 30h | ok  |  7.1 | 4200 RPM
+//
+// Discrete sensors ("Presence detected") and sensors without a reading
+// ("ns") are skipped.
+func parseIPMISdrFrom(r io.Reader) ([]protocol.SensorMetric, error) {
+	var sensors []protocol.SensorMetric
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		cols := strings.Split(scanner.Text(), "|")
+		if len(cols) < 5 {
+			continue
+		}
+
+		name := strings.TrimSpace(cols[0])
+		status, ok := ipmiStatus[strings.TrimSpace(cols[2])]
+		if name == "" || !ok {
+			continue
+		}
+
+		valStr, unitStr, ok := strings.Cut(strings.TrimSpace(cols[4]), " ")
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(valStr, 64)
+		if err != nil {
+			continue
+		}
+		unit, ok := ipmiUnits[strings.TrimSpace(unitStr)]
+		if !ok {
+			continue
+		}
+
+		sensors = append(sensors, protocol.SensorMetric{
+			Source: "ipmi",
+			Name:   name,
+			Kind:   unit[0],
+			Value:  value,
+			Unit:   unit[1],
+			Status: status,
+		})
+	}
+
+	return sensors, scanner.Err()
+}
+
+func toMetrics(sensors []protocol.SensorMetric) []protocol.Metric {
+	metrics := make([]protocol.Metric, len(sensors))
+	for i, s := range sensors {
+		metrics[i] = s
+	}
+	return metrics
+}
@@ -0,0 +1,51 @@
+package sensors
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const ipmiSdrSample = `Fan1A RPM        | 30h | ok  |  7.1 | 4200 RPM
+Fan1B RPM        | 31h | cr  |  7.1 | 0 RPM
+Inlet Temp       | 04h | ok  |  7.1 | 23 degrees C
+Exhaust Temp     | 01h | nc  |  7.1 | 71 degrees C
+Temp             | 0Eh | ns  |  3.1 | No Reading
+Current 1        | 6Ah | ok  | 10.1 | 0.60 Amps
+Voltage 1        | 6Ch | ok  | 10.1 | 230 Volts
+Pwr Consumption  | 77h | ok  |  7.1 | 126 Watts
+PS1 Status       | 63h | ok  | 10.1 | Presence detected
+Intrusion        | 73h | ok  |  7.1 |
+`
+
+func TestParseIPMISdrFrom(t *testing.T) {
+	got, err := parseIPMISdrFrom(strings.NewReader(ipmiSdrSample))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []protocol.SensorMetric{
+		{Source: "ipmi", Name: "Fan1A RPM", Kind: "fan", Value: 4200, Unit: "RPM", Status: "ok"},
+		{Source: "ipmi", Name: "Fan1B RPM", Kind: "fan", Value: 0, Unit: "RPM", Status: "critical"},
+		{Source: "ipmi", Name: "Inlet Temp", Kind: "temperature", Value: 23, Unit: "C", Status: "ok"},
+		{Source: "ipmi", Name: "Exhaust Temp", Kind: "temperature", Value: 71, Unit: "C", Status: "warning"},
+		{Source: "ipmi", Name: "Current 1", Kind: "current", Value: 0.6, Unit: "A", Status: "ok"},
+		{Source: "ipmi", Name: "Voltage 1", Kind: "voltage", Value: 230, Unit: "V", Status: "ok"},
+		{Source: "ipmi", Name: "Pwr Consumption", Kind: "power", Value: 126, Unit: "W", Status: "ok"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseIPMISdrFrom_Empty(t *testing.T) {
+	got, err := parseIPMISdrFrom(strings.NewReader("Could not open device at /dev/ipmi0\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no sensors, got %+v", got)
+	}
+}
@@ -0,0 +1,119 @@
+package sensors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"maps"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// CollectSensors reports fan speeds, voltages, temperatures and other
+// readings from lm-sensors (`sensors -j`). Temperatures here complement
+// the thermal zones read by the temperature collector, covering
+// motherboard and drive sensors. It is a no-op when sensors is missing.
+func CollectSensors(ctx context.Context) ([]protocol.Metric, error) {
+	path, err := exec.LookPath("sensors")
+	if err != nil {
+		return nil, nil
+	}
+	sensors, err := readLMSensors(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return toMetrics(sensors), nil
+}
+
+// readLMSensors runs `sensors -j` at path. sensors exits non-zero when a
+// chip fails to read but still prints the others, so any output is
+// parsed; the exit error is returned only when there is none.
+func readLMSensors(ctx context.Context, path string) ([]protocol.SensorMetric, error) {
+	out, err := exec.CommandContext(ctx, path, "-j").Output()
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil, err
+	}
+	return parseSensorsJSON(out)
+}
+
+// lmSensorKinds maps lm-sensors subfeature prefixes to SensorMetric kind
+// and unit.
+var lmSensorKinds = map[string][2]string{
+	"fan":   {"fan", "RPM"},
+	"in":    {"voltage", "V"},
+	"temp":  {"temperature", "C"},
+	"power": {"power", "W"},
+	"curr":  {"current", "A"},
+}
+
+// parseSensorsJSON parses `sensors -j` output, which nests chips, then
+// features, then subfeatures ("fan1_input", "in0_alarm"). Each feature
+// with an _input becomes one SensorMetric, in chip and feature order.
+func parseSensorsJSON(data []byte) ([]protocol.SensorMetric, error) {
+	var chips map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &chips); err != nil {
+		return nil, err
+	}
+
+	var sensors []protocol.SensorMetric
+	for _, chip := range slices.Sorted(maps.Keys(chips)) {
+		features := chips[chip]
+		for _, feature := range slices.Sorted(maps.Keys(features)) {
+			// "Adapter" is a string; every feature is an object
+			var sub map[string]float64
+			if err := json.Unmarshal(features[feature], &sub); err != nil {
+				continue
+			}
+			if s, ok := lmSensorFrom(chip, feature, sub); ok {
+				sensors = append(sensors, s)
+			}
+		}
+	}
+	return sensors, nil
+}
+
+// lmSensorFrom builds a SensorMetric from one feature's subfeatures.
+func lmSensorFrom(chip, feature string, sub map[string]float64) (protocol.SensorMetric, bool) {
+	for key, value := range sub {
+		prefix, ok := strings.CutSuffix(key, "_input")
+		if !ok {
+			continue
+		}
+		kind, ok := lmSensorKinds[strings.TrimRight(prefix, "0123456789")]
+		if !ok {
+			return protocol.SensorMetric{}, false
+		}
+
+		status := "ok"
+		switch {
+		case sub[prefix+"_alarm"] > 0, sub[prefix+"_crit_alarm"] > 0:
+			status = "critical"
+		case hasLimit(sub, prefix+"_crit") && value >= sub[prefix+"_crit"]:
+			status = "critical"
+		case hasLimit(sub, prefix+"_max") && value >= sub[prefix+"_max"],
+			hasLimit(sub, prefix+"_min") && value < sub[prefix+"_min"]:
+			status = "warning"
+		}
+
+		return protocol.SensorMetric{
+			Source: "lm-sensors",
+			Chip:   chip,
+			Name:   feature,
+			Kind:   kind[0],
+			Value:  value,
+			Unit:   kind[1],
+			Status: status,
+		}, true
+	}
+	return protocol.SensorMetric{}, false
+}
+
+// hasLimit reports whether a non-zero limit subfeature is present; drivers
+// report unset limits as 0.
+func hasLimit(sub map[string]float64, key string) bool {
+	v, ok := sub[key]
+	return ok && v != 0
+}
@@ -0,0 +1,162 @@
+package sensors
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const sensorsJSONSample = `{
+   "coretemp-isa-0000":{
+      "Adapter": "ISA adapter",
+      "Package id 0":{
+         "temp1_input": 45.000,
+         "temp1_max": 80.000,
+         "temp1_crit": 100.000,
+         "temp1_crit_alarm": 0.000
+      },
+      "Core 0":{
+         "temp2_input": 101.000,
+         "temp2_max": 80.000,
+         "temp2_crit": 100.000,
+         "temp2_crit_alarm": 0.000
+      }
+   },
+   "nct6775-isa-0290":{
+      "Adapter": "ISA adapter",
+      "Vcore":{
+         "in0_input": 0.880,
+         "in0_min": 0.000,
+         "in0_max": 1.744,
+         "in0_alarm": 0.000
+      },
+      "+12V":{
+         "in1_input": 11.520,
+         "in1_min": 11.800,
+         "in1_max": 12.200,
+         "in1_alarm": 0.000
+      },
+      "fan1":{
+         "fan1_input": 1054.000,
+         "fan1_min": 0.000,
+         "fan1_alarm": 0.000
+      },
+      "fan2":{
+         "fan2_input": 0.000,
+         "fan2_min": 300.000,
+         "fan2_alarm": 1.000
+      },
+      "intrusion0":{
+         "intrusion0_alarm": 1.000
+      },
+      "beep_enable":{
+         "beep_enable": 0.000
+      }
+   },
+   "nvme-pci-0100":{
+      "Adapter": "PCI adapter",
+      "Composite":{
+         "temp1_input": 38.850,
+         "temp1_max": 81.850,
+         "temp1_min": -273.150,
+         "temp1_crit": 84.850,
+         "temp1_alarm": 0.000
+      }
+   },
+   "amdgpu-pci-0300":{
+      "Adapter": "PCI adapter",
+      "PPT":{
+         "power1_average": 12.000,
+         "power1_input": 35.120,
+         "power1_cap": 150.000
+      }
+   }
+}`
+
+func TestParseSensorsJSON(t *testing.T) {
+	got, err := parseSensorsJSON([]byte(sensorsJSONSample))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []protocol.SensorMetric{
+		{Source: "lm-sensors", Chip: "amdgpu-pci-0300", Name: "PPT", Kind: "power", Value: 35.12, Unit: "W", Status: "ok"},
+		{Source: "lm-sensors", Chip: "coretemp-isa-0000", Name: "Core 0", Kind: "temperature", Value: 101, Unit: "C", Status: "critical"},
+		{Source: "lm-sensors", Chip: "coretemp-isa-0000", Name: "Package id 0", Kind: "temperature", Value: 45, Unit: "C", Status: "ok"},
+		{Source: "lm-sensors", Chip: "nct6775-isa-0290", Name: "+12V", Kind: "voltage", Value: 11.52, Unit: "V", Status: "warning"},
+		{Source: "lm-sensors", Chip: "nct6775-isa-0290", Name: "Vcore", Kind: "voltage", Value: 0.88, Unit: "V", Status: "ok"},
+		{Source: "lm-sensors", Chip: "nct6775-isa-0290", Name: "fan1", Kind: "fan", Value: 1054, Unit: "RPM", Status: "ok"},
+		{Source: "lm-sensors", Chip: "nct6775-isa-0290", Name: "fan2", Kind: "fan", Value: 0, Unit: "RPM", Status: "critical"},
+		{Source: "lm-sensors", Chip: "nvme-pci-0100", Name: "Composite", Kind: "temperature", Value: 38.85, Unit: "C", Status: "ok"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:")
+		for _, s := range got {
+			t.Errorf("  %+v", s)
+		}
+		t.Errorf("want:")
+		for _, s := range want {
+			t.Errorf("  %+v", s)
+		}
+	}
+}
+
+func TestParseSensorsJSON_Invalid(t *testing.T) {
+	if _, err := parseSensorsJSON([]byte("not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestParseSensorsJSON_Empty(t *testing.T) {
+	got, err := parseSensorsJSON([]byte("{}"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no sensors, got %+v", got)
+	}
+}
+
+// fakeSensors writes a script that prints output and exits with code.
+func fakeSensors(t *testing.T, output string, code int) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	path := filepath.Join(t.TempDir(), "sensors")
+	script := fmt.Sprintf("#!/bin/sh\ncat <<'EOF'\n%s\nEOF\nexit %d\n", output, code)
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadLMSensors_PartialReadNonZeroExit(t *testing.T) {
+	// One chip read, another failed: sensors prints what it has and exits 1
+	path := fakeSensors(t, `{"nct6775-isa-0290": {"Adapter": "ISA adapter", "fan1": {"fan1_input": 1054.0}}}`, 1)
+
+	got, err := readLMSensors(context.Background(), path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []protocol.SensorMetric{
+		{Source: "lm-sensors", Chip: "nct6775-isa-0290", Name: "fan1", Kind: "fan", Value: 1054, Unit: "RPM", Status: "ok"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestReadLMSensors_NoOutput(t *testing.T) {
+	path := fakeSensors(t, "", 1)
+
+	if _, err := readLMSensors(context.Background(), path); err == nil {
+		t.Error("expected the exit error when sensors prints nothing")
+	}
+}
@@ -0,0 +1,153 @@
+//go:build linux
+
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// unitAccounting is the cgroup accounting systemd reports for one unit.
+type unitAccounting struct {
+	MemoryCurrent uint64
+	CPUUsageNSec  uint64
+}
+
+type cpuSample struct {
+	nsec uint64
+	at   time.Time
+}
+
+// accountingTracker remembers each unit's last CPUUsageNSec so CPU percent
+// can be computed as a delta between collections.
+type accountingTracker struct {
+	mu   sync.Mutex
+	prev map[string]cpuSample
+}
+
+func newAccountingTracker() *accountingTracker {
+	return &accountingTracker{prev: make(map[string]cpuSample)}
+}
+
+// enrich fills the accounting fields of every active service in place.
+// A failed `systemctl show` leaves the services untouched.
+func (t *accountingTracker) enrich(ctx context.Context, systemctlPath string, services []protocol.ServiceMetric) {
+	units := make([]string, 0, len(services))
+	for _, s := range services {
+		if s.Status == "active" {
+			units = append(units, s.Name)
+		}
+	}
+	if len(units) == 0 {
+		return
+	}
+
+	args := append([]string{"show", "--property=Id,MemoryCurrent,CPUUsageNSec", "--"}, units...)
+	out, err := exec.CommandContext(ctx, systemctlPath, args...).Output()
+	if err != nil {
+		return
+	}
+
+	t.apply(parseSystemctlShow(bytes.NewReader(out)), services, time.Now())
+}
+
+func (t *accountingTracker) apply(acct map[string]unitAccounting, services []protocol.ServiceMetric, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	seen := make(map[string]bool, len(acct))
+	for i := range services {
+		s := &services[i]
+		a, ok := acct[s.Name]
+		if !ok {
+			continue
+		}
+		s.MemoryCurrent = a.MemoryCurrent
+		s.CPUUsageNSec = a.CPUUsageNSec
+
+		cur := cpuSample{nsec: a.CPUUsageNSec, at: now}
+		if prev, ok := t.prev[s.Name]; ok {
+			s.CPUPercent = cpuDeltaPercent(prev, cur)
+		}
+		t.prev[s.Name] = cur
+		seen[s.Name] = true
+	}
+
+	// Drop units that stopped so a restart starts from a fresh sample
+	for name := range t.prev {
+		if !seen[name] {
+			delete(t.prev, name)
+		}
+	}
+}
+
+// cpuDeltaPercent returns CPU time used between two samples as percent of
+// one core. A counter that went backwards (unit restarted) yields zero.
+func cpuDeltaPercent(prev, cur cpuSample) float64 {
+	elapsed := cur.at.Sub(prev.at)
+	if elapsed <= 0 || cur.nsec < prev.nsec {
+		return 0
+	}
+	return float64(cur.nsec-prev.nsec) / float64(elapsed.Nanoseconds()) * 100.0
+}
+
+// parseSystemctlShow parses `systemctl show --property=Id,...` output for
+// one or more units. Units are separated by blank lines and keyed by Id.
+// Values systemd reports as "[not set]" or as UINT64_MAX read as zero.
+func parseSystemctlShow(r io.Reader) map[string]unitAccounting {
+	result := make(map[string]unitAccounting)
+	scanner := bufio.NewScanner(r)
+
+	var id string
+	var cur unitAccounting
+	flush := func() {
+		if id != "" {
+			result[id] = cur
+		}
+		id = ""
+		cur = unitAccounting{}
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			flush()
+			continue
+		}
+
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "Id":
+			id = val
+		case "MemoryCurrent":
+			cur.MemoryCurrent = parseAccountingValue(val)
+		case "CPUUsageNSec":
+			cur.CPUUsageNSec = parseAccountingValue(val)
+		}
+	}
+	flush()
+
+	return result
+}
+
+func parseAccountingValue(s string) uint64 {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || v == math.MaxUint64 {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,111 @@
+//go:build linux
+
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestParseSystemctlShow(t *testing.T) {
+	input := `Id=ssh.service
+MemoryCurrent=5431296
+CPUUsageNSec=1234567890
+
+Id=cron.service
+MemoryCurrent=[not set]
+CPUUsageNSec=18446744073709551615
+
+Id=nginx.service
+CPUUsageNSec=42
+MemoryCurrent=1048576
+`
+
+	got := parseSystemctlShow(strings.NewReader(input))
+	if len(got) != 3 {
+		t.Fatalf("got %d units, want 3", len(got))
+	}
+
+	want := map[string]unitAccounting{
+		"ssh.service":   {MemoryCurrent: 5431296, CPUUsageNSec: 1234567890},
+		"cron.service":  {},
+		"nginx.service": {MemoryCurrent: 1048576, CPUUsageNSec: 42},
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("%s = %+v, want %+v", name, got[name], w)
+		}
+	}
+}
+
+func TestParseSystemctlShow_Empty(t *testing.T) {
+	if got := parseSystemctlShow(strings.NewReader("")); len(got) != 0 {
+		t.Errorf("got %d units, want 0", len(got))
+	}
+}
+
+func TestCPUDeltaPercent(t *testing.T) {
+	start := time.Now()
+	tests := []struct {
+		name string
+		prev cpuSample
+		cur  cpuSample
+		want float64
+	}{
+		{"quarter core", cpuSample{1e9, start}, cpuSample{1.25e9, start.Add(time.Second)}, 25},
+		{"two cores", cpuSample{0, start}, cpuSample{120e9, start.Add(time.Minute)}, 200},
+		{"idle", cpuSample{5e9, start}, cpuSample{5e9, start.Add(time.Second)}, 0},
+		{"restarted", cpuSample{5e9, start}, cpuSample{1e9, start.Add(time.Second)}, 0},
+		{"no elapsed", cpuSample{1e9, start}, cpuSample{2e9, start}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cpuDeltaPercent(tt.prev, tt.cur); got != tt.want {
+				t.Errorf("cpuDeltaPercent = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccountingTracker_Apply(t *testing.T) {
+	tr := newAccountingTracker()
+	start := time.Now()
+
+	services := []protocol.ServiceMetric{
+		{Name: "ssh.service", Status: "active"},
+		{Name: "nginx.service", Status: "failed"},
+	}
+
+	tr.apply(map[string]unitAccounting{
+		"ssh.service": {MemoryCurrent: 4096, CPUUsageNSec: 1e9},
+	}, services, start)
+
+	if services[0].MemoryCurrent != 4096 || services[0].CPUUsageNSec != 1e9 {
+		t.Errorf("first apply: ssh = %+v", services[0])
+	}
+	if services[0].CPUPercent != 0 {
+		t.Errorf("first apply: CPUPercent = %f, want 0 without a previous sample", services[0].CPUPercent)
+	}
+	if services[1].MemoryCurrent != 0 {
+		t.Errorf("inactive unit should not be enriched: %+v", services[1])
+	}
+
+	services = []protocol.ServiceMetric{{Name: "ssh.service", Status: "active"}}
+	tr.apply(map[string]unitAccounting{
+		"ssh.service": {MemoryCurrent: 8192, CPUUsageNSec: 3e9},
+	}, services, start.Add(10*time.Second))
+
+	if services[0].CPUPercent != 20 {
+		t.Errorf("second apply: CPUPercent = %f, want 20", services[0].CPUPercent)
+	}
+
+	// Unit gone: its sample is forgotten
+	tr.apply(map[string]unitAccounting{}, nil, start.Add(20*time.Second))
+	if len(tr.prev) != 0 {
+		t.Errorf("prev = %v, want empty", tr.prev)
+	}
+}
@@ -0,0 +1,73 @@
+//go:build linux
+
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// CollectFailedUnits reports the systemd units currently in the failed
+// state. An empty list is still emitted so cleared failures are seen. It
+// is a no-op when systemctl is not installed.
+func CollectFailedUnits(ctx context.Context) ([]protocol.Metric, error) {
+	path, err := exec.LookPath("systemctl")
+	if err != nil {
+		return nil, nil
+	}
+
+	cmd := exec.CommandContext(ctx,
+		path, "--failed",
+		"--no-pager", "--no-legend",
+		"--plain",
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := parseFailedUnitsFrom(bytes.NewReader(out))
+	if err != nil {
+		return nil, err
+	}
+	return []protocol.Metric{protocol.FailedUnitListMetric{Units: units}}, nil
+}
+
+// parseFailedUnitsFrom parses `systemctl --failed --no-legend` output:
+// UNIT LOAD ACTIVE SUB DESCRIPTION. A leading "●" marker, printed when
+// --plain is unsupported, is ignored, as is the "N loaded units listed."
+// summary older systemd versions print regardless of --no-legend.
+func parseFailedUnitsFrom(r io.Reader) ([]protocol.FailedUnitMetric, error) {
+	units := []protocol.FailedUnitMetric{}
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 && fields[0] == "●" {
+			fields = fields[1:]
+		}
+		// Unit names always carry a type suffix (.service, .mount, ...)
+		if len(fields) < 4 || !strings.Contains(fields[0], ".") {
+			continue
+		}
+
+		units = append(units, protocol.FailedUnitMetric{
+			Unit:        fields[0],
+			LoadState:   fields[1],
+			ActiveState: fields[2],
+			SubState:    fields[3],
+			Description: strings.Join(fields[4:], " "),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return units, nil
+}
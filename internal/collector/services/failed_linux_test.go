@@ -0,0 +1,56 @@
+//go:build linux
+
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestParseFailedUnitsFrom(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []protocol.FailedUnitMetric
+	}{
+		{
+			name: "Failures",
+			input: `nginx.service        loaded failed failed A high performance web server and a reverse proxy server
+● systemd-networkd-wait-online.service loaded failed failed Wait for Network to be Configured
+backup.mount         loaded failed failed /backup
+`,
+			want: []protocol.FailedUnitMetric{
+				{Unit: "nginx.service", LoadState: "loaded", ActiveState: "failed", SubState: "failed",
+					Description: "A high performance web server and a reverse proxy server"},
+				{Unit: "systemd-networkd-wait-online.service", LoadState: "loaded", ActiveState: "failed", SubState: "failed",
+					Description: "Wait for Network to be Configured"},
+				{Unit: "backup.mount", LoadState: "loaded", ActiveState: "failed", SubState: "failed", Description: "/backup"},
+			},
+		},
+		{
+			name:  "No failures",
+			input: "",
+			want:  []protocol.FailedUnitMetric{},
+		},
+		{
+			name:  "Short lines skipped",
+			input: "0 loaded units listed.\n",
+			want:  []protocol.FailedUnitMetric{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFailedUnitsFrom(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -14,7 +14,7 @@ import (
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
-func MakeCollector(launchctlPath string) collector.CollectFunc {
+func MakeCollector(launchctlPath string, _ bool) collector.CollectFunc {
 	return func(ctx context.Context) ([]protocol.Metric, error) {
 		if launchctlPath == "" {
 			return nil, nil
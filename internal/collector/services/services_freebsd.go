@@ -15,7 +15,7 @@ import (
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
-func MakeCollector(_ string) collector.CollectFunc {
+func MakeCollector(_ string, _ bool) collector.CollectFunc {
 	return Collect
 }
 
@@ -14,18 +14,48 @@ import (
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
-var statusIntern = map[string]string{
-	"loaded":    "loaded",
-	"not-found": "not-found",
-	"active":    "active",
-	"inactive":  "inactive",
-	"running":   "running",
-	"dead":      "dead",
-	"failed":    "failed",
-	"exited":    "exited",
+// unitStates is the systemd load, active and sub-state vocabulary seen in
+// `systemctl list-units` output. Add new states here; statusIntern is
+// built from it.
+var unitStates = []string{
+	// LOAD
+	"stub", "loaded", "not-found", "bad-setting", "error", "merged", "masked",
+
+	// ACTIVE
+	"active", "reloading", "inactive", "failed", "activating", "deactivating",
+	"maintenance", "refreshing",
+
+	// SUB (services)
+	"dead", "condition", "start-pre", "start", "start-post", "running", "exited",
+	"reload", "reload-signal", "reload-notify", "stop", "stop-watchdog",
+	"stop-sigterm", "stop-sigkill", "stop-post", "final-watchdog",
+	"final-sigterm", "final-sigkill", "auto-restart", "auto-restart-queued",
+	"dead-before-auto-restart", "failed-before-auto-restart",
+	"dead-resources-pinned", "cleaning",
+
+	// SUB (sockets, timers, mounts and friends)
+	"start-chown", "listening", "stop-pre", "stop-pre-sigterm",
+	"stop-pre-sigkill", "waiting", "elapsed", "plugged", "mounted", "mounting",
+	"unmounting", "remounting", "tentative", "abandoned",
 }
 
-func MakeCollector(systemctlPath string) collector.CollectFunc {
+var statusIntern = func() map[string]string {
+	m := make(map[string]string, len(unitStates))
+	for _, s := range unitStates {
+		m[s] = s
+	}
+	return m
+}()
+
+// MakeCollector returns the systemd service collector. With accounting set,
+// active services also carry their cgroup memory and CPU usage, at the cost
+// of one extra `systemctl show` per collection.
+func MakeCollector(systemctlPath string, accounting bool) collector.CollectFunc {
+	var tracker *accountingTracker
+	if accounting {
+		tracker = newAccountingTracker()
+	}
+
 	return func(ctx context.Context) ([]protocol.Metric, error) {
 		if systemctlPath == "" {
 			return nil, nil
@@ -40,7 +70,16 @@ func MakeCollector(systemctlPath string) collector.CollectFunc {
 		if err != nil {
 			return nil, err
 		}
-		return parseSystemctlFrom(bytes.NewReader(out))
+		metrics, err := parseSystemctlFrom(bytes.NewReader(out))
+		if err != nil || tracker == nil {
+			return metrics, err
+		}
+		for _, m := range metrics {
+			if list, ok := m.(protocol.ServiceListMetric); ok {
+				tracker.enrich(ctx, systemctlPath, list.Services)
+			}
+		}
+		return metrics, nil
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"strings"
 	"testing"
+	"unsafe"
 
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
@@ -225,6 +226,19 @@ func TestIntern(t *testing.T) {
 	}
 }
 
+func TestIntern_SubStates(t *testing.T) {
+	for _, state := range []string{"start-pre", "auto-restart", "listening", "stop-sigterm", "reload-notify", "dead-before-auto-restart"} {
+		a := intern([]byte(state))
+		b := intern([]byte(state))
+		if a != state {
+			t.Errorf("intern(%q) = %q", state, a)
+		}
+		if unsafe.StringData(a) != unsafe.StringData(b) || unsafe.StringData(a) != unsafe.StringData(statusIntern[state]) {
+			t.Errorf("intern(%q) did not return the interned string", state)
+		}
+	}
+}
+
 func TestIntern_ReturnsSamePointer(t *testing.T) {
 	// Interned strings should return the same pointer
 	s1 := intern([]byte("loaded"))
@@ -326,7 +340,7 @@ func TestCollect_ContextCancel(t *testing.T) {
 }
 
 func TestMakeCollector_EmptyPath(t *testing.T) {
-	col := MakeCollector("")
+	col := MakeCollector("", false)
 	metrics, err := col(context.Background())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
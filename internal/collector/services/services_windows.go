@@ -15,7 +15,7 @@ import (
 	"golang.org/x/sys/windows/svc/mgr"
 )
 
-func MakeCollector(_ string) collector.CollectFunc {
+func MakeCollector(_ string, _ bool) collector.CollectFunc {
 	return Collect
 }
 
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package services
+
+import (
+	"context"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// CollectTimers is a no-op on platforms without systemd.
+func CollectTimers(ctx context.Context) ([]protocol.Metric, error) {
+	return nil, nil
+}
+
+// CollectFailedUnits is a no-op on platforms without systemd.
+func CollectFailedUnits(ctx context.Context) ([]protocol.Metric, error) {
+	return nil, nil
+}
@@ -0,0 +1,130 @@
+//go:build linux
+
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// timerTimeLayout is how systemctl prints NEXT and LAST.
+const timerTimeLayout = "Mon 2006-01-02 15:04:05 MST"
+
+// CollectTimers reports every systemd timer. It is a no-op when systemctl
+// is not installed.
+func CollectTimers(ctx context.Context) ([]protocol.Metric, error) {
+	path, err := exec.LookPath("systemctl")
+	if err != nil {
+		return nil, nil
+	}
+
+	cmd := exec.CommandContext(ctx,
+		path, "list-timers",
+		"--all", "--no-pager", "--no-legend",
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	timers, err := parseTimersFrom(bytes.NewReader(out), time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return []protocol.Metric{protocol.TimerListMetric{Timers: timers}}, nil
+}
+
+// parseTimersFrom parses `systemctl list-timers --no-legend` output, whose
+// columns are NEXT, LEFT, LAST, PASSED, UNIT and ACTIVATES. Timestamps
+// and durations span several space-separated words and are "n/a" (or "-"
+// on newer systemd) for a timer that is not scheduled or has never run.
+// Timestamps are interpreted in loc.
+func parseTimersFrom(r io.Reader, loc *time.Location) ([]protocol.TimerMetric, error) {
+	timers := []protocol.TimerMetric{}
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		// Everything before the unit is time columns
+		unit := -1
+		for i, f := range fields {
+			if strings.HasSuffix(f, ".timer") {
+				unit = i
+				break
+			}
+		}
+		if unit < 0 {
+			continue
+		}
+
+		t := protocol.TimerMetric{Unit: fields[unit]}
+		if unit+1 < len(fields) {
+			t.Activates = fields[unit+1]
+		}
+
+		cols := fields[:unit]
+		t.NextElapse, cols = takeTimerTime(cols, loc)
+		t.Left, cols = takeTimerDuration(cols, "left")
+		t.LastTrigger, cols = takeTimerTime(cols, loc)
+		t.Passed, _ = takeTimerDuration(cols, "ago")
+
+		timers = append(timers, t)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return timers, nil
+}
+
+// isTimerUnset reports whether a column is systemd's placeholder for an
+// unset value.
+func isTimerUnset(f string) bool {
+	return f == "n/a" || f == "-"
+}
+
+// takeTimerTime consumes a timestamp column from the front of fields. It
+// returns nil for an unset or unparsable value.
+func takeTimerTime(fields []string, loc *time.Location) (*time.Time, []string) {
+	if len(fields) == 0 {
+		return nil, fields
+	}
+	if isTimerUnset(fields[0]) {
+		return nil, fields[1:]
+	}
+	if len(fields) < 4 {
+		return nil, nil
+	}
+
+	ts, err := time.ParseInLocation(timerTimeLayout, strings.Join(fields[:4], " "), loc)
+	if err != nil {
+		return nil, fields[4:]
+	}
+	return &ts, fields[4:]
+}
+
+// takeTimerDuration consumes a relative duration column ending in suffix
+// ("5h 12min left") from the front of fields, returning it without the
+// suffix.
+func takeTimerDuration(fields []string, suffix string) (string, []string) {
+	if len(fields) == 0 {
+		return "", fields
+	}
+	if isTimerUnset(fields[0]) {
+		return "", fields[1:]
+	}
+	for i, f := range fields {
+		if f == suffix {
+			return strings.Join(fields[:i], " "), fields[i+1:]
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,83 @@
+//go:build linux
+
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func timerTime(s string) *time.Time {
+	t, err := time.ParseInLocation(timerTimeLayout, s, time.UTC)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+func TestParseTimersFrom(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []protocol.TimerMetric
+	}{
+		{
+			name:  "Scheduled and run",
+			input: "Thu 2024-06-13 00:00:00 UTC 5h 12min left Wed 2024-06-12 00:00:04 UTC 18h ago logrotate.timer logrotate.service\n",
+			want: []protocol.TimerMetric{{
+				Unit:        "logrotate.timer",
+				Activates:   "logrotate.service",
+				NextElapse:  timerTime("Thu 2024-06-13 00:00:00 UTC"),
+				Left:        "5h 12min",
+				LastTrigger: timerTime("Wed 2024-06-12 00:00:04 UTC"),
+				Passed:      "18h",
+			}},
+		},
+		{
+			name:  "Never run",
+			input: "Thu 2024-06-13 06:42:11 UTC 1 day 2h left n/a                         n/a      apt-daily.timer apt-daily.service\n",
+			want: []protocol.TimerMetric{{
+				Unit:       "apt-daily.timer",
+				Activates:  "apt-daily.service",
+				NextElapse: timerTime("Thu 2024-06-13 06:42:11 UTC"),
+				Left:       "1 day 2h",
+			}},
+		},
+		{
+			name:  "Not scheduled, dash placeholders",
+			input: "-                           -             Wed 2024-06-12 09:15:00 UTC 3min 20s ago fstrim.timer fstrim.service\n",
+			want: []protocol.TimerMetric{{
+				Unit:        "fstrim.timer",
+				Activates:   "fstrim.service",
+				LastTrigger: timerTime("Wed 2024-06-12 09:15:00 UTC"),
+				Passed:      "3min 20s",
+			}},
+		},
+		{
+			name:  "Fully unset",
+			input: "n/a n/a n/a n/a ureadahead-stop.timer ureadahead-stop.service\n",
+			want:  []protocol.TimerMetric{{Unit: "ureadahead-stop.timer", Activates: "ureadahead-stop.service"}},
+		},
+		{
+			name:  "Junk lines skipped",
+			input: "\n3 timers listed.\n",
+			want:  []protocol.TimerMetric{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTimersFrom(strings.NewReader(tt.input), time.UTC)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
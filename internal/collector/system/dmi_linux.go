@@ -0,0 +1,155 @@
+//go:build linux
+
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const sysfsDMI = "/sys/class/dmi/id"
+
+// CollectDMI reports firmware, board and chassis identity. It reads the
+// sysfs DMI attributes, falling back to dmidecode only when sysfs is
+// unavailable. It is a no-op when neither source exists.
+func CollectDMI(ctx context.Context) ([]protocol.Metric, error) {
+	m, ok := parseDMIFrom(sysfsDMI)
+	if !ok {
+		path, err := exec.LookPath("dmidecode")
+		if err != nil {
+			return nil, nil
+		}
+		out, err := exec.CommandContext(ctx, path,
+			"-t", "bios", "-t", "system", "-t", "baseboard", "-t", "chassis",
+		).Output()
+		if err != nil {
+			return nil, err
+		}
+		if m, err = parseDmidecodeFrom(bytes.NewReader(out)); err != nil {
+			return nil, err
+		}
+	}
+
+	if m == (protocol.DMIMetric{}) {
+		return nil, nil
+	}
+	return []protocol.Metric{m}, nil
+}
+
+// parseDMIFrom reads the DMI attributes under a sysfs dmi/id root.
+// Attributes that are missing or unreadable (product_serial is usually
+// root-only) are left blank. ok is false if root does not exist.
+func parseDMIFrom(root string) (m protocol.DMIMetric, ok bool) {
+	if _, err := os.Stat(root); err != nil {
+		return m, false
+	}
+
+	m = protocol.DMIMetric{
+		SysVendor:     readDMIAttr(root, "sys_vendor"),
+		ProductName:   readDMIAttr(root, "product_name"),
+		ProductSerial: readDMIAttr(root, "product_serial"),
+		BoardVendor:   readDMIAttr(root, "board_vendor"),
+		BoardName:     readDMIAttr(root, "board_name"),
+		BIOSVendor:    readDMIAttr(root, "bios_vendor"),
+		BIOSVersion:   readDMIAttr(root, "bios_version"),
+		BIOSDate:      readDMIAttr(root, "bios_date"),
+		ChassisType:   chassisTypeName(readDMIAttr(root, "chassis_type")),
+	}
+	return m, true
+}
+
+// readDMIAttr returns a trimmed sysfs attribute, or "" if it can't be read.
+func readDMIAttr(root, name string) string {
+	data, err := os.ReadFile(filepath.Join(root, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// chassisTypes maps SMBIOS chassis type codes to the names dmidecode uses.
+var chassisTypes = map[int]string{
+	1: "Other", 2: "Unknown", 3: "Desktop", 4: "Low Profile Desktop",
+	5: "Pizza Box", 6: "Mini Tower", 7: "Tower", 8: "Portable",
+	9: "Laptop", 10: "Notebook", 11: "Hand Held", 12: "Docking Station",
+	13: "All In One", 14: "Sub Notebook", 15: "Space-saving", 16: "Lunch Box",
+	17: "Main Server Chassis", 18: "Expansion Chassis", 19: "Sub Chassis",
+	20: "Bus Expansion Chassis", 21: "Peripheral Chassis", 22: "RAID Chassis",
+	23: "Rack Mount Chassis", 24: "Sealed-case PC", 25: "Multi-system",
+	26: "CompactPCI", 27: "AdvancedTCA", 28: "Blade", 29: "Blade Enclosing",
+	30: "Tablet", 31: "Convertible", 32: "Detachable", 33: "IoT Gateway",
+	34: "Embedded PC", 35: "Mini PC", 36: "Stick PC",
+}
+
+// chassisTypeName converts the numeric sysfs chassis_type to its name,
+// returning the raw value if it isn't a known code.
+func chassisTypeName(code string) string {
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return code
+	}
+	if name, ok := chassisTypes[n]; ok {
+		return name
+	}
+	return code
+}
+
+// parseDmidecodeFrom parses `dmidecode -t bios -t system -t baseboard
+// -t chassis` output. Placeholder values such as "Not Specified" are
+// treated as blank.
+func parseDmidecodeFrom(r io.Reader) (protocol.DMIMetric, error) {
+	var m protocol.DMIMetric
+	var section string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "Handle ") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, "\t") {
+			section = strings.TrimSpace(line)
+			continue
+		}
+
+		key, val, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		val = strings.TrimSpace(val)
+		if val == "Not Specified" || val == "Not Present" {
+			continue
+		}
+
+		switch section + "/" + key {
+		case "BIOS Information/Vendor":
+			m.BIOSVendor = val
+		case "BIOS Information/Version":
+			m.BIOSVersion = val
+		case "BIOS Information/Release Date":
+			m.BIOSDate = val
+		case "System Information/Manufacturer":
+			m.SysVendor = val
+		case "System Information/Product Name":
+			m.ProductName = val
+		case "System Information/Serial Number":
+			m.ProductSerial = val
+		case "Base Board Information/Manufacturer":
+			m.BoardVendor = val
+		case "Base Board Information/Product Name":
+			m.BoardName = val
+		case "Chassis Information/Type":
+			m.ChassisType = val
+		}
+	}
+	return m, scanner.Err()
+}
@@ -0,0 +1,130 @@
+//go:build linux
+
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestParseDMIFrom(t *testing.T) {
+	root := t.TempDir()
+	attrs := map[string]string{
+		"sys_vendor":     "LENOVO",
+		"product_name":   "20HDCTO1WW",
+		"product_serial": "PF0ABCDE",
+		"board_vendor":   "LENOVO",
+		"board_name":     "20HDCTO1WW",
+		"bios_vendor":    "LENOVO",
+		"bios_version":   "N1QET98W (1.73 )",
+		"bios_date":      "12/26/2022",
+		"chassis_type":   "10",
+	}
+	for name, val := range attrs {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(val+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Serial is root-only on most systems
+	if err := os.Chmod(filepath.Join(root, "product_serial"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := parseDMIFrom(root)
+	if !ok {
+		t.Fatal("expected ok for existing root")
+	}
+
+	want := protocol.DMIMetric{
+		SysVendor:   "LENOVO",
+		ProductName: "20HDCTO1WW",
+		BoardVendor: "LENOVO",
+		BoardName:   "20HDCTO1WW",
+		BIOSVendor:  "LENOVO",
+		BIOSVersion: "N1QET98W (1.73 )",
+		BIOSDate:    "12/26/2022",
+		ChassisType: "Notebook",
+	}
+	// Running as root can still read the serial
+	if os.Geteuid() == 0 {
+		want.ProductSerial = "PF0ABCDE"
+	}
+	if got != want {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseDMIFrom_Missing(t *testing.T) {
+	if _, ok := parseDMIFrom(filepath.Join(t.TempDir(), "missing")); ok {
+		t.Error("expected ok=false for missing root")
+	}
+}
+
+func TestChassisTypeName(t *testing.T) {
+	tests := map[string]string{
+		"3":   "Desktop",
+		"23":  "Rack Mount Chassis",
+		"99":  "99",
+		"":    "",
+		"abc": "abc",
+	}
+	for in, want := range tests {
+		if got := chassisTypeName(in); got != want {
+			t.Errorf("chassisTypeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+const dmidecodeSample = `# dmidecode 3.3
+Getting SMBIOS data from sysfs.
+SMBIOS 3.0.0 present.
+
+Handle 0x0000, DMI type 0, 24 bytes
+BIOS Information
+	Vendor: American Megatrends Inc.
+	Version: 1.40
+	Release Date: 03/15/2021
+	Characteristics:
+		PCI is supported
+
+Handle 0x0001, DMI type 1, 27 bytes
+System Information
+	Manufacturer: Micro-Star International Co., Ltd.
+	Product Name: MS-7C56
+	Serial Number: Not Specified
+
+Handle 0x0002, DMI type 2, 15 bytes
+Base Board Information
+	Manufacturer: Micro-Star International Co., Ltd.
+	Product Name: B550-A PRO (MS-7C56)
+
+Handle 0x0003, DMI type 3, 22 bytes
+Chassis Information
+	Manufacturer: Micro-Star International Co., Ltd.
+	Type: Desktop
+`
+
+func TestParseDmidecodeFrom(t *testing.T) {
+	got, err := parseDmidecodeFrom(strings.NewReader(dmidecodeSample))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := protocol.DMIMetric{
+		SysVendor:   "Micro-Star International Co., Ltd.",
+		ProductName: "MS-7C56",
+		BoardVendor: "Micro-Star International Co., Ltd.",
+		BoardName:   "B550-A PRO (MS-7C56)",
+		BIOSVendor:  "American Megatrends Inc.",
+		BIOSVersion: "1.40",
+		BIOSDate:    "03/15/2021",
+		ChassisType: "Desktop",
+	}
+	if got != want {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
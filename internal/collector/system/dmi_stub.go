@@ -0,0 +1,14 @@
+//go:build !linux
+
+package system
+
+import (
+	"context"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// CollectDMI is a no-op on platforms without sysfs DMI attributes.
+func CollectDMI(ctx context.Context) ([]protocol.Metric, error) {
+	return nil, nil
+}
@@ -0,0 +1,58 @@
+//go:build linux
+
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const procRandom = "/proc/sys/kernel/random"
+
+// CollectEntropy reports the kernel entropy pool level. It is a no-op
+// when entropy_avail is absent.
+func CollectEntropy(ctx context.Context) ([]protocol.Metric, error) {
+	m, ok, err := parseEntropyFrom(procRandom)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return []protocol.Metric{m}, nil
+}
+
+// parseEntropyFrom reads entropy_avail and, if present, poolsize from a
+// kernel/random directory. ok is false when entropy_avail does not exist.
+func parseEntropyFrom(dir string) (m protocol.EntropyMetric, ok bool, err error) {
+	avail, err := readProcInt(filepath.Join(dir, "entropy_avail"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, false, nil
+	}
+	if err != nil {
+		return m, false, err
+	}
+	m.Available = avail
+
+	if pool, err := readProcInt(filepath.Join(dir, "poolsize")); err == nil {
+		m.PoolSize = pool
+	}
+	return m, true, nil
+}
+
+func readProcInt(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,60 @@
+//go:build linux
+
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseEntropyFrom(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "entropy_avail"), []byte("256\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "poolsize"), []byte("256\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok, err := parseEntropyFrom(dir)
+	if err != nil || !ok {
+		t.Fatalf("got ok=%v err=%v, want ok", ok, err)
+	}
+	if got.Available != 256 || got.PoolSize != 256 {
+		t.Errorf("got %+v, want 256/256", got)
+	}
+}
+
+func TestParseEntropyFrom_NoPoolSize(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "entropy_avail"), []byte("12\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok, err := parseEntropyFrom(dir)
+	if err != nil || !ok {
+		t.Fatalf("got ok=%v err=%v, want ok", ok, err)
+	}
+	if got.Available != 12 || got.PoolSize != 0 {
+		t.Errorf("got %+v, want 12 with no pool size", got)
+	}
+}
+
+func TestParseEntropyFrom_Missing(t *testing.T) {
+	_, ok, err := parseEntropyFrom(t.TempDir())
+	if err != nil || ok {
+		t.Errorf("got ok=%v err=%v, want a silent no-op", ok, err)
+	}
+}
+
+func TestParseEntropyFrom_Malformed(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "entropy_avail"), []byte("lots\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := parseEntropyFrom(dir); err == nil {
+		t.Error("expected parse error")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package system
+
+import (
+	"context"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// CollectEntropy is a no-op on platforms without /proc/sys/kernel/random.
+func CollectEntropy(ctx context.Context) ([]protocol.Metric, error) {
+	return nil, nil
+}
@@ -13,7 +13,7 @@ import (
 // macOS thermal sensors require either CGo or a helper
 // binary. SMC key names vary by Mac model, making a pure
 // Go implementation fragile.
-func MakeCollector(_ []string) collector.CollectFunc {
+func MakeCollector(_ []string, _ float64) collector.CollectFunc {
 	return func(ctx context.Context) ([]protocol.Metric, error) {
 		return nil, nil
 	}
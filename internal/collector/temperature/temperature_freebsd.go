@@ -13,7 +13,7 @@ import (
 	"golang.org/x/sys/unix"
 )
 
-func MakeCollector(_ []string) collector.CollectFunc {
+func MakeCollector(_ []string, _ float64) collector.CollectFunc {
 	return Collect
 }
 
@@ -17,11 +17,13 @@ import (
 
 // MakeCollector returns a CollectFunc that reads from the
 // provided thermal zone paths, avoiding a filepath.Glob on every cycle.
-func MakeCollector(zones []string) collector.CollectFunc {
+// Trip points at or above maxBound are dropped; zero uses
+// util.DefaultMaxTempBound.
+func MakeCollector(zones []string, maxBound float64) collector.CollectFunc {
 	return func(ctx context.Context) ([]protocol.Metric, error) {
 		var results []protocol.Metric
 		for _, zone := range zones {
-			if m, err := readThermalZone(zone); err == nil {
+			if m, err := readThermalZone(zone, maxBound); err == nil {
 				results = append(results, *m)
 			}
 		}
@@ -29,7 +31,7 @@ func MakeCollector(zones []string) collector.CollectFunc {
 	}
 }
 
-func readThermalZone(dir string) (*protocol.TemperatureMetric, error) {
+func readThermalZone(dir string, maxBound float64) (*protocol.TemperatureMetric, error) {
 	fType, err := os.Open(filepath.Join(dir, "type"))
 	if err != nil {
 		return nil, err
@@ -48,10 +50,10 @@ func readThermalZone(dir string) (*protocol.TemperatureMetric, error) {
 		defer fMax.Close()
 	}
 
-	return parseThermalZoneFrom(fType, fTemp, fMax)
+	return parseThermalZoneFrom(fType, fTemp, fMax, maxBound)
 }
 
-func parseThermalZoneFrom(typeR, tempR, maxR io.Reader) (*protocol.TemperatureMetric, error) {
+func parseThermalZoneFrom(typeR, tempR, maxR io.Reader, maxBound float64) (*protocol.TemperatureMetric, error) {
 	// Sensor Name
 	nameData, err := io.ReadAll(typeR)
 	if err != nil {
@@ -73,7 +75,7 @@ func parseThermalZoneFrom(typeR, tempR, maxR io.Reader) (*protocol.TemperatureMe
 	var max *float64
 	if maxR != nil {
 		if v, err := parseThermalValueFrom(maxR); err == nil {
-			max = util.NormalizeMax(tempVal, v)
+			max = util.NormalizeMax(tempVal, v, maxBound)
 		}
 	}
 
@@ -210,7 +210,7 @@ func TestParseThermalZoneFrom(t *testing.T) {
 				maxR = strings.NewReader(tt.maxData)
 			}
 
-			got, err := parseThermalZoneFrom(typeR, tempR, maxR)
+			got, err := parseThermalZoneFrom(typeR, tempR, maxR, 0)
 
 			if tt.wantErr {
 				if err == nil {
@@ -249,7 +249,7 @@ func TestReadThermalZone_Integration(t *testing.T) {
 
 	// Test first available zone
 	zone := zones[0]
-	m, err := readThermalZone(zone)
+	m, err := readThermalZone(zone, 0)
 	if err != nil {
 		t.Fatalf("readThermalZone(%s) failed: %v", zone, err)
 	}
@@ -262,7 +262,7 @@ func TestReadThermalZone_Integration(t *testing.T) {
 }
 
 func TestReadThermalZone_InvalidPath(t *testing.T) {
-	_, err := readThermalZone("/nonexistent/path")
+	_, err := readThermalZone("/nonexistent/path", 0)
 	if err == nil {
 		t.Error("Expected error for invalid path")
 	}
@@ -370,7 +370,7 @@ func TestNormalizeMax(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := util.NormalizeMax(tt.temp, tt.max)
+			got := util.NormalizeMax(tt.temp, tt.max, 0)
 			switch {
 			case got == nil && tt.want == nil:
 				// pass
@@ -383,10 +383,67 @@ func TestNormalizeMax(t *testing.T) {
 	}
 }
 
+func TestNormalizeMax_UpperBound(t *testing.T) {
+	tests := []struct {
+		name  string
+		temp  float64
+		max   float64
+		upper float64
+		want  *float64
+	}{
+		{"default keeps 199.9", 40, 199.9, 0, float64Ptr(199.9)},
+		{"default drops 200", 40, 200, 0, nil},
+		{"negative upper uses default", 40, 250, -1, nil},
+		{"raised bound keeps industrial trip point", 180, 350, 400, float64Ptr(350)},
+		{"raised bound still exclusive", 180, 400, 400, nil},
+		{"tighter bound drops 110", 50, 110, 105, nil},
+		{"tighter bound keeps 100", 50, 100, 105, float64Ptr(100)},
+		{"max below temp still dropped", 300, 250, 400, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := util.NormalizeMax(tt.temp, tt.max, tt.upper)
+			switch {
+			case got == nil && tt.want == nil:
+				// pass
+			case got == nil || tt.want == nil:
+				t.Errorf("NormalizeMax(%.1f, %.1f, %.1f) = %v, want %v", tt.temp, tt.max, tt.upper, got, tt.want)
+			case *got != *tt.want:
+				t.Errorf("NormalizeMax(%.1f, %.1f, %.1f) = %v, want %v", tt.temp, tt.max, tt.upper, *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestParseThermalZoneFrom_MaxBound(t *testing.T) {
+	for _, tt := range []struct {
+		bound   float64
+		wantMax bool
+	}{
+		{0, false},   // 250°C trip point is above the 200 default
+		{300, true},  // raised bound accepts it
+		{250, false}, // bound is exclusive
+	} {
+		m, err := parseThermalZoneFrom(
+			strings.NewReader("x86_pkg_temp\n"),
+			strings.NewReader("60000\n"),
+			strings.NewReader("250000\n"),
+			tt.bound,
+		)
+		if err != nil {
+			t.Fatalf("bound %.0f: %v", tt.bound, err)
+		}
+		if (m.Max != nil) != tt.wantMax {
+			t.Errorf("bound %.0f: Max = %v, want present=%v", tt.bound, m.Max, tt.wantMax)
+		}
+	}
+}
+
 func float64Ptr(v float64) *float64 { return &v }
 
 func TestMakeCollector_NoZones(t *testing.T) {
-	col := MakeCollector(nil)
+	col := MakeCollector(nil, 0)
 	metrics, err := col(context.Background())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -397,7 +454,7 @@ func TestMakeCollector_NoZones(t *testing.T) {
 }
 
 func TestMakeCollector_InvalidZones(t *testing.T) {
-	col := MakeCollector([]string{"/nonexistent/zone"})
+	col := MakeCollector([]string{"/nonexistent/zone"}, 0)
 	metrics, err := col(context.Background())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -413,7 +470,7 @@ func TestMakeCollector_Integration(t *testing.T) {
 		t.Skip("no thermal zones available")
 	}
 
-	col := MakeCollector(zones)
+	col := MakeCollector(zones, 0)
 	metrics, err := col(context.Background())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -442,7 +499,7 @@ func BenchmarkParseThermalZoneFrom(b *testing.B) {
 		typeR := strings.NewReader(typeData)
 		tempR := strings.NewReader(tempData)
 		maxR := strings.NewReader(maxData)
-		_, _ = parseThermalZoneFrom(typeR, tempR, maxR)
+		_, _ = parseThermalZoneFrom(typeR, tempR, maxR, 0)
 	}
 }
 
@@ -454,7 +511,7 @@ func BenchmarkParseThermalZoneFrom_NoMax(b *testing.B) {
 	for b.Loop() {
 		typeR := strings.NewReader(typeData)
 		tempR := strings.NewReader(tempData)
-		_, _ = parseThermalZoneFrom(typeR, tempR, nil)
+		_, _ = parseThermalZoneFrom(typeR, tempR, nil, 0)
 	}
 }
 
@@ -468,7 +525,7 @@ func BenchmarkReadThermalZone(b *testing.B) {
 	b.ReportAllocs()
 	b.ResetTimer()
 	for b.Loop() {
-		_, _ = readThermalZone(zone)
+		_, _ = readThermalZone(zone, 0)
 	}
 }
 
@@ -478,7 +535,7 @@ func BenchmarkMakeCollector(b *testing.B) {
 		b.Skip("No thermal zones available")
 	}
 
-	col := MakeCollector(zones)
+	col := MakeCollector(zones, 0)
 	ctx := context.Background()
 	b.ReportAllocs()
 	b.ResetTimer()
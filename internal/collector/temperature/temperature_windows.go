@@ -20,13 +20,19 @@ type MSAcpi_ThermalZoneTemperature struct {
 	InstanceName       string
 }
 
-// MakeCollector returns Collect unchanged on Windows.
-// Thermal zones are detected via WMI, not sysfs paths.
-func MakeCollector(_ []string) collector.CollectFunc {
-	return Collect
+// MakeCollector ignores zones on Windows; thermal zones are detected via
+// WMI, not sysfs paths. Trip points at or above maxBound are dropped.
+func MakeCollector(_ []string, maxBound float64) collector.CollectFunc {
+	return func(ctx context.Context) ([]protocol.Metric, error) {
+		return collect(ctx, maxBound)
+	}
 }
 
 func Collect(ctx context.Context) ([]protocol.Metric, error) {
+	return collect(ctx, util.DefaultMaxTempBound)
+}
+
+func collect(ctx context.Context, maxBound float64) ([]protocol.Metric, error) {
 	var dst []MSAcpi_ThermalZoneTemperature
 
 	q := wmi.CreateQuery(&dst, "")
@@ -45,7 +51,7 @@ func Collect(ctx context.Context) ([]protocol.Metric, error) {
 		var max *float64
 		if v.CriticalTripPoint > 0 {
 			raw := (float64(v.CriticalTripPoint) - 2732.0) / 10.0
-			max = util.NormalizeMax(celsius, raw)
+			max = util.NormalizeMax(celsius, raw, maxBound)
 		}
 
 		// Clean Name
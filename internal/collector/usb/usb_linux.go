@@ -0,0 +1,77 @@
+//go:build linux
+
+package usb
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const sysfsUSBDevices = "/sys/bus/usb/devices"
+
+// Collect reports the USB devices currently attached. It is a no-op when
+// the sysfs USB tree is absent.
+func Collect(ctx context.Context) ([]protocol.Metric, error) {
+	devices, err := parseUSBDevicesFrom(sysfsUSBDevices)
+	if err != nil || devices == nil {
+		return nil, err
+	}
+	return []protocol.Metric{protocol.USBDeviceListMetric{Devices: devices}}, nil
+}
+
+// parseUSBDevicesFrom reads every device under a sysfs usb/devices root.
+// Root hubs ("usbN") and interfaces ("1-1:1.0") are skipped, as are
+// entries without vendor and product IDs. It returns nil if root does not
+// exist.
+func parseUSBDevicesFrom(root string) ([]protocol.USBDeviceMetric, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	devices := []protocol.USBDeviceMetric{}
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, "usb") || strings.Contains(name, ":") {
+			continue
+		}
+
+		dir := filepath.Join(root, name)
+		vendor := readAttr(dir, "idVendor")
+		product := readAttr(dir, "idProduct")
+		if vendor == "" || product == "" {
+			continue
+		}
+
+		devices = append(devices, protocol.USBDeviceMetric{
+			Port:         name,
+			VendorID:     vendor,
+			ProductID:    product,
+			Manufacturer: readAttr(dir, "manufacturer"),
+			Product:      readAttr(dir, "product"),
+			Serial:       readAttr(dir, "serial"),
+		})
+	}
+
+	slices.SortFunc(devices, func(a, b protocol.USBDeviceMetric) int {
+		return strings.Compare(a.Port, b.Port)
+	})
+	return devices, nil
+}
+
+// readAttr returns a trimmed sysfs attribute, or "" if it can't be read.
+func readAttr(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,68 @@
+//go:build linux
+
+package usb
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func writeAttrs(t *testing.T, dir string, attrs map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, val := range attrs {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(val+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestParseUSBDevicesFrom(t *testing.T) {
+	root := t.TempDir()
+
+	writeAttrs(t, filepath.Join(root, "1-1.2"), map[string]string{
+		"idVendor":     "046d",
+		"idProduct":    "c52b",
+		"manufacturer": "Logitech",
+		"product":      "USB Receiver",
+	})
+	writeAttrs(t, filepath.Join(root, "1-1"), map[string]string{
+		"idVendor":     "0781",
+		"idProduct":    "5583",
+		"manufacturer": "SanDisk",
+		"product":      "Ultra Fit",
+		"serial":       "4C530001230101115091",
+	})
+	// Root hub and interface entries are not devices of interest
+	writeAttrs(t, filepath.Join(root, "usb1"), map[string]string{"idVendor": "1d6b", "idProduct": "0002"})
+	writeAttrs(t, filepath.Join(root, "1-1:1.0"), map[string]string{"bInterfaceClass": "08"})
+
+	got, err := parseUSBDevicesFrom(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []protocol.USBDeviceMetric{
+		{Port: "1-1", VendorID: "0781", ProductID: "5583", Manufacturer: "SanDisk", Product: "Ultra Fit", Serial: "4C530001230101115091"},
+		{Port: "1-1.2", VendorID: "046d", ProductID: "c52b", Manufacturer: "Logitech", Product: "USB Receiver"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseUSBDevicesFrom_NoSysfs(t *testing.T) {
+	got, err := parseUSBDevicesFrom(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != nil {
+		t.Errorf("expected nil for missing sysfs tree, got %+v", got)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package usb
+
+import (
+	"context"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// Collect is a no-op outside Linux.
+func Collect(ctx context.Context) ([]protocol.Metric, error) {
+	return nil, nil
+}
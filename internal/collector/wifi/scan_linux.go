@@ -0,0 +1,157 @@
+//go:build linux
+
+package wifi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// minScanInterval bounds how often CollectWiFiScan queries the driver,
+// whatever interval it is scheduled at.
+const minScanInterval = time.Minute
+
+var (
+	reBSS = regexp.MustCompile(`^BSS ([0-9a-fA-F:]{17})`)
+
+	scanMu   sync.Mutex
+	lastScan time.Time
+)
+
+// CollectWiFiScan reports the access points visible to each wireless
+// interface. It reads the driver's cached scan results (`iw dev <iface>
+// scan dump`) rather than triggering a scan, so the current link is not
+// disturbed. It is a no-op without iw or wireless interfaces, or when
+// called again within minScanInterval.
+func CollectWiFiScan(ctx context.Context) ([]protocol.Metric, error) {
+	scanMu.Lock()
+	if !lastScan.IsZero() && time.Since(lastScan) < minScanInterval {
+		scanMu.Unlock()
+		return nil, nil
+	}
+	lastScan = time.Now()
+	scanMu.Unlock()
+
+	iwPath, err := exec.LookPath("iw")
+	if err != nil {
+		return nil, nil
+	}
+
+	networks := []protocol.WiFiNetwork{}
+	for _, iface := range wirelessInterfaces("/sys/class/net") {
+		out, err := exec.CommandContext(ctx, iwPath, "dev", iface, "scan", "dump").Output()
+		if err != nil {
+			continue
+		}
+		found, err := parseIwScanFrom(bytes.NewReader(out), iface)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, found...)
+	}
+	if len(networks) == 0 {
+		return nil, nil
+	}
+
+	return []protocol.Metric{protocol.WiFiScanMetric{Networks: networks}}, nil
+}
+
+// wirelessInterfaces lists the interfaces under a sysfs class/net root that
+// have a wireless directory.
+func wirelessInterfaces(root string) []string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+
+	var ifaces []string
+	for _, e := range entries {
+		if _, err := os.Stat(filepath.Join(root, e.Name(), "wireless")); err == nil {
+			ifaces = append(ifaces, e.Name())
+		}
+	}
+	return ifaces
+}
+
+// parseIwScanFrom parses `iw dev <iface> scan` output into one entry per
+// BSS. Hidden networks are kept with an empty SSID.
+func parseIwScanFrom(r io.Reader, iface string) ([]protocol.WiFiNetwork, error) {
+	var networks []protocol.WiFiNetwork
+	var cur *protocol.WiFiNetwork
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if m := reBSS.FindStringSubmatch(line); m != nil {
+			networks = append(networks, protocol.WiFiNetwork{
+				Interface:  iface,
+				BSSID:      strings.ToLower(m[1]),
+				Associated: strings.HasSuffix(line, "-- associated"),
+			})
+			cur = &networks[len(networks)-1]
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+
+		// Only the BSS's own top-level attributes are one tab deep
+		if !strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "\t\t") {
+			continue
+		}
+		key, val, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		val = strings.TrimSpace(val)
+
+		switch key {
+		case "SSID":
+			cur.SSID = val
+		case "freq":
+			// Newer iw prints fractional MHz ("5180.0")
+			if mhz, err := strconv.ParseFloat(val, 64); err == nil {
+				cur.Frequency = mhzToGHz(mhz)
+				cur.Channel = freqToChannel(int(mhz))
+			}
+		case "signal":
+			// "-52.00 dBm"
+			if f := strings.Fields(val); len(f) > 0 {
+				if dbm, err := strconv.ParseFloat(f[0], 64); err == nil {
+					cur.SignalLevel = int(dbm)
+				}
+			}
+		}
+	}
+
+	return networks, scanner.Err()
+}
+
+// freqToChannel converts a centre frequency in MHz to its 802.11 channel
+// number, or 0 if the frequency is outside the 2.4, 5 and 6 GHz bands.
+func freqToChannel(mhz int) int {
+	switch {
+	case mhz == 2484:
+		return 14
+	case mhz >= 2412 && mhz <= 2472:
+		return (mhz - 2407) / 5
+	case mhz >= 5955 && mhz <= 7115:
+		return (mhz - 5950) / 5
+	case mhz >= 5000 && mhz <= 5925:
+		return (mhz - 5000) / 5
+	}
+	return 0
+}
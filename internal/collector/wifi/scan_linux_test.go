@@ -0,0 +1,99 @@
+//go:build linux
+
+package wifi
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const iwScanSample = `BSS 11:22:33:44:55:66(on wlan0) -- associated
+	last seen: 120.480s [boottime]
+	TSF: 1234567890 usec (0d, 00:20:34)
+	freq: 5180
+	beacon interval: 100 TUs
+	capability: ESS Privacy SpectrumMgmt (0x0111)
+	signal: -52.00 dBm
+	last seen: 40 ms ago
+	Information elements from Probe Response frame:
+	SSID: HomeNet
+	Supported rates: 6.0* 9.0 12.0* 18.0 24.0* 36.0 48.0 54.0
+	HT operation:
+		 * primary channel: 36
+		 * secondary channel offset: above
+BSS AA:BB:CC:DD:EE:FF(on wlan0)
+	freq: 2437.0
+	signal: -71.00 dBm
+	SSID: Neighbor 2.4
+	DS Parameter set: channel 6
+BSS 02:00:00:00:00:01(on wlan0)
+	freq: 5955
+	signal: -80.00 dBm
+	SSID: 
+`
+
+func TestParseIwScanFrom(t *testing.T) {
+	got, err := parseIwScanFrom(strings.NewReader(iwScanSample), "wlan0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []protocol.WiFiNetwork{
+		{Interface: "wlan0", BSSID: "11:22:33:44:55:66", SSID: "HomeNet", Frequency: 5.18, Channel: 36, SignalLevel: -52, Associated: true},
+		{Interface: "wlan0", BSSID: "aa:bb:cc:dd:ee:ff", SSID: "Neighbor 2.4", Frequency: 2.437, Channel: 6, SignalLevel: -71},
+		// Hidden 6 GHz network
+		{Interface: "wlan0", BSSID: "02:00:00:00:00:01", Frequency: 5.955, Channel: 1, SignalLevel: -80},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseIwScanFrom_Empty(t *testing.T) {
+	got, err := parseIwScanFrom(strings.NewReader(""), "wlan0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no networks, got %+v", got)
+	}
+}
+
+func TestFreqToChannel(t *testing.T) {
+	tests := map[int]int{
+		2412: 1,
+		2437: 6,
+		2472: 13,
+		2484: 14,
+		5180: 36,
+		5825: 165,
+		5955: 1,
+		6115: 33,
+		900:  0,
+	}
+	for mhz, want := range tests {
+		if got := freqToChannel(mhz); got != want {
+			t.Errorf("freqToChannel(%d) = %d, want %d", mhz, got, want)
+		}
+	}
+}
+
+func TestWirelessInterfaces(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"wlan0/wireless", "eth0", "wlp2s0/wireless"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := wirelessInterfaces(root)
+	want := []string{"wlan0", "wlp2s0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
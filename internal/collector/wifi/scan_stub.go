@@ -0,0 +1,14 @@
+//go:build !linux
+
+package wifi
+
+import (
+	"context"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// CollectWiFiScan is only implemented on Linux.
+func CollectWiFiScan(ctx context.Context) ([]protocol.Metric, error) {
+	return nil, nil
+}
@@ -0,0 +1,7 @@
+package wifi
+
+// mhzToGHz converts a radio frequency from MHz, as drivers and tools
+// report it, to GHz. WiFiMetric.Frequency is GHz on every platform.
+func mhzToGHz(mhz float64) float64 {
+	return mhz / 1000.0
+}
@@ -70,7 +70,7 @@ func collectWlanInterface(ctx context.Context, iface string) (protocol.Metric, e
 	if m := reChan.FindStringSubmatch(ifcfg); len(m) > 1 {
 		mhz, err := strconv.ParseFloat(m[1], 64)
 		if err == nil {
-			freq = mhz / 1000.0
+			freq = mhzToGHz(mhz)
 		}
 	}
 
@@ -4,6 +4,7 @@ package wifi
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -12,6 +13,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
@@ -20,7 +22,7 @@ type metadataFetcher func(ctx context.Context, iface string) (string, float64, f
 
 var (
 	reSSID    = regexp.MustCompile(`SSID: (.+)`)
-	reFreq    = regexp.MustCompile(`freq: (\d+)`)
+	reFreq    = regexp.MustCompile(`freq: (\d+(?:\.\d+)?)`)
 	reBitRate = regexp.MustCompile(`tx bitrate: ([\d.]+)`)
 )
 
@@ -41,9 +43,22 @@ func parseNetWireless(ctx context.Context, fetcher metadataFetcher) ([]protocol.
 	return parseNetWirelessFrom(ctx, f, fetcher)
 }
 
+// lineBufPool holds scanner buffers between reads of /proc/net/wireless.
+var lineBufPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 4096)
+		return &b
+	},
+}
+
 func parseNetWirelessFrom(ctx context.Context, r io.Reader, fetcher metadataFetcher) ([]protocol.Metric, error) {
 	var results []protocol.Metric
+
+	buf := lineBufPool.Get().(*[]byte)
+	defer lineBufPool.Put(buf)
+
 	scanner := bufio.NewScanner(r)
+	scanner.Buffer(*buf, bufio.MaxScanTokenSize)
 
 	for range 2 {
 		if !scanner.Scan() {
@@ -51,27 +66,28 @@ func parseNetWirelessFrom(ctx context.Context, r io.Reader, fetcher metadataFetc
 		}
 	}
 
+	// Only the first four fields are used
+	var fields [4][]byte
 	for scanner.Scan() {
-		fields := strings.Fields(scanner.Text())
-		if len(fields) < 4 {
+		if splitFields(scanner.Bytes(), fields[:]) < len(fields) {
 			continue
 		}
 
-		// Field 0: "wlan0:" -> "wlan0"
-		iface := strings.TrimSuffix(fields[0], ":")
-
 		// Field 2: Link Quality
-		linkQual, err := parseFloat(fields[2])
+		linkQual, err := parseFloat(string(fields[2]))
 		if err != nil {
 			return nil, err
 		}
 
 		// Field 3: Signal Level (dBm)
-		sigLevel, err := parseFloat(fields[3])
+		sigLevel, err := parseFloat(string(fields[3]))
 		if err != nil {
 			return nil, err
 		}
 
+		// Field 0: "wlan0:" -> "wlan0"
+		iface := string(bytes.TrimSuffix(fields[0], []byte(":")))
+
 		ssid, freq, bitrate := fetcher(ctx, iface)
 
 		if ssid == "" {
@@ -93,36 +109,65 @@ func parseNetWirelessFrom(ctx context.Context, r io.Reader, fetcher metadataFetc
 	return results, scanner.Err()
 }
 
+// splitFields fills dst with the leading whitespace-separated fields of
+// line, like strings.Fields without allocating, and returns how many it
+// found. The fields alias line.
+func splitFields(line []byte, dst [][]byte) int {
+	n := 0
+	for i := 0; i < len(line) && n < len(dst); {
+		for i < len(line) && isSpace(line[i]) {
+			i++
+		}
+		start := i
+		for i < len(line) && !isSpace(line[i]) {
+			i++
+		}
+		if i > start {
+			dst[n] = line[start:i]
+			n++
+		}
+	}
+	return n
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	}
+	return false
+}
+
 // parseFloat strips trailing dots before parsing
 func parseFloat(s string) (float64, error) {
 	return strconv.ParseFloat(strings.TrimSuffix(s, "."), 64)
 }
 
-// getWiFiMetadata calls `iwgetid` to fetch SSID and Frequency
+// getWiFiMetadata runs `iw dev <iface> link` to fetch the SSID,
+// frequency (GHz) and bitrate (Mbps).
 func getWiFiMetadata(ctx context.Context, iface string) (ssid string, freq, bitrate float64) {
-	// iw dev <interface> link
 	out, err := exec.CommandContext(ctx, "iw", "dev", iface, "link").Output()
 	if err != nil {
 		return "", 0.0, 0.0
 	}
+	return parseIwLink(string(out))
+}
 
-	output := string(out)
-
-	// Parse SSID
+// parseIwLink extracts the SSID, frequency and bitrate from `iw dev
+// <iface> link` output. iw reports frequency in MHz; it is returned in GHz.
+func parseIwLink(output string) (ssid string, freq, bitrate float64) {
 	if match := reSSID.FindStringSubmatch(output); len(match) > 1 {
 		ssid = match[1]
 	}
 
-	// Parse frequency
 	if match := reFreq.FindStringSubmatch(output); len(match) > 1 {
 		val, err := strconv.ParseFloat(match[1], 64)
 		if err != nil {
 			return ssid, 0.0, 0.0
 		}
-		freq = val / 1000.0
+		freq = mhzToGHz(val)
 	}
 
-	// Parse Bitrate
 	if match := reBitRate.FindStringSubmatch(output); len(match) > 1 {
 		val, err := strconv.ParseFloat(match[1], 64)
 		if err != nil {
@@ -4,6 +4,7 @@ package wifi
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/exec"
 	"strings"
@@ -209,6 +210,37 @@ wlan0:    0000    60.    -50.    -256        0      0      0      0      0
 	}
 }
 
+func TestSplitFields(t *testing.T) {
+	lines := []string{
+		"  wlan0: 0000   60.  -50.  -256        0",
+		"wlan0:\t0000\t60.\t-50.",
+		"  wlan0: 0000   60.",
+		"",
+		"   ",
+		"a b c d e f",
+	}
+
+	for _, line := range lines {
+		t.Run(line, func(t *testing.T) {
+			want := strings.Fields(line)
+			if len(want) > 4 {
+				want = want[:4]
+			}
+
+			var dst [4][]byte
+			n := splitFields([]byte(line), dst[:])
+			if n != len(want) {
+				t.Fatalf("got %d fields, want %d", n, len(want))
+			}
+			for i := range n {
+				if string(dst[i]) != want[i] {
+					t.Errorf("field %d = %q, want %q", i, dst[i], want[i])
+				}
+			}
+		})
+	}
+}
+
 func TestParseFloat(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -276,6 +308,7 @@ func TestRegexPatterns(t *testing.T) {
 		}{
 			{"freq: 2437", "2437"},
 			{"freq: 5180", "5180"},
+			{"freq: 5180.0", "5180.0"},
 			{"frequency: 2437", ""}, // Wrong prefix
 		}
 
@@ -315,6 +348,51 @@ func TestRegexPatterns(t *testing.T) {
 	})
 }
 
+func TestParseIwLink(t *testing.T) {
+	// The mock fetchers above return GHz; the real parser must agree
+	tests := []struct {
+		name        string
+		output      string
+		wantSSID    string
+		wantFreq    float64
+		wantBitRate float64
+	}{
+		{
+			name: "5 GHz",
+			output: `Connected to 11:22:33:44:55:66 (on wlan0)
+	SSID: TestNetwork
+	freq: 5180
+	RX: 123456 bytes (789 packets)
+	signal: -50 dBm
+	tx bitrate: 866.7 MBit/s VHT-MCS 9 80MHz short GI VHT-NSS 2
+`,
+			wantSSID:    "TestNetwork",
+			wantFreq:    5.18,
+			wantBitRate: 866.7,
+		},
+		{
+			name:     "2.4 GHz fractional MHz",
+			output:   "Connected to aa:bb:cc:dd:ee:ff (on wlan0)\n\tSSID: Cafe\n\tfreq: 2437.0\n",
+			wantSSID: "Cafe",
+			wantFreq: 2.437,
+		},
+		{
+			name:   "Not connected",
+			output: "Not connected.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ssid, freq, bitrate := parseIwLink(tt.output)
+			if ssid != tt.wantSSID || freq != tt.wantFreq || bitrate != tt.wantBitRate {
+				t.Errorf("got %q, %v GHz, %v Mbps; want %q, %v GHz, %v Mbps",
+					ssid, freq, bitrate, tt.wantSSID, tt.wantFreq, tt.wantBitRate)
+			}
+		})
+	}
+}
+
 func TestCollect_Integration(t *testing.T) {
 	// Check if /proc/net/wireless exists
 	if _, err := os.Stat("/proc/net/wireless"); os.IsNotExist(err) {
@@ -461,6 +539,28 @@ func BenchmarkParseNetWirelessFrom_MultipleInterfaces(b *testing.B) {
 	}
 }
 
+// BenchmarkParseNetWirelessFrom_ManyInterfaces shows the per-interface
+// cost: the interface name, the boxed metric and result growth.
+func BenchmarkParseNetWirelessFrom_ManyInterfaces(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString("Inter-| sta-|   Quality        |   Discarded packets               | Missed | WE\n")
+	sb.WriteString(" face | tus | link level noise |  nwid  crypt   frag  retry   misc | beacon | 22\n")
+	for i := range 16 {
+		fmt.Fprintf(&sb, "  wlan%d: 0000   60.  -50.  -256        0      0      0      0      0        0\n", i)
+	}
+	input := sb.String()
+
+	mockFetcher := func(ctx context.Context, iface string) (string, float64, float64) {
+		return "Network", 5.0, 100.0
+	}
+
+	ctx := context.Background()
+	b.ReportAllocs()
+	for b.Loop() {
+		_, _ = parseNetWirelessFrom(ctx, strings.NewReader(input), mockFetcher)
+	}
+}
+
 func BenchmarkCollect(b *testing.B) {
 	if _, err := os.Stat("/proc/net/wireless"); os.IsNotExist(err) {
 		b.Skip("/proc/net/wireless not available")
@@ -188,5 +188,5 @@ func channelToFrequency(channel uint32) float64 {
 	default:
 		return 0.0
 	}
-	return float64(mhz) / 1000.0
+	return mhzToGHz(float64(mhz))
 }
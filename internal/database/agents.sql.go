@@ -32,7 +32,7 @@ func (q *Queries) DeleteAgent(ctx context.Context, id pgtype.UUID) error {
 }
 
 const getAgent = `-- name: GetAgent :one
-SELECT id, secret_hash, hostname, os, platform, arch, cpu_model, cpu_cores, ram_total, registered_at, last_seen, ip_address
+SELECT id, secret_hash, hostname, os, platform, arch, cpu_model, cpu_cores, ram_total, registered_at, last_seen, ip_address, version, kernel, tags
 FROM agents WHERE id = $1
 `
 
@@ -49,6 +49,9 @@ type GetAgentRow struct {
 	RegisteredAt pgtype.Timestamptz `json:"registered_at"`
 	LastSeen     pgtype.Timestamptz `json:"last_seen"`
 	IpAddress    pgtype.Text        `json:"ip_address"`
+	Version      string             `json:"version"`
+	Kernel       string             `json:"kernel"`
+	Tags         []string           `json:"tags"`
 }
 
 func (q *Queries) GetAgent(ctx context.Context, id pgtype.UUID) (GetAgentRow, error) {
@@ -67,6 +70,9 @@ func (q *Queries) GetAgent(ctx context.Context, id pgtype.UUID) (GetAgentRow, er
 		&i.RegisteredAt,
 		&i.LastSeen,
 		&i.IpAddress,
+		&i.Version,
+		&i.Kernel,
+		&i.Tags,
 	)
 	return i, err
 }
@@ -94,7 +100,7 @@ func (q *Queries) GetAgentSecretSHA256(ctx context.Context, id pgtype.UUID) ([]b
 }
 
 const listAgents = `-- name: ListAgents :many
-SELECT id, hostname, os, platform, arch, cpu_cores, ram_total, registered_at, last_seen
+SELECT id, hostname, os, platform, arch, cpu_cores, ram_total, registered_at, last_seen, tags
 FROM agents
 ORDER BY hostname
 `
@@ -109,6 +115,7 @@ type ListAgentsRow struct {
 	RamTotal     pgtype.Int8        `json:"ram_total"`
 	RegisteredAt pgtype.Timestamptz `json:"registered_at"`
 	LastSeen     pgtype.Timestamptz `json:"last_seen"`
+	Tags         []string           `json:"tags"`
 }
 
 func (q *Queries) ListAgents(ctx context.Context) ([]ListAgentsRow, error) {
@@ -130,6 +137,7 @@ func (q *Queries) ListAgents(ctx context.Context) ([]ListAgentsRow, error) {
 			&i.RamTotal,
 			&i.RegisteredAt,
 			&i.LastSeen,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -156,8 +164,13 @@ func (q *Queries) PurgeOfflineAgents(ctx context.Context) (int64, error) {
 }
 
 const registerAgent = `-- name: RegisterAgent :exec
-INSERT INTO agents (id, secret_hash, secret_sha256, hostname, os, platform, arch, cpu_model, cpu_cores, ram_total, ip_address, version)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+INSERT INTO agents (id, secret_hash, secret_sha256, hostname, os, platform, arch, cpu_model, cpu_cores, ram_total, ip_address, version, kernel, tags)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (id)
+DO UPDATE SET secret_hash = EXCLUDED.secret_hash, secret_sha256 = EXCLUDED.secret_sha256, hostname = EXCLUDED.hostname,
+    os = EXCLUDED.os, platform = EXCLUDED.platform, arch = EXCLUDED.arch, cpu_model = EXCLUDED.cpu_model,
+    cpu_cores = EXCLUDED.cpu_cores, ram_total = EXCLUDED.ram_total, ip_address = EXCLUDED.ip_address,
+    version = EXCLUDED.version, kernel = EXCLUDED.kernel, tags = EXCLUDED.tags, last_seen = NOW()
 `
 
 type RegisterAgentParams struct {
@@ -173,6 +186,8 @@ type RegisterAgentParams struct {
 	RamTotal     pgtype.Int8 `json:"ram_total"`
 	IpAddress    pgtype.Text `json:"ip_address"`
 	Version      string      `json:"version"`
+	Kernel       string      `json:"kernel"`
+	Tags         []string    `json:"tags"`
 }
 
 func (q *Queries) RegisterAgent(ctx context.Context, arg RegisterAgentParams) error {
@@ -189,6 +204,8 @@ func (q *Queries) RegisterAgent(ctx context.Context, arg RegisterAgentParams) er
 		arg.RamTotal,
 		arg.IpAddress,
 		arg.Version,
+		arg.Kernel,
+		arg.Tags,
 	)
 	return err
 }
@@ -92,6 +92,38 @@ func (q *Queries) GetApplications(ctx context.Context, agentID pgtype.UUID) ([]C
 	return items, nil
 }
 
+const getInventory = `-- name: GetInventory :many
+SELECT agent_id, kind, data, updated_at
+FROM current_inventory
+WHERE agent_id = $1
+ORDER BY kind
+`
+
+func (q *Queries) GetInventory(ctx context.Context, agentID pgtype.UUID) ([]CurrentInventory, error) {
+	rows, err := q.db.Query(ctx, getInventory, agentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CurrentInventory{}
+	for rows.Next() {
+		var i CurrentInventory
+		if err := rows.Scan(
+			&i.AgentID,
+			&i.Kind,
+			&i.Data,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getProcessesByCPU = `-- name: GetProcessesByCPU :many
 SELECT agent_id, pid, name, cpu_percent, mem_percent, mem_rss, status, threads, updated_at
 FROM current_processes
@@ -250,6 +282,25 @@ func (q *Queries) UpsertApplication(ctx context.Context, arg UpsertApplicationPa
 	return err
 }
 
+const upsertInventory = `-- name: UpsertInventory :exec
+INSERT INTO current_inventory (agent_id, kind, data, updated_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (agent_id, kind) DO UPDATE
+SET data = EXCLUDED.data,
+    updated_at = NOW()
+`
+
+type UpsertInventoryParams struct {
+	AgentID pgtype.UUID `json:"agent_id"`
+	Kind    string      `json:"kind"`
+	Data    []byte      `json:"data"`
+}
+
+func (q *Queries) UpsertInventory(ctx context.Context, arg UpsertInventoryParams) error {
+	_, err := q.db.Exec(ctx, upsertInventory, arg.AgentID, arg.Kind, arg.Data)
+	return err
+}
+
 const upsertProcess = `-- name: UpsertProcess :exec
 INSERT INTO current_processes (agent_id, pid, name, cpu_percent, mem_percent, mem_rss, status, threads, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
@@ -16,20 +16,21 @@ const getCPUBucketed = `-- name: GetCPUBucketed :many
 SELECT
     time_bucket(($1)::text::interval, time)::timestamptz AS time,
     agent_id,
-    AVG(usage)::float8 AS usage,
+    CASE $2::text WHEN 'min' THEN MIN(usage) WHEN 'max' THEN MAX(usage) ELSE AVG(usage) END::float8 AS usage,
     NULL::float8[] AS core_usages,
-    AVG(load_1m)::float8 AS load_1m,
-    AVG(load_5m)::float8 AS load_5m,
-    AVG(load_15m)::float8 AS load_15m,
-    AVG(iowait)::float8 AS iowait
+    CASE $2::text WHEN 'min' THEN MIN(load_1m) WHEN 'max' THEN MAX(load_1m) ELSE AVG(load_1m) END::float8 AS load_1m,
+    CASE $2::text WHEN 'min' THEN MIN(load_5m) WHEN 'max' THEN MAX(load_5m) ELSE AVG(load_5m) END::float8 AS load_5m,
+    CASE $2::text WHEN 'min' THEN MIN(load_15m) WHEN 'max' THEN MAX(load_15m) ELSE AVG(load_15m) END::float8 AS load_15m,
+    CASE $2::text WHEN 'min' THEN MIN(iowait) WHEN 'max' THEN MAX(iowait) ELSE AVG(iowait) END::float8 AS iowait
 FROM metrics_cpu
-WHERE agent_id = $2 AND time >= $3 AND time <= $4
+WHERE agent_id = $3 AND time >= $4 AND time <= $5
 GROUP BY 1, 2
 ORDER BY 1 ASC
 `
 
 type GetCPUBucketedParams struct {
 	BucketInterval string             `json:"bucket_interval"`
+	Aggregate      string             `json:"aggregate"`
 	AgentID        pgtype.UUID        `json:"agent_id"`
 	StartTime      pgtype.Timestamptz `json:"start_time"`
 	EndTime        pgtype.Timestamptz `json:"end_time"`
@@ -47,8 +48,9 @@ type GetCPUBucketedRow struct {
 }
 
 // Bucketed metric queries using TimescaleDB time_bucket.
-// The interval parameter controls aggregation granularity.
-// Handlers pick the bucket size based on the time range:
+// The interval parameter controls aggregation granularity and the
+// aggregate parameter selects avg (default), min or max per bucket.
+// Without ?resolution= handlers pick the bucket size from the time range:
 //
 //	<= 1h: no bucketing
 //	<= 6h: 1m
@@ -58,6 +60,7 @@ type GetCPUBucketedRow struct {
 func (q *Queries) GetCPUBucketed(ctx context.Context, arg GetCPUBucketedParams) ([]GetCPUBucketedRow, error) {
 	rows, err := q.db.Query(ctx, getCPUBucketed,
 		arg.BucketInterval,
+		arg.Aggregate,
 		arg.AgentID,
 		arg.StartTime,
 		arg.EndTime,
@@ -99,20 +102,21 @@ SELECT
     MAX(state)::text AS state,
     MAX(source)::text AS source,
     MAX(kind)::text AS kind,
-    AVG(cpu_percent)::float8 AS cpu_percent,
-    AVG(cpu_cores)::float8 AS cpu_cores,
-    AVG(memory_bytes)::float8 AS memory_bytes,
-    AVG(memory_limit)::float8 AS memory_limit,
-    AVG(net_rx_bytes)::float8 AS net_rx_bytes,
-    AVG(net_tx_bytes)::float8 AS net_tx_bytes
+    CASE $2::text WHEN 'min' THEN MIN(cpu_percent) WHEN 'max' THEN MAX(cpu_percent) ELSE AVG(cpu_percent) END::float8 AS cpu_percent,
+    CASE $2::text WHEN 'min' THEN MIN(cpu_cores) WHEN 'max' THEN MAX(cpu_cores) ELSE AVG(cpu_cores) END::float8 AS cpu_cores,
+    CASE $2::text WHEN 'min' THEN MIN(memory_bytes) WHEN 'max' THEN MAX(memory_bytes) ELSE AVG(memory_bytes) END::float8 AS memory_bytes,
+    CASE $2::text WHEN 'min' THEN MIN(memory_limit) WHEN 'max' THEN MAX(memory_limit) ELSE AVG(memory_limit) END::float8 AS memory_limit,
+    CASE $2::text WHEN 'min' THEN MIN(net_rx_bytes) WHEN 'max' THEN MAX(net_rx_bytes) ELSE AVG(net_rx_bytes) END::float8 AS net_rx_bytes,
+    CASE $2::text WHEN 'min' THEN MIN(net_tx_bytes) WHEN 'max' THEN MAX(net_tx_bytes) ELSE AVG(net_tx_bytes) END::float8 AS net_tx_bytes
 FROM metrics_container
-WHERE agent_id = $2 AND time >= $3 AND time <= $4
+WHERE agent_id = $3 AND time >= $4 AND time <= $5
 GROUP BY 1, 2, 3
 ORDER BY 1 ASC
 `
 
 type GetContainerBucketedParams struct {
 	BucketInterval string             `json:"bucket_interval"`
+	Aggregate      string             `json:"aggregate"`
 	AgentID        pgtype.UUID        `json:"agent_id"`
 	StartTime      pgtype.Timestamptz `json:"start_time"`
 	EndTime        pgtype.Timestamptz `json:"end_time"`
@@ -138,6 +142,7 @@ type GetContainerBucketedRow struct {
 func (q *Queries) GetContainerBucketed(ctx context.Context, arg GetContainerBucketedParams) ([]GetContainerBucketedRow, error) {
 	rows, err := q.db.Query(ctx, getContainerBucketed,
 		arg.BucketInterval,
+		arg.Aggregate,
 		arg.AgentID,
 		arg.StartTime,
 		arg.EndTime,
@@ -183,21 +188,22 @@ SELECT
     mountpoint,
     filesystem,
     disk_type,
-    AVG(total_bytes)::float8 AS total_bytes,
-    AVG(used_bytes)::float8 AS used_bytes,
-    AVG(free_bytes)::float8 AS free_bytes,
-    AVG(used_percent)::float8 AS used_percent,
-    AVG(inodes_total)::float8 AS inodes_total,
-    AVG(inodes_used)::float8 AS inodes_used,
-    AVG(inodes_percent)::float8 AS inodes_percent
+    CASE $2::text WHEN 'min' THEN MIN(total_bytes) WHEN 'max' THEN MAX(total_bytes) ELSE AVG(total_bytes) END::float8 AS total_bytes,
+    CASE $2::text WHEN 'min' THEN MIN(used_bytes) WHEN 'max' THEN MAX(used_bytes) ELSE AVG(used_bytes) END::float8 AS used_bytes,
+    CASE $2::text WHEN 'min' THEN MIN(free_bytes) WHEN 'max' THEN MAX(free_bytes) ELSE AVG(free_bytes) END::float8 AS free_bytes,
+    CASE $2::text WHEN 'min' THEN MIN(used_percent) WHEN 'max' THEN MAX(used_percent) ELSE AVG(used_percent) END::float8 AS used_percent,
+    CASE $2::text WHEN 'min' THEN MIN(inodes_total) WHEN 'max' THEN MAX(inodes_total) ELSE AVG(inodes_total) END::float8 AS inodes_total,
+    CASE $2::text WHEN 'min' THEN MIN(inodes_used) WHEN 'max' THEN MAX(inodes_used) ELSE AVG(inodes_used) END::float8 AS inodes_used,
+    CASE $2::text WHEN 'min' THEN MIN(inodes_percent) WHEN 'max' THEN MAX(inodes_percent) ELSE AVG(inodes_percent) END::float8 AS inodes_percent
 FROM metrics_disk
-WHERE agent_id = $2 AND time >= $3 AND time <= $4
+WHERE agent_id = $3 AND time >= $4 AND time <= $5
 GROUP BY 1, 2, 3, 4, 5, 6
 ORDER BY 1 ASC
 `
 
 type GetDiskBucketedParams struct {
 	BucketInterval string             `json:"bucket_interval"`
+	Aggregate      string             `json:"aggregate"`
 	AgentID        pgtype.UUID        `json:"agent_id"`
 	StartTime      pgtype.Timestamptz `json:"start_time"`
 	EndTime        pgtype.Timestamptz `json:"end_time"`
@@ -222,6 +228,7 @@ type GetDiskBucketedRow struct {
 func (q *Queries) GetDiskBucketed(ctx context.Context, arg GetDiskBucketedParams) ([]GetDiskBucketedRow, error) {
 	rows, err := q.db.Query(ctx, getDiskBucketed,
 		arg.BucketInterval,
+		arg.Aggregate,
 		arg.AgentID,
 		arg.StartTime,
 		arg.EndTime,
@@ -263,21 +270,22 @@ SELECT
     time_bucket(($1)::text::interval, time)::timestamptz AS time,
     agent_id,
     device,
-    AVG(read_bytes)::float8 AS read_bytes,
-    AVG(write_bytes)::float8 AS write_bytes,
-    AVG(read_ops)::float8 AS read_ops,
-    AVG(write_ops)::float8 AS write_ops,
-    AVG(read_latency)::float8 AS read_latency,
-    AVG(write_latency)::float8 AS write_latency,
-    AVG(io_in_progress)::float8 AS io_in_progress
+    CASE $2::text WHEN 'min' THEN MIN(read_bytes) WHEN 'max' THEN MAX(read_bytes) ELSE AVG(read_bytes) END::float8 AS read_bytes,
+    CASE $2::text WHEN 'min' THEN MIN(write_bytes) WHEN 'max' THEN MAX(write_bytes) ELSE AVG(write_bytes) END::float8 AS write_bytes,
+    CASE $2::text WHEN 'min' THEN MIN(read_ops) WHEN 'max' THEN MAX(read_ops) ELSE AVG(read_ops) END::float8 AS read_ops,
+    CASE $2::text WHEN 'min' THEN MIN(write_ops) WHEN 'max' THEN MAX(write_ops) ELSE AVG(write_ops) END::float8 AS write_ops,
+    CASE $2::text WHEN 'min' THEN MIN(read_latency) WHEN 'max' THEN MAX(read_latency) ELSE AVG(read_latency) END::float8 AS read_latency,
+    CASE $2::text WHEN 'min' THEN MIN(write_latency) WHEN 'max' THEN MAX(write_latency) ELSE AVG(write_latency) END::float8 AS write_latency,
+    CASE $2::text WHEN 'min' THEN MIN(io_in_progress) WHEN 'max' THEN MAX(io_in_progress) ELSE AVG(io_in_progress) END::float8 AS io_in_progress
 FROM metrics_disk_io
-WHERE agent_id = $2 AND time >= $3 AND time <= $4
+WHERE agent_id = $3 AND time >= $4 AND time <= $5
 GROUP BY 1, 2, 3
 ORDER BY 1 ASC
 `
 
 type GetDiskIOBucketedParams struct {
 	BucketInterval string             `json:"bucket_interval"`
+	Aggregate      string             `json:"aggregate"`
 	AgentID        pgtype.UUID        `json:"agent_id"`
 	StartTime      pgtype.Timestamptz `json:"start_time"`
 	EndTime        pgtype.Timestamptz `json:"end_time"`
@@ -299,6 +307,7 @@ type GetDiskIOBucketedRow struct {
 func (q *Queries) GetDiskIOBucketed(ctx context.Context, arg GetDiskIOBucketedParams) ([]GetDiskIOBucketedRow, error) {
 	rows, err := q.db.Query(ctx, getDiskIOBucketed,
 		arg.BucketInterval,
+		arg.Aggregate,
 		arg.AgentID,
 		arg.StartTime,
 		arg.EndTime,
@@ -336,21 +345,22 @@ const getMemoryBucketed = `-- name: GetMemoryBucketed :many
 SELECT
     time_bucket(($1)::text::interval, time)::timestamptz AS time,
     agent_id,
-    AVG(ram_total)::float8 AS ram_total,
-    AVG(ram_used)::float8 AS ram_used,
-    AVG(ram_available)::float8 AS ram_available,
-    AVG(ram_percent)::float8 AS ram_percent,
-    AVG(swap_total)::float8 AS swap_total,
-    AVG(swap_used)::float8 AS swap_used,
-    AVG(swap_percent)::float8 AS swap_percent
+    CASE $2::text WHEN 'min' THEN MIN(ram_total) WHEN 'max' THEN MAX(ram_total) ELSE AVG(ram_total) END::float8 AS ram_total,
+    CASE $2::text WHEN 'min' THEN MIN(ram_used) WHEN 'max' THEN MAX(ram_used) ELSE AVG(ram_used) END::float8 AS ram_used,
+    CASE $2::text WHEN 'min' THEN MIN(ram_available) WHEN 'max' THEN MAX(ram_available) ELSE AVG(ram_available) END::float8 AS ram_available,
+    CASE $2::text WHEN 'min' THEN MIN(ram_percent) WHEN 'max' THEN MAX(ram_percent) ELSE AVG(ram_percent) END::float8 AS ram_percent,
+    CASE $2::text WHEN 'min' THEN MIN(swap_total) WHEN 'max' THEN MAX(swap_total) ELSE AVG(swap_total) END::float8 AS swap_total,
+    CASE $2::text WHEN 'min' THEN MIN(swap_used) WHEN 'max' THEN MAX(swap_used) ELSE AVG(swap_used) END::float8 AS swap_used,
+    CASE $2::text WHEN 'min' THEN MIN(swap_percent) WHEN 'max' THEN MAX(swap_percent) ELSE AVG(swap_percent) END::float8 AS swap_percent
 FROM metrics_memory
-WHERE agent_id = $2 AND time >= $3 AND time <= $4
+WHERE agent_id = $3 AND time >= $4 AND time <= $5
 GROUP BY 1, 2
 ORDER BY 1 ASC
 `
 
 type GetMemoryBucketedParams struct {
 	BucketInterval string             `json:"bucket_interval"`
+	Aggregate      string             `json:"aggregate"`
 	AgentID        pgtype.UUID        `json:"agent_id"`
 	StartTime      pgtype.Timestamptz `json:"start_time"`
 	EndTime        pgtype.Timestamptz `json:"end_time"`
@@ -371,6 +381,7 @@ type GetMemoryBucketedRow struct {
 func (q *Queries) GetMemoryBucketed(ctx context.Context, arg GetMemoryBucketedParams) ([]GetMemoryBucketedRow, error) {
 	rows, err := q.db.Query(ctx, getMemoryBucketed,
 		arg.BucketInterval,
+		arg.Aggregate,
 		arg.AgentID,
 		arg.StartTime,
 		arg.EndTime,
@@ -411,22 +422,23 @@ SELECT
     MAX(mac)::text AS mac,
     MAX(mtu)::bigint AS mtu,
     MAX(speed)::bigint AS speed,
-    AVG(rx_bytes)::float8 AS rx_bytes,
-    AVG(rx_packets)::float8 AS rx_packets,
-    AVG(rx_errors)::float8 AS rx_errors,
-    AVG(rx_drops)::float8 AS rx_drops,
-    AVG(tx_bytes)::float8 AS tx_bytes,
-    AVG(tx_packets)::float8 AS tx_packets,
-    AVG(tx_errors)::float8 AS tx_errors,
-    AVG(tx_drops)::float8 AS tx_drops
+    CASE $2::text WHEN 'min' THEN MIN(rx_bytes) WHEN 'max' THEN MAX(rx_bytes) ELSE AVG(rx_bytes) END::float8 AS rx_bytes,
+    CASE $2::text WHEN 'min' THEN MIN(rx_packets) WHEN 'max' THEN MAX(rx_packets) ELSE AVG(rx_packets) END::float8 AS rx_packets,
+    CASE $2::text WHEN 'min' THEN MIN(rx_errors) WHEN 'max' THEN MAX(rx_errors) ELSE AVG(rx_errors) END::float8 AS rx_errors,
+    CASE $2::text WHEN 'min' THEN MIN(rx_drops) WHEN 'max' THEN MAX(rx_drops) ELSE AVG(rx_drops) END::float8 AS rx_drops,
+    CASE $2::text WHEN 'min' THEN MIN(tx_bytes) WHEN 'max' THEN MAX(tx_bytes) ELSE AVG(tx_bytes) END::float8 AS tx_bytes,
+    CASE $2::text WHEN 'min' THEN MIN(tx_packets) WHEN 'max' THEN MAX(tx_packets) ELSE AVG(tx_packets) END::float8 AS tx_packets,
+    CASE $2::text WHEN 'min' THEN MIN(tx_errors) WHEN 'max' THEN MAX(tx_errors) ELSE AVG(tx_errors) END::float8 AS tx_errors,
+    CASE $2::text WHEN 'min' THEN MIN(tx_drops) WHEN 'max' THEN MAX(tx_drops) ELSE AVG(tx_drops) END::float8 AS tx_drops
 FROM metrics_network
-WHERE agent_id = $2 AND time >= $3 AND time <= $4
+WHERE agent_id = $3 AND time >= $4 AND time <= $5
 GROUP BY 1, 2, 3
 ORDER BY 1 ASC
 `
 
 type GetNetworkBucketedParams struct {
 	BucketInterval string             `json:"bucket_interval"`
+	Aggregate      string             `json:"aggregate"`
 	AgentID        pgtype.UUID        `json:"agent_id"`
 	StartTime      pgtype.Timestamptz `json:"start_time"`
 	EndTime        pgtype.Timestamptz `json:"end_time"`
@@ -452,6 +464,7 @@ type GetNetworkBucketedRow struct {
 func (q *Queries) GetNetworkBucketed(ctx context.Context, arg GetNetworkBucketedParams) ([]GetNetworkBucketedRow, error) {
 	rows, err := q.db.Query(ctx, getNetworkBucketed,
 		arg.BucketInterval,
+		arg.Aggregate,
 		arg.AgentID,
 		arg.StartTime,
 		arg.EndTime,
@@ -494,13 +507,13 @@ SELECT
     time_bucket(($1)::text::interval, time)::timestamptz AS time,
     agent_id,
     MAX(metric_type)::text AS metric_type,
-    COALESCE(AVG(arm_freq_hz), 0)::float8 AS arm_freq_hz,
-    COALESCE(AVG(core_freq_hz), 0)::float8 AS core_freq_hz,
-    COALESCE(AVG(gpu_freq_hz), 0)::float8 AS gpu_freq_hz,
-    COALESCE(AVG(core_volts), 0)::float8 AS core_volts,
-    COALESCE(AVG(sdram_c_volts), 0)::float8 AS sdram_c_volts,
-    COALESCE(AVG(sdram_i_volts), 0)::float8 AS sdram_i_volts,
-    COALESCE(AVG(sdram_p_volts), 0)::float8 AS sdram_p_volts,
+    COALESCE(CASE $2::text WHEN 'min' THEN MIN(arm_freq_hz) WHEN 'max' THEN MAX(arm_freq_hz) ELSE AVG(arm_freq_hz) END, 0)::float8 AS arm_freq_hz,
+    COALESCE(CASE $2::text WHEN 'min' THEN MIN(core_freq_hz) WHEN 'max' THEN MAX(core_freq_hz) ELSE AVG(core_freq_hz) END, 0)::float8 AS core_freq_hz,
+    COALESCE(CASE $2::text WHEN 'min' THEN MIN(gpu_freq_hz) WHEN 'max' THEN MAX(gpu_freq_hz) ELSE AVG(gpu_freq_hz) END, 0)::float8 AS gpu_freq_hz,
+    COALESCE(CASE $2::text WHEN 'min' THEN MIN(core_volts) WHEN 'max' THEN MAX(core_volts) ELSE AVG(core_volts) END, 0)::float8 AS core_volts,
+    COALESCE(CASE $2::text WHEN 'min' THEN MIN(sdram_c_volts) WHEN 'max' THEN MAX(sdram_c_volts) ELSE AVG(sdram_c_volts) END, 0)::float8 AS sdram_c_volts,
+    COALESCE(CASE $2::text WHEN 'min' THEN MIN(sdram_i_volts) WHEN 'max' THEN MAX(sdram_i_volts) ELSE AVG(sdram_i_volts) END, 0)::float8 AS sdram_i_volts,
+    COALESCE(CASE $2::text WHEN 'min' THEN MIN(sdram_p_volts) WHEN 'max' THEN MAX(sdram_p_volts) ELSE AVG(sdram_p_volts) END, 0)::float8 AS sdram_p_volts,
     COALESCE(BOOL_OR(soft_temp_limit), false) AS soft_temp_limit,
     COALESCE(BOOL_OR(throttled), false) AS throttled,
     COALESCE(BOOL_OR(under_voltage), false) AS under_voltage,
@@ -509,17 +522,18 @@ SELECT
     COALESCE(BOOL_OR(freq_cap_occurred), false) AS freq_cap_occurred,
     COALESCE(BOOL_OR(throttled_occurred), false) AS throttled_occurred,
     COALESCE(BOOL_OR(soft_temp_limit_occurred), false) AS soft_temp_limit_occurred,
-    COALESCE(AVG(gpu_mem_total), 0)::float8 AS gpu_mem_total,
-    COALESCE(AVG(gpu_mem_used), 0)::float8 AS gpu_mem_used,
-    COALESCE(AVG(gpu_temp), 0)::float8 AS gpu_temp
+    COALESCE(CASE $2::text WHEN 'min' THEN MIN(gpu_mem_total) WHEN 'max' THEN MAX(gpu_mem_total) ELSE AVG(gpu_mem_total) END, 0)::float8 AS gpu_mem_total,
+    COALESCE(CASE $2::text WHEN 'min' THEN MIN(gpu_mem_used) WHEN 'max' THEN MAX(gpu_mem_used) ELSE AVG(gpu_mem_used) END, 0)::float8 AS gpu_mem_used,
+    COALESCE(CASE $2::text WHEN 'min' THEN MIN(gpu_temp) WHEN 'max' THEN MAX(gpu_temp) ELSE AVG(gpu_temp) END, 0)::float8 AS gpu_temp
 FROM metrics_pi
-WHERE agent_id = $2 AND time >= $3 AND time <= $4
+WHERE agent_id = $3 AND time >= $4 AND time <= $5
 GROUP BY 1, 2
 ORDER BY 1 ASC
 `
 
 type GetPiBucketedParams struct {
 	BucketInterval string             `json:"bucket_interval"`
+	Aggregate      string             `json:"aggregate"`
 	AgentID        pgtype.UUID        `json:"agent_id"`
 	StartTime      pgtype.Timestamptz `json:"start_time"`
 	EndTime        pgtype.Timestamptz `json:"end_time"`
@@ -552,6 +566,7 @@ type GetPiBucketedRow struct {
 func (q *Queries) GetPiBucketed(ctx context.Context, arg GetPiBucketedParams) ([]GetPiBucketedRow, error) {
 	rows, err := q.db.Query(ctx, getPiBucketed,
 		arg.BucketInterval,
+		arg.Aggregate,
 		arg.AgentID,
 		arg.StartTime,
 		arg.EndTime,
@@ -600,18 +615,19 @@ const getSystemBucketed = `-- name: GetSystemBucketed :many
 SELECT
     time_bucket(($1)::text::interval, time)::timestamptz AS time,
     agent_id,
-    AVG(uptime)::float8 AS uptime,
-    AVG(process_count)::float8 AS process_count,
-    AVG(user_count)::float8 AS user_count,
+    CASE $2::text WHEN 'min' THEN MIN(uptime) WHEN 'max' THEN MAX(uptime) ELSE AVG(uptime) END::float8 AS uptime,
+    CASE $2::text WHEN 'min' THEN MIN(process_count) WHEN 'max' THEN MAX(process_count) ELSE AVG(process_count) END::float8 AS process_count,
+    CASE $2::text WHEN 'min' THEN MIN(user_count) WHEN 'max' THEN MAX(user_count) ELSE AVG(user_count) END::float8 AS user_count,
     MAX(boot_time)::timestamptz AS boot_time
 FROM metrics_system
-WHERE agent_id = $2 AND time >= $3 AND time <= $4
+WHERE agent_id = $3 AND time >= $4 AND time <= $5
 GROUP BY 1, 2
 ORDER BY 1 ASC
 `
 
 type GetSystemBucketedParams struct {
 	BucketInterval string             `json:"bucket_interval"`
+	Aggregate      string             `json:"aggregate"`
 	AgentID        pgtype.UUID        `json:"agent_id"`
 	StartTime      pgtype.Timestamptz `json:"start_time"`
 	EndTime        pgtype.Timestamptz `json:"end_time"`
@@ -629,6 +645,7 @@ type GetSystemBucketedRow struct {
 func (q *Queries) GetSystemBucketed(ctx context.Context, arg GetSystemBucketedParams) ([]GetSystemBucketedRow, error) {
 	rows, err := q.db.Query(ctx, getSystemBucketed,
 		arg.BucketInterval,
+		arg.Aggregate,
 		arg.AgentID,
 		arg.StartTime,
 		arg.EndTime,
@@ -663,16 +680,17 @@ SELECT
     time_bucket(($1)::text::interval, time)::timestamptz AS time,
     agent_id,
     sensor,
-    AVG(temperature)::float8 AS temperature,
+    CASE $2::text WHEN 'min' THEN MIN(temperature) WHEN 'max' THEN MAX(temperature) ELSE AVG(temperature) END::float8 AS temperature,
     COALESCE(MAX(max_temp), 0)::float8 AS max_temp
 FROM metrics_temperature
-WHERE agent_id = $2 AND time >= $3 AND time <= $4
+WHERE agent_id = $3 AND time >= $4 AND time <= $5
 GROUP BY 1, 2, 3
 ORDER BY 1 ASC
 `
 
 type GetTemperatureBucketedParams struct {
 	BucketInterval string             `json:"bucket_interval"`
+	Aggregate      string             `json:"aggregate"`
 	AgentID        pgtype.UUID        `json:"agent_id"`
 	StartTime      pgtype.Timestamptz `json:"start_time"`
 	EndTime        pgtype.Timestamptz `json:"end_time"`
@@ -689,6 +707,7 @@ type GetTemperatureBucketedRow struct {
 func (q *Queries) GetTemperatureBucketed(ctx context.Context, arg GetTemperatureBucketedParams) ([]GetTemperatureBucketedRow, error) {
 	rows, err := q.db.Query(ctx, getTemperatureBucketed,
 		arg.BucketInterval,
+		arg.Aggregate,
 		arg.AgentID,
 		arg.StartTime,
 		arg.EndTime,
@@ -724,19 +743,20 @@ SELECT
     interface,
     MAX(ssid)::text AS ssid,
     MAX(bssid)::text AS bssid,
-    AVG(frequency_mhz)::float8 AS frequency_mhz,
-    AVG(signal_dbm)::float8 AS signal_dbm,
-    AVG(noise_dbm)::float8 AS noise_dbm,
-    AVG(bitrate_mbps)::float8 AS bitrate_mbps,
-    AVG(link_quality)::float8 AS link_quality
+    CASE $2::text WHEN 'min' THEN MIN(frequency_mhz) WHEN 'max' THEN MAX(frequency_mhz) ELSE AVG(frequency_mhz) END::float8 AS frequency_mhz,
+    CASE $2::text WHEN 'min' THEN MIN(signal_dbm) WHEN 'max' THEN MAX(signal_dbm) ELSE AVG(signal_dbm) END::float8 AS signal_dbm,
+    CASE $2::text WHEN 'min' THEN MIN(noise_dbm) WHEN 'max' THEN MAX(noise_dbm) ELSE AVG(noise_dbm) END::float8 AS noise_dbm,
+    CASE $2::text WHEN 'min' THEN MIN(bitrate_mbps) WHEN 'max' THEN MAX(bitrate_mbps) ELSE AVG(bitrate_mbps) END::float8 AS bitrate_mbps,
+    CASE $2::text WHEN 'min' THEN MIN(link_quality) WHEN 'max' THEN MAX(link_quality) ELSE AVG(link_quality) END::float8 AS link_quality
 FROM metrics_wifi
-WHERE agent_id = $2 AND time >= $3 AND time <= $4
+WHERE agent_id = $3 AND time >= $4 AND time <= $5
 GROUP BY 1, 2, 3
 ORDER BY 1 ASC
 `
 
 type GetWifiBucketedParams struct {
 	BucketInterval string             `json:"bucket_interval"`
+	Aggregate      string             `json:"aggregate"`
 	AgentID        pgtype.UUID        `json:"agent_id"`
 	StartTime      pgtype.Timestamptz `json:"start_time"`
 	EndTime        pgtype.Timestamptz `json:"end_time"`
@@ -758,6 +778,7 @@ type GetWifiBucketedRow struct {
 func (q *Queries) GetWifiBucketed(ctx context.Context, arg GetWifiBucketedParams) ([]GetWifiBucketedRow, error) {
 	rows, err := q.db.Query(ctx, getWifiBucketed,
 		arg.BucketInterval,
+		arg.Aggregate,
 		arg.AgentID,
 		arg.StartTime,
 		arg.EndTime,
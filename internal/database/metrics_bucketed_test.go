@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// aggregateCase matches one column's min/max/avg switch on the aggregate
+// parameter.
+var aggregateCase = regexp.MustCompile(`CASE \$2::text WHEN 'min' THEN MIN\((\w+)\) WHEN 'max' THEN MAX\((\w+)\) ELSE AVG\((\w+)\) END`)
+
+var bucketedQueries = map[string]string{
+	"GetCPUBucketed":         getCPUBucketed,
+	"GetContainerBucketed":   getContainerBucketed,
+	"GetDiskBucketed":        getDiskBucketed,
+	"GetDiskIOBucketed":      getDiskIOBucketed,
+	"GetMemoryBucketed":      getMemoryBucketed,
+	"GetNetworkBucketed":     getNetworkBucketed,
+	"GetPiBucketed":          getPiBucketed,
+	"GetSystemBucketed":      getSystemBucketed,
+	"GetTemperatureBucketed": getTemperatureBucketed,
+	"GetWifiBucketed":        getWifiBucketed,
+}
+
+func TestBucketedQueries_AggregateBranches(t *testing.T) {
+	for name, sql := range bucketedQueries {
+		t.Run(name, func(t *testing.T) {
+			matches := aggregateCase.FindAllStringSubmatch(sql, -1)
+			if len(matches) == 0 {
+				t.Fatal("no min/max/avg aggregate columns")
+			}
+			if n := strings.Count(sql, "CASE $2::text"); n != len(matches) {
+				t.Errorf("%d aggregate CASEs, only %d well-formed", n, len(matches))
+			}
+			for _, m := range matches {
+				if m[1] != m[2] || m[1] != m[3] {
+					t.Errorf("branches aggregate different columns: MIN(%s) MAX(%s) AVG(%s)", m[1], m[2], m[3])
+				}
+			}
+
+			// Every averaged column must go through the switch, or ?agg=
+			// would silently fall back to avg for it.
+			rest := aggregateCase.ReplaceAllString(sql, "")
+			if strings.Contains(rest, "AVG(") {
+				t.Error("AVG outside the aggregate CASE ignores the aggregate parameter")
+			}
+		})
+	}
+}
+
+// captureDB records the arguments of the first Query and fails it.
+type captureDB struct {
+	args []any
+}
+
+var errCaptured = errors.New("captured")
+
+func (c *captureDB) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errCaptured
+}
+
+func (c *captureDB) Query(_ context.Context, _ string, args ...any) (pgx.Rows, error) {
+	c.args = args
+	return nil, errCaptured
+}
+
+func (c *captureDB) QueryRow(context.Context, string, ...any) pgx.Row { return nil }
+
+func TestBucketedQueries_BindAggregate(t *testing.T) {
+	const interval, agg = "5 minutes", "max"
+	calls := map[string]func(*Queries) error{
+		"GetCPUBucketed": func(q *Queries) error {
+			_, err := q.GetCPUBucketed(context.Background(), GetCPUBucketedParams{BucketInterval: interval, Aggregate: agg})
+			return err
+		},
+		"GetContainerBucketed": func(q *Queries) error {
+			_, err := q.GetContainerBucketed(context.Background(), GetContainerBucketedParams{BucketInterval: interval, Aggregate: agg})
+			return err
+		},
+		"GetDiskBucketed": func(q *Queries) error {
+			_, err := q.GetDiskBucketed(context.Background(), GetDiskBucketedParams{BucketInterval: interval, Aggregate: agg})
+			return err
+		},
+		"GetDiskIOBucketed": func(q *Queries) error {
+			_, err := q.GetDiskIOBucketed(context.Background(), GetDiskIOBucketedParams{BucketInterval: interval, Aggregate: agg})
+			return err
+		},
+		"GetMemoryBucketed": func(q *Queries) error {
+			_, err := q.GetMemoryBucketed(context.Background(), GetMemoryBucketedParams{BucketInterval: interval, Aggregate: agg})
+			return err
+		},
+		"GetNetworkBucketed": func(q *Queries) error {
+			_, err := q.GetNetworkBucketed(context.Background(), GetNetworkBucketedParams{BucketInterval: interval, Aggregate: agg})
+			return err
+		},
+		"GetPiBucketed": func(q *Queries) error {
+			_, err := q.GetPiBucketed(context.Background(), GetPiBucketedParams{BucketInterval: interval, Aggregate: agg})
+			return err
+		},
+		"GetSystemBucketed": func(q *Queries) error {
+			_, err := q.GetSystemBucketed(context.Background(), GetSystemBucketedParams{BucketInterval: interval, Aggregate: agg})
+			return err
+		},
+		"GetTemperatureBucketed": func(q *Queries) error {
+			_, err := q.GetTemperatureBucketed(context.Background(), GetTemperatureBucketedParams{BucketInterval: interval, Aggregate: agg})
+			return err
+		},
+		"GetWifiBucketed": func(q *Queries) error {
+			_, err := q.GetWifiBucketed(context.Background(), GetWifiBucketedParams{BucketInterval: interval, Aggregate: agg})
+			return err
+		},
+	}
+	if len(calls) != len(bucketedQueries) {
+		t.Fatalf("%d calls for %d bucketed queries", len(calls), len(bucketedQueries))
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			db := &captureDB{}
+			if err := call(New(db)); !errors.Is(err, errCaptured) {
+				t.Fatalf("got %v, want the captured query", err)
+			}
+			// The SQL reads the bucket width from $1 and the aggregate from $2.
+			if len(db.args) < 2 || db.args[0] != interval || db.args[1] != agg {
+				t.Errorf("args: got %v, want [%q %q ...]", db.args, interval, agg)
+			}
+		})
+	}
+}
@@ -42,25 +42,27 @@ func (q *Queries) InsertCPU(ctx context.Context, arg InsertCPUParams) error {
 }
 
 const insertContainer = `-- name: InsertContainer :exec
-INSERT INTO metrics_container (time, agent_id, container_id, name, image, state, source, kind, cpu_percent, cpu_cores, memory_bytes, memory_limit, net_rx_bytes, net_tx_bytes)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+INSERT INTO metrics_container (time, agent_id, container_id, name, image, state, source, kind, cpu_percent, cpu_cores, memory_bytes, memory_limit, net_rx_bytes, net_tx_bytes, restart_count, health)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 `
 
 type InsertContainerParams struct {
-	Time        pgtype.Timestamptz `json:"time"`
-	AgentID     pgtype.UUID        `json:"agent_id"`
-	ContainerID pgtype.Text        `json:"container_id"`
-	Name        pgtype.Text        `json:"name"`
-	Image       pgtype.Text        `json:"image"`
-	State       pgtype.Text        `json:"state"`
-	Source      pgtype.Text        `json:"source"`
-	Kind        pgtype.Text        `json:"kind"`
-	CpuPercent  pgtype.Float8      `json:"cpu_percent"`
-	CpuCores    pgtype.Int4        `json:"cpu_cores"`
-	MemoryBytes pgtype.Int8        `json:"memory_bytes"`
-	MemoryLimit pgtype.Int8        `json:"memory_limit"`
-	NetRxBytes  pgtype.Int8        `json:"net_rx_bytes"`
-	NetTxBytes  pgtype.Int8        `json:"net_tx_bytes"`
+	Time         pgtype.Timestamptz `json:"time"`
+	AgentID      pgtype.UUID        `json:"agent_id"`
+	ContainerID  pgtype.Text        `json:"container_id"`
+	Name         pgtype.Text        `json:"name"`
+	Image        pgtype.Text        `json:"image"`
+	State        pgtype.Text        `json:"state"`
+	Source       pgtype.Text        `json:"source"`
+	Kind         pgtype.Text        `json:"kind"`
+	CpuPercent   pgtype.Float8      `json:"cpu_percent"`
+	CpuCores     pgtype.Int4        `json:"cpu_cores"`
+	MemoryBytes  pgtype.Int8        `json:"memory_bytes"`
+	MemoryLimit  pgtype.Int8        `json:"memory_limit"`
+	NetRxBytes   pgtype.Int8        `json:"net_rx_bytes"`
+	NetTxBytes   pgtype.Int8        `json:"net_tx_bytes"`
+	RestartCount pgtype.Int4        `json:"restart_count"`
+	Health       pgtype.Text        `json:"health"`
 }
 
 func (q *Queries) InsertContainer(ctx context.Context, arg InsertContainerParams) error {
@@ -79,13 +81,15 @@ func (q *Queries) InsertContainer(ctx context.Context, arg InsertContainerParams
 		arg.MemoryLimit,
 		arg.NetRxBytes,
 		arg.NetTxBytes,
+		arg.RestartCount,
+		arg.Health,
 	)
 	return err
 }
 
 const insertDisk = `-- name: InsertDisk :exec
-INSERT INTO metrics_disk (time, agent_id, device, mountpoint, filesystem, disk_type, total_bytes, used_bytes, free_bytes, used_percent, inodes_total, inodes_used, inodes_percent)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+INSERT INTO metrics_disk (time, agent_id, device, mountpoint, filesystem, disk_type, total_bytes, used_bytes, free_bytes, used_percent, inodes_total, inodes_used, inodes_percent, read_only)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 `
 
 type InsertDiskParams struct {
@@ -102,6 +106,7 @@ type InsertDiskParams struct {
 	InodesTotal   pgtype.Int8        `json:"inodes_total"`
 	InodesUsed    pgtype.Int8        `json:"inodes_used"`
 	InodesPercent pgtype.Float8      `json:"inodes_percent"`
+	ReadOnly      pgtype.Bool        `json:"read_only"`
 }
 
 func (q *Queries) InsertDisk(ctx context.Context, arg InsertDiskParams) error {
@@ -119,6 +124,7 @@ func (q *Queries) InsertDisk(ctx context.Context, arg InsertDiskParams) error {
 		arg.InodesTotal,
 		arg.InodesUsed,
 		arg.InodesPercent,
+		arg.ReadOnly,
 	)
 	return err
 }
@@ -0,0 +1,2 @@
+ALTER TABLE agents DROP COLUMN tags;
+ALTER TABLE agents DROP COLUMN kernel;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE agents ADD COLUMN kernel TEXT NOT NULL DEFAULT '';
+ALTER TABLE agents ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- Latest hardware inventory snapshot per agent, one row per kind
+-- (e.g. "usb"). The snapshot is stored as reported by the agent.
+CREATE TABLE current_inventory (
+    agent_id    UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    kind        TEXT NOT NULL,
+    data        JSONB NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (agent_id, kind)
+);
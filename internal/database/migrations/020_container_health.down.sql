@@ -0,0 +1,2 @@
+ALTER TABLE metrics_container DROP COLUMN health;
+ALTER TABLE metrics_container DROP COLUMN restart_count;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE metrics_container ADD COLUMN restart_count INTEGER;
+ALTER TABLE metrics_container ADD COLUMN health TEXT;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE metrics_disk DROP COLUMN read_only;
\ No newline at end of file
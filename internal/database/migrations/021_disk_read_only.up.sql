@@ -0,0 +1 @@
+ALTER TABLE metrics_disk ADD COLUMN read_only BOOLEAN;
\ No newline at end of file
@@ -25,6 +25,8 @@ type Agent struct {
 	Version      string             `json:"version"`
 	Commit       string             `json:"commit"`
 	BinaryHash   string             `json:"binary_hash"`
+	Kernel       string             `json:"kernel"`
+	Tags         []string           `json:"tags"`
 }
 
 type AgentConfig struct {
@@ -85,6 +87,13 @@ type CurrentApplication struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type CurrentInventory struct {
+	AgentID   pgtype.UUID        `json:"agent_id"`
+	Kind      string             `json:"kind"`
+	Data      []byte             `json:"data"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
 type CurrentMetric struct {
 	AgentID        pgtype.UUID        `json:"agent_id"`
 	CpuUsage       pgtype.Float8      `json:"cpu_usage"`
@@ -131,20 +140,22 @@ type CurrentUpdate struct {
 }
 
 type MetricsContainer struct {
-	Time        pgtype.Timestamptz `json:"time"`
-	AgentID     pgtype.UUID        `json:"agent_id"`
-	ContainerID pgtype.Text        `json:"container_id"`
-	Name        pgtype.Text        `json:"name"`
-	Image       pgtype.Text        `json:"image"`
-	State       pgtype.Text        `json:"state"`
-	Source      pgtype.Text        `json:"source"`
-	Kind        pgtype.Text        `json:"kind"`
-	CpuPercent  pgtype.Float8      `json:"cpu_percent"`
-	CpuCores    pgtype.Int4        `json:"cpu_cores"`
-	MemoryBytes pgtype.Int8        `json:"memory_bytes"`
-	MemoryLimit pgtype.Int8        `json:"memory_limit"`
-	NetRxBytes  pgtype.Int8        `json:"net_rx_bytes"`
-	NetTxBytes  pgtype.Int8        `json:"net_tx_bytes"`
+	Time         pgtype.Timestamptz `json:"time"`
+	AgentID      pgtype.UUID        `json:"agent_id"`
+	ContainerID  pgtype.Text        `json:"container_id"`
+	Name         pgtype.Text        `json:"name"`
+	Image        pgtype.Text        `json:"image"`
+	State        pgtype.Text        `json:"state"`
+	Source       pgtype.Text        `json:"source"`
+	Kind         pgtype.Text        `json:"kind"`
+	CpuPercent   pgtype.Float8      `json:"cpu_percent"`
+	CpuCores     pgtype.Int4        `json:"cpu_cores"`
+	MemoryBytes  pgtype.Int8        `json:"memory_bytes"`
+	MemoryLimit  pgtype.Int8        `json:"memory_limit"`
+	NetRxBytes   pgtype.Int8        `json:"net_rx_bytes"`
+	NetTxBytes   pgtype.Int8        `json:"net_tx_bytes"`
+	RestartCount pgtype.Int4        `json:"restart_count"`
+	Health       pgtype.Text        `json:"health"`
 }
 
 type MetricsCpu struct {
@@ -172,6 +183,7 @@ type MetricsDisk struct {
 	InodesTotal   pgtype.Int8        `json:"inodes_total"`
 	InodesUsed    pgtype.Int8        `json:"inodes_used"`
 	InodesPercent pgtype.Float8      `json:"inodes_percent"`
+	ReadOnly      pgtype.Bool        `json:"read_only"`
 }
 
 type MetricsDiskIo struct {
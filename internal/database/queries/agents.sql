@@ -1,13 +1,18 @@
 -- name: RegisterAgent :exec
-INSERT INTO agents (id, secret_hash, secret_sha256, hostname, os, platform, arch, cpu_model, cpu_cores, ram_total, ip_address, version)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+INSERT INTO agents (id, secret_hash, secret_sha256, hostname, os, platform, arch, cpu_model, cpu_cores, ram_total, ip_address, version, kernel, tags)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (id)
+DO UPDATE SET secret_hash = EXCLUDED.secret_hash, secret_sha256 = EXCLUDED.secret_sha256, hostname = EXCLUDED.hostname,
+    os = EXCLUDED.os, platform = EXCLUDED.platform, arch = EXCLUDED.arch, cpu_model = EXCLUDED.cpu_model,
+    cpu_cores = EXCLUDED.cpu_cores, ram_total = EXCLUDED.ram_total, ip_address = EXCLUDED.ip_address,
+    version = EXCLUDED.version, kernel = EXCLUDED.kernel, tags = EXCLUDED.tags, last_seen = NOW();
 
 -- name: GetAgent :one
-SELECT id, secret_hash, hostname, os, platform, arch, cpu_model, cpu_cores, ram_total, registered_at, last_seen, ip_address
+SELECT id, secret_hash, hostname, os, platform, arch, cpu_model, cpu_cores, ram_total, registered_at, last_seen, ip_address, version, kernel, tags
 FROM agents WHERE id = $1;
 
 -- name: ListAgents :many
-SELECT id, hostname, os, platform, arch, cpu_cores, ram_total, registered_at, last_seen
+SELECT id, hostname, os, platform, arch, cpu_cores, ram_total, registered_at, last_seen, tags
 FROM agents
 ORDER BY hostname;
 
@@ -55,6 +55,19 @@ FROM current_applications
 WHERE agent_id = $1
 ORDER BY name;
 
+-- name: UpsertInventory :exec
+INSERT INTO current_inventory (agent_id, kind, data, updated_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (agent_id, kind) DO UPDATE
+SET data = EXCLUDED.data,
+    updated_at = NOW();
+
+-- name: GetInventory :many
+SELECT agent_id, kind, data, updated_at
+FROM current_inventory
+WHERE agent_id = $1
+ORDER BY kind;
+
 -- name: UpsertUpdates :exec
 INSERT INTO current_updates (agent_id, pending_count, security_count, reboot_required, package_manager, updated_at)
 VALUES ($1, $2, $3, $4, $5, NOW())
@@ -0,0 +1,3 @@
+-- name: Ping :one
+-- Round-trip used by the readiness probe.
+SELECT true AS ok;
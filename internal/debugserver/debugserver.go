@@ -0,0 +1,114 @@
+// Package debugserver runs an optional localhost-only HTTP listener that
+// exposes pprof profiles and Go runtime statistics for diagnosing the
+// agent's and server's own resource use.
+package debugserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	"time"
+)
+
+// AddrEnvVar enables the listener when set, e.g. "127.0.0.1:6060".
+const AddrEnvVar = "SPECTRA_DEBUG_ADDR"
+
+// AddrFromEnv returns the configured debug address, or "" when the
+// listener should stay off.
+func AddrFromEnv() string {
+	return os.Getenv(AddrEnvVar)
+}
+
+// RuntimeStats is the JSON body of /debug/runtime.
+type RuntimeStats struct {
+	Goroutines   int     `json:"goroutines"`
+	HeapAlloc    uint64  `json:"heap_alloc_bytes"`
+	HeapInuse    uint64  `json:"heap_inuse_bytes"`
+	HeapObjects  uint64  `json:"heap_objects"`
+	Sys          uint64  `json:"sys_bytes"`
+	NumGC        uint32  `json:"num_gc"`
+	LastPauseMS  float64 `json:"last_gc_pause_ms"`
+	GCCPUPercent float64 `json:"gc_cpu_percent"`
+}
+
+func readRuntimeStats() RuntimeStats {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	return RuntimeStats{
+		Goroutines:   runtime.NumGoroutine(),
+		HeapAlloc:    ms.HeapAlloc,
+		HeapInuse:    ms.HeapInuse,
+		HeapObjects:  ms.HeapObjects,
+		Sys:          ms.Sys,
+		NumGC:        ms.NumGC,
+		LastPauseMS:  float64(ms.PauseNs[(ms.NumGC+255)%256]) / 1e6,
+		GCCPUPercent: ms.GCCPUFraction * 100,
+	}
+}
+
+// Handler returns a mux serving /debug/pprof/ and /debug/runtime. It uses
+// its own mux so nothing leaks onto http.DefaultServeMux.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/runtime", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(readRuntimeStats())
+	})
+	return mux
+}
+
+// checkLoopback rejects addresses that would expose the listener beyond
+// this host.
+func checkLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid debug address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("debug address %q must be a loopback address", addr)
+	}
+	return nil
+}
+
+// Start listens on addr and serves Handler in the background. An empty
+// addr starts nothing and returns a nil server; non-loopback addresses
+// are refused.
+func Start(addr string) (*http.Server, error) {
+	if addr == "" {
+		return nil, nil
+	}
+	if err := checkLoopback(addr); err != nil {
+		return nil, err
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("debug listener: %w", err)
+	}
+
+	srv := &http.Server{
+		Addr:              ln.Addr().String(),
+		Handler:           Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "debug server: %v\n", err)
+		}
+	}()
+	return srv, nil
+}
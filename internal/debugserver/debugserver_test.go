@@ -0,0 +1,72 @@
+package debugserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestStart_Enabled(t *testing.T) {
+	srv, err := Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer srv.Shutdown(context.Background())
+
+	resp, err := http.Get("http://" + srv.Addr + "/debug/pprof/")
+	if err != nil {
+		t.Fatalf("GET /debug/pprof/: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("/debug/pprof/: got %d, want 200", resp.StatusCode)
+	}
+
+	resp, err = http.Get("http://" + srv.Addr + "/debug/runtime")
+	if err != nil {
+		t.Fatalf("GET /debug/runtime: %v", err)
+	}
+	defer resp.Body.Close()
+	var stats RuntimeStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if stats.Goroutines <= 0 || stats.Sys == 0 {
+		t.Errorf("implausible stats: %+v", stats)
+	}
+}
+
+func TestStart_Disabled(t *testing.T) {
+	t.Setenv(AddrEnvVar, "")
+
+	srv, err := Start(AddrFromEnv())
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if srv != nil {
+		t.Errorf("expected no listener, got one on %s", srv.Addr)
+	}
+}
+
+func TestCheckLoopback(t *testing.T) {
+	tests := []struct {
+		addr string
+		ok   bool
+	}{
+		{"127.0.0.1:6060", true},
+		{"localhost:6060", true},
+		{"[::1]:6060", true},
+		{"0.0.0.0:6060", false},
+		{":6060", false},
+		{"192.168.1.10:6060", false},
+		{"127.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		err := checkLoopback(tt.addr)
+		if (err == nil) != tt.ok {
+			t.Errorf("checkLoopback(%q): err=%v, want ok=%v", tt.addr, err, tt.ok)
+		}
+	}
+}
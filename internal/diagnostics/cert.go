@@ -0,0 +1,88 @@
+package diagnostics
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const (
+	defaultCertTimeout = 10 * time.Second
+	maxCertTimeout     = 30 * time.Second
+)
+
+// CheckCert connects to req.Target and reports on the leaf certificate it
+// presents. The handshake itself skips verification so that expired or
+// untrusted certificates can still be inspected; the chain is verified
+// separately against the system roots and the outcome recorded.
+func CheckCert(ctx context.Context, req protocol.CertCheckRequest) (*protocol.CertCheckResult, error) {
+	return checkCert(ctx, req, nil, time.Now())
+}
+
+// checkCert is CheckCert with the verification roots (nil for the system
+// pool) and current time supplied by the caller.
+func checkCert(ctx context.Context, req protocol.CertCheckRequest, roots *x509.CertPool, now time.Time) (*protocol.CertCheckResult, error) {
+	host, _, err := net.SplitHostPort(req.Target)
+	if err != nil {
+		return nil, fmt.Errorf("target must be host:port: %w", err)
+	}
+
+	timeout := defaultCertTimeout
+	if req.TimeoutSeconds > 0 {
+		timeout = min(time.Duration(req.TimeoutSeconds)*time.Second, maxCertTimeout)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dialer := &tls.Dialer{
+		Config: &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: true, //nolint:gosec // G402: verified below, after capturing the certificate.
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", req.Target)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, errors.New("server presented no certificate")
+	}
+	leaf := certs[0]
+
+	result := &protocol.CertCheckResult{
+		Target:        req.Target,
+		Subject:       leaf.Subject.String(),
+		Issuer:        leaf.Issuer.String(),
+		DNSNames:      leaf.DNSNames,
+		NotBefore:     leaf.NotBefore,
+		NotAfter:      leaf.NotAfter,
+		DaysRemaining: int(math.Floor(leaf.NotAfter.Sub(now).Hours() / 24)),
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		DNSName:       host,
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	}); err != nil {
+		result.VerifyError = err.Error()
+	} else {
+		result.Verified = true
+	}
+
+	return result, nil
+}
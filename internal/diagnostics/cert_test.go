@@ -0,0 +1,94 @@
+package diagnostics
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestCheckCert(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	want := srv.Certificate()
+	target := strings.TrimPrefix(srv.URL, "https://")
+	now := want.NotAfter.Add(-10*24*time.Hour - time.Hour)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(want)
+
+	got, err := checkCert(context.Background(), protocol.CertCheckRequest{Target: target}, roots, now)
+	if err != nil {
+		t.Fatalf("checkCert: %v", err)
+	}
+
+	if !got.NotAfter.Equal(want.NotAfter) {
+		t.Errorf("NotAfter: got %v, want %v", got.NotAfter, want.NotAfter)
+	}
+	if got.DaysRemaining != 10 {
+		t.Errorf("DaysRemaining: got %d, want 10", got.DaysRemaining)
+	}
+	if got.Subject != want.Subject.String() || got.Issuer != want.Issuer.String() {
+		t.Errorf("subject/issuer: got %q/%q, want %q/%q", got.Subject, got.Issuer, want.Subject, want.Issuer)
+	}
+	if got.Target != target {
+		t.Errorf("Target: got %q, want %q", got.Target, target)
+	}
+	if !got.Verified || got.VerifyError != "" {
+		t.Errorf("expected verified chain, got error %q", got.VerifyError)
+	}
+}
+
+func TestCheckCert_Expired(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	cert := srv.Certificate()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	// An expired certificate is still reported, just not verified
+	now := cert.NotAfter.Add(36 * time.Hour)
+	got, err := checkCert(context.Background(), protocol.CertCheckRequest{Target: strings.TrimPrefix(srv.URL, "https://")}, roots, now)
+	if err != nil {
+		t.Fatalf("checkCert: %v", err)
+	}
+	if got.DaysRemaining != -2 {
+		t.Errorf("DaysRemaining: got %d, want -2", got.DaysRemaining)
+	}
+	if got.Verified || got.VerifyError == "" {
+		t.Error("expected verification failure for expired certificate")
+	}
+}
+
+func TestCheckCert_UntrustedByDefault(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	got, err := CheckCert(context.Background(), protocol.CertCheckRequest{Target: strings.TrimPrefix(srv.URL, "https://")})
+	if err != nil {
+		t.Fatalf("CheckCert: %v", err)
+	}
+	if got.Verified {
+		t.Error("self-signed test certificate should not verify against system roots")
+	}
+}
+
+func TestCheckCert_InvalidTarget(t *testing.T) {
+	if _, err := CheckCert(context.Background(), protocol.CertCheckRequest{Target: "example.com"}); err == nil {
+		t.Error("expected error for target without port")
+	}
+}
+
+func TestCheckCert_Unreachable(t *testing.T) {
+	_, err := CheckCert(context.Background(), protocol.CertCheckRequest{Target: "127.0.0.1:1", TimeoutSeconds: 1})
+	if err == nil {
+		t.Error("expected error for unreachable target")
+	}
+}
@@ -0,0 +1,215 @@
+package diagnostics
+
+import (
+	"container/heap"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// maxDiskUsageWorkers caps the number of concurrent directory readers.
+const maxDiskUsageWorkers = 32
+
+// scannedEntry is a single directory entry captured during a concurrent
+// scan. Exactly one of dir or the file fields is meaningful.
+type scannedEntry struct {
+	path   string
+	size   uint64
+	key    [2]uint64
+	hasKey bool
+	dir    *scannedDir
+}
+
+// scannedDir holds the entries of one directory in ReadDir order.
+type scannedDir struct {
+	path    string
+	skipped bool // ignored path or cancelled before reading
+	failed  bool // ReadDir error
+	entries []scannedEntry
+}
+
+// RunDiskUsageTopConcurrent is RunDiskUsageTop with directory reads spread
+// across up to workers goroutines. The tree is read concurrently and then
+// aggregated serially in the same order as the serial walk, so dedup,
+// directory sizes and top-N results are identical to RunDiskUsageTop.
+// The scanned tree is held in memory until aggregation, so this trades
+// memory for I/O parallelism. A workers value <= 1 falls back to the
+// serial walk; values above maxDiskUsageWorkers are clamped.
+func RunDiskUsageTopConcurrent(ctx context.Context, root string, topDirsN, topFilesN, workers int) (*protocol.DiskUsageTopReport, error) {
+	if workers <= 1 {
+		return RunDiskUsageTop(ctx, root, topDirsN, topFilesN)
+	}
+	workers = min(workers, maxDiskUsageWorkers)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+
+	tree := scanTree(ctx, root, workers)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	filesHeap := make(topNHeap, 0, topFilesN)
+	dirsHeap := make(topNHeap, 0, topDirsN)
+	heap.Init(&filesHeap)
+	heap.Init(&dirsHeap)
+	seen := make(map[[2]uint64]struct{})
+
+	var scannedFiles, scannedDirs, errorCount uint64
+
+	var aggregate func(*scannedDir) (size, count uint64)
+
+	aggregate = func(d *scannedDir) (uint64, uint64) {
+		if d.skipped {
+			return 0, 0
+		}
+		if d.failed {
+			errorCount++
+			return 0, 0
+		}
+
+		scannedDirs++
+
+		var dirSize, dirFileCount uint64
+
+		for _, e := range d.entries {
+			if e.dir != nil {
+				s, c := aggregate(e.dir)
+				dirSize += s
+				dirFileCount += c
+				continue
+			}
+
+			if e.hasKey {
+				if _, dup := seen[e.key]; dup {
+					continue
+				}
+				seen[e.key] = struct{}{}
+			}
+			dirSize += e.size
+			dirFileCount++
+			scannedFiles++
+
+			pushTopN(&filesHeap, topFilesN, protocol.TopEntry{
+				Path: e.path,
+				Size: e.size,
+			})
+		}
+
+		if dirSize > 0 {
+			pushTopN(&dirsHeap, topDirsN, protocol.TopEntry{
+				Path:  d.path,
+				Size:  dirSize,
+				Count: dirFileCount,
+			})
+		}
+
+		return dirSize, dirFileCount
+	}
+
+	aggregate(tree)
+
+	report := &protocol.DiskUsageTopReport{
+		Root:         root,
+		ScannedFiles: scannedFiles,
+		ScannedDirs:  scannedDirs,
+		ErrorCount:   errorCount,
+		DurationMs:   time.Since(start).Milliseconds(),
+		ScannedAt:    time.Now(),
+		TopFiles:     popAllSortedDesc(&filesHeap),
+		TopDirs:      popAllSortedDesc(&dirsHeap),
+	}
+
+	return report, nil
+}
+
+// scanTree reads the directory tree under root. Subdirectories are handed
+// to a new goroutine while fewer than workers are running; otherwise they
+// are read inline by the current goroutine, so the walk never blocks
+// waiting for a free slot.
+func scanTree(ctx context.Context, root string, workers int) *scannedDir {
+	sem := make(chan struct{}, workers-1)
+	var wg sync.WaitGroup
+
+	var scan func(*scannedDir)
+
+	scan = func(d *scannedDir) {
+		if ctx.Err() != nil {
+			d.skipped = true
+			return
+		}
+
+		if _, skip := ignoredPaths[d.path]; skip {
+			d.skipped = true
+			return
+		}
+
+		entries, err := os.ReadDir(d.path)
+		if err != nil {
+			d.failed = true
+			return
+		}
+
+		d.entries = make([]scannedEntry, 0, len(entries))
+
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+
+			// Skip symlinks (avoid double-counts)
+			if info.Mode()&os.ModeSymlink != 0 {
+				continue
+			}
+
+			fullPath := filepath.Join(d.path, entry.Name())
+
+			if entry.IsDir() {
+				d.entries = append(d.entries, scannedEntry{
+					dir: &scannedDir{path: fullPath},
+				})
+				continue
+			}
+
+			key, ok := fileKey(info)
+			d.entries = append(d.entries, scannedEntry{
+				path:   fullPath,
+				size:   uint64(info.Size()),
+				key:    key,
+				hasKey: ok,
+			})
+		}
+
+		for _, e := range d.entries {
+			if e.dir == nil {
+				continue
+			}
+
+			select {
+			case sem <- struct{}{}:
+				wg.Add(1)
+				go func(sub *scannedDir) {
+					defer wg.Done()
+					defer func() { <-sem }()
+					scan(sub)
+				}(e.dir)
+			default:
+				scan(e.dir)
+			}
+		}
+	}
+
+	tree := &scannedDir{path: root}
+	scan(tree)
+	wg.Wait()
+
+	return tree
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 )
@@ -565,3 +566,64 @@ func createDummyFileB(b *testing.B, path string, size int64) {
 		b.Fatalf("failed to truncate file: %s: %v", path, err)
 	}
 }
+
+func TestRunDiskUsageTopConcurrent_MatchesSerial(t *testing.T) {
+	rootDir := t.TempDir()
+
+	for i := range 8 {
+		for j := range 6 {
+			for k := range 5 {
+				path := filepath.Join(rootDir, fmt.Sprintf("d%d", i), fmt.Sprintf("s%d", j), fmt.Sprintf("f%d.bin", k))
+				// Repeat sizes so top-N ordering depends on tie-breaks
+				createDummyFile(t, path, int64((i*j+k)%7*100+100))
+			}
+		}
+	}
+	createDummyFile(t, filepath.Join(rootDir, "root.bin"), 2500)
+
+	// Hardlinks must be attributed to the same path in both modes
+	if err := os.Link(filepath.Join(rootDir, "d1", "s1", "f1.bin"), filepath.Join(rootDir, "d0", "link.bin")); err != nil {
+		t.Logf("hardlink unsupported: %v", err)
+	}
+
+	ctx := context.Background()
+
+	serial, err := RunDiskUsageTop(ctx, rootDir, 10, 20)
+	if err != nil {
+		t.Fatalf("serial: %v", err)
+	}
+
+	for _, workers := range []int{2, 4, 16} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			got, err := RunDiskUsageTopConcurrent(ctx, rootDir, 10, 20, workers)
+			if err != nil {
+				t.Fatalf("concurrent: %v", err)
+			}
+
+			if got.ScannedFiles != serial.ScannedFiles || got.ScannedDirs != serial.ScannedDirs || got.ErrorCount != serial.ErrorCount {
+				t.Errorf("counters = %d/%d/%d, want %d/%d/%d",
+					got.ScannedFiles, got.ScannedDirs, got.ErrorCount,
+					serial.ScannedFiles, serial.ScannedDirs, serial.ErrorCount)
+			}
+			if !slices.Equal(got.TopFiles, serial.TopFiles) {
+				t.Errorf("TopFiles = %v, want %v", got.TopFiles, serial.TopFiles)
+			}
+			if !slices.Equal(got.TopDirs, serial.TopDirs) {
+				t.Errorf("TopDirs = %v, want %v", got.TopDirs, serial.TopDirs)
+			}
+		})
+	}
+}
+
+func TestRunDiskUsageTopConcurrent_ContextCancel(t *testing.T) {
+	rootDir := t.TempDir()
+	createDummyFile(t, filepath.Join(rootDir, "d", "f1"), 100)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := RunDiskUsageTopConcurrent(ctx, rootDir, 10, 10, 4)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package diagnostics
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const (
+	defaultIOStatInterval = time.Second
+	maxIOStatInterval     = 10 * time.Second
+
+	diskstatsSectorSize = 512
+)
+
+// diskCounters are the cumulative /proc/diskstats fields iostat needs.
+type diskCounters struct {
+	ReadOps      uint64
+	ReadSectors  uint64
+	WriteOps     uint64
+	WriteSectors uint64
+	IOTicksMS    uint64 // time spent doing I/O
+}
+
+// ioStatInterval clamps the requested interval to (0, maxIOStatInterval].
+func ioStatInterval(req protocol.IOStatRequest) time.Duration {
+	d := time.Duration(req.IntervalSeconds) * time.Second
+	if d <= 0 {
+		return defaultIOStatInterval
+	}
+	return min(d, maxIOStatInterval)
+}
+
+// parseDiskstats reads /proc/diskstats content into counters keyed by
+// device name. Loop and RAM disks are skipped.
+func parseDiskstats(r io.Reader) (map[string]diskCounters, error) {
+	result := make(map[string]diskCounters)
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 13 {
+			continue
+		}
+
+		device := fields[2]
+		if strings.HasPrefix(device, "loop") || strings.HasPrefix(device, "ram") {
+			continue
+		}
+
+		parse := func(i int) uint64 {
+			v, _ := strconv.ParseUint(fields[i], 10, 64)
+			return v
+		}
+		result[device] = diskCounters{
+			ReadOps:      parse(3),
+			ReadSectors:  parse(5),
+			WriteOps:     parse(7),
+			WriteSectors: parse(9),
+			IOTicksMS:    parse(12),
+		}
+	}
+
+	return result, scanner.Err()
+}
+
+// computeIOStat turns two diskstats samples taken elapsed apart into
+// per-device rates, sorted by device. Devices missing from either sample
+// or whose counters went backwards are left out.
+func computeIOStat(prev, curr map[string]diskCounters, elapsed time.Duration) *protocol.IOStatResult {
+	result := &protocol.IOStatResult{
+		IntervalSeconds: elapsed.Seconds(),
+		Devices:         []protocol.IOStatDevice{},
+	}
+	secs := elapsed.Seconds()
+	if secs <= 0 {
+		return result
+	}
+
+	for device, c := range curr {
+		p, ok := prev[device]
+		if !ok || c.ReadOps < p.ReadOps || c.WriteOps < p.WriteOps ||
+			c.ReadSectors < p.ReadSectors || c.WriteSectors < p.WriteSectors || c.IOTicksMS < p.IOTicksMS {
+			continue
+		}
+
+		util := float64(c.IOTicksMS-p.IOTicksMS) / float64(elapsed.Milliseconds()) * 100
+		result.Devices = append(result.Devices, protocol.IOStatDevice{
+			Device:           device,
+			ReadIOPS:         float64(c.ReadOps-p.ReadOps) / secs,
+			WriteIOPS:        float64(c.WriteOps-p.WriteOps) / secs,
+			ReadBytesPerSec:  float64(c.ReadSectors-p.ReadSectors) * diskstatsSectorSize / secs,
+			WriteBytesPerSec: float64(c.WriteSectors-p.WriteSectors) * diskstatsSectorSize / secs,
+			UtilPct:          min(util, 100),
+		})
+	}
+
+	slices.SortFunc(result.Devices, func(a, b protocol.IOStatDevice) int {
+		return strings.Compare(a.Device, b.Device)
+	})
+	return result
+}
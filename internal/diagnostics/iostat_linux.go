@@ -0,0 +1,44 @@
+//go:build linux
+
+package diagnostics
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// RunIOStat samples /proc/diskstats twice, req.IntervalSeconds apart, and
+// reports per-device IOPS, throughput and utilization.
+func RunIOStat(ctx context.Context, req protocol.IOStatRequest) (*protocol.IOStatResult, error) {
+	prev, err := readDiskstats()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
+	timer := time.NewTimer(ioStatInterval(req))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+	}
+
+	curr, err := readDiskstats()
+	if err != nil {
+		return nil, err
+	}
+	return computeIOStat(prev, curr, time.Since(start)), nil
+}
+
+func readDiskstats() (map[string]diskCounters, error) {
+	f, err := os.Open("/proc/diskstats")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseDiskstats(f)
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// RunIOStat is only implemented on Linux.
+func RunIOStat(ctx context.Context, req protocol.IOStatRequest) (*protocol.IOStatResult, error) {
+	return nil, fmt.Errorf("iostat is not supported on %s", runtime.GOOS)
+}
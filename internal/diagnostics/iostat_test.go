@@ -0,0 +1,87 @@
+package diagnostics
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const diskstatsBefore = `   7       0 loop0 100 0 800 10 0 0 0 0 0 20 10 0 0 0 0
+   8       0 sda 1000 50 80000 500 2000 100 160000 900 0 1200 1400 0 0 0 0
+   8       1 sda1 900 50 72000 450 1900 100 150000 850 0 1100 1300 0 0 0 0
+ 259       0 nvme0n1 5000 0 400000 1000 3000 0 240000 800 0 1500 1800 0 0 0 0
+`
+
+// Two seconds later: sda did 200 reads / 100 writes and was busy 500ms,
+// nvme0n1's counters reset (device replaced), sdb is new.
+const diskstatsAfter = `   7       0 loop0 500 0 4000 50 0 0 0 0 0 60 50 0 0 0 0
+   8       0 sda 1200 50 96000 600 2100 100 168000 950 1 1700 1550 0 0 0 0
+   8       1 sda1 1100 50 88000 550 2000 100 158000 900 1 1600 1450 0 0 0 0
+ 259       0 nvme0n1 10 0 80 1 0 0 0 0 0 5 1 0 0 0 0
+   8      16 sdb 10 0 80 1 0 0 0 0 0 5 1 0 0 0 0
+`
+
+func TestComputeIOStat(t *testing.T) {
+	prev, err := parseDiskstats(strings.NewReader(diskstatsBefore))
+	if err != nil {
+		t.Fatalf("parse before: %v", err)
+	}
+	curr, err := parseDiskstats(strings.NewReader(diskstatsAfter))
+	if err != nil {
+		t.Fatalf("parse after: %v", err)
+	}
+	if _, ok := curr["loop0"]; ok {
+		t.Error("loop devices should be skipped")
+	}
+
+	got := computeIOStat(prev, curr, 2*time.Second)
+
+	if got.IntervalSeconds != 2 {
+		t.Errorf("IntervalSeconds: got %v, want 2", got.IntervalSeconds)
+	}
+	if len(got.Devices) != 2 || got.Devices[0].Device != "sda" || got.Devices[1].Device != "sda1" {
+		t.Fatalf("devices: got %+v, want sda and sda1", got.Devices)
+	}
+
+	want := protocol.IOStatDevice{
+		Device:           "sda",
+		ReadIOPS:         100,
+		WriteIOPS:        50,
+		ReadBytesPerSec:  16000 * 512 / 2,
+		WriteBytesPerSec: 8000 * 512 / 2,
+		UtilPct:          25,
+	}
+	if got.Devices[0] != want {
+		t.Errorf("sda: got %+v, want %+v", got.Devices[0], want)
+	}
+}
+
+func TestComputeIOStat_UtilCapped(t *testing.T) {
+	prev := map[string]diskCounters{"sda": {IOTicksMS: 0}}
+	curr := map[string]diskCounters{"sda": {IOTicksMS: 1100}}
+
+	got := computeIOStat(prev, curr, time.Second)
+	if len(got.Devices) != 1 || math.Abs(got.Devices[0].UtilPct-100) > 1e-9 {
+		t.Errorf("got %+v, want util capped at 100", got.Devices)
+	}
+}
+
+func TestIOStatInterval(t *testing.T) {
+	tests := []struct {
+		secs int
+		want time.Duration
+	}{
+		{0, time.Second},
+		{-3, time.Second},
+		{3, 3 * time.Second},
+		{60, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := ioStatInterval(protocol.IOStatRequest{IntervalSeconds: tt.secs}); got != tt.want {
+			t.Errorf("ioStatInterval(%d): got %v, want %v", tt.secs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package diagnostics
+
+import "github.com/nhdewitt/spectra/internal/protocol"
+
+// CollapseRepeats merges runs of consecutive entries with the same source,
+// level and message into the first entry of the run, recording the run
+// length in Count. Entries that aren't repeated keep a zero Count.
+func CollapseRepeats(entries []protocol.LogEntry) []protocol.LogEntry {
+	if len(entries) < 2 {
+		return entries
+	}
+
+	out := make([]protocol.LogEntry, 0, len(entries))
+	for _, e := range entries {
+		if n := len(out); n > 0 {
+			last := &out[n-1]
+			if last.Source == e.Source && last.Level == e.Level && last.Message == e.Message {
+				last.Count = max(last.Count, 1) + 1
+				continue
+			}
+		}
+		out = append(out, e)
+	}
+
+	return out
+}
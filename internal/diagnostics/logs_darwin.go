@@ -64,10 +64,8 @@ func FetchLogs(ctx context.Context, opts protocol.LogRequest) ([]protocol.LogEnt
 func getMacLogsFiltered(ctx context.Context, minLevel protocol.LogLevel, limit int, predicate string) ([]protocol.LogEntry, error) {
 	args := []string{"show", "--style", "json", "--last", "4h", "--predicate", predicate}
 
-	minSeverity := levelToPriority(minLevel)
-
 	// cap at info to prevent OOM
-	if minSeverity >= 6 {
+	if minLevel.Severity() >= 6 {
 		args = append(args, "--info")
 	}
 
@@ -94,8 +92,6 @@ func parseMacLogsAndTail(r io.Reader, minLevel protocol.LogLevel, limit int) ([]
 	decoder := json.NewDecoder(r)
 	seen := make(map[string]int)
 
-	minSeverity := levelToPriority(minLevel)
-
 	// consume the opening '[' of the array
 	_, err := decoder.Token()
 	if err != nil {
@@ -113,7 +109,7 @@ func parseMacLogsAndTail(r io.Reader, minLevel protocol.LogLevel, limit int) ([]
 
 		level := parseMacLogLevel(mEntry.MessageType)
 
-		if levelToPriority(level) > minSeverity {
+		if !level.AtLeast(minLevel) {
 			continue
 		}
 
@@ -178,26 +174,3 @@ func parseMacLogLevel(macType string) protocol.LogLevel {
 		return protocol.LevelInfo
 	}
 }
-
-func levelToPriority(l protocol.LogLevel) int {
-	switch l {
-	case protocol.LevelEmergency:
-		return 0
-	case protocol.LevelAlert:
-		return 1
-	case protocol.LevelCritical:
-		return 2
-	case protocol.LevelError:
-		return 3
-	case protocol.LevelWarning:
-		return 4
-	case protocol.LevelNotice:
-		return 5
-	case protocol.LevelInfo:
-		return 6
-	case protocol.LevelDebug:
-		return 7
-	default:
-		return 6
-	}
-}
@@ -42,12 +42,9 @@ func TestFetchLogs_Integration(t *testing.T) {
 			dedupCount++
 		}
 
-		priority := levelToPriority(entry.Level)
-		reqPriority := levelToPriority(req.MinLevel)
-
-		if priority > reqPriority {
+		if !entry.Level.AtLeast(req.MinLevel) {
 			t.Errorf("Log %d: Got severity %s (%d) but requested minimum was %s (%d)",
-				i, entry.Level, priority, req.MinLevel, reqPriority)
+				i, entry.Level, entry.Level.Severity(), req.MinLevel, req.MinLevel.Severity())
 		}
 	}
 
@@ -0,0 +1,134 @@
+//go:build linux
+
+package diagnostics
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// followPollInterval is how often a followed syslog file is checked for
+// newly appended lines.
+const followPollInterval = time.Second
+
+// FollowLogs streams new log entries at or above minLevel until ctx is
+// done, using `journalctl -f` where available and otherwise tailing the
+// syslog file. The returned channel is closed when following stops.
+func FollowLogs(ctx context.Context, minLevel protocol.LogLevel) (<-chan protocol.LogEntry, error) {
+	if _, err := exec.LookPath("journalctl"); err == nil {
+		return followJournal(ctx, minLevel)
+	}
+
+	for _, path := range syslogPaths {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		if _, err := f.Seek(0, io.SeekEnd); err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		out := make(chan protocol.LogEntry)
+		go func() {
+			defer f.Close()
+			followSyslogFrom(ctx, f, minLevel, followPollInterval, out)
+		}()
+		return out, nil
+	}
+
+	return nil, errors.New("no journald or syslog file to follow")
+}
+
+func followJournal(ctx context.Context, minLevel protocol.LogLevel) (<-chan protocol.LogEntry, error) {
+	priority := mapLogLevelToJournalPriority(minLevel)
+
+	//nolint:gosec // G204: priority is restricted to a fixed set.
+	cmd := exec.CommandContext(ctx, "journalctl", "-f", "-n", "0", "-p", priority, "-o", "json", "--no-pager")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	out := make(chan protocol.LogEntry)
+	go func() {
+		defer close(out)
+		defer func() { _ = cmd.Wait() }()
+
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			var jEntry journalEntry
+			if err := json.Unmarshal(scanner.Bytes(), &jEntry); err != nil || jEntry.Message == "" {
+				continue
+			}
+
+			entry := journalToEntry(jEntry, nil)
+			if entry.Timestamp == 0 {
+				entry.Timestamp = time.Now().Unix()
+			}
+
+			select {
+			case out <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// followSyslogFrom sends the entries parsed from lines appended to r,
+// polling for more every interval once it reaches EOF. It closes out when
+// ctx is done or r fails.
+func followSyslogFrom(ctx context.Context, r io.Reader, minLevel protocol.LogLevel, interval time.Duration, out chan<- protocol.LogEntry) {
+	defer close(out)
+
+	br := bufio.NewReader(r)
+	var pending strings.Builder
+
+	for {
+		chunk, err := br.ReadString('\n')
+		pending.WriteString(chunk)
+
+		if err == nil {
+			line := strings.TrimRight(pending.String(), "\r\n")
+			pending.Reset()
+
+			entry, ok := parseSyslogLine(line, time.Now())
+			if !ok || !entry.Level.AtLeast(minLevel) {
+				continue
+			}
+
+			select {
+			case out <- entry:
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		// A partial line stays pending until the rest is written
+		if err != io.EOF {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
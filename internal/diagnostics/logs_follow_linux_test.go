@@ -0,0 +1,74 @@
+//go:build linux
+
+package diagnostics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestFollowSyslogFrom(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "syslog")
+	if err := os.WriteFile(path, []byte("Mar 10 09:00:01 pi app[1]: first\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := make(chan protocol.LogEntry)
+	go followSyslogFrom(ctx, f, protocol.LevelInfo, 10*time.Millisecond, out)
+
+	recv := func() protocol.LogEntry {
+		t.Helper()
+		select {
+		case e, ok := <-out:
+			if !ok {
+				t.Fatal("channel closed early")
+			}
+			return e
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for entry")
+		}
+		return protocol.LogEntry{}
+	}
+
+	if e := recv(); e.Message != "first" {
+		t.Errorf("got %q, want first", e.Message)
+	}
+
+	// Append a line in two writes; it must only be parsed once complete
+	w, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.WriteString("Mar 10 09:00:02 pi app[1]: sec")
+	time.Sleep(50 * time.Millisecond)
+	w.WriteString("ond\n")
+
+	if e := recv(); e.Message != "second" || e.ProcessID != 1 {
+		t.Errorf("got %+v, want message second from pid 1", e)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Error("expected channel to close after cancel")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+}
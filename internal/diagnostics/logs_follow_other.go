@@ -0,0 +1,16 @@
+//go:build !linux
+
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// FollowLogs is only implemented on Linux.
+func FollowLogs(ctx context.Context, minLevel protocol.LogLevel) (<-chan protocol.LogEntry, error) {
+	return nil, fmt.Errorf("following logs is not supported on %s", runtime.GOOS)
+}
@@ -7,7 +7,6 @@ import (
 	"cmp"
 	"context"
 	"os"
-	"regexp"
 	"slices"
 	"strconv"
 	"strings"
@@ -32,10 +31,6 @@ var logSources = []struct {
 	{"/var/log/security", protocol.LevelInfo},
 }
 
-var reSyslog = regexp.MustCompile(
-	`^(\w{3}\s+\d{1,2}\s+\d{2}:\d{2}:\d{2})\s+\S+\s+(\S+?)(?:\[(\d+)])?:\s+(.+)$`,
-)
-
 func FetchLogs(ctx context.Context, opts protocol.LogRequest) ([]protocol.LogEntry, error) {
 	var results []protocol.LogEntry
 
@@ -164,42 +159,3 @@ func parseSyslogLines(lines []string, defaultLevel protocol.LogLevel) ([]protoco
 
 	return entries, nil
 }
-
-// parseSyslogTimestamp parses "Feb 19 12:24:47" with the current year.
-// BSD syslog timestamps don't include the year.
-func parseSyslogTimestamp(s string, now time.Time) int64 {
-	t, err := time.Parse("Jan _2 15:04:05", s)
-	if err != nil {
-		return 0
-	}
-	// Add current year
-	t = t.AddDate(now.Year(), 0, 0)
-	if t.After(now.Add(24 * time.Hour)) {
-		t = t.AddDate(-1, 0, 0)
-	}
-	return t.Unix()
-}
-
-// inferLevel attempts to determine the log level from the source
-// and message content, falling back to defaultLevel.
-func inferLevel(source, msg string, defaultLevel protocol.LogLevel) protocol.LogLevel {
-	lower := strings.ToLower(msg)
-
-	if source == "kernel" {
-		if strings.Contains(lower, "error") || strings.Contains(lower, "fail") {
-			return protocol.LevelError
-		}
-		if strings.Contains(lower, "warn") {
-			return protocol.LevelWarning
-		}
-	}
-
-	// Auth failures
-	if source == "sshd" || source == "login" || source == "su" {
-		if strings.Contains(lower, "fail") || strings.Contains(lower, "invalid") {
-			return protocol.LevelWarning
-		}
-	}
-
-	return defaultLevel
-}
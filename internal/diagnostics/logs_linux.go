@@ -8,12 +8,17 @@ import (
 	"cmp"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
@@ -31,30 +36,100 @@ var dmesgLevels = []string{
 
 const MaxLogs = 10000
 
+// syslogPaths are the plain-text logs read when journald is unavailable:
+// /var/log/syslog on Debian-based hosts, /var/log/messages on RHEL-based.
+var syslogPaths = []string{"/var/log/syslog", "/var/log/messages"}
+
 type journalEntry struct {
-	Message           string `json:"MESSAGE"`
-	SystemdUnit       string `json:"_SYSTEMD_UNIT"`
-	SyslogIdentifier  string `json:"SYSLOG_IDENTIFIER"`
-	Comm              string `json:"_COMM"`
-	PID               string `json:"_PID"`
-	Priority          string `json:"PRIORITY"`
-	RealtimeTimestamp string `json:"__REALTIME_TIMESTAMP"`
+	Message           journalMessage `json:"MESSAGE"`
+	SystemdUnit       string         `json:"_SYSTEMD_UNIT"`
+	SyslogIdentifier  string         `json:"SYSLOG_IDENTIFIER"`
+	Comm              string         `json:"_COMM"`
+	PID               string         `json:"_PID"`
+	Priority          string         `json:"PRIORITY"`
+	RealtimeTimestamp string         `json:"__REALTIME_TIMESTAMP"`
+}
+
+// journalMessage is a MESSAGE field. journalctl writes messages that
+// aren't valid UTF-8 text as an array of byte values instead of a string;
+// those are reassembled, or replaced by a placeholder if the bytes aren't
+// printable text.
+type journalMessage string
+
+func (m *journalMessage) UnmarshalJSON(b []byte) error {
+	switch {
+	case string(b) == "null":
+		return nil
+	case len(b) >= 2 && b[0] == '"' && bytes.IndexByte(b, '\\') < 0 && utf8.Valid(b):
+		// Fast path: a string with no escapes needs no decoding
+		*m = journalMessage(b[1 : len(b)-1])
+		return nil
+	case len(b) == 0 || b[0] != '[':
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*m = journalMessage(s)
+		return nil
+	}
+
+	var raw []byte
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	if isPrintableText(raw) {
+		*m = journalMessage(raw)
+	} else {
+		*m = journalMessage(fmt.Sprintf("[binary message, %d bytes]", len(raw)))
+	}
+	return nil
+}
+
+// isPrintableText reports whether b is valid UTF-8 made of printable runes
+// and whitespace. ESC is allowed too: colored output is the most common
+// reason journald stores a message as bytes.
+func isPrintableText(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		switch {
+		case r == '\n', r == '\t', r == '\r', r == '\x1b':
+		case !unicode.IsPrint(r):
+			return false
+		}
+	}
+	return true
 }
 
 func FetchLogs(ctx context.Context, opts protocol.LogRequest) ([]protocol.LogEntry, error) {
 	var results []protocol.LogEntry
 	remaining := MaxLogs
-
-	// Kernel Logs
-	if dmesg, err := getDmesg(ctx, opts.MinLevel, remaining); err == nil {
-		results = append(results, dmesg...)
-		remaining -= len(dmesg)
+	remainingBytes := opts.MaxBytes
+
+	// Kernel Logs. dmesg only covers the current boot, so it is skipped
+	// when a specific boot is requested; journald has the kernel messages
+	// for that boot anyway.
+	if opts.Boot == nil {
+		if dmesg, err := getDmesg(ctx, opts.MinLevel, remaining, remainingBytes); err == nil {
+			results = append(results, dmesg...)
+			remaining -= len(dmesg)
+			if opts.MaxBytes > 0 {
+				remainingBytes -= messageBytes(dmesg)
+			}
+		}
 	}
 
-	// Journal Logs
-	if remaining > 0 {
-		if journal, err := getJournal(ctx, opts.MinLevel, remaining); err == nil {
+	// Journal Logs, falling back to the syslog file on non-systemd hosts.
+	// The file can't select a boot, so there is no fallback for one.
+	if remaining > 0 && (opts.MaxBytes <= 0 || remainingBytes > 0) {
+		if journal, err := getJournal(ctx, opts.MinLevel, opts.Boot, remaining, remainingBytes); err == nil {
 			results = append(results, journal...)
+		} else if opts.Boot == nil {
+			if syslog, err := getSyslog(opts.MinLevel, remaining, remainingBytes); err == nil {
+				results = append(results, syslog...)
+			}
 		}
 	}
 
@@ -73,36 +148,103 @@ func FetchLogs(ctx context.Context, opts protocol.LogRequest) ([]protocol.LogEnt
 	return results, nil
 }
 
-func getDmesg(ctx context.Context, minLevel protocol.LogLevel, limit int) ([]protocol.LogEntry, error) {
+func getDmesg(ctx context.Context, minLevel protocol.LogLevel, limit, maxBytes int) ([]protocol.LogEntry, error) {
 	levelFlag := buildDmesgLevelFlag(minLevel)
 	//nolint:gosec // G204: levelFlag is restricted to valid dmesg levels.
 	cmd := exec.CommandContext(ctx, "dmesg", "-T", "-x", "--level="+levelFlag)
 
-	out, err := cmd.Output()
+	return streamCommand(cmd, func(r io.Reader) ([]protocol.LogEntry, error) {
+		// dmesg -T prints wall-clock time in the host's zone
+		return parseDmesgFrom(r, minLevel, limit, maxBytes, time.Local)
+	})
+}
+
+func getJournal(ctx context.Context, minLevel protocol.LogLevel, boot *int, limit, maxBytes int) ([]protocol.LogEntry, error) {
+	priority := mapLogLevelToJournalPriority(minLevel)
+
+	//nolint:gosec // G204: arguments are built from a fixed priority set and integers.
+	cmd := exec.CommandContext(ctx, "journalctl", buildJournalArgs(priority, boot, limit)...)
+
+	// journalctl prints newest-first, so parsing can stop at the budget
+	// without losing recent entries
+	entries, err := streamCommand(cmd, func(r io.Reader) ([]protocol.LogEntry, error) {
+		return parseJournalFrom(r, limit, maxBytes)
+	})
+	slices.Reverse(entries)
+	return entries, err
+}
+
+// streamCommand starts cmd and hands its stdout to parse, so the output is
+// never held in full. If parse stops before the end, cmd is killed rather
+// than left blocked on a full pipe. Otherwise a failed exit is returned as
+// an error, as cmd.Output would.
+func streamCommand(cmd *exec.Cmd, parse func(io.Reader) ([]protocol.LogEntry, error)) ([]protocol.LogEntry, error) {
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
 	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	r := &eofReader{r: stdout}
+	entries, parseErr := parse(r)
 
-	return parseDmesgFrom(bytes.NewReader(out), limit)
+	if !r.eof {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return entries, parseErr
+	}
+	if err := cmd.Wait(); err != nil {
+		return nil, err
+	}
+	return entries, parseErr
 }
 
-func getJournal(ctx context.Context, minLevel protocol.LogLevel, limit int) ([]protocol.LogEntry, error) {
-	priority := mapLogLevelToJournalPriority(minLevel)
+// eofReader records whether the underlying reader was read to the end.
+type eofReader struct {
+	r   io.Reader
+	eof bool
+}
+
+func (e *eofReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if err == io.EOF {
+		e.eof = true
+	}
+	return n, err
+}
+
+func getSyslog(minLevel protocol.LogLevel, limit, maxBytes int) ([]protocol.LogEntry, error) {
+	var lastErr error
+	for _, path := range syslogPaths {
+		f, err := os.Open(path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		defer f.Close()
+
+		return parseSyslogFrom(f, minLevel, limit, maxBytes, time.Now())
+	}
+	return nil, lastErr
+}
 
-	cmd := exec.CommandContext(ctx, "journalctl",
-		"-b",
+// buildJournalArgs returns the journalctl arguments for the newest limit
+// entries, newest first. A nil boot keeps the bare -b; otherwise the
+// selector is passed through as `-b <n>`.
+func buildJournalArgs(priority string, boot *int, limit int) []string {
+	args := []string{"-b"}
+	if boot != nil {
+		args = append(args, strconv.Itoa(*boot))
+	}
+	return append(args,
 		"-p", priority,
 		"-n", strconv.Itoa(limit),
 		"-o", "json",
+		"--reverse",
 		"--no-pager",
 	)
-
-	out, err := cmd.Output()
-	if err != nil {
-		return nil, err
-	}
-
-	return parseJournalFrom(bytes.NewReader(out), limit)
 }
 
 // buildDmesgLevelFlag returns a comma-separated string of all levels
@@ -132,9 +274,24 @@ func buildDmesgLevelFlag(min protocol.LogLevel) string {
 	return strings.Join(dmesgLevels[startIdx:], ",")
 }
 
-// parseDmesgFrom parses the raw output of `dmesg -T -x`
-func parseDmesgFrom(r io.Reader, limit int) ([]protocol.LogEntry, error) {
+// messageBytes sums the message lengths of entries.
+func messageBytes(entries []protocol.LogEntry) int {
+	n := 0
+	for _, e := range entries {
+		n += len(e.Message)
+	}
+	return n
+}
+
+// parseDmesgFrom parses the raw output of `dmesg -T -x`. loc is the zone
+// that ctime timestamps were printed in. Entries below minLevel are dropped
+// even though the --level flag should already exclude them. dmesg prints
+// oldest-first and can't be reversed, so the whole input is read and the
+// newest limit entries are kept, further trimmed to fit maxBytes (if
+// positive). Only the kept entries are held in memory.
+func parseDmesgFrom(r io.Reader, minLevel protocol.LogLevel, limit, maxBytes int, loc *time.Location) ([]protocol.LogEntry, error) {
 	var entries []protocol.LogEntry
+	var usedBytes int
 	scanner := bufio.NewScanner(r)
 
 	var sourceBuilder strings.Builder
@@ -144,9 +301,6 @@ func parseDmesgFrom(r io.Reader, limit int) ([]protocol.LogEntry, error) {
 	var lastTimestamp int64 = 0
 
 	for scanner.Scan() {
-		if len(entries) >= limit {
-			break
-		}
 		line := scanner.Text()
 
 		parts := strings.SplitN(line, ":", 3)
@@ -156,7 +310,7 @@ func parseDmesgFrom(r io.Reader, limit int) ([]protocol.LogEntry, error) {
 
 		level := parseDmesgLevel(strings.TrimSpace(parts[1]))
 		raw := strings.TrimSpace(parts[2])
-		timestamp, msg := parseDmesgTimestampAndMsg(raw)
+		timestamp, msg := parseDmesgTimestampAndMsg(raw, loc)
 
 		if timestamp == 0 {
 			timestamp = lastTimestamp
@@ -164,7 +318,7 @@ func parseDmesgFrom(r io.Reader, limit int) ([]protocol.LogEntry, error) {
 			lastTimestamp = timestamp
 		}
 
-		if msg == "" {
+		if msg == "" || !level.AtLeast(minLevel) {
 			continue
 		}
 
@@ -183,9 +337,15 @@ func parseDmesgFrom(r io.Reader, limit int) ([]protocol.LogEntry, error) {
 			Level:     level,
 			Message:   msg,
 		})
+		usedBytes += len(msg)
+
+		for len(entries) > limit || (maxBytes > 0 && usedBytes > maxBytes && len(entries) > 0) {
+			usedBytes -= len(entries[0].Message)
+			entries = entries[1:]
+		}
 	}
 
-	return entries, nil
+	return entries, scanner.Err()
 }
 
 func parseDmesgLevel(level string) protocol.LogLevel {
@@ -211,8 +371,69 @@ func parseDmesgLevel(level string) protocol.LogLevel {
 	}
 }
 
+// dmesgBootTime returns the host boot time, used to convert the default
+// seconds-since-boot dmesg timestamps. It is a variable so tests can pin it.
+var dmesgBootTime = sync.OnceValue(func() time.Time {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return time.Time{}
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return time.Time{}
+	}
+	uptime, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Now().Add(-time.Duration(uptime * float64(time.Second)))
+})
+
+// dmesgISOLayouts covers `dmesg --time-format=iso`, which uses a comma
+// before the fractional seconds (normalized to a dot before parsing).
+var dmesgISOLayouts = []string{
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-0700",
+	time.RFC3339Nano,
+}
+
+// parseDmesgTimestamp parses the bracketed dmesg timestamp in any of the
+// ctime (-T), ISO 8601 (--time-format=iso) or seconds-since-boot (default)
+// forms. ctime carries no zone, so it is interpreted in loc. It returns 0
+// if the timestamp is not recognized.
+func parseDmesgTimestamp(timeStr string, loc *time.Location) int64 {
+	timeStr = strings.TrimSpace(timeStr)
+	if timeStr == "" {
+		return 0
+	}
+
+	if parsed, err := time.ParseInLocation(time.ANSIC, timeStr, loc); err == nil {
+		return parsed.Unix()
+	}
+
+	if strings.Contains(timeStr, "T") {
+		iso := strings.Replace(timeStr, ",", ".", 1)
+		for _, layout := range dmesgISOLayouts {
+			if parsed, err := time.Parse(layout, iso); err == nil {
+				return parsed.Unix()
+			}
+		}
+		return 0
+	}
+
+	if secs, err := strconv.ParseFloat(timeStr, 64); err == nil && secs >= 0 {
+		boot := dmesgBootTime()
+		if boot.IsZero() {
+			return 0
+		}
+		return boot.Add(time.Duration(secs * float64(time.Second))).Unix()
+	}
+
+	return 0
+}
+
 // parseDmesgTimestampAndMsg extracts "[<DATE>] <MESSAGE>"
-func parseDmesgTimestampAndMsg(raw string) (int64, string) {
+func parseDmesgTimestampAndMsg(raw string, loc *time.Location) (int64, string) {
 	start := strings.Index(raw, "[")
 	end := strings.Index(raw, "]")
 
@@ -220,11 +441,7 @@ func parseDmesgTimestampAndMsg(raw string) (int64, string) {
 	msg := raw
 
 	if start != -1 && end != -1 && end > start {
-		timeStr := raw[start+1 : end]
-
-		if parsed, err := time.Parse(time.ANSIC, strings.TrimSpace(timeStr)); err == nil {
-			timestamp = parsed.Unix()
-		}
+		timestamp = parseDmesgTimestamp(raw[start+1:end], loc)
 
 		if len(raw) > end+1 {
 			msg = strings.TrimSpace(raw[end+1:])
@@ -236,25 +453,29 @@ func parseDmesgTimestampAndMsg(raw string) (int64, string) {
 	return timestamp, msg
 }
 
-// parseJournalFrom reads JSON from journalctl -o json
-func parseJournalFrom(r io.Reader, limit int) ([]protocol.LogEntry, error) {
+// parseJournalFrom reads JSON from journalctl -o json, keeping entries in
+// input order. It stops reading after limit entries or once the messages
+// would exceed maxBytes (if positive), so the input should be newest-first
+// for the newest entries to be kept.
+func parseJournalFrom(r io.Reader, limit, maxBytes int) ([]protocol.LogEntry, error) {
 	var entries []protocol.LogEntry
+	var usedBytes int
 	scanner := bufio.NewScanner(r)
-	var sourceBuilder strings.Builder
 	var lastTimestamp int64 = 0
 
-	sourceBuilder.Grow(64)
+	// Reused across lines so each decode doesn't allocate a fresh record;
+	// sources caches the prefixed source names, which repeat heavily.
+	var jEntry journalEntry
+	sources := make(map[string]string)
 
-	for scanner.Scan() {
-		if len(entries) >= limit {
-			break
-		}
+	// Check the limit before scanning so no input is read past it
+	for len(entries) < limit && scanner.Scan() {
 		line := scanner.Bytes()
 		if len(line) == 0 {
 			continue
 		}
 
-		var jEntry journalEntry
+		jEntry = journalEntry{}
 		if err := json.Unmarshal(line, &jEntry); err != nil {
 			continue
 		}
@@ -263,52 +484,125 @@ func parseJournalFrom(r io.Reader, limit int) ([]protocol.LogEntry, error) {
 			continue
 		}
 
-		sourceBuilder.Reset()
-		sourceBuilder.WriteString("journald:")
-
-		if jEntry.SystemdUnit != "" {
-			sourceBuilder.WriteString(jEntry.SystemdUnit)
-		} else if jEntry.SyslogIdentifier != "" {
-			sourceBuilder.WriteString(jEntry.SyslogIdentifier)
-		} else if jEntry.Comm != "" {
-			sourceBuilder.WriteString(jEntry.Comm)
+		if maxBytes > 0 && usedBytes+len(jEntry.Message) > maxBytes {
+			break
+		}
+		usedBytes += len(jEntry.Message)
+
+		entry := journalToEntry(jEntry, sources)
+		if entry.Timestamp == 0 {
+			entry.Timestamp = lastTimestamp
 		} else {
-			sourceBuilder.WriteString("unknown")
+			lastTimestamp = entry.Timestamp
 		}
 
-		pid, _ := strconv.Atoi(jEntry.PID)
+		entries = append(entries, entry)
+	}
 
-		level := protocol.LevelInfo
-		if jEntry.Priority != "" {
-			if priorityInt, err := strconv.Atoi(jEntry.Priority); err == nil {
-				if l, exists := protocol.PriorityToLevel[priorityInt]; exists {
-					level = l
-				}
-			}
+	return entries, nil
+}
+
+// parseSyslogFrom parses a plain syslog file in the BSD format. The file
+// carries no severity, so levels are inferred from the content with a
+// default of info, and entries below minLevel are dropped. Since the file
+// is oldest-first, the newest limit entries are kept, further trimmed to
+// fit maxBytes (if positive).
+func parseSyslogFrom(r io.Reader, minLevel protocol.LogLevel, limit, maxBytes int, now time.Time) ([]protocol.LogEntry, error) {
+	var entries []protocol.LogEntry
+	var usedBytes int
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		entry, ok := parseSyslogLine(scanner.Text(), now)
+		if !ok || !entry.Level.AtLeast(minLevel) {
+			continue
 		}
 
-		var timestamp int64
-		if timestampInt, err := strconv.ParseInt(jEntry.RealtimeTimestamp, 10, 64); err == nil {
-			timestamp = timestampInt / 1000000
+		entries = append(entries, entry)
+		usedBytes += len(entry.Message)
+
+		for len(entries) > limit || (maxBytes > 0 && usedBytes > maxBytes && len(entries) > 0) {
+			usedBytes -= len(entries[0].Message)
+			entries = entries[1:]
 		}
+	}
 
-		if timestamp == 0 {
-			timestamp = lastTimestamp
-		} else {
-			lastTimestamp = timestamp
+	return entries, scanner.Err()
+}
+
+// journalToEntry converts a journalctl JSON record. The timestamp is 0 if
+// the record has none. A non-nil sources map caches the "journald:"
+// source strings across calls.
+func journalToEntry(j journalEntry, sources map[string]string) protocol.LogEntry {
+	source := "unknown"
+	switch {
+	case j.SystemdUnit != "":
+		source = j.SystemdUnit
+	case j.SyslogIdentifier != "":
+		source = j.SyslogIdentifier
+	case j.Comm != "":
+		source = j.Comm
+	}
+
+	pid, _ := strconv.Atoi(j.PID)
+
+	level := protocol.LevelInfo
+	if j.Priority != "" {
+		if priorityInt, err := strconv.Atoi(j.Priority); err == nil {
+			if l, exists := protocol.PriorityToLevel[priorityInt]; exists {
+				level = l
+			}
 		}
+	}
 
-		entries = append(entries, protocol.LogEntry{
-			Timestamp:   timestamp,
-			Source:      sourceBuilder.String(),
-			Level:       level,
-			Message:     jEntry.Message,
-			ProcessName: jEntry.Comm,
-			ProcessID:   pid,
-		})
+	var timestamp int64
+	if timestampInt, err := strconv.ParseInt(j.RealtimeTimestamp, 10, 64); err == nil {
+		timestamp = timestampInt / 1000000
 	}
 
-	return entries, nil
+	prefixed, ok := sources[source]
+	if !ok {
+		prefixed = "journald:" + source
+		if sources != nil {
+			sources[source] = prefixed
+		}
+	}
+
+	return protocol.LogEntry{
+		Timestamp:   timestamp,
+		Source:      prefixed,
+		Level:       level,
+		Message:     string(j.Message),
+		ProcessName: j.Comm,
+		ProcessID:   pid,
+	}
+}
+
+// parseSyslogLine parses one BSD-format syslog line, inferring its level
+// with a default of info. Unparseable and "last message repeated" lines
+// report false.
+func parseSyslogLine(line string, now time.Time) (protocol.LogEntry, bool) {
+	if line == "" || strings.Contains(line, "last message repeated") {
+		return protocol.LogEntry{}, false
+	}
+
+	m := reSyslog.FindStringSubmatch(line)
+	if m == nil {
+		return protocol.LogEntry{}, false
+	}
+
+	source := m[2]
+	msg := m[4]
+	pid, _ := strconv.Atoi(m[3])
+
+	return protocol.LogEntry{
+		Timestamp:   parseSyslogTimestamp(m[1], now),
+		Source:      "syslog:" + source,
+		Level:       inferLevel(source, msg, protocol.LevelInfo),
+		Message:     msg,
+		ProcessName: source,
+		ProcessID:   pid,
+	}, true
 }
 
 func mapLogLevelToJournalPriority(l protocol.LogLevel) string {
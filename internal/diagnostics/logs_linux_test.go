@@ -4,8 +4,13 @@ package diagnostics
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
@@ -113,11 +118,45 @@ func TestParseDmesgTimestampAndMsg(t *testing.T) {
 			expectedTS:  1736164800,
 			expectedMsg: "usb 1-1: new device [USB]",
 		},
+		{
+			name:        "iso timestamp",
+			input:       "[2025-01-06T12:00:00,123456+00:00] Some kernel message here",
+			expectedTS:  1736164800,
+			expectedMsg: "Some kernel message here",
+		},
+		{
+			name:        "iso timestamp with offset",
+			input:       "[2025-01-06T13:00:00,000000+0100] eth0: link up",
+			expectedTS:  1736164800,
+			expectedMsg: "eth0: link up",
+		},
+		{
+			name:        "uptime timestamp",
+			input:       "[   12.345678] Booting Linux on physical CPU 0x0",
+			expectedTS:  1736164812,
+			expectedMsg: "Booting Linux on physical CPU 0x0",
+		},
+		{
+			name:        "uptime timestamp at boot",
+			input:       "[    0.000000] Linux version 6.1.0",
+			expectedTS:  1736164800,
+			expectedMsg: "Linux version 6.1.0",
+		},
+		{
+			name:        "malformed iso timestamp",
+			input:       "[2025-13-45T99:00:00] Some message",
+			expectedTS:  0,
+			expectedMsg: "Some message",
+		},
 	}
 
+	origBootTime := dmesgBootTime
+	dmesgBootTime = func() time.Time { return time.Unix(1736164800, 0) }
+	defer func() { dmesgBootTime = origBootTime }()
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ts, msg := parseDmesgTimestampAndMsg(tt.input)
+			ts, msg := parseDmesgTimestampAndMsg(tt.input, time.UTC)
 			if ts != tt.expectedTS {
 				t.Errorf("timestamp: got %d, want %d", ts, tt.expectedTS)
 			}
@@ -128,6 +167,35 @@ func TestParseDmesgTimestampAndMsg(t *testing.T) {
 	}
 }
 
+func TestParseDmesgTimestampAndMsg_Location(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	const input = "[Mon Jan  6 12:00:00 2025] Some kernel message here"
+
+	tests := []struct {
+		name       string
+		loc        *time.Location
+		expectedTS int64
+	}{
+		{"utc", time.UTC, 1736164800},
+		{"new york (UTC-5)", newYork, 1736164800 + 5*3600},
+		{"tokyo (UTC+9)", tokyo, 1736164800 - 9*3600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, _ := parseDmesgTimestampAndMsg(input, tt.loc)
+			if ts != tt.expectedTS {
+				t.Errorf("timestamp: got %d, want %d", ts, tt.expectedTS)
+			}
+		})
+	}
+}
+
 func TestParseDmesgFrom(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -257,7 +325,7 @@ kern  :warn  : [Mon Jan  6 12:00:01 2025] Another valid message`,
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseDmesgFrom(strings.NewReader(tt.input), 10000)
+			got, err := parseDmesgFrom(strings.NewReader(tt.input), protocol.LevelDebug, 10000, 0, time.UTC)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -417,6 +485,22 @@ func TestParseJournalFrom(t *testing.T) {
 				{Timestamp: 1736164801, Source: "journald:unknown", Level: protocol.LevelInfo, Message: "Also valid"},
 			},
 		},
+		{
+			name: "fields do not carry over between records",
+			input: `{"MESSAGE":"First","_SYSTEMD_UNIT":"a.service","_COMM":"a","_PID":"10","PRIORITY":"3","__REALTIME_TIMESTAMP":"1736164800000000"}
+{"MESSAGE":"Second","__REALTIME_TIMESTAMP":"1736164801000000"}`,
+			expected: []protocol.LogEntry{
+				{Timestamp: 1736164800, Source: "journald:a.service", Level: protocol.LevelError, Message: "First", ProcessName: "a", ProcessID: 10},
+				{Timestamp: 1736164801, Source: "journald:unknown", Level: protocol.LevelInfo, Message: "Second"},
+			},
+		},
+		{
+			name:  "unused fields ignored",
+			input: `{"__CURSOR":"s=abc;i=1","_BOOT_ID":"f00","_HOSTNAME":"web-01","_UID":"0","_SOURCE_MONOTONIC_TIMESTAMP":12345,"_CMDLINE":["/usr/sbin/sshd","-D"],"_EXTRA":{"k":"v"},"MESSAGE":"Accepted publickey","_SYSTEMD_UNIT":"ssh.service","_COMM":"sshd","_PID":"812","PRIORITY":"6","__REALTIME_TIMESTAMP":"1736164800000000"}`,
+			expected: []protocol.LogEntry{
+				{Timestamp: 1736164800, Source: "journald:ssh.service", Level: protocol.LevelInfo, Message: "Accepted publickey", ProcessName: "sshd", ProcessID: 812},
+			},
+		},
 		{
 			name:  "unknown priority defaults to info",
 			input: `{"MESSAGE":"Test","PRIORITY":"99","__REALTIME_TIMESTAMP":"1736164800000000"}`,
@@ -466,7 +550,7 @@ func TestParseJournalFrom(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseJournalFrom(strings.NewReader(tt.input), 10000)
+			got, err := parseJournalFrom(strings.NewReader(tt.input), 10000, 0)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -525,6 +609,30 @@ func TestMapLogLevelToJournalPriority(t *testing.T) {
 	}
 }
 
+func TestBuildJournalArgs(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+
+	tests := []struct {
+		name     string
+		boot     *int
+		expected []string
+	}{
+		{"default", nil, []string{"-b", "-p", "3", "-n", "100", "-o", "json", "--reverse", "--no-pager"}},
+		{"current boot", intPtr(0), []string{"-b", "0", "-p", "3", "-n", "100", "-o", "json", "--reverse", "--no-pager"}},
+		{"previous boot", intPtr(-1), []string{"-b", "-1", "-p", "3", "-n", "100", "-o", "json", "--reverse", "--no-pager"}},
+		{"first boot", intPtr(1), []string{"-b", "1", "-p", "3", "-n", "100", "-o", "json", "--reverse", "--no-pager"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildJournalArgs("3", tt.boot, 100)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("got %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestFetchLogs_Integration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -600,7 +708,7 @@ func BenchmarkParseDmesgTimestampAndMsg(b *testing.B) {
 
 	b.ReportAllocs()
 	for b.Loop() {
-		_, _ = parseDmesgTimestampAndMsg(input)
+		_, _ = parseDmesgTimestampAndMsg(input, time.UTC)
 	}
 }
 
@@ -609,7 +717,7 @@ func BenchmarkParseDmesgTimestampAndMsg_NoTimestamp(b *testing.B) {
 
 	b.ReportAllocs()
 	for b.Loop() {
-		_, _ = parseDmesgTimestampAndMsg(input)
+		_, _ = parseDmesgTimestampAndMsg(input, time.UTC)
 	}
 }
 
@@ -650,7 +758,7 @@ kern  :info  : [Mon Jan  6 12:00:04 2025] Message 5`
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseDmesgFrom(strings.NewReader(input), tt.limit)
+			got, err := parseDmesgFrom(strings.NewReader(input), protocol.LevelDebug, tt.limit, 0, time.UTC)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -661,6 +769,76 @@ kern  :info  : [Mon Jan  6 12:00:04 2025] Message 5`
 	}
 }
 
+func TestParseDmesgFrom_MinLevel(t *testing.T) {
+	input := `kern  :debug : [Mon Jan  6 12:00:00 2025] probing
+user  :info  : [Mon Jan  6 12:00:01 2025] session opened
+kern  :warn  : [Mon Jan  6 12:00:02 2025] temperature above threshold
+daemon:err   : [Mon Jan  6 12:00:03 2025] service failed
+kern  :emerg : [Mon Jan  6 12:00:04 2025] panic`
+
+	tests := []struct {
+		min  protocol.LogLevel
+		want []protocol.LogLevel
+	}{
+		{protocol.LevelDebug, []protocol.LogLevel{protocol.LevelDebug, protocol.LevelInfo, protocol.LevelWarning, protocol.LevelError, protocol.LevelEmergency}},
+		{protocol.LevelWarning, []protocol.LogLevel{protocol.LevelWarning, protocol.LevelError, protocol.LevelEmergency}},
+		{protocol.LevelError, []protocol.LogLevel{protocol.LevelError, protocol.LevelEmergency}},
+		{protocol.LevelEmergency, []protocol.LogLevel{protocol.LevelEmergency}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.min), func(t *testing.T) {
+			got, err := parseDmesgFrom(strings.NewReader(input), tt.min, 10000, 0, time.UTC)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d entries, want %d", len(got), len(tt.want))
+			}
+			for i, e := range got {
+				if e.Level != tt.want[i] {
+					t.Errorf("entry %d level = %s, want %s", i, e.Level, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseDmesgFrom_MaxBytes(t *testing.T) {
+	big := strings.Repeat("x", 1000)
+	var sb strings.Builder
+	for range 10 {
+		sb.WriteString("kern  :info  : [Mon Jan  6 12:00:00 2025] " + big + "\n")
+	}
+	input := sb.String()
+
+	tests := []struct {
+		name     string
+		maxBytes int
+		want     int
+	}{
+		{"no byte limit", 0, 10},
+		{"budget fits three", 3500, 3},
+		{"budget exactly two", 2000, 2},
+		{"budget smaller than one", 999, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDmesgFrom(strings.NewReader(input), protocol.LevelDebug, 10000, tt.maxBytes, time.UTC)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("got %d entries, want %d", len(got), tt.want)
+			}
+			if tt.maxBytes > 0 && messageBytes(got) > tt.maxBytes {
+				t.Errorf("message bytes %d exceed budget %d", messageBytes(got), tt.maxBytes)
+			}
+		})
+	}
+}
+
 func BenchmarkParseDmesgFrom_Small(b *testing.B) {
 	input := `kern  :info  : [Mon Jan  6 12:00:00 2025] Message one
 kern  :warn  : [Mon Jan  6 12:00:01 2025] Message two
@@ -668,7 +846,7 @@ kern  :err   : [Mon Jan  6 12:00:02 2025] Message three`
 
 	b.ReportAllocs()
 	for b.Loop() {
-		_, _ = parseDmesgFrom(strings.NewReader(input), 10000)
+		_, _ = parseDmesgFrom(strings.NewReader(input), protocol.LevelDebug, 10000, 0, time.UTC)
 	}
 }
 
@@ -684,7 +862,46 @@ func BenchmarkParseDmesgFrom_Large(b *testing.B) {
 	b.ReportAllocs()
 	b.ResetTimer()
 	for b.Loop() {
-		_, _ = parseDmesgFrom(strings.NewReader(input), 10000)
+		_, _ = parseDmesgFrom(strings.NewReader(input), protocol.LevelDebug, 10000, 0, time.UTC)
+	}
+}
+
+func TestParseJournalFrom_ByteArrayMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want string
+	}{
+		{"utf8 text", `[104,195,169,108,108,111]`, "héllo"},
+		{"ansi colored", `[27,91,51,49,109,101,114,114,27,91,48,109]`, "\x1b[31merr\x1b[0m"},
+		{"invalid utf8", `[104,105,255,254]`, "[binary message, 4 bytes]"},
+		{"control bytes", `[0,1,2,3,4]`, "[binary message, 5 bytes]"},
+		{"null message", `null`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := `{"MESSAGE":` + tt.msg + `,"_SYSTEMD_UNIT":"app.service","PRIORITY":"6","__REALTIME_TIMESTAMP":"1736164800000000"}`
+			got, err := parseJournalFrom(strings.NewReader(input), 10000, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.want == "" {
+				if len(got) != 0 {
+					t.Errorf("got %d entries, want empty message dropped", len(got))
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("got %d entries, want 1", len(got))
+			}
+			if got[0].Message != tt.want {
+				t.Errorf("Message = %q, want %q", got[0].Message, tt.want)
+			}
+			if got[0].Source != "journald:app.service" {
+				t.Errorf("Source = %q", got[0].Source)
+			}
+		})
 	}
 }
 
@@ -708,7 +925,7 @@ func TestParseJournalFrom_Limit(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseJournalFrom(strings.NewReader(input), tt.limit)
+			got, err := parseJournalFrom(strings.NewReader(input), tt.limit, 0)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -719,13 +936,298 @@ func TestParseJournalFrom_Limit(t *testing.T) {
 	}
 }
 
+func TestParseJournalFrom_MaxBytes(t *testing.T) {
+	big := strings.Repeat("y", 4096)
+	var sb strings.Builder
+	for range 8 {
+		sb.WriteString(`{"MESSAGE":"` + big + `","PRIORITY":"6","__REALTIME_TIMESTAMP":"1736164800000000"}` + "\n")
+	}
+	input := sb.String()
+
+	tests := []struct {
+		name     string
+		maxBytes int
+		want     int
+	}{
+		{"no byte limit", 0, 8},
+		{"budget fits five", 5*4096 + 100, 5},
+		{"budget smaller than one", 100, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseJournalFrom(strings.NewReader(input), 10000, tt.maxBytes)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("got %d entries, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+// stopReader fails the test if a parser reads past the input it needs.
+type stopReader struct{ t *testing.T }
+
+func (r stopReader) Read([]byte) (int, error) {
+	r.t.Error("read past the point where parsing should have stopped")
+	return 0, io.EOF
+}
+
+func TestParseJournalFrom_StopsEarly(t *testing.T) {
+	lines := `{"MESSAGE":"Msg 5","__REALTIME_TIMESTAMP":"1736164804000000"}
+{"MESSAGE":"Msg 4","__REALTIME_TIMESTAMP":"1736164803000000"}
+{"MESSAGE":"Msg 3","__REALTIME_TIMESTAMP":"1736164802000000"}
+`
+
+	t.Run("limit", func(t *testing.T) {
+		got, err := parseJournalFrom(io.MultiReader(strings.NewReader(lines), stopReader{t}), 3, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 3 || got[0].Message != "Msg 5" || got[2].Message != "Msg 3" {
+			t.Errorf("got %+v, want Msg 5..3 in input order", got)
+		}
+	})
+
+	t.Run("max bytes", func(t *testing.T) {
+		// The third message is read to find it doesn't fit; nothing after it
+		got, err := parseJournalFrom(io.MultiReader(strings.NewReader(lines), stopReader{t}), 100, 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 2 || got[0].Message != "Msg 5" || got[1].Message != "Msg 4" {
+			t.Errorf("got %+v, want Msg 5 and Msg 4", got)
+		}
+	})
+}
+
+func TestParseDmesgFrom_KeepsNewest(t *testing.T) {
+	input := `kern  :info  : [Mon Jan  6 12:00:00 2025] Message 1
+kern  :info  : [Mon Jan  6 12:00:01 2025] Message 2
+kern  :info  : [Mon Jan  6 12:00:02 2025] Message 3
+kern  :info  : [Mon Jan  6 12:00:03 2025] Message 4
+kern  :info  : [Mon Jan  6 12:00:04 2025] Message 5`
+
+	tests := []struct {
+		name     string
+		limit    int
+		maxBytes int
+		want     []string
+	}{
+		{"limit", 2, 0, []string{"Message 4", "Message 5"}},
+		{"max bytes", 100, 20, []string{"Message 4", "Message 5"}},
+		{"both", 1, 20, []string{"Message 5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDmesgFrom(strings.NewReader(input), protocol.LevelDebug, tt.limit, tt.maxBytes, time.UTC)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var msgs []string
+			for _, e := range got {
+				msgs = append(msgs, e.Message)
+			}
+			if !slices.Equal(msgs, tt.want) {
+				t.Errorf("got %q, want %q", msgs, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamCommand(t *testing.T) {
+	parseJournal := func(limit int) func(io.Reader) ([]protocol.LogEntry, error) {
+		return func(r io.Reader) ([]protocol.LogEntry, error) {
+			return parseJournalFrom(r, limit, 0)
+		}
+	}
+
+	t.Run("stops endless output", func(t *testing.T) {
+		if _, err := exec.LookPath("yes"); err != nil {
+			t.Skip("yes not available")
+		}
+		cmd := exec.Command("yes", `{"MESSAGE":"again","__REALTIME_TIMESTAMP":"1736164800000000"}`)
+
+		done := make(chan struct{})
+		var got []protocol.LogEntry
+		var err error
+		go func() {
+			got, err = streamCommand(cmd, parseJournal(5))
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatal("streamCommand did not return after the limit")
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 5 {
+			t.Errorf("got %d entries, want 5", len(got))
+		}
+	})
+
+	t.Run("reads to end", func(t *testing.T) {
+		cmd := exec.Command("sh", "-c", `printf '{"MESSAGE":"a"}\n{"MESSAGE":"b"}\n'`)
+		got, err := streamCommand(cmd, parseJournal(100))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 2 {
+			t.Errorf("got %d entries, want 2", len(got))
+		}
+	})
+
+	t.Run("failed command", func(t *testing.T) {
+		cmd := exec.Command("sh", "-c", "exit 3")
+		if _, err := streamCommand(cmd, parseJournal(100)); err == nil {
+			t.Error("expected error for non-zero exit")
+		}
+	})
+}
+
+func TestParseSyslogFrom(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		line     string
+		minLevel protocol.LogLevel
+		want     *protocol.LogEntry
+	}{
+		{
+			name: "with pid",
+			line: "Mar 10 09:15:02 pi sshd[812]: Accepted publickey for pi from 10.0.0.5",
+			want: &protocol.LogEntry{
+				Timestamp:   time.Date(2025, time.March, 10, 9, 15, 2, 0, time.UTC).Unix(),
+				Source:      "syslog:sshd",
+				Level:       protocol.LevelInfo,
+				Message:     "Accepted publickey for pi from 10.0.0.5",
+				ProcessName: "sshd",
+				ProcessID:   812,
+			},
+		},
+		{
+			name: "without pid",
+			line: "Mar  9 23:59:59 pi kernel: usb 1-1: new high-speed USB device",
+			want: &protocol.LogEntry{
+				Timestamp:   time.Date(2025, time.March, 9, 23, 59, 59, 0, time.UTC).Unix(),
+				Source:      "syslog:kernel",
+				Level:       protocol.LevelInfo,
+				Message:     "usb 1-1: new high-speed USB device",
+				ProcessName: "kernel",
+			},
+		},
+		{
+			name: "inferred level",
+			line: "Mar 10 10:00:00 pi kernel: mmc0: error -110 whilst initialising SD card",
+			want: &protocol.LogEntry{
+				Timestamp:   time.Date(2025, time.March, 10, 10, 0, 0, 0, time.UTC).Unix(),
+				Source:      "syslog:kernel",
+				Level:       protocol.LevelError,
+				Message:     "mmc0: error -110 whilst initialising SD card",
+				ProcessName: "kernel",
+			},
+		},
+		{
+			name:     "above min level",
+			line:     "Mar 10 10:00:00 pi kernel: mmc0: error -110 whilst initialising SD card",
+			minLevel: protocol.LevelWarning,
+			want: &protocol.LogEntry{
+				Timestamp:   time.Date(2025, time.March, 10, 10, 0, 0, 0, time.UTC).Unix(),
+				Source:      "syslog:kernel",
+				Level:       protocol.LevelError,
+				Message:     "mmc0: error -110 whilst initialising SD card",
+				ProcessName: "kernel",
+			},
+		},
+		{
+			name:     "below min level",
+			line:     "Mar 10 09:15:02 pi cron[400]: (root) CMD (run-parts /etc/cron.hourly)",
+			minLevel: protocol.LevelWarning,
+		},
+		{
+			name: "repeated marker",
+			line: "Mar 10 09:15:02 pi rsyslogd: last message repeated 3 times",
+		},
+		{
+			name: "malformed",
+			line: "not a syslog line",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSyslogFrom(strings.NewReader(tt.line+"\n"), tt.minLevel, 100, 0, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.want == nil {
+				if len(got) != 0 {
+					t.Errorf("expected no entries, got %+v", got)
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("got %d entries, want 1", len(got))
+			}
+			if got[0] != *tt.want {
+				t.Errorf("got %+v, want %+v", got[0], *tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSyslogFrom_KeepsNewest(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+	input := `Mar 10 09:00:01 pi app[1]: Msg 1
+Mar 10 09:00:02 pi app[1]: Msg 2
+Mar 10 09:00:03 pi app[1]: Msg 3
+Mar 10 09:00:04 pi app[1]: Msg 4
+Mar 10 09:00:05 pi app[1]: Msg 5`
+
+	tests := []struct {
+		name     string
+		limit    int
+		maxBytes int
+		want     []string
+	}{
+		{"limit 0", 0, 0, nil},
+		{"limit 2", 2, 0, []string{"Msg 4", "Msg 5"}},
+		{"limit exceeds entries", 100, 0, []string{"Msg 1", "Msg 2", "Msg 3", "Msg 4", "Msg 5"}},
+		{"budget fits three", 100, 15, []string{"Msg 3", "Msg 4", "Msg 5"}},
+		{"budget smaller than one", 100, 3, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSyslogFrom(strings.NewReader(input), protocol.LevelDebug, tt.limit, tt.maxBytes, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var msgs []string
+			for _, e := range got {
+				msgs = append(msgs, e.Message)
+			}
+			if !slices.Equal(msgs, tt.want) {
+				t.Errorf("got %v, want %v", msgs, tt.want)
+			}
+		})
+	}
+}
+
 func BenchmarkParseJournalFrom_Small(b *testing.B) {
 	input := `{"MESSAGE":"First message","_SYSTEMD_UNIT":"test.service","PRIORITY":"6","__REALTIME_TIMESTAMP":"1736164800000000","_COMM":"test","_PID":"1234"}
 {"MESSAGE":"Second message","_SYSTEMD_UNIT":"other.service","PRIORITY":"3","__REALTIME_TIMESTAMP":"1736164801000000","_COMM":"other","_PID":"5678"}`
 
 	b.ReportAllocs()
 	for b.Loop() {
-		_, _ = parseJournalFrom(strings.NewReader(input), 10000)
+		_, _ = parseJournalFrom(strings.NewReader(input), 10000, 0)
 	}
 }
 
@@ -742,7 +1244,21 @@ func BenchmarkParseJournalFrom_Large(b *testing.B) {
 	b.ReportAllocs()
 	b.ResetTimer()
 	for b.Loop() {
-		_, _ = parseJournalFrom(strings.NewReader(input), 10000)
+		_, _ = parseJournalFrom(strings.NewReader(input), 10000, 0)
+	}
+}
+
+func BenchmarkParseJournalFrom_ManySources(b *testing.B) {
+	var sb strings.Builder
+	for i := range 1000 {
+		fmt.Fprintf(&sb, `{"MESSAGE":"Log message %d","_SYSTEMD_UNIT":"unit-%d.service","PRIORITY":"%d","__REALTIME_TIMESTAMP":"1736164800000000","_COMM":"proc-%d","_PID":"%d"}`+"\n",
+			i, i%50, i%8, i%50, 1000+i)
+	}
+	input := sb.String()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_, _ = parseJournalFrom(strings.NewReader(input), 10000, 0)
 	}
 }
 
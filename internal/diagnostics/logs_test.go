@@ -0,0 +1,47 @@
+package diagnostics
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestCollapseRepeats(t *testing.T) {
+	entry := func(ts int64, source string, level protocol.LogLevel, msg string) protocol.LogEntry {
+		return protocol.LogEntry{Timestamp: ts, Source: source, Level: level, Message: msg}
+	}
+
+	input := []protocol.LogEntry{
+		entry(1, "journald:app", protocol.LevelError, "connection refused"),
+		entry(2, "journald:app", protocol.LevelError, "connection refused"),
+		entry(3, "journald:app", protocol.LevelError, "connection refused"),
+		entry(4, "journald:app", protocol.LevelWarning, "connection refused"), // different level
+		entry(5, "dmesg:kernel", protocol.LevelWarning, "connection refused"), // different source
+		entry(6, "dmesg:kernel", protocol.LevelWarning, "connection refused"),
+		entry(7, "journald:app", protocol.LevelError, "connection refused"), // not consecutive with the first run
+	}
+
+	want := []protocol.LogEntry{
+		{Timestamp: 1, Source: "journald:app", Level: protocol.LevelError, Message: "connection refused", Count: 3},
+		{Timestamp: 4, Source: "journald:app", Level: protocol.LevelWarning, Message: "connection refused"},
+		{Timestamp: 5, Source: "dmesg:kernel", Level: protocol.LevelWarning, Message: "connection refused", Count: 2},
+		{Timestamp: 7, Source: "journald:app", Level: protocol.LevelError, Message: "connection refused"},
+	}
+
+	got := CollapseRepeats(input)
+	if !slices.Equal(got, want) {
+		t.Errorf("CollapseRepeats:\n got  %+v\n want %+v", got, want)
+	}
+}
+
+func TestCollapseRepeats_Small(t *testing.T) {
+	if got := CollapseRepeats(nil); got != nil {
+		t.Errorf("nil input: got %v", got)
+	}
+
+	single := []protocol.LogEntry{{Message: "only"}}
+	if got := CollapseRepeats(single); len(got) != 1 || got[0].Count != 0 {
+		t.Errorf("single entry: got %+v", got)
+	}
+}
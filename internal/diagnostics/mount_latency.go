@@ -0,0 +1,125 @@
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const (
+	defaultMountProbeTimeout = 5 * time.Second
+	maxMountProbeTimeout     = 30 * time.Second
+
+	mountProbePattern = ".spectra-latency-*"
+)
+
+// mountProbeTimeout clamps the requested per-mount timeout to
+// (0, maxMountProbeTimeout].
+func mountProbeTimeout(req protocol.MountLatencyRequest) time.Duration {
+	d := time.Duration(req.TimeoutSeconds) * time.Second
+	if d <= 0 {
+		return defaultMountProbeTimeout
+	}
+	return min(d, maxMountProbeTimeout)
+}
+
+// RunMountLatency probes every mountpoint concurrently. Each probe is
+// bounded by the request timeout so one hung mount can't stall the rest.
+func RunMountLatency(ctx context.Context, mountpoints []string, req protocol.MountLatencyRequest) (*protocol.MountLatencyResult, error) {
+	if len(mountpoints) == 0 {
+		return nil, errors.New("no mountpoints to probe")
+	}
+
+	mountpoints = slices.Clone(mountpoints)
+	slices.Sort(mountpoints)
+	mountpoints = slices.Compact(mountpoints)
+
+	timeout := mountProbeTimeout(req)
+	result := &protocol.MountLatencyResult{
+		Mounts: make([]protocol.MountLatency, len(mountpoints)),
+	}
+
+	var wg sync.WaitGroup
+	for i, mp := range mountpoints {
+		wg.Go(func() {
+			result.Mounts[i] = probeMount(ctx, mp, timeout, probeMountOnce)
+		})
+	}
+	wg.Wait()
+
+	return result, nil
+}
+
+// probeMount runs probe with a timeout. File calls on a hung mount can't
+// be interrupted, so on timeout the probe goroutine is left to finish (or
+// block) on its own.
+func probeMount(ctx context.Context, mountpoint string, timeout time.Duration, probe func(string) protocol.MountLatency) protocol.MountLatency {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan protocol.MountLatency, 1)
+	go func() {
+		done <- probe(mountpoint)
+	}()
+
+	select {
+	case res := <-done:
+		return res
+	case <-ctx.Done():
+		return protocol.MountLatency{
+			Mountpoint: mountpoint,
+			TimedOut:   true,
+			Error:      fmt.Sprintf("no response within %s", timeout),
+		}
+	}
+}
+
+// probeMountOnce times a stat of the mountpoint, then creating, writing
+// and closing a one-byte temp file in it. The temp file is removed.
+func probeMountOnce(mountpoint string) protocol.MountLatency {
+	res := protocol.MountLatency{Mountpoint: mountpoint}
+	dir := probeDir(mountpoint)
+
+	start := time.Now()
+	if _, err := os.Stat(dir); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.StatMs = msSince(start)
+
+	start = time.Now()
+	f, err := os.CreateTemp(dir, mountProbePattern)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	_, writeErr := f.Write([]byte{0})
+	closeErr := f.Close()
+	res.WriteMs = msSince(start)
+	os.Remove(f.Name())
+
+	if err := errors.Join(writeErr, closeErr); err != nil {
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// probeDir turns a bare Windows drive ("C:") into its root ("C:\");
+// "C:" alone means the current directory on that drive.
+func probeDir(mountpoint string) string {
+	if v := filepath.VolumeName(mountpoint); v != "" && v == mountpoint {
+		return mountpoint + string(filepath.Separator)
+	}
+	return mountpoint
+}
+
+func msSince(t time.Time) float64 {
+	return float64(time.Since(t)) / float64(time.Millisecond)
+}
@@ -0,0 +1,107 @@
+package diagnostics
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestRunMountLatency_TempDir(t *testing.T) {
+	dir := t.TempDir()
+
+	res, err := RunMountLatency(context.Background(), []string{dir}, protocol.MountLatencyRequest{})
+	if err != nil {
+		t.Fatalf("RunMountLatency: %v", err)
+	}
+	if len(res.Mounts) != 1 {
+		t.Fatalf("got %d mounts, want 1", len(res.Mounts))
+	}
+
+	m := res.Mounts[0]
+	if m.Mountpoint != dir {
+		t.Errorf("Mountpoint = %q, want %q", m.Mountpoint, dir)
+	}
+	if m.Error != "" || m.TimedOut {
+		t.Fatalf("unexpected failure: %+v", m)
+	}
+	if m.StatMs <= 0 || m.StatMs > 1000 {
+		t.Errorf("StatMs = %f, want small and nonzero", m.StatMs)
+	}
+	if m.WriteMs <= 0 || m.WriteMs > 1000 {
+		t.Errorf("WriteMs = %f, want small and nonzero", m.WriteMs)
+	}
+
+	leftover, _ := filepath.Glob(filepath.Join(dir, mountProbePattern))
+	if len(leftover) != 0 {
+		t.Errorf("temp files left behind: %v", leftover)
+	}
+}
+
+func TestRunMountLatency_SortsAndReportsErrors(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+
+	res, err := RunMountLatency(context.Background(), []string{missing, dir, dir}, protocol.MountLatencyRequest{})
+	if err != nil {
+		t.Fatalf("RunMountLatency: %v", err)
+	}
+	if len(res.Mounts) != 2 {
+		t.Fatalf("got %d mounts, want 2 after dedup", len(res.Mounts))
+	}
+	if res.Mounts[0].Mountpoint != dir || res.Mounts[1].Mountpoint != missing {
+		t.Errorf("order = %q, %q; want sorted", res.Mounts[0].Mountpoint, res.Mounts[1].Mountpoint)
+	}
+	if res.Mounts[1].Error == "" || res.Mounts[1].StatMs != 0 {
+		t.Errorf("missing mount = %+v, want stat error", res.Mounts[1])
+	}
+}
+
+func TestRunMountLatency_NoMounts(t *testing.T) {
+	if _, err := RunMountLatency(context.Background(), nil, protocol.MountLatencyRequest{}); err == nil {
+		t.Error("expected error with no mountpoints")
+	}
+}
+
+func TestMountProbeTimeout(t *testing.T) {
+	tests := []struct {
+		secs int
+		want time.Duration
+	}{
+		{0, defaultMountProbeTimeout},
+		{-3, defaultMountProbeTimeout},
+		{2, 2 * time.Second},
+		{300, maxMountProbeTimeout},
+	}
+
+	for _, tt := range tests {
+		got := mountProbeTimeout(protocol.MountLatencyRequest{TimeoutSeconds: tt.secs})
+		if got != tt.want {
+			t.Errorf("mountProbeTimeout(%d) = %v, want %v", tt.secs, got, tt.want)
+		}
+	}
+}
+
+func TestProbeMount_HungMount(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	hung := func(mp string) protocol.MountLatency {
+		<-release
+		return protocol.MountLatency{Mountpoint: mp}
+	}
+
+	start := time.Now()
+	res := probeMount(context.Background(), "/mnt/hung", 50*time.Millisecond, hung)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("probe took %v, want it cut off near the timeout", elapsed)
+	}
+	if !res.TimedOut || res.Error == "" {
+		t.Errorf("got %+v, want timed out with an error", res)
+	}
+	if res.Mountpoint != "/mnt/hung" {
+		t.Errorf("Mountpoint = %q, want /mnt/hung", res.Mountpoint)
+	}
+}
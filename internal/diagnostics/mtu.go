@@ -0,0 +1,151 @@
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+const (
+	// mtuOverhead is the IPv4 + ICMP header size added to a ping payload.
+	mtuOverhead = 28
+	// mtuMaxPayload is the payload that fills a standard 1500-byte MTU.
+	mtuMaxPayload = 1500 - mtuOverhead
+)
+
+// errMTUUnsupported means the local ping cannot send DF-bit probes.
+var errMTUUnsupported = errors.New("DF-bit ping probes not supported")
+
+// mtuProbeOutcome classifies a single DF-bit ping.
+type mtuProbeOutcome int
+
+const (
+	mtuProbeOK     mtuProbeOutcome = iota // reply received
+	mtuProbeTooBig                        // rejected locally or by a hop as too large
+	mtuProbeLost                          // no reply
+)
+
+// mtuProbeFunc sends one DF-bit ping with the given ICMP payload size. hint
+// is the MTU reported by the rejecting hop, or zero.
+type mtuProbeFunc func(ctx context.Context, payload int) (outcome mtuProbeOutcome, hint int, err error)
+
+var (
+	pingReceivedRe = regexp.MustCompile(`(\d+) (?:packets )?received`)
+	mtuHintRe      = regexp.MustCompile(`mtu ?= ?(\d+)`)
+)
+
+// parseMTUProbe classifies the output of a single Linux `ping -M do` run.
+// A ping that rejects -M (e.g. BusyBox) yields errMTUUnsupported; any other
+// unrecognised output, such as a DNS failure, is returned as an error.
+func parseMTUProbe(out string) (mtuProbeOutcome, int, error) {
+	lower := strings.ToLower(out)
+
+	hint := 0
+	if m := mtuHintRe.FindStringSubmatch(lower); m != nil {
+		hint, _ = strconv.Atoi(m[1])
+	}
+
+	if strings.Contains(lower, "message too long") || strings.Contains(lower, "frag needed") {
+		return mtuProbeTooBig, hint, nil
+	}
+
+	if m := pingReceivedRe.FindStringSubmatch(lower); m != nil {
+		if n, _ := strconv.Atoi(m[1]); n > 0 {
+			return mtuProbeOK, 0, nil
+		}
+		return mtuProbeLost, 0, nil
+	}
+
+	if strings.Contains(lower, "invalid option") || strings.Contains(lower, "unrecognized option") || strings.Contains(lower, "usage:") {
+		return mtuProbeLost, 0, errMTUUnsupported
+	}
+
+	return mtuProbeLost, 0, fmt.Errorf("ping: %s", strings.TrimSpace(out))
+}
+
+// discoverMTU binary-searches the largest payload that probe gets through
+// unfragmented. A full-size payload is tried first, then the hop's MTU hint
+// if one was reported. A lost probe is treated like one that was too big.
+func discoverMTU(ctx context.Context, probe mtuProbeFunc) (payload, probes int, err error) {
+	try := func(size int) (bool, int, error) {
+		probes++
+		outcome, hint, err := probe(ctx, size)
+		if err != nil {
+			return false, 0, err
+		}
+		return outcome == mtuProbeOK, hint, nil
+	}
+
+	ok, hint, err := try(mtuMaxPayload)
+	if err != nil || ok {
+		return mtuMaxPayload, probes, err
+	}
+
+	hi := mtuMaxPayload
+	if hint > mtuOverhead && hint-mtuOverhead < hi {
+		ok, _, err := try(hint - mtuOverhead)
+		if err != nil {
+			return 0, probes, err
+		}
+		if ok {
+			return hint - mtuOverhead, probes, nil
+		}
+		hi = hint - mtuOverhead
+	}
+
+	lo := 0
+	if ok, _, err := try(lo); err != nil {
+		return 0, probes, err
+	} else if !ok {
+		return 0, probes, fmt.Errorf("target unreachable")
+	}
+
+	// Invariant: lo gets through, hi does not
+	for hi-lo > 1 {
+		if ctx.Err() != nil {
+			return 0, probes, ctx.Err()
+		}
+		mid := lo + (hi-lo)/2
+		ok, _, err := try(mid)
+		if err != nil {
+			return 0, probes, err
+		}
+		if ok {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return lo, probes, nil
+}
+
+// runMTUProbe runs discoverMTU and folds an unsupported probe into the
+// result instead of failing the command.
+func runMTUProbe(ctx context.Context, req protocol.MTURequest, probe mtuProbeFunc) (*protocol.MTUResult, error) {
+	if req.Target == "" {
+		return nil, fmt.Errorf("target required")
+	}
+
+	res := &protocol.MTUResult{Target: req.Target}
+
+	payload, probes, err := discoverMTU(ctx, probe)
+	res.Probes = probes
+	if errors.Is(err, errMTUUnsupported) {
+		res.Message = err.Error()
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res.Supported = true
+	res.Payload = payload
+	res.MTU = payload + mtuOverhead
+	return res, nil
+}
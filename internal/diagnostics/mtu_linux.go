@@ -0,0 +1,38 @@
+//go:build linux
+
+package diagnostics
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// RunMTUProbe discovers the path MTU to req.Target using iputils ping with
+// the Don't Fragment bit set. A missing ping binary is reported in the
+// result rather than as an error.
+func RunMTUProbe(ctx context.Context, req protocol.MTURequest) (*protocol.MTUResult, error) {
+	if _, err := exec.LookPath("ping"); err != nil {
+		return &protocol.MTUResult{Target: req.Target, Message: "ping not found"}, nil
+	}
+
+	return runMTUProbe(ctx, req, func(ctx context.Context, payload int) (mtuProbeOutcome, int, error) {
+		args := []string{
+			"-M", "do",
+			"-s", strconv.Itoa(payload),
+			"-c", "1",
+			"-W", "1",
+			"-n",
+			req.Target,
+		}
+
+		// ping exits non-zero for lost and oversized probes; the output says which
+		out, _ := exec.CommandContext(ctx, "ping", args...).CombinedOutput()
+		if ctx.Err() != nil {
+			return mtuProbeLost, 0, ctx.Err()
+		}
+		return parseMTUProbe(string(out))
+	})
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// RunMTUProbe is only implemented on Linux; elsewhere it reports the probe
+// as unsupported.
+func RunMTUProbe(ctx context.Context, req protocol.MTURequest) (*protocol.MTUResult, error) {
+	return &protocol.MTUResult{
+		Target:  req.Target,
+		Message: fmt.Sprintf("MTU probing is not supported on %s", runtime.GOOS),
+	}, nil
+}
@@ -0,0 +1,171 @@
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestParseMTUProbe(t *testing.T) {
+	tests := []struct {
+		name     string
+		out      string
+		want     mtuProbeOutcome
+		wantHint int
+		wantErr  error
+	}{
+		{
+			name: "reply",
+			out: `PING 10.0.0.1 (10.0.0.1) 1372(1400) bytes of data.
+1380 bytes from 10.0.0.1: icmp_seq=1 ttl=64 time=0.412 ms
+
+--- 10.0.0.1 ping statistics ---
+1 packets transmitted, 1 received, 0% packet loss, time 0ms
+rtt min/avg/max/mdev = 0.412/0.412/0.412/0.000 ms
+`,
+			want: mtuProbeOK,
+		},
+		{
+			name: "local message too long",
+			out: `PING 10.8.0.1 (10.8.0.1) 1472(1500) bytes of data.
+ping: local error: message too long, mtu=1420
+
+--- 10.8.0.1 ping statistics ---
+1 packets transmitted, 0 received, +1 errors, 100% packet loss, time 0ms
+`,
+			want:     mtuProbeTooBig,
+			wantHint: 1420,
+		},
+		{
+			name: "sendmsg message too long",
+			out: `PING 10.8.0.1 (10.8.0.1) 1472(1500) bytes of data.
+ping: sendmsg: Message too long
+
+--- 10.8.0.1 ping statistics ---
+1 packets transmitted, 0 received, +1 errors, 100% packet loss, time 0ms
+`,
+			want: mtuProbeTooBig,
+		},
+		{
+			name: "frag needed from hop",
+			out: `PING 203.0.113.5 (203.0.113.5) 1472(1500) bytes of data.
+From 192.168.1.1 icmp_seq=1 Frag needed and DF set (mtu = 1400)
+
+--- 203.0.113.5 ping statistics ---
+1 packets transmitted, 0 received, +1 errors, 100% packet loss, time 0ms
+`,
+			want:     mtuProbeTooBig,
+			wantHint: 1400,
+		},
+		{
+			name: "lost",
+			out: `PING 10.0.0.9 (10.0.0.9) 1472(1500) bytes of data.
+
+--- 10.0.0.9 ping statistics ---
+1 packets transmitted, 0 received, 100% packet loss, time 0ms
+`,
+			want: mtuProbeLost,
+		},
+		{
+			name:    "busybox without -M",
+			out:     "ping: invalid option -- 'M'\nBusyBox v1.36.1 multi-call binary.\n\nUsage: ping [OPTIONS] HOST\n",
+			want:    mtuProbeLost,
+			wantErr: errMTUUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, hint, err := parseMTUProbe(tt.out)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want || hint != tt.wantHint {
+				t.Errorf("got (%d, %d), want (%d, %d)", got, hint, tt.want, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestParseMTUProbe_ResolveFailure(t *testing.T) {
+	_, _, err := parseMTUProbe("ping: nosuchhost: Name or service not known\n")
+	if err == nil || errors.Is(err, errMTUUnsupported) {
+		t.Errorf("err = %v, want a resolve error", err)
+	}
+}
+
+// pathProbe simulates a path that passes payloads up to max and reports
+// hint for anything larger.
+func pathProbe(maxPayload, hint int) mtuProbeFunc {
+	return func(_ context.Context, payload int) (mtuProbeOutcome, int, error) {
+		if payload <= maxPayload {
+			return mtuProbeOK, 0, nil
+		}
+		return mtuProbeTooBig, hint, nil
+	}
+}
+
+func TestDiscoverMTU(t *testing.T) {
+	tests := []struct {
+		name       string
+		probe      mtuProbeFunc
+		wantMax    int
+		wantProbes int
+	}{
+		{"full size", pathProbe(1472, 0), 1472, 1},
+		{"hint accepted", pathProbe(1392, 1420), 1392, 2},
+		{"search without hint", pathProbe(1372, 0), 1372, 0},
+		{"wrong hint", pathProbe(1300, 1420), 1300, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, probes, err := discoverMTU(context.Background(), tt.probe)
+			if err != nil {
+				t.Fatalf("discoverMTU: %v", err)
+			}
+			if got != tt.wantMax {
+				t.Errorf("payload = %d, want %d", got, tt.wantMax)
+			}
+			if tt.wantProbes > 0 && probes != tt.wantProbes {
+				t.Errorf("probes = %d, want %d", probes, tt.wantProbes)
+			}
+		})
+	}
+}
+
+func TestDiscoverMTU_Unreachable(t *testing.T) {
+	lost := func(context.Context, int) (mtuProbeOutcome, int, error) {
+		return mtuProbeLost, 0, nil
+	}
+
+	if _, _, err := discoverMTU(context.Background(), lost); err == nil {
+		t.Error("expected error for unreachable target")
+	}
+}
+
+func TestRunMTUProbe_Unsupported(t *testing.T) {
+	unsupported := func(context.Context, int) (mtuProbeOutcome, int, error) {
+		return mtuProbeLost, 0, errMTUUnsupported
+	}
+
+	res, err := runMTUProbe(context.Background(), protocol.MTURequest{Target: "10.0.0.1"}, unsupported)
+	if err != nil {
+		t.Fatalf("runMTUProbe: %v", err)
+	}
+	if res.Supported || res.MTU != 0 || res.Message == "" {
+		t.Errorf("got %+v, want unsupported result with message", res)
+	}
+}
+
+func TestRunMTUProbe_ReportsMTU(t *testing.T) {
+	res, err := runMTUProbe(context.Background(), protocol.MTURequest{Target: "10.8.0.1"}, pathProbe(1392, 1420))
+	if err != nil {
+		t.Fatalf("runMTUProbe: %v", err)
+	}
+	if !res.Supported || res.MTU != 1420 || res.Payload != 1392 {
+		t.Errorf("got %+v, want MTU 1420 / payload 1392", res)
+	}
+}
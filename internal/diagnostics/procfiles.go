@@ -0,0 +1,16 @@
+package diagnostics
+
+import "github.com/nhdewitt/spectra/internal/protocol"
+
+const (
+	defaultProcFilesLimit = 200
+	maxProcFilesLimit     = 2000
+)
+
+// procFilesLimit returns the number of descriptors to list for req.
+func procFilesLimit(req protocol.ProcFilesRequest) int {
+	if req.Limit <= 0 {
+		return defaultProcFilesLimit
+	}
+	return min(req.Limit, maxProcFilesLimit)
+}
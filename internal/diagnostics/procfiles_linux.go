@@ -0,0 +1,83 @@
+//go:build linux
+
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// ProcFiles lists the open descriptors of req.PID from /proc/<pid>/fd,
+// resolving each link target. Targets that can't be read are reported per
+// descriptor; an unreadable fd directory fails the whole request.
+func ProcFiles(ctx context.Context, req protocol.ProcFilesRequest) (*protocol.ProcFilesResult, error) {
+	return procFiles(ctx, "/proc", req)
+}
+
+func procFiles(ctx context.Context, procRoot string, req protocol.ProcFilesRequest) (*protocol.ProcFilesResult, error) {
+	if req.PID <= 0 {
+		return nil, fmt.Errorf("pid required")
+	}
+
+	dir := filepath.Join(procRoot, strconv.Itoa(req.PID), "fd")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return nil, fmt.Errorf("process %d not found", req.PID)
+		case errors.Is(err, fs.ErrPermission):
+			return nil, fmt.Errorf("permission denied reading descriptors of process %d", req.PID)
+		}
+		return nil, err
+	}
+
+	fds := make([]int, 0, len(entries))
+	for _, e := range entries {
+		if fd, err := strconv.Atoi(e.Name()); err == nil {
+			fds = append(fds, fd)
+		}
+	}
+	slices.Sort(fds)
+
+	limit := procFilesLimit(req)
+	res := &protocol.ProcFilesResult{
+		PID:   req.PID,
+		Count: len(fds),
+		Files: make([]protocol.OpenFile, 0, min(len(fds), limit)),
+	}
+	if len(fds) > limit {
+		res.Truncated = true
+		fds = fds[:limit]
+	}
+
+	for _, fd := range fds {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		f := protocol.OpenFile{FD: fd}
+		target, err := os.Readlink(filepath.Join(dir, strconv.Itoa(fd)))
+		switch {
+		case err == nil:
+			f.Target = target
+		case errors.Is(err, fs.ErrNotExist):
+			// Closed between ReadDir and Readlink
+			continue
+		case errors.Is(err, fs.ErrPermission):
+			f.Error = "permission denied"
+		default:
+			f.Error = err.Error()
+		}
+		res.Files = append(res.Files, f)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,103 @@
+//go:build linux
+
+package diagnostics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestProcFiles_Self(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "held-open.txt")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	defer f.Close()
+
+	res, err := ProcFiles(context.Background(), protocol.ProcFilesRequest{PID: os.Getpid(), Limit: maxProcFilesLimit})
+	if err != nil {
+		t.Fatalf("ProcFiles: %v", err)
+	}
+
+	if res.Count == 0 || len(res.Files) == 0 {
+		t.Fatalf("expected open descriptors, got %+v", res)
+	}
+
+	found := false
+	for _, of := range res.Files {
+		if of.FD == int(f.Fd()) {
+			found = true
+			if of.Target != path {
+				t.Errorf("fd %d target = %q, want %q", of.FD, of.Target, path)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("fd %d for %s not listed", f.Fd(), path)
+	}
+}
+
+func TestProcFiles_Limit(t *testing.T) {
+	for range 3 {
+		f, err := os.Open(os.DevNull)
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		defer f.Close()
+	}
+
+	res, err := ProcFiles(context.Background(), protocol.ProcFilesRequest{PID: os.Getpid(), Limit: 2})
+	if err != nil {
+		t.Fatalf("ProcFiles: %v", err)
+	}
+
+	if len(res.Files) != 2 || !res.Truncated {
+		t.Errorf("got %d files (truncated=%v), want 2 truncated", len(res.Files), res.Truncated)
+	}
+	if res.Count <= 2 {
+		t.Errorf("Count = %d, want the full total", res.Count)
+	}
+	if res.Files[0].FD > res.Files[1].FD {
+		t.Errorf("files not sorted by fd: %v", res.Files)
+	}
+}
+
+func TestProcFiles_NoSuchProcess(t *testing.T) {
+	_, err := procFiles(context.Background(), t.TempDir(), protocol.ProcFilesRequest{PID: 424242})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("err = %v, want not found", err)
+	}
+}
+
+func TestProcFiles_PermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read any fd directory")
+	}
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "1", "fd")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dir, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(dir, 0o755)
+
+	_, err := procFiles(context.Background(), root, protocol.ProcFilesRequest{PID: 1})
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("err = %v, want permission denied", err)
+	}
+}
+
+func TestProcFiles_InvalidPID(t *testing.T) {
+	if _, err := ProcFiles(context.Background(), protocol.ProcFilesRequest{}); err == nil {
+		t.Error("expected error for missing pid")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// ProcFiles is only implemented on Linux.
+func ProcFiles(ctx context.Context, req protocol.ProcFilesRequest) (*protocol.ProcFilesResult, error) {
+	return nil, fmt.Errorf("open file listing is not supported on %s", runtime.GOOS)
+}
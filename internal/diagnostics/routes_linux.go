@@ -0,0 +1,89 @@
+//go:build linux
+
+package diagnostics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// rtfUp is RTF_UP from <linux/route.h>.
+const rtfUp = 0x1
+
+// RouteTable returns the kernel's IPv4 routing table.
+func RouteTable(ctx context.Context) ([]protocol.RouteEntry, error) {
+	f, err := os.Open("/proc/net/route")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseProcRouteFrom(f)
+}
+
+// parseProcRouteFrom parses /proc/net/route, whose addresses are
+// little-endian hex like /proc/net/tcp. Routes that are not up are
+// skipped.
+//
+//	Iface Destination Gateway  Flags RefCnt Use Metric Mask     MTU Window IRTT
+//	eth0  00000000    0101A8C0 0003  0      0   100    00000000 0   0      0
+func parseProcRouteFrom(r io.Reader) ([]protocol.RouteEntry, error) {
+	var routes []protocol.RouteEntry
+	scanner := bufio.NewScanner(r)
+
+	// Skip header
+	scanner.Scan()
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(fields[3], 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("bad flags %q: %w", fields[3], err)
+		}
+		if flags&rtfUp == 0 {
+			continue
+		}
+
+		dest, err := parseIPv4Hex(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("bad destination %q: %w", fields[1], err)
+		}
+		gw, err := parseIPv4Hex(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("bad gateway %q: %w", fields[2], err)
+		}
+		mask, err := parseIPv4Hex(fields[7])
+		if err != nil {
+			return nil, fmt.Errorf("bad mask %q: %w", fields[7], err)
+		}
+		metric, err := strconv.Atoi(fields[6])
+		if err != nil {
+			return nil, fmt.Errorf("bad metric %q: %w", fields[6], err)
+		}
+
+		ones, _ := net.IPMask(mask.To4()).Size()
+		entry := protocol.RouteEntry{
+			Destination: fmt.Sprintf("%s/%d", dest, ones),
+			Interface:   fields[0],
+			Metric:      metric,
+		}
+		if !gw.Equal(net.IPv4zero) {
+			entry.Gateway = gw.String()
+		}
+		routes = append(routes, entry)
+	}
+
+	return routes, scanner.Err()
+}
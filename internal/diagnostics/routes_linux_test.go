@@ -0,0 +1,45 @@
+//go:build linux
+
+package diagnostics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestParseProcRouteFrom(t *testing.T) {
+	input := `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	00000000	0101A8C0	0003	0	0	100	00000000	0	0	0
+eth0	0001A8C0	00000000	0001	0	0	100	00FFFFFF	0	0	0
+wlan0	0000A8C0	00000000	0001	0	0	600	0000FFFF	0	0	0
+eth1	0000000A	00000000	0000	0	0	0	000000FF	0	0	0
+`
+	got, err := parseProcRouteFrom(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []protocol.RouteEntry{
+		{Destination: "0.0.0.0/0", Gateway: "192.168.1.1", Interface: "eth0", Metric: 100},
+		{Destination: "192.168.1.0/24", Interface: "eth0", Metric: 100},
+		{Destination: "192.168.0.0/16", Interface: "wlan0", Metric: 600},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d routes, want %d (down route should be skipped): %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("route %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseProcRouteFrom_BadHex(t *testing.T) {
+	input := "Iface\tDestination\tGateway\tFlags\tRefCnt\tUse\tMetric\tMask\n" +
+		"eth0\tZZZZZZZZ\t00000000\t0001\t0\t0\t0\t00000000\n"
+	if _, err := parseProcRouteFrom(strings.NewReader(input)); err == nil {
+		t.Error("expected error for malformed destination")
+	}
+}
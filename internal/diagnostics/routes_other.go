@@ -0,0 +1,16 @@
+//go:build !linux && !windows
+
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// RouteTable is only implemented on Linux and Windows.
+func RouteTable(ctx context.Context) ([]protocol.RouteEntry, error) {
+	return nil, fmt.Errorf("route table is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,80 @@
+//go:build windows
+
+package diagnostics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// RouteTable returns the IPv4 routing table from `route print -4`.
+func RouteTable(ctx context.Context) ([]protocol.RouteEntry, error) {
+	out, err := exec.CommandContext(ctx, "route", "print", "-4").Output()
+	if err != nil {
+		return nil, fmt.Errorf("route print failed: %w", err)
+	}
+
+	return parseRoutePrintFrom(bytes.NewReader(out))
+}
+
+// parseRoutePrintFrom reads the "Active Routes" section of route print.
+// Interface is the local interface address, as Windows reports it.
+//
+//	Network Destination        Netmask          Gateway       Interface  Metric
+//	          0.0.0.0          0.0.0.0      192.168.1.1    192.168.1.100     25
+//	        127.0.0.0        255.0.0.0         On-link         127.0.0.1    331
+func parseRoutePrintFrom(r io.Reader) ([]protocol.RouteEntry, error) {
+	var routes []protocol.RouteEntry
+	scanner := bufio.NewScanner(r)
+	active := false
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "Active Routes:":
+			active = true
+			continue
+		case strings.HasPrefix(line, "==="):
+			active = false
+			continue
+		case !active:
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 5 || fields[0] == "Network" {
+			continue
+		}
+
+		mask := net.ParseIP(fields[1]).To4()
+		if mask == nil || net.ParseIP(fields[0]) == nil {
+			continue
+		}
+		metric, err := strconv.Atoi(fields[4])
+		if err != nil {
+			continue
+		}
+
+		ones, _ := net.IPMask(mask).Size()
+		entry := protocol.RouteEntry{
+			Destination: fmt.Sprintf("%s/%d", fields[0], ones),
+			Interface:   fields[3],
+			Metric:      metric,
+		}
+		if fields[2] != "On-link" {
+			entry.Gateway = fields[2]
+		}
+		routes = append(routes, entry)
+	}
+
+	return routes, scanner.Err()
+}
@@ -0,0 +1,47 @@
+//go:build windows
+
+package diagnostics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestParseRoutePrintFrom(t *testing.T) {
+	input := `===========================================================================
+Interface List
+ 12...00 15 5d 01 02 03 ......Microsoft Hyper-V Network Adapter
+===========================================================================
+
+IPv4 Route Table
+===========================================================================
+Active Routes:
+Network Destination        Netmask          Gateway       Interface  Metric
+          0.0.0.0          0.0.0.0      192.168.1.1    192.168.1.100     25
+        127.0.0.0        255.0.0.0         On-link         127.0.0.1    331
+      192.168.1.0    255.255.255.0         On-link     192.168.1.100    281
+===========================================================================
+Persistent Routes:
+  None
+`
+	got, err := parseRoutePrintFrom(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []protocol.RouteEntry{
+		{Destination: "0.0.0.0/0", Gateway: "192.168.1.1", Interface: "192.168.1.100", Metric: 25},
+		{Destination: "127.0.0.0/8", Interface: "127.0.0.1", Metric: 331},
+		{Destination: "192.168.1.0/24", Interface: "192.168.1.100", Metric: 281},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d routes, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("route %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,56 @@
+//go:build linux || freebsd
+
+package diagnostics
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// reSyslog matches the BSD syslog (RFC 3164) line format:
+// "Mon DD HH:MM:SS host proc[pid]: msg", with the [pid] optional.
+var reSyslog = regexp.MustCompile(
+	`^(\w{3}\s+\d{1,2}\s+\d{2}:\d{2}:\d{2})\s+\S+\s+(\S+?)(?:\[(\d+)])?:\s+(.+)$`,
+)
+
+// parseSyslogTimestamp parses "Feb 19 12:24:47" in now's location with the
+// current year. BSD syslog timestamps don't include the year.
+func parseSyslogTimestamp(s string, now time.Time) int64 {
+	t, err := time.ParseInLocation("Jan _2 15:04:05", s, now.Location())
+	if err != nil {
+		return 0
+	}
+	// Add current year
+	t = t.AddDate(now.Year(), 0, 0)
+	if t.After(now.Add(24 * time.Hour)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t.Unix()
+}
+
+// inferLevel attempts to determine the log level from the source
+// and message content, falling back to defaultLevel.
+func inferLevel(source, msg string, defaultLevel protocol.LogLevel) protocol.LogLevel {
+	lower := strings.ToLower(msg)
+
+	if source == "kernel" {
+		if strings.Contains(lower, "error") || strings.Contains(lower, "fail") {
+			return protocol.LevelError
+		}
+		if strings.Contains(lower, "warn") {
+			return protocol.LevelWarning
+		}
+	}
+
+	// Auth failures
+	if source == "sshd" || source == "login" || source == "su" {
+		if strings.Contains(lower, "fail") || strings.Contains(lower, "invalid") {
+			return protocol.LevelWarning
+		}
+	}
+
+	return defaultLevel
+}
@@ -0,0 +1,123 @@
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// throughputTimeout bounds each direction of a throughput test.
+const throughputTimeout = 30 * time.Second
+
+// zeroReader yields an endless stream of zero bytes.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// RunThroughput measures download then upload throughput against url,
+// which sources req.Bytes on GET and sinks the body of a POST. prepare is
+// applied to every request so the caller can add its credentials.
+func RunThroughput(ctx context.Context, client *http.Client, url string, req protocol.ThroughputRequest, prepare func(*http.Request)) (*protocol.ThroughputResult, error) {
+	n := req.Bytes
+	if n <= 0 {
+		n = protocol.DefaultThroughputBytes
+	}
+	n = min(n, protocol.MaxThroughputBytes)
+
+	down, err := throughputDownload(ctx, client, url, n, prepare)
+	if err != nil {
+		return nil, fmt.Errorf("download: %w", err)
+	}
+
+	up, err := throughputUpload(ctx, client, url, n, prepare)
+	if err != nil {
+		return nil, fmt.Errorf("upload: %w", err)
+	}
+
+	return &protocol.ThroughputResult{
+		Bytes:        n,
+		DownloadMbps: mbps(n, down),
+		UploadMbps:   mbps(n, up),
+		DownloadMs:   down.Milliseconds(),
+		UploadMs:     up.Milliseconds(),
+	}, nil
+}
+
+func throughputDownload(ctx context.Context, client *http.Client, url string, n int64, prepare func(*http.Request)) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, throughputTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"?bytes="+strconv.FormatInt(n, 10), nil)
+	if err != nil {
+		return 0, err
+	}
+	prepare(req)
+	req.Header.Del("Content-Encoding")
+	// Compression would inflate the measured rate
+	req.Header.Set("Accept-Encoding", "identity")
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	got, err := io.Copy(io.Discard, io.LimitReader(resp.Body, n))
+	if err != nil {
+		return 0, err
+	}
+	if got != n {
+		return 0, fmt.Errorf("received %d of %d bytes", got, n)
+	}
+
+	return time.Since(start), nil
+}
+
+func throughputUpload(ctx context.Context, client *http.Client, url string, n int64, prepare func(*http.Request)) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, throughputTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, io.LimitReader(zeroReader{}, n))
+	if err != nil {
+		return 0, err
+	}
+	prepare(req)
+	req.Header.Del("Content-Encoding")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.ContentLength = n
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	return time.Since(start), nil
+}
+
+// mbps converts n bytes moved in d to megabits per second.
+func mbps(n int64, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) * 8 / d.Seconds() / 1e6
+}
@@ -0,0 +1,192 @@
+// Package export flattens metrics into named numeric samples for
+// external monitoring systems: the server's OTLP and InfluxDB exporters
+// and the agent's scrape endpoint.
+package export
+
+import (
+	"strconv"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// Tag is a sample dimension such as device or interface.
+type Tag struct {
+	Key, Value string
+}
+
+// Field is a single numeric value within a sample.
+type Field struct {
+	Key   string
+	Value float64
+}
+
+// Sample is one measurement of a metric in a form every exporter can
+// take: a measurement name, identifying tags and values.
+type Sample struct {
+	Measurement string
+	Tags        []Tag
+	Fields      []Field
+}
+
+// Samples flattens the numeric parts of m. Metric types without a
+// meaningful numeric form (inventory, lists, health) return nil.
+func Samples(m protocol.Metric) []Sample {
+	switch v := m.(type) {
+	case *protocol.CPUMetric:
+		fields := []Field{
+			{"usage", v.Usage},
+			{"iowait", v.IOWait},
+			{"load_1m", v.LoadAvg1},
+			{"load_5m", v.LoadAvg5},
+			{"load_15m", v.LoadAvg15},
+		}
+		if v.FreqMHz > 0 {
+			fields = append(fields, Field{"freq_mhz", v.FreqMHz})
+		}
+		return []Sample{{Measurement: "cpu", Fields: fields}}
+	case *protocol.MemoryMetric:
+		return []Sample{{Measurement: "memory", Fields: []Field{
+			{"total", float64(v.Total)},
+			{"used", float64(v.Used)},
+			{"available", float64(v.Available)},
+			{"used_pct", v.UsedPct},
+			{"swap_total", float64(v.SwapTotal)},
+			{"swap_used", float64(v.SwapUsed)},
+			{"swap_pct", v.SwapPct},
+		}}}
+	case *protocol.DiskMetric:
+		return []Sample{{
+			Measurement: "disk",
+			Tags:        []Tag{{"device", v.Device}, {"mountpoint", v.Mountpoint}, {"fstype", v.Filesystem}},
+			Fields: []Field{
+				{"total", float64(v.Total)},
+				{"used", float64(v.Used)},
+				{"available", float64(v.Available)},
+				{"used_pct", v.UsedPct},
+				{"inodes_pct", v.InodesPct},
+			},
+		}}
+	case *protocol.DiskIOMetric:
+		return []Sample{{
+			Measurement: "disk_io",
+			Tags:        []Tag{{"device", v.Device}},
+			Fields: []Field{
+				{"read_bytes", float64(v.ReadBytes)},
+				{"write_bytes", float64(v.WriteBytes)},
+				{"read_ops", float64(v.ReadOps)},
+				{"write_ops", float64(v.WriteOps)},
+				{"in_progress", float64(v.InProgress)},
+			},
+		}}
+	case *protocol.NetworkMetric:
+		return []Sample{{
+			Measurement: "network",
+			Tags:        []Tag{{"iface", v.Interface}},
+			Fields: []Field{
+				{"rx_bytes", float64(v.RxBytes)},
+				{"tx_bytes", float64(v.TxBytes)},
+				{"rx_packets", float64(v.RxPackets)},
+				{"tx_packets", float64(v.TxPackets)},
+				{"rx_errors", float64(v.RxErrors)},
+				{"tx_errors", float64(v.TxErrors)},
+				{"rx_drops", float64(v.RxDrops)},
+				{"tx_drops", float64(v.TxDrops)},
+			},
+		}}
+	case *protocol.TemperatureMetric:
+		return []Sample{{
+			Measurement: "temperature",
+			Tags:        []Tag{{"sensor", v.Sensor}},
+			Fields:      []Field{{"celsius", v.Temp}},
+		}}
+	case *protocol.SystemMetric:
+		return []Sample{{Measurement: "system", Fields: []Field{
+			{"uptime", float64(v.Uptime)},
+			{"processes", float64(v.Processes)},
+			{"users", float64(v.Users)},
+		}}}
+	case *protocol.WiFiMetric:
+		return []Sample{{
+			Measurement: "wifi",
+			Tags:        []Tag{{"iface", v.Interface}, {"ssid", v.SSID}},
+			Fields: []Field{
+				{"signal_dbm", float64(v.SignalLevel)},
+				{"link_quality", float64(v.LinkQuality)},
+				{"bitrate_mbps", v.BitRate},
+			},
+		}}
+	case *protocol.SensorMetric:
+		return []Sample{{
+			Measurement: "sensor",
+			Tags:        []Tag{{"source", v.Source}, {"chip", v.Chip}, {"name", v.Name}, {"kind", v.Kind}},
+			Fields:      []Field{{"value", v.Value}},
+		}}
+	case *protocol.GPUProcessMetric:
+		return []Sample{{
+			Measurement: "gpu_process",
+			Tags:        []Tag{{"pid", strconv.Itoa(v.PID)}, {"name", v.Name}, {"gpu", v.GPU}},
+			Fields:      []Field{{"used_memory", float64(v.UsedMemory)}},
+		}}
+	case *protocol.PowerDrawMetric:
+		return []Sample{{
+			Measurement: "power",
+			Tags:        []Tag{{"domain", v.Domain}},
+			Fields:      []Field{{"watts", v.Watts}},
+		}}
+	case *protocol.SchedMetric:
+		return []Sample{{Measurement: "sched", Fields: []Field{
+			{"ctxt_per_sec", v.ContextSwitchesPerSec},
+			{"intr_per_sec", v.InterruptsPerSec},
+		}}}
+	case *protocol.SwapDeviceMetric:
+		return []Sample{{
+			Measurement: "swap_device",
+			Tags:        []Tag{{"name", v.Name}, {"type", v.Type}},
+			Fields: []Field{
+				{"size", float64(v.Size)},
+				{"used", float64(v.Used)},
+			},
+		}}
+	case *protocol.SlabMetric:
+		samples := []Sample{{Measurement: "slab", Fields: []Field{{"total_bytes", float64(v.TotalBytes)}}}}
+		for _, c := range v.Caches {
+			samples = append(samples, Sample{
+				Measurement: "slab_cache",
+				Tags:        []Tag{{"cache", c.Name}},
+				Fields:      []Field{{"bytes", float64(c.Bytes)}, {"active_objs", float64(c.ActiveObjs)}},
+			})
+		}
+		return samples
+	case *protocol.SocketStatMetric:
+		return []Sample{{Measurement: "sockets", Fields: []Field{
+			{"used", float64(v.Used)},
+			{"tcp_inuse", float64(v.TCPInUse + v.TCP6InUse)},
+			{"tcp_orphan", float64(v.TCPOrphan)},
+			{"tcp_tw", float64(v.TCPTimeWait)},
+			{"udp_inuse", float64(v.UDPInUse + v.UDP6InUse)},
+		}}}
+	case *protocol.TCPStatMetric:
+		return []Sample{{Measurement: "tcp", Fields: []Field{
+			{"retrans_per_sec", v.RetransPerSec},
+			{"retrans_pct", v.RetransPct},
+			{"in_errs_per_sec", v.InErrsPerSec},
+			{"out_rsts_per_sec", v.OutRstsPerSec},
+			{"timeouts_per_sec", v.TimeoutsPerSec},
+			{"listen_drops_per_sec", v.ListenDropsPerSec},
+		}}}
+	case *protocol.EntropyMetric:
+		return []Sample{{Measurement: "entropy", Fields: []Field{
+			{"available_bits", float64(v.Available)},
+		}}}
+	case *protocol.ProcessSummaryMetric:
+		return []Sample{{Measurement: "processes", Fields: []Field{
+			{"total", float64(v.Total)},
+			{"running", float64(v.Running)},
+			{"sleeping", float64(v.Sleeping)},
+			{"stopped", float64(v.Stopped)},
+			{"zombie", float64(v.Zombie)},
+			{"threads", float64(v.Threads)},
+		}}}
+	}
+	return nil
+}
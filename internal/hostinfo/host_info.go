@@ -4,12 +4,50 @@ import (
 	"net"
 	"os"
 	"runtime"
+	"slices"
+	"sync"
 
 	"github.com/nhdewitt/spectra/internal/platform"
 	"github.com/nhdewitt/spectra/internal/protocol"
 	"github.com/nhdewitt/spectra/internal/version"
 )
 
+var (
+	cacheMu sync.Mutex
+	cached  *protocol.HostInfo
+
+	// gather is swapped out in tests to count collections.
+	gather = CollectHostInfo
+)
+
+// Cached returns the host info gathered on first use, so registration
+// retries and reconnects don't re-run uname/WMI lookups. Call Refresh to
+// gather it again.
+func Cached() protocol.HostInfo {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if cached == nil {
+		info := gather()
+		cached = &info
+	}
+
+	info := *cached
+	info.IPs = slices.Clone(info.IPs)
+	info.Tags = slices.Clone(info.Tags)
+	return info
+}
+
+// Refresh drops the cached host info; the next Cached call gathers it
+// again.
+func Refresh() {
+	cacheMu.Lock()
+	cached = nil
+	cacheMu.Unlock()
+}
+
+// CollectHostInfo gathers host info from the system. Most callers want
+// Cached instead.
 func CollectHostInfo() protocol.HostInfo {
 	plat, platVer := getPlatformInfo()
 
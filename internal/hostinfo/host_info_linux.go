@@ -55,13 +55,32 @@ func getPlatformInfoFrom(r io.Reader) (platform, version string) {
 	return platform, version
 }
 
+// getKernel returns the kernel release from uname, falling back to
+// /proc/sys/kernel/osrelease if the syscall fails.
 func getKernel() string {
 	var uname syscall.Utsname
-	if err := syscall.Uname(&uname); err != nil {
+	if err := syscall.Uname(&uname); err == nil {
+		if release := util.CharsToString(uname.Release[:]); release != "" {
+			return release
+		}
+	}
+
+	f, err := os.Open("/proc/sys/kernel/osrelease")
+	if err != nil {
 		return ""
 	}
+	defer f.Close()
 
-	return util.CharsToString(uname.Release[:])
+	return getKernelFrom(f)
+}
+
+// getKernelFrom reads the first line of an osrelease file.
+func getKernelFrom(r io.Reader) string {
+	scanner := bufio.NewScanner(r)
+	if scanner.Scan() {
+		return strings.TrimSpace(scanner.Text())
+	}
+	return ""
 }
 
 // getCPUModel returns a human-readable CPU model string. It first attempts to
@@ -9,6 +9,27 @@ import (
 	"github.com/nhdewitt/spectra/internal/util"
 )
 
+func TestGetKernelFrom(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Debian", "6.1.0-18-amd64\n", "6.1.0-18-amd64"},
+		{"Raspberry Pi", "6.6.31+rpt-rpi-v8\n", "6.6.31+rpt-rpi-v8"},
+		{"Surrounding Whitespace", "  5.15.0-91-generic  \n", "5.15.0-91-generic"},
+		{"Empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getKernelFrom(strings.NewReader(tt.input)); got != tt.want {
+				t.Errorf("getKernelFrom() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestPlatformInfoFrom(t *testing.T) {
 	tests := []struct {
 		name            string
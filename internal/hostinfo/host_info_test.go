@@ -0,0 +1,41 @@
+package hostinfo
+
+import (
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestCached_GathersOnce(t *testing.T) {
+	calls := 0
+	orig := gather
+	gather = func() protocol.HostInfo {
+		calls++
+		return protocol.HostInfo{Hostname: "pi", IPs: []string{"10.0.0.5"}}
+	}
+	t.Cleanup(func() {
+		gather = orig
+		Refresh()
+	})
+	Refresh()
+
+	first := Cached()
+	first.IPs[0] = "mutated"
+	second := Cached()
+
+	if calls != 1 {
+		t.Errorf("gatherer called %d times, want 1", calls)
+	}
+	if second.Hostname != "pi" {
+		t.Errorf("Hostname: got %q, want pi", second.Hostname)
+	}
+	if second.IPs[0] != "10.0.0.5" {
+		t.Errorf("cached IPs changed through a returned copy: %v", second.IPs)
+	}
+
+	Refresh()
+	Cached()
+	if calls != 2 {
+		t.Errorf("after Refresh: gatherer called %d times, want 2", calls)
+	}
+}
@@ -148,6 +148,25 @@ func ParseLevel(s string) slog.Level {
 	}
 }
 
+// FromHandler wraps h in a Logger, for callers that route logs somewhere
+// other than the console and log file (tests, embedding applications).
+// Level changes apply only if h consults the returned LevelVars.
+func FromHandler(h slog.Handler) *Logger {
+	return &Logger{
+		Logger:       slog.New(h),
+		ConsoleLevel: &slog.LevelVar{},
+		FileLevel:    &slog.LevelVar{},
+	}
+}
+
+// WithHandler returns a copy of l that logs through h, keeping l's level
+// controls and log file.
+func (l *Logger) WithHandler(h slog.Handler) *Logger {
+	c := *l
+	c.Logger = slog.New(h)
+	return &c
+}
+
 // NewDiscard returns a Logger that discards all output. For tests and benchmarks.
 func NewDiscard() *Logger {
 	level := &slog.LevelVar{}
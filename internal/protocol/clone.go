@@ -0,0 +1,162 @@
+package protocol
+
+import "slices"
+
+// Clone implementations deep-copy slice and pointer fields so the copy can
+// be handed to another goroutine while the original keeps being used. Every
+// Clone returns a pointer, matching how the server decodes metrics, even
+// when called on a value.
+
+// Clone returns a deep copy of the envelope and its metric.
+func (e Envelope) Clone() Envelope {
+	if e.Data != nil {
+		e.Data = e.Data.Clone()
+	}
+	return e
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+func (m CPUMetric) Clone() Metric {
+	m.CoreUsage = slices.Clone(m.CoreUsage)
+	return &m
+}
+
+func (m MemoryMetric) Clone() Metric  { return &m }
+func (m DiskMetric) Clone() Metric    { return &m }
+func (m DiskIOMetric) Clone() Metric  { return &m }
+func (m NetworkMetric) Clone() Metric { return &m }
+func (m WiFiMetric) Clone() Metric    { return &m }
+func (m SystemMetric) Clone() Metric  { return &m }
+
+func (m TemperatureMetric) Clone() Metric {
+	m.Max = clonePtr(m.Max)
+	return &m
+}
+
+func (m ProcessMetric) Clone() Metric {
+	c := m.clone()
+	return &c
+}
+
+func (m ProcessMetric) clone() ProcessMetric {
+	m.ThreadsRunning = clonePtr(m.ThreadsRunning)
+	m.ThreadsRunnable = clonePtr(m.ThreadsRunnable)
+	m.ThreadsWaiting = clonePtr(m.ThreadsWaiting)
+	return m
+}
+
+func (m ProcessListMetric) Clone() Metric {
+	if m.Processes != nil {
+		procs := make([]ProcessMetric, len(m.Processes))
+		for i, p := range m.Processes {
+			procs[i] = p.clone()
+		}
+		m.Processes = procs
+	}
+	return &m
+}
+
+func (m ThrottleMetric) Clone() Metric        { return &m }
+func (m ClockMetric) Clone() Metric           { return &m }
+func (m VoltageMetric) Clone() Metric         { return &m }
+func (m GPUMetric) Clone() Metric             { return &m }
+func (m GPUProcessMetric) Clone() Metric      { return &m }
+func (m ContainerMetric) Clone() Metric       { return &m }
+func (m CollectorHealthMetric) Clone() Metric { return &m }
+
+func (m ApplicationListMetric) Clone() Metric {
+	m.Applications = slices.Clone(m.Applications)
+	return &m
+}
+
+func (m ContainerListMetric) Clone() Metric {
+	m.Containers = slices.Clone(m.Containers)
+	return &m
+}
+
+func (m UpdateMetric) Clone() Metric {
+	m.Packages = slices.Clone(m.Packages)
+	return &m
+}
+
+func (m ServiceMetric) Clone() Metric { return &m }
+
+func (m ServiceListMetric) Clone() Metric {
+	m.Services = slices.Clone(m.Services)
+	return &m
+}
+
+func (m SensorMetric) Clone() Metric         { return &m }
+func (m PowerDrawMetric) Clone() Metric      { return &m }
+func (m SchedMetric) Clone() Metric          { return &m }
+func (m SwapDeviceMetric) Clone() Metric     { return &m }
+func (m EntropyMetric) Clone() Metric        { return &m }
+func (m SocketStatMetric) Clone() Metric     { return &m }
+func (m TCPStatMetric) Clone() Metric        { return &m }
+func (m ProcessSummaryMetric) Clone() Metric { return &m }
+func (m DMIMetric) Clone() Metric            { return &m }
+
+func (m SlabMetric) Clone() Metric {
+	m.Caches = slices.Clone(m.Caches)
+	return &m
+}
+
+func (m FailedUnitMetric) Clone() Metric { return &m }
+
+func (m FailedUnitListMetric) Clone() Metric {
+	m.Units = slices.Clone(m.Units)
+	return &m
+}
+
+func (m TimerMetric) Clone() Metric {
+	c := m.clone()
+	return &c
+}
+
+func (m TimerMetric) clone() TimerMetric {
+	m.NextElapse = clonePtr(m.NextElapse)
+	m.LastTrigger = clonePtr(m.LastTrigger)
+	return m
+}
+
+func (m TimerListMetric) Clone() Metric {
+	if m.Timers != nil {
+		timers := make([]TimerMetric, len(m.Timers))
+		for i, t := range m.Timers {
+			timers[i] = t.clone()
+		}
+		m.Timers = timers
+	}
+	return &m
+}
+
+func (m WiFiScanMetric) Clone() Metric {
+	m.Networks = slices.Clone(m.Networks)
+	return &m
+}
+
+func (m ARPTableMetric) Clone() Metric {
+	m.Entries = slices.Clone(m.Entries)
+	return &m
+}
+
+func (m USBDeviceMetric) Clone() Metric { return &m }
+
+func (m USBDeviceListMetric) Clone() Metric {
+	m.Devices = slices.Clone(m.Devices)
+	return &m
+}
+
+func (m PCIDeviceMetric) Clone() Metric { return &m }
+
+func (m PCIDeviceListMetric) Clone() Metric {
+	m.Devices = slices.Clone(m.Devices)
+	return &m
+}
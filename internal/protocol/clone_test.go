@@ -0,0 +1,159 @@
+package protocol
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClone_AllTypes(t *testing.T) {
+	metrics := []Metric{
+		CPUMetric{Usage: 50, CoreUsage: []float64{40, 60}},
+		MemoryMetric{Total: 100},
+		DiskMetric{Device: "/dev/sda1"},
+		DiskIOMetric{Device: "sda"},
+		NetworkMetric{Interface: "eth0"},
+		WiFiMetric{Interface: "wlan0"},
+		WiFiScanMetric{Networks: []WiFiNetwork{{SSID: "home"}}},
+		ARPTableMetric{Entries: []ARPEntry{{IP: "10.0.0.1"}}},
+		SocketStatMetric{Used: 100},
+		TCPStatMetric{RetransPerSec: 1},
+		TemperatureMetric{Sensor: "cpu", Max: new(95.0)},
+		SensorMetric{Name: "fan1"},
+		PowerDrawMetric{Domain: "package-0"},
+		SystemMetric{Uptime: 60},
+		SchedMetric{},
+		SwapDeviceMetric{Name: "/swapfile"},
+		SlabMetric{Caches: []SlabCache{{Name: "dentry"}}},
+		EntropyMetric{},
+		ProcessMetric{Pid: 1, ThreadsRunning: new(uint32(2))},
+		ProcessListMetric{Processes: []ProcessMetric{{Pid: 1}}},
+		ProcessSummaryMetric{},
+		ThrottleMetric{},
+		ClockMetric{},
+		VoltageMetric{},
+		GPUMetric{},
+		GPUProcessMetric{},
+		ServiceMetric{Name: "ssh"},
+		ServiceListMetric{Services: []ServiceMetric{{Name: "ssh"}}},
+		FailedUnitMetric{},
+		FailedUnitListMetric{Units: []FailedUnitMetric{{}}},
+		TimerMetric{NextElapse: new(time.Unix(0, 0))},
+		TimerListMetric{Timers: []TimerMetric{{}}},
+		USBDeviceMetric{},
+		USBDeviceListMetric{Devices: []USBDeviceMetric{{}}},
+		PCIDeviceMetric{},
+		PCIDeviceListMetric{Devices: []PCIDeviceMetric{{}}},
+		DMIMetric{},
+		ApplicationListMetric{Applications: []Application{{Name: "vim"}}},
+		ContainerMetric{ID: "abc"},
+		ContainerListMetric{Containers: []ContainerMetric{{ID: "abc"}}},
+		UpdateMetric{Packages: []PendingUpdate{{Name: "curl"}}},
+		CollectorHealthMetric{Name: "cpu"},
+	}
+
+	for _, m := range metrics {
+		t.Run(m.MetricType(), func(t *testing.T) {
+			c := m.Clone()
+			v := reflect.ValueOf(c)
+			if v.Kind() != reflect.Pointer || v.Elem().Type() != reflect.TypeOf(m) {
+				t.Fatalf("Clone() returned %T, want *%T", c, m)
+			}
+			if got := v.Elem().Interface(); !reflect.DeepEqual(got, m) {
+				t.Errorf("Clone() = %+v, want %+v", got, m)
+			}
+
+			// Cloning a pointer gives the same result
+			p := reflect.New(reflect.TypeOf(m))
+			p.Elem().Set(reflect.ValueOf(m))
+			if got := p.Interface().(Metric).Clone(); !reflect.DeepEqual(got, c) {
+				t.Errorf("pointer Clone() = %+v, want %+v", got, c)
+			}
+		})
+	}
+}
+
+func TestCPUMetric_CloneIsDeep(t *testing.T) {
+	orig := &CPUMetric{Usage: 50, CoreUsage: []float64{40, 60}}
+
+	c := orig.Clone().(*CPUMetric)
+	c.CoreUsage[0] = 99
+	c.CoreUsage = append(c.CoreUsage, 1)
+
+	if orig.CoreUsage[0] != 40 || len(orig.CoreUsage) != 2 {
+		t.Errorf("original mutated: %v", orig.CoreUsage)
+	}
+}
+
+func TestProcessListMetric_CloneIsDeep(t *testing.T) {
+	running := uint32(3)
+	orig := &ProcessListMetric{Processes: []ProcessMetric{
+		{Pid: 1, Name: "init", ThreadsRunning: &running},
+	}}
+
+	c := orig.Clone().(*ProcessListMetric)
+	c.Processes[0].Name = "changed"
+	*c.Processes[0].ThreadsRunning = 99
+
+	if orig.Processes[0].Name != "init" {
+		t.Errorf("Name = %q, want init", orig.Processes[0].Name)
+	}
+	if *orig.Processes[0].ThreadsRunning != 3 {
+		t.Errorf("ThreadsRunning = %d, want 3", *orig.Processes[0].ThreadsRunning)
+	}
+}
+
+func TestTimerListMetric_CloneIsDeep(t *testing.T) {
+	next := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	orig := &TimerListMetric{Timers: []TimerMetric{{Unit: "backup.timer", NextElapse: &next}}}
+
+	c := orig.Clone().(*TimerListMetric)
+	*c.Timers[0].NextElapse = next.Add(time.Hour)
+
+	if !orig.Timers[0].NextElapse.Equal(next) {
+		t.Errorf("NextElapse = %v, want %v", *orig.Timers[0].NextElapse, next)
+	}
+}
+
+func TestTemperatureMetric_CloneIsDeep(t *testing.T) {
+	limit := 90.0
+	orig := &TemperatureMetric{Sensor: "cpu", Temp: 50, Max: &limit}
+
+	c := orig.Clone().(*TemperatureMetric)
+	*c.Max = 10
+
+	if *orig.Max != 90 {
+		t.Errorf("Max = %f, want 90", *orig.Max)
+	}
+}
+
+func TestClone_PreservesNilAndEmpty(t *testing.T) {
+	if c := (CPUMetric{}).Clone().(*CPUMetric); c.CoreUsage != nil {
+		t.Errorf("nil CoreUsage cloned to %v", c.CoreUsage)
+	}
+	if c := (ProcessListMetric{Processes: []ProcessMetric{}}).Clone().(*ProcessListMetric); c.Processes == nil {
+		t.Error("empty Processes cloned to nil")
+	}
+}
+
+func TestEnvelope_Clone(t *testing.T) {
+	orig := Envelope{
+		Type:     TypeCPU,
+		Hostname: "web-01",
+		Data:     &CPUMetric{CoreUsage: []float64{10, 20}},
+	}
+
+	c := orig.Clone()
+	if c.Hostname != "web-01" || c.Type != TypeCPU {
+		t.Errorf("metadata not copied: %+v", c)
+	}
+	c.Data.(*CPUMetric).CoreUsage[1] = 99
+
+	if got := orig.Data.(*CPUMetric).CoreUsage[1]; got != 20 {
+		t.Errorf("original CoreUsage[1] = %f, want 20", got)
+	}
+
+	if empty := (Envelope{Type: TypeCPU}).Clone(); empty.Data != nil {
+		t.Errorf("nil Data cloned to %v", empty.Data)
+	}
+}
@@ -0,0 +1,28 @@
+package protocol
+
+import (
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Content types understood by the metrics endpoint.
+const (
+	ContentTypeJSON    = "application/json"
+	ContentTypeMsgpack = "application/msgpack"
+)
+
+// NewMsgpackEncoder returns a MessagePack encoder that uses the json
+// struct tags, so every type encodes with the same field names as JSON.
+func NewMsgpackEncoder(w io.Writer) *msgpack.Encoder {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	return enc
+}
+
+// NewMsgpackDecoder is the decoding counterpart of NewMsgpackEncoder.
+func NewMsgpackDecoder(r io.Reader) *msgpack.Decoder {
+	dec := msgpack.NewDecoder(r)
+	dec.SetCustomStructTag("json")
+	return dec
+}
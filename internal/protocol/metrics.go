@@ -8,6 +8,12 @@ import (
 // Metric is implemented by all metric types
 type Metric interface {
 	MetricType() string
+	// Validate reports whether the values are plausible, so malformed
+	// metrics can be rejected before they are stored.
+	Validate() error
+	// Clone returns a deep copy that shares no slices or pointers with
+	// the original.
+	Clone() Metric
 }
 
 // ProcessListMetric holds all proccesses from a single collection
@@ -15,12 +21,18 @@ type ProcessListMetric struct {
 	Processes []ProcessMetric `json:"processes"`
 }
 
+// SchemaVersion is the current envelope wire version. Bump it whenever
+// a metric changes incompatibly so the server can tell old and new apart.
+// Envelopes without a version (0) predate versioning.
+const SchemaVersion = 1
+
 // Envelope wraps any metric with metadata for transmission
 type Envelope struct {
-	Type      string    `json:"type"`
-	Timestamp time.Time `json:"timestamp"`
-	Hostname  string    `json:"hostname"`
-	Data      Metric    `json:"data"`
+	Type          string    `json:"type"`
+	SchemaVersion int       `json:"schema_version"`
+	Timestamp     time.Time `json:"timestamp"`
+	Hostname      string    `json:"hostname"`
+	Data          Metric    `json:"data"`
 }
 
 // MarshalJSON ensured proper serialization with the concrete type
@@ -35,25 +47,72 @@ func (e Envelope) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// Metric type names, as sent in Envelope.Type and returned by MetricType.
+const (
+	TypeCPU             = "cpu"
+	TypeMemory          = "memory"
+	TypeDisk            = "disk"
+	TypeDiskIO          = "disk_io"
+	TypeNetwork         = "network"
+	TypeWiFi            = "wifi"
+	TypeWiFiScan        = "wifi_scan"
+	TypeARPTable        = "arp_table"
+	TypeSocketStat      = "socket_stat"
+	TypeTCPStat         = "tcp_stat"
+	TypeTemperature     = "temperature"
+	TypeSensor          = "sensor"
+	TypePowerDraw       = "power_draw"
+	TypeSystem          = "system"
+	TypeSched           = "sched"
+	TypeSwapDevice      = "swap_device"
+	TypeSlab            = "slab"
+	TypeEntropy         = "entropy"
+	TypeProcess         = "process"
+	TypeProcessList     = "process_list"
+	TypeProcessSummary  = "process_summary"
+	TypeThrottle        = "throttle"
+	TypeClock           = "clock"
+	TypeVoltage         = "voltage"
+	TypeGPU             = "gpu"
+	TypeGPUProcess      = "gpu_process"
+	TypeService         = "service"
+	TypeServiceList     = "service_list"
+	TypeFailedUnit      = "failed_unit"
+	TypeFailedUnitList  = "failed_unit_list"
+	TypeTimer           = "timer"
+	TypeTimerList       = "timer_list"
+	TypeUSBDevice       = "usb_device"
+	TypeUSBDeviceList   = "usb_device_list"
+	TypePCIDevice       = "pci_device"
+	TypePCIDeviceList   = "pci_device_list"
+	TypeDMI             = "dmi"
+	TypeApplicationList = "application_list"
+	TypeContainer       = "container"
+	TypeContainerList   = "container_list"
+	TypeUpdates         = "updates"
+	TypeCollectorHealth = "collector_health"
+)
+
 // Impelement the interface on each metric type
-func (CPUMetric) MetricType() string             { return "cpu" }
-func (MemoryMetric) MetricType() string          { return "memory" }
-func (DiskMetric) MetricType() string            { return "disk" }
-func (NetworkMetric) MetricType() string         { return "network" }
-func (TemperatureMetric) MetricType() string     { return "temperature" }
-func (SystemMetric) MetricType() string          { return "system" }
-func (DiskIOMetric) MetricType() string          { return "disk_io" }
-func (ProcessMetric) MetricType() string         { return "process" }
-func (ProcessListMetric) MetricType() string     { return "process_list" }
-func (ThrottleMetric) MetricType() string        { return "throttle" }
-func (ClockMetric) MetricType() string           { return "clock" }
-func (VoltageMetric) MetricType() string         { return "voltage" }
-func (WiFiMetric) MetricType() string            { return "wifi" }
-func (GPUMetric) MetricType() string             { return "gpu" }
-func (ApplicationListMetric) MetricType() string { return "application_list" }
-func (ContainerMetric) MetricType() string       { return "container" }
-func (ContainerListMetric) MetricType() string   { return "container_list" }
-func (UpdateMetric) MetricType() string          { return "updates" }
+func (CPUMetric) MetricType() string             { return TypeCPU }
+func (MemoryMetric) MetricType() string          { return TypeMemory }
+func (DiskMetric) MetricType() string            { return TypeDisk }
+func (NetworkMetric) MetricType() string         { return TypeNetwork }
+func (TemperatureMetric) MetricType() string     { return TypeTemperature }
+func (SystemMetric) MetricType() string          { return TypeSystem }
+func (DiskIOMetric) MetricType() string          { return TypeDiskIO }
+func (ProcessMetric) MetricType() string         { return TypeProcess }
+func (ProcessListMetric) MetricType() string     { return TypeProcessList }
+func (ThrottleMetric) MetricType() string        { return TypeThrottle }
+func (ClockMetric) MetricType() string           { return TypeClock }
+func (VoltageMetric) MetricType() string         { return TypeVoltage }
+func (WiFiMetric) MetricType() string            { return TypeWiFi }
+func (GPUMetric) MetricType() string             { return TypeGPU }
+func (ApplicationListMetric) MetricType() string { return TypeApplicationList }
+func (ContainerMetric) MetricType() string       { return TypeContainer }
+func (ContainerListMetric) MetricType() string   { return TypeContainerList }
+func (UpdateMetric) MetricType() string          { return TypeUpdates }
+func (CollectorHealthMetric) MetricType() string { return TypeCollectorHealth }
 
 type CPUMetric struct {
 	Usage     float64   `json:"usage"`
@@ -62,6 +121,8 @@ type CPUMetric struct {
 	LoadAvg1  float64   `json:"load_1m"`
 	LoadAvg5  float64   `json:"load_5m,omitempty"`
 	LoadAvg15 float64   `json:"load_15m,omitempty"`
+	FreqMHz   float64   `json:"freq_mhz,omitempty"` // Average current frequency across cores
+	Governor  string    `json:"governor,omitempty"` // cpufreq scaling governor
 }
 
 type MemoryMetric struct {
@@ -72,20 +133,32 @@ type MemoryMetric struct {
 	SwapTotal uint64  `json:"swap_total"`
 	SwapUsed  uint64  `json:"swap_used"`
 	SwapPct   float64 `json:"swap_pct"`
+	Buffers   uint64  `json:"buffers,omitempty"`
+	Cached    uint64  `json:"cached,omitempty"`
+	Shmem     uint64  `json:"shmem,omitempty"`
+	Dirty     uint64  `json:"dirty,omitempty"`
+
+	// Writeback is memory actively being written to disk; WritebackTmp is
+	// FUSE writeback buffering. A Writeback that stays high alongside Dirty
+	// means the backing device can't keep up (Linux only).
+	Writeback    uint64 `json:"writeback,omitempty"`
+	WritebackTmp uint64 `json:"writeback_tmp,omitempty"`
 }
 
 type DiskMetric struct {
-	Device      string  `json:"device"`
-	Mountpoint  string  `json:"mountpoint"`
-	Filesystem  string  `json:"filesystem"`
-	Type        string  `json:"disk_type"`
-	Total       uint64  `json:"disk_total"`
-	Used        uint64  `json:"disk_used"`
-	Available   uint64  `json:"disk_available"`
-	UsedPct     float64 `json:"disk_used_pct"`
-	InodesTotal uint64  `json:"inodes_total,omitempty"`
-	InodesUsed  uint64  `json:"inodes_used,omitempty"`
-	InodesPct   float64 `json:"inodes_pct,omitempty"`
+	Device          string  `json:"device"`
+	Mountpoint      string  `json:"mountpoint"`
+	Filesystem      string  `json:"filesystem"`
+	Type            string  `json:"disk_type"` // nvme, ssd, hdd or unknown on Linux; local or other elsewhere
+	Total           uint64  `json:"disk_total"`
+	Used            uint64  `json:"disk_used"`
+	Available       uint64  `json:"disk_available"`
+	UsedPct         float64 `json:"disk_used_pct"`
+	InodesTotal     uint64  `json:"inodes_total,omitempty"`
+	InodesUsed      uint64  `json:"inodes_used,omitempty"`
+	InodesPct       float64 `json:"inodes_pct,omitempty"`
+	InodesAvailable uint64  `json:"inodes_available,omitempty"`
+	ReadOnly        bool    `json:"read_only,omitempty"` // mounted read-only, e.g. after the kernel remounted it on errors
 }
 
 // NetworkMetric holds per-interface network statistics.
@@ -131,6 +204,8 @@ type DiskIOMetric struct {
 
 type ProcessMetric struct {
 	Pid             int        `json:"pid"`
+	PPid            int        `json:"ppid"`
+	StartTime       int64      `json:"start_time,omitempty"` // unix seconds
 	Name            string     `json:"name"`
 	CPUPercent      float64    `json:"cpu_percent"`
 	MemPercent      float64    `json:"mem_percent"`
@@ -140,6 +215,8 @@ type ProcessMetric struct {
 	ThreadsRunning  *uint32    `json:"threads_running,omitempty"`
 	ThreadsRunnable *uint32    `json:"threads_runnable,omitempty"`
 	ThreadsWaiting  *uint32    `json:"threads_waiting,omitempty"`
+	ReadBytes       uint64     `json:"read_bytes,omitempty"`  // storage reads since the previous sample
+	WriteBytes      uint64     `json:"write_bytes,omitempty"` // storage writes since the previous sample
 }
 
 type ThrottleMetric struct {
@@ -171,7 +248,7 @@ type WiFiMetric struct {
 	SSID        string  `json:"ssid"`
 	SignalLevel int     `json:"signal_dbm"`
 	LinkQuality int     `json:"link_quality"`
-	Frequency   float64 `json:"frequency_ghz"`
+	Frequency   float64 `json:"frequency_ghz"` // always GHz; collectors convert from MHz
 	BitRate     float64 `json:"bitrate_mbps"`
 }
 
@@ -203,6 +280,8 @@ type ContainerMetric struct {
 	MemoryLimit   uint64  `json:"memory_limit,omitempty"`
 	NetRxBytes    uint64  `json:"net_rx_bytes,omitempty"`
 	NetTxBytes    uint64  `json:"net_tx_bytes,omitempty"`
+	RestartCount  int     `json:"restart_count,omitempty"` // Docker only
+	Health        string  `json:"health,omitempty"`        // "healthy", "unhealthy", "starting"; empty without a healthcheck
 }
 
 type ContainerListMetric struct {
@@ -222,3 +301,12 @@ type UpdateMetric struct {
 	PackageManager string          `json:"package_manager"`
 	Packages       []PendingUpdate `json:"packages,omitempty"`
 }
+
+// CollectorHealthMetric reports the recent outcome of a single named
+// collector so silent collection gaps become visible on the server.
+type CollectorHealthMetric struct {
+	Name              string    `json:"name"`
+	LastSuccess       time.Time `json:"last_success"`
+	ConsecutiveErrors int       `json:"consecutive_errors"`
+	LastError         string    `json:"last_error,omitempty"`
+}
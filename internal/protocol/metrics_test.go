@@ -124,11 +124,33 @@ func TestMetricType(t *testing.T) {
 		{VoltageMetric{}, "voltage"},
 		{WiFiMetric{}, "wifi"},
 		{GPUMetric{}, "gpu"},
+		{GPUProcessMetric{}, "gpu_process"},
 		{ApplicationListMetric{}, "application_list"},
 		{ContainerMetric{}, "container"},
 		{ContainerListMetric{}, "container_list"},
 		{ServiceMetric{}, "service"},
 		{ServiceListMetric{}, "service_list"},
+		{USBDeviceMetric{}, "usb_device"},
+		{USBDeviceListMetric{}, "usb_device_list"},
+		{PCIDeviceMetric{}, "pci_device"},
+		{PCIDeviceListMetric{}, "pci_device_list"},
+		{DMIMetric{}, "dmi"},
+		{WiFiScanMetric{}, "wifi_scan"},
+		{SensorMetric{}, "sensor"},
+		{PowerDrawMetric{}, "power_draw"},
+		{SchedMetric{}, "sched"},
+		{EntropyMetric{}, "entropy"},
+		{SwapDeviceMetric{}, "swap_device"},
+		{SlabMetric{}, "slab"},
+		{ARPTableMetric{}, "arp_table"},
+		{SocketStatMetric{}, "socket_stat"},
+		{TCPStatMetric{}, "tcp_stat"},
+		{ProcessSummaryMetric{}, "process_summary"},
+		{FailedUnitMetric{}, "failed_unit"},
+		{FailedUnitListMetric{}, "failed_unit_list"},
+		{TimerMetric{}, "timer"},
+		{TimerListMetric{}, "timer_list"},
+		{CollectorHealthMetric{}, "collector_health"},
 	}
 
 	for _, tt := range tests {
@@ -139,3 +161,61 @@ func TestMetricType(t *testing.T) {
 		})
 	}
 }
+
+func TestMetricType_MatchesConstant(t *testing.T) {
+	tests := []struct {
+		metric Metric
+		want   string
+	}{
+		{CPUMetric{}, TypeCPU},
+		{MemoryMetric{}, TypeMemory},
+		{DiskMetric{}, TypeDisk},
+		{DiskIOMetric{}, TypeDiskIO},
+		{NetworkMetric{}, TypeNetwork},
+		{WiFiMetric{}, TypeWiFi},
+		{WiFiScanMetric{}, TypeWiFiScan},
+		{ARPTableMetric{}, TypeARPTable},
+		{SocketStatMetric{}, TypeSocketStat},
+		{TCPStatMetric{}, TypeTCPStat},
+		{TemperatureMetric{}, TypeTemperature},
+		{SensorMetric{}, TypeSensor},
+		{PowerDrawMetric{}, TypePowerDraw},
+		{SystemMetric{}, TypeSystem},
+		{SchedMetric{}, TypeSched},
+		{SwapDeviceMetric{}, TypeSwapDevice},
+		{SlabMetric{}, TypeSlab},
+		{EntropyMetric{}, TypeEntropy},
+		{ProcessMetric{}, TypeProcess},
+		{ProcessListMetric{}, TypeProcessList},
+		{ProcessSummaryMetric{}, TypeProcessSummary},
+		{ThrottleMetric{}, TypeThrottle},
+		{ClockMetric{}, TypeClock},
+		{VoltageMetric{}, TypeVoltage},
+		{GPUMetric{}, TypeGPU},
+		{GPUProcessMetric{}, TypeGPUProcess},
+		{ServiceMetric{}, TypeService},
+		{ServiceListMetric{}, TypeServiceList},
+		{FailedUnitMetric{}, TypeFailedUnit},
+		{FailedUnitListMetric{}, TypeFailedUnitList},
+		{TimerMetric{}, TypeTimer},
+		{TimerListMetric{}, TypeTimerList},
+		{USBDeviceMetric{}, TypeUSBDevice},
+		{USBDeviceListMetric{}, TypeUSBDeviceList},
+		{PCIDeviceMetric{}, TypePCIDevice},
+		{PCIDeviceListMetric{}, TypePCIDeviceList},
+		{DMIMetric{}, TypeDMI},
+		{ApplicationListMetric{}, TypeApplicationList},
+		{ContainerMetric{}, TypeContainer},
+		{ContainerListMetric{}, TypeContainerList},
+		{UpdateMetric{}, TypeUpdates},
+		{CollectorHealthMetric{}, TypeCollectorHealth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.metric.MetricType(); got != tt.want {
+				t.Errorf("%T.MetricType() = %s, want %s", tt.metric, got, tt.want)
+			}
+		})
+	}
+}
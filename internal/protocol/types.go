@@ -29,6 +29,37 @@ var PriorityToLevel = map[int]LogLevel{
 	7: LevelDebug,
 }
 
+// Severity returns the level's syslog severity, from 0 (emergency) to 7
+// (debug); lower is more severe. Unknown levels are treated as info.
+func (l LogLevel) Severity() int {
+	switch l {
+	case LevelEmergency:
+		return 0
+	case LevelAlert:
+		return 1
+	case LevelCritical:
+		return 2
+	case LevelError:
+		return 3
+	case LevelWarning:
+		return 4
+	case LevelNotice:
+		return 5
+	case LevelInfo:
+		return 6
+	case LevelDebug:
+		return 7
+	default:
+		return 6
+	}
+}
+
+// AtLeast reports whether l is as severe as min or more, i.e. whether an
+// entry at level l passes a min-level filter.
+func (l LogLevel) AtLeast(min LogLevel) bool {
+	return l.Severity() <= min.Severity()
+}
+
 type LogEntry struct {
 	Timestamp   int64    `json:"timestamp"`
 	Source      string   `json:"source"`
@@ -36,6 +67,7 @@ type LogEntry struct {
 	Message     string   `json:"message"`
 	ProcessID   int      `json:"pid,omitempty"`
 	ProcessName string   `json:"process_name,omitempty"`
+	Count       int      `json:"count,omitempty"` // Number of identical consecutive entries, when collapsed
 }
 
 type CommandType string
@@ -47,12 +79,23 @@ const (
 	CmdListMounts   CommandType = "LIST_MOUNTS"
 	CmdNetworkDiag  CommandType = "NETWORK_DIAG"
 	CmdUpdateAgent  CommandType = "UPDATE_AGENT"
+	CmdFollowLogs   CommandType = "FOLLOW_LOGS"
+	CmdCancel       CommandType = "CANCEL"
+	CmdCertCheck    CommandType = "CERT_CHECK"
+	CmdIOStat       CommandType = "IOSTAT"
+	CmdTopProcesses CommandType = "TOP_PROCESSES"
+	CmdRouteTable   CommandType = "ROUTE_TABLE"
+	CmdThroughput   CommandType = "THROUGHPUT"
+	CmdMTUProbe     CommandType = "MTU_PROBE"
+	CmdProcFiles    CommandType = "PROC_FILES"
+	CmdMountLatency CommandType = "MOUNT_LATENCY"
 )
 
 type Command struct {
 	ID      string      `json:"id"`
 	Type    CommandType `json:"type"`
 	Payload []byte      `json:"payload"`
+	Timeout int         `json:"timeout,omitempty"` // seconds; 0 uses the agent default
 }
 
 // CommandResult is the response to a Command sent from the server.
@@ -61,10 +104,33 @@ type CommandResult struct {
 	Type    CommandType     `json:"type"` // Command.Type
 	Payload json.RawMessage `json:"payload"`
 	Error   string          `json:"error,omitempty"`
+	// Partial marks an intermediate result of a streaming command; more
+	// results follow, and the last one has Partial unset.
+	Partial bool `json:"partial,omitempty"`
 }
 
 type LogRequest struct {
 	MinLevel LogLevel `json:"min_level"`
+	// Boot selects a journald boot (0 = current, -1 = previous, ...).
+	// Nil keeps the default of all recent logs. Linux only.
+	Boot *int `json:"boot,omitempty"`
+	// MaxBytes caps the total message bytes returned, on top of the
+	// entry cap. Zero means no byte limit.
+	MaxBytes int `json:"max_bytes,omitempty"`
+	// Dedup collapses consecutive identical entries into one with a Count.
+	Dedup bool `json:"dedup,omitempty"`
+}
+
+// FollowLogsRequest streams new log entries until Duration elapses or the
+// command is cancelled. A zero Duration uses the agent's default.
+type FollowLogsRequest struct {
+	MinLevel        LogLevel `json:"min_level"`
+	DurationSeconds int      `json:"duration_seconds,omitempty"`
+}
+
+// CancelRequest stops a running streaming command.
+type CancelRequest struct {
+	CommandID string `json:"command_id"`
 }
 
 type ServiceMetric struct {
@@ -73,10 +139,15 @@ type ServiceMetric struct {
 	SubStatus   string `json:"sub_status"` // "running", "exited", "dead"
 	LoadState   string `json:"load_state"` // "loaded", "not-found"
 	Description string `json:"description"`
+
+	// Cgroup accounting, only filled for active units when enabled
+	MemoryCurrent uint64  `json:"memory_current,omitempty"`
+	CPUUsageNSec  uint64  `json:"cpu_usage_nsec,omitempty"`
+	CPUPercent    float64 `json:"cpu_percent,omitempty"`
 }
 
 func (m ServiceMetric) MetricType() string {
-	return "service"
+	return TypeService
 }
 
 type ServiceListMetric struct {
@@ -84,7 +155,306 @@ type ServiceListMetric struct {
 }
 
 func (m ServiceListMetric) MetricType() string {
-	return "service_list"
+	return TypeServiceList
+}
+
+// SensorMetric is a single hardware sensor reading from the BMC (IPMI)
+// or lm-sensors.
+type SensorMetric struct {
+	Source string  `json:"source"`         // "ipmi", "lm-sensors"
+	Chip   string  `json:"chip,omitempty"` // lm-sensors chip, e.g. "nct6775-isa-0290"
+	Name   string  `json:"name"`
+	Kind   string  `json:"kind"` // "fan", "voltage", "temperature", "power", "current", "other"
+	Value  float64 `json:"value"`
+	Unit   string  `json:"unit"`             // "RPM", "V", "C", "W", "A", "%"
+	Status string  `json:"status,omitempty"` // "ok", "warning", "critical"
+}
+
+func (m SensorMetric) MetricType() string {
+	return TypeSensor
+}
+
+// PowerDrawMetric is the average power of one RAPL domain over the
+// collection interval.
+type PowerDrawMetric struct {
+	Domain string  `json:"domain"` // e.g. "package-0", "package-0/dram"
+	Watts  float64 `json:"watts"`
+}
+
+func (m PowerDrawMetric) MetricType() string {
+	return TypePowerDraw
+}
+
+// SchedMetric is the system-wide context switch and interrupt rate.
+type SchedMetric struct {
+	ContextSwitchesPerSec float64 `json:"ctxt_per_sec"`
+	InterruptsPerSec      float64 `json:"intr_per_sec"`
+}
+
+func (m SchedMetric) MetricType() string {
+	return TypeSched
+}
+
+// SwapDeviceMetric is one active swap partition or file. Size and Used
+// are bytes.
+type SwapDeviceMetric struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"` // "partition", "file"
+	Size     uint64 `json:"size"`
+	Used     uint64 `json:"used"`
+	Priority int    `json:"priority"`
+}
+
+func (m SwapDeviceMetric) MetricType() string {
+	return TypeSwapDevice
+}
+
+// SlabMetric is total kernel slab memory plus the largest slab caches,
+// where kernel memory leaks show up.
+type SlabMetric struct {
+	TotalBytes uint64      `json:"total_bytes"`
+	Caches     []SlabCache `json:"caches"` // largest first
+}
+
+// SlabCache is one /proc/slabinfo cache. Bytes is the memory its slabs
+// occupy.
+type SlabCache struct {
+	Name       string `json:"name"`
+	ActiveObjs uint64 `json:"active_objs"`
+	NumObjs    uint64 `json:"num_objs"`
+	ObjSize    uint64 `json:"obj_size"`
+	Bytes      uint64 `json:"bytes"`
+}
+
+func (m SlabMetric) MetricType() string {
+	return TypeSlab
+}
+
+// EntropyMetric is the kernel entropy pool level in bits.
+type EntropyMetric struct {
+	Available int `json:"entropy_avail"`
+	PoolSize  int `json:"pool_size,omitempty"` // 0 if unknown
+}
+
+func (m EntropyMetric) MetricType() string {
+	return TypeEntropy
+}
+
+// ProcessSummaryMetric counts processes by state. Sleeping includes
+// uninterruptible (D) and idle kernel threads.
+type ProcessSummaryMetric struct {
+	Total    int `json:"total"`
+	Running  int `json:"running"`
+	Sleeping int `json:"sleeping"`
+	Stopped  int `json:"stopped"`
+	Zombie   int `json:"zombie"`
+	Threads  int `json:"threads"`
+}
+
+func (m ProcessSummaryMetric) MetricType() string {
+	return TypeProcessSummary
+}
+
+// FailedUnitMetric is a systemd unit in the failed state.
+type FailedUnitMetric struct {
+	Unit        string `json:"unit"`
+	LoadState   string `json:"load_state"`
+	ActiveState string `json:"active_state"`
+	SubState    string `json:"sub_state"`
+	Description string `json:"description"`
+}
+
+func (m FailedUnitMetric) MetricType() string {
+	return TypeFailedUnit
+}
+
+// FailedUnitListMetric lists every failed unit; empty when none have failed.
+type FailedUnitListMetric struct {
+	Units []FailedUnitMetric `json:"units"`
+}
+
+func (m FailedUnitListMetric) MetricType() string {
+	return TypeFailedUnitList
+}
+
+// TimerMetric is a single systemd timer. Times are nil when the timer is
+// not scheduled or has never run; Left and Passed are systemd's relative
+// durations, e.g. "5h 12min".
+type TimerMetric struct {
+	Unit        string     `json:"unit"`
+	Activates   string     `json:"activates"`
+	NextElapse  *time.Time `json:"next_elapse,omitempty"`
+	Left        string     `json:"left,omitempty"`
+	LastTrigger *time.Time `json:"last_trigger,omitempty"`
+	Passed      string     `json:"passed,omitempty"`
+}
+
+func (m TimerMetric) MetricType() string {
+	return TypeTimer
+}
+
+type TimerListMetric struct {
+	Timers []TimerMetric `json:"timers"`
+}
+
+func (m TimerListMetric) MetricType() string {
+	return TypeTimerList
+}
+
+// WiFiNetwork is one access point seen in a Wi-Fi scan.
+type WiFiNetwork struct {
+	Interface   string  `json:"interface"`
+	SSID        string  `json:"ssid"` // empty for hidden networks
+	BSSID       string  `json:"bssid"`
+	Frequency   float64 `json:"frequency_ghz"`
+	Channel     int     `json:"channel"`
+	SignalLevel int     `json:"signal_dbm"`
+	Associated  bool    `json:"associated,omitempty"`
+}
+
+// WiFiScanMetric lists the access points visible from every wireless
+// interface.
+type WiFiScanMetric struct {
+	Networks []WiFiNetwork `json:"networks"`
+}
+
+func (m WiFiScanMetric) MetricType() string {
+	return TypeWiFiScan
+}
+
+// ARP neighbor states.
+const (
+	ARPComplete   = "complete"
+	ARPIncomplete = "incomplete" // unresolved; MAC is empty
+	ARPPermanent  = "permanent"
+)
+
+// ARPEntry is one IPv4 neighbor the agent has resolved or tried to.
+type ARPEntry struct {
+	IP     string `json:"ip"`
+	MAC    string `json:"mac,omitempty"`
+	Device string `json:"device"`
+	State  string `json:"state"`
+}
+
+// ARPTableMetric is a snapshot of the agent's neighbor table.
+type ARPTableMetric struct {
+	Entries []ARPEntry `json:"entries"`
+}
+
+func (m ARPTableMetric) MetricType() string {
+	return TypeARPTable
+}
+
+// SocketStatMetric summarizes kernel socket usage, like `ss -s`. The
+// IPv6 counts are zero when IPv6 is disabled.
+type SocketStatMetric struct {
+	Used        int `json:"sockets_used"` // allocated sockets of every family
+	TCPInUse    int `json:"tcp_inuse"`
+	TCP6InUse   int `json:"tcp6_inuse"`
+	TCPOrphan   int `json:"tcp_orphan"` // closed by the process, still held by the kernel
+	TCPTimeWait int `json:"tcp_tw"`
+	TCPAlloc    int `json:"tcp_alloc"`
+	UDPInUse    int `json:"udp_inuse"`
+	UDP6InUse   int `json:"udp6_inuse"`
+}
+
+func (m SocketStatMetric) MetricType() string {
+	return TypeSocketStat
+}
+
+// TCPStatMetric is the system-wide TCP error rate since the previous
+// sample. A rising retransmit rate is an early sign of a degrading link.
+type TCPStatMetric struct {
+	RetransPerSec     float64 `json:"retrans_per_sec"`
+	RetransPct        float64 `json:"retrans_pct"` // retransmitted share of segments sent
+	InErrsPerSec      float64 `json:"in_errs_per_sec"`
+	OutRstsPerSec     float64 `json:"out_rsts_per_sec"`
+	TimeoutsPerSec    float64 `json:"timeouts_per_sec"`     // 0 without /proc/net/netstat
+	ListenDropsPerSec float64 `json:"listen_drops_per_sec"` // 0 without /proc/net/netstat
+}
+
+func (m TCPStatMetric) MetricType() string {
+	return TypeTCPStat
+}
+
+// USBDeviceMetric is a single attached USB device.
+type USBDeviceMetric struct {
+	Port         string `json:"port"`      // sysfs bus-port path, e.g. "1-1.2"
+	VendorID     string `json:"vendor_id"` // hex, e.g. "046d"
+	ProductID    string `json:"product_id"`
+	Manufacturer string `json:"manufacturer,omitempty"`
+	Product      string `json:"product,omitempty"`
+	Serial       string `json:"serial,omitempty"`
+}
+
+func (m USBDeviceMetric) MetricType() string {
+	return TypeUSBDevice
+}
+
+// USBDeviceListMetric is a snapshot of every attached USB device.
+type USBDeviceListMetric struct {
+	Devices []USBDeviceMetric `json:"devices"`
+}
+
+func (m USBDeviceListMetric) MetricType() string {
+	return TypeUSBDeviceList
+}
+
+// GPUProcessMetric is GPU memory held by one compute process.
+type GPUProcessMetric struct {
+	PID        int    `json:"pid"`
+	Name       string `json:"name"`
+	UsedMemory uint64 `json:"used_memory"`   // bytes; 0 when the driver doesn't report it
+	GPU        string `json:"gpu,omitempty"` // device UUID
+}
+
+func (m GPUProcessMetric) MetricType() string {
+	return TypeGPUProcess
+}
+
+// PCIDeviceMetric is a single PCI device. Names are empty when the agent
+// could not resolve the IDs.
+type PCIDeviceMetric struct {
+	Slot     string `json:"slot"`     // e.g. "00:02.0"
+	ClassID  string `json:"class_id"` // hex class and subclass, e.g. "0300"
+	Class    string `json:"class,omitempty"`
+	VendorID string `json:"vendor_id"`
+	Vendor   string `json:"vendor,omitempty"`
+	DeviceID string `json:"device_id"`
+	Device   string `json:"device,omitempty"`
+	Revision string `json:"revision,omitempty"`
+}
+
+func (m PCIDeviceMetric) MetricType() string {
+	return TypePCIDevice
+}
+
+// PCIDeviceListMetric is a snapshot of every PCI device.
+type PCIDeviceListMetric struct {
+	Devices []PCIDeviceMetric `json:"devices"`
+}
+
+func (m PCIDeviceListMetric) MetricType() string {
+	return TypePCIDeviceList
+}
+
+// DMIMetric identifies the machine's firmware, board and chassis. Fields
+// the agent can't read (often the serial, which is root-only) are empty.
+type DMIMetric struct {
+	SysVendor     string `json:"sys_vendor,omitempty"`
+	ProductName   string `json:"product_name,omitempty"`
+	ProductSerial string `json:"product_serial,omitempty"`
+	BoardVendor   string `json:"board_vendor,omitempty"`
+	BoardName     string `json:"board_name,omitempty"`
+	BIOSVendor    string `json:"bios_vendor,omitempty"`
+	BIOSVersion   string `json:"bios_version,omitempty"`
+	BIOSDate      string `json:"bios_date,omitempty"`
+	ChassisType   string `json:"chassis_type,omitempty"`
+}
+
+func (m DMIMetric) MetricType() string {
+	return TypeDMI
 }
 
 // TopEntry represents a single file or directory in the usage report
@@ -107,8 +477,9 @@ type DiskUsageTopReport struct {
 }
 
 type DiskUsageRequest struct {
-	Path string `json:"path"`  // If empty, return list of mounts from DriveCache
-	TopN int    `json:"top_n"` // Default to 50 if 0
+	Path    string `json:"path"`              // If empty, return list of mounts from DriveCache
+	TopN    int    `json:"top_n"`             // Default to 50 if 0
+	Workers int    `json:"workers,omitempty"` // Concurrent directory readers; 0 or 1 walks serially
 }
 
 // MountInfo is the universal structure sent to the server.
@@ -156,6 +527,153 @@ type NetworkDiagnosticReport struct {
 	PingResults []PingResult   `json:"ping_results,omitempty"`
 }
 
+// RouteEntry is one IPv4 route from the agent's routing table.
+type RouteEntry struct {
+	Destination string `json:"destination"`       // CIDR, e.g. "0.0.0.0/0"
+	Gateway     string `json:"gateway,omitempty"` // empty for directly connected routes
+	Interface   string `json:"interface"`         // name on Linux, local address on Windows
+	Metric      int    `json:"metric"`
+}
+
+// CertCheckRequest asks the agent to inspect the TLS certificate served at
+// Target. The host part of Target is also sent as the SNI server name.
+type CertCheckRequest struct {
+	Target         string `json:"target"`                    // host:port
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"` // default 10
+}
+
+// CertCheckResult describes the leaf certificate presented by a TLS server.
+type CertCheckResult struct {
+	Target        string    `json:"target"`
+	Subject       string    `json:"subject"`
+	Issuer        string    `json:"issuer"`
+	DNSNames      []string  `json:"dns_names,omitempty"`
+	NotBefore     time.Time `json:"not_before"`
+	NotAfter      time.Time `json:"not_after"`
+	DaysRemaining int       `json:"days_remaining"` // negative once expired
+	Verified      bool      `json:"verified"`       // chain and hostname check against the system roots
+	VerifyError   string    `json:"verify_error,omitempty"`
+}
+
+// IOStatRequest asks the agent for an immediate disk I/O sample taken
+// over IntervalSeconds.
+type IOStatRequest struct {
+	IntervalSeconds int `json:"interval_seconds,omitempty"` // default 1
+}
+
+// IOStatResult holds per-device rates measured between two reads of the
+// kernel's disk counters.
+type IOStatResult struct {
+	IntervalSeconds float64        `json:"interval_seconds"`
+	Devices         []IOStatDevice `json:"devices"`
+}
+
+// IOStatDevice is one device's activity over an IOStatResult interval.
+type IOStatDevice struct {
+	Device           string  `json:"device"`
+	ReadIOPS         float64 `json:"read_iops"`
+	WriteIOPS        float64 `json:"write_iops"`
+	ReadBytesPerSec  float64 `json:"read_bytes_per_sec"`
+	WriteBytesPerSec float64 `json:"write_bytes_per_sec"`
+	UtilPct          float64 `json:"util_pct"` // share of the interval the device was busy
+}
+
+// Size bounds for a throughput test, per direction.
+const (
+	DefaultThroughputBytes = 8 << 20
+	MaxThroughputBytes     = 64 << 20
+)
+
+// ThroughputRequest asks the agent to measure link throughput to the
+// server by transferring Bytes in each direction.
+type ThroughputRequest struct {
+	Bytes int64 `json:"bytes,omitempty"` // default DefaultThroughputBytes, capped at MaxThroughputBytes
+}
+
+// ThroughputResult holds the rates measured by a ThroughputRequest.
+type ThroughputResult struct {
+	Bytes        int64   `json:"bytes"` // transferred in each direction
+	DownloadMbps float64 `json:"download_mbps"`
+	UploadMbps   float64 `json:"upload_mbps"`
+	DownloadMs   int64   `json:"download_ms"`
+	UploadMs     int64   `json:"upload_ms"`
+}
+
+// MTURequest asks the agent to discover the path MTU to Target by sending
+// pings with the Don't Fragment bit set.
+type MTURequest struct {
+	Target string `json:"target"` // hostname or IPv4 address
+}
+
+// MTUResult holds the outcome of an MTURequest. Supported is false when the
+// agent has no way to send DF-bit probes, in which case MTU is zero.
+type MTUResult struct {
+	Target    string `json:"target"`
+	Supported bool   `json:"supported"`
+	MTU       int    `json:"mtu,omitempty"`     // largest unfragmented IPv4 packet, headers included
+	Payload   int    `json:"payload,omitempty"` // matching ICMP payload size (MTU - 28)
+	Probes    int    `json:"probes"`
+	Message   string `json:"message,omitempty"`
+}
+
+// Sort orders for TopProcessesRequest.
+const (
+	SortByCPU    = "cpu"
+	SortByMemory = "memory"
+)
+
+// TopProcessesRequest asks the agent for its busiest processes right now.
+// The result is a ProcessListMetric.
+type TopProcessesRequest struct {
+	SortBy string `json:"sort_by,omitempty"` // SortByCPU (default) or SortByMemory
+	Limit  int    `json:"limit,omitempty"`   // default 20, max 500
+}
+
+// ProcFilesRequest asks the agent for the open file descriptors of PID.
+type ProcFilesRequest struct {
+	PID   int `json:"pid"`
+	Limit int `json:"limit,omitempty"` // default 200, max 2000
+}
+
+// ProcFilesResult lists a process's open descriptors. Count is the total
+// number open even when Files was cut to the request limit.
+type ProcFilesResult struct {
+	PID       int        `json:"pid"`
+	Count     int        `json:"count"`
+	Truncated bool       `json:"truncated,omitempty"`
+	Files     []OpenFile `json:"files"`
+}
+
+// OpenFile is one descriptor in a ProcFilesResult. Target is what the
+// descriptor points at, e.g. a path, "socket:[1234]" or "pipe:[5678]".
+type OpenFile struct {
+	FD     int    `json:"fd"`
+	Target string `json:"target,omitempty"`
+	Error  string `json:"error,omitempty"` // why Target couldn't be read
+}
+
+// MountLatencyRequest asks the agent to time a stat and a tiny
+// create-write-close on Mountpoint, or on every known mount when empty.
+type MountLatencyRequest struct {
+	Mountpoint     string `json:"mountpoint,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"` // per mount, default 5, max 30
+}
+
+// MountLatencyResult holds one probe per mount, sorted by mountpoint.
+type MountLatencyResult struct {
+	Mounts []MountLatency `json:"mounts"`
+}
+
+// MountLatency is one mount's probe. A mount that didn't answer within
+// the timeout has TimedOut set and no timings.
+type MountLatency struct {
+	Mountpoint string  `json:"mountpoint"`
+	StatMs     float64 `json:"stat_ms"`
+	WriteMs    float64 `json:"write_ms,omitempty"` // create, write one byte, close
+	TimedOut   bool    `json:"timed_out,omitempty"`
+	Error      string  `json:"error,omitempty"` // e.g. read-only or permission denied on the write
+}
+
 type HostInfo struct {
 	Hostname string `json:"hostname"`
 	OS       string `json:"os"`
@@ -174,7 +692,8 @@ type HostInfo struct {
 	BootTime    int64    `json:"boot_time"`
 	IPs         []string `json:"ips"` // List of local interface IPs
 
-	Hardware string `json:"hardware,omitempty"`
+	Hardware string   `json:"hardware,omitempty"`
+	Tags     []string `json:"tags,omitempty"` // Operator-defined tags from the agent config
 }
 
 type RegisterRequest struct {
@@ -36,6 +36,45 @@ func TestPriorityToLevel(t *testing.T) {
 	}
 }
 
+func TestLogLevel_Severity(t *testing.T) {
+	// Most severe first
+	ordered := []LogLevel{
+		LevelEmergency, LevelAlert, LevelCritical, LevelError,
+		LevelWarning, LevelNotice, LevelInfo, LevelDebug,
+	}
+
+	for i, l := range ordered {
+		if got := l.Severity(); got != i {
+			t.Errorf("%s.Severity() = %d, want %d", l, got, i)
+		}
+		if PriorityToLevel[l.Severity()] != l {
+			t.Errorf("%s does not round-trip through PriorityToLevel", l)
+		}
+	}
+
+	for _, l := range []LogLevel{"", "VERBOSE", "info"} {
+		if got := l.Severity(); got != LevelInfo.Severity() {
+			t.Errorf("%q.Severity() = %d, want info (%d)", l, got, LevelInfo.Severity())
+		}
+	}
+}
+
+func TestLogLevel_AtLeast(t *testing.T) {
+	ordered := []LogLevel{
+		LevelEmergency, LevelAlert, LevelCritical, LevelError,
+		LevelWarning, LevelNotice, LevelInfo, LevelDebug,
+	}
+
+	for i, l := range ordered {
+		for j, min := range ordered {
+			want := i <= j
+			if got := l.AtLeast(min); got != want {
+				t.Errorf("%s.AtLeast(%s) = %v, want %v", l, min, got, want)
+			}
+		}
+	}
+}
+
 func TestLogLevel_Constants(t *testing.T) {
 	// Verify log levels are uppercase strings
 	levels := map[LogLevel]string{
@@ -59,6 +98,8 @@ func TestLogLevel_Constants(t *testing.T) {
 func TestCommandType_Constants(t *testing.T) {
 	commands := []CommandType{
 		CmdFetchLogs, CmdDiskUsage, CmdRestartAgent, CmdListMounts, CmdNetworkDiag,
+		CmdUpdateAgent, CmdFollowLogs, CmdCancel, CmdCertCheck, CmdIOStat,
+		CmdTopProcesses, CmdRouteTable,
 	}
 
 	seen := make(map[CommandType]bool)
@@ -0,0 +1,383 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// checkRange reports an error if v is NaN, infinite, or outside [lo, hi].
+func checkRange(field string, v, lo, hi float64) error {
+	if math.IsNaN(v) || math.IsInf(v, 0) || v < lo || v > hi {
+		return fmt.Errorf("%s %v out of range [%v, %v]", field, v, lo, hi)
+	}
+	return nil
+}
+
+// checkPercent is checkRange for the usual 0-100 percentage.
+func checkPercent(field string, v float64) error {
+	return checkRange(field, v, 0, 100)
+}
+
+// checkNonNegative reports an error if v is NaN, infinite or negative.
+func checkNonNegative(field string, v float64) error {
+	return checkRange(field, v, 0, math.MaxFloat64)
+}
+
+// checkNotAbove reports an error if part exceeds whole (e.g. used > total).
+func checkNotAbove(partField string, part uint64, wholeField string, whole uint64) error {
+	if part > whole {
+		return fmt.Errorf("%s %d exceeds %s %d", partField, part, wholeField, whole)
+	}
+	return nil
+}
+
+func (m CPUMetric) Validate() error {
+	// Some platforms report aggregate usage summed across cores
+	cores := max(len(m.CoreUsage), 1)
+	errs := []error{
+		checkRange("usage", m.Usage, 0, 100*float64(cores)),
+		checkPercent("iowait", m.IOWait),
+		checkNonNegative("load_1m", m.LoadAvg1),
+		checkNonNegative("load_5m", m.LoadAvg5),
+		checkNonNegative("load_15m", m.LoadAvg15),
+		checkNonNegative("freq_mhz", m.FreqMHz),
+	}
+	for i, u := range m.CoreUsage {
+		errs = append(errs, checkPercent(fmt.Sprintf("cores[%d]", i), u))
+	}
+	return errors.Join(errs...)
+}
+
+func (m MemoryMetric) Validate() error {
+	return errors.Join(
+		checkNotAbove("ram_used", m.Used, "ram_total", m.Total),
+		checkNotAbove("ram_available", m.Available, "ram_total", m.Total),
+		checkNotAbove("swap_used", m.SwapUsed, "swap_total", m.SwapTotal),
+		checkPercent("ram_used_pct", m.UsedPct),
+		checkPercent("swap_pct", m.SwapPct),
+	)
+}
+
+func (m DiskMetric) Validate() error {
+	return errors.Join(
+		checkNotAbove("disk_used", m.Used, "disk_total", m.Total),
+		checkNotAbove("inodes_used", m.InodesUsed, "inodes_total", m.InodesTotal),
+		checkPercent("disk_used_pct", m.UsedPct),
+		checkPercent("inodes_pct", m.InodesPct),
+	)
+}
+
+func (m NetworkMetric) Validate() error {
+	if m.Interface == "" {
+		return errors.New("interface is required")
+	}
+	return nil
+}
+
+func (m TemperatureMetric) Validate() error {
+	// Below absolute zero or implausibly hot means a broken sensor read
+	errs := []error{checkRange("temperature", m.Temp, -273.15, 1000)}
+	if m.Max != nil {
+		errs = append(errs, checkRange("max_temp", *m.Max, -273.15, 1000))
+	}
+	return errors.Join(errs...)
+}
+
+func (m SystemMetric) Validate() error {
+	if m.Processes < 0 || m.Users < 0 {
+		return fmt.Errorf("negative count: processes %d, users %d", m.Processes, m.Users)
+	}
+	return nil
+}
+
+func (m DiskIOMetric) Validate() error {
+	if m.Device == "" {
+		return errors.New("device is required")
+	}
+	return nil
+}
+
+func (m ProcessMetric) Validate() error {
+	var errs []error
+	if m.Pid < 0 {
+		errs = append(errs, fmt.Errorf("pid %d is negative", m.Pid))
+	}
+	// CPU percent is per-core, so it can exceed 100 on multi-core hosts
+	errs = append(errs,
+		checkNonNegative("cpu_percent", m.CPUPercent),
+		checkPercent("mem_percent", m.MemPercent),
+	)
+	return errors.Join(errs...)
+}
+
+func (m ProcessListMetric) Validate() error {
+	for _, p := range m.Processes {
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("process %d: %w", p.Pid, err)
+		}
+	}
+	return nil
+}
+
+func (ThrottleMetric) Validate() error { return nil }
+func (ClockMetric) Validate() error    { return nil }
+
+func (m VoltageMetric) Validate() error {
+	return errors.Join(
+		checkNonNegative("core_volts", m.Core),
+		checkNonNegative("sdram_c_volts", m.SDRamC),
+		checkNonNegative("sdram_i_volts", m.SDRamI),
+		checkNonNegative("sdram_p_volts", m.SDRamP),
+	)
+}
+
+func (m WiFiMetric) Validate() error {
+	return errors.Join(
+		checkNonNegative("frequency_ghz", m.Frequency),
+		checkNonNegative("bitrate_mbps", m.BitRate),
+	)
+}
+
+func (m GPUMetric) Validate() error {
+	if m.MemoryTotal == 0 {
+		return nil
+	}
+	return checkNotAbove("gpu_mem_used", m.MemoryUsed, "gpu_mem_total", m.MemoryTotal)
+}
+
+func (ApplicationListMetric) Validate() error { return nil }
+func (DMIMetric) Validate() error             { return nil }
+
+func (m ContainerMetric) Validate() error {
+	if m.ID == "" {
+		return errors.New("id is required")
+	}
+	return checkNonNegative("cpu_percent", m.CPUPercent)
+}
+
+func (m ContainerListMetric) Validate() error {
+	for _, c := range m.Containers {
+		if err := c.Validate(); err != nil {
+			return fmt.Errorf("container %q: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+func (m UpdateMetric) Validate() error {
+	if m.PendingCount < 0 || m.SecurityCount < 0 {
+		return fmt.Errorf("negative count: pending %d, security %d", m.PendingCount, m.SecurityCount)
+	}
+	return nil
+}
+
+func (m CollectorHealthMetric) Validate() error {
+	if m.Name == "" {
+		return errors.New("name is required")
+	}
+	if m.ConsecutiveErrors < 0 {
+		return fmt.Errorf("consecutive_errors %d is negative", m.ConsecutiveErrors)
+	}
+	return nil
+}
+
+func (m ServiceMetric) Validate() error {
+	if m.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func (m ServiceListMetric) Validate() error {
+	for _, svc := range m.Services {
+		if err := svc.Validate(); err != nil {
+			return fmt.Errorf("service %q: %w", svc.Name, err)
+		}
+	}
+	return nil
+}
+
+func (m SensorMetric) Validate() error {
+	if m.Name == "" {
+		return errors.New("name is required")
+	}
+	if math.IsNaN(m.Value) || math.IsInf(m.Value, 0) {
+		return fmt.Errorf("value %v is not finite", m.Value)
+	}
+	return nil
+}
+
+func (m PowerDrawMetric) Validate() error {
+	if m.Domain == "" {
+		return errors.New("domain is required")
+	}
+	return checkNonNegative("watts", m.Watts)
+}
+
+func (m SchedMetric) Validate() error {
+	return errors.Join(
+		checkNonNegative("ctxt_per_sec", m.ContextSwitchesPerSec),
+		checkNonNegative("intr_per_sec", m.InterruptsPerSec),
+	)
+}
+
+func (m SwapDeviceMetric) Validate() error {
+	if m.Name == "" {
+		return errors.New("swap device name is required")
+	}
+	if m.Used > m.Size {
+		return fmt.Errorf("swap %s used %d exceeds size %d", m.Name, m.Used, m.Size)
+	}
+	return nil
+}
+
+func (m SlabMetric) Validate() error {
+	var errs []error
+	for _, c := range m.Caches {
+		if c.Name == "" {
+			errs = append(errs, errors.New("slab cache name is required"))
+		}
+		if c.ActiveObjs > c.NumObjs {
+			errs = append(errs, fmt.Errorf("slab cache %s active_objs %d exceeds num_objs %d", c.Name, c.ActiveObjs, c.NumObjs))
+		}
+		if c.Bytes > m.TotalBytes {
+			errs = append(errs, fmt.Errorf("slab cache %s bytes %d exceeds total %d", c.Name, c.Bytes, m.TotalBytes))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m EntropyMetric) Validate() error {
+	if m.Available < 0 || m.PoolSize < 0 {
+		return fmt.Errorf("negative entropy: %+v", m)
+	}
+	if m.PoolSize > 0 && m.Available > m.PoolSize {
+		return fmt.Errorf("entropy_avail %d exceeds pool_size %d", m.Available, m.PoolSize)
+	}
+	return nil
+}
+
+func (m ProcessSummaryMetric) Validate() error {
+	if m.Total < 0 || m.Running < 0 || m.Sleeping < 0 || m.Stopped < 0 || m.Zombie < 0 || m.Threads < 0 {
+		return fmt.Errorf("negative count in process summary: %+v", m)
+	}
+	if m.Running+m.Sleeping+m.Stopped+m.Zombie > m.Total {
+		return fmt.Errorf("state counts exceed total %d", m.Total)
+	}
+	return nil
+}
+
+func (m FailedUnitMetric) Validate() error {
+	if m.Unit == "" {
+		return errors.New("unit is required")
+	}
+	return nil
+}
+
+func (m FailedUnitListMetric) Validate() error {
+	for _, u := range m.Units {
+		if err := u.Validate(); err != nil {
+			return fmt.Errorf("failed unit %q: %w", u.Unit, err)
+		}
+	}
+	return nil
+}
+
+func (m TimerMetric) Validate() error {
+	if m.Unit == "" {
+		return errors.New("unit is required")
+	}
+	return nil
+}
+
+func (m TimerListMetric) Validate() error {
+	for _, t := range m.Timers {
+		if err := t.Validate(); err != nil {
+			return fmt.Errorf("timer %q: %w", t.Unit, err)
+		}
+	}
+	return nil
+}
+
+func (m WiFiScanMetric) Validate() error {
+	for _, n := range m.Networks {
+		if n.BSSID == "" {
+			return fmt.Errorf("network %q: bssid is required", n.SSID)
+		}
+		if err := checkNonNegative("frequency_ghz", n.Frequency); err != nil {
+			return fmt.Errorf("network %s: %w", n.BSSID, err)
+		}
+	}
+	return nil
+}
+
+func (m ARPTableMetric) Validate() error {
+	for _, e := range m.Entries {
+		if e.IP == "" {
+			return errors.New("arp entry: ip is required")
+		}
+		if e.MAC == "" && e.State != ARPIncomplete {
+			return fmt.Errorf("arp entry %s: mac is required when %s", e.IP, e.State)
+		}
+	}
+	return nil
+}
+
+func (m SocketStatMetric) Validate() error {
+	if m.Used < 0 || m.TCPInUse < 0 || m.TCP6InUse < 0 || m.TCPOrphan < 0 ||
+		m.TCPTimeWait < 0 || m.TCPAlloc < 0 || m.UDPInUse < 0 || m.UDP6InUse < 0 {
+		return fmt.Errorf("negative count in socket stats: %+v", m)
+	}
+	return nil
+}
+
+func (m TCPStatMetric) Validate() error {
+	return errors.Join(
+		checkNonNegative("retrans_per_sec", m.RetransPerSec),
+		checkNonNegative("retrans_pct", m.RetransPct),
+		checkNonNegative("in_errs_per_sec", m.InErrsPerSec),
+		checkNonNegative("out_rsts_per_sec", m.OutRstsPerSec),
+		checkNonNegative("timeouts_per_sec", m.TimeoutsPerSec),
+		checkNonNegative("listen_drops_per_sec", m.ListenDropsPerSec),
+	)
+}
+
+func (m USBDeviceMetric) Validate() error {
+	if m.VendorID == "" || m.ProductID == "" {
+		return errors.New("vendor_id and product_id are required")
+	}
+	return nil
+}
+
+func (m USBDeviceListMetric) Validate() error {
+	for _, d := range m.Devices {
+		if err := d.Validate(); err != nil {
+			return fmt.Errorf("usb device %q: %w", d.Port, err)
+		}
+	}
+	return nil
+}
+
+func (m GPUProcessMetric) Validate() error {
+	if m.PID <= 0 {
+		return fmt.Errorf("gpu process pid %d must be positive", m.PID)
+	}
+	return nil
+}
+
+func (m PCIDeviceMetric) Validate() error {
+	if m.VendorID == "" || m.DeviceID == "" {
+		return errors.New("vendor_id and device_id are required")
+	}
+	return nil
+}
+
+func (m PCIDeviceListMetric) Validate() error {
+	for _, d := range m.Devices {
+		if err := d.Validate(); err != nil {
+			return fmt.Errorf("pci device %q: %w", d.Slot, err)
+		}
+	}
+	return nil
+}
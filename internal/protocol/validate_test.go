@@ -0,0 +1,123 @@
+package protocol
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	maxTemp := 2000.0
+
+	tests := []struct {
+		name    string
+		metric  Metric
+		wantErr bool
+	}{
+		{"cpu valid", CPUMetric{Usage: 55, CoreUsage: []float64{50, 60}, IOWait: 1, LoadAvg1: 0.7}, false},
+		{"cpu summed across cores", CPUMetric{Usage: 180, CoreUsage: []float64{90, 90}}, false},
+		{"cpu negative usage", CPUMetric{Usage: -1}, true},
+		{"cpu usage above cores", CPUMetric{Usage: 250, CoreUsage: []float64{100, 100}}, true},
+		{"cpu core over 100", CPUMetric{Usage: 50, CoreUsage: []float64{50, 101}}, true},
+		{"cpu NaN", CPUMetric{Usage: math.NaN()}, true},
+		{"cpu negative load", CPUMetric{LoadAvg5: -0.1}, true},
+
+		{"memory valid", MemoryMetric{Total: 100, Used: 40, Available: 60, UsedPct: 40}, false},
+		{"memory used over total", MemoryMetric{Total: 100, Used: 150}, true},
+		{"memory swap used over total", MemoryMetric{Total: 100, SwapTotal: 10, SwapUsed: 11}, true},
+		{"memory pct over 100", MemoryMetric{Total: 100, UsedPct: 120}, true},
+
+		{"disk valid", DiskMetric{Total: 100, Used: 90, UsedPct: 90}, false},
+		{"disk used over total", DiskMetric{Total: 100, Used: 101}, true},
+		{"disk inodes used over total", DiskMetric{Total: 100, InodesTotal: 5, InodesUsed: 6}, true},
+
+		{"network valid", NetworkMetric{Interface: "eth0"}, false},
+		{"network no interface", NetworkMetric{}, true},
+
+		{"temperature valid", TemperatureMetric{Sensor: "cpu", Temp: 45}, false},
+		{"temperature below absolute zero", TemperatureMetric{Temp: -300}, true},
+		{"temperature bad max", TemperatureMetric{Temp: 40, Max: &maxTemp}, true},
+
+		{"system valid", SystemMetric{Processes: 120, Users: 2}, false},
+		{"system negative processes", SystemMetric{Processes: -1}, true},
+
+		{"disk_io valid", DiskIOMetric{Device: "sda"}, false},
+		{"disk_io no device", DiskIOMetric{}, true},
+
+		{"process valid multi-core", ProcessMetric{Pid: 1, CPUPercent: 350, MemPercent: 10}, false},
+		{"process negative pid", ProcessMetric{Pid: -1}, true},
+		{"process mem over 100", ProcessMetric{Pid: 1, MemPercent: 101}, true},
+		{"process_list valid", ProcessListMetric{Processes: []ProcessMetric{{Pid: 1}}}, false},
+		{"process_list bad entry", ProcessListMetric{Processes: []ProcessMetric{{Pid: 1}, {Pid: 2, CPUPercent: -5}}}, true},
+
+		{"voltage valid", VoltageMetric{Core: 0.85}, false},
+		{"voltage negative", VoltageMetric{Core: -1}, true},
+
+		{"gpu valid", GPUMetric{MemoryTotal: 100, MemoryUsed: 50}, false},
+		{"gpu unknown total", GPUMetric{MemoryUsed: 50}, false},
+		{"gpu used over total", GPUMetric{MemoryTotal: 100, MemoryUsed: 200}, true},
+		{"gpu_process valid", GPUProcessMetric{PID: 4123, Name: "python3", UsedMemory: 1 << 30}, false},
+		{"gpu_process no pid", GPUProcessMetric{Name: "python3"}, true},
+
+		{"container valid", ContainerMetric{ID: "abc", CPUPercent: 12}, false},
+		{"container no id", ContainerMetric{Name: "web"}, true},
+		{"container_list bad entry", ContainerListMetric{Containers: []ContainerMetric{{ID: "abc", CPUPercent: -1}}}, true},
+
+		{"updates valid", UpdateMetric{PendingCount: 3, SecurityCount: 1}, false},
+		{"updates negative", UpdateMetric{PendingCount: -1}, true},
+
+		{"collector_health valid", CollectorHealthMetric{Name: "wifi", ConsecutiveErrors: 2}, false},
+		{"collector_health no name", CollectorHealthMetric{}, true},
+
+		{"service valid", ServiceMetric{Name: "nginx"}, false},
+		{"service_list bad entry", ServiceListMetric{Services: []ServiceMetric{{Name: ""}}}, true},
+		{"usb_device_list ok", USBDeviceListMetric{Devices: []USBDeviceMetric{{Port: "1-1", VendorID: "046d", ProductID: "c52b"}}}, false},
+		{"pci_device_list ok", PCIDeviceListMetric{Devices: []PCIDeviceMetric{{Slot: "00:02.0", VendorID: "8086", DeviceID: "5916"}}}, false},
+		{"pci_device_list missing ids", PCIDeviceListMetric{Devices: []PCIDeviceMetric{{Slot: "00:02.0"}}}, true},
+		{"wifi_scan ok", WiFiScanMetric{Networks: []WiFiNetwork{{BSSID: "11:22:33:44:55:66", Frequency: 5.18}}}, false},
+		{"wifi_scan missing bssid", WiFiScanMetric{Networks: []WiFiNetwork{{SSID: "HomeNet"}}}, true},
+		{"usb_device_list missing ids", USBDeviceListMetric{Devices: []USBDeviceMetric{{Port: "1-1"}}}, true},
+
+		{"throttle", ThrottleMetric{Throttled: true}, false},
+		{"clock", ClockMetric{ArmFreq: 1500000000}, false},
+		{"application_list", ApplicationListMetric{}, false},
+		{"sensor ok", SensorMetric{Source: "ipmi", Name: "Fan1A RPM", Kind: "fan", Value: 4200, Unit: "RPM"}, false},
+		{"sensor missing name", SensorMetric{Value: 1}, true},
+		{"sensor nan", SensorMetric{Name: "Temp", Value: math.NaN()}, true},
+		{"power_draw ok", PowerDrawMetric{Domain: "package-0", Watts: 15.2}, false},
+		{"power_draw missing domain", PowerDrawMetric{Watts: 1}, true},
+		{"power_draw negative", PowerDrawMetric{Domain: "package-0", Watts: -1}, true},
+		{"sched ok", SchedMetric{ContextSwitchesPerSec: 12000, InterruptsPerSec: 4000}, false},
+		{"sched negative", SchedMetric{ContextSwitchesPerSec: -1}, true},
+		{"swap_device ok", SwapDeviceMetric{Name: "/swapfile", Type: "file", Size: 1 << 30, Used: 1 << 20}, false},
+		{"swap_device no name", SwapDeviceMetric{Size: 1}, true},
+		{"swap_device overfull", SwapDeviceMetric{Name: "/dev/sda2", Size: 1, Used: 2}, true},
+		{"slab ok", SlabMetric{TotalBytes: 1 << 20, Caches: []SlabCache{{Name: "dentry", ActiveObjs: 10, NumObjs: 20, ObjSize: 192, Bytes: 4096}}}, false},
+		{"slab cache exceeds total", SlabMetric{TotalBytes: 1, Caches: []SlabCache{{Name: "dentry", Bytes: 4096}}}, true},
+		{"slab cache unnamed", SlabMetric{TotalBytes: 4096, Caches: []SlabCache{{Bytes: 4096}}}, true},
+		{"arp_table ok", ARPTableMetric{Entries: []ARPEntry{{IP: "192.168.1.1", MAC: "aa:bb:cc:dd:ee:ff", Device: "eth0", State: ARPComplete}, {IP: "192.168.1.50", Device: "eth0", State: ARPIncomplete}}}, false},
+		{"arp_table complete without mac", ARPTableMetric{Entries: []ARPEntry{{IP: "192.168.1.1", Device: "eth0", State: ARPComplete}}}, true},
+		{"socket_stat ok", SocketStatMetric{Used: 290, TCPInUse: 5, TCP6InUse: 3, TCPTimeWait: 2, TCPAlloc: 7, UDPInUse: 3}, false},
+		{"socket_stat negative", SocketStatMetric{TCPOrphan: -1}, true},
+		{"tcp_stat ok", TCPStatMetric{RetransPerSec: 2, RetransPct: 0.5, OutRstsPerSec: 1}, false},
+		{"tcp_stat negative", TCPStatMetric{InErrsPerSec: -1}, true},
+		{"entropy ok", EntropyMetric{Available: 256, PoolSize: 256}, false},
+		{"entropy without pool size", EntropyMetric{Available: 3000}, false},
+		{"entropy exceeds pool", EntropyMetric{Available: 300, PoolSize: 256}, true},
+		{"process_summary ok", ProcessSummaryMetric{Total: 10, Running: 1, Sleeping: 8, Zombie: 1, Threads: 40}, false},
+		{"process_summary states exceed total", ProcessSummaryMetric{Total: 1, Running: 1, Zombie: 1}, true},
+		{"failed_unit_list empty", FailedUnitListMetric{}, false},
+		{"failed_unit_list missing unit", FailedUnitListMetric{Units: []FailedUnitMetric{{ActiveState: "failed"}}}, true},
+		{"timer_list ok", TimerListMetric{Timers: []TimerMetric{{Unit: "logrotate.timer"}}}, false},
+		{"timer_list missing unit", TimerListMetric{Timers: []TimerMetric{{Activates: "logrotate.service"}}}, true},
+		{"dmi", DMIMetric{SysVendor: "LENOVO"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.metric.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
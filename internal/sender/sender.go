@@ -4,24 +4,44 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/nhdewitt/spectra/internal/logging"
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
 type Sender struct {
+	// Logger receives send failures. New sets it to the slog default;
+	// replace it before Run to route them elsewhere.
+	Logger *logging.Logger
+
 	endpoint string
 	in       <-chan protocol.Envelope
 	client   *http.Client
-	batch    []protocol.Envelope
 	maxBatch int
 	flush    time.Duration
+
+	mu    sync.Mutex // guards batch; Flush may run on any goroutine
+	batch []protocol.Envelope
+
+	// sizeEst is a moving average of recent payload sizes in bytes, used
+	// to size the encode buffer up front instead of growing it while
+	// encoding
+	sizeEst atomic.Int64
+}
+
+// bufPool holds encode buffers between sends.
+var bufPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
 }
 
 func New(endpoint string, in <-chan protocol.Envelope) *Sender {
 	return &Sender{
+		Logger:   logging.FromHandler(slog.Default().Handler()),
 		endpoint: endpoint,
 		in:       in,
 		client:   &http.Client{Timeout: 10 * time.Second},
@@ -41,42 +61,103 @@ func (s *Sender) Run(ctx context.Context) {
 			s.sendBatch()
 			return
 		case m := <-s.in:
-			s.batch = append(s.batch, m)
-			if len(s.batch) >= s.maxBatch {
+			if s.enqueue(m) {
 				s.sendBatch()
 			}
 		case <-ticker.C:
-			if len(s.batch) > 0 {
-				s.sendBatch()
-			}
+			s.sendBatch()
 		}
 	}
 }
 
-func (s *Sender) sendBatch() {
+// Flush sends whatever is batched now. It is safe to call concurrently
+// with Run.
+func (s *Sender) Flush() {
+	s.sendBatch()
+}
+
+// enqueue adds m to the batch and reports whether the batch is full.
+func (s *Sender) enqueue(m protocol.Envelope) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batch = append(s.batch, m)
+	return len(s.batch) >= s.maxBatch
+}
+
+// takeBatch detaches the pending batch so it can be sent without holding
+// mu. It returns nil when nothing is pending.
+func (s *Sender) takeBatch() []protocol.Envelope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if len(s.batch) == 0 {
+		return nil
+	}
+	batch := s.batch
+	s.batch = make([]protocol.Envelope, 0, s.maxBatch)
+	return batch
+}
+
+// sendBatch posts the pending batch. The batch is dropped on failure.
+func (s *Sender) sendBatch() {
+	batch := s.takeBatch()
+	if batch == nil {
 		return
 	}
 
-	defer func() {
-		s.batch = s.batch[:0]
-	}()
+	buf := bufPool.Get().(*bytes.Buffer)
+	defer bufPool.Put(buf)
 
-	data, err := json.Marshal(s.batch)
-	if err != nil {
-		log.Printf("error marshalling json: %v", err)
+	if err := s.encodeBatch(buf, batch); err != nil {
+		s.Logger.Error("encoding metrics batch failed", "error", err, "batch_size", len(batch))
 		return
 	}
 
-	resp, err := s.client.Post(s.endpoint, "application/json", bytes.NewReader(data))
+	resp, err := s.client.Post(s.endpoint, "application/json", bytes.NewReader(buf.Bytes()))
 	if err != nil {
-		log.Printf("error posting: %v", err)
+		s.Logger.Warn("posting metrics batch failed", "error", err, "batch_size", len(batch))
 		return
 	}
 	resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		log.Printf("server returned non-success status code %d for batch of %d metrics", resp.StatusCode, len(s.batch))
+		s.Logger.Warn("server rejected metrics batch", "status", resp.StatusCode, "batch_size", len(batch))
 		return
 	}
 }
+
+// encodeBatch writes batch to buf as json.Marshal would, first growing buf
+// to the expected payload size, and folds the actual size into the
+// estimate.
+func (s *Sender) encodeBatch(buf *bytes.Buffer, batch []protocol.Envelope) error {
+	buf.Reset()
+	if est := s.sizeEst.Load(); est > 0 {
+		// Headroom so a slightly larger batch doesn't double the buffer
+		buf.Grow(int(est + est/8))
+	}
+
+	if err := json.NewEncoder(buf).Encode(batch); err != nil {
+		return err
+	}
+	// Encode ends with a newline that json.Marshal doesn't
+	buf.Truncate(buf.Len() - 1)
+
+	s.observeSize(int64(buf.Len()))
+	return nil
+}
+
+// observeSize moves sizeEst a quarter of the way toward n. The first
+// sample is taken as is.
+func (s *Sender) observeSize(n int64) {
+	for {
+		old := s.sizeEst.Load()
+		est := n
+		if old > 0 {
+			est = old + (n-old)/4
+		}
+		if s.sizeEst.CompareAndSwap(old, est) {
+			return
+		}
+	}
+}
@@ -5,14 +5,17 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"log/slog"
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/nhdewitt/spectra/internal/logging"
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
@@ -287,6 +290,8 @@ func TestSender_SendBatch_ServerError(t *testing.T) {
 
 	ch := make(chan protocol.Envelope)
 	s := New(server.URL, ch)
+	var logs bytes.Buffer
+	s.Logger = logging.FromHandler(slog.NewTextHandler(&logs, nil))
 
 	s.batch = append(s.batch, randomEnvelope())
 	s.sendBatch()
@@ -294,11 +299,15 @@ func TestSender_SendBatch_ServerError(t *testing.T) {
 	if len(s.batch) != 0 {
 		t.Errorf("batch should be cleared even on error, got %d items", len(s.batch))
 	}
+	if out := logs.String(); !strings.Contains(out, "server rejected metrics batch") || !strings.Contains(out, "status=500") {
+		t.Errorf("expected the rejection on the injected logger, got %q", out)
+	}
 }
 
 func TestSender_SendBatch_ConnectionError(t *testing.T) {
 	ch := make(chan protocol.Envelope)
 	s := New("http://localhost:59999", ch)
+	s.Logger = logging.NewDiscard()
 
 	s.batch = append(s.batch, randomEnvelope())
 	s.sendBatch()
@@ -442,6 +451,62 @@ func TestSender_Run_ContextCancel(t *testing.T) {
 	}
 }
 
+// Run with go test -race: Flush from other goroutines while Run enqueues.
+func TestSender_ConcurrentFlushAndEnqueue(t *testing.T) {
+	var received atomic.Int64
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&batch); err == nil {
+			received.Add(int64(len(batch)))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	const total = 200
+
+	ch := make(chan protocol.Envelope)
+	s := New(server.URL, ch)
+	s.maxBatch = 7
+	s.flush = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for {
+				select {
+				case <-stop:
+					return
+				case <-time.After(100 * time.Microsecond):
+					s.Flush()
+				}
+			}
+		})
+	}
+
+	for range total {
+		ch <- randomEnvelope()
+	}
+
+	close(stop)
+	wg.Wait()
+	cancel()
+	<-done
+
+	if got := received.Load(); got != total {
+		t.Errorf("server received %d envelopes, want %d", got, total)
+	}
+}
+
 func TestSender_Run_EmptyOnCancel(t *testing.T) {
 	var requestCount int32
 
@@ -502,6 +567,63 @@ func TestSender_BatchClearing(t *testing.T) {
 	}
 }
 
+func TestSender_EncodeBatch_MatchesMarshal(t *testing.T) {
+	s := New("http://localhost", make(chan protocol.Envelope))
+	var buf bytes.Buffer
+
+	for i := range 3 {
+		batch := makeMixedBatch(20)
+		want, err := json.Marshal(batch)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.encodeBatch(&buf, batch); err != nil {
+			t.Fatalf("encodeBatch: %v", err)
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Fatalf("round %d: payload differs from json.Marshal\n got %s\nwant %s", i, buf.Bytes(), want)
+		}
+	}
+}
+
+func TestSender_ObserveSize(t *testing.T) {
+	s := New("http://localhost", make(chan protocol.Envelope))
+
+	s.observeSize(1000)
+	if got := s.sizeEst.Load(); got != 1000 {
+		t.Errorf("first sample: got %d, want 1000", got)
+	}
+	s.observeSize(2000)
+	if got := s.sizeEst.Load(); got != 1250 {
+		t.Errorf("after 2000: got %d, want 1250", got)
+	}
+	s.observeSize(250)
+	if got := s.sizeEst.Load(); got != 1000 {
+		t.Errorf("after 250: got %d, want 1000", got)
+	}
+}
+
+func TestSender_EncodeBatch_PresizesBuffer(t *testing.T) {
+	s := New("http://localhost", make(chan protocol.Envelope))
+	batch := makeMixedBatch(50)
+
+	var warm bytes.Buffer
+	if err := s.encodeBatch(&warm, batch); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh buffer, as after the pool was emptied, is grown once up
+	// front and has room for the same payload
+	var buf bytes.Buffer
+	if err := s.encodeBatch(&buf, batch); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Cap() < int(s.sizeEst.Load()) {
+		t.Errorf("buffer capacity %d below estimate %d", buf.Cap(), s.sizeEst.Load())
+	}
+}
+
 func BenchmarkMarshalMixedBatch_10(b *testing.B) {
 	batch := makeMixedBatch(10)
 
@@ -685,3 +807,18 @@ func BenchmarkSendBatch_Large(b *testing.B) {
 		s.sendBatch()
 	}
 }
+
+// BenchmarkEncodeBatch_50 is the steady-state counterpart of
+// BenchmarkMarshalMixedBatch_50: the payload goes into a pooled buffer
+// already sized from earlier sends instead of a fresh slice.
+func BenchmarkEncodeBatch_50(b *testing.B) {
+	s := New("http://localhost", make(chan protocol.Envelope))
+	batch := makeMixedBatch(50)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		buf := bufPool.Get().(*bytes.Buffer)
+		_ = s.encodeBatch(buf, batch)
+		bufPool.Put(buf)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,20 +20,37 @@ func (s *Server) handleAdminTriggerLogs(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	level := protocol.LogLevel(r.URL.Query().Get("level"))
-	if !isValidLogLevel(level) {
-		level = protocol.LevelWarning
+	req := protocol.LogRequest{MinLevel: protocol.LogLevel(r.URL.Query().Get("level"))}
+	if b := r.URL.Query().Get("boot"); b != "" {
+		boot, err := strconv.Atoi(b)
+		if err != nil {
+			http.Error(w, "invalid boot selector", http.StatusBadRequest)
+			return
+		}
+		req.Boot = &boot
+	}
+	if mb := r.URL.Query().Get("max_bytes"); mb != "" {
+		maxBytes, err := strconv.Atoi(mb)
+		if err != nil {
+			http.Error(w, "invalid max_bytes", http.StatusBadRequest)
+			return
+		}
+		req.MaxBytes = maxBytes
+	}
+	req.Dedup = r.URL.Query().Get("dedup") == "true"
+	if err := checkLogRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	req := protocol.LogRequest{MinLevel: level}
 	payload, err := json.Marshal(req)
 	if err != nil {
-		s.Logger.Error("json marshaling failed", "error", err, "handler", "handleAdminTriggerLogs")
+		s.Logger.ErrorContext(r.Context(), "json marshaling failed", "error", err, "handler", "handleAdminTriggerLogs")
 		http.Error(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	s.queueHelper(w, agentID, protocol.CmdFetchLogs, payload, "Queued FetchLogs")
+	s.queueHelper(w, r, agentID, protocol.CmdFetchLogs, payload, "Queued FetchLogs")
 }
 
 func isValidLogLevel(l protocol.LogLevel) bool {
@@ -49,23 +68,20 @@ func (s *Server) handleAdminTriggerDisk(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	path := r.URL.Query().Get("path")
-	topN := 20
+	req := protocol.DiskUsageRequest{Path: r.URL.Query().Get("path")}
 	if val := r.URL.Query().Get("top_n"); val != "" {
-		if n, err := strconv.Atoi(val); err == nil && n > 0 {
-			topN = n
-		}
+		req.TopN, _ = strconv.Atoi(val)
 	}
+	checkDiskUsageRequest(&req)
 
-	req := protocol.DiskUsageRequest{Path: path, TopN: topN}
 	payload, err := json.Marshal(req)
 	if err != nil {
-		s.Logger.Error("json marshaling failed", "error", err, "handler", "handleAdminTriggerDisk")
+		s.Logger.ErrorContext(r.Context(), "json marshaling failed", "error", err, "handler", "handleAdminTriggerDisk")
 		http.Error(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	s.queueHelper(w, agentID, protocol.CmdDiskUsage, payload, fmt.Sprintf("Queued Disk Scan (Top %d)", topN))
+	s.queueHelper(w, r, agentID, protocol.CmdDiskUsage, payload, fmt.Sprintf("Queued Disk Scan (Top %d)", req.TopN))
 }
 
 func (s *Server) handleAdminTriggerNetwork(w http.ResponseWriter, r *http.Request) {
@@ -74,30 +90,305 @@ func (s *Server) handleAdminTriggerNetwork(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	action := r.URL.Query().Get("action")
+	req := protocol.NetworkRequest{
+		Action: r.URL.Query().Get("action"),
+		Target: r.URL.Query().Get("target"),
+	}
+	if err := checkNetworkRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		s.Logger.ErrorContext(r.Context(), "json marshaling failed", "error", err, "handler", "handleAdminTriggerNetwork")
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	s.queueHelper(w, r, agentID, protocol.CmdNetworkDiag, payload, fmt.Sprintf("Queued Network Diag: %s", req.Action))
+}
+
+// maxCertDialSeconds matches the agent's cap on the TLS dial timeout.
+const maxCertDialSeconds = 30
+
+func (s *Server) handleAdminTriggerCertCheck(w http.ResponseWriter, r *http.Request) {
+	agentID, ok := s.getTargetAgent(w, r)
+	if !ok {
+		return
+	}
+
+	// ?timeout= is the command deadline (see queueHelper); the dial
+	// timeout has its own parameter so the two can be set independently.
+	req := protocol.CertCheckRequest{Target: r.URL.Query().Get("target")}
+	if v := r.URL.Query().Get("dial_timeout"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs <= 0 {
+			http.Error(w, fmt.Sprintf("dial_timeout must be 1-%d seconds", maxCertDialSeconds), http.StatusBadRequest)
+			return
+		}
+		req.TimeoutSeconds = secs
+	}
+	if err := checkCertCheckRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		s.Logger.ErrorContext(r.Context(), "json marshaling failed", "error", err, "handler", "handleAdminTriggerCertCheck")
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	s.queueHelper(w, r, agentID, protocol.CmdCertCheck, payload, fmt.Sprintf("Queued Cert Check: %s", req.Target))
+}
+
+func (s *Server) handleAdminTriggerRouteTable(w http.ResponseWriter, r *http.Request) {
+	agentID, ok := s.getTargetAgent(w, r)
+	if !ok {
+		return
+	}
+
+	s.queueHelper(w, r, agentID, protocol.CmdRouteTable, nil, "Queued Route Table")
+}
+
+// maxIOStatSeconds matches the agent's cap on the iostat sample interval.
+const maxIOStatSeconds = 10
+
+func (s *Server) handleAdminTriggerIOStat(w http.ResponseWriter, r *http.Request) {
+	agentID, ok := s.getTargetAgent(w, r)
+	if !ok {
+		return
+	}
+
+	var req protocol.IOStatRequest
+	if v := r.URL.Query().Get("interval"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs <= 0 {
+			http.Error(w, fmt.Sprintf("interval must be 1-%d seconds", maxIOStatSeconds), http.StatusBadRequest)
+			return
+		}
+		req.IntervalSeconds = secs
+	}
+	if err := checkIOStatRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		s.Logger.ErrorContext(r.Context(), "json marshaling failed", "error", err, "handler", "handleAdminTriggerIOStat")
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	s.queueHelper(w, r, agentID, protocol.CmdIOStat, payload, "Queued IO Stat")
+}
+
+// maxMountProbeSeconds matches the agent's cap on the per-mount timeout.
+const maxMountProbeSeconds = 30
+
+func (s *Server) handleAdminTriggerMountLatency(w http.ResponseWriter, r *http.Request) {
+	agentID, ok := s.getTargetAgent(w, r)
+	if !ok {
+		return
+	}
+
+	req := protocol.MountLatencyRequest{Mountpoint: r.URL.Query().Get("mountpoint")}
+	if v := r.URL.Query().Get("probe_timeout"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs <= 0 || secs > maxMountProbeSeconds {
+			http.Error(w, fmt.Sprintf("probe_timeout must be 1-%d seconds", maxMountProbeSeconds), http.StatusBadRequest)
+			return
+		}
+		req.TimeoutSeconds = secs
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		s.Logger.ErrorContext(r.Context(), "json marshaling failed", "error", err, "handler", "handleAdminTriggerMountLatency")
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	s.queueHelper(w, r, agentID, protocol.CmdMountLatency, payload, "Queued Mount Latency")
+}
+
+func (s *Server) handleAdminTriggerMTUProbe(w http.ResponseWriter, r *http.Request) {
+	agentID, ok := s.getTargetAgent(w, r)
+	if !ok {
+		return
+	}
+
 	target := r.URL.Query().Get("target")
+	if target == "" {
+		http.Error(w, "target required", http.StatusBadRequest)
+		return
+	}
+
+	payload, err := json.Marshal(protocol.MTURequest{Target: target})
+	if err != nil {
+		s.Logger.ErrorContext(r.Context(), "json marshaling failed", "error", err, "handler", "handleAdminTriggerMTUProbe")
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	s.queueHelper(w, r, agentID, protocol.CmdMTUProbe, payload, "Queued MTU Probe")
+}
 
-	if action == "" {
-		http.Error(w, "Action required", http.StatusBadRequest)
+func (s *Server) handleAdminTriggerThroughput(w http.ResponseWriter, r *http.Request) {
+	agentID, ok := s.getTargetAgent(w, r)
+	if !ok {
 		return
 	}
 
-	req := protocol.NetworkRequest{Action: action, Target: target}
+	var req protocol.ThroughputRequest
+	if v := r.URL.Query().Get("bytes"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 || n > protocol.MaxThroughputBytes {
+			http.Error(w, fmt.Sprintf("bytes must be 1-%d", protocol.MaxThroughputBytes), http.StatusBadRequest)
+			return
+		}
+		req.Bytes = n
+	}
+
 	payload, err := json.Marshal(req)
 	if err != nil {
-		s.Logger.Error("json marshaling failed", "error", err, "handler", "handleAdminTriggerNetwork")
+		s.Logger.ErrorContext(r.Context(), "json marshaling failed", "error", err, "handler", "handleAdminTriggerThroughput")
 		http.Error(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	s.queueHelper(w, agentID, protocol.CmdNetworkDiag, payload, fmt.Sprintf("Queued Network Diag: %s", action))
+	s.queueHelper(w, r, agentID, protocol.CmdThroughput, payload, "Queued Throughput Test")
+}
+
+func (s *Server) handleAdminTriggerTopProcesses(w http.ResponseWriter, r *http.Request) {
+	agentID, ok := s.getTargetAgent(w, r)
+	if !ok {
+		return
+	}
+
+	req := protocol.TopProcessesRequest{SortBy: r.URL.Query().Get("sort")}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		req.Limit = n
+	}
+	if err := checkTopProcessesRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		s.Logger.ErrorContext(r.Context(), "json marshaling failed", "error", err, "handler", "handleAdminTriggerTopProcesses")
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	s.queueHelper(w, r, agentID, protocol.CmdTopProcesses, payload, "Queued Top Processes")
+}
+
+func (s *Server) handleAdminTriggerProcFiles(w http.ResponseWriter, r *http.Request) {
+	agentID, ok := s.getTargetAgent(w, r)
+	if !ok {
+		return
+	}
+
+	var req protocol.ProcFilesRequest
+	pid, err := strconv.Atoi(r.URL.Query().Get("pid"))
+	if err != nil || pid <= 0 {
+		http.Error(w, "pid required", http.StatusBadRequest)
+		return
+	}
+	req.PID = pid
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		req.Limit = n
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		s.Logger.ErrorContext(r.Context(), "json marshaling failed", "error", err, "handler", "handleAdminTriggerProcFiles")
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	s.queueHelper(w, r, agentID, protocol.CmdProcFiles, payload, fmt.Sprintf("Queued Open Files: pid %d", pid))
+}
+
+// maxFollowSeconds matches the agent's cap on how long logs are followed.
+const maxFollowSeconds = 300
+
+func (s *Server) handleAdminTriggerFollowLogs(w http.ResponseWriter, r *http.Request) {
+	agentID, ok := s.getTargetAgent(w, r)
+	if !ok {
+		return
+	}
+
+	level := protocol.LogLevel(r.URL.Query().Get("level"))
+	if !isValidLogLevel(level) {
+		level = protocol.LevelWarning
+	}
+
+	req := protocol.FollowLogsRequest{MinLevel: level}
+	if d := r.URL.Query().Get("duration"); d != "" {
+		secs, err := strconv.Atoi(d)
+		if err != nil || secs <= 0 || secs > maxFollowSeconds {
+			http.Error(w, fmt.Sprintf("duration must be 1-%d seconds", maxFollowSeconds), http.StatusBadRequest)
+			return
+		}
+		req.DurationSeconds = secs
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		s.Logger.ErrorContext(r.Context(), "json marshaling failed", "error", err, "handler", "handleAdminTriggerFollowLogs")
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	s.queueHelper(w, r, agentID, protocol.CmdFollowLogs, payload, "Queued FollowLogs")
+}
+
+// handleCancelCommand asks the agent running a streaming command to stop it.
+//
+// POST /api/v1/admin/commands/{id}/cancel
+func (s *Server) handleCancelCommand(w http.ResponseWriter, r *http.Request) {
+	cmdID := r.PathValue("id")
+	entry, ok := s.Commands.Get(cmdID)
+	if !ok {
+		http.Error(w, "command not found", http.StatusNotFound)
+		return
+	}
+	if entry.Done {
+		http.Error(w, "command already finished", http.StatusConflict)
+		return
+	}
+
+	payload, err := json.Marshal(protocol.CancelRequest{CommandID: cmdID})
+	if err != nil {
+		s.Logger.ErrorContext(r.Context(), "json marshaling failed", "error", err, "handler", "handleCancelCommand")
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	s.queueHelper(w, r, entry.AgentID, protocol.CmdCancel, payload, "Queued Cancel")
 }
 
 func (s *Server) handleGenerateToken(w http.ResponseWriter, r *http.Request) {
 	token := s.Tokens.Generate(24 * time.Hour)
-	s.Logger.Info("registration token generated", "expires_in", "24h")
+	s.Logger.InfoContext(r.Context(), "registration token generated", "expires_in", "24h")
 
-	respondJSON(w, http.StatusCreated, map[string]string{
+	s.respondJSON(w, http.StatusCreated, map[string]string{
 		"token": token,
 	})
 }
@@ -136,21 +427,21 @@ func (s *Server) handlePushUpdate(w http.ResponseWriter, r *http.Request) {
 	for _, id := range req.AgentIDs {
 		agent, err := s.DB.GetAgent(ctx, mustUUID(id))
 		if err != nil {
-			s.Logger.Warn("update: agent not found", "agent_id", id)
+			s.Logger.WarnContext(r.Context(), "update: agent not found", "agent_id", id)
 			failed++
 			continue
 		}
 
 		filename := agentBinaryFilename(agent.Os.String, agent.Arch.String)
 		if filename == "" {
-			s.Logger.Warn("update: unknown platform", "agent_id", id, "os", agent.Os, "arch", agent.Arch)
+			s.Logger.WarnContext(r.Context(), "update: unknown platform", "agent_id", id, "os", agent.Os, "arch", agent.Arch)
 			skipped++
 			continue
 		}
 
 		sha256, ok := s.Releases.get(filename)
 		if !ok {
-			s.Logger.Warn("update: no binary for platform", "agent_id", id, "filename", filename)
+			s.Logger.WarnContext(r.Context(), "update: no binary for platform", "agent_id", id, "filename", filename)
 			skipped++
 			continue
 		}
@@ -170,20 +461,20 @@ func (s *Server) handlePushUpdate(w http.ResponseWriter, r *http.Request) {
 			Payload: payload,
 		}
 		if err := s.CmdQueue.Send(id, cmd); err != nil {
-			s.Logger.Warn("update: queue failed", "agent_id", id, "error", err)
+			s.Logger.WarnContext(r.Context(), "update: queue failed", "agent_id", id, "error", err)
 			failed++
 		} else {
 			queued++
 		}
 	}
 
-	s.Logger.Info("agent update pushed",
+	s.Logger.InfoContext(r.Context(), "agent update pushed",
 		"ip", clientIP(r),
 		"queued", queued,
 		"skipped", skipped,
 		"failed", failed)
 
-	respondJSON(w, http.StatusOK, map[string]int{
+	s.respondJSON(w, http.StatusOK, map[string]int{
 		"queued":  queued,
 		"skipped": skipped,
 		"failed":  failed,
@@ -199,3 +490,120 @@ func agentBinaryFilename(goos, arch string) string {
 	}
 	return fmt.Sprintf("spectra-agent-%s-%s", goos, arch)
 }
+
+// broadcastable lists the commands handleBroadcast will fan out. Updates
+// need per-platform payloads and follow/cancel are tied to a single
+// session, so those stay on their dedicated endpoints.
+var broadcastable = map[protocol.CommandType]bool{
+	protocol.CmdFetchLogs:    true,
+	protocol.CmdDiskUsage:    true,
+	protocol.CmdListMounts:   true,
+	protocol.CmdNetworkDiag:  true,
+	protocol.CmdCertCheck:    true,
+	protocol.CmdIOStat:       true,
+	protocol.CmdTopProcesses: true,
+	protocol.CmdRouteTable:   true,
+}
+
+// handleBroadcast queues the same command to every registered agent,
+// optionally restricted to agents carrying all of the given labels and
+// tags.
+//
+// POST /api/v1/admin/broadcast
+func (s *Server) handleBroadcast(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Type    protocol.CommandType `json:"type"`
+		Payload json.RawMessage      `json:"payload,omitempty"`
+		Labels  map[string]string    `json:"labels,omitempty"`
+		Tags    []string             `json:"tags,omitempty"`
+		Timeout int                  `json:"timeout,omitempty"` // seconds
+	}
+	if err := decodeJSONBody(r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Timeout < 0 {
+		http.Error(w, "invalid timeout", http.StatusBadRequest)
+		return
+	}
+	if !broadcastable[req.Type] {
+		http.Error(w, fmt.Sprintf("command %q cannot be broadcast", req.Type), http.StatusBadRequest)
+		return
+	}
+	// Reject a bad payload once, up front, rather than queue it to every
+	// agent for each one to fail on its own.
+	payload, err := checkBroadcastPayload(req.Type, req.Payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	agents, err := s.DB.ListAgents(ctx)
+	if err != nil {
+		s.dbError(w, r, err, "handleBroadcast")
+		return
+	}
+
+	var matched map[string]int
+	if len(req.Labels) > 0 {
+		rows, err := s.DB.ListAllAgentLabels(ctx)
+		if err != nil {
+			s.dbError(w, r, err, "handleBroadcast")
+			return
+		}
+		matched = make(map[string]int)
+		for _, row := range rows {
+			if v, ok := req.Labels[row.Key]; ok && v == row.Value {
+				matched[formatUUID(row.AgentID)]++
+			}
+		}
+	}
+
+	commands := make(map[string]string)
+	failed := 0
+	for _, agent := range agents {
+		id := formatUUID(agent.ID)
+		if matched != nil && matched[id] != len(req.Labels) {
+			continue
+		}
+		if !hasAllTags(agent.Tags, req.Tags) {
+			continue
+		}
+
+		cmd := protocol.Command{
+			ID:      uuid.NewString(),
+			Type:    req.Type,
+			Payload: payload,
+			Timeout: req.Timeout,
+		}
+		if err := s.enqueue(id, cmd); err != nil {
+			s.Logger.WarnContext(ctx, "broadcast: queue failed", "agent_id", id, "error", err)
+			failed++
+			continue
+		}
+		commands[id] = cmd.ID
+	}
+
+	s.Logger.InfoContext(ctx, "command broadcast",
+		"ip", clientIP(r),
+		"command", req.Type,
+		"queued", len(commands),
+		"failed", failed)
+
+	s.respondJSON(w, http.StatusAccepted, map[string]any{
+		"queued":   len(commands),
+		"failed":   failed,
+		"commands": commands,
+	})
+}
+
+// hasAllTags reports whether every wanted tag appears in tags.
+func hasAllTags(tags, want []string) bool {
+	for _, t := range want {
+		if !slices.Contains(tags, strings.TrimSpace(t)) {
+			return false
+		}
+	}
+	return true
+}
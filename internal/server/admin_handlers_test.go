@@ -1,9 +1,17 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/database"
+	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
 // --- Admin Triggers ---
@@ -22,6 +30,81 @@ func TestHandleAdminTriggerLogs_Success(t *testing.T) {
 	}
 }
 
+func TestHandleAdminTriggerLogs_QueueFull(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	s.CmdQueue = NewCommandQueueWithLimit(1, QueueRejectNewest)
+	setupTestSession(mock)
+
+	codes := make([]int, 2)
+	for i := range codes {
+		req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/logs?agent="+agentID, nil))
+		rec := httptest.NewRecorder()
+		s.Router.ServeHTTP(rec, req)
+		codes[i] = rec.Code
+	}
+
+	if codes[0] != http.StatusAccepted {
+		t.Errorf("first trigger: got %d, want 202", codes[0])
+	}
+	if codes[1] != http.StatusTooManyRequests {
+		t.Errorf("second trigger: got %d, want 429", codes[1])
+	}
+}
+
+func TestHandleAdminTriggerLogs_Boot(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/logs?agent="+agentID+"&boot=-1", nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202", rec.Code)
+	}
+
+	cmd, err := s.CmdQueue.Wait(context.Background(), agentID, time.Second)
+	if err != nil {
+		t.Fatalf("expected queued command: %v", err)
+	}
+	var lr protocol.LogRequest
+	if err := json.Unmarshal(cmd.Payload, &lr); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if lr.Boot == nil || *lr.Boot != -1 {
+		t.Errorf("Boot: got %v, want -1", lr.Boot)
+	}
+}
+
+func TestHandleAdminTriggerLogs_InvalidBoot(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/logs?agent="+agentID+"&boot=last", nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status: got %d, want 400", rec.Code)
+	}
+}
+
+func TestHandleAdminTriggerLogs_InvalidMaxBytes(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/logs?agent="+agentID+"&max_bytes=-5", nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status: got %d, want 400", rec.Code)
+	}
+}
+
 func TestHandleAdminTriggerLogs_Unauthenticated(t *testing.T) {
 	s, agentID, _, _ := newTestServer()
 
@@ -188,3 +271,782 @@ func TestHandleGenerateToken_Unauthenticated(t *testing.T) {
 		t.Errorf("status: got %d, want 401", rec.Code)
 	}
 }
+
+func TestHandleAdminTriggerFollowLogs(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/logs/follow?agent="+agentID+"&level=ERROR&duration=90", nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202", rec.Code)
+	}
+
+	cmd, err := s.CmdQueue.Wait(context.Background(), agentID, time.Second)
+	if err != nil {
+		t.Fatalf("expected queued command: %v", err)
+	}
+	if cmd.Type != protocol.CmdFollowLogs {
+		t.Errorf("type: got %s, want %s", cmd.Type, protocol.CmdFollowLogs)
+	}
+	var fr protocol.FollowLogsRequest
+	if err := json.Unmarshal(cmd.Payload, &fr); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if fr.MinLevel != protocol.LevelError || fr.DurationSeconds != 90 {
+		t.Errorf("payload: got %+v, want ERROR for 90s", fr)
+	}
+}
+
+func TestHandleAdminTriggerFollowLogs_InvalidDuration(t *testing.T) {
+	for _, d := range []string{"0", "-1", "soon", "301"} {
+		s, agentID, _, mock := newTestServer()
+		setupTestSession(mock)
+
+		req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/logs/follow?agent="+agentID+"&duration="+d, nil))
+		rec := httptest.NewRecorder()
+
+		s.Router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("duration=%s: status got %d, want 400", d, rec.Code)
+		}
+	}
+}
+
+func TestHandleCancelCommand(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	setupTestSession(mock)
+	s.Commands.Track("cmd-follow", protocol.CmdFollowLogs, agentID)
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/commands/cmd-follow/cancel", nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202", rec.Code)
+	}
+
+	cmd, err := s.CmdQueue.Wait(context.Background(), agentID, time.Second)
+	if err != nil {
+		t.Fatalf("expected queued command: %v", err)
+	}
+	var cr protocol.CancelRequest
+	if err := json.Unmarshal(cmd.Payload, &cr); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if cmd.Type != protocol.CmdCancel || cr.CommandID != "cmd-follow" {
+		t.Errorf("got %s for %q, want CANCEL for cmd-follow", cmd.Type, cr.CommandID)
+	}
+}
+
+func TestHandleCancelCommand_Errors(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	setupTestSession(mock)
+	s.Commands.Track("cmd-done", protocol.CmdFollowLogs, agentID)
+	s.Commands.Complete("cmd-done", protocol.CommandResult{ID: "cmd-done"})
+
+	tests := []struct {
+		id   string
+		want int
+	}{
+		{"missing", http.StatusNotFound},
+		{"cmd-done", http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/commands/"+tt.id+"/cancel", nil))
+		rec := httptest.NewRecorder()
+
+		s.Router.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s: status got %d, want %d", tt.id, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestHandleAdminTriggerCertCheck(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/cert?agent="+agentID+"&target=example.com:443&dial_timeout=5&timeout=60", nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202", rec.Code)
+	}
+
+	cmd, err := s.CmdQueue.Wait(context.Background(), agentID, time.Second)
+	if err != nil {
+		t.Fatalf("expected queued command: %v", err)
+	}
+	var cr protocol.CertCheckRequest
+	if err := json.Unmarshal(cmd.Payload, &cr); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if cmd.Type != protocol.CmdCertCheck || cr.Target != "example.com:443" || cr.TimeoutSeconds != 5 {
+		t.Errorf("got %s %+v, want CERT_CHECK for example.com:443 with 5s dial timeout", cmd.Type, cr)
+	}
+	if cmd.Timeout != 60 {
+		t.Errorf("command timeout: got %d, want 60 independent of the dial timeout", cmd.Timeout)
+	}
+}
+
+func TestHandleAdminTriggerCertCheck_InvalidParams(t *testing.T) {
+	for _, q := range []string{
+		"",
+		"&target=example.com",
+		"&target=example.com:443&dial_timeout=0",
+		"&target=example.com:443&dial_timeout=31",
+		"&target=example.com:443&dial_timeout=x",
+	} {
+		s, agentID, _, mock := newTestServer()
+		setupTestSession(mock)
+
+		req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/cert?agent="+agentID+q, nil))
+		rec := httptest.NewRecorder()
+
+		s.Router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("query %q: status got %d, want 400", q, rec.Code)
+		}
+	}
+}
+
+func TestHandleAdminTriggerIOStat(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/iostat?agent="+agentID+"&interval=2", nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202", rec.Code)
+	}
+
+	cmd, err := s.CmdQueue.Wait(context.Background(), agentID, time.Second)
+	if err != nil {
+		t.Fatalf("expected queued command: %v", err)
+	}
+	var ir protocol.IOStatRequest
+	if err := json.Unmarshal(cmd.Payload, &ir); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if cmd.Type != protocol.CmdIOStat || ir.IntervalSeconds != 2 {
+		t.Errorf("got %s %+v, want IOSTAT with 2s interval", cmd.Type, ir)
+	}
+}
+
+func TestHandleAdminTriggerIOStat_InvalidInterval(t *testing.T) {
+	for _, v := range []string{"0", "11", "abc"} {
+		s, agentID, _, mock := newTestServer()
+		setupTestSession(mock)
+
+		req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/iostat?agent="+agentID+"&interval="+v, nil))
+		rec := httptest.NewRecorder()
+
+		s.Router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("interval %q: status got %d, want 400", v, rec.Code)
+		}
+	}
+}
+
+func TestHandleAdminTriggerProcFiles(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/procfiles?agent="+agentID+"&pid=1234&limit=50", nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202", rec.Code)
+	}
+
+	cmd, err := s.CmdQueue.Wait(context.Background(), agentID, time.Second)
+	if err != nil {
+		t.Fatalf("expected queued command: %v", err)
+	}
+	var pr protocol.ProcFilesRequest
+	if err := json.Unmarshal(cmd.Payload, &pr); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if cmd.Type != protocol.CmdProcFiles || pr.PID != 1234 || pr.Limit != 50 {
+		t.Errorf("got %s %+v, want PROC_FILES for pid 1234 limit 50", cmd.Type, pr)
+	}
+}
+
+func TestHandleAdminTriggerProcFiles_InvalidParams(t *testing.T) {
+	for _, q := range []string{"", "&pid=0", "&pid=abc", "&pid=1&limit=0"} {
+		s, agentID, _, mock := newTestServer()
+		setupTestSession(mock)
+
+		req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/procfiles?agent="+agentID+q, nil))
+		rec := httptest.NewRecorder()
+
+		s.Router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("query %q: status got %d, want 400", q, rec.Code)
+		}
+	}
+}
+
+func TestHandleAdminTriggerMountLatency(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/mountlatency?agent="+agentID+"&mountpoint=/mnt/nfs&probe_timeout=10", nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202", rec.Code)
+	}
+
+	cmd, err := s.CmdQueue.Wait(context.Background(), agentID, time.Second)
+	if err != nil {
+		t.Fatalf("expected queued command: %v", err)
+	}
+	var mr protocol.MountLatencyRequest
+	if err := json.Unmarshal(cmd.Payload, &mr); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if cmd.Type != protocol.CmdMountLatency || mr.Mountpoint != "/mnt/nfs" || mr.TimeoutSeconds != 10 {
+		t.Errorf("got %s %+v, want MOUNT_LATENCY for /mnt/nfs with 10s timeout", cmd.Type, mr)
+	}
+}
+
+func TestHandleAdminTriggerMountLatency_InvalidTimeout(t *testing.T) {
+	for _, v := range []string{"0", "-1", "31", "abc"} {
+		s, agentID, _, mock := newTestServer()
+		setupTestSession(mock)
+
+		req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/mountlatency?agent="+agentID+"&probe_timeout="+v, nil))
+		rec := httptest.NewRecorder()
+
+		s.Router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("probe_timeout %q: status got %d, want 400", v, rec.Code)
+		}
+	}
+}
+
+func TestHandleAdminTriggerMTUProbe(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/mtu?agent="+agentID+"&target=10.8.0.1", nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202", rec.Code)
+	}
+
+	cmd, err := s.CmdQueue.Wait(context.Background(), agentID, time.Second)
+	if err != nil {
+		t.Fatalf("expected queued command: %v", err)
+	}
+	var mr protocol.MTURequest
+	if err := json.Unmarshal(cmd.Payload, &mr); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if cmd.Type != protocol.CmdMTUProbe || mr.Target != "10.8.0.1" {
+		t.Errorf("got %s %+v, want MTU_PROBE for 10.8.0.1", cmd.Type, mr)
+	}
+}
+
+func TestHandleAdminTriggerMTUProbe_MissingTarget(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/mtu?agent="+agentID, nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status got %d, want 400", rec.Code)
+	}
+}
+
+func TestHandleAdminTriggerThroughput(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/throughput?agent="+agentID+"&bytes=1048576", nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202", rec.Code)
+	}
+
+	cmd, err := s.CmdQueue.Wait(context.Background(), agentID, time.Second)
+	if err != nil {
+		t.Fatalf("expected queued command: %v", err)
+	}
+	var tr protocol.ThroughputRequest
+	if err := json.Unmarshal(cmd.Payload, &tr); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if cmd.Type != protocol.CmdThroughput || tr.Bytes != 1<<20 {
+		t.Errorf("got %s %+v, want THROUGHPUT with 1 MiB", cmd.Type, tr)
+	}
+}
+
+func TestHandleAdminTriggerThroughput_InvalidBytes(t *testing.T) {
+	for _, v := range []string{"0", "67108865", "abc"} {
+		s, agentID, _, mock := newTestServer()
+		setupTestSession(mock)
+
+		req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/throughput?agent="+agentID+"&bytes="+v, nil))
+		rec := httptest.NewRecorder()
+
+		s.Router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("bytes %q: status got %d, want 400", v, rec.Code)
+		}
+	}
+}
+
+func TestHandleAdminTriggerTopProcesses(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/processes?agent="+agentID+"&sort=memory&limit=5", nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202", rec.Code)
+	}
+
+	cmd, err := s.CmdQueue.Wait(context.Background(), agentID, time.Second)
+	if err != nil {
+		t.Fatalf("expected queued command: %v", err)
+	}
+	var tr protocol.TopProcessesRequest
+	if err := json.Unmarshal(cmd.Payload, &tr); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if cmd.Type != protocol.CmdTopProcesses || tr.SortBy != protocol.SortByMemory || tr.Limit != 5 {
+		t.Errorf("got %s %+v, want TOP_PROCESSES by memory, limit 5", cmd.Type, tr)
+	}
+}
+
+func TestHandleAdminTriggerTopProcesses_InvalidParams(t *testing.T) {
+	for _, q := range []string{"&sort=name", "&limit=0", "&limit=x"} {
+		s, agentID, _, mock := newTestServer()
+		setupTestSession(mock)
+
+		req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/processes?agent="+agentID+q, nil))
+		rec := httptest.NewRecorder()
+
+		s.Router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("query %q: status got %d, want 400", q, rec.Code)
+		}
+	}
+}
+
+func TestHandleAdminTriggerRouteTable(t *testing.T) {
+	s, agentID, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/routes?agent="+agentID, nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202", rec.Code)
+	}
+
+	cmd, err := s.CmdQueue.Wait(context.Background(), agentID, time.Second)
+	if err != nil {
+		t.Fatalf("expected queued command: %v", err)
+	}
+	if cmd.Type != protocol.CmdRouteTable {
+		t.Errorf("type: got %s, want ROUTE_TABLE", cmd.Type)
+	}
+}
+
+// --- Broadcast ---
+
+func broadcastAgents(ids ...string) []database.ListAgentsRow {
+	rows := make([]database.ListAgentsRow, len(ids))
+	for i, id := range ids {
+		rows[i] = database.ListAgentsRow{ID: mustUUID(id)}
+	}
+	return rows
+}
+
+func TestHandleBroadcast_AllAgents(t *testing.T) {
+	s, _, _, mock := newTestServer()
+	setupTestSession(mock)
+	ids := []string{
+		"11111111-1111-1111-1111-111111111111",
+		"22222222-2222-2222-2222-222222222222",
+		"33333333-3333-3333-3333-333333333333",
+	}
+	mock.ListAgentsReturn = broadcastAgents(ids...)
+
+	body := strings.NewReader(`{"type":"FETCH_LOGS","payload":{"min_level":"ERROR"}}`)
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/broadcast", body))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Queued   int               `json:"queued"`
+		Commands map[string]string `json:"commands"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Queued != len(ids) {
+		t.Errorf("queued: got %d, want %d", resp.Queued, len(ids))
+	}
+
+	for _, id := range ids {
+		cmd, err := s.CmdQueue.Wait(context.Background(), id, time.Second)
+		if err != nil {
+			t.Fatalf("agent %s: expected queued command: %v", id, err)
+		}
+		if cmd.Type != protocol.CmdFetchLogs {
+			t.Errorf("agent %s: type %q, want FETCH_LOGS", id, cmd.Type)
+		}
+		if cmd.ID != resp.Commands[id] {
+			t.Errorf("agent %s: command id %q, response says %q", id, cmd.ID, resp.Commands[id])
+		}
+		if string(cmd.Payload) != `{"min_level":"ERROR"}` {
+			t.Errorf("agent %s: payload %s", id, cmd.Payload)
+		}
+	}
+}
+
+func TestHandleBroadcast_LabelFilter(t *testing.T) {
+	s, _, _, mock := newTestServer()
+	setupTestSession(mock)
+	prod := "11111111-1111-1111-1111-111111111111"
+	prodOther := "22222222-2222-2222-2222-222222222222"
+	dev := "33333333-3333-3333-3333-333333333333"
+	mock.ListAgentsReturn = broadcastAgents(prod, prodOther, dev)
+	mock.ListAllAgentLabelsReturn = []database.ListAllAgentLabelsRow{
+		{AgentID: mustUUID(prod), Key: "env", Value: "prod"},
+		{AgentID: mustUUID(prod), Key: "role", Value: "web"},
+		{AgentID: mustUUID(prodOther), Key: "env", Value: "prod"},
+		{AgentID: mustUUID(prodOther), Key: "role", Value: "db"},
+		{AgentID: mustUUID(dev), Key: "env", Value: "dev"},
+		{AgentID: mustUUID(dev), Key: "role", Value: "web"},
+	}
+
+	body := strings.NewReader(`{"type":"DISK_USAGE","labels":{"env":"prod","role":"web"}}`)
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/broadcast", body))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202", rec.Code)
+	}
+	if _, err := s.CmdQueue.Wait(context.Background(), prod, time.Second); err != nil {
+		t.Errorf("matching agent should have a command: %v", err)
+	}
+	for _, id := range []string{prodOther, dev} {
+		if _, err := s.CmdQueue.Wait(context.Background(), id, 10*time.Millisecond); err == nil {
+			t.Errorf("agent %s should not have a command", id)
+		}
+	}
+}
+
+func TestHandleBroadcast_TagFilter(t *testing.T) {
+	s, _, _, mock := newTestServer()
+	setupTestSession(mock)
+	prodWeb := "11111111-1111-1111-1111-111111111111"
+	prod := "22222222-2222-2222-2222-222222222222"
+	untagged := "33333333-3333-3333-3333-333333333333"
+	mock.ListAgentsReturn = []database.ListAgentsRow{
+		{ID: mustUUID(prodWeb), Tags: []string{"prod", "web"}},
+		{ID: mustUUID(prod), Tags: []string{"prod"}},
+		{ID: mustUUID(untagged), Tags: []string{}},
+	}
+
+	body := strings.NewReader(`{"type":"ROUTE_TABLE","tags":["prod"]}`)
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/broadcast", body))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202", rec.Code)
+	}
+	for _, id := range []string{prodWeb, prod} {
+		if _, err := s.CmdQueue.Wait(context.Background(), id, time.Second); err != nil {
+			t.Errorf("agent %s tagged prod should have a command: %v", id, err)
+		}
+	}
+	if _, err := s.CmdQueue.Wait(context.Background(), untagged, 10*time.Millisecond); err == nil {
+		t.Error("untagged agent should not have a command")
+	}
+}
+
+// registerTagged registers an agent through the handler and returns its ID.
+func registerTagged(t *testing.T, s *Server, hostname string, tags ...string) string {
+	t.Helper()
+	body, _ := json.Marshal(protocol.RegisterRequest{
+		Token: s.Tokens.Generate(time.Hour),
+		Info:  protocol.HostInfo{Hostname: hostname, Tags: tags},
+	})
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/agent/register", bytes.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("register %s: status %d", hostname, rec.Code)
+	}
+	var resp protocol.RegisterResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("register %s: %v", hostname, err)
+	}
+	return resp.AgentID
+}
+
+func TestAdminTrigger_TagTargetsRegisteredAgents(t *testing.T) {
+	s, _, _, mock := newTestServer()
+	setupTestSession(mock)
+	web := registerTagged(t, s, "web-1", "prod", "web")
+	db := registerTagged(t, s, "db-1", "prod", "db")
+	dev := registerTagged(t, s, "dev-1", "dev")
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/disk?tag=prod&tag=web&top_n=5", nil))
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Queued   int               `json:"queued"`
+		Commands map[string]string `json:"commands"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Queued != 1 {
+		t.Errorf("queued: got %d, want 1", resp.Queued)
+	}
+
+	cmd, err := s.CmdQueue.Wait(context.Background(), web, time.Second)
+	if err != nil {
+		t.Fatalf("agent tagged prod+web should have a command: %v", err)
+	}
+	if cmd.Type != protocol.CmdDiskUsage || cmd.ID != resp.Commands[web] {
+		t.Errorf("got %+v, response commands %v", cmd, resp.Commands)
+	}
+	if string(cmd.Payload) != `{"path":"","top_n":5}` {
+		t.Errorf("payload: got %s", cmd.Payload)
+	}
+	if _, ok := s.Commands.Get(cmd.ID); !ok {
+		t.Error("tagged command should be tracked")
+	}
+	for _, id := range []string{db, dev} {
+		if _, err := s.CmdQueue.Wait(context.Background(), id, 10*time.Millisecond); err == nil {
+			t.Errorf("agent %s should not have a command", id)
+		}
+	}
+}
+
+func TestAdminTrigger_TagStillValidatesParams(t *testing.T) {
+	s, _, _, mock := newTestServer()
+	setupTestSession(mock)
+	registerTagged(t, s, "web-1", "prod")
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/network?tag=prod", nil))
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("missing action: got %d, want 400", rec.Code)
+	}
+}
+
+func TestAdminTrigger_TagRejectsSessionCommands(t *testing.T) {
+	s, _, _, mock := newTestServer()
+	setupTestSession(mock)
+	id := registerTagged(t, s, "web-1", "prod")
+
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/logs/follow?tag=prod", nil))
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status: got %d, want 400", rec.Code)
+	}
+	if _, err := s.CmdQueue.Wait(context.Background(), id, 10*time.Millisecond); err == nil {
+		t.Error("follow should not be fanned out by tag")
+	}
+}
+
+func TestHasAllTags(t *testing.T) {
+	tests := []struct {
+		tags, want []string
+		expected   bool
+	}{
+		{[]string{"prod", "web"}, nil, true},
+		{[]string{"prod", "web"}, []string{"prod"}, true},
+		{[]string{"prod", "web"}, []string{"web", "prod"}, true},
+		{[]string{"prod"}, []string{"prod", "web"}, false},
+		{nil, []string{"prod"}, false},
+		{[]string{"prod"}, []string{" prod "}, true},
+	}
+	for _, tt := range tests {
+		if got := hasAllTags(tt.tags, tt.want); got != tt.expected {
+			t.Errorf("hasAllTags(%v, %v) = %v, want %v", tt.tags, tt.want, got, tt.expected)
+		}
+	}
+}
+
+func TestHandleBroadcast_Timeout(t *testing.T) {
+	s, _, _, mock := newTestServer()
+	setupTestSession(mock)
+	id := "11111111-1111-1111-1111-111111111111"
+	mock.ListAgentsReturn = broadcastAgents(id)
+
+	body := strings.NewReader(`{"type":"DISK_USAGE","timeout":300}`)
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/broadcast", body))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202: %s", rec.Code, rec.Body.String())
+	}
+	cmd, err := s.CmdQueue.Wait(context.Background(), id, time.Second)
+	if err != nil {
+		t.Fatalf("expected queued command: %v", err)
+	}
+	if cmd.Timeout != 300 {
+		t.Errorf("Timeout: got %d, want 300", cmd.Timeout)
+	}
+}
+
+func TestHandleBroadcast_NegativeTimeout(t *testing.T) {
+	s, _, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	body := strings.NewReader(`{"type":"DISK_USAGE","timeout":-1}`)
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/broadcast", body))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("got %d, want 400", rec.Code)
+	}
+}
+
+func TestHandleBroadcast_ValidatesPayload(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"network without action", `{"type":"NETWORK_DIAG","payload":{"target":"8.8.8.8"}}`},
+		{"cert target without port", `{"type":"CERT_CHECK","payload":{"target":"example.com"}}`},
+		{"cert missing payload", `{"type":"CERT_CHECK"}`},
+		{"negative cert timeout", `{"type":"CERT_CHECK","payload":{"target":"example.com:443","timeout_seconds":-1}}`},
+		{"cert timeout over cap", `{"type":"CERT_CHECK","payload":{"target":"example.com:443","timeout_seconds":31}}`},
+		{"iostat interval too long", `{"type":"IOSTAT","payload":{"interval_seconds":60}}`},
+		{"bad process sort", `{"type":"TOP_PROCESSES","payload":{"sort_by":"disk"}}`},
+		{"negative process limit", `{"type":"TOP_PROCESSES","payload":{"limit":-5}}`},
+		{"negative max_bytes", `{"type":"FETCH_LOGS","payload":{"max_bytes":-1}}`},
+		{"unknown field", `{"type":"DISK_USAGE","payload":{"top":5}}`},
+		{"wrong shape", `{"type":"DISK_USAGE","payload":[1,2]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _, mock := newTestServer()
+			setupTestSession(mock)
+			id := "11111111-1111-1111-1111-111111111111"
+			mock.ListAgentsReturn = broadcastAgents(id)
+
+			req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/broadcast", strings.NewReader(tt.body)))
+			rec := httptest.NewRecorder()
+			s.Router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status: got %d, want 400: %s", rec.Code, rec.Body.String())
+			}
+			if _, err := s.CmdQueue.Wait(context.Background(), id, 10*time.Millisecond); err == nil {
+				t.Error("invalid payload should not be queued")
+			}
+		})
+	}
+}
+
+func TestHandleBroadcast_NormalizesPayload(t *testing.T) {
+	s, _, _, mock := newTestServer()
+	setupTestSession(mock)
+	id := "11111111-1111-1111-1111-111111111111"
+	mock.ListAgentsReturn = broadcastAgents(id)
+
+	body := strings.NewReader(`{"type":"DISK_USAGE","payload":{"path":"/var"}}`)
+	req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/broadcast", body))
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202: %s", rec.Code, rec.Body.String())
+	}
+	cmd, err := s.CmdQueue.Wait(context.Background(), id, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got protocol.DiskUsageRequest
+	if err := json.Unmarshal(cmd.Payload, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "/var" || got.TopN != defaultDiskTopN {
+		t.Errorf("payload: got %+v, want path /var with the trigger's default top_n", got)
+	}
+}
+
+func TestHandleBroadcast_RejectsUnsupportedCommand(t *testing.T) {
+	s, _, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	for _, typ := range []string{"UPDATE_AGENT", "CANCEL", "BOGUS", ""} {
+		body := strings.NewReader(`{"type":"` + typ + `"}`)
+		req := authedRequest(httptest.NewRequest(http.MethodPost, "/api/v1/admin/broadcast", body))
+		rec := httptest.NewRecorder()
+
+		s.Router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("type %q: got %d, want 400", typ, rec.Code)
+		}
+	}
+}
@@ -60,10 +60,10 @@ func validateChannelRequest(req channelRequest) error {
 func (s *Server) handleListAlertChannels(w http.ResponseWriter, r *http.Request) {
 	channels, err := s.DB.ListAlertChannels(r.Context())
 	if err != nil {
-		s.dbError(w, err, "handleListAlertChannels")
+		s.dbError(w, r, err, "handleListAlertChannels")
 		return
 	}
-	respondJSON(w, http.StatusOK, toChannelResponses(channels))
+	s.respondJSON(w, http.StatusOK, toChannelResponses(channels))
 }
 
 // handleCreateAlertChannel creates a new alert channel.
@@ -86,12 +86,12 @@ func (s *Server) handleCreateAlertChannel(w http.ResponseWriter, r *http.Request
 		Config: req.Config,
 	})
 	if err != nil {
-		s.dbError(w, err, "handleCreateAlertChannel")
+		s.dbError(w, r, err, "handleCreateAlertChannel")
 		return
 	}
 
-	s.Logger.Info("alert channel created", "channel_id", formatUUID(ch.ID), "type", ch.Type)
-	respondJSON(w, http.StatusCreated, toChannelResponse(ch))
+	s.Logger.InfoContext(r.Context(), "alert channel created", "channel_id", formatUUID(ch.ID), "type", ch.Type)
+	s.respondJSON(w, http.StatusCreated, toChannelResponse(ch))
 }
 
 // handleUpdateAlertChannel updates an existing alert channel.
@@ -121,12 +121,12 @@ func (s *Server) handleUpdateAlertChannel(w http.ResponseWriter, r *http.Request
 		Config: req.Config,
 	})
 	if err != nil {
-		s.dbError(w, err, "handleUpdateAlertChannel")
+		s.dbError(w, r, err, "handleUpdateAlertChannel")
 		return
 	}
 
-	s.Logger.Info("alert channel updated", "channel_id", id)
-	respondJSON(w, http.StatusOK, toChannelResponse(ch))
+	s.Logger.InfoContext(r.Context(), "alert channel updated", "channel_id", id)
+	s.respondJSON(w, http.StatusOK, toChannelResponse(ch))
 }
 
 // handleDeleteAlertChannel deletes an alert channel. The alert_rule_channels
@@ -141,11 +141,11 @@ func (s *Server) handleDeleteAlertChannel(w http.ResponseWriter, r *http.Request
 	}
 
 	if err := s.DB.DeleteAlertChannel(r.Context(), mustUUID(id)); err != nil {
-		s.dbError(w, err, "handleDeleteAlertChannel")
+		s.dbError(w, r, err, "handleDeleteAlertChannel")
 		return
 	}
 
-	s.Logger.Info("alert channel deleted", "channel_id", id)
+	s.Logger.InfoContext(r.Context(), "alert channel deleted", "channel_id", id)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -263,10 +263,10 @@ func (s *Server) setRuleChannels(r *http.Request, ruleID pgtype.UUID, channelIDs
 func (s *Server) handleListAlertRules(w http.ResponseWriter, r *http.Request) {
 	rules, err := s.DB.ListAlertRules(r.Context())
 	if err != nil {
-		s.dbError(w, err, "handleListAlertRules")
+		s.dbError(w, r, err, "handleListAlertRules")
 		return
 	}
-	respondJSON(w, http.StatusOK, toRuleViews(rules))
+	s.respondJSON(w, http.StatusOK, toRuleViews(rules))
 }
 
 // handleGetAlertRule returns a single rule plus its channel associations.
@@ -286,7 +286,7 @@ func (s *Server) handleGetAlertRule(w http.ResponseWriter, r *http.Request) {
 	}
 	channels, err := s.DB.ListChannelsForRule(r.Context(), mustUUID(id))
 	if err != nil {
-		s.dbError(w, err, "handleGetAlertRule")
+		s.dbError(w, r, err, "handleGetAlertRule")
 		return
 	}
 
@@ -294,7 +294,7 @@ func (s *Server) handleGetAlertRule(w http.ResponseWriter, r *http.Request) {
 		Rule     ruleView          `json:"rule"`
 		Channels []channelResponse `json:"channels"`
 	}
-	respondJSON(w, http.StatusOK, resp{
+	s.respondJSON(w, http.StatusOK, resp{
 		Rule:     toRuleView(rule),
 		Channels: toChannelResponses(channels),
 	})
@@ -329,20 +329,20 @@ func (s *Server) handleCreateAlertRule(w http.ResponseWriter, r *http.Request) {
 		CooldownSeconds: req.CooldownSeconds,
 	})
 	if err != nil {
-		s.dbError(w, err, "handleCreateAlertRule")
+		s.dbError(w, r, err, "handleCreateAlertRule")
 		return
 	}
 
 	if err := s.setRuleChannels(r, rule.ID, req.ChannelIDs); err != nil {
 		// rule exists but channel wiring failed - report so the caller can retry
-		s.Logger.Error("failed to set rule channels", "error", err, "rule_id", formatUUID(rule.ID))
+		s.Logger.ErrorContext(r.Context(), "failed to set rule channels", "error", err, "rule_id", formatUUID(rule.ID))
 		http.Error(w, "rule created but channel association failed", http.StatusInternalServerError)
 		return
 	}
 
-	s.Logger.Info("alert rule created",
+	s.Logger.InfoContext(r.Context(), "alert rule created",
 		"rule_id", formatUUID(rule.ID), "scope", rule.Scope, "condition", rule.ConditionType)
-	respondJSON(w, http.StatusCreated, ruleResponse{
+	s.respondJSON(w, http.StatusCreated, ruleResponse{
 		Rule:     toRuleView(rule),
 		Warnings: s.serviceDownWarnings(r, req),
 	})
@@ -391,18 +391,18 @@ func (s *Server) handleUpdateAlertRule(w http.ResponseWriter, r *http.Request) {
 		CooldownSeconds: req.CooldownSeconds,
 	})
 	if err != nil {
-		s.dbError(w, err, "handleUpdateAlertRule")
+		s.dbError(w, r, err, "handleUpdateAlertRule")
 		return
 	}
 
 	if err := s.setRuleChannels(r, updated.ID, req.ChannelIDs); err != nil {
-		s.Logger.Error("failed to set rule channels", "error", err, "rule_id", id)
+		s.Logger.ErrorContext(r.Context(), "failed to set rule channels", "error", err, "rule_id", id)
 		http.Error(w, "rule updated but channel association failed", http.StatusInternalServerError)
 		return
 	}
 
-	s.Logger.Info("alert rule updated", "rule_id", id)
-	respondJSON(w, http.StatusOK, ruleResponse{
+	s.Logger.InfoContext(r.Context(), "alert rule updated", "rule_id", id)
+	s.respondJSON(w, http.StatusOK, ruleResponse{
 		Rule:     toRuleView(updated),
 		Warnings: s.serviceDownWarnings(r, req),
 	})
@@ -431,12 +431,12 @@ func (s *Server) handleSetAlertRuleEnabled(w http.ResponseWriter, r *http.Reques
 		Enabled: req.Enabled,
 	})
 	if err != nil {
-		s.dbError(w, err, "handleSetAlertRuleEnabled")
+		s.dbError(w, r, err, "handleSetAlertRuleEnabled")
 		return
 	}
 
-	s.Logger.Info("alert rule enabled toggled", "rule_id", id, "enabled", req.Enabled)
-	respondJSON(w, http.StatusOK, toRuleView(rule))
+	s.Logger.InfoContext(r.Context(), "alert rule enabled toggled", "rule_id", id, "enabled", req.Enabled)
+	s.respondJSON(w, http.StatusOK, toRuleView(rule))
 }
 
 // handleDeleteAlertRule deletes a rule. Channel associations and events are
@@ -451,11 +451,11 @@ func (s *Server) handleDeleteAlertRule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.DB.DeleteAlertRule(r.Context(), mustUUID(id)); err != nil {
-		s.dbError(w, err, "handleDeleteAlertRule")
+		s.dbError(w, r, err, "handleDeleteAlertRule")
 		return
 	}
 
-	s.Logger.Info("alert rule deleted", "rule_id", id)
+	s.Logger.InfoContext(r.Context(), "alert rule deleted", "rule_id", id)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -465,10 +465,10 @@ func (s *Server) handleDeleteAlertRule(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleListActiveAlerts(w http.ResponseWriter, r *http.Request) {
 	events, err := s.DB.ListActiveAlertEvents(r.Context())
 	if err != nil {
-		s.dbError(w, err, "handleListActiveAlerts")
+		s.dbError(w, r, err, "handleListActiveAlerts")
 		return
 	}
-	respondJSON(w, http.StatusOK, toActiveEventViews(events))
+	s.respondJSON(w, http.StatusOK, toActiveEventViews(events))
 }
 
 // parseLimitOffset reads limit/offset query params with defaults and bounds.
@@ -514,10 +514,10 @@ func (s *Server) handleListAlertHistory(w http.ResponseWriter, r *http.Request)
 		Offset: offset,
 	})
 	if err != nil {
-		s.dbError(w, err, "handleListAlertHistory")
+		s.dbError(w, r, err, "handleListAlertHistory")
 		return
 	}
-	respondJSON(w, http.StatusOK, toHistoryEventViews(events))
+	s.respondJSON(w, http.StatusOK, toHistoryEventViews(events))
 }
 
 // handleListAgentAlertHistory returns paginated alert event history for one agent.
@@ -541,8 +541,8 @@ func (s *Server) handleListAgentAlertHistory(w http.ResponseWriter, r *http.Requ
 		Offset:  offset,
 	})
 	if err != nil {
-		s.dbError(w, err, "handleListAgentAlertHistory")
+		s.dbError(w, r, err, "handleListAgentAlertHistory")
 		return
 	}
-	respondJSON(w, http.StatusOK, toAgentEventViews(events))
+	s.respondJSON(w, http.StatusOK, toAgentEventViews(events))
 }
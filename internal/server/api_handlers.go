@@ -2,6 +2,7 @@ package server
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
@@ -53,7 +54,7 @@ var shortRanges = map[string]time.Duration{
 //   - Calendar range: ?start=YYYY-MM-DDT00:00:00Z&end=YYYY-MM-DDT00:00:00Z
 //
 // If end is omitted in calendar, it defaults to now.
-// Start is clamped to the 30-day retention boundary.
+// Both ends are clamped to the 30-day retention boundary and now.
 func parseTimeRange(r *http.Request) (pgtype.Timestamptz, pgtype.Timestamptz, error) {
 	now := time.Now()
 	oldest := now.AddDate(0, 0, -30)
@@ -88,24 +89,34 @@ func parseTimeRange(r *http.Request) (pgtype.Timestamptz, pgtype.Timestamptz, er
 		}
 	}
 
-	if start.Before(oldest) {
-		start = oldest
-	}
-	if end.After(now) {
-		end = now
-	}
 	if start.After(end) {
 		return pgtype.Timestamptz{}, pgtype.Timestamptz{}, fmt.Errorf("start time must be before end time")
 	}
 
+	// Clamp both ends to available history. A window that lies entirely
+	// outside it collapses to zero width and returns no rows.
+	start = clampTime(start, oldest, now)
+	end = clampTime(end, oldest, now)
+
 	return pgTimestamp(start), pgTimestamp(end), nil
 }
 
+// clampTime limits t to [lo, hi].
+func clampTime(t, lo, hi time.Time) time.Time {
+	switch {
+	case t.Before(lo):
+		return lo
+	case t.After(hi):
+		return hi
+	}
+	return t
+}
+
 // handleOverview returns all agents with their current metrics for the dashboard.
 func (s *Server) handleOverview(w http.ResponseWriter, r *http.Request) {
 	rows, err := s.DB.GetOverview(r.Context())
 	if err != nil {
-		s.dbError(w, err, "handleGetOverview")
+		s.dbError(w, r, err, "handleGetOverview")
 		return
 	}
 
@@ -178,7 +189,7 @@ func (s *Server) handleOverview(w http.ResponseWriter, r *http.Request) {
 		result = append(result, a)
 	}
 
-	respondJSON(w, http.StatusOK, result)
+	s.respondJSON(w, http.StatusOK, result)
 }
 
 // handleGetAgent returns details for a single agent.
@@ -191,11 +202,11 @@ func (s *Server) handleGetAgent(w http.ResponseWriter, r *http.Request) {
 
 	agent, err := s.DB.GetAgent(r.Context(), mustUUID(agentID))
 	if err != nil {
-		s.dbError(w, err, "handleGetAgent")
+		s.dbError(w, r, err, "handleGetAgent")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, agent)
+	s.respondJSON(w, http.StatusOK, agent)
 }
 
 // handleDeleteAgent removes an agent and all associated data.
@@ -207,10 +218,11 @@ func (s *Server) handleDeleteAgent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.DB.DeleteAgent(r.Context(), mustUUID(agentID)); err != nil {
-		s.dbError(w, err, "handleDeleteAgent")
+		s.dbError(w, r, err, "handleDeleteAgent")
 		return
 	}
 	s.forgetAgentLabels(agentID)
+	s.hostnames.forget(agentID)
 
 	s.CmdQueue.Remove(agentID)
 	w.WriteHeader(http.StatusNoContent)
@@ -222,26 +234,27 @@ func (s *Server) handleGetCPU(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	bucket, agg, err := resolveBucket(r, start, end)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	var (
-		result any
-		err    error
-	)
-
-	if bucket := bucketInterval(start, end); bucket == "" {
+	var result any
+	if bucket == "" {
 		result, err = s.DB.GetCPURange(r.Context(), database.GetCPURangeParams{
 			AgentID: uid, StartTime: start, EndTime: end,
 		})
 	} else {
 		result, err = s.DB.GetCPUBucketed(r.Context(), database.GetCPUBucketedParams{
-			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket,
+			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket, Aggregate: agg,
 		})
 	}
 	if err != nil {
-		s.dbError(w, err, "handleGetCPU")
+		s.dbError(w, r, err, "handleGetCPU")
 		return
 	}
-	respondJSON(w, http.StatusOK, result)
+	s.respondJSON(w, http.StatusOK, result)
 }
 
 // handleGetMemory returns memory metrics for an agent over a time range.
@@ -250,26 +263,27 @@ func (s *Server) handleGetMemory(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	bucket, agg, err := resolveBucket(r, start, end)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	var (
-		result any
-		err    error
-	)
-
-	if bucket := bucketInterval(start, end); bucket == "" {
+	var result any
+	if bucket == "" {
 		result, err = s.DB.GetMemoryRange(r.Context(), database.GetMemoryRangeParams{
 			AgentID: uid, StartTime: start, EndTime: end,
 		})
 	} else {
 		result, err = s.DB.GetMemoryBucketed(r.Context(), database.GetMemoryBucketedParams{
-			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket,
+			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket, Aggregate: agg,
 		})
 	}
 	if err != nil {
-		s.dbError(w, err, "handleGetMemory")
+		s.dbError(w, r, err, "handleGetMemory")
 		return
 	}
-	respondJSON(w, http.StatusOK, result)
+	s.respondJSON(w, http.StatusOK, result)
 }
 
 // handleGetDisk returns disk metrics for an agent over a time range.
@@ -278,26 +292,27 @@ func (s *Server) handleGetDisk(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	bucket, agg, err := resolveBucket(r, start, end)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	var (
-		result any
-		err    error
-	)
-
-	if bucket := bucketInterval(start, end); bucket == "" {
+	var result any
+	if bucket == "" {
 		result, err = s.DB.GetDiskRange(r.Context(), database.GetDiskRangeParams{
 			AgentID: uid, StartTime: start, EndTime: end,
 		})
 	} else {
 		result, err = s.DB.GetDiskBucketed(r.Context(), database.GetDiskBucketedParams{
-			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket,
+			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket, Aggregate: agg,
 		})
 	}
 	if err != nil {
-		s.dbError(w, err, "handleGetDisk")
+		s.dbError(w, r, err, "handleGetDisk")
 		return
 	}
-	respondJSON(w, http.StatusOK, result)
+	s.respondJSON(w, http.StatusOK, result)
 }
 
 // handleGetDiskIO returns diskio metrics for an agent over a time range.
@@ -306,26 +321,27 @@ func (s *Server) handleGetDiskIO(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	bucket, agg, err := resolveBucket(r, start, end)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	var (
-		result any
-		err    error
-	)
-
-	if bucket := bucketInterval(start, end); bucket == "" {
+	var result any
+	if bucket == "" {
 		result, err = s.DB.GetDiskIORange(r.Context(), database.GetDiskIORangeParams{
 			AgentID: uid, StartTime: start, EndTime: end,
 		})
 	} else {
 		result, err = s.DB.GetDiskIOBucketed(r.Context(), database.GetDiskIOBucketedParams{
-			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket,
+			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket, Aggregate: agg,
 		})
 	}
 	if err != nil {
-		s.dbError(w, err, "handleGetDiskIO")
+		s.dbError(w, r, err, "handleGetDiskIO")
 		return
 	}
-	respondJSON(w, http.StatusOK, result)
+	s.respondJSON(w, http.StatusOK, result)
 }
 
 // handleGetNetwork returns network metrics for an agent over a time range.
@@ -334,26 +350,27 @@ func (s *Server) handleGetNetwork(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	bucket, agg, err := resolveBucket(r, start, end)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	var (
-		result any
-		err    error
-	)
-
-	if bucket := bucketInterval(start, end); bucket == "" {
+	var result any
+	if bucket == "" {
 		result, err = s.DB.GetNetworkRange(r.Context(), database.GetNetworkRangeParams{
 			AgentID: uid, StartTime: start, EndTime: end,
 		})
 	} else {
 		result, err = s.DB.GetNetworkBucketed(r.Context(), database.GetNetworkBucketedParams{
-			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket,
+			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket, Aggregate: agg,
 		})
 	}
 	if err != nil {
-		s.dbError(w, err, "handleGetNetwork")
+		s.dbError(w, r, err, "handleGetNetwork")
 		return
 	}
-	respondJSON(w, http.StatusOK, result)
+	s.respondJSON(w, http.StatusOK, result)
 }
 
 // handleGetTemperature returns temperature metrics for an agent over a time range.
@@ -362,26 +379,27 @@ func (s *Server) handleGetTemperature(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	bucket, agg, err := resolveBucket(r, start, end)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	var (
-		result any
-		err    error
-	)
-
-	if bucket := bucketInterval(start, end); bucket == "" {
+	var result any
+	if bucket == "" {
 		result, err = s.DB.GetTemperatureRange(r.Context(), database.GetTemperatureRangeParams{
 			AgentID: uid, StartTime: start, EndTime: end,
 		})
 	} else {
 		result, err = s.DB.GetTemperatureBucketed(r.Context(), database.GetTemperatureBucketedParams{
-			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket,
+			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket, Aggregate: agg,
 		})
 	}
 	if err != nil {
-		s.dbError(w, err, "handleGetTemperature")
+		s.dbError(w, r, err, "handleGetTemperature")
 		return
 	}
-	respondJSON(w, http.StatusOK, result)
+	s.respondJSON(w, http.StatusOK, result)
 }
 
 // handleGetSystem returns system metrics for an agent over a time range.
@@ -390,26 +408,27 @@ func (s *Server) handleGetSystem(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	bucket, agg, err := resolveBucket(r, start, end)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	var (
-		result any
-		err    error
-	)
-
-	if bucket := bucketInterval(start, end); bucket == "" {
+	var result any
+	if bucket == "" {
 		result, err = s.DB.GetSystemRange(r.Context(), database.GetSystemRangeParams{
 			AgentID: uid, StartTime: start, EndTime: end,
 		})
 	} else {
 		result, err = s.DB.GetSystemBucketed(r.Context(), database.GetSystemBucketedParams{
-			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket,
+			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket, Aggregate: agg,
 		})
 	}
 	if err != nil {
-		s.dbError(w, err, "handleGetSystem")
+		s.dbError(w, r, err, "handleGetSystem")
 		return
 	}
-	respondJSON(w, http.StatusOK, result)
+	s.respondJSON(w, http.StatusOK, result)
 }
 
 // handleGetContainers returns container metrics for an agent over a time range.
@@ -418,26 +437,27 @@ func (s *Server) handleGetContainers(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	bucket, agg, err := resolveBucket(r, start, end)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	var (
-		result any
-		err    error
-	)
-
-	if bucket := bucketInterval(start, end); bucket == "" {
+	var result any
+	if bucket == "" {
 		result, err = s.DB.GetContainerRange(r.Context(), database.GetContainerRangeParams{
 			AgentID: uid, StartTime: start, EndTime: end,
 		})
 	} else {
 		result, err = s.DB.GetContainerBucketed(r.Context(), database.GetContainerBucketedParams{
-			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket,
+			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket, Aggregate: agg,
 		})
 	}
 	if err != nil {
-		s.dbError(w, err, "handleGetContainers")
+		s.dbError(w, r, err, "handleGetContainers")
 		return
 	}
-	respondJSON(w, http.StatusOK, result)
+	s.respondJSON(w, http.StatusOK, result)
 }
 
 // handleGetWifi returns WiFi metrics for an agent over a time range.
@@ -446,26 +466,27 @@ func (s *Server) handleGetWifi(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	bucket, agg, err := resolveBucket(r, start, end)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	var (
-		result any
-		err    error
-	)
-
-	if bucket := bucketInterval(start, end); bucket == "" {
+	var result any
+	if bucket == "" {
 		result, err = s.DB.GetWifiRange(r.Context(), database.GetWifiRangeParams{
 			AgentID: uid, StartTime: start, EndTime: end,
 		})
 	} else {
 		result, err = s.DB.GetWifiBucketed(r.Context(), database.GetWifiBucketedParams{
-			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket,
+			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket, Aggregate: agg,
 		})
 	}
 	if err != nil {
-		s.dbError(w, err, "handleGetWifi")
+		s.dbError(w, r, err, "handleGetWifi")
 		return
 	}
-	respondJSON(w, http.StatusOK, result)
+	s.respondJSON(w, http.StatusOK, result)
 }
 
 // handleGetPi returns Raspberry Pi metrics for an agent over a time range.
@@ -474,26 +495,27 @@ func (s *Server) handleGetPi(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	bucket, agg, err := resolveBucket(r, start, end)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	var (
-		result any
-		err    error
-	)
-
-	if bucket := bucketInterval(start, end); bucket == "" {
+	var result any
+	if bucket == "" {
 		result, err = s.DB.GetPiRange(r.Context(), database.GetPiRangeParams{
 			AgentID: uid, StartTime: start, EndTime: end,
 		})
 	} else {
 		result, err = s.DB.GetPiBucketed(r.Context(), database.GetPiBucketedParams{
-			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket,
+			AgentID: uid, StartTime: start, EndTime: end, BucketInterval: bucket, Aggregate: agg,
 		})
 	}
 	if err != nil {
-		s.dbError(w, err, "handleGetPi")
+		s.dbError(w, r, err, "handleGetPi")
 		return
 	}
-	respondJSON(w, http.StatusOK, result)
+	s.respondJSON(w, http.StatusOK, result)
 }
 
 // handleGetProcesses returns the top processes for an agent, sorted by CPU or memory.
@@ -513,7 +535,7 @@ func (s *Server) handleGetProcesses(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		if limit < 1 || limit > 100 {
-			s.Logger.Warn("invalid limit", "limit", limit, "handler", "handleGetProcesses")
+			s.Logger.WarnContext(r.Context(), "invalid limit", "limit", limit, "handler", "handleGetProcesses")
 			http.Error(w, "limit must be between 1 and 100", http.StatusBadRequest)
 			return
 		}
@@ -537,11 +559,11 @@ func (s *Server) handleGetProcesses(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		s.dbError(w, err, "handleGetProcesses")
+		s.dbError(w, r, err, "handleGetProcesses")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, rows)
+	s.respondJSON(w, http.StatusOK, rows)
 }
 
 // handleGetServices returns the current services for an agent.
@@ -554,11 +576,11 @@ func (s *Server) handleGetServices(w http.ResponseWriter, r *http.Request) {
 
 	rows, err := s.DB.GetServices(r.Context(), mustUUID(agentID))
 	if err != nil {
-		s.dbError(w, err, "handleGetServices")
+		s.dbError(w, r, err, "handleGetServices")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, rows)
+	s.respondJSON(w, http.StatusOK, rows)
 }
 
 // handleGetApplications returns the installed applications for an agent.
@@ -571,11 +593,44 @@ func (s *Server) handleGetApplications(w http.ResponseWriter, r *http.Request) {
 
 	rows, err := s.DB.GetApplications(r.Context(), mustUUID(agentID))
 	if err != nil {
-		s.dbError(w, err, "handleGetApplications")
+		s.dbError(w, r, err, "handleGetApplications")
+		return
+	}
+
+	s.respondJSON(w, http.StatusOK, rows)
+}
+
+// inventoryItem is a current_inventory row with its snapshot inlined as JSON.
+type inventoryItem struct {
+	Kind      string          `json:"kind"`
+	Data      json.RawMessage `json:"data"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// handleGetInventory returns the latest hardware inventory snapshots for an agent.
+func (s *Server) handleGetInventory(w http.ResponseWriter, r *http.Request) {
+	agentID, err := parsePathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	respondJSON(w, http.StatusOK, rows)
+	rows, err := s.DB.GetInventory(r.Context(), mustUUID(agentID))
+	if err != nil {
+		s.dbError(w, r, err, "handleGetInventory")
+		return
+	}
+
+	items := make([]inventoryItem, len(rows))
+	for i, row := range rows {
+		items[i] = inventoryItem{
+			Kind:      row.Kind,
+			Data:      json.RawMessage(row.Data),
+			UpdatedAt: row.UpdatedAt.Time,
+		}
+	}
+
+	s.respondJSON(w, http.StatusOK, items)
 }
 
 // handleGetUpdates returns the current update status for an agent.
@@ -588,22 +643,22 @@ func (s *Server) handleGetUpdates(w http.ResponseWriter, r *http.Request) {
 
 	row, err := s.DB.GetUpdates(r.Context(), mustUUID(agentID))
 	if err != nil {
-		s.dbError(w, err, "handleGetUpdates")
+		s.dbError(w, r, err, "handleGetUpdates")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, row)
+	s.respondJSON(w, http.StatusOK, row)
 }
 
 // handleListAgents returns the agents registered to the server.
 func (s *Server) handleListAgents(w http.ResponseWriter, r *http.Request) {
 	rows, err := s.DB.ListAgents(r.Context())
 	if err != nil {
-		s.dbError(w, err, "handleListAgents")
+		s.dbError(w, r, err, "handleListAgents")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, rows)
+	s.respondJSON(w, http.StatusOK, rows)
 }
 
 func (s *Server) handleGetLatestSystem(w http.ResponseWriter, r *http.Request) {
@@ -614,11 +669,11 @@ func (s *Server) handleGetLatestSystem(w http.ResponseWriter, r *http.Request) {
 	}
 	row, err := s.DB.GetLatestSystem(r.Context(), mustUUID(agentID))
 	if err != nil {
-		s.dbError(w, err, "handleGetLatestSystem")
+		s.dbError(w, r, err, "handleGetLatestSystem")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, row)
+	s.respondJSON(w, http.StatusOK, row)
 }
 
 func (s *Server) parseRangeRequest(w http.ResponseWriter, r *http.Request) (pgtype.UUID, pgtype.Timestamptz, pgtype.Timestamptz, bool) {
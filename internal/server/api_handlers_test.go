@@ -11,6 +11,7 @@ import (
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/nhdewitt/spectra/internal/database"
+	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
 const testUUID = "550e8400-e29b-41d4-a716-446655440000"
@@ -556,6 +557,34 @@ func TestHandleGetApplications_Success(t *testing.T) {
 	}
 }
 
+func TestHandleGetInventory_Success(t *testing.T) {
+	s, _, _, mock := newTestServer()
+	setupTestSession(mock)
+	mock.Inventory = map[string]map[string][]byte{
+		testUUID: {"usb": []byte(`[{"port":"1-1","vendor_id":"046d","product_id":"c52b"}]`)},
+	}
+
+	req := authedRequest(httptest.NewRequest(http.MethodGet, "/api/v1/agents/"+testUUID+"/inventory", nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want 200", rec.Code)
+	}
+
+	var items []struct {
+		Kind string                     `json:"kind"`
+		Data []protocol.USBDeviceMetric `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &items); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(items) != 1 || items[0].Kind != "usb" || len(items[0].Data) != 1 || items[0].Data[0].ProductID != "c52b" {
+		t.Errorf("got %+v, want usb snapshot with one c52b device", items)
+	}
+}
+
 func TestHandleGetUpdates_Success(t *testing.T) {
 	s, _, _, mock := newTestServer()
 	setupTestSession(mock)
@@ -706,6 +735,34 @@ func TestParseTimeRange_FutureEndClamped(t *testing.T) {
 	}
 }
 
+func TestParseTimeRange_BeyondRetentionIsEmpty(t *testing.T) {
+	s := time.Now().AddDate(0, 0, -50).Format(time.RFC3339)
+	e := time.Now().AddDate(0, 0, -40).Format(time.RFC3339)
+
+	req := httptest.NewRequest(http.MethodGet, "/?start="+s+"&end="+e, nil)
+	start, end, err := parseTimeRange(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !start.Time.Equal(end.Time) {
+		t.Errorf("range outside retention should collapse to zero width, got %v..%v", start.Time, end.Time)
+	}
+}
+
+func TestParseTimeRange_FutureWindowIsEmpty(t *testing.T) {
+	s := time.Now().Add(time.Hour).Format(time.RFC3339)
+	e := time.Now().Add(2 * time.Hour).Format(time.RFC3339)
+
+	req := httptest.NewRequest(http.MethodGet, "/?start="+s+"&end="+e, nil)
+	start, end, err := parseTimeRange(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !start.Time.Equal(end.Time) {
+		t.Errorf("future range should collapse to zero width, got %v..%v", start.Time, end.Time)
+	}
+}
+
 func TestCurrentStateHandlers(t *testing.T) {
 	agentID := "00000000-0000-0000-0000-000000000001"
 
@@ -71,10 +71,10 @@ func (s *Server) requireUserAuth(next http.HandlerFunc) http.HandlerFunc {
 		// Verify IP
 		if session.IpAddress != clientIP(r) {
 			if err := s.DB.DeleteSession(r.Context(), cookie.Value); err != nil {
-				s.Logger.Error("failed to delete session", "error", err)
+				s.Logger.ErrorContext(r.Context(), "failed to delete session", "error", err)
 			}
 			clearSessionCookie(w)
-			s.Logger.Warn("session invalidated: IP mismatch",
+			s.Logger.WarnContext(r.Context(), "session invalidated: IP mismatch",
 				"username", session.Username,
 				"session_ip", session.IpAddress,
 				"request_ip", clientIP(r),
@@ -99,7 +99,7 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	ip := clientIP(r)
 
 	if err := s.LoginTracker.check(ip); err != nil {
-		s.Logger.Warn("login locked out", "ip", ip)
+		s.Logger.WarnContext(r.Context(), "login locked out", "ip", ip)
 		http.Error(w, err.Error(), http.StatusTooManyRequests)
 		return
 	}
@@ -129,13 +129,13 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
 		s.LoginTracker.recordFailure(ip)
-		s.Logger.Warn("login failed", "username", req.Username, "ip", ip)
+		s.Logger.WarnContext(r.Context(), "login failed", "username", req.Username, "ip", ip)
 		http.Error(w, "invalid credentials", http.StatusUnauthorized)
 		return
 	}
 
 	s.LoginTracker.recordSuccess(ip)
-	s.Logger.Info("login successful", "username", user.Username, "ip", ip)
+	s.Logger.InfoContext(r.Context(), "login successful", "username", user.Username, "ip", ip)
 
 	// Session token
 	tokenBytes := make([]byte, sessionTokenBytes)
@@ -168,7 +168,7 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		SameSite: http.SameSiteStrictMode,
 	})
 
-	respondJSON(w, http.StatusOK, map[string]string{
+	s.respondJSON(w, http.StatusOK, map[string]string{
 		"username": user.Username,
 		"role":     user.Role,
 	})
@@ -183,7 +183,7 @@ func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.DB.DeleteSession(r.Context(), cookie.Value); err != nil {
-		s.Logger.Error("failed to delete session on logout", "error", err)
+		s.Logger.ErrorContext(r.Context(), "failed to delete session on logout", "error", err)
 	}
 	clearSessionCookie(w)
 	w.WriteHeader(http.StatusNoContent)
@@ -197,7 +197,7 @@ func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]string{
+	s.respondJSON(w, http.StatusOK, map[string]string{
 		"id":       u.ID,
 		"username": u.Username,
 		"role":     u.Role,
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
@@ -30,3 +32,49 @@ func bucketInterval(start, end pgtype.Timestamptz) string {
 		return "1 hour"
 	}
 }
+
+// resolutions maps ?resolution= values to time_bucket intervals.
+var resolutions = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"1h":  time.Hour,
+	"6h":  6 * time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+// maxBuckets caps how many buckets an explicit resolution may produce so
+// a fine resolution over a long range can't return more rows than the
+// automatic thresholds would.
+const maxBuckets = 1500
+
+// aggregates lists the per-bucket aggregators the bucketed queries accept.
+var aggregates = map[string]bool{"avg": true, "min": true, "max": true}
+
+// resolveBucket reads the optional ?resolution= and ?agg= parameters.
+// Without a resolution it falls back to bucketInterval, so existing
+// callers keep raw data for short ranges. The aggregator defaults to avg.
+func resolveBucket(r *http.Request, start, end pgtype.Timestamptz) (bucket, agg string, err error) {
+	q := r.URL.Query()
+
+	agg = q.Get("agg")
+	if agg == "" {
+		agg = "avg"
+	}
+	if !aggregates[agg] {
+		return "", "", fmt.Errorf("invalid agg %q, valid: avg, min, max", agg)
+	}
+
+	raw := q.Get("resolution")
+	if raw == "" {
+		return bucketInterval(start, end), agg, nil
+	}
+	d, ok := resolutions[raw]
+	if !ok {
+		return "", "", fmt.Errorf("invalid resolution %q, valid: 1m, 5m, 15m, 1h, 6h, 1d", raw)
+	}
+	if n := end.Time.Sub(start.Time) / d; n > maxBuckets {
+		return "", "", fmt.Errorf("resolution %s yields %d buckets for this range, max %d", raw, n, maxBuckets)
+	}
+	return fmt.Sprintf("%d seconds", int64(d/time.Second)), agg, nil
+}
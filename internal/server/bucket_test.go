@@ -0,0 +1,118 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestBucketInterval(t *testing.T) {
+	tests := []struct {
+		dur  time.Duration
+		want string
+	}{
+		{30 * time.Minute, ""},
+		{time.Hour, ""},
+		{time.Hour + time.Second, "1 minute"},
+		{6 * time.Hour, "1 minute"},
+		{24 * time.Hour, "5 minutes"},
+		{7 * 24 * time.Hour, "15 minutes"},
+		{30 * 24 * time.Hour, "1 hour"},
+	}
+	end := time.Now()
+	for _, tt := range tests {
+		got := bucketInterval(pgTimestamp(end.Add(-tt.dur)), pgTimestamp(end))
+		if got != tt.want {
+			t.Errorf("bucketInterval(%v) = %q, want %q", tt.dur, got, tt.want)
+		}
+	}
+}
+
+func TestResolveBucket(t *testing.T) {
+	end := time.Now()
+	rng := func(d time.Duration) (pgtype.Timestamptz, pgtype.Timestamptz) {
+		return pgTimestamp(end.Add(-d)), pgTimestamp(end)
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		dur        time.Duration
+		wantBucket string
+		wantAgg    string
+		wantErr    string
+	}{
+		{"defaults to raw for short range", "", 30 * time.Minute, "", "avg", ""},
+		{"defaults to automatic bucket", "", 24 * time.Hour, "5 minutes", "avg", ""},
+		{"explicit resolution on short range", "resolution=1m", 30 * time.Minute, "60 seconds", "avg", ""},
+		{"explicit coarse resolution", "resolution=1d&agg=max", 30 * 24 * time.Hour, "86400 seconds", "max", ""},
+		{"min aggregator", "resolution=5m&agg=min", 6 * time.Hour, "300 seconds", "min", ""},
+		{"agg without resolution", "agg=max", 7 * 24 * time.Hour, "15 minutes", "max", ""},
+		{"exactly max buckets", "resolution=1m", maxBuckets * time.Minute, "60 seconds", "avg", ""},
+		{"too many buckets", "resolution=1m", 7 * 24 * time.Hour, "", "", "max 1500"},
+		{"unknown resolution", "resolution=7m", time.Hour, "", "", "invalid resolution"},
+		{"unknown aggregator", "agg=median", time.Hour, "", "", "invalid agg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			start, stop := rng(tt.dur)
+			bucket, agg, err := resolveBucket(req, start, stop)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if bucket != tt.wantBucket {
+				t.Errorf("bucket = %q, want %q", bucket, tt.wantBucket)
+			}
+			if agg != tt.wantAgg {
+				t.Errorf("agg = %q, want %q", agg, tt.wantAgg)
+			}
+		})
+	}
+}
+
+func TestHandleGetCPU_ResolutionAndAggregate(t *testing.T) {
+	s, _, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	req := authedRequest(httptest.NewRequest(http.MethodGet,
+		"/api/v1/agents/"+testUUID+"/cpu?range=1h&resolution=5m&agg=max", nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want 200", rec.Code)
+	}
+	if got := mock.LastCPUBucketed.BucketInterval; got != "300 seconds" {
+		t.Errorf("BucketInterval: got %q, want 300 seconds", got)
+	}
+	if got := mock.LastCPUBucketed.Aggregate; got != "max" {
+		t.Errorf("Aggregate: got %q, want max", got)
+	}
+}
+
+func TestHandleGetCPU_InvalidResolution(t *testing.T) {
+	s, _, _, mock := newTestServer()
+	setupTestSession(mock)
+
+	req := authedRequest(httptest.NewRequest(http.MethodGet,
+		"/api/v1/agents/"+testUUID+"/cpu?range=1h&resolution=2m", nil))
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status: got %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// msgpackEnvelope mirrors RawEnvelope for MessagePack bodies, deferring
+// decoding of the metric payload until its type is known.
+type msgpackEnvelope struct {
+	Type          string             `json:"type"`
+	SchemaVersion int                `json:"schema_version"`
+	Timestamp     time.Time          `json:"timestamp"`
+	Hostname      string             `json:"hostname"`
+	Data          msgpack.RawMessage `json:"data"`
+}
+
+// maxPooledBatch caps the slices returned to envelopePool so one
+// oversized batch doesn't pin its memory for the life of the process.
+const maxPooledBatch = defaultMaxBatchSize
+
+// defaultMaxBatchSize is the number of envelopes a single metrics POST may
+// carry before it's rejected with 413. Agents flush every 100 envelopes,
+// so hitting this means a misbehaving or hostile client.
+const defaultMaxBatchSize = 1000
+
+// errBatchTooLarge is returned by decodeEnvelopes as soon as a batch is
+// found to hold more than the allowed number of envelopes, before the
+// rest of the body is read.
+var errBatchTooLarge = errors.New("too many envelopes in batch")
+
+// envelopePool reuses decoded batch slices. Slices are handed back with
+// releaseEnvelopes once every envelope has been processed.
+var envelopePool = sync.Pool{
+	New: func() any { return new([]RawEnvelope) },
+}
+
+// releaseEnvelopes returns a batch from decodeEnvelopes to the pool. The
+// entries are cleared first so pooled slices don't keep payloads alive,
+// and slices above maxPooledBatch are left to the GC.
+func releaseEnvelopes(envs []RawEnvelope) {
+	if envs == nil || cap(envs) > maxPooledBatch {
+		return
+	}
+	clear(envs[:cap(envs)])
+	envs = envs[:0]
+	envelopePool.Put(&envs)
+}
+
+// decodeEnvelopes decodes a metrics batch, dispatching on Content-Type.
+// JSON is assumed when the header is missing. Envelopes are counted as
+// they are decoded, and a batch of more than maxEnvelopes fails with
+// errBatchTooLarge without reading the remainder.
+func decodeEnvelopes(r *http.Request, maxEnvelopes int) ([]RawEnvelope, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != protocol.ContentTypeMsgpack {
+		return decodeJSONEnvelopes(r, maxEnvelopes)
+	}
+
+	reader, err := requestBody(r)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	dec := protocol.NewMsgpackDecoder(reader)
+	n, err := dec.DecodeArrayLen()
+	if err != nil {
+		return nil, fmt.Errorf("invalid msgpack: %w", err)
+	}
+	if n > maxEnvelopes {
+		return nil, fmt.Errorf("%w: %d, max %d", errBatchTooLarge, n, maxEnvelopes)
+	}
+	if n < 0 {
+		return nil, nil
+	}
+
+	envs := make([]RawEnvelope, n)
+	for i := range envs {
+		var p msgpackEnvelope
+		if err := dec.Decode(&p); err != nil {
+			return nil, fmt.Errorf("invalid msgpack: %w", err)
+		}
+		data, err := msgpackToJSON(p.Data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid msgpack data for %q: %w", p.Type, err)
+		}
+		envs[i] = RawEnvelope{
+			Type:          p.Type,
+			SchemaVersion: p.SchemaVersion,
+			Timestamp:     p.Timestamp,
+			Hostname:      p.Hostname,
+			Data:          data,
+		}
+	}
+
+	return envs, nil
+}
+
+// decodeJSONEnvelopes streams a JSON batch into a pooled slice one
+// envelope at a time, so the body is never held in memory whole.
+func decodeJSONEnvelopes(r *http.Request, maxEnvelopes int) ([]RawEnvelope, error) {
+	reader, err := requestBody(r)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	dec := json.NewDecoder(reader)
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("invalid json: %w", err)
+	}
+	if tok == nil {
+		return nil, nil
+	}
+	if tok != json.Delim('[') {
+		return nil, fmt.Errorf("invalid json: expected an array of envelopes")
+	}
+
+	envs := *envelopePool.Get().(*[]RawEnvelope)
+	for dec.More() {
+		if len(envs) == maxEnvelopes {
+			releaseEnvelopes(envs)
+			return nil, fmt.Errorf("%w: more than %d", errBatchTooLarge, maxEnvelopes)
+		}
+		envs = append(envs, RawEnvelope{})
+		if err := dec.Decode(&envs[len(envs)-1]); err != nil {
+			releaseEnvelopes(envs)
+			return nil, fmt.Errorf("invalid json: %w", err)
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		releaseEnvelopes(envs)
+		return nil, fmt.Errorf("invalid json: %w", err)
+	}
+	return envs, nil
+}
+
+// msgpackToJSON re-encodes a MessagePack value as JSON so it can go
+// through the same unmarshalMetric path as JSON bodies.
+func msgpackToJSON(raw msgpack.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var v any
+	if err := msgpack.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
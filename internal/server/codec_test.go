@@ -0,0 +1,208 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func mixedBatch() []protocol.Envelope {
+	ts := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	return []protocol.Envelope{
+		{Type: "cpu", Timestamp: ts, Hostname: "pi", Data: protocol.CPUMetric{
+			Usage: 42.5, CoreUsage: []float64{40, 45}, LoadAvg1: 0.5, FreqMHz: 1500, Governor: "ondemand",
+		}},
+		{Type: "memory", Timestamp: ts, Hostname: "pi", Data: protocol.MemoryMetric{
+			Total: 8 << 30, Used: 2 << 30, Available: 6 << 30, UsedPct: 25, Cached: 1 << 30,
+		}},
+		{Type: "process_list", Timestamp: ts, Hostname: "pi", Data: protocol.ProcessListMetric{
+			Processes: []protocol.ProcessMetric{{Pid: 1, Name: "init", CPUPercent: 0.1}},
+		}},
+		{Type: "collector_health", Timestamp: ts, Hostname: "pi", Data: protocol.CollectorHealthMetric{
+			Name: "wifi", LastSuccess: ts.Add(-time.Minute), ConsecutiveErrors: 2, LastError: "no device",
+		}},
+	}
+}
+
+func newMetricsRequest(t *testing.T, contentType string, encode func(*gzip.Writer) error) *http.Request {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if err := encode(gz); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/agent/metrics", &buf)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Content-Encoding", "gzip")
+	return req
+}
+
+func TestDecodeEnvelopes_MsgpackRoundTrip(t *testing.T) {
+	s := New(Config{Port: 8080}, NewMockDB())
+	batch := mixedBatch()
+
+	packed, err := decodeEnvelopes(newMetricsRequest(t, protocol.ContentTypeMsgpack, func(w *gzip.Writer) error {
+		return protocol.NewMsgpackEncoder(w).Encode(batch)
+	}), defaultMaxBatchSize)
+	if err != nil {
+		t.Fatalf("decode msgpack: %v", err)
+	}
+	plain, err := decodeEnvelopes(newMetricsRequest(t, protocol.ContentTypeJSON, func(w *gzip.Writer) error {
+		return json.NewEncoder(w).Encode(batch)
+	}), defaultMaxBatchSize)
+	if err != nil {
+		t.Fatalf("decode json: %v", err)
+	}
+
+	if len(packed) != len(batch) || len(plain) != len(batch) {
+		t.Fatalf("envelope count: msgpack %d, json %d, want %d", len(packed), len(plain), len(batch))
+	}
+
+	for i, want := range batch {
+		got := packed[i]
+		if got.Type != want.Type || got.Hostname != want.Hostname || !got.Timestamp.Equal(want.Timestamp) {
+			t.Errorf("envelope %d header: got %s/%s/%v, want %s/%s/%v",
+				i, got.Type, got.Hostname, got.Timestamp, want.Type, want.Hostname, want.Timestamp)
+		}
+
+		fromPack, err := s.unmarshalMetric(got.Type, got.Data)
+		if err != nil {
+			t.Fatalf("unmarshal msgpack %s: %v", got.Type, err)
+		}
+		fromJSON, err := s.unmarshalMetric(plain[i].Type, plain[i].Data)
+		if err != nil {
+			t.Fatalf("unmarshal json %s: %v", plain[i].Type, err)
+		}
+
+		// Times may come back in a different location, so compare those by instant
+		if h, ok := fromPack.(*protocol.CollectorHealthMetric); ok {
+			wantH := want.Data.(protocol.CollectorHealthMetric)
+			if !h.LastSuccess.Equal(wantH.LastSuccess) {
+				t.Errorf("LastSuccess: got %v, want %v", h.LastSuccess, wantH.LastSuccess)
+			}
+			h.LastSuccess = wantH.LastSuccess
+			fromJSON.(*protocol.CollectorHealthMetric).LastSuccess = wantH.LastSuccess
+		}
+
+		if !reflect.DeepEqual(fromPack, fromJSON) {
+			t.Errorf("%s: msgpack and json decode differ:\n msgpack: %+v\n json:    %+v", got.Type, fromPack, fromJSON)
+		}
+		if gotVal := reflect.ValueOf(fromPack).Elem().Interface(); !reflect.DeepEqual(gotVal, want.Data) {
+			t.Errorf("%s: got %+v, want %+v", got.Type, gotVal, want.Data)
+		}
+	}
+}
+
+func TestDecodeEnvelopes_InvalidMsgpack(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/agent/metrics", bytes.NewReader([]byte{0xc1}))
+	req.Header.Set("Content-Type", protocol.ContentTypeMsgpack)
+
+	if _, err := decodeEnvelopes(req, defaultMaxBatchSize); err == nil {
+		t.Error("expected error for invalid msgpack body")
+	}
+}
+
+func BenchmarkDecodeEnvelopes_JSON(b *testing.B) {
+	batch := make([]protocol.Envelope, 0, 50)
+	for range 50 {
+		batch = append(batch, mixedBatch()...)
+	}
+	body, _ := json.Marshal(batch)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/agent/metrics", bytes.NewReader(body))
+		req.Header.Set("Content-Type", protocol.ContentTypeJSON)
+
+		envs, err := decodeEnvelopes(req, defaultMaxBatchSize)
+		if err != nil {
+			b.Fatalf("decode: %v", err)
+		}
+		releaseEnvelopes(envs)
+	}
+}
+
+func TestReleaseEnvelopes_DropsOversized(t *testing.T) {
+	releaseEnvelopes(make([]RawEnvelope, 1, maxPooledBatch+1))
+
+	for range 10 {
+		if envs := *envelopePool.Get().(*[]RawEnvelope); cap(envs) > maxPooledBatch {
+			t.Fatalf("pool handed back a slice of cap %d, above %d", cap(envs), maxPooledBatch)
+		}
+	}
+}
+
+// endlessBatch is a JSON array body that never ends, so a decoder that
+// read the whole body first would never return.
+type endlessBatch struct{ started bool }
+
+func (b *endlessBatch) Read(p []byte) (int, error) {
+	elem := `{"type":"cpu","hostname":"h","data":{"usage":1}},`
+	n := 0
+	if !b.started {
+		b.started = true
+		n = copy(p, "[")
+	}
+	for n+len(elem) <= len(p) {
+		n += copy(p[n:], elem)
+	}
+	return n, nil
+}
+
+func TestDecodeEnvelopes_StopsAtMax(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/agent/metrics", &endlessBatch{})
+	req.Header.Set("Content-Type", protocol.ContentTypeJSON)
+
+	envs, err := decodeEnvelopes(req, 5)
+	if !errors.Is(err, errBatchTooLarge) {
+		t.Fatalf("got %v, want errBatchTooLarge", err)
+	}
+	if envs != nil {
+		t.Errorf("expected no envelopes, got %d", len(envs))
+	}
+}
+
+func TestDecodeEnvelopes_MsgpackOverMax(t *testing.T) {
+	batch := mixedBatch()
+	req := newMetricsRequest(t, protocol.ContentTypeMsgpack, func(w *gzip.Writer) error {
+		return protocol.NewMsgpackEncoder(w).Encode(batch)
+	})
+
+	if _, err := decodeEnvelopes(req, len(batch)-1); !errors.Is(err, errBatchTooLarge) {
+		t.Errorf("got %v, want errBatchTooLarge", err)
+	}
+}
+
+func TestDecodeEnvelopes_AtMax(t *testing.T) {
+	batch := mixedBatch()
+	for _, ct := range []string{protocol.ContentTypeJSON, protocol.ContentTypeMsgpack} {
+		req := newMetricsRequest(t, ct, func(w *gzip.Writer) error {
+			if ct == protocol.ContentTypeMsgpack {
+				return protocol.NewMsgpackEncoder(w).Encode(batch)
+			}
+			return json.NewEncoder(w).Encode(batch)
+		})
+
+		envs, err := decodeEnvelopes(req, len(batch))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", ct, err)
+		}
+		if len(envs) != len(batch) {
+			t.Errorf("%s: got %d envelopes, want %d", ct, len(envs), len(batch))
+		}
+	}
+}
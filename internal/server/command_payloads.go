@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// The check* functions hold the payload rules for commands that can be
+// queued both by a single-agent trigger and by broadcast, so the two
+// paths can't drift. Each normalizes defaults in place and returns an
+// error suitable for a 400 response.
+
+// defaultDiskTopN is the number of largest entries a disk scan reports
+// when the request doesn't say.
+const defaultDiskTopN = 20
+
+func checkLogRequest(req *protocol.LogRequest) error {
+	if !isValidLogLevel(req.MinLevel) {
+		req.MinLevel = protocol.LevelWarning
+	}
+	if req.MaxBytes < 0 {
+		return errors.New("invalid max_bytes")
+	}
+	return nil
+}
+
+func checkDiskUsageRequest(req *protocol.DiskUsageRequest) error {
+	if req.TopN <= 0 {
+		req.TopN = defaultDiskTopN
+	}
+	return nil
+}
+
+func checkNetworkRequest(req *protocol.NetworkRequest) error {
+	if req.Action == "" {
+		return errors.New("Action required")
+	}
+	return nil
+}
+
+func checkCertCheckRequest(req *protocol.CertCheckRequest) error {
+	if _, _, err := net.SplitHostPort(req.Target); err != nil {
+		return errors.New("target must be host:port")
+	}
+	if req.TimeoutSeconds < 0 || req.TimeoutSeconds > maxCertDialSeconds {
+		return fmt.Errorf("dial_timeout must be 1-%d seconds", maxCertDialSeconds)
+	}
+	return nil
+}
+
+func checkIOStatRequest(req *protocol.IOStatRequest) error {
+	if req.IntervalSeconds < 0 || req.IntervalSeconds > maxIOStatSeconds {
+		return fmt.Errorf("interval must be 1-%d seconds", maxIOStatSeconds)
+	}
+	return nil
+}
+
+func checkTopProcessesRequest(req *protocol.TopProcessesRequest) error {
+	if req.SortBy != "" && req.SortBy != protocol.SortByCPU && req.SortBy != protocol.SortByMemory {
+		return errors.New("sort must be cpu or memory")
+	}
+	if req.Limit < 0 {
+		return errors.New("invalid limit")
+	}
+	return nil
+}
+
+// checkBroadcastPayload decodes a broadcast payload as cmdType's request,
+// applies the same checks as its single-agent trigger and returns the
+// payload to queue. Commands that take no payload get nil.
+func checkBroadcastPayload(cmdType protocol.CommandType, payload json.RawMessage) ([]byte, error) {
+	switch cmdType {
+	case protocol.CmdFetchLogs:
+		return decodeChecked(payload, checkLogRequest)
+	case protocol.CmdDiskUsage:
+		return decodeChecked(payload, checkDiskUsageRequest)
+	case protocol.CmdNetworkDiag:
+		return decodeChecked(payload, checkNetworkRequest)
+	case protocol.CmdCertCheck:
+		return decodeChecked(payload, checkCertCheckRequest)
+	case protocol.CmdIOStat:
+		return decodeChecked(payload, checkIOStatRequest)
+	case protocol.CmdTopProcesses:
+		return decodeChecked(payload, checkTopProcessesRequest)
+	default:
+		return nil, nil
+	}
+}
+
+// decodeChecked strictly decodes payload into T (empty or null decodes to
+// the zero value), runs check on it and re-encodes the result.
+func decodeChecked[T any](payload json.RawMessage, check func(*T) error) ([]byte, error) {
+	var req T
+	if len(payload) > 0 && string(payload) != "null" {
+		dec := json.NewDecoder(bytes.NewReader(payload))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			return nil, fmt.Errorf("invalid payload: %w", err)
+		}
+	}
+	if err := check(&req); err != nil {
+		return nil, err
+	}
+	return json.Marshal(req)
+}
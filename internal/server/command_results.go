@@ -1,6 +1,7 @@
 package server
 
 import (
+	"slices"
 	"sync"
 	"time"
 
@@ -15,8 +16,14 @@ type commandEntry struct {
 	QueuedAt time.Time               `json:"queued_at"`
 	Result   *protocol.CommandResult `json:"result,omitempty"`
 	Done     bool                    `json:"done"`
+	// Partials holds the intermediate results of a streaming command,
+	// oldest first, capped at maxPartialResults.
+	Partials []protocol.CommandResult `json:"partials,omitempty"`
 }
 
+// maxPartialResults bounds the partial results kept per streaming command.
+const maxPartialResults = 100
+
 // commandResultStore holds in-flight and completed command results with TTL cleanup.
 type commandResultStore struct {
 	mu      sync.Mutex
@@ -47,6 +54,13 @@ func (s *commandResultStore) Track(id string, cmdType protocol.CommandType, agen
 	}
 }
 
+// Forget drops a tracked command, e.g. one that never made it into the queue.
+func (s *commandResultStore) Forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, id)
+}
+
 // Complete stores the result for a tracked command.
 func (s *commandResultStore) Complete(id string, result protocol.CommandResult) {
 	s.mu.Lock()
@@ -57,7 +71,22 @@ func (s *commandResultStore) Complete(id string, result protocol.CommandResult)
 	}
 }
 
-// Get returns the current state of a command.
+// Append stores an intermediate result for a tracked streaming command,
+// dropping the oldest once maxPartialResults is reached.
+func (s *commandResultStore) Append(id string, result protocol.CommandResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[id]
+	if !ok || entry.Done {
+		return
+	}
+	if len(entry.Partials) >= maxPartialResults {
+		entry.Partials = entry.Partials[1:]
+	}
+	entry.Partials = append(entry.Partials, result)
+}
+
+// Get returns a snapshot of the current state of a command.
 func (s *commandResultStore) Get(id string) (*commandEntry, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -65,7 +94,9 @@ func (s *commandResultStore) Get(id string) (*commandEntry, bool) {
 	if !ok {
 		return nil, false
 	}
-	return entry, true
+	snapshot := *entry
+	snapshot.Partials = slices.Clone(entry.Partials)
+	return &snapshot, true
 }
 
 // cleanup removes entries older than TTL.
@@ -19,7 +19,7 @@ func (s *Server) handleGetAgentConfig(w http.ResponseWriter, r *http.Request) {
 
 	rows, err := s.DB.GetAgentConfig(r.Context(), mustUUID(agentID))
 	if err != nil {
-		s.Logger.Error("database query failed", "error", err, "handler", "handleGetAgentConfig")
+		s.Logger.ErrorContext(r.Context(), "database query failed", "error", err, "handler", "handleGetAgentConfig")
 		http.Error(w, "database error", http.StatusInternalServerError)
 		return
 	}
@@ -29,7 +29,7 @@ func (s *Server) handleGetAgentConfig(w http.ResponseWriter, r *http.Request) {
 		config[row.ConfigKey] = row.ConfigValue
 	}
 
-	respondJSON(w, http.StatusOK, config)
+	s.respondJSON(w, http.StatusOK, config)
 }
 
 // handleSetAgentConfig sets a single config key for an agent.
@@ -72,12 +72,12 @@ func (s *Server) handleSetAgentConfig(w http.ResponseWriter, r *http.Request) {
 		ConfigKey:   req.Key,
 		ConfigValue: req.Value,
 	}); err != nil {
-		s.Logger.Error("database query failed", "error", err, "handler", "handleSetAgentConfig")
+		s.Logger.ErrorContext(r.Context(), "database query failed", "error", err, "handler", "handleSetAgentConfig")
 		http.Error(w, "database error", http.StatusInternalServerError)
 		return
 	}
 
-	s.Logger.Info("agent config updated", "agent_id", agentID, "key", req.Key)
+	s.Logger.InfoContext(r.Context(), "agent config updated", "agent_id", agentID, "key", req.Key)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -101,12 +101,12 @@ func (s *Server) handleDeleteAgentConfig(w http.ResponseWriter, r *http.Request)
 		AgentID:   mustUUID(agentID),
 		ConfigKey: key,
 	}); err != nil {
-		s.Logger.Error("database query failed", "error", err, "handler", "handleDeleteAgentConfig")
+		s.Logger.ErrorContext(r.Context(), "database query failed", "error", err, "handler", "handleDeleteAgentConfig")
 		http.Error(w, "database error", http.StatusInternalServerError)
 		return
 	}
 
-	s.Logger.Info("agent config deleted", "agent_id", agentID, "key", key)
+	s.Logger.InfoContext(r.Context(), "agent config deleted", "agent_id", agentID, "key", key)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -131,7 +131,7 @@ func (s *Server) handleGetAgentSelfConfig(w http.ResponseWriter, r *http.Request
 
 	rows, err := s.DB.GetAgentConfig(r.Context(), mustUUID(agentID))
 	if err != nil {
-		s.Logger.Error("database query failed", "error", err, "handler", "handleGetAgentSelfConfig")
+		s.Logger.ErrorContext(r.Context(), "database query failed", "error", err, "handler", "handleGetAgentSelfConfig")
 		http.Error(w, "database error", http.StatusInternalServerError)
 		return
 	}
@@ -141,5 +141,5 @@ func (s *Server) handleGetAgentSelfConfig(w http.ResponseWriter, r *http.Request
 		config[row.ConfigKey] = row.ConfigValue
 	}
 
-	respondJSON(w, http.StatusOK, config)
+	s.respondJSON(w, http.StatusOK, config)
 }
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// CORSConfig controls cross-origin access to the dashboard API. CORS is
+// off while AllowedOrigins is empty.
+type CORSConfig struct {
+	AllowedOrigins []string // exact origins, e.g. "https://grafana.example.com"
+	AllowedMethods []string // defaults to GET, HEAD
+	AllowedHeaders []string // defaults to Content-Type, X-Request-ID
+}
+
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodHead}
+	defaultCORSHeaders = []string{"Content-Type", requestIDHeader}
+)
+
+// corsMaxAge is how long browsers may cache a preflight answer, in seconds.
+const corsMaxAge = "600"
+
+// corsEligible reports whether path is a dashboard API route. Agent
+// ingestion and command polling never serve browsers, so they're excluded.
+func corsEligible(path string) bool {
+	return strings.HasPrefix(path, "/api/v1/") && !strings.HasPrefix(path, "/api/v1/agent/")
+}
+
+// corsMiddleware adds CORS headers for allowed origins and answers
+// preflight requests. Sessions are cookie-based, so credentials are
+// allowed and the origin is echoed rather than answered with "*".
+func corsMiddleware(cfg CORSConfig, next http.Handler) http.Handler {
+	if len(cfg.AllowedOrigins) == 0 {
+		return next
+	}
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !corsEligible(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		allowed := slices.Contains(cfg.AllowedOrigins, origin)
+
+		if preflight {
+			if !allowed || !slices.Contains(methods, r.Header.Get("Access-Control-Request-Method")) {
+				http.Error(w, "CORS request not allowed", http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Set("Access-Control-Allow-Methods", allowMethods)
+			w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+			w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if allowed && slices.Contains(methods, r.Method) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Set("Access-Control-Expose-Headers", requestIDHeader)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
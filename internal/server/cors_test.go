@@ -0,0 +1,119 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func corsTestHandler(cfg CORSConfig) http.Handler {
+	return corsMiddleware(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+var testCORS = CORSConfig{AllowedOrigins: []string{"https://dash.example.com"}}
+
+func preflight(path, origin, method string) *http.Request {
+	req := httptest.NewRequest(http.MethodOptions, path, nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", method)
+	return req
+}
+
+func TestCORS_AllowedPreflight(t *testing.T) {
+	rec := httptest.NewRecorder()
+	corsTestHandler(testCORS).ServeHTTP(rec, preflight("/api/v1/overview", "https://dash.example.com", http.MethodGet))
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status: got %d, want 204", rec.Code)
+	}
+	h := rec.Header()
+	if got := h.Get("Access-Control-Allow-Origin"); got != "https://dash.example.com" {
+		t.Errorf("Allow-Origin: got %q", got)
+	}
+	if got := h.Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Allow-Credentials: got %q", got)
+	}
+	if got := h.Get("Access-Control-Allow-Methods"); got != "GET, HEAD" {
+		t.Errorf("Allow-Methods: got %q", got)
+	}
+	if got := h.Get("Access-Control-Allow-Headers"); got != "Content-Type, X-Request-ID" {
+		t.Errorf("Allow-Headers: got %q", got)
+	}
+	if got := h.Get("Vary"); got != "Origin" {
+		t.Errorf("Vary: got %q", got)
+	}
+}
+
+func TestCORS_DisallowedOrigin(t *testing.T) {
+	h := corsTestHandler(testCORS)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, preflight("/api/v1/overview", "https://evil.example.com", http.MethodGet))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("preflight status: got %d, want 403", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("preflight Allow-Origin should be unset, got %q", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/overview", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Allow-Origin should be unset, got %q", got)
+	}
+}
+
+func TestCORS_DisallowedMethod(t *testing.T) {
+	rec := httptest.NewRecorder()
+	corsTestHandler(testCORS).ServeHTTP(rec, preflight("/api/v1/admin/users", "https://dash.example.com", http.MethodDelete))
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status: got %d, want 403", rec.Code)
+	}
+}
+
+func TestCORS_AllowedSimpleRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/agents", nil)
+	req.Header.Set("Origin", "https://dash.example.com")
+	rec := httptest.NewRecorder()
+
+	corsTestHandler(testCORS).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://dash.example.com" {
+		t.Errorf("Allow-Origin: got %q", got)
+	}
+	if got := rec.Header().Get("Access-Control-Expose-Headers"); got != requestIDHeader {
+		t.Errorf("Expose-Headers: got %q", got)
+	}
+}
+
+func TestCORS_IngestionExcluded(t *testing.T) {
+	rec := httptest.NewRecorder()
+	corsTestHandler(testCORS).ServeHTTP(rec, preflight("/api/v1/agent/metrics", "https://dash.example.com", http.MethodGet))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status: got %d, want request passed through", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Allow-Origin should be unset on ingestion, got %q", got)
+	}
+}
+
+func TestCORS_DisabledByDefault(t *testing.T) {
+	rec := httptest.NewRecorder()
+	corsTestHandler(CORSConfig{}).ServeHTTP(rec, preflight("/api/v1/overview", "https://dash.example.com", http.MethodGet))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status: got %d, want request passed through", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Allow-Origin should be unset, got %q", got)
+	}
+}
@@ -10,6 +10,9 @@ import (
 // DB defines the database operations the server depends on.
 // Implemented by *database.Queries for production and MockDB for tests.
 type DB interface {
+	// Health
+	Ping(ctx context.Context) (bool, error)
+
 	// Agent management
 	RegisterAgent(ctx context.Context, arg database.RegisterAgentParams) error
 	GetAgentSecret(ctx context.Context, id pgtype.UUID) (string, error)
@@ -44,6 +47,7 @@ type DB interface {
 	DeleteStaleProcesses(ctx context.Context, arg database.DeleteStaleProcessesParams) error
 	UpsertService(ctx context.Context, arg database.UpsertServiceParams) error
 	UpsertApplication(ctx context.Context, arg database.UpsertApplicationParams) error
+	UpsertInventory(ctx context.Context, arg database.UpsertInventoryParams) error
 	UpsertUpdates(ctx context.Context, arg database.UpsertUpdatesParams) error
 	UpsertCurrentCPU(ctx context.Context, arg database.UpsertCurrentCPUParams) error
 	UpsertCurrentMemory(ctx context.Context, arg database.UpsertCurrentMemoryParams) error
@@ -88,6 +92,7 @@ type DB interface {
 	GetProcessesByMemory(ctx context.Context, args database.GetProcessesByMemoryParams) ([]database.CurrentProcess, error)
 	GetServices(ctx context.Context, id pgtype.UUID) ([]database.CurrentService, error)
 	GetApplications(ctx context.Context, id pgtype.UUID) ([]database.CurrentApplication, error)
+	GetInventory(ctx context.Context, id pgtype.UUID) ([]database.CurrentInventory, error)
 	GetUpdates(ctx context.Context, id pgtype.UUID) (database.CurrentUpdate, error)
 	GetLatestSystem(ctx context.Context, id pgtype.UUID) (database.MetricsSystem, error)
 
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/export"
+	"github.com/nhdewitt/spectra/internal/logging"
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// metricExporter forwards ingested metrics to an external system. Export
+// must not block ingestion.
+type metricExporter interface {
+	Export(host string, ts time.Time, m protocol.Metric)
+}
+
+// exportItem is the queued form of one metric.
+type exportItem struct {
+	Host    string
+	Time    time.Time
+	Samples []export.Sample
+}
+
+const (
+	exportQueueSize     = 4096
+	exportMaxBatch      = 500
+	exportFlushInterval = 10 * time.Second
+	exportFlushTimeout  = 10 * time.Second
+)
+
+// batchExporter queues samples and hands them to flush in batches, every
+// interval or whenever maxBatch items are waiting. When the queue is full
+// new items are dropped so a slow backend never stalls ingestion.
+type batchExporter struct {
+	name     string
+	flush    func(ctx context.Context, items []exportItem) error
+	queue    chan exportItem
+	interval time.Duration
+	maxBatch int
+	logger   *logging.Logger
+
+	dropped atomic.Int64
+}
+
+func newBatchExporter(name string, logger *logging.Logger, flush func(context.Context, []exportItem) error) *batchExporter {
+	return &batchExporter{
+		name:     name,
+		flush:    flush,
+		queue:    make(chan exportItem, exportQueueSize),
+		interval: exportFlushInterval,
+		maxBatch: exportMaxBatch,
+		logger:   logger,
+	}
+}
+
+func (e *batchExporter) Export(host string, ts time.Time, m protocol.Metric) {
+	samples := export.Samples(m)
+	if len(samples) == 0 {
+		return
+	}
+
+	select {
+	case e.queue <- exportItem{Host: host, Time: ts, Samples: samples}:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// run drains the queue until done is closed, flushing what is left on
+// the way out.
+func (e *batchExporter) run(done <-chan struct{}) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	batch := make([]exportItem, 0, e.maxBatch)
+	send := func() {
+		if dropped := e.dropped.Swap(0); dropped > 0 {
+			e.logger.Warn("export queue full, dropped metrics", "exporter", e.name, "dropped", dropped)
+		}
+		if len(batch) == 0 {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), exportFlushTimeout)
+		if err := e.flush(ctx, batch); err != nil {
+			e.logger.Warn("metric export failed", "exporter", e.name, "count", len(batch), "error", err)
+		}
+		cancel()
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case item := <-e.queue:
+			batch = append(batch, item)
+			if len(batch) >= e.maxBatch {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case <-done:
+			for {
+				select {
+				case item := <-e.queue:
+					batch = append(batch, item)
+				default:
+					send()
+					return
+				}
+			}
+		}
+	}
+}
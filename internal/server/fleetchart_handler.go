@@ -73,10 +73,10 @@ func (s *Server) handleFleetChart(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		s.Logger.Error("database query error", "error", err, "handler", "handleFleetChart")
+		s.Logger.ErrorContext(r.Context(), "database query error", "error", err, "handler", "handleFleetChart")
 		http.Error(w, "database error", http.StatusInternalServerError)
 		return
 	}
 
-	respondJSON(w, http.StatusOK, result)
+	s.respondJSON(w, http.StatusOK, result)
 }
@@ -1,11 +1,14 @@
 package server
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,10 +20,11 @@ import (
 
 // RawEnvelope is used for unmarshalling metrics
 type RawEnvelope struct {
-	Type      string          `json:"type"`
-	Timestamp time.Time       `json:"timestamp"`
-	Hostname  string          `json:"hostname"`
-	Data      json.RawMessage `json:"data"`
+	Type          string          `json:"type"`
+	SchemaVersion int             `json:"schema_version"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Hostname      string          `json:"hostname"`
+	Data          json.RawMessage `json:"data"`
 }
 
 // generateAgentSecret creates a 32-byte random secret, returned as hex.
@@ -38,6 +42,22 @@ func hashAgentSecret(secret string) []byte {
 	return sum[:]
 }
 
+// normalizeTags trims and de-duplicates agent tags. It never returns nil,
+// since the tags column is NOT NULL.
+func normalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	return out
+}
+
 // handleAgentRegister accepts the HostInfo payload
 func (s *Server) handleAgentRegister(w http.ResponseWriter, r *http.Request) {
 	var req protocol.RegisterRequest
@@ -47,7 +67,7 @@ func (s *Server) handleAgentRegister(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !s.Tokens.Validate(req.Token) {
-		s.Logger.Warn("invalid registration token", "hostname", req.Info.Hostname, "ip", clientIP(r))
+		s.Logger.WarnContext(r.Context(), "invalid registration token", "hostname", req.Info.Hostname, "ip", clientIP(r))
 		http.Error(w, "invalid or expired registration token", http.StatusUnauthorized)
 		return
 	}
@@ -73,14 +93,17 @@ func (s *Server) handleAgentRegister(w http.ResponseWriter, r *http.Request) {
 			RamTotal:     pgInt8(int64(req.Info.RAMTotal)),
 			IpAddress:    pgText(clientIP(r)),
 			Version:      req.Info.AgentVer,
+			Kernel:       req.Info.Kernel,
+			Tags:         normalizeTags(req.Info.Tags),
 		}); err != nil {
-			s.Logger.Error("database query error", "error", err, "handler", "handleAgentRegister")
+			s.Logger.ErrorContext(r.Context(), "database query error", "error", err, "handler", "handleAgentRegister")
 			http.Error(w, "registration failed", http.StatusInternalServerError)
 			return
 		}
+		s.hostnames.set(agentID, req.Info.Hostname)
 	}
 
-	s.Logger.Info("registered agent",
+	s.Logger.InfoContext(r.Context(), "registered agent",
 		"hostname", req.Info.Hostname,
 		"agent_id", agentID,
 		"cpu_cores", req.Info.CPUCores,
@@ -94,11 +117,11 @@ func (s *Server) handleAgentRegister(w http.ResponseWriter, r *http.Request) {
 		AgentVersion: req.Info.AgentVer,
 	}
 	if err := s.syncAutoLabelsOnRegister(r.Context(), agentID, autoInfo); err != nil {
-		s.Logger.Warn("auto label sync failed on register",
+		s.Logger.WarnContext(r.Context(), "auto label sync failed on register",
 			"agent_id", agentID, "err", err)
 	}
 
-	respondJSON(w, http.StatusCreated, protocol.RegisterResponse{
+	s.respondJSON(w, http.StatusCreated, protocol.RegisterResponse{
 		AgentID: agentID,
 		Secret:  secret,
 	})
@@ -109,13 +132,24 @@ func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 
 	if v := r.Header.Get("X-Spectra-Agent-Version"); v != "" {
 		if err := s.syncAgentVersionLabel(r.Context(), agentID, v); err != nil {
-			s.Logger.Warn("agent_version sync failed",
+			s.Logger.WarnContext(r.Context(), "agent_version sync failed",
 				"agent_id", agentID, "err", err)
 		}
 	}
 
-	var rawEnvelopes []RawEnvelope
-	if err := decodeJSONBody(r, &rawEnvelopes); err != nil {
+	rawEnvelopes, err := decodeEnvelopes(r, s.Config.MaxBatchSize)
+	if errors.Is(err, errBatchTooLarge) {
+		total := s.oversizedBatches.Add(1)
+		s.Logger.WarnContext(r.Context(), "rejecting oversized metrics batch",
+			"agent_id", agentID,
+			"error", err,
+			"max", s.Config.MaxBatchSize,
+			"total", total,
+		)
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -128,26 +162,77 @@ func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 			Commit:     r.Header.Get("X-Agent-Commit"),
 			BinaryHash: r.Header.Get("X-Agent-Binary-Hash"),
 		}); err != nil {
-			s.Logger.Error("database query error", "error", err, "handler", "handleMetrics")
+			s.Logger.ErrorContext(r.Context(), "database query error", "error", err, "handler", "handleMetrics")
+			releaseEnvelopes(rawEnvelopes)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+
+		// Refuse the batch rather than store it under hostnames the agent
+		// merely claims; the agent keeps it and retries.
+		hostname, err := s.registeredHostname(r.Context(), agentID)
+		if err != nil {
+			s.Logger.ErrorContext(r.Context(), "hostname lookup failed, rejecting batch",
+				"agent_id", agentID, "error", err, "handler", "handleMetrics")
+			releaseEnvelopes(rawEnvelopes)
+			http.Error(w, "hostname lookup failed", http.StatusServiceUnavailable)
+			return
+		}
+		s.reconcileHostnames(r.Context(), agentID, hostname, rawEnvelopes)
 	}
 
 	w.WriteHeader(http.StatusAccepted)
+	s.Logger.DebugContext(r.Context(), "metrics batch accepted", "agent_id", agentID, "batch_size", len(rawEnvelopes))
 
+	// Detached from the request's cancellation, but keeps its request ID
+	// for the processing logs
+	ctx := context.WithoutCancel(r.Context())
 	go func() {
+		defer releaseEnvelopes(rawEnvelopes)
 		for _, env := range rawEnvelopes {
 			select {
 			case <-s.done:
 				return
 			default:
-				s.processMetric(agentID, env)
+				s.processMetric(ctx, agentID, env)
 			}
 		}
 	}()
 }
 
+// reconcileHostnames stamps the authenticated agent's registered hostname
+// onto every envelope. The envelope hostname is agent-supplied and feeds the
+// exporters' host tag, so it must not let one agent report under another's
+// name. Disagreements are counted and logged once per batch; envelopes with
+// no hostname are filled in silently.
+func (s *Server) reconcileHostnames(ctx context.Context, agentID, registered string, envs []RawEnvelope) {
+	if registered == "" {
+		return
+	}
+
+	var mismatched int
+	var claimed string
+	for i := range envs {
+		if h := envs[i].Hostname; h != "" && h != registered {
+			mismatched++
+			claimed = h
+		}
+		envs[i].Hostname = registered
+	}
+	if mismatched == 0 {
+		return
+	}
+
+	total := s.hostnameMismatches.Add(int64(mismatched))
+	s.Logger.WarnContext(ctx, "envelope hostname does not match registered agent",
+		"agent_id", agentID,
+		"claimed", claimed,
+		"registered", registered,
+		"envelopes", mismatched,
+		"total", total,
+	)
+}
+
 func (s *Server) handleAgentCommand(w http.ResponseWriter, r *http.Request) {
 	agentID := getAgentID(r)
 
@@ -157,7 +242,7 @@ func (s *Server) handleAgentCommand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondJSON(w, http.StatusOK, cmd)
+	s.respondJSON(w, http.StatusOK, cmd)
 }
 
 func (s *Server) handleCommandResult(w http.ResponseWriter, r *http.Request) {
@@ -169,11 +254,18 @@ func (s *Server) handleCommandResult(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.Logger.Info("command result received", "agent_id", agentID, "command", res.ID, "type", res.Type)
+	if res.Partial {
+		s.Logger.DebugContext(r.Context(), "partial command result received", "agent_id", agentID, "command", res.ID, "type", res.Type)
+		s.Commands.Append(res.ID, res)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	s.Logger.InfoContext(r.Context(), "command result received", "agent_id", agentID, "command", res.ID, "type", res.Type)
 	s.Commands.Complete(res.ID, res)
 
 	if res.Error != "" {
-		s.Logger.Warn("command failed", "command", res.ID, "error", res.Error)
+		s.Logger.WarnContext(r.Context(), "command failed", "command", res.ID, "error", res.Error)
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -195,14 +287,14 @@ func (s *Server) handleGetCommandResult(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	respondJSON(w, http.StatusOK, entry)
+	s.respondJSON(w, http.StatusOK, entry)
 }
 
 // handleVersion returns the version of the binary build.
 //
 // GET /api/v1/version
 func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
-	respondJSON(w, http.StatusOK, map[string]string{
+	s.respondJSON(w, http.StatusOK, map[string]string{
 		"version": version.Version,
 		"commit":  version.Commit,
 		"date":    version.Date,
@@ -215,12 +307,12 @@ func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handlePurgeOfflineAgents(w http.ResponseWriter, r *http.Request) {
 	count, err := s.DB.PurgeOfflineAgents(r.Context())
 	if err != nil {
-		s.dbError(w, err, "handlePurgeOfflineAgents")
+		s.dbError(w, r, err, "handlePurgeOfflineAgents")
 		return
 	}
 
-	s.Logger.Info("purged offline agents", "count", count)
-	respondJSON(w, http.StatusOK, map[string]int64{"purged": count})
+	s.Logger.InfoContext(r.Context(), "purged offline agents", "count", count)
+	s.respondJSON(w, http.StatusOK, map[string]int64{"purged": count})
 }
 
 // handleRevokeAllTokens invalidates all pending registration tokens.
@@ -228,6 +320,6 @@ func (s *Server) handlePurgeOfflineAgents(w http.ResponseWriter, r *http.Request
 // POST /api/v1/admin/tokens/revoke
 func (s *Server) handleRevokeAllTokens(w http.ResponseWriter, r *http.Request) {
 	s.Tokens.RevokeAll()
-	s.Logger.Info("all registration tokens revoked")
+	s.Logger.InfoContext(r.Context(), "all registration tokens revoked")
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -2,14 +2,21 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/nhdewitt/spectra/internal/logging"
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
@@ -55,6 +62,52 @@ func TestHandleAgentRegister_Success(t *testing.T) {
 	}
 }
 
+func TestHandleAgentRegister_PersistsHostInfo(t *testing.T) {
+	db := NewMockDB()
+	s := New(Config{Port: 8080}, db)
+	token := s.Tokens.Generate(24 * time.Hour)
+
+	regReq := protocol.RegisterRequest{
+		Token: token,
+		Info: protocol.HostInfo{
+			Hostname: "tagged-agent",
+			OS:       "linux",
+			Kernel:   "6.1.0-rpi7-rpi-v8",
+			AgentVer: "1.2.3",
+			Tags:     []string{"prod", " rack-2 ", "prod", ""},
+		},
+	}
+
+	body, _ := json.Marshal(regReq)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/agent/register", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "10.0.0.1:1234"
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want 201", rec.Code)
+	}
+
+	got := db.LastRegisterAgentParams
+	if got.Kernel != "6.1.0-rpi7-rpi-v8" {
+		t.Errorf("Kernel: got %q, want 6.1.0-rpi7-rpi-v8", got.Kernel)
+	}
+	if got.Version != "1.2.3" {
+		t.Errorf("Version: got %q, want 1.2.3", got.Version)
+	}
+	if want := []string{"prod", "rack-2"}; !slices.Equal(got.Tags, want) {
+		t.Errorf("Tags: got %v, want %v", got.Tags, want)
+	}
+}
+
+func TestNormalizeTags_NeverNil(t *testing.T) {
+	if got := normalizeTags(nil); got == nil || len(got) != 0 {
+		t.Errorf("normalizeTags(nil) = %#v, want empty non-nil slice", got)
+	}
+}
+
 func TestHandleAgentRegister_InvalidToken(t *testing.T) {
 	s := New(Config{Port: 8080}, NewMockDB())
 
@@ -257,6 +310,254 @@ func TestHandleMetrics_Success(t *testing.T) {
 	}
 }
 
+// captureHandler records every log record at any level.
+type captureHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *captureHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *captureHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h *captureHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r.Clone())
+	return nil
+}
+
+// attr returns the value of key on the first record with message msg.
+func (h *captureHandler) attr(msg, key string) (slog.Value, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, r := range h.records {
+		if r.Message != msg {
+			continue
+		}
+		var v slog.Value
+		var found bool
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == key {
+				v, found = a.Value, true
+				return false
+			}
+			return true
+		})
+		return v, found
+	}
+	return slog.Value{}, false
+}
+
+func TestHandleMetrics_LogsBatchSize(t *testing.T) {
+	capture := &captureHandler{}
+	mock := NewMockDB()
+	s := New(Config{Port: 8080, Logger: logging.FromHandler(capture)}, mock)
+	agentID := "550e8400-e29b-41d4-a716-446655440000"
+	sum := sha256.Sum256([]byte("test-secret"))
+	mock.AgentSHA256[agentID] = sum[:]
+
+	body, _ := json.Marshal([]RawEnvelope{
+		{Type: "cpu", Hostname: "test-host", Data: json.RawMessage(`{"usage": 50.0}`)},
+		{Type: "memory", Hostname: "test-host", Data: json.RawMessage(`{"total": 100}`)},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/agent/metrics", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	setAgentAuth(req, agentID, "test-secret")
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202", rec.Code)
+	}
+	v, ok := capture.attr("metrics batch accepted", "batch_size")
+	if !ok {
+		t.Fatal("batch_size not logged on ingest")
+	}
+	if v.Int64() != 2 {
+		t.Errorf("batch_size: got %v, want 2", v)
+	}
+	if v, _ := capture.attr("metrics batch accepted", "agent_id"); v.String() != agentID {
+		t.Errorf("agent_id: got %q", v.String())
+	}
+}
+
+func TestHandleMetrics_RejectsOversizedBatch(t *testing.T) {
+	s, agentID, secret, _ := newTestServer()
+	s.Config.MaxBatchSize = 2
+
+	body, _ := json.Marshal([]RawEnvelope{
+		{Type: "cpu", Hostname: "test-host", Data: json.RawMessage(`{"usage": 50.0}`)},
+		{Type: "cpu", Hostname: "test-host", Data: json.RawMessage(`{"usage": 51.0}`)},
+		{Type: "cpu", Hostname: "test-host", Data: json.RawMessage(`{"usage": 52.0}`)},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/agent/metrics", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	setAgentAuth(req, agentID, secret)
+	rec := httptest.NewRecorder()
+
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status: got %d, want 413", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "more than 2") {
+		t.Errorf("body: got %q", rec.Body.String())
+	}
+	if got := s.oversizedBatches.Load(); got != 1 {
+		t.Errorf("oversizedBatches: got %d, want 1", got)
+	}
+}
+
+func TestReconcileHostnames(t *testing.T) {
+	tests := []struct {
+		name         string
+		registered   string
+		in           []string
+		want         []string
+		wantMismatch int64
+	}{
+		{"matching", "web-01", []string{"web-01", "web-01"}, []string{"web-01", "web-01"}, 0},
+		{"mismatching", "web-01", []string{"web-01", "db-01"}, []string{"web-01", "web-01"}, 1},
+		{"missing envelope hostname", "web-01", []string{"", ""}, []string{"web-01", "web-01"}, 0},
+		{"unknown registration", "", []string{"db-01"}, []string{"db-01"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capture := &captureHandler{}
+			s := New(Config{Port: 8080, Logger: logging.FromHandler(capture)}, NewMockDB())
+
+			envs := make([]RawEnvelope, len(tt.in))
+			for i, h := range tt.in {
+				envs[i] = RawEnvelope{Type: "cpu", Hostname: h}
+			}
+
+			s.reconcileHostnames(context.Background(), "agent-1", tt.registered, envs)
+
+			for i, env := range envs {
+				if env.Hostname != tt.want[i] {
+					t.Errorf("envs[%d].Hostname = %q, want %q", i, env.Hostname, tt.want[i])
+				}
+			}
+			if got := s.hostnameMismatches.Load(); got != tt.wantMismatch {
+				t.Errorf("hostnameMismatches = %d, want %d", got, tt.wantMismatch)
+			}
+			_, logged := capture.attr("envelope hostname does not match registered agent", "claimed")
+			if logged != (tt.wantMismatch > 0) {
+				t.Errorf("mismatch logged = %v, want %v", logged, tt.wantMismatch > 0)
+			}
+		})
+	}
+}
+
+// hostRecorder is a metricExporter that reports the host of each export.
+type hostRecorder chan string
+
+func (h hostRecorder) Export(host string, _ time.Time, _ protocol.Metric) { h <- host }
+
+// postCPUBatch sends one metrics batch claiming the given hostnames.
+func postCPUBatch(s *Server, agentID, secret string, hostnames ...string) *httptest.ResponseRecorder {
+	envs := make([]RawEnvelope, len(hostnames))
+	for i, h := range hostnames {
+		envs[i] = RawEnvelope{Type: "cpu", Hostname: h, Data: json.RawMessage(`{"usage": 50.0}`)}
+	}
+	body, _ := json.Marshal(envs)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/agent/metrics", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	setAgentAuth(req, agentID, secret)
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandleMetrics_StampsRegisteredHostname(t *testing.T) {
+	s, agentID, secret, mock := newTestServer()
+	mock.AgentHostname = "web-01"
+	hosts := make(hostRecorder, 2)
+	s.exporters = []metricExporter{hosts}
+
+	rec := postCPUBatch(s, agentID, secret, "web-01", "db-01")
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202", rec.Code)
+	}
+	for range 2 {
+		select {
+		case h := <-hosts:
+			if h != "web-01" {
+				t.Errorf("exported host: got %q, want registered web-01", h)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for export")
+		}
+	}
+	if got := s.hostnameMismatches.Load(); got != 1 {
+		t.Errorf("hostnameMismatches: got %d, want 1", got)
+	}
+}
+
+func TestHandleMetrics_HostnameCached(t *testing.T) {
+	s, agentID, secret, mock := newTestServer()
+	mock.AgentHostname = "web-01"
+
+	if rec := postCPUBatch(s, agentID, secret, "web-01"); rec.Code != http.StatusAccepted {
+		t.Fatalf("first batch: got %d, want 202", rec.Code)
+	}
+
+	// A cached hostname means later batches don't need the database.
+	mock.mu.Lock()
+	mock.GetAgentErr = errors.New("db down")
+	mock.mu.Unlock()
+
+	if rec := postCPUBatch(s, agentID, secret, "db-01"); rec.Code != http.StatusAccepted {
+		t.Fatalf("second batch: got %d, want 202", rec.Code)
+	}
+	if got := s.hostnameMismatches.Load(); got != 1 {
+		t.Errorf("hostnameMismatches: got %d, want 1", got)
+	}
+}
+
+func TestHandleMetrics_HostnameLookupFails(t *testing.T) {
+	s, agentID, secret, mock := newTestServer()
+	mock.GetAgentErr = errors.New("db down")
+	hosts := make(hostRecorder, 1)
+	s.exporters = []metricExporter{hosts}
+
+	rec := postCPUBatch(s, agentID, secret, "db-01")
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status: got %d, want 503", rec.Code)
+	}
+	select {
+	case h := <-hosts:
+		t.Errorf("batch should not be processed, exported host %q", h)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, ok := s.hostnames.get(agentID); ok {
+		t.Error("failed lookup should not be cached")
+	}
+}
+
+func TestHandleAgentRegister_SeedsHostnameCache(t *testing.T) {
+	s := New(Config{Port: 8080}, NewMockDB())
+	body, _ := json.Marshal(protocol.RegisterRequest{
+		Token: s.Tokens.Generate(time.Hour),
+		Info:  protocol.HostInfo{Hostname: "web-01"},
+	})
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/agent/register", bytes.NewReader(body)))
+
+	var resp protocol.RegisterResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if h, ok := s.hostnames.get(resp.AgentID); !ok || h != "web-01" {
+		t.Errorf("cached hostname: got %q, %v; want web-01", h, ok)
+	}
+}
+
 func TestHandleMetrics_EmptyBatch(t *testing.T) {
 	s, agentID, secret, _ := newTestServer()
 
@@ -384,6 +685,47 @@ func TestHandleCommandResult_Success(t *testing.T) {
 	}
 }
 
+func TestHandleCommandResult_Partial(t *testing.T) {
+	s, agentID, secret, _ := newTestServer()
+	s.Commands.Track("cmd-follow", protocol.CmdFollowLogs, agentID)
+
+	post := func(res protocol.CommandResult) {
+		t.Helper()
+		body, _ := json.Marshal(res)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/agent/command/result", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = "10.0.0.5:1234"
+		setAgentAuth(req, agentID, secret)
+		rec := httptest.NewRecorder()
+
+		s.Router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status: got %d, want 200", rec.Code)
+		}
+	}
+
+	post(protocol.CommandResult{ID: "cmd-follow", Type: protocol.CmdFollowLogs, Payload: json.RawMessage(`[{"message":"a"}]`), Partial: true})
+
+	entry, ok := s.Commands.Get("cmd-follow")
+	if !ok {
+		t.Fatal("command not tracked")
+	}
+	if entry.Done || len(entry.Partials) != 1 {
+		t.Fatalf("after partial: done %v, partials %d; want false, 1", entry.Done, len(entry.Partials))
+	}
+
+	post(protocol.CommandResult{ID: "cmd-follow", Type: protocol.CmdFollowLogs, Payload: json.RawMessage(`[]`)})
+
+	entry, _ = s.Commands.Get("cmd-follow")
+	if !entry.Done || entry.Result == nil {
+		t.Error("expected command done after final result")
+	}
+	if len(entry.Partials) != 1 {
+		t.Errorf("partials: got %d, want 1", len(entry.Partials))
+	}
+}
+
 func TestHandleCommandResult_WithError(t *testing.T) {
 	s, agentID, secret, _ := newTestServer()
 
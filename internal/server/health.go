@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// readyTimeout bounds the database round-trip behind /readyz so a hung
+// pool fails the probe instead of stalling it.
+const readyTimeout = 2 * time.Second
+
+// handleHealthz reports that the process is up and serving HTTP.
+//
+// GET /healthz
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	s.respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReadyz reports whether the server should receive traffic: the
+// database answers and shutdown hasn't begun.
+//
+// GET /readyz
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if s.draining.Load() {
+		s.respondJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "shutting down"})
+		return
+	}
+
+	if s.DB != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+		defer cancel()
+		if _, err := s.DB.Ping(ctx); err != nil {
+			s.Logger.WarnContext(r.Context(), "readiness check failed", "error", err)
+			s.respondJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "database unavailable"})
+			return
+		}
+	}
+
+	s.respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
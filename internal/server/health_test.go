@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthz(t *testing.T) {
+	s := New(Config{Port: 8080}, NewMockDB())
+
+	for _, stopped := range []bool{false, true} {
+		if stopped {
+			s.draining.Store(true)
+		}
+		rec := httptest.NewRecorder()
+		s.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("shutting down=%v: status %d, want 200", stopped, rec.Code)
+		}
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	tests := []struct {
+		name     string
+		pingErr  error
+		shutdown bool
+		want     int
+	}{
+		{"ready", nil, false, http.StatusOK},
+		{"database down", errors.New("connection refused"), false, http.StatusServiceUnavailable},
+		{"shutting down", nil, true, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := NewMockDB()
+			mock.PingErr = tt.pingErr
+			s := New(Config{Port: 8080}, mock)
+			if tt.shutdown {
+				s.draining.Store(true)
+			}
+
+			rec := httptest.NewRecorder()
+			s.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rec.Code != tt.want {
+				t.Errorf("status: got %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestShutdown_DrainsBeforeClosing(t *testing.T) {
+	const delay = 300 * time.Millisecond
+	s := New(Config{Port: 8080, DrainDelay: delay}, NewMockDB())
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.httpServer = &http.Server{Handler: s.Router}
+	go s.httpServer.Serve(ln)
+	base := "http://" + ln.Addr().String()
+
+	get := func(path string) int {
+		resp, err := http.Get(base + path)
+		if err != nil {
+			t.Fatalf("GET %s during drain: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := get("/readyz"); code != http.StatusOK {
+		t.Fatalf("readyz before shutdown: got %d, want 200", code)
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- s.Shutdown(context.Background()) }()
+
+	deadline := time.Now().Add(delay / 2)
+	for !s.draining.Load() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Still listening, but no longer ready.
+	if code := get("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("readyz during drain: got %d, want 503", code)
+	}
+	if code := get("/healthz"); code != http.StatusOK {
+		t.Errorf("healthz during drain: got %d, want 200", code)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Errorf("Shutdown returned after %v, want at least %v", elapsed, delay)
+	}
+	if _, err := http.Get(base + "/healthz"); err == nil {
+		t.Error("listener still accepting after Shutdown")
+	}
+}
+
+func TestShutdown_DrainHonorsContext(t *testing.T) {
+	s := New(Config{Port: 8080, DrainDelay: time.Hour}, NewMockDB())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	s.Shutdown(ctx)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Shutdown waited %v despite cancelled context", elapsed)
+	}
+}
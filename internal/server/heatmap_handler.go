@@ -32,7 +32,7 @@ func (s *Server) handleFleetHeatmap(w http.ResponseWriter, r *http.Request) {
 		EndTime:   endTime,
 	})
 	if err != nil {
-		s.dbError(w, err, "handleFleetHeatmap")
+		s.dbError(w, r, err, "handleFleetHeatmap")
 		return
 	}
 
@@ -65,5 +65,5 @@ func (s *Server) handleFleetHeatmap(w http.ResponseWriter, r *http.Request) {
 		result = append(result, *ah)
 	}
 
-	respondJSON(w, http.StatusOK, result)
+	s.respondJSON(w, http.StatusOK, result)
 }
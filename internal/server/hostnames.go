@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"sync"
+)
+
+// hostnameCache remembers each agent's registered hostname so the metrics
+// handler doesn't query the agents table on every POST. Registration
+// seeds it and agent deletion clears it; a miss falls back to GetAgent.
+type hostnameCache struct {
+	mu sync.RWMutex
+	m  map[string]string
+}
+
+func newHostnameCache() *hostnameCache {
+	return &hostnameCache{m: make(map[string]string)}
+}
+
+func (c *hostnameCache) get(agentID string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	h, ok := c.m[agentID]
+	return h, ok
+}
+
+func (c *hostnameCache) set(agentID, hostname string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[agentID] = hostname
+}
+
+func (c *hostnameCache) forget(agentID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.m, agentID)
+}
+
+// registeredHostname returns the hostname agentID registered with,
+// loading it from the database on a cache miss. Failed lookups aren't
+// cached, so the next batch retries.
+func (s *Server) registeredHostname(ctx context.Context, agentID string) (string, error) {
+	if h, ok := s.hostnames.get(agentID); ok {
+		return h, nil
+	}
+	agent, err := s.DB.GetAgent(ctx, mustUUID(agentID))
+	if err != nil {
+		return "", err
+	}
+	s.hostnames.set(agentID, agent.Hostname)
+	return agent.Hostname, nil
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/export"
+	"github.com/nhdewitt/spectra/internal/logging"
+)
+
+// InfluxConfig points the InfluxDB v2 exporter at a bucket. The exporter
+// is disabled while URL is empty.
+type InfluxConfig struct {
+	URL    string
+	Org    string
+	Bucket string
+	Token  string
+}
+
+// newInfluxExporter returns an exporter that writes line protocol to the
+// InfluxDB v2 write API.
+func newInfluxExporter(cfg InfluxConfig, logger *logging.Logger) *batchExporter {
+	q := url.Values{}
+	q.Set("org", cfg.Org)
+	q.Set("bucket", cfg.Bucket)
+	q.Set("precision", "ns")
+	writeURL := strings.TrimRight(cfg.URL, "/") + "/api/v2/write?" + q.Encode()
+	client := &http.Client{Timeout: exportFlushTimeout}
+
+	return newBatchExporter("influxdb", logger, func(ctx context.Context, items []exportItem) error {
+		var buf bytes.Buffer
+		for _, item := range items {
+			for _, s := range item.Samples {
+				buf.WriteString(formatLineProtocol(item.Host, item.Time, s))
+				buf.WriteByte('\n')
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, writeURL, &buf)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+		if cfg.Token != "" {
+			req.Header.Set("Authorization", "Token "+cfg.Token)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("influxdb returned %s", resp.Status)
+		}
+		return nil
+	})
+}
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	tagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+)
+
+// formatLineProtocol renders s as one InfluxDB line:
+//
+//	measurement,host=h,tag=v field=1.5,other=2 1700000000000000000
+//
+// Tags are sorted by key with host first, empty tag values are left out
+// (line protocol rejects them), and fields are written as floats.
+func formatLineProtocol(host string, ts time.Time, s export.Sample) string {
+	var b strings.Builder
+
+	b.WriteString(measurementEscaper.Replace(s.Measurement))
+	if host != "" {
+		b.WriteString(",host=")
+		b.WriteString(tagEscaper.Replace(host))
+	}
+
+	tags := slices.Clone(s.Tags)
+	slices.SortFunc(tags, func(a, b export.Tag) int { return strings.Compare(a.Key, b.Key) })
+	for _, t := range tags {
+		if t.Value == "" {
+			continue
+		}
+		b.WriteByte(',')
+		b.WriteString(tagEscaper.Replace(t.Key))
+		b.WriteByte('=')
+		b.WriteString(tagEscaper.Replace(t.Value))
+	}
+
+	for i, f := range s.Fields {
+		if i == 0 {
+			b.WriteByte(' ')
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(tagEscaper.Replace(f.Key))
+		b.WriteByte('=')
+		b.WriteString(strconv.FormatFloat(f.Value, 'f', -1, 64))
+	}
+
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatInt(ts.UnixNano(), 10))
+	return b.String()
+}
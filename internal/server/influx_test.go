@@ -0,0 +1,105 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/export"
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestFormatLineProtocol(t *testing.T) {
+	ts := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		host   string
+		metric protocol.Metric
+		want   string
+	}{
+		{
+			name:   "CPU",
+			host:   "pi",
+			metric: &protocol.CPUMetric{Usage: 42.5, IOWait: 1.25, LoadAvg1: 0.5, LoadAvg5: 0.25, LoadAvg15: 0.1},
+			want:   "cpu,host=pi usage=42.5,iowait=1.25,load_1m=0.5,load_5m=0.25,load_15m=0.1 1748779200000000000",
+		},
+		{
+			name: "Disk with escaping",
+			host: "media server",
+			metric: &protocol.DiskMetric{
+				Device: "/dev/sdb1", Mountpoint: "/mnt/My Files,old", Filesystem: "ext4",
+				Total: 1000, Used: 250, Available: 750, UsedPct: 25,
+			},
+			want: `disk,host=media\ server,device=/dev/sdb1,fstype=ext4,mountpoint=/mnt/My\ Files\,old ` +
+				"total=1000,used=250,available=750,used_pct=25,inodes_pct=0 1748779200000000000",
+		},
+		{
+			name:   "Empty tag dropped",
+			host:   "pi",
+			metric: &protocol.SensorMetric{Source: "ipmi", Name: "Fan1", Kind: "fan", Value: 4200},
+			want:   "sensor,host=pi,kind=fan,name=Fan1,source=ipmi value=4200 1748779200000000000",
+		},
+		{
+			name:   "Equals in tag",
+			host:   "pi",
+			metric: &protocol.WiFiMetric{Interface: "wlan0", SSID: "a=b", SignalLevel: -60},
+			want:   `wifi,host=pi,iface=wlan0,ssid=a\=b signal_dbm=-60,link_quality=0,bitrate_mbps=0 1748779200000000000`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := export.Samples(tt.metric)
+			if len(samples) != 1 {
+				t.Fatalf("samples: got %d, want 1", len(samples))
+			}
+			if got := formatLineProtocol(tt.host, ts, samples[0]); got != tt.want {
+				t.Errorf("got\n  %s\nwant\n  %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInfluxExporter_Write(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan string, 1)
+	influx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer influx.Close()
+
+	s := New(Config{Port: 8080}, NewMockDB())
+	e := newInfluxExporter(InfluxConfig{URL: influx.URL, Org: "home", Bucket: "spectra", Token: "tok"}, s.Logger)
+	e.interval = 10 * time.Millisecond
+	done := make(chan struct{})
+	defer close(done)
+	go e.run(done)
+
+	e.Export("pi", time.Unix(0, 1), &protocol.SchedMetric{ContextSwitchesPerSec: 100, InterruptsPerSec: 50})
+
+	select {
+	case r := <-received:
+		if r.URL.Path != "/api/v2/write" {
+			t.Errorf("path: got %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("org") != "home" || q.Get("bucket") != "spectra" || q.Get("precision") != "ns" {
+			t.Errorf("query: got %s", r.URL.RawQuery)
+		}
+		if got := r.Header.Get("Authorization"); got != "Token tok" {
+			t.Errorf("Authorization: got %q", got)
+		}
+		if body := <-bodies; strings.TrimSpace(body) != "sched,host=pi ctxt_per_sec=100,intr_per_sec=50 1" {
+			t.Errorf("body: got %q", body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for InfluxDB write")
+	}
+}
@@ -44,12 +44,12 @@ func (s *Server) handleUpgradeInstructions(w http.ResponseWriter, r *http.Reques
 
 	p := platformFromAgent(agent.Os.String, agent.Arch.String)
 	if p == nil {
-		respondJSON(w, http.StatusOK, upgradeInstructions{})
+		s.respondJSON(w, http.StatusOK, upgradeInstructions{})
 		return
 	}
 
 	instructions := generateUpgradeInstructions(p)
-	respondJSON(w, http.StatusOK, instructions)
+	s.respondJSON(w, http.StatusOK, instructions)
 }
 
 func (s *Server) handleUninstallInstructions(w http.ResponseWriter, r *http.Request) {
@@ -71,10 +71,10 @@ func (s *Server) handleUninstallInstructions(w http.ResponseWriter, r *http.Requ
 
 	p := platformFromAgent(agent.Os.String, agent.Arch.String)
 	if p == nil {
-		respondJSON(w, http.StatusOK, uninstallInstructions{})
+		s.respondJSON(w, http.StatusOK, uninstallInstructions{})
 		return
 	}
 
 	instructions := generateUninstallInstructions(p)
-	respondJSON(w, http.StatusOK, instructions)
+	s.respondJSON(w, http.StatusOK, instructions)
 }
@@ -32,13 +32,13 @@ type putLabelRequest struct {
 func (s *Server) handleListAgentLabels(w http.ResponseWriter, r *http.Request) {
 	agentID, err := parsePathID(r)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+		s.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	rows, err := s.DB.ListAgentLabels(r.Context(), mustUUID(agentID))
 	if err != nil {
-		s.dbError(w, err, "handleListAgentLabels")
+		s.dbError(w, r, err, "handleListAgentLabels")
 		return
 	}
 
@@ -51,7 +51,7 @@ func (s *Server) handleListAgentLabels(w http.ResponseWriter, r *http.Request) {
 			UpdatedAt: row.UpdatedAt.Time,
 		}
 	}
-	respondJSON(w, http.StatusOK, out)
+	s.respondJSON(w, http.StatusOK, out)
 }
 
 // bulkLabelDTO is one label in the bulk agent-labels respopnse. The batch query
@@ -70,7 +70,7 @@ type bulkLabelDTO struct {
 func (s *Server) handleListAllAgentLabels(w http.ResponseWriter, r *http.Request) {
 	rows, err := s.DB.ListAllAgentLabels(r.Context())
 	if err != nil {
-		s.dbError(w, err, "handleListAllAgentLabels")
+		s.dbError(w, r, err, "handleListAllAgentLabels")
 		return
 	}
 
@@ -84,7 +84,7 @@ func (s *Server) handleListAllAgentLabels(w http.ResponseWriter, r *http.Request
 		})
 	}
 
-	respondJSON(w, http.StatusOK, out)
+	s.respondJSON(w, http.StatusOK, out)
 }
 
 // handleListLabelKeys returns the distinct set of label keys across the
@@ -95,7 +95,7 @@ func (s *Server) handleListAllAgentLabels(w http.ResponseWriter, r *http.Request
 func (s *Server) handleListLabelKeys(w http.ResponseWriter, r *http.Request) {
 	rows, err := s.DB.ListLabelKeys(r.Context())
 	if err != nil {
-		s.dbError(w, err, "handleListLabelKeys")
+		s.dbError(w, r, err, "handleListLabelKeys")
 		return
 	}
 
@@ -103,7 +103,7 @@ func (s *Server) handleListLabelKeys(w http.ResponseWriter, r *http.Request) {
 	for i, row := range rows {
 		out[i] = labelKeyDTO{Key: row.Key, Source: row.Source}
 	}
-	respondJSON(w, http.StatusOK, out)
+	s.respondJSON(w, http.StatusOK, out)
 }
 
 // handleListLabelValues returns the distinct values for a given key. Used
@@ -113,13 +113,13 @@ func (s *Server) handleListLabelKeys(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleListLabelValues(w http.ResponseWriter, r *http.Request) {
 	key := r.URL.Query().Get("key")
 	if key == "" {
-		respondError(w, http.StatusBadRequest, "key query parameter is required")
+		s.respondError(w, http.StatusBadRequest, "key query parameter is required")
 		return
 	}
 
 	values, err := s.DB.ListLabelValuesForKey(r.Context(), key)
 	if err != nil {
-		s.dbError(w, err, "handleListLabelValues")
+		s.dbError(w, r, err, "handleListLabelValues")
 		return
 	}
 
@@ -127,7 +127,7 @@ func (s *Server) handleListLabelValues(w http.ResponseWriter, r *http.Request) {
 	if values == nil {
 		values = []string{}
 	}
-	respondJSON(w, http.StatusOK, values)
+	s.respondJSON(w, http.StatusOK, values)
 }
 
 // handlePutAgentLabel upserts a user-sourced label on an agent. Reserved
@@ -137,14 +137,14 @@ func (s *Server) handleListLabelValues(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handlePutAgentLabel(w http.ResponseWriter, r *http.Request) {
 	agentID, err := parsePathID(r)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+		s.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	key := r.PathValue("key")
 
 	var req putLabelRequest
 	if err := decodeJSONBody(r, &req); err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+		s.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -153,7 +153,7 @@ func (s *Server) handlePutAgentLabel(w http.ResponseWriter, r *http.Request) {
 		if errors.Is(err, labels.ErrReservedKey) {
 			status = http.StatusForbidden
 		}
-		respondError(w, status, err.Error())
+		s.respondError(w, status, err.Error())
 		return
 	}
 
@@ -164,14 +164,14 @@ func (s *Server) handlePutAgentLabel(w http.ResponseWriter, r *http.Request) {
 	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			respondError(w, http.StatusConflict, "label key is held by an auto label")
+			s.respondError(w, http.StatusConflict, "label key is held by an auto label")
 			return
 		}
-		s.dbError(w, err, "handlePutAgentLabel")
+		s.dbError(w, r, err, "handlePutAgentLabel")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, labelDTO{
+	s.respondJSON(w, http.StatusOK, labelDTO{
 		Key:       row.Key,
 		Value:     row.Value,
 		Source:    row.Source,
@@ -186,7 +186,7 @@ func (s *Server) handlePutAgentLabel(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleDeleteAgentLabel(w http.ResponseWriter, r *http.Request) {
 	agentID, err := parsePathID(r)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+		s.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	key := r.PathValue("key")
@@ -196,7 +196,7 @@ func (s *Server) handleDeleteAgentLabel(w http.ResponseWriter, r *http.Request)
 		Key:     key,
 	})
 	if err != nil {
-		s.dbError(w, err, "handleDeleteAgentLabel")
+		s.dbError(w, r, err, "handleDeleteAgentLabel")
 		return
 	}
 
@@ -206,19 +206,19 @@ func (s *Server) handleDeleteAgentLabel(w http.ResponseWriter, r *http.Request)
 			Key:     key,
 		})
 		if errors.Is(getErr, pgx.ErrNoRows) {
-			respondError(w, http.StatusNotFound, "label not found")
+			s.respondError(w, http.StatusNotFound, "label not found")
 			return
 		}
 		if getErr != nil {
-			s.dbError(w, getErr, "handleDeleteAgentLabel")
+			s.dbError(w, r, getErr, "handleDeleteAgentLabel")
 			return
 		}
 		if existing.Source == "auto" {
-			respondError(w, http.StatusForbidden, "cannot delete auto-sourced labels")
+			s.respondError(w, http.StatusForbidden, "cannot delete auto-sourced labels")
 			return
 		}
 		// Source='user' but delete missed: race condition (concurrent delete)
-		respondError(w, http.StatusNotFound, "label not found")
+		s.respondError(w, http.StatusNotFound, "label not found")
 		return
 	}
 
@@ -44,13 +44,13 @@ func (s *Server) requireAgentAuth(next http.HandlerFunc) http.HandlerFunc {
 					ID:           id,
 					SecretSha256: sum[:],
 				}); err != nil {
-					s.Logger.Error("failed upgrading agent to SHA-256", "agent_id", agentID, "error", err)
+					s.Logger.ErrorContext(r.Context(), "failed upgrading agent to SHA-256", "agent_id", agentID, "error", err)
 				}
 			}
 		}
 
 		if !authOK {
-			s.Logger.Warn("agent auth failed", "agent_id", agentID, "ip", clientIP(r))
+			s.Logger.WarnContext(r.Context(), "agent auth failed", "agent_id", agentID, "ip", clientIP(r))
 			http.Error(w, "invalid agent credentials", http.StatusUnauthorized)
 			return
 		}
@@ -62,7 +62,7 @@ func (s *Server) requireAgentAuth(next http.HandlerFunc) http.HandlerFunc {
 			Commit:     r.Header.Get("X-Agent-Commit"),
 			BinaryHash: r.Header.Get("X-Agent-Binary-Hash"),
 		}); err != nil {
-			s.Logger.Warn("failed to update agent last_seen", "agent_id", agentID, "error", err)
+			s.Logger.WarnContext(r.Context(), "failed to update agent last_seen", "agent_id", agentID, "error", err)
 		}
 		next(w, r)
 	}
@@ -5,7 +5,9 @@ import (
 	"context"
 	"crypto/rand"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 
@@ -93,6 +95,11 @@ type MockDB struct {
 	// Stored agents: agentID (string) -> secret hash
 	Agents map[string]string
 
+	LastRegisterAgentParams database.RegisterAgentParams
+	// Registered agents in registration order, upserted by ID like the
+	// RegisterAgent query; ListAgents returns them unless ListAgentsReturn is set
+	registered []database.ListAgentsRow
+
 	// Counters for verifying calls
 	InsertCPUCount         int
 	InsertMemoryCount      int
@@ -109,6 +116,9 @@ type MockDB struct {
 	UpsertApplicationCount int
 	TouchLastSeenCount     int
 
+	// Inventory snapshots, keyed by agent ID then kind
+	Inventory map[string]map[string][]byte
+
 	// Auth
 	Users       map[string]mockUser    // username -> user
 	Sessions    map[string]mockSession // token -> session
@@ -176,12 +186,18 @@ type MockDB struct {
 	SMTPConfigErr error
 
 	ListAllAgentLabelsReturn []database.ListAllAgentLabelsRow
+	ListAgentsReturn         []database.ListAgentsRow
+
+	LastCPUBucketed database.GetCPUBucketedParams
+
+	PingErr error
 
-	Err         error
-	QueryErr    error // errors for data queries (not auth)
-	GetAgentErr error
-	FleetErr    error // errors for fleet queries
-	ConfigErr   error // errors for agent config queries
+	Err           error
+	QueryErr      error // errors for data queries (not auth)
+	GetAgentErr   error
+	AgentHostname string // returned by GetAgent
+	FleetErr      error  // errors for fleet queries
+	ConfigErr     error  // errors for agent config queries
 }
 
 type mockUser struct {
@@ -247,6 +263,16 @@ func (m *MockDB) RegisterAgent(_ context.Context, arg database.RegisterAgentPara
 
 	id := formatUUID(arg.ID)
 	m.Agents[id] = arg.SecretHash
+	m.LastRegisterAgentParams = arg
+
+	row := database.ListAgentsRow{ID: arg.ID, Hostname: arg.Hostname, Tags: arg.Tags}
+	for i := range m.registered {
+		if m.registered[i].ID == arg.ID {
+			m.registered[i] = row
+			return nil
+		}
+	}
+	m.registered = append(m.registered, row)
 	return nil
 }
 
@@ -274,6 +300,15 @@ func (m *MockDB) TouchLastSeen(_ context.Context, _ pgtype.UUID) error {
 	return m.Err
 }
 
+func (m *MockDB) Ping(_ context.Context) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.PingErr != nil {
+		return false, m.PingErr
+	}
+	return true, nil
+}
+
 func (m *MockDB) AgentExists(_ context.Context, id pgtype.UUID) (bool, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -384,6 +419,23 @@ func (m *MockDB) UpsertApplication(_ context.Context, _ database.UpsertApplicati
 	return m.Err
 }
 
+func (m *MockDB) UpsertInventory(_ context.Context, arg database.UpsertInventoryParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if m.Inventory == nil {
+		m.Inventory = make(map[string]map[string][]byte)
+	}
+	id := formatUUID(arg.AgentID)
+	if m.Inventory[id] == nil {
+		m.Inventory[id] = make(map[string][]byte)
+	}
+	m.Inventory[id][arg.Kind] = arg.Data
+	return nil
+}
+
 func (m *MockDB) UpsertUpdates(_ context.Context, _ database.UpsertUpdatesParams) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -447,7 +499,7 @@ func (m *MockDB) GetAgent(_ context.Context, _ pgtype.UUID) (database.GetAgentRo
 	if m.GetAgentErr != nil {
 		return database.GetAgentRow{}, m.GetAgentErr
 	}
-	return database.GetAgentRow{}, nil
+	return database.GetAgentRow{Hostname: m.AgentHostname}, nil
 }
 
 func (m *MockDB) DeleteAgent(_ context.Context, _ pgtype.UUID) error {
@@ -546,9 +598,10 @@ func (m *MockDB) GetPiRange(_ context.Context, _ database.GetPiRangeParams) ([]d
 	return []database.GetPiRangeRow{}, nil
 }
 
-func (m *MockDB) GetCPUBucketed(_ context.Context, _ database.GetCPUBucketedParams) ([]database.GetCPUBucketedRow, error) {
+func (m *MockDB) GetCPUBucketed(_ context.Context, arg database.GetCPUBucketedParams) ([]database.GetCPUBucketedRow, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.LastCPUBucketed = arg
 	if m.QueryErr != nil {
 		return nil, m.QueryErr
 	}
@@ -669,6 +722,25 @@ func (m *MockDB) GetApplications(_ context.Context, _ pgtype.UUID) ([]database.C
 	return []database.CurrentApplication{}, nil
 }
 
+func (m *MockDB) GetInventory(_ context.Context, id pgtype.UUID) ([]database.CurrentInventory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.QueryErr != nil {
+		return nil, m.QueryErr
+	}
+	kinds := m.Inventory[formatUUID(id)]
+	rows := make([]database.CurrentInventory, 0, len(kinds))
+	for _, kind := range slices.Sorted(maps.Keys(kinds)) {
+		rows = append(rows, database.CurrentInventory{
+			AgentID:   id,
+			Kind:      kind,
+			Data:      kinds[kind],
+			UpdatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		})
+	}
+	return rows, nil
+}
+
 func (m *MockDB) GetUpdates(_ context.Context, _ pgtype.UUID) (database.CurrentUpdate, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -684,7 +756,10 @@ func (m *MockDB) ListAgents(_ context.Context) ([]database.ListAgentsRow, error)
 	if m.QueryErr != nil {
 		return nil, m.QueryErr
 	}
-	return []database.ListAgentsRow{}, nil
+	if m.ListAgentsReturn != nil {
+		return m.ListAgentsReturn, nil
+	}
+	return append([]database.ListAgentsRow{}, m.registered...), nil
 }
 
 func (m *MockDB) CreateUser(_ context.Context, args database.CreateUserParams) error {
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nhdewitt/spectra/internal/logging"
+	"github.com/nhdewitt/spectra/internal/version"
+)
+
+// OTLP/HTTP JSON request shapes, covering only the gauge subset Spectra
+// sends. See opentelemetry-proto's metrics.proto for the full schema.
+type (
+	otlpRequest struct {
+		ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+	}
+	otlpResourceMetrics struct {
+		Resource     otlpResource       `json:"resource"`
+		ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeMetrics struct {
+		Scope   otlpScope    `json:"scope"`
+		Metrics []otlpMetric `json:"metrics"`
+	}
+	otlpScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+	otlpMetric struct {
+		Name  string    `json:"name"`
+		Gauge otlpGauge `json:"gauge"`
+	}
+	otlpGauge struct {
+		DataPoints []otlpDataPoint `json:"dataPoints"`
+	}
+	otlpDataPoint struct {
+		Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+		TimeUnixNano string         `json:"timeUnixNano"`
+		AsDouble     float64        `json:"asDouble"`
+	}
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+	otlpAnyValue struct {
+		StringValue string `json:"stringValue"`
+	}
+)
+
+// newOTLPExporter returns an exporter that pushes gauges to an OTLP/HTTP
+// collector. endpoint is the collector base URL (e.g.
+// http://otel:4318); /v1/metrics is appended unless already present.
+func newOTLPExporter(endpoint string, logger *logging.Logger) *batchExporter {
+	url := strings.TrimRight(endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/metrics") {
+		url += "/v1/metrics"
+	}
+	client := &http.Client{Timeout: exportFlushTimeout}
+
+	return newBatchExporter("otlp", logger, func(ctx context.Context, items []exportItem) error {
+		body, err := json.Marshal(buildOTLPRequest(items))
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("otlp collector returned %s", resp.Status)
+		}
+		return nil
+	})
+}
+
+// buildOTLPRequest groups items by host into one resource each, naming
+// every field spectra.<measurement>.<field> with the sample tags as
+// data point attributes.
+func buildOTLPRequest(items []exportItem) otlpRequest {
+	var req otlpRequest
+	byHost := make(map[string]int)
+
+	for _, item := range items {
+		idx, ok := byHost[item.Host]
+		if !ok {
+			idx = len(req.ResourceMetrics)
+			byHost[item.Host] = idx
+			req.ResourceMetrics = append(req.ResourceMetrics, otlpResourceMetrics{
+				Resource: otlpResource{Attributes: []otlpKeyValue{
+					{Key: "host.name", Value: otlpAnyValue{StringValue: item.Host}},
+				}},
+				ScopeMetrics: []otlpScopeMetrics{{
+					Scope: otlpScope{Name: "spectra", Version: version.Version},
+				}},
+			})
+		}
+		scope := &req.ResourceMetrics[idx].ScopeMetrics[0]
+
+		ts := strconv.FormatInt(item.Time.UnixNano(), 10)
+		for _, s := range item.Samples {
+			var attrs []otlpKeyValue
+			for _, t := range s.Tags {
+				if t.Value == "" {
+					continue
+				}
+				attrs = append(attrs, otlpKeyValue{Key: t.Key, Value: otlpAnyValue{StringValue: t.Value}})
+			}
+			for _, f := range s.Fields {
+				scope.Metrics = append(scope.Metrics, otlpMetric{
+					Name: "spectra." + s.Measurement + "." + f.Key,
+					Gauge: otlpGauge{DataPoints: []otlpDataPoint{{
+						Attributes:   attrs,
+						TimeUnixNano: ts,
+						AsDouble:     f.Value,
+					}}},
+				})
+			}
+		}
+	}
+
+	return req
+}
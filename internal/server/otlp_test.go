@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/export"
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestOTLPExporter_DeliversMetric(t *testing.T) {
+	received := make(chan otlpRequest, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/metrics" {
+			t.Errorf("path: got %s, want /v1/metrics", r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type: got %s", ct)
+		}
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		received <- req
+	}))
+	defer collector.Close()
+
+	s := New(Config{Port: 8080}, NewMockDB())
+	otlp := newOTLPExporter(collector.URL, s.Logger)
+	otlp.interval = 10 * time.Millisecond
+	done := make(chan struct{})
+	defer close(done)
+	go otlp.run(done)
+	s.exporters = []metricExporter{otlp}
+
+	ts := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.processMetric(context.Background(), testAgentUUID, RawEnvelope{
+		Type:      "network",
+		Timestamp: ts,
+		Hostname:  "pi",
+		Data:      []byte(`{"interface":"eth0","rx_bytes":1500,"tx_bytes":300}`),
+	})
+
+	var req otlpRequest
+	select {
+	case req = <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for OTLP export")
+	}
+
+	if len(req.ResourceMetrics) != 1 {
+		t.Fatalf("resourceMetrics: got %d, want 1", len(req.ResourceMetrics))
+	}
+	rm := req.ResourceMetrics[0]
+	if attrs := rm.Resource.Attributes; len(attrs) != 1 || attrs[0].Key != "host.name" || attrs[0].Value.StringValue != "pi" {
+		t.Errorf("resource attributes: got %+v, want host.name=pi", attrs)
+	}
+
+	var rx *otlpMetric
+	for i, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name == "spectra.network.rx_bytes" {
+			rx = &rm.ScopeMetrics[0].Metrics[i]
+		}
+	}
+	if rx == nil {
+		t.Fatal("spectra.network.rx_bytes not exported")
+	}
+	dp := rx.Gauge.DataPoints[0]
+	if dp.AsDouble != 1500 {
+		t.Errorf("rx_bytes: got %v, want 1500", dp.AsDouble)
+	}
+	if dp.TimeUnixNano != "1748779200000000000" {
+		t.Errorf("timeUnixNano: got %s", dp.TimeUnixNano)
+	}
+	if len(dp.Attributes) != 1 || dp.Attributes[0].Key != "iface" || dp.Attributes[0].Value.StringValue != "eth0" {
+		t.Errorf("data point attributes: got %+v, want iface=eth0", dp.Attributes)
+	}
+}
+
+func TestBatchExporter_DropsWhenFull(t *testing.T) {
+	s := New(Config{Port: 8080}, NewMockDB())
+	e := newOTLPExporter("http://127.0.0.1:1", s.Logger)
+	e.queue = make(chan exportItem, 1)
+
+	m := &protocol.CPUMetric{Usage: 10}
+	e.Export("pi", time.Now(), m)
+	e.Export("pi", time.Now(), m)
+
+	if got := e.dropped.Load(); got != 1 {
+		t.Errorf("dropped: got %d, want 1", got)
+	}
+}
+
+func TestExportSamples_SkipsNonNumeric(t *testing.T) {
+	if got := export.Samples(&protocol.ApplicationListMetric{}); got != nil {
+		t.Errorf("application_list: got %+v, want nil", got)
+	}
+}
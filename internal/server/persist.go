@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
@@ -53,7 +54,7 @@ func (s *Server) persistMetric(ctx context.Context, agentID string, ts time.Time
 			CpuUsage:       pgFloat8(m.Usage),
 			LoadNormalized: pgFloat8(normalized),
 		}); cacheErr != nil {
-			s.Logger.Warn("error updating current_metrics", "metric", "cpu", "error", cacheErr)
+			s.Logger.WarnContext(ctx, "error updating current_metrics", "metric", "cpu", "error", cacheErr)
 		}
 
 	case *protocol.MemoryMetric:
@@ -74,7 +75,7 @@ func (s *Server) persistMetric(ctx context.Context, agentID string, ts time.Time
 			RamPercent:  pgFloat8(m.UsedPct),
 			SwapPercent: pgFloat8(m.SwapPct),
 		}); cacheErr != nil {
-			s.Logger.Warn("error updating current_metrics", "metric", "memory", "error", cacheErr)
+			s.Logger.WarnContext(ctx, "error updating current_metrics", "metric", "memory", "error", cacheErr)
 		}
 
 	case *protocol.DiskMetric:
@@ -92,10 +93,11 @@ func (s *Server) persistMetric(ctx context.Context, agentID string, ts time.Time
 			InodesTotal:   pgInt8(int64(m.InodesTotal)),
 			InodesUsed:    pgInt8(int64(m.InodesUsed)),
 			InodesPercent: pgFloat8(m.InodesPct),
+			ReadOnly:      pgBool(m.ReadOnly),
 		})
 
 		if cacheErr := s.DB.UpsertCurrentDiskMax(ctx, uid); cacheErr != nil {
-			s.Logger.Warn("error updating current_metrics", "metric", "disk", "error", cacheErr)
+			s.Logger.WarnContext(ctx, "error updating current_metrics", "metric", "disk", "error", cacheErr)
 		}
 
 	case *protocol.DiskIOMetric:
@@ -131,7 +133,7 @@ func (s *Server) persistMetric(ctx context.Context, agentID string, ts time.Time
 		})
 
 		if cacheErr := s.DB.UpsertCurrentNetwork(ctx, uid); cacheErr != nil {
-			s.Logger.Warn("error updating current_metrics", "metric", "network", "error", cacheErr)
+			s.Logger.WarnContext(ctx, "error updating current_metrics", "metric", "network", "error", cacheErr)
 		}
 
 	case *protocol.TemperatureMetric:
@@ -148,7 +150,7 @@ func (s *Server) persistMetric(ctx context.Context, agentID string, ts time.Time
 		})
 
 		if cacheErr := s.DB.UpsertCurrentTemperature(ctx, uid); cacheErr != nil {
-			s.Logger.Warn("error updating current_metrics", "metric", "temperature", "error", cacheErr)
+			s.Logger.WarnContext(ctx, "error updating current_metrics", "metric", "temperature", "error", cacheErr)
 		}
 
 	case *protocol.SystemMetric:
@@ -166,7 +168,7 @@ func (s *Server) persistMetric(ctx context.Context, agentID string, ts time.Time
 			Uptime:       pgInt8(int64(m.Uptime)),
 			ProcessCount: pgInt4(int32(m.Processes)),
 		}); cacheErr != nil {
-			s.Logger.Warn("error updating current_metrics", "metric", "system", "error", cacheErr)
+			s.Logger.WarnContext(ctx, "error updating current_metrics", "metric", "system", "error", cacheErr)
 		}
 
 	case *protocol.WiFiMetric:
@@ -184,20 +186,22 @@ func (s *Server) persistMetric(ctx context.Context, agentID string, ts time.Time
 
 	case *protocol.ContainerMetric:
 		err = s.DB.InsertContainer(ctx, database.InsertContainerParams{
-			Time:        t,
-			AgentID:     uid,
-			ContainerID: pgText(m.ID),
-			Name:        pgText(m.Name),
-			Image:       pgText(m.Image),
-			State:       pgText(m.State),
-			Source:      pgText(m.Source),
-			Kind:        pgText(m.Kind),
-			CpuPercent:  pgFloat8(m.CPUPercent),
-			CpuCores:    pgInt4(int32(m.CPULimitCores)),
-			MemoryBytes: pgInt8(int64(m.MemoryBytes)),
-			MemoryLimit: pgInt8(int64(m.MemoryLimit)),
-			NetRxBytes:  pgInt8(int64(m.NetRxBytes)),
-			NetTxBytes:  pgInt8(int64(m.NetTxBytes)),
+			Time:         t,
+			AgentID:      uid,
+			ContainerID:  pgText(m.ID),
+			Name:         pgText(m.Name),
+			Image:        pgText(m.Image),
+			State:        pgText(m.State),
+			Source:       pgText(m.Source),
+			Kind:         pgText(m.Kind),
+			CpuPercent:   pgFloat8(m.CPUPercent),
+			CpuCores:     pgInt4(int32(m.CPULimitCores)),
+			MemoryBytes:  pgInt8(int64(m.MemoryBytes)),
+			MemoryLimit:  pgInt8(int64(m.MemoryLimit)),
+			NetRxBytes:   pgInt8(int64(m.NetRxBytes)),
+			NetTxBytes:   pgInt8(int64(m.NetTxBytes)),
+			RestartCount: pgInt4(int32(m.RestartCount)),
+			Health:       pgText(m.Health),
 		})
 
 	case *protocol.ContainerListMetric:
@@ -219,7 +223,7 @@ func (s *Server) persistMetric(ctx context.Context, agentID string, ts time.Time
 				Status:     pgText(string(p.Status)),
 				Threads:    pgInt4(int32(p.ThreadsTotal)),
 			}); upsertErr != nil {
-				s.Logger.Warn("error upserting process", "pid", p.Pid, "error", upsertErr)
+				s.Logger.WarnContext(ctx, "error upserting process", "pid", p.Pid, "error", upsertErr)
 			}
 		}
 		// Remove processes that weren't in this batch
@@ -236,7 +240,7 @@ func (s *Server) persistMetric(ctx context.Context, agentID string, ts time.Time
 				Status:    pgText(svc.Status),
 				SubStatus: pgText(svc.SubStatus),
 			}); upsertErr != nil {
-				s.Logger.Warn("error upserting service", "service", svc.Name, "error", upsertErr)
+				s.Logger.WarnContext(ctx, "error upserting service", "service", svc.Name, "error", upsertErr)
 			}
 		}
 		return
@@ -248,16 +252,37 @@ func (s *Server) persistMetric(ctx context.Context, agentID string, ts time.Time
 				Name:    app.Name,
 				Version: pgText(app.Version),
 			}); upsertErr != nil {
-				s.Logger.Warn("error upserting application", "name", app.Name, "error", upsertErr)
+				s.Logger.WarnContext(ctx, "error upserting application", "name", app.Name, "error", upsertErr)
 			}
 		}
 		return
 
+	case *protocol.FailedUnitListMetric:
+		err = s.upsertInventory(ctx, uid, "failed_units", m.Units)
+
+	case *protocol.TimerListMetric:
+		err = s.upsertInventory(ctx, uid, "timers", m.Timers)
+
+	case *protocol.WiFiScanMetric:
+		err = s.upsertInventory(ctx, uid, "wifi_scan", m.Networks)
+
+	case *protocol.ARPTableMetric:
+		err = s.upsertInventory(ctx, uid, "arp", m.Entries)
+
+	case *protocol.USBDeviceListMetric:
+		err = s.upsertInventory(ctx, uid, "usb", m.Devices)
+
+	case *protocol.PCIDeviceListMetric:
+		err = s.upsertInventory(ctx, uid, "pci", m.Devices)
+
+	case *protocol.DMIMetric:
+		err = s.upsertInventory(ctx, uid, "dmi", m)
+
 	case *protocol.ClockMetric:
 		err = s.DB.InsertPi(ctx, database.InsertPiParams{
 			Time:       t,
 			AgentID:    uid,
-			MetricType: "clock",
+			MetricType: protocol.TypeClock,
 			ArmFreqHz:  pgInt8(int64(m.ArmFreq)),
 			CoreFreqHz: pgInt8(int64(m.CoreFreq)),
 			GpuFreqHz:  pgInt8(int64(m.GPUFreq)),
@@ -267,7 +292,7 @@ func (s *Server) persistMetric(ctx context.Context, agentID string, ts time.Time
 		err = s.DB.InsertPi(ctx, database.InsertPiParams{
 			Time:        t,
 			AgentID:     uid,
-			MetricType:  "voltage",
+			MetricType:  protocol.TypeVoltage,
 			CoreVolts:   pgFloat8(m.Core),
 			SdramCVolts: pgFloat8(m.SDRamC),
 			SdramIVolts: pgFloat8(m.SDRamI),
@@ -278,7 +303,7 @@ func (s *Server) persistMetric(ctx context.Context, agentID string, ts time.Time
 		err = s.DB.InsertPi(ctx, database.InsertPiParams{
 			Time:                  t,
 			AgentID:               uid,
-			MetricType:            "throttle",
+			MetricType:            protocol.TypeThrottle,
 			Throttled:             pgBool(m.Throttled),
 			UnderVoltage:          pgBool(m.Undervoltage),
 			FreqCapped:            pgBool(m.ArmFreqCapped),
@@ -293,7 +318,7 @@ func (s *Server) persistMetric(ctx context.Context, agentID string, ts time.Time
 		err = s.DB.InsertPi(ctx, database.InsertPiParams{
 			Time:        t,
 			AgentID:     uid,
-			MetricType:  "gpu",
+			MetricType:  protocol.TypeGPU,
 			GpuMemTotal: pgInt8(int64(m.MemoryTotal)),
 			GpuMemUsed:  pgInt8(int64(m.MemoryUsed)),
 		})
@@ -311,7 +336,7 @@ func (s *Server) persistMetric(ctx context.Context, agentID string, ts time.Time
 			AgentID:        uid,
 			RebootRequired: m.RebootRequired,
 		}); cacheErr != nil {
-			s.Logger.Warn("error updating current_metrics", "metric", "updates", "error", cacheErr)
+			s.Logger.WarnContext(ctx, "error updating current_metrics", "metric", "updates", "error", cacheErr)
 		}
 
 	default:
@@ -320,8 +345,21 @@ func (s *Server) persistMetric(ctx context.Context, agentID string, ts time.Time
 	}
 
 	if err != nil {
-		s.Logger.Error("failed to persist metric", "metric", metric.MetricType(), "agent_id", agentID, "error", err)
+		s.Logger.ErrorContext(ctx, "failed to persist metric", "metric", metric.MetricType(), "agent_id", agentID, "error", err)
+	}
+}
+
+// upsertInventory stores v as the agent's current inventory snapshot of kind.
+func (s *Server) upsertInventory(ctx context.Context, uid pgtype.UUID, kind string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
 	}
+	return s.DB.UpsertInventory(ctx, database.UpsertInventoryParams{
+		AgentID: uid,
+		Kind:    kind,
+		Data:    data,
+	})
 }
 
 func pgText(s string) pgtype.Text {
@@ -3,6 +3,7 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
 	"time"
@@ -167,6 +168,105 @@ func TestPersistMetric(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "USBDeviceList",
+			metric: &protocol.USBDeviceListMetric{
+				Devices: []protocol.USBDeviceMetric{{Port: "1-1", VendorID: "046d", ProductID: "c52b"}},
+			},
+			checkMock: func(t *testing.T, m *MockDB) {
+				var got []protocol.USBDeviceMetric
+				for _, kinds := range m.Inventory {
+					if err := json.Unmarshal(kinds["usb"], &got); err != nil {
+						t.Fatalf("usb inventory: %v", err)
+					}
+				}
+				if len(got) != 1 || got[0].VendorID != "046d" {
+					t.Errorf("usb inventory: got %+v, want one 046d device", got)
+				}
+			},
+		},
+		{
+			name: "PCIDeviceList",
+			metric: &protocol.PCIDeviceListMetric{
+				Devices: []protocol.PCIDeviceMetric{{Slot: "00:02.0", VendorID: "8086", DeviceID: "5916"}},
+			},
+			checkMock: func(t *testing.T, m *MockDB) {
+				for _, kinds := range m.Inventory {
+					if _, ok := kinds["pci"]; ok {
+						return
+					}
+				}
+				t.Error("expected a pci inventory snapshot")
+			},
+		},
+		{
+			name: "WiFiScan",
+			metric: &protocol.WiFiScanMetric{
+				Networks: []protocol.WiFiNetwork{{Interface: "wlan0", BSSID: "11:22:33:44:55:66", SSID: "HomeNet"}},
+			},
+			checkMock: func(t *testing.T, m *MockDB) {
+				for _, kinds := range m.Inventory {
+					if _, ok := kinds["wifi_scan"]; ok {
+						return
+					}
+				}
+				t.Error("expected a wifi_scan inventory snapshot")
+			},
+		},
+		{
+			name: "ARPTable",
+			metric: &protocol.ARPTableMetric{
+				Entries: []protocol.ARPEntry{{IP: "192.168.1.1", MAC: "aa:bb:cc:dd:ee:ff", Device: "eth0", State: protocol.ARPComplete}},
+			},
+			checkMock: func(t *testing.T, m *MockDB) {
+				for _, kinds := range m.Inventory {
+					if _, ok := kinds["arp"]; ok {
+						return
+					}
+				}
+				t.Error("expected an arp inventory snapshot")
+			},
+		},
+		{
+			name: "FailedUnitList",
+			metric: &protocol.FailedUnitListMetric{
+				Units: []protocol.FailedUnitMetric{{Unit: "nginx.service", ActiveState: "failed"}},
+			},
+			checkMock: func(t *testing.T, m *MockDB) {
+				for _, kinds := range m.Inventory {
+					if _, ok := kinds["failed_units"]; ok {
+						return
+					}
+				}
+				t.Error("expected a failed_units inventory snapshot")
+			},
+		},
+		{
+			name: "TimerList",
+			metric: &protocol.TimerListMetric{
+				Timers: []protocol.TimerMetric{{Unit: "logrotate.timer", Activates: "logrotate.service"}},
+			},
+			checkMock: func(t *testing.T, m *MockDB) {
+				for _, kinds := range m.Inventory {
+					if _, ok := kinds["timers"]; ok {
+						return
+					}
+				}
+				t.Error("expected a timers inventory snapshot")
+			},
+		},
+		{
+			name:   "DMI",
+			metric: &protocol.DMIMetric{SysVendor: "LENOVO", BIOSVersion: "N1QET98W"},
+			checkMock: func(t *testing.T, m *MockDB) {
+				for _, kinds := range m.Inventory {
+					if _, ok := kinds["dmi"]; ok {
+						return
+					}
+				}
+				t.Error("expected a dmi inventory snapshot")
+			},
+		},
 		{
 			name:   "Clock (Pi)",
 			metric: &protocol.ClockMetric{ArmFreq: 1500000000, CoreFreq: 500000000, GPUFreq: 400000000},
@@ -243,7 +343,9 @@ func TestPersistMetric_UnknownMetricType(t *testing.T) {
 // unknownMetric is a test-only type to exercise the default branch.
 type unknownMetric struct{}
 
-func (unknownMetric) MetricType() string { return "unknown_test" }
+func (unknownMetric) MetricType() string       { return "unknown_test" }
+func (unknownMetric) Validate() error          { return nil }
+func (m unknownMetric) Clone() protocol.Metric { return &m }
 
 func TestPersistMetric_DBError(t *testing.T) {
 	s, _, _, mock := newTestServer()
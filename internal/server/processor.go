@@ -4,19 +4,83 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
-// processMetric is the entry point for handling a raw metric envelope
-func (s *Server) processMetric(agentID string, env RawEnvelope) {
+// processMetric is the entry point for handling a raw metric envelope.
+// ctx carries the ingest request's values, such as its request ID, for
+// logging; it is not cancelled when the request ends.
+func (s *Server) processMetric(ctx context.Context, agentID string, env RawEnvelope) {
+	// Newer agents may add fields we don't know about yet. Decode what we
+	// can and flag the mismatch rather than dropping the metric.
+	if env.SchemaVersion > protocol.SchemaVersion {
+		total := s.futureEnvelopes.Add(1)
+		s.Logger.WarnContext(ctx, "envelope from newer schema version",
+			"agent_id", agentID,
+			"type", env.Type,
+			"schema_version", env.SchemaVersion,
+			"supported", protocol.SchemaVersion,
+			"total", total,
+		)
+	}
+
 	metric, err := s.unmarshalMetric(env.Type, env.Data)
 	if err != nil {
-		s.Logger.Warn("error processing metric", "hostname", env.Hostname, "error", err)
+		s.Logger.WarnContext(ctx, "error processing metric", "hostname", env.Hostname, "error", err)
+		return
+	}
+
+	if err := metric.Validate(); err != nil {
+		total := s.invalidEnvelopes.Add(1)
+		s.Logger.WarnContext(ctx, "dropping invalid metric",
+			"agent_id", agentID,
+			"type", env.Type,
+			"error", err,
+			"total", total,
+		)
 		return
 	}
 
-	s.persistMetric(context.Background(), agentID, env.Timestamp, metric)
+	s.persistMetric(ctx, agentID, env.Timestamp, metric)
+
+	for _, e := range s.exporters {
+		e.Export(env.Hostname, env.Timestamp, metric)
+	}
+
+	for _, fn := range s.metricHandlers[metric.MetricType()] {
+		fn(ctx, agentID, env.Timestamp, metric)
+	}
+}
+
+// MetricHandler runs type-specific logic for one validated metric. ctx is
+// the one passed to processMetric.
+type MetricHandler func(ctx context.Context, agentID string, ts time.Time, m protocol.Metric)
+
+// OnMetric registers fn to run for every validated metric of metricType,
+// after it has been persisted and exported. Handlers run in registration
+// order on the ingest goroutine, so slow work should be handed off.
+// Register handlers before Start; the registry isn't locked.
+func (s *Server) OnMetric(metricType string, fn MetricHandler) {
+	if s.metricHandlers == nil {
+		s.metricHandlers = make(map[string][]MetricHandler)
+	}
+	s.metricHandlers[metricType] = append(s.metricHandlers[metricType], fn)
+}
+
+// warnCollectorFailing surfaces agent collectors that keep erroring.
+func (s *Server) warnCollectorFailing(ctx context.Context, agentID string, _ time.Time, m protocol.Metric) {
+	h, ok := m.(*protocol.CollectorHealthMetric)
+	if !ok || h.ConsecutiveErrors == 0 {
+		return
+	}
+	s.Logger.WarnContext(ctx, "agent collector failing",
+		"agent_id", agentID,
+		"collector", h.Name,
+		"consecutive_errors", h.ConsecutiveErrors,
+		"last_error", h.LastError,
+	)
 }
 
 // unmarshalMetric converts raw JSON into a concrete protocol.Metric struct
@@ -24,46 +88,90 @@ func (s *Server) unmarshalMetric(typ string, data []byte) (protocol.Metric, erro
 	var metric protocol.Metric
 
 	switch typ {
-	case "cpu":
+	case protocol.TypeCPU:
 		metric = &protocol.CPUMetric{}
-	case "memory":
+	case protocol.TypeMemory:
 		metric = &protocol.MemoryMetric{}
-	case "disk":
+	case protocol.TypeDisk:
 		metric = &protocol.DiskMetric{}
-	case "disk_io":
+	case protocol.TypeDiskIO:
 		metric = &protocol.DiskIOMetric{}
-	case "network":
+	case protocol.TypeNetwork:
 		metric = &protocol.NetworkMetric{}
-	case "wifi":
+	case protocol.TypeWiFi:
 		metric = &protocol.WiFiMetric{}
-	case "clock":
+	case protocol.TypeClock:
 		metric = &protocol.ClockMetric{}
-	case "voltage":
+	case protocol.TypeVoltage:
 		metric = &protocol.VoltageMetric{}
-	case "throttle":
+	case protocol.TypeThrottle:
 		metric = &protocol.ThrottleMetric{}
-	case "gpu":
+	case protocol.TypeGPU:
 		metric = &protocol.GPUMetric{}
-	case "system":
+	case protocol.TypeGPUProcess:
+		metric = &protocol.GPUProcessMetric{}
+	case protocol.TypeSystem:
 		metric = &protocol.SystemMetric{}
-	case "process":
+	case protocol.TypeProcess:
 		metric = &protocol.ProcessMetric{}
-	case "process_list":
+	case protocol.TypeProcessList:
 		metric = &protocol.ProcessListMetric{}
-	case "temperature":
+	case protocol.TypeTemperature:
 		metric = &protocol.TemperatureMetric{}
-	case "service":
+	case protocol.TypeService:
 		metric = &protocol.ServiceMetric{}
-	case "service_list":
+	case protocol.TypeServiceList:
 		metric = &protocol.ServiceListMetric{}
-	case "application_list":
+	case protocol.TypeARPTable:
+		metric = &protocol.ARPTableMetric{}
+	case protocol.TypeSocketStat:
+		metric = &protocol.SocketStatMetric{}
+	case protocol.TypeTCPStat:
+		metric = &protocol.TCPStatMetric{}
+	case protocol.TypeWiFiScan:
+		metric = &protocol.WiFiScanMetric{}
+	case protocol.TypeUSBDevice:
+		metric = &protocol.USBDeviceMetric{}
+	case protocol.TypeUSBDeviceList:
+		metric = &protocol.USBDeviceListMetric{}
+	case protocol.TypePCIDevice:
+		metric = &protocol.PCIDeviceMetric{}
+	case protocol.TypePCIDeviceList:
+		metric = &protocol.PCIDeviceListMetric{}
+	case protocol.TypeSensor:
+		metric = &protocol.SensorMetric{}
+	case protocol.TypePowerDraw:
+		metric = &protocol.PowerDrawMetric{}
+	case protocol.TypeSched:
+		metric = &protocol.SchedMetric{}
+	case protocol.TypeSwapDevice:
+		metric = &protocol.SwapDeviceMetric{}
+	case protocol.TypeSlab:
+		metric = &protocol.SlabMetric{}
+	case protocol.TypeEntropy:
+		metric = &protocol.EntropyMetric{}
+	case protocol.TypeProcessSummary:
+		metric = &protocol.ProcessSummaryMetric{}
+	case protocol.TypeFailedUnit:
+		metric = &protocol.FailedUnitMetric{}
+	case protocol.TypeFailedUnitList:
+		metric = &protocol.FailedUnitListMetric{}
+	case protocol.TypeTimer:
+		metric = &protocol.TimerMetric{}
+	case protocol.TypeTimerList:
+		metric = &protocol.TimerListMetric{}
+	case protocol.TypeDMI:
+		metric = &protocol.DMIMetric{}
+	case protocol.TypeApplicationList:
 		metric = &protocol.ApplicationListMetric{}
-	case "container":
+	case protocol.TypeContainer:
 		metric = &protocol.ContainerMetric{}
-	case "container_list":
+	case protocol.TypeContainerList:
 		metric = &protocol.ContainerListMetric{}
-	case "updates":
+	case protocol.TypeUpdates:
 		metric = &protocol.UpdateMetric{}
+	case protocol.TypeCollectorHealth:
+		metric = &protocol.CollectorHealthMetric{}
 	default:
 		return nil, fmt.Errorf("unknown metric type: %s", typ)
 	}
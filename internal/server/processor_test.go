@@ -1,8 +1,11 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
@@ -29,9 +32,24 @@ func TestUnmarshalMetric_AllTypes(t *testing.T) {
 		{"temperature", `{"sensor": "coretemp", "temperature": 45.5}`, "temperature"},
 		{"service", `{"name": "nginx", "status": "active"}`, "service"},
 		{"service_list", `{"services": [{"name": "nginx", "status": "active"}]}`, "service_list"},
+		{"sensor", `{"source": "ipmi", "name": "Fan1A RPM", "kind": "fan", "value": 4200, "unit": "RPM", "status": "ok"}`, "sensor"},
+		{"power_draw", `{"domain": "package-0", "watts": 15.2}`, "power_draw"},
+		{"sched", `{"ctxt_per_sec": 12000.5, "intr_per_sec": 4000}`, "sched"},
+		{"swap_device", `{"name": "/swapfile", "type": "file", "size": 2147483648, "used": 1048576, "priority": -2}`, "swap_device"},
+		{"slab", `{"total_bytes": 104857600, "caches": [{"name": "dentry", "active_objs": 10, "num_objs": 20, "obj_size": 192, "bytes": 4096}]}`, "slab"},
+		{"entropy", `{"entropy_avail": 256, "pool_size": 256}`, "entropy"},
+		{"process_summary", `{"total": 120, "running": 2, "sleeping": 117, "zombie": 1, "threads": 480}`, "process_summary"},
+		{"failed_unit_list", `{"units": [{"unit": "nginx.service", "load_state": "loaded", "active_state": "failed", "sub_state": "failed"}]}`, "failed_unit_list"},
+		{"timer_list", `{"timers": [{"unit": "logrotate.timer", "activates": "logrotate.service", "left": "5h"}]}`, "timer_list"},
+		{"dmi", `{"sys_vendor": "LENOVO", "bios_version": "N1QET98W", "chassis_type": "Notebook"}`, "dmi"},
+		{"pci_device_list", `{"devices": [{"slot": "00:02.0", "class_id": "0300", "vendor_id": "8086", "device_id": "5916"}]}`, "pci_device_list"},
+		{"arp_table", `{"entries": [{"ip": "192.168.1.1", "mac": "aa:bb:cc:dd:ee:ff", "device": "eth0", "state": "complete"}]}`, "arp_table"},
+		{"wifi_scan", `{"networks": [{"interface": "wlan0", "ssid": "HomeNet", "bssid": "11:22:33:44:55:66", "frequency_ghz": 5.18, "channel": 36, "signal_dbm": -52}]}`, "wifi_scan"},
+		{"usb_device_list", `{"devices": [{"port": "1-1", "vendor_id": "046d", "product_id": "c52b"}]}`, "usb_device_list"},
 		{"application_list", `{"applications": [{"name": "vim", "version": "8.0"}]}`, "application_list"},
 		{"container", `{"id": "abc123", "name": "nginx", "state": "running"}`, "container"},
 		{"container_list", `{"containers": [{"id": "abc123", "name": "nginx"}]}`, "container_list"},
+		{"collector_health", `{"name": "wifi", "consecutive_errors": 3}`, "collector_health"},
 	}
 
 	s := New(Config{Port: 8080}, NewMockDB())
@@ -217,3 +235,106 @@ func BenchmarkUnmarshalMetric_ContainerList(b *testing.B) {
 		_, _ = s.unmarshalMetric("container_list", data)
 	}
 }
+
+func TestProcessMetric_SchemaVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    int
+		wantFuture int64
+	}{
+		{"legacy", 0, 0},
+		{"current", protocol.SchemaVersion, 0},
+		{"future", protocol.SchemaVersion + 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := NewMockDB()
+			s := New(Config{Port: 8080}, db)
+
+			var env RawEnvelope
+			body := fmt.Sprintf(`{"type":"cpu","schema_version":%d,"timestamp":"2025-01-01T00:00:00Z","hostname":"h","data":{"usage":12.5}}`, tt.version)
+			if err := json.Unmarshal([]byte(body), &env); err != nil {
+				t.Fatalf("unmarshal envelope: %v", err)
+			}
+			if env.SchemaVersion != tt.version {
+				t.Fatalf("SchemaVersion: got %d, want %d", env.SchemaVersion, tt.version)
+			}
+
+			s.processMetric(context.Background(), testAgentUUID, env)
+
+			if got := s.futureEnvelopes.Load(); got != tt.wantFuture {
+				t.Errorf("futureEnvelopes: got %d, want %d", got, tt.wantFuture)
+			}
+			if db.InsertCPUCount != 1 {
+				t.Errorf("expected metric to be persisted, InsertCPUCount = %d", db.InsertCPUCount)
+			}
+		})
+	}
+}
+
+func TestProcessMetric_DropsInvalid(t *testing.T) {
+	db := NewMockDB()
+	s := New(Config{Port: 8080}, db)
+
+	s.processMetric(context.Background(), testAgentUUID, RawEnvelope{
+		Type: "memory",
+		Data: []byte(`{"ram_total": 100, "ram_used": 500}`),
+	})
+
+	if db.InsertMemoryCount != 0 {
+		t.Errorf("invalid metric should not be persisted, InsertMemoryCount = %d", db.InsertMemoryCount)
+	}
+	if got := s.invalidEnvelopes.Load(); got != 1 {
+		t.Errorf("invalidEnvelopes: got %d, want 1", got)
+	}
+}
+
+func TestOnMetric_CalledWithDecodedMetric(t *testing.T) {
+	s := New(Config{Port: 8080}, NewMockDB())
+
+	var (
+		calls   int
+		gotID   string
+		gotTS   time.Time
+		gotType string
+		usage   float64
+	)
+	s.OnMetric("cpu", func(_ context.Context, agentID string, ts time.Time, m protocol.Metric) {
+		calls++
+		gotID, gotTS, gotType = agentID, ts, m.MetricType()
+		if cpu, ok := m.(*protocol.CPUMetric); ok {
+			usage = cpu.Usage
+		}
+	})
+	memCalls := 0
+	s.OnMetric("memory", func(context.Context, string, time.Time, protocol.Metric) { memCalls++ })
+
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.processMetric(context.Background(), testAgentUUID, RawEnvelope{Type: "cpu", Timestamp: ts, Data: []byte(`{"usage": 42.5}`)})
+
+	if calls != 1 {
+		t.Fatalf("cpu handler calls: got %d, want 1", calls)
+	}
+	if gotID != testAgentUUID || !gotTS.Equal(ts) || gotType != "cpu" || usage != 42.5 {
+		t.Errorf("handler got agent=%q ts=%v type=%q usage=%v", gotID, gotTS, gotType, usage)
+	}
+	if memCalls != 0 {
+		t.Errorf("memory handler should not run for cpu, ran %d times", memCalls)
+	}
+}
+
+func TestOnMetric_SkippedForInvalidMetric(t *testing.T) {
+	s := New(Config{Port: 8080}, NewMockDB())
+	called := false
+	s.OnMetric("memory", func(context.Context, string, time.Time, protocol.Metric) { called = true })
+
+	s.processMetric(context.Background(), testAgentUUID, RawEnvelope{
+		Type: "memory",
+		Data: []byte(`{"ram_total": 100, "ram_used": 500}`),
+	})
+
+	if called {
+		t.Error("handler should not run for a metric that failed validation")
+	}
+}
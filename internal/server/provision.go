@@ -44,16 +44,16 @@ type agentConfig struct {
 // GET /api/v1/admin/platforms
 func (s *Server) handleListPlatforms(w http.ResponseWriter, r *http.Request) {
 	if s.Releases == nil {
-		respondJSON(w, http.StatusOK, []platformInfo{})
+		s.respondJSON(w, http.StatusOK, []platformInfo{})
 		return
 	}
 
 	available := s.Releases.availablePlatforms()
 	if available == nil {
-		s.Logger.Warn("no agent builds available")
+		s.Logger.WarnContext(r.Context(), "no agent builds available")
 		available = []platformInfo{}
 	}
-	respondJSON(w, http.StatusOK, available)
+	s.respondJSON(w, http.StatusOK, available)
 }
 
 // handleProvision generates a one-time registration token and returns
@@ -99,7 +99,7 @@ func (s *Server) handleProvision(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Generate one-time token
-	s.Logger.Info("one-time token provisioned", "ip", clientIP(r))
+	s.Logger.InfoContext(r.Context(), "one-time token provisioned", "ip", clientIP(r))
 	token := s.Tokens.Generate(24 * time.Hour)
 
 	// Build server URL from request
@@ -117,7 +117,7 @@ func (s *Server) handleProvision(w http.ResponseWriter, r *http.Request) {
 	if s.Config.TLSCA != "" {
 		data, err := os.ReadFile(s.Config.TLSCA)
 		if err != nil {
-			s.Logger.Error("failed to read TLS CA", "path", s.Config.TLSCA, "error", err)
+			s.Logger.ErrorContext(r.Context(), "failed to read TLS CA", "path", s.Config.TLSCA, "error", err)
 			http.Error(w, "TLS CA is configured but could not be read", http.StatusInternalServerError)
 			return
 		}
@@ -161,7 +161,7 @@ func (s *Server) handleProvision(w http.ResponseWriter, r *http.Request) {
 		Install:     install,
 	}
 
-	respondJSON(w, http.StatusCreated, resp)
+	s.respondJSON(w, http.StatusCreated, resp)
 }
 
 // handleDownloadRelease serves a verified agent binary.
@@ -182,7 +182,7 @@ func (s *Server) handleDownloadRelease(w http.ResponseWriter, r *http.Request) {
 	f, size, err := s.Releases.verifyAndOpen(filename)
 	if err != nil {
 		if strings.Contains(err.Error(), "integrity check failed") {
-			s.Logger.Error("binary integrity check failed", "filename", filename, "error", err)
+			s.Logger.ErrorContext(r.Context(), "binary integrity check failed", "filename", filename, "error", err)
 			http.Error(w, "binary integrity check failed", http.StatusInternalServerError)
 			return
 		}
@@ -218,7 +218,7 @@ func (s *Server) handleDownloadConfig(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Disposition", `attachment; filename="spectra-agent.json"`)
 
 	if _, err := w.Write(data); err != nil {
-		s.Logger.Warn("failed to write config response", "error", err)
+		s.Logger.WarnContext(r.Context(), "failed to write config response", "error", err)
 	}
 }
 
@@ -1,7 +1,9 @@
 package server
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -20,7 +22,22 @@ const (
 	agentBurst = 30
 )
 
-// rateLimiter implements a per-key token bucket rate limiter.
+// RateLimit overrides one tier's token bucket. A zero Rate or Burst keeps
+// the built-in default; a negative Rate disables limiting for the tier.
+type RateLimit struct {
+	Rate  float64 // requests per second
+	Burst int
+}
+
+// RateLimits configures each limiter tier.
+type RateLimits struct {
+	Anon   RateLimit // login, register; keyed by client IP
+	Authed RateLimit // dashboard and admin API; keyed by username
+	Agent  RateLimit // agent ingestion and polling; keyed by agent ID
+}
+
+// rateLimiter implements a per-key token bucket rate limiter. A nil
+// *rateLimiter allows everything, which is how a disabled tier is represented.
 type rateLimiter struct {
 	mu      sync.Mutex
 	buckets map[string]*bucket
@@ -49,11 +66,24 @@ func newRateLimiter(rate float64, burst int) *rateLimiter {
 }
 
 func (rl *rateLimiter) Stop() {
+	if rl == nil {
+		return
+	}
 	close(rl.done)
 }
 
-// allow checks whether the given IP has tokens available.
+// allow checks whether the given key has tokens available.
 func (rl *rateLimiter) allow(key string) bool {
+	ok, _ := rl.reserve(key)
+	return ok
+}
+
+// reserve takes a token for key if one is available. When it isn't, the
+// returned duration is how long until the next token refills.
+func (rl *rateLimiter) reserve(key string) (bool, time.Duration) {
+	if rl == nil {
+		return true, 0
+	}
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
@@ -64,7 +94,7 @@ func (rl *rateLimiter) allow(key string) bool {
 			tokens:   float64(rl.burst) - 1,
 			lastSeen: now,
 		}
-		return true
+		return true, 0
 	}
 
 	// Refill based on elapsed time
@@ -73,11 +103,11 @@ func (rl *rateLimiter) allow(key string) bool {
 	b.lastSeen = now
 
 	if b.tokens < 1 {
-		return false
+		return false, time.Duration((1 - b.tokens) / rl.rate * float64(time.Second))
 	}
 
 	b.tokens--
-	return true
+	return true, 0
 }
 
 // cleanupLoop periodically removes stale bucket entries.
@@ -110,11 +140,39 @@ type tieredLimiters struct {
 }
 
 func newTieredLimiters() *tieredLimiters {
+	return newConfiguredLimiters(RateLimits{})
+}
+
+// newConfiguredLimiters builds the tiers from cfg, falling back to the
+// built-in defaults for unset fields.
+func newConfiguredLimiters(cfg RateLimits) *tieredLimiters {
 	return &tieredLimiters{
-		anon:   newRateLimiter(anonRate, anonBurst),
-		authed: newRateLimiter(authedRate, authedBurst),
-		agent:  newRateLimiter(agentRate, agentBurst),
+		anon:   newTierLimiter(cfg.Anon, anonRate, anonBurst),
+		authed: newTierLimiter(cfg.Authed, authedRate, authedBurst),
+		agent:  newTierLimiter(cfg.Agent, agentRate, agentBurst),
+	}
+}
+
+// newTierLimiter returns nil when the tier is disabled.
+func newTierLimiter(cfg RateLimit, rate float64, burst int) *rateLimiter {
+	if cfg.Rate < 0 {
+		return nil
 	}
+	if cfg.Rate > 0 {
+		rate = cfg.Rate
+	}
+	if cfg.Burst > 0 {
+		burst = cfg.Burst
+	}
+	return newRateLimiter(rate, burst)
+}
+
+// tooManyRequests rejects a rate-limited request, telling the client when
+// a token will be available again.
+func tooManyRequests(w http.ResponseWriter, retry time.Duration) {
+	secs := max(1, int(math.Ceil(retry.Seconds())))
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+	http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
 }
 
 func (tl *tieredLimiters) Stop() {
@@ -127,9 +185,9 @@ func (tl *tieredLimiters) Stop() {
 func (s *Server) rateLimit(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ip := clientIP(r)
-		if !s.Limiters.anon.allow(ip) {
-			s.Logger.Warn("rate limit exceeded", "tier", "anonymous", "ip", clientIP(r))
-			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		if ok, retry := s.Limiters.anon.reserve(ip); !ok {
+			s.Logger.WarnContext(r.Context(), "rate limit exceeded", "tier", "anonymous", "ip", clientIP(r))
+			tooManyRequests(w, retry)
 			return
 		}
 		next(w, r)
@@ -148,9 +206,9 @@ func (s *Server) rateLimitAuthed(next http.HandlerFunc) http.HandlerFunc {
 			username = u.Username
 			key = "user:" + u.Username
 		}
-		if !s.Limiters.authed.allow(key) {
-			s.Logger.Warn("rate limit exceeded", "tier", "authed", "ip", clientIP(r), "username", username)
-			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		if ok, retry := s.Limiters.authed.reserve(key); !ok {
+			s.Logger.WarnContext(r.Context(), "rate limit exceeded", "tier", "authed", "ip", clientIP(r), "username", username)
+			tooManyRequests(w, retry)
 			return
 		}
 		next(w, r)
@@ -165,10 +223,9 @@ func (s *Server) rateLimitAgent(next http.HandlerFunc) http.HandlerFunc {
 		if agentID != "" {
 			key = "agent:" + agentID
 		}
-		if !s.Limiters.agent.allow(key) {
-			w.Header().Set("Retry-After", "5")
-			s.Logger.Warn("rate limit exceeded", "tier", "agent", "ip", clientIP(r), "agent_id", agentID)
-			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		if ok, retry := s.Limiters.agent.reserve(key); !ok {
+			s.Logger.WarnContext(r.Context(), "rate limit exceeded", "tier", "agent", "ip", clientIP(r), "agent_id", agentID)
+			tooManyRequests(w, retry)
 			return
 		}
 		next(w, r)
@@ -2,10 +2,13 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 func contextWithUser(ctx context.Context, u *userContext) context.Context {
@@ -142,6 +145,47 @@ func TestTieredLimiters_IndependentBuckets(t *testing.T) {
 	}
 }
 
+func TestNewConfiguredLimiters(t *testing.T) {
+	tl := newConfiguredLimiters(RateLimits{
+		Anon:   RateLimit{Burst: 7},
+		Authed: RateLimit{Rate: 5, Burst: 9},
+		Agent:  RateLimit{Rate: -1},
+	})
+	defer tl.Stop()
+
+	if tl.anon.rate != anonRate || tl.anon.burst != 7 {
+		t.Errorf("anon: got rate %v burst %d, want %v/7", tl.anon.rate, tl.anon.burst, anonRate)
+	}
+	if tl.authed.rate != 5 || tl.authed.burst != 9 {
+		t.Errorf("authed: got rate %v burst %d, want 5/9", tl.authed.rate, tl.authed.burst)
+	}
+	if tl.agent != nil {
+		t.Fatal("negative rate should disable the agent tier")
+	}
+	for range 1000 {
+		if !tl.agent.allow("10.0.0.1") {
+			t.Fatal("disabled tier should allow everything")
+		}
+	}
+}
+
+func TestRateLimiter_ReserveRetryAfter(t *testing.T) {
+	rl := newRateLimiter(2, 1)
+	defer rl.Stop()
+
+	if ok, _ := rl.reserve("k"); !ok {
+		t.Fatal("first request should be allowed")
+	}
+	ok, retry := rl.reserve("k")
+	if ok {
+		t.Fatal("second request should be limited")
+	}
+	// One token refills in 1/rate = 500ms
+	if retry <= 0 || retry > 500*time.Millisecond {
+		t.Errorf("retry = %v, want (0, 500ms]", retry)
+	}
+}
+
 // --- Middleware tests ---
 
 func TestClientIP_RemoteAddr(t *testing.T) {
@@ -308,6 +352,40 @@ func TestRateLimitAgent_Middleware(t *testing.T) {
 	}
 }
 
+func TestRateLimit_MetricsEndpointHammer(t *testing.T) {
+	mock := NewMockDB()
+	s := New(Config{Port: 8080, RateLimits: RateLimits{Agent: RateLimit{Rate: 0.5, Burst: 3}}}, mock)
+	agentID := testAgentUUID
+	sum := sha256.Sum256([]byte("test-secret"))
+	mock.AgentSHA256[agentID] = sum[:]
+
+	var accepted, limited int
+	for range 10 {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/agent/metrics", strings.NewReader("[]"))
+		req.Header.Set("Content-Type", "application/json")
+		setAgentAuth(req, agentID, "test-secret")
+		rec := httptest.NewRecorder()
+
+		s.Router.ServeHTTP(rec, req)
+
+		switch rec.Code {
+		case http.StatusAccepted:
+			accepted++
+		case http.StatusTooManyRequests:
+			limited++
+			if ra := rec.Header().Get("Retry-After"); ra != "2" {
+				t.Errorf("Retry-After: got %q, want 2", ra)
+			}
+		default:
+			t.Fatalf("unexpected status %d", rec.Code)
+		}
+	}
+
+	if accepted != 3 || limited != 7 {
+		t.Errorf("accepted %d, limited %d; want 3 and 7", accepted, limited)
+	}
+}
+
 // --- Benchmark ---
 
 func BenchmarkRateLimiter_Allow(b *testing.B) {
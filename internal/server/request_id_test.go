@@ -0,0 +1,149 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/logging"
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestRequestIDMiddleware_EchoesSuppliedID(t *testing.T) {
+	var seen string
+	h := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = requestIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(requestIDHeader, "agent-batch-42")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(requestIDHeader); got != "agent-batch-42" {
+		t.Errorf("response header: got %q, want agent-batch-42", got)
+	}
+	if seen != "agent-batch-42" {
+		t.Errorf("context: got %q, want agent-batch-42", seen)
+	}
+}
+
+func TestRequestIDMiddleware_GeneratesWhenAbsent(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{"Absent", ""},
+		{"Invalid characters", "bad id\r\nX-Evil: 1"},
+		{"Too long", strings.Repeat("a", maxRequestIDLen+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(requestIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(requestIDHeader)
+			if len(got) != 32 || got == tt.header {
+				t.Errorf("got %q, want a generated 32-char ID", got)
+			}
+		})
+	}
+}
+
+func TestRequestIDMiddleware_LogsAndErrorBody(t *testing.T) {
+	capture := &captureHandler{}
+	s := New(Config{Port: 8080, Logger: logging.FromHandler(capture)}, NewMockDB())
+	h := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Logger.WarnContext(r.Context(), "something failed")
+		s.respondError(w, http.StatusBadRequest, "bad input")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(requestIDHeader, "trace-me")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if v, ok := capture.attr("something failed", "request_id"); !ok || v.String() != "trace-me" {
+		t.Errorf("log request_id: got %v (found=%v), want trace-me", v, ok)
+	}
+
+	var body map[string]string
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body["request_id"] != "trace-me" || body["error"] != "bad input" {
+		t.Errorf("body: got %v", body)
+	}
+}
+
+// withRequestID returns req carrying id the way requestIDMiddleware
+// stores it.
+func withRequestID(req *http.Request, id string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), requestIDKey{}, id))
+}
+
+func TestDBError_LogsRequestID(t *testing.T) {
+	capture := &captureHandler{}
+	s := New(Config{Port: 8080, Logger: logging.FromHandler(capture)}, NewMockDB())
+
+	req := withRequestID(httptest.NewRequest(http.MethodGet, "/", nil), "trace-db")
+	s.dbError(httptest.NewRecorder(), req, errors.New("connection refused"), "handleTest")
+
+	if v, ok := capture.attr("database query failed", "request_id"); !ok || v.String() != "trace-db" {
+		t.Errorf("request_id: got %v (found=%v), want trace-db", v, ok)
+	}
+}
+
+func TestQueueHelper_LogsRequestID(t *testing.T) {
+	s, agentID, _, _ := newTestServer()
+	capture := &captureHandler{}
+	s.Logger = logging.FromHandler(requestIDHandler{capture})
+
+	req := withRequestID(httptest.NewRequest(http.MethodPost, "/", nil), "trace-queue")
+	s.queueHelper(httptest.NewRecorder(), req, agentID, protocol.CmdFetchLogs, []byte(`{}`), "Queued!")
+
+	if v, ok := capture.attr("command queued", "request_id"); !ok || v.String() != "trace-queue" {
+		t.Errorf("request_id: got %v (found=%v), want trace-queue", v, ok)
+	}
+}
+
+func TestHandleMetrics_ProcessingLogsKeepRequestID(t *testing.T) {
+	s, agentID, secret, _ := newTestServer()
+	capture := &captureHandler{}
+	s.Logger = logging.FromHandler(requestIDHandler{capture})
+
+	body, _ := json.Marshal([]RawEnvelope{{Type: "bogus", Hostname: "test-host", Data: json.RawMessage(`{}`)}})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/agent/metrics", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(requestIDHeader, "trace-ingest")
+	setAgentAuth(req, agentID, secret)
+
+	requestIDMiddleware(s.Router).ServeHTTP(httptest.NewRecorder(), req)
+
+	// Envelopes are processed after the response is written
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if v, ok := capture.attr("error processing metric", "request_id"); ok {
+			if v.String() != "trace-ingest" {
+				t.Errorf("request_id: got %q, want trace-ingest", v.String())
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("processing log never arrived")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -7,11 +7,13 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/nhdewitt/spectra/internal/labels"
 	"github.com/nhdewitt/spectra/internal/logging"
+	"github.com/nhdewitt/spectra/internal/protocol"
 	"github.com/nhdewitt/spectra/internal/secret"
 	"github.com/nhdewitt/spectra/internal/version"
 	"golang.org/x/net/netutil"
@@ -49,11 +51,21 @@ type Config struct {
 	CommandTimeout time.Duration
 	ReleasesDir    string // path to pre-built agent binaries
 	MaxConnections uint
-	LogFile        string // path to JSON log file
-	LogLevel       string // "debug", "info", "warn", "error"
+	MaxBatchSize   int           // envelopes accepted per metrics POST; 0 uses defaultMaxBatchSize
+	CmdQueueSize   int           // pending commands per agent; 0 uses defaultCommandQueueSize
+	CmdQueuePolicy QueuePolicy   // behavior when an agent's queue is full; empty rejects newest
+	RateLimits     RateLimits    // per-tier overrides; zero values keep the defaults
+	CORS           CORSConfig    // cross-origin access to the dashboard API; empty origins disables
+	StateFile      string        // pending commands and tokens saved across restarts; empty disables
+	DrainDelay     time.Duration // how long /readyz reports 503 before the listener closes; 0 skips the wait
+	LogFile        string        // path to JSON log file
+	LogLevel       string        // "debug", "info", "warn", "error"
 	TLSCert        string
 	TLSKey         string
 	TLSCA          string
+	OTLPEndpoint   string          // OTLP/HTTP collector to forward metrics to; empty disables
+	Influx         InfluxConfig    // InfluxDB v2 bucket to forward metrics to; empty URL disables
+	Logger         *logging.Logger // overrides LogFile/LogLevel when set
 }
 
 type Server struct {
@@ -69,8 +81,28 @@ type Server struct {
 	httpServer   *http.Server
 	Commands     *commandResultStore
 	versionCache *labels.VersionCache
+	hostnames    *hostnameCache
 	Cipher       *secret.Cipher
 
+	// futureEnvelopes counts envelopes newer than protocol.SchemaVersion
+	futureEnvelopes atomic.Int64
+	// invalidEnvelopes counts envelopes dropped by Metric.Validate
+	invalidEnvelopes atomic.Int64
+	// oversizedBatches counts metrics POSTs rejected for exceeding MaxBatchSize
+	oversizedBatches atomic.Int64
+	// hostnameMismatches counts envelopes whose Hostname disagreed with the
+	// authenticated agent's registered hostname
+	hostnameMismatches atomic.Int64
+
+	// exporters receive every valid metric after it is stored
+	exporters []metricExporter
+	// metricHandlers run per metric type after export; see OnMetric
+	metricHandlers map[string][]MetricHandler
+
+	// draining is set once Shutdown begins so /readyz fails while the
+	// listener is still accepting requests
+	draining atomic.Bool
+
 	done chan struct{}
 }
 
@@ -78,6 +110,9 @@ func New(cfg Config, db DB) *Server {
 	if cfg.CommandTimeout == 0 {
 		cfg.CommandTimeout = 30 * time.Second
 	}
+	if cfg.MaxBatchSize <= 0 {
+		cfg.MaxBatchSize = defaultMaxBatchSize
+	}
 
 	logCfg := logging.DefaultServerConfig()
 	if cfg.LogFile != "" {
@@ -91,31 +126,53 @@ func New(cfg Config, db DB) *Server {
 	// and tests don't flood output with per-iteration log lines. Production
 	// builds get the configured logger.
 	var logger *logging.Logger
-	if testing.Testing() {
+	switch {
+	case cfg.Logger != nil:
+		logger = cfg.Logger
+	case testing.Testing():
 		logger = logging.NewDiscard()
-	} else {
+	default:
 		logger = logging.New(logCfg)
 	}
 
+	logger = logger.WithHandler(requestIDHandler{logger.Handler()})
+
 	s := &Server{
 		Config:       cfg,
-		CmdQueue:     NewCommandQueue(),
+		CmdQueue:     NewCommandQueueWithLimit(cfg.CmdQueueSize, cfg.CmdQueuePolicy),
 		Tokens:       NewTokenStore(),
 		DB:           db,
 		Router:       http.NewServeMux(),
 		Logger:       logger,
 		LoginTracker: newLoginTracker(),
-		Limiters:     newTieredLimiters(),
+		Limiters:     newConfiguredLimiters(cfg.RateLimits),
 		Releases:     newReleaseManifest(cfg.ReleasesDir),
 		Commands:     newCommandResultStore(10 * time.Minute),
 		versionCache: labels.NewVersionCache(),
+		hostnames:    newHostnameCache(),
 		done:         make(chan struct{}),
 	}
+	s.CmdQueue.onEvict = s.commandEvicted
+	s.OnMetric(protocol.TypeCollectorHealth, s.warnCollectorFailing)
+	if cfg.OTLPEndpoint != "" {
+		otlp := newOTLPExporter(cfg.OTLPEndpoint, logger)
+		go otlp.run(s.done)
+		s.exporters = append(s.exporters, otlp)
+	}
+	if cfg.Influx.URL != "" {
+		influx := newInfluxExporter(cfg.Influx, logger)
+		go influx.run(s.done)
+		s.exporters = append(s.exporters, influx)
+	}
 	s.routes()
 	return s
 }
 
 func (s *Server) routes() {
+	// Probes (public, no rate limit) for orchestrators and load balancers
+	s.Router.HandleFunc("GET /healthz", s.handleHealthz)
+	s.Router.HandleFunc("GET /readyz", s.handleReadyz)
+
 	// Auth (public, anonymous rate limit)
 	s.Router.HandleFunc("POST /api/v1/auth/login", s.rateLimit(s.handleLogin))
 	s.Router.HandleFunc("POST /api/v1/auth/logout", s.rateLimit(s.handleLogout))
@@ -127,6 +184,8 @@ func (s *Server) routes() {
 	s.Router.HandleFunc("GET /api/v1/agent/command", s.rateLimitAgent(s.requireAgentAuth(s.handleAgentCommand)))
 	s.Router.HandleFunc("POST /api/v1/agent/command/result", s.rateLimitAgent(s.requireAgentAuth(s.handleCommandResult)))
 	s.Router.HandleFunc("GET /api/v1/agent/config", s.requireAgentAuth(s.handleGetAgentSelfConfig))
+	s.Router.HandleFunc("GET /api/v1/agent/throughput", s.rateLimitAgent(s.requireAgentAuth(s.handleThroughputDownload)))
+	s.Router.HandleFunc("POST /api/v1/agent/throughput", s.rateLimitAgent(s.requireAgentAuth(s.handleThroughputUpload)))
 
 	// Dashboard (user auth, authed rate limit)
 	s.Router.HandleFunc("GET /api/v1/overview", s.requireUserAuth(s.rateLimitAuthed(s.handleOverview)))
@@ -149,6 +208,7 @@ func (s *Server) routes() {
 	s.Router.HandleFunc("GET /api/v1/agents/{id}/services", s.requireUserAuth(s.rateLimitAuthed(s.handleGetServices)))
 	s.Router.HandleFunc("GET /api/v1/agents/{id}/applications", s.requireUserAuth(s.rateLimitAuthed(s.handleGetApplications)))
 	s.Router.HandleFunc("GET /api/v1/agents/{id}/updates", s.requireUserAuth(s.rateLimitAuthed(s.handleGetUpdates)))
+	s.Router.HandleFunc("GET /api/v1/agents/{id}/inventory", s.requireUserAuth(s.rateLimitAuthed(s.handleGetInventory)))
 	s.Router.HandleFunc("GET /api/v1/agents/{id}/system/latest", s.requireUserAuth(s.rateLimitAuthed(s.handleGetLatestSystem)))
 	s.Router.HandleFunc("GET /api/v1/admin/commands/{id}", s.requireUserAuth(s.rateLimitAuthed(s.handleGetCommandResult)))
 	s.Router.HandleFunc("GET /api/v1/overview/heatmap", s.requireUserAuth(s.rateLimitAuthed(s.handleFleetHeatmap)))
@@ -185,13 +245,24 @@ func (s *Server) routes() {
 	s.Router.HandleFunc("PUT /api/v1/agents/{id}/config", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleSetAgentConfig))))
 	s.Router.HandleFunc("DELETE /api/v1/agents/{id}/config", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleDeleteAgentConfig))))
 	s.Router.HandleFunc("POST /api/v1/admin/logs", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleAdminTriggerLogs))))
+	s.Router.HandleFunc("POST /api/v1/admin/logs/follow", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleAdminTriggerFollowLogs))))
+	s.Router.HandleFunc("POST /api/v1/admin/commands/{id}/cancel", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleCancelCommand))))
 	s.Router.HandleFunc("POST /api/v1/admin/disk", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleAdminTriggerDisk))))
 	s.Router.HandleFunc("POST /api/v1/admin/network", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleAdminTriggerNetwork))))
+	s.Router.HandleFunc("POST /api/v1/admin/cert", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleAdminTriggerCertCheck))))
+	s.Router.HandleFunc("POST /api/v1/admin/iostat", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleAdminTriggerIOStat))))
+	s.Router.HandleFunc("POST /api/v1/admin/routes", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleAdminTriggerRouteTable))))
+	s.Router.HandleFunc("POST /api/v1/admin/processes", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleAdminTriggerTopProcesses))))
+	s.Router.HandleFunc("POST /api/v1/admin/procfiles", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleAdminTriggerProcFiles))))
+	s.Router.HandleFunc("POST /api/v1/admin/mountlatency", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleAdminTriggerMountLatency))))
+	s.Router.HandleFunc("POST /api/v1/admin/throughput", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleAdminTriggerThroughput))))
+	s.Router.HandleFunc("POST /api/v1/admin/mtu", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleAdminTriggerMTUProbe))))
 	s.Router.HandleFunc("POST /api/v1/admin/tokens", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleGenerateToken))))
 	s.Router.HandleFunc("POST /api/v1/admin/provision", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleProvision))))
 	s.Router.HandleFunc("POST /api/v1/admin/agents/purge", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handlePurgeOfflineAgents))))
 	s.Router.HandleFunc("POST /api/v1/admin/tokens/revoke", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleRevokeAllTokens))))
 	s.Router.HandleFunc("POST /api/v1/admin/update", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handlePushUpdate))))
+	s.Router.HandleFunc("POST /api/v1/admin/broadcast", s.requireUserAuth(s.rateLimitAuthed(requireRole(RoleAdmin)(s.handleBroadcast))))
 
 	// User config (any authenticated user)
 	s.Router.HandleFunc("GET /api/v1/user/config", s.requireUserAuth(s.rateLimitAuthed(s.handleGetUserConfig)))
@@ -235,7 +306,7 @@ func (s *Server) Start() error {
 	addr := fmt.Sprintf(":%d", s.Config.Port)
 	s.httpServer = &http.Server{
 		Addr:              addr,
-		Handler:           gzipMiddleware(s.requestLogger(s.Router)),
+		Handler:           requestIDMiddleware(gzipMiddleware(s.requestLogger(corsMiddleware(s.Config.CORS, s.Router)))),
 		ReadHeaderTimeout: 10 * time.Second,
 		ReadTimeout:       30 * time.Second,
 		WriteTimeout:      40 * time.Second,
@@ -251,6 +322,10 @@ func (s *Server) Start() error {
 	}
 	ln = netutil.LimitListener(ln, int(s.Config.MaxConnections))
 
+	if err := s.loadState(); err != nil {
+		s.Logger.Warn("could not restore saved state", "path", s.Config.StateFile, "error", err)
+	}
+
 	go s.startAlertEvaluator()
 
 	if s.Config.TLSCert != "" && s.Config.TLSKey != "" {
@@ -263,11 +338,30 @@ func (s *Server) Start() error {
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
-	s.Logger.Info("server shutting down")
+	s.Logger.Info("server shutting down", "drain_delay", s.Config.DrainDelay)
+
+	// Fail readiness first and keep serving for DrainDelay, giving load
+	// balancers time to stop routing here before the listener closes.
+	s.draining.Store(true)
+	if s.Config.DrainDelay > 0 {
+		t := time.NewTimer(s.Config.DrainDelay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+		}
+	}
+
+	var err error
+	if s.httpServer != nil {
+		err = s.httpServer.Shutdown(ctx)
+	}
 	close(s.done)
 	s.Limiters.Stop()
 	s.Commands.Stop()
-	err := s.httpServer.Shutdown(ctx)
+	if serr := s.saveState(); serr != nil {
+		s.Logger.Error("could not save state", "path", s.Config.StateFile, "error", serr)
+	}
 	s.Logger.Close() // flush
 	return err
 }
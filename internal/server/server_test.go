@@ -41,6 +41,13 @@ func TestNew_DefaultCommandTimeout(t *testing.T) {
 	}
 }
 
+func TestNew_DefaultMaxBatchSize(t *testing.T) {
+	s := New(Config{Port: 8080}, NewMockDB())
+	if s.Config.MaxBatchSize != defaultMaxBatchSize {
+		t.Errorf("MaxBatchSize: got %d, want %d", s.Config.MaxBatchSize, defaultMaxBatchSize)
+	}
+}
+
 func TestNew_CustomCommandTimeout(t *testing.T) {
 	s := New(Config{Port: 8080, CommandTimeout: 5 * time.Second}, NewMockDB())
 
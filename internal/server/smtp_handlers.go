@@ -133,13 +133,13 @@ func (s *Server) handleGetSMTPConfig(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			// Not configured yet
-			respondJSON(w, http.StatusOK, smtpConfigResponse{TLSMode: string(SMTPTLSStartTLS)})
+			s.respondJSON(w, http.StatusOK, smtpConfigResponse{TLSMode: string(SMTPTLSStartTLS)})
 			return
 		}
-		s.dbError(w, err, "handleGetSMTPConfig")
+		s.dbError(w, r, err, "handleGetSMTPConfig")
 		return
 	}
-	respondJSON(w, http.StatusOK, toSMTPConfigResponse(cfg))
+	s.respondJSON(w, http.StatusOK, toSMTPConfigResponse(cfg))
 }
 
 // handleUpdateSMTPConfig upserts the SMTP config. The password is encrypted on write;
@@ -166,7 +166,7 @@ func (s *Server) handleUpdateSMTPConfig(w http.ResponseWriter, r *http.Request)
 			http.Error(w, err.Error(), http.StatusServiceUnavailable)
 			return
 		}
-		s.dbError(w, err, "handleUpdateSMTPConfig")
+		s.dbError(w, r, err, "handleUpdateSMTPConfig")
 		return
 	}
 
@@ -180,12 +180,12 @@ func (s *Server) handleUpdateSMTPConfig(w http.ResponseWriter, r *http.Request)
 		TlsMode:           string(tlsMode),
 	})
 	if err != nil {
-		s.dbError(w, err, "handleUpdateSMTPConfig")
+		s.dbError(w, r, err, "handleUpdateSMTPConfig")
 		return
 	}
 
-	s.Logger.Info("smtp config updated", "enabled", cfg.Enabled, "host", cfg.Host)
-	respondJSON(w, http.StatusOK, toSMTPConfigResponse(cfg))
+	s.Logger.InfoContext(r.Context(), "smtp config updated", "enabled", cfg.Enabled, "host", cfg.Host)
+	s.respondJSON(w, http.StatusOK, toSMTPConfigResponse(cfg))
 }
 
 // handleTestSMTPConfig sends a test email using the request-body settings,
@@ -217,7 +217,7 @@ func (s *Server) handleTestSMTPConfig(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), http.StatusServiceUnavailable)
 			return
 		}
-		s.dbError(w, err, "handleTestSMTPConfig")
+		s.dbError(w, r, err, "handleTestSMTPConfig")
 		return
 	}
 
@@ -229,7 +229,7 @@ func (s *Server) handleTestSMTPConfig(w http.ResponseWriter, r *http.Request) {
 		}
 		plainPassword, err = s.Cipher.DecryptString(encPassword)
 		if err != nil {
-			s.Logger.Error("decrypt SMTP password", "err", err)
+			s.Logger.ErrorContext(r.Context(), "decrypt SMTP password", "err", err)
 			http.Error(w, "stored SMTP password could not be decrypted", http.StatusServiceUnavailable)
 			return
 		}
@@ -245,9 +245,9 @@ func (s *Server) handleTestSMTPConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.sendTestEmail(r.Context(), settings, req.TestTo); err != nil {
-		respondJSON(w, http.StatusBadGateway, map[string]string{"error": err.Error()})
+		s.respondJSON(w, http.StatusBadGateway, map[string]string{"error": err.Error()})
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]string{"status": "sent"})
+	s.respondJSON(w, http.StatusOK, map[string]string{"status": "sent"})
 }
@@ -39,7 +39,7 @@ func (s *Server) handleGetSparklines(w http.ResponseWriter, r *http.Request) {
 	// CPU
 	cpuRows, err := s.DB.GetRecentCPU(ctx)
 	if err != nil {
-		s.Logger.Error("database query error", "error", err, "handler", "handleGetSparklines", "metric", "cpu")
+		s.Logger.ErrorContext(r.Context(), "database query error", "error", err, "handler", "handleGetSparklines", "metric", "cpu")
 		http.Error(w, "database error", http.StatusInternalServerError)
 		return
 	}
@@ -52,7 +52,7 @@ func (s *Server) handleGetSparklines(w http.ResponseWriter, r *http.Request) {
 	// Memory
 	memRows, err := s.DB.GetRecentMemory(ctx)
 	if err != nil {
-		s.Logger.Error("database query error", "error", err, "handler", "handleGetSparklines", "metric", "mem")
+		s.Logger.ErrorContext(r.Context(), "database query error", "error", err, "handler", "handleGetSparklines", "metric", "mem")
 		http.Error(w, "database error", http.StatusInternalServerError)
 		return
 	}
@@ -65,7 +65,7 @@ func (s *Server) handleGetSparklines(w http.ResponseWriter, r *http.Request) {
 	// Disk
 	diskRows, err := s.DB.GetRecentDiskMax(ctx)
 	if err != nil {
-		s.Logger.Error("database query error", "error", err, "handler", "handleGetSparklines", "metric", "disk")
+		s.Logger.ErrorContext(r.Context(), "database query error", "error", err, "handler", "handleGetSparklines", "metric", "disk")
 		http.Error(w, "database error", http.StatusInternalServerError)
 		return
 	}
@@ -75,5 +75,5 @@ func (s *Server) handleGetSparklines(w http.ResponseWriter, r *http.Request) {
 		d.Disk = append(d.Disk, row.MaxPercent)
 	}
 
-	respondJSON(w, http.StatusOK, result)
+	s.respondJSON(w, http.StatusOK, result)
 }
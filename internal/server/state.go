@@ -0,0 +1,90 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/nhdewitt/spectra/internal/fileutil"
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// serverState is the in-memory state that doesn't live in Postgres:
+// commands queued for agents that haven't polled yet and registration
+// tokens that haven't been used.
+type serverState struct {
+	Commands map[string][]protocol.Command `json:"commands"`
+	Tokens   []RegistrationToken           `json:"tokens"`
+}
+
+// Snapshot writes pending commands and outstanding registration tokens
+// to w as JSON. It takes the commands out of the queue and closes it to
+// new ones, so call it only once the HTTP server has stopped.
+func (s *Server) Snapshot(w io.Writer) error {
+	return json.NewEncoder(w).Encode(serverState{
+		Commands: s.CmdQueue.snapshot(),
+		Tokens:   s.Tokens.snapshot(),
+	})
+}
+
+// Restore loads state written by Snapshot. Expired tokens are skipped and
+// commands that no longer fit an agent's queue are dropped.
+func (s *Server) Restore(r io.Reader) error {
+	var st serverState
+	if err := json.NewDecoder(r).Decode(&st); err != nil {
+		return fmt.Errorf("decode state: %w", err)
+	}
+	// Restored commands are tracked again, like freshly queued ones, so
+	// their results show up on the command status endpoint.
+	dropped := 0
+	for agentID, cmds := range st.Commands {
+		for _, cmd := range cmds {
+			if err := s.enqueue(agentID, cmd); err != nil {
+				dropped++
+			}
+		}
+	}
+	if dropped > 0 {
+		s.Logger.Warn("commands dropped restoring state", "count", dropped)
+	}
+	s.Tokens.restore(st.Tokens)
+	return nil
+}
+
+// saveState snapshots to Config.StateFile. The file holds live
+// registration tokens, so it's written owner-only.
+func (s *Server) saveState() error {
+	if s.Config.StateFile == "" {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := s.Snapshot(&buf); err != nil {
+		return err
+	}
+	return fileutil.WriteSecure(s.Config.StateFile, buf.Bytes())
+}
+
+// loadState restores from Config.StateFile if it exists, then removes it
+// so a later crash can't replay the same commands twice.
+func (s *Server) loadState() error {
+	if s.Config.StateFile == "" {
+		return nil
+	}
+	f, err := os.Open(s.Config.StateFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := s.Restore(f); err != nil {
+		return err
+	}
+	return os.Remove(s.Config.StateFile)
+}
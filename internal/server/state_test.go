@@ -0,0 +1,189 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestSnapshotRestore_RoundTrip(t *testing.T) {
+	src := New(Config{Port: 8080}, NewMockDB())
+	cmds := map[string][]protocol.Command{
+		"agent-1": {
+			{ID: "a", Type: protocol.CmdFetchLogs, Payload: []byte(`{"max_lines":10}`)},
+			{ID: "b", Type: protocol.CmdDiskUsage},
+		},
+		"agent-2": {{ID: "c", Type: protocol.CmdRouteTable}},
+	}
+	for agentID, list := range cmds {
+		for _, cmd := range list {
+			if err := src.CmdQueue.Send(agentID, cmd); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	live := src.Tokens.Generate(time.Hour)
+	used := src.Tokens.Generate(time.Hour)
+	src.Tokens.Validate(used)
+	expired := src.Tokens.Generate(-time.Minute)
+
+	var buf bytes.Buffer
+	if err := src.Snapshot(&buf); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	dst := New(Config{Port: 8080}, NewMockDB())
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	for agentID, want := range cmds {
+		for _, w := range want {
+			got, err := dst.CmdQueue.Wait(context.Background(), agentID, 10*time.Millisecond)
+			if err != nil {
+				t.Fatalf("%s: missing command %s: %v", agentID, w.ID, err)
+			}
+			if got.ID != w.ID || got.Type != w.Type || !bytes.Equal(got.Payload, w.Payload) {
+				t.Errorf("%s: got %+v, want %+v", agentID, got, w)
+			}
+			entry, ok := dst.Commands.Get(w.ID)
+			if !ok {
+				t.Errorf("%s: command %s not tracked after restore", agentID, w.ID)
+			} else if entry.AgentID != agentID || entry.Type != w.Type {
+				t.Errorf("%s: tracked entry %+v, want agent %s type %s", agentID, entry, agentID, w.Type)
+			}
+		}
+	}
+	if !dst.Tokens.Peek(live) {
+		t.Error("live token should survive restore")
+	}
+	if dst.Tokens.Peek(used) || dst.Tokens.Peek(expired) {
+		t.Error("used and expired tokens should not be restored")
+	}
+}
+
+func TestSnapshot_ClosesQueue(t *testing.T) {
+	s := New(Config{Port: 8080}, NewMockDB())
+	for _, id := range []string{"a", "b", "c"} {
+		s.CmdQueue.Send("agent-1", protocol.Command{ID: id})
+	}
+
+	got := s.CmdQueue.snapshot()
+	var ids []string
+	for _, cmd := range got["agent-1"] {
+		ids = append(ids, cmd.ID)
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(ids, want) {
+		t.Errorf("snapshot: got %v, want %v", ids, want)
+	}
+
+	if _, err := s.CmdQueue.Wait(context.Background(), "agent-1", 10*time.Millisecond); err == nil {
+		t.Error("snapshotted commands should no longer be deliverable")
+	}
+	if err := s.CmdQueue.Send("agent-1", protocol.Command{ID: "d"}); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("Send after snapshot: got %v, want ErrQueueClosed", err)
+	}
+}
+
+// Every command must end up exactly once: delivered to a waiting agent,
+// captured by the snapshot, or refused by Send.
+func TestSnapshot_ConcurrentSendWait(t *testing.T) {
+	q := NewCommandQueueWithLimit(1000, QueueRejectNewest)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		sent      = make(map[string]bool)
+		delivered []string
+	)
+	stop := make(chan struct{})
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			id := strconv.Itoa(i)
+			err := q.Send("agent-1", protocol.Command{ID: id})
+			if errors.Is(err, ErrQueueClosed) {
+				return
+			}
+			if err == nil {
+				mu.Lock()
+				sent[id] = true
+				mu.Unlock()
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			cmd, err := q.Wait(context.Background(), "agent-1", time.Millisecond)
+			if err == nil {
+				mu.Lock()
+				delivered = append(delivered, cmd.ID)
+				mu.Unlock()
+			}
+		}
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	snap := q.snapshot()
+	close(stop)
+	wg.Wait()
+
+	seen := make(map[string]int)
+	for _, id := range delivered {
+		seen[id]++
+	}
+	for _, cmd := range snap["agent-1"] {
+		seen[cmd.ID]++
+	}
+	for id := range sent {
+		if seen[id] != 1 {
+			t.Errorf("command %s accounted for %d times, want 1", id, seen[id])
+		}
+	}
+	if len(seen) != len(sent) {
+		t.Errorf("saw %d distinct commands, sent %d", len(seen), len(sent))
+	}
+}
+
+func TestSaveLoadState_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	src := New(Config{Port: 8080, StateFile: path}, NewMockDB())
+	src.CmdQueue.Send("agent-1", protocol.Command{ID: "a"})
+	if err := src.saveState(); err != nil {
+		t.Fatalf("saveState: %v", err)
+	}
+
+	dst := New(Config{Port: 8080, StateFile: path}, NewMockDB())
+	if err := dst.loadState(); err != nil {
+		t.Fatalf("loadState: %v", err)
+	}
+	if _, err := dst.CmdQueue.Wait(context.Background(), "agent-1", 10*time.Millisecond); err != nil {
+		t.Errorf("command not restored: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("state file should be removed after loading, stat err = %v", err)
+	}
+
+	// Missing file is a clean start, not an error.
+	if err := dst.loadState(); err != nil {
+		t.Errorf("loadState with no file: %v", err)
+	}
+}
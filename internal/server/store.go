@@ -2,23 +2,84 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"sync"
 	"time"
 
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
+// QueuePolicy decides what happens when an agent's command queue is full.
+type QueuePolicy string
+
+const (
+	// QueueRejectNewest refuses the incoming command, leaving queued ones intact.
+	QueueRejectNewest QueuePolicy = "reject_newest"
+	// QueueDropOldest discards the oldest queued command to make room.
+	QueueDropOldest QueuePolicy = "drop_oldest"
+)
+
+// ParseQueuePolicy validates a configured queue policy. Empty selects
+// QueueRejectNewest so an agent that stops polling can't silently lose
+// commands an admin already saw accepted.
+func ParseQueuePolicy(s string) (QueuePolicy, error) {
+	switch QueuePolicy(s) {
+	case QueueRejectNewest, QueueDropOldest:
+		return QueuePolicy(s), nil
+	case "":
+		return QueueRejectNewest, nil
+	default:
+		return "", fmt.Errorf("invalid command queue policy %q (want reject_newest or drop_oldest)", s)
+	}
+}
+
+// defaultCommandQueueSize is the per-agent queue length when none is configured.
+const defaultCommandQueueSize = 10
+
+// ErrQueueFull is returned by Send when the agent's queue is at capacity
+// under QueueRejectNewest.
+var ErrQueueFull = errors.New("command queue full")
+
+// ErrQueueClosed is returned by Send once snapshot has taken the queue's
+// contents for shutdown.
+var ErrQueueClosed = errors.New("command queue closed")
+
 // CommandQueue manages pending command channels for agents.
 // Channels are created lazily on first use (Send or Wait).
 type CommandQueue struct {
 	mu     sync.Mutex
 	queues map[string]chan protocol.Command
+	size   int
+	policy QueuePolicy
+	closed bool // set by snapshot; Send refuses new commands
+
+	// onEvict, when set, is called for each command QueueDropOldest
+	// discards, after q.mu is released.
+	onEvict func(agentID string, cmd protocol.Command)
 }
 
+// NewCommandQueue returns a queue holding defaultCommandQueueSize commands
+// per agent that rejects new commands when full.
 func NewCommandQueue() *CommandQueue {
+	return NewCommandQueueWithLimit(defaultCommandQueueSize, QueueRejectNewest)
+}
+
+// NewCommandQueueWithLimit returns a queue holding size commands per agent,
+// applying policy once an agent's queue is full. A non-positive size uses
+// defaultCommandQueueSize.
+func NewCommandQueueWithLimit(size int, policy QueuePolicy) *CommandQueue {
+	if size <= 0 {
+		size = defaultCommandQueueSize
+	}
+	if policy == "" {
+		policy = QueueRejectNewest
+	}
 	return &CommandQueue{
 		queues: make(map[string]chan protocol.Command),
+		size:   size,
+		policy: policy,
 	}
 }
 
@@ -26,24 +87,60 @@ func NewCommandQueue() *CommandQueue {
 func (q *CommandQueue) getOrCreate(agentID string) chan protocol.Command {
 	q.mu.Lock()
 	defer q.mu.Unlock()
+	return q.getOrCreateLocked(agentID)
+}
 
+// getOrCreateLocked is getOrCreate for callers already holding q.mu.
+func (q *CommandQueue) getOrCreateLocked(agentID string) chan protocol.Command {
 	ch, ok := q.queues[agentID]
 	if !ok {
-		ch = make(chan protocol.Command, 10)
+		ch = make(chan protocol.Command, q.size)
 		q.queues[agentID] = ch
 	}
 	return ch
 }
 
-// Send queues a command for an agent.
+// Send queues a command for an agent. When the queue is full it either
+// returns ErrQueueFull or evicts the oldest command, depending on policy.
+// Every channel operation here is non-blocking, so Send holds q.mu
+// throughout; that keeps it from racing Remove and snapshot.
 func (q *CommandQueue) Send(agentID string, cmd protocol.Command) error {
-	ch := q.getOrCreate(agentID)
+	evicted, err := q.send(agentID, cmd)
+	if q.onEvict != nil {
+		for _, old := range evicted {
+			q.onEvict(agentID, old)
+		}
+	}
+	return err
+}
 
-	select {
-	case ch <- cmd:
-		return nil
-	default:
-		return fmt.Errorf("command queue full for %q", agentID)
+func (q *CommandQueue) send(agentID string, cmd protocol.Command) (evicted []protocol.Command, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, ErrQueueClosed
+	}
+	ch := q.getOrCreateLocked(agentID)
+
+	for {
+		select {
+		case ch <- cmd:
+			return evicted, nil
+		default:
+		}
+
+		if q.policy != QueueDropOldest {
+			return nil, fmt.Errorf("%w for %q", ErrQueueFull, agentID)
+		}
+
+		// A waiting agent may race us here; either way a slot opens up
+		// and the next attempt succeeds.
+		select {
+		case old := <-ch:
+			evicted = append(evicted, old)
+		default:
+		}
 	}
 }
 
@@ -71,3 +168,39 @@ func (q *CommandQueue) Remove(agentID string) {
 		delete(q.queues, agentID)
 	}
 }
+
+// snapshot closes the queue to new commands and returns every agent's
+// pending commands in queue order. Nothing is put back: a command is
+// either handed to a polling agent or returned here, never both, so the
+// result is safe to replay after a restart. Meant for shutdown.
+func (q *CommandQueue) snapshot() map[string][]protocol.Command {
+	q.mu.Lock()
+	q.closed = true
+	chans := make(map[string]chan protocol.Command, len(q.queues))
+	maps.Copy(chans, q.queues)
+	q.mu.Unlock()
+
+	// With Send refused, receives are the only other operations on these
+	// channels, so draining outside the lock can't block or miss a late
+	// arrival.
+	out := make(map[string][]protocol.Command)
+	for agentID, ch := range chans {
+		var cmds []protocol.Command
+	drain:
+		for {
+			select {
+			case cmd, ok := <-ch:
+				if !ok {
+					break drain
+				}
+				cmds = append(cmds, cmd)
+			default:
+				break drain
+			}
+		}
+		if len(cmds) > 0 {
+			out[agentID] = cmds
+		}
+	}
+	return out
+}
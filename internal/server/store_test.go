@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -56,6 +57,103 @@ func TestCommandQueue_Send_Full(t *testing.T) {
 	}
 }
 
+func TestCommandQueue_Send_RejectNewest(t *testing.T) {
+	q := NewCommandQueueWithLimit(2, QueueRejectNewest)
+
+	for _, id := range []string{"a", "b"} {
+		if err := q.Send("agent-1", protocol.Command{ID: id}); err != nil {
+			t.Fatalf("Send(%s): %v", id, err)
+		}
+	}
+	err := q.Send("agent-1", protocol.Command{ID: "c"})
+	if !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+
+	for _, want := range []string{"a", "b"} {
+		cmd, err := q.Wait(context.Background(), "agent-1", 10*time.Millisecond)
+		if err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+		if cmd.ID != want {
+			t.Errorf("got %q, want %q", cmd.ID, want)
+		}
+	}
+}
+
+func TestCommandQueue_Send_DropOldest(t *testing.T) {
+	q := NewCommandQueueWithLimit(2, QueueDropOldest)
+
+	for _, id := range []string{"a", "b", "c"} {
+		if err := q.Send("agent-1", protocol.Command{ID: id}); err != nil {
+			t.Fatalf("Send(%s): %v", id, err)
+		}
+	}
+
+	for _, want := range []string{"b", "c"} {
+		cmd, err := q.Wait(context.Background(), "agent-1", 10*time.Millisecond)
+		if err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+		if cmd.ID != want {
+			t.Errorf("got %q, want %q", cmd.ID, want)
+		}
+	}
+}
+
+func TestCommandQueue_Send_DropOldestReportsEvictions(t *testing.T) {
+	q := NewCommandQueueWithLimit(2, QueueDropOldest)
+	var evicted []string
+	q.onEvict = func(agentID string, cmd protocol.Command) {
+		if agentID != "agent-1" {
+			t.Errorf("evicted from %q, want agent-1", agentID)
+		}
+		evicted = append(evicted, cmd.ID)
+	}
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if err := q.Send("agent-1", protocol.Command{ID: id}); err != nil {
+			t.Fatalf("Send(%s): %v", id, err)
+		}
+	}
+
+	if want := []string{"a", "b"}; !slices.Equal(evicted, want) {
+		t.Errorf("evicted: got %v, want %v", evicted, want)
+	}
+}
+
+func TestNewCommandQueueWithLimit_Defaults(t *testing.T) {
+	q := NewCommandQueueWithLimit(0, "")
+	if q.size != defaultCommandQueueSize {
+		t.Errorf("size: got %d, want %d", q.size, defaultCommandQueueSize)
+	}
+	if q.policy != QueueRejectNewest {
+		t.Errorf("policy: got %q, want %q", q.policy, QueueRejectNewest)
+	}
+}
+
+func TestParseQueuePolicy(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    QueuePolicy
+		wantErr bool
+	}{
+		{"", QueueRejectNewest, false},
+		{"reject_newest", QueueRejectNewest, false},
+		{"drop_oldest", QueueDropOldest, false},
+		{"fifo", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseQueuePolicy(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseQueuePolicy(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseQueuePolicy(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
 func TestCommandQueue_Wait_CreatesChannel(t *testing.T) {
 	q := NewCommandQueue()
 
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+// throughputTimeout bounds how long one throughput transfer may hold a
+// connection.
+const throughputTimeout = 30 * time.Second
+
+// throughputChunk is the buffer streamed repeatedly to the agent.
+var throughputChunk = make([]byte, 32<<10)
+
+// handleThroughputDownload streams ?bytes= zero bytes to the agent.
+func (s *Server) handleThroughputDownload(w http.ResponseWriter, r *http.Request) {
+	n := int64(protocol.DefaultThroughputBytes)
+	if v := r.URL.Query().Get("bytes"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed <= 0 || parsed > protocol.MaxThroughputBytes {
+			http.Error(w, fmt.Sprintf("bytes must be 1-%d", protocol.MaxThroughputBytes), http.StatusBadRequest)
+			return
+		}
+		n = parsed
+	}
+
+	// Not all writers support deadlines; the transfer is size-bounded regardless
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(throughputTimeout))
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+
+	for n > 0 {
+		chunk := throughputChunk[:min(n, int64(len(throughputChunk)))]
+		if _, err := w.Write(chunk); err != nil {
+			return
+		}
+		n -= int64(len(chunk))
+	}
+}
+
+// handleThroughputUpload discards the request body and reports how many
+// bytes arrived.
+func (s *Server) handleThroughputUpload(w http.ResponseWriter, r *http.Request) {
+	_ = http.NewResponseController(w).SetReadDeadline(time.Now().Add(throughputTimeout))
+
+	n, err := io.Copy(io.Discard, http.MaxBytesReader(w, r.Body, protocol.MaxThroughputBytes))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	s.respondJSON(w, http.StatusOK, map[string]int64{"bytes": n})
+}
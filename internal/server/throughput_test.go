@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nhdewitt/spectra/internal/diagnostics"
+	"github.com/nhdewitt/spectra/internal/protocol"
+)
+
+func TestThroughput_EndToEnd(t *testing.T) {
+	s, agentID, secret, _ := newTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	prepare := func(req *http.Request) { setAgentAuth(req, agentID, secret) }
+
+	res, err := diagnostics.RunThroughput(context.Background(), ts.Client(), ts.URL+"/api/v1/agent/throughput",
+		protocol.ThroughputRequest{Bytes: 1 << 20}, prepare)
+	if err != nil {
+		t.Fatalf("RunThroughput: %v", err)
+	}
+
+	if res.Bytes != 1<<20 {
+		t.Errorf("Bytes = %d, want %d", res.Bytes, 1<<20)
+	}
+	if res.DownloadMbps <= 0 || res.UploadMbps <= 0 {
+		t.Errorf("rates = %.2f down / %.2f up, want both > 0", res.DownloadMbps, res.UploadMbps)
+	}
+}
+
+func TestThroughput_RequiresAgentAuth(t *testing.T) {
+	s, _, _, _ := newTestServer()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/agent/throughput?bytes=10", nil)
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+}
+
+func TestThroughputDownload_InvalidBytes(t *testing.T) {
+	for _, v := range []string{"0", "-1", "abc", "67108865"} {
+		s, agentID, secret, _ := newTestServer()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/agent/throughput?bytes="+v, nil)
+		setAgentAuth(req, agentID, secret)
+		rec := httptest.NewRecorder()
+		s.Router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("bytes %q: status = %d, want 400", v, rec.Code)
+		}
+	}
+}
+
+func TestThroughputUpload_TooLarge(t *testing.T) {
+	s, agentID, secret, _ := newTestServer()
+
+	body := strings.NewReader(strings.Repeat("x", protocol.MaxThroughputBytes+1))
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/agent/throughput", body)
+	setAgentAuth(req, agentID, secret)
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", rec.Code)
+	}
+}
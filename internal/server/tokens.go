@@ -72,3 +72,33 @@ func (ts *TokenStore) Peek(token string) bool {
 
 	return !t.Used && time.Now().Before(t.ExpiresAt)
 }
+
+// snapshot returns the tokens that could still be used.
+func (ts *TokenStore) snapshot() []RegistrationToken {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	now := time.Now()
+	out := make([]RegistrationToken, 0, len(ts.tokens))
+	for _, t := range ts.tokens {
+		if !t.Used && now.Before(t.ExpiresAt) {
+			out = append(out, *t)
+		}
+	}
+	return out
+}
+
+// restore re-adds snapshotted tokens, skipping any that expired while
+// the server was down.
+func (ts *TokenStore) restore(tokens []RegistrationToken) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	now := time.Now()
+	for _, t := range tokens {
+		if t.Used || !now.Before(t.ExpiresAt) {
+			continue
+		}
+		ts.tokens[t.Token] = &t
+	}
+}
@@ -19,7 +19,7 @@ func (s *Server) handleGetUserConfig(w http.ResponseWriter, r *http.Request) {
 
 	rows, err := s.DB.GetUserConfig(r.Context(), mustUUID(u.ID))
 	if err != nil {
-		s.dbError(w, err, "handleGetUserConfig")
+		s.dbError(w, r, err, "handleGetUserConfig")
 		return
 	}
 
@@ -28,7 +28,7 @@ func (s *Server) handleGetUserConfig(w http.ResponseWriter, r *http.Request) {
 		config[row.ConfigKey] = row.ConfigValue
 	}
 
-	respondJSON(w, http.StatusOK, config)
+	s.respondJSON(w, http.StatusOK, config)
 }
 
 // handleSetUserConfig sets a config key for the current user.
@@ -64,7 +64,7 @@ func (s *Server) handleSetUserConfig(w http.ResponseWriter, r *http.Request) {
 		ConfigKey:   req.Key,
 		ConfigValue: req.Value,
 	}); err != nil {
-		s.dbError(w, err, "handleSetUserConfig")
+		s.dbError(w, r, err, "handleSetUserConfig")
 		return
 	}
 
@@ -91,7 +91,7 @@ func (s *Server) handleDeleteUserConfig(w http.ResponseWriter, r *http.Request)
 		UserID:    mustUUID(u.ID),
 		ConfigKey: key,
 	}); err != nil {
-		s.dbError(w, err, "handleDeleteUserConfig")
+		s.dbError(w, r, err, "handleDeleteUserConfig")
 		return
 	}
 
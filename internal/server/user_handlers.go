@@ -15,7 +15,7 @@ func (s *Server) handleListUsers(w http.ResponseWriter, r *http.Request) {
 
 	rows, err := s.DB.ListUsersWithLastLogin(r.Context())
 	if err != nil {
-		s.dbError(w, err, "handleListUsers")
+		s.dbError(w, r, err, "handleListUsers")
 		return
 	}
 
@@ -37,7 +37,7 @@ func (s *Server) handleListUsers(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	respondJSON(w, http.StatusOK, filtered)
+	s.respondJSON(w, http.StatusOK, filtered)
 }
 
 // handleCreateUser creates a new user account.
@@ -83,7 +83,7 @@ func (s *Server) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 
 	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		s.Logger.Error("failed to hash password", "error", err)
+		s.Logger.ErrorContext(r.Context(), "failed to hash password", "error", err)
 		http.Error(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -97,11 +97,11 @@ func (s *Server) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "username already exists", http.StatusConflict)
 			return
 		}
-		s.dbError(w, err, "handleCreateUser")
+		s.dbError(w, r, err, "handleCreateUser")
 		return
 	}
 
-	s.Logger.Info("user created", "username", req.Username, "role", req.Role, "created_by", caller.Username)
+	s.Logger.InfoContext(r.Context(), "user created", "username", req.Username, "role", req.Role, "created_by", caller.Username)
 	w.WriteHeader(http.StatusCreated)
 }
 
@@ -145,7 +145,7 @@ func (s *Server) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
 
 		count, err := s.DB.SuperAdminCount(r.Context())
 		if err != nil {
-			s.dbError(w, err, "handleDeleteUser")
+			s.dbError(w, r, err, "handleDeleteUser")
 			return
 		}
 		if count <= 1 {
@@ -166,14 +166,14 @@ func (s *Server) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
 
 	// Delete user's sessions, then delete user
 	if err := s.DB.DeleteUserSessions(r.Context(), mustUUID(targetID)); err != nil {
-		s.Logger.Warn("failed to delete user sessions", "user_id", targetID, "error", err)
+		s.Logger.WarnContext(r.Context(), "failed to delete user sessions", "user_id", targetID, "error", err)
 	}
 	if err := s.DB.DeleteUser(r.Context(), mustUUID(targetID)); err != nil {
-		s.dbError(w, err, "handleDeleteUser")
+		s.dbError(w, r, err, "handleDeleteUser")
 		return
 	}
 
-	s.Logger.Info("user deleted", "user_id", targetID, "target_role", target.Role, "deleted_by", caller.Username)
+	s.Logger.InfoContext(r.Context(), "user deleted", "user_id", targetID, "target_role", target.Role, "deleted_by", caller.Username)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -215,7 +215,7 @@ func (s *Server) handleUpdateUserRole(w http.ResponseWriter, r *http.Request) {
 	if target.Role == RoleSuperAdmin && req.Role != RoleSuperAdmin {
 		count, err := s.DB.SuperAdminCount(r.Context())
 		if err != nil {
-			s.dbError(w, err, "handleUpdateUserRole")
+			s.dbError(w, r, err, "handleUpdateUserRole")
 			return
 		}
 		if count <= 1 {
@@ -228,10 +228,10 @@ func (s *Server) handleUpdateUserRole(w http.ResponseWriter, r *http.Request) {
 		ID:   mustUUID(targetID),
 		Role: req.Role,
 	}); err != nil {
-		s.dbError(w, err, "handleUpdateUserRole")
+		s.dbError(w, r, err, "handleUpdateUserRole")
 		return
 	}
 
-	s.Logger.Info("user role updated", "user_id", targetID, "old_role", target.Role, "new_role", req.Role, "updated_by", caller.Username)
+	s.Logger.InfoContext(r.Context(), "user role updated", "user_id", targetID, "old_role", target.Role, "new_role", req.Role, "updated_by", caller.Username)
 	w.WriteHeader(http.StatusNoContent)
 }
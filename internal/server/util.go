@@ -9,9 +9,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"regexp"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -21,17 +21,26 @@ import (
 
 var uuidRegex = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
 
+// requestBody returns the request body, transparently decompressing
+// it when Content-Encoding is gzip.
+func requestBody(r *http.Request) (io.ReadCloser, error) {
+	if r.Header.Get("Content-Encoding") != "gzip" {
+		return r.Body, nil
+	}
+
+	gz, err := gzip.NewReader(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("bad gzip body: %w", err)
+	}
+	return gz, nil
+}
+
 // decodeJSONBody reads the request body, handling optional gzip compression,
 // and decodes it into the provided target struct.
 func decodeJSONBody(r *http.Request, target any) error {
-	var reader io.ReadCloser = r.Body
-
-	if r.Header.Get("Content-Encoding") == "gzip" {
-		gz, err := gzip.NewReader(r.Body)
-		if err != nil {
-			return fmt.Errorf("bad gzip body: %w", err)
-		}
-		reader = gz
+	reader, err := requestBody(r)
+	if err != nil {
+		return err
 	}
 	defer reader.Close()
 
@@ -47,44 +56,161 @@ func getAgentID(r *http.Request) string {
 }
 
 // respondJSON sends a JSON response with the given status code.
-func respondJSON(w http.ResponseWriter, status int, data any) {
+func (s *Server) respondJSON(w http.ResponseWriter, status int, data any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if data != nil {
 		if err := json.NewEncoder(w).Encode(data); err != nil {
-			log.Printf("Failed to write JSON response: %v", err)
+			s.Logger.Warn("failed to write JSON response", "error", err)
 		}
 	}
 }
 
-// respondError sends a JSON error response.
-func respondError(w http.ResponseWriter, status int, msg string) {
-	respondJSON(w, status, map[string]string{"error": msg})
+// respondError sends a JSON error response, including the request ID
+// when requestIDMiddleware has assigned one.
+func (s *Server) respondError(w http.ResponseWriter, status int, msg string) {
+	body := map[string]string{"error": msg}
+	if id := w.Header().Get(requestIDHeader); id != "" {
+		body["request_id"] = id
+	}
+	s.respondJSON(w, status, body)
 }
 
 // queueHelper abstracts the repetitive command creation/queueing logic for Admin handlers.
-func (s *Server) queueHelper(w http.ResponseWriter, agentID string, cmdType protocol.CommandType, payload []byte, successMsg string) {
+// An empty agentID means the request targets agents by ?tag= (see
+// getTargetAgent) and the command is fanned out with queueTagged.
+func (s *Server) queueHelper(w http.ResponseWriter, r *http.Request, agentID string, cmdType protocol.CommandType, payload []byte, successMsg string) {
+	timeout, err := parseCommandTimeout(r.URL.Query().Get("timeout"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if agentID == "" {
+		s.queueTagged(w, r, targetTags(r), cmdType, payload, timeout, successMsg)
+		return
+	}
+
 	cmd := protocol.Command{
 		ID:      uuid.NewString(),
 		Type:    cmdType,
 		Payload: payload,
+		Timeout: timeout,
 	}
 
-	err := s.CmdQueue.Send(agentID, cmd)
+	err = s.enqueue(agentID, cmd)
+	if errors.Is(err, ErrQueueFull) {
+		s.Logger.WarnContext(r.Context(), "command rejected, queue full", "agent_id", agentID, "command", cmdType)
+		http.Error(w, "Command queue full for agent", http.StatusTooManyRequests)
+		return
+	}
 	if err != nil {
-		s.Logger.Error("queue full or agent not found", "error", err, "handler", "queueHelper")
+		s.Logger.ErrorContext(r.Context(), "queue full or agent not found", "error", err, "handler", "queueHelper")
 		http.Error(w, "Queue full or agent not found", http.StatusServiceUnavailable)
 		return
 	}
 
-	s.Commands.Track(cmd.ID, cmdType, agentID)
-	s.Logger.Info("command queued", "agent_id", agentID, "command", cmdType)
-	respondJSON(w, http.StatusAccepted, map[string]string{
+	s.Logger.InfoContext(r.Context(), "command queued", "agent_id", agentID, "command", cmdType)
+	s.respondJSON(w, http.StatusAccepted, map[string]string{
 		"command_id": cmd.ID,
 		"message":    successMsg,
 	})
 }
 
+// enqueue tracks cmd and queues it for agentID. Tracking comes first so
+// an eviction racing the send can still mark the command failed.
+func (s *Server) enqueue(agentID string, cmd protocol.Command) error {
+	s.Commands.Track(cmd.ID, cmd.Type, agentID)
+	if err := s.CmdQueue.Send(agentID, cmd); err != nil {
+		s.Commands.Forget(cmd.ID)
+		return err
+	}
+	return nil
+}
+
+// commandEvicted records a command QueueDropOldest pushed out of an
+// agent's queue, so anyone polling its status sees it failed.
+func (s *Server) commandEvicted(agentID string, cmd protocol.Command) {
+	s.Logger.Warn("command evicted, queue full",
+		"agent_id", agentID,
+		"command_id", cmd.ID,
+		"command", cmd.Type)
+	s.Commands.Complete(cmd.ID, protocol.CommandResult{
+		ID:    cmd.ID,
+		Type:  cmd.Type,
+		Error: "evicted from a full command queue before the agent picked it up",
+	})
+}
+
+// targetTags returns the normalized ?tag= values of an admin trigger.
+// Repeat the parameter to require several tags.
+func targetTags(r *http.Request) []string {
+	return normalizeTags(r.URL.Query()["tag"])
+}
+
+// queueTagged queues one command to every agent carrying all of tags.
+// Only broadcastable commands may be fanned out this way.
+func (s *Server) queueTagged(w http.ResponseWriter, r *http.Request, tags []string, cmdType protocol.CommandType, payload []byte, timeout int, successMsg string) {
+	if !broadcastable[cmdType] {
+		http.Error(w, fmt.Sprintf("command %q cannot be targeted by tag", cmdType), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	agents, err := s.DB.ListAgents(ctx)
+	if err != nil {
+		s.dbError(w, r, err, "queueTagged")
+		return
+	}
+
+	commands := make(map[string]string)
+	failed := 0
+	for _, agent := range agents {
+		if !hasAllTags(agent.Tags, tags) {
+			continue
+		}
+		id := formatUUID(agent.ID)
+		cmd := protocol.Command{
+			ID:      uuid.NewString(),
+			Type:    cmdType,
+			Payload: payload,
+			Timeout: timeout,
+		}
+		if err := s.enqueue(id, cmd); err != nil {
+			s.Logger.WarnContext(ctx, "tagged command: queue failed", "agent_id", id, "error", err)
+			failed++
+			continue
+		}
+		commands[id] = cmd.ID
+	}
+
+	s.Logger.InfoContext(ctx, "command queued by tag",
+		"tags", tags,
+		"command", cmdType,
+		"queued", len(commands),
+		"failed", failed)
+
+	s.respondJSON(w, http.StatusAccepted, map[string]any{
+		"queued":   len(commands),
+		"failed":   failed,
+		"commands": commands,
+		"message":  successMsg,
+	})
+}
+
+// parseCommandTimeout reads the optional ?timeout= seconds for a queued
+// command. The agent applies its own default and cap.
+func parseCommandTimeout(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs <= 0 {
+		return 0, fmt.Errorf("invalid timeout")
+	}
+	return secs, nil
+}
+
 func formatBytes(b uint64) string {
 	const unit = 1024
 	if b < unit {
@@ -106,24 +232,31 @@ func generateSecret(n int) (string, error) {
 	return hex.EncodeToString(b), nil
 }
 
+// getTargetAgent validates the ?agent= ID of an admin trigger. A request
+// with ?tag= instead returns an empty ID, which queueHelper fans out to
+// every agent carrying all the tags.
 func (s *Server) getTargetAgent(w http.ResponseWriter, r *http.Request) (string, bool) {
 	agentID := r.URL.Query().Get("agent")
+	if agentID == "" && len(targetTags(r)) > 0 {
+		// Resolved to the tagged agents by queueHelper.
+		return "", true
+	}
 	if agentID == "" {
-		s.Logger.Warn("no agent ID provided", "handler", "getTargetAgent")
+		s.Logger.WarnContext(r.Context(), "no agent ID provided", "handler", "getTargetAgent")
 		http.Error(w, "agent ID required", http.StatusBadRequest)
 		return "", false
 	}
 
 	var uid pgtype.UUID
 	if err := uid.Scan(agentID); err != nil {
-		s.Logger.Warn("invalid agent ID", "agent_id", agentID, "handler", "getTargetAgent")
+		s.Logger.WarnContext(r.Context(), "invalid agent ID", "agent_id", agentID, "handler", "getTargetAgent")
 		http.Error(w, "invalid agent ID", http.StatusBadRequest)
 		return "", false
 	}
 
 	_, err := s.DB.GetAgent(r.Context(), uid)
 	if err != nil {
-		s.Logger.Warn("agent not found", "agent_id", agentID, "handler", "getTargetAgent")
+		s.Logger.WarnContext(r.Context(), "agent not found", "agent_id", agentID, "handler", "getTargetAgent")
 		http.Error(w, "agent not found", http.StatusNotFound)
 		return "", false
 	}
@@ -145,8 +278,8 @@ func fleetQuery[P any, R any](ctx context.Context, queryFn func(context.Context,
 	return result, nil
 }
 
-func (s *Server) dbError(w http.ResponseWriter, err error, handler string) {
-	s.Logger.Error("database query failed", "error", err, "handler", handler)
+func (s *Server) dbError(w http.ResponseWriter, r *http.Request, err error, handler string) {
+	s.Logger.ErrorContext(r.Context(), "database query failed", "error", err, "handler", handler)
 	http.Error(w, "database error", http.StatusInternalServerError)
 }
 
@@ -5,10 +5,14 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/nhdewitt/spectra/internal/logging"
 	"github.com/nhdewitt/spectra/internal/protocol"
 )
 
@@ -231,10 +235,11 @@ func TestGetTargetAgent_NotFound(t *testing.T) {
 }
 
 func TestRespondJSON_Success(t *testing.T) {
+	s := New(Config{Port: 8080}, NewMockDB())
 	rec := httptest.NewRecorder()
 
 	data := map[string]string{"status": "ok"}
-	respondJSON(rec, http.StatusOK, data)
+	s.respondJSON(rec, http.StatusOK, data)
 
 	if rec.Code != http.StatusOK {
 		t.Errorf("status = %d, want 200", rec.Code)
@@ -251,9 +256,10 @@ func TestRespondJSON_Success(t *testing.T) {
 }
 
 func TestRespondJSON_NilData(t *testing.T) {
+	s := New(Config{Port: 8080}, NewMockDB())
 	rec := httptest.NewRecorder()
 
-	respondJSON(rec, http.StatusNoContent, nil)
+	s.respondJSON(rec, http.StatusNoContent, nil)
 
 	if rec.Code != http.StatusNoContent {
 		t.Errorf("status = %d, want 204", rec.Code)
@@ -264,10 +270,11 @@ func TestRespondJSON_NilData(t *testing.T) {
 }
 
 func TestRespondJSON_Struct(t *testing.T) {
+	s := New(Config{Port: 8080}, NewMockDB())
 	rec := httptest.NewRecorder()
 
 	cmd := protocol.Command{ID: "cmd-123", Type: protocol.CmdFetchLogs}
-	respondJSON(rec, http.StatusOK, cmd)
+	s.respondJSON(rec, http.StatusOK, cmd)
 
 	var response protocol.Command
 	json.NewDecoder(rec.Body).Decode(&response)
@@ -280,7 +287,7 @@ func TestQueueHelper_Success(t *testing.T) {
 	s, agentID, _, _ := newTestServer()
 
 	rec := httptest.NewRecorder()
-	s.queueHelper(rec, agentID, protocol.CmdFetchLogs, []byte(`{}`), "Queued!")
+	s.queueHelper(rec, httptest.NewRequest(http.MethodPost, "/", nil), agentID, protocol.CmdFetchLogs, []byte(`{}`), "Queued!")
 
 	if rec.Code != http.StatusAccepted {
 		t.Errorf("status = %d, want 202", rec.Code)
@@ -302,7 +309,7 @@ func TestQueueHelper_UnregisteredAgent(t *testing.T) {
 	s := New(Config{Port: 8080}, NewMockDB())
 
 	rec := httptest.NewRecorder()
-	s.queueHelper(rec, "unknown-agent", protocol.CmdFetchLogs, []byte(`{}`), "Queued!")
+	s.queueHelper(rec, httptest.NewRequest(http.MethodPost, "/", nil), "unknown-agent", protocol.CmdFetchLogs, []byte(`{}`), "Queued!")
 
 	if rec.Code != http.StatusAccepted {
 		t.Errorf("status = %d, want 202", rec.Code)
@@ -317,10 +324,93 @@ func TestQueueHelper_QueueFull(t *testing.T) {
 	}
 
 	rec := httptest.NewRecorder()
-	s.queueHelper(rec, "agent-1", protocol.CmdFetchLogs, []byte(`{}`), "Queued!")
+	s.queueHelper(rec, httptest.NewRequest(http.MethodPost, "/", nil), "agent-1", protocol.CmdFetchLogs, []byte(`{}`), "Queued!")
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want 429", rec.Code)
+	}
+}
+
+func TestQueueHelper_DropOldestFailsEvicted(t *testing.T) {
+	capture := &captureHandler{}
+	s := New(Config{Port: 8080, CmdQueueSize: 1, CmdQueuePolicy: QueueDropOldest}, NewMockDB())
+	s.Logger = logging.FromHandler(requestIDHandler{capture})
+
+	queue := func() string {
+		rec := httptest.NewRecorder()
+		s.queueHelper(rec, httptest.NewRequest(http.MethodPost, "/", nil), "agent-1", protocol.CmdFetchLogs, []byte(`{}`), "Queued!")
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("status = %d, want 202", rec.Code)
+		}
+		var resp map[string]string
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return resp["command_id"]
+	}
+	first := queue()
+	second := queue()
+
+	entry, ok := s.Commands.Get(first)
+	if !ok {
+		t.Fatal("evicted command should stay tracked")
+	}
+	if !entry.Done || entry.Result == nil || entry.Result.Error == "" {
+		t.Errorf("evicted command should be done with an error, got %+v", entry)
+	}
+	if v, ok := capture.attr("command evicted, queue full", "command_id"); !ok || v.String() != first {
+		t.Errorf("eviction log command_id = %v (found %v), want %s", v, ok, first)
+	}
+
+	if entry, _ := s.Commands.Get(second); entry == nil || entry.Done {
+		t.Errorf("queued command should still be pending, got %+v", entry)
+	}
+}
+
+func TestQueueHelper_QueueFullForgetsCommand(t *testing.T) {
+	s := New(Config{Port: 8080, CmdQueueSize: 1}, NewMockDB())
+	s.CmdQueue.Send("agent-1", protocol.Command{ID: "cmd"})
+
+	rec := httptest.NewRecorder()
+	s.queueHelper(rec, httptest.NewRequest(http.MethodPost, "/", nil), "agent-1", protocol.CmdFetchLogs, []byte(`{}`), "Queued!")
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+	s.Commands.mu.Lock()
+	n := len(s.Commands.entries)
+	s.Commands.mu.Unlock()
+	if n != 0 {
+		t.Errorf("rejected command left %d tracked entries", n)
+	}
+}
+
+func TestQueueHelper_Timeout(t *testing.T) {
+	s, agentID, _, _ := newTestServer()
+
+	rec := httptest.NewRecorder()
+	s.queueHelper(rec, httptest.NewRequest(http.MethodPost, "/?timeout=300", nil), agentID, protocol.CmdDiskUsage, nil, "Queued!")
 
-	if rec.Code != http.StatusServiceUnavailable {
-		t.Errorf("status = %d, want 503", rec.Code)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", rec.Code)
+	}
+	cmd, err := s.CmdQueue.Wait(context.Background(), agentID, time.Second)
+	if err != nil {
+		t.Fatalf("expected queued command: %v", err)
+	}
+	if cmd.Timeout != 300 {
+		t.Errorf("Timeout = %d, want 300", cmd.Timeout)
+	}
+}
+
+func TestQueueHelper_InvalidTimeout(t *testing.T) {
+	for _, v := range []string{"0", "-5", "abc"} {
+		s, agentID, _, _ := newTestServer()
+
+		rec := httptest.NewRecorder()
+		s.queueHelper(rec, httptest.NewRequest(http.MethodPost, "/?timeout="+v, nil), agentID, protocol.CmdDiskUsage, nil, "Queued!")
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("timeout %q: status = %d, want 400", v, rec.Code)
+		}
 	}
 }
 
@@ -426,13 +516,31 @@ func BenchmarkGenerateSecret(b *testing.B) {
 	}
 }
 
+// failingWriter is a ResponseWriter whose body writes fail, as when the
+// client has gone away.
+type failingWriter struct{ *httptest.ResponseRecorder }
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+func TestRespondJSON_WriteErrorUsesServerLogger(t *testing.T) {
+	capture := &captureHandler{}
+	s := New(Config{Port: 8080, Logger: logging.FromHandler(capture)}, NewMockDB())
+
+	s.respondJSON(failingWriter{httptest.NewRecorder()}, http.StatusOK, map[string]string{"status": "ok"})
+
+	if v, ok := capture.attr("failed to write JSON response", "error"); !ok || !strings.Contains(v.String(), "connection reset") {
+		t.Errorf("error attr: got %v (found=%v)", v, ok)
+	}
+}
+
 func BenchmarkRespondJSON_Small(b *testing.B) {
 	data := map[string]string{"status": "ok"}
 
+	s := New(Config{Port: 8080}, NewMockDB())
 	b.ReportAllocs()
 	for b.Loop() {
 		rec := httptest.NewRecorder()
-		respondJSON(rec, http.StatusOK, data)
+		s.respondJSON(rec, http.StatusOK, data)
 	}
 }
 
@@ -443,10 +551,11 @@ func BenchmarkRespondJSON_Command(b *testing.B) {
 		Payload: []byte(`{"min_level":"ERROR"}`),
 	}
 
+	s := New(Config{Port: 8080}, NewMockDB())
 	b.ReportAllocs()
 	for b.Loop() {
 		rec := httptest.NewRecorder()
-		respondJSON(rec, http.StatusOK, cmd)
+		s.respondJSON(rec, http.StatusOK, cmd)
 	}
 }
 
@@ -479,6 +588,6 @@ func BenchmarkQueueHelper(b *testing.B) {
 		b.StartTimer()
 
 		rec := httptest.NewRecorder()
-		s.queueHelper(rec, "agent-1", protocol.CmdFetchLogs, payload, "Queued!")
+		s.queueHelper(rec, httptest.NewRequest(http.MethodPost, "/", nil), "agent-1", protocol.CmdFetchLogs, payload, "Queued!")
 	}
 }
@@ -29,6 +29,58 @@ type ServerConfig struct {
 	TLSCert     string `json:"tls_cert,omitempty"`
 	TLSKey      string `json:"tls_key,omitempty"`
 	TLSCA       string `json:"tls_ca,omitempty"`
+
+	// MaxBatchSize caps the envelopes accepted per metrics POST. Zero
+	// uses the server default of 1000.
+	MaxBatchSize int `json:"max_batch_size,omitempty"`
+
+	// CommandQueueSize caps pending commands per agent (default 10).
+	// CommandQueuePolicy is "reject_newest" (default) or "drop_oldest".
+	CommandQueueSize   int    `json:"command_queue_size,omitempty"`
+	CommandQueuePolicy string `json:"command_queue_policy,omitempty"`
+
+	// RateLimits overrides the per-tier request limits. Omitted fields keep
+	// the built-in defaults; a negative rate disables a tier.
+	RateLimits RateLimits `json:"rate_limits,omitzero"`
+
+	// CORS* allow browser dashboards on other origins to call the read
+	// API. CORS is off while CORSOrigins is empty.
+	CORSOrigins []string `json:"cors_origins,omitempty"`
+	CORSMethods []string `json:"cors_methods,omitempty"`
+	CORSHeaders []string `json:"cors_headers,omitempty"`
+
+	// StateFile, when set, carries pending agent commands and unused
+	// registration tokens across a graceful restart.
+	StateFile string `json:"state_file,omitempty"`
+
+	// ShutdownDrainSeconds keeps serving with /readyz failing for this
+	// long after SIGTERM before the listener closes. Zero closes at once.
+	ShutdownDrainSeconds int `json:"shutdown_drain_seconds,omitempty"`
+
+	// OTLPEndpoint forwards ingested metrics to an OpenTelemetry
+	// collector over OTLP/HTTP, e.g. "http://otel-collector:4318".
+	OTLPEndpoint string `json:"otlp_endpoint,omitempty"`
+
+	// Influx* forward ingested metrics to an InfluxDB v2 bucket as line
+	// protocol. Export is off while InfluxURL is empty.
+	InfluxURL    string `json:"influx_url,omitempty"`
+	InfluxOrg    string `json:"influx_org,omitempty"`
+	InfluxBucket string `json:"influx_bucket,omitempty"`
+	InfluxToken  string `json:"influx_token,omitempty"`
+}
+
+// RateLimit is one tier's token bucket: sustained requests per second
+// and burst size.
+type RateLimit struct {
+	Rate  float64 `json:"rate,omitempty"`
+	Burst int     `json:"burst,omitempty"`
+}
+
+// RateLimits groups the server's rate limit tiers.
+type RateLimits struct {
+	Anon   RateLimit `json:"anon,omitzero"`
+	Authed RateLimit `json:"authed,omitzero"`
+	Agent  RateLimit `json:"agent,omitzero"`
 }
 
 // AdminCredentials holds the admin user info collected during setup.
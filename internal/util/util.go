@@ -76,8 +76,18 @@ func CleanVendor(v string) string {
 	return v
 }
 
-func NormalizeMax(temp, v float64) *float64 {
-	if v <= 0 || v < temp || v >= 200 {
+// DefaultMaxTempBound is the exclusive upper bound NormalizeMax applies
+// when none is configured.
+const DefaultMaxTempBound = 200.0
+
+// NormalizeMax returns v as a sensor's max temperature, or nil when it is
+// unset, below temp, or at or above upper. An upper of zero or less uses
+// DefaultMaxTempBound.
+func NormalizeMax(temp, v, upper float64) *float64 {
+	if upper <= 0 {
+		upper = DefaultMaxTempBound
+	}
+	if v <= 0 || v < temp || v >= upper {
 		return nil
 	}
 	return &v